@@ -0,0 +1,221 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Defaults of an AdaptiveConcurrency.
+const (
+	// DefaultAdaptiveMin and DefaultAdaptiveMax bound the concurrency limit.
+	DefaultAdaptiveMin = 1
+	DefaultAdaptiveMax = 32
+
+	// DefaultAdaptiveLatency is the response time above which requests count as a
+	// sign of congestion.
+	DefaultAdaptiveLatency = 2 * time.Second
+
+	// DefaultAdaptiveBackoff is the factor the limit is multiplied by on congestion.
+	DefaultAdaptiveBackoff = 0.5
+)
+
+// AdaptiveOption configures an AdaptiveConcurrency.
+type AdaptiveOption func(*AdaptiveConcurrency)
+
+// WithAdaptiveBounds sets the lowest and highest concurrency limit. The limit starts
+// at 4, or within the bounds. Default: 1 and 32.
+func WithAdaptiveBounds(lowest, highest int) AdaptiveOption {
+	return func(a *AdaptiveConcurrency) {
+		a.min = float64(max(1, lowest))
+		a.max = float64(max(lowest, highest, 1))
+	}
+}
+
+// WithAdaptiveLatency sets the response time above which a successful request counts
+// as congestion. Default: 2 seconds.
+func WithAdaptiveLatency(target time.Duration) AdaptiveOption {
+	return func(a *AdaptiveConcurrency) {
+		if target > 0 {
+			a.target = target
+		}
+	}
+}
+
+// WithAdaptiveBackoff sets the factor, between 0 and 1, the limit is multiplied by on
+// congestion. Default: 0.5.
+func WithAdaptiveBackoff(factor float64) AdaptiveOption {
+	return func(a *AdaptiveConcurrency) {
+		if factor > 0 && factor < 1 {
+			a.backoff = factor
+		}
+	}
+}
+
+// AdaptiveConcurrency limits the number of requests in flight with an AIMD
+// (additive increase, multiplicative decrease) controller: every request answered
+// within the latency target raises the limit by 1/limit, about one per round of
+// requests, and a 429, 503 or 504 response, a timeout or a slow response multiplies
+// it by the backoff factor. Only one decrease applies per round, so a burst of
+// failures from requests sent at the same limit backs off once. It replaces tuning
+// static batch and page concurrency for long-running jobs; attach it to a client with
+// WithAdaptiveConcurrency.
+type AdaptiveConcurrency struct {
+	min, max float64
+	target   time.Duration
+	backoff  float64
+
+	mu           sync.Mutex
+	limit        float64
+	inflight     int
+	waiters      []chan struct{}
+	lastDecrease time.Time
+	increases    int
+	decreases    int
+}
+
+// AdaptiveStats describes the state of an AdaptiveConcurrency.
+type AdaptiveStats struct {
+	// Limit is the current number of requests allowed in flight.
+	Limit int `json:"limit"`
+
+	InFlight int `json:"in_flight"`
+	Waiting  int `json:"waiting"`
+
+	// Increases and Decreases count limit changes.
+	Increases int `json:"increases"`
+	Decreases int `json:"decreases"`
+}
+
+// NewAdaptiveConcurrency creates an AdaptiveConcurrency.
+//
+// Example usage:
+//
+//	adaptive := gonpi.NewAdaptiveConcurrency(gonpi.WithAdaptiveBounds(2, 64))
+//	extracts := client.With(gonpi.WithAdaptiveConcurrency(adaptive))
+//	items, err := extracts.GetProvidersByNPIsOrdered(ctx, npis, gonpi.WithBatchConcurrency(64))
+func NewAdaptiveConcurrency(opts ...AdaptiveOption) *AdaptiveConcurrency {
+	a := &AdaptiveConcurrency{
+		min:     DefaultAdaptiveMin,
+		max:     DefaultAdaptiveMax,
+		target:  DefaultAdaptiveLatency,
+		backoff: DefaultAdaptiveBackoff,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.limit = min(max(4, a.min), a.max)
+	return a
+}
+
+// WithAdaptiveConcurrency makes every API request of the client, including retries
+// and requests sent through Transport, wait for a slot of a. Several clients may
+// share a. Raise WithBatchConcurrency and SearchOptions.PageConcurrency to the most
+// the job should ever use and let a settle on the level the API sustains.
+func WithAdaptiveConcurrency(a *AdaptiveConcurrency) ClientOption {
+	return func(c *Client) {
+		c.adaptive = a
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (a *AdaptiveConcurrency) Limit() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return int(a.limit)
+}
+
+// Stats returns the current state of the controller.
+func (a *AdaptiveConcurrency) Stats() AdaptiveStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return AdaptiveStats{
+		Limit:     int(a.limit),
+		InFlight:  a.inflight,
+		Waiting:   len(a.waiters),
+		Increases: a.increases,
+		Decreases: a.decreases,
+	}
+}
+
+// acquire waits for a slot or until ctx is done. Waiters are served in arrival order.
+func (a *AdaptiveConcurrency) acquire(ctx context.Context) error {
+	a.mu.Lock()
+	if len(a.waiters) == 0 && a.inflight < int(a.limit) {
+		a.inflight++
+		a.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	a.waiters = append(a.waiters, ready)
+	a.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if i := slices.Index(a.waiters, ready); i >= 0 {
+			a.waiters = slices.Delete(a.waiters, i, i+1)
+		} else {
+			// The slot was granted as ctx was cancelled; hand it on
+			a.inflight--
+			a.wake()
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees the slot of a request sent at start that ended with resp and err,
+// adjusting the limit.
+func (a *AdaptiveConcurrency) release(start time.Time, resp *http.Response, err error) {
+	latency := time.Since(start)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.inflight--
+	switch {
+	case congested(resp, err) || (err == nil && latency > a.target):
+		// Requests sent before the last decrease saw the old limit
+		if start.After(a.lastDecrease) {
+			a.limit = max(a.min, a.limit*a.backoff)
+			a.lastDecrease = time.Now()
+			a.decreases++
+		}
+	case err == nil && resp.StatusCode < http.StatusInternalServerError:
+		next := min(a.max, a.limit+1/a.limit)
+		if int(next) > int(a.limit) {
+			a.increases++
+		}
+		a.limit = next
+	}
+	a.wake()
+}
+
+// wake grants slots to waiters up to the limit. The caller must hold a.mu.
+func (a *AdaptiveConcurrency) wake() {
+	for len(a.waiters) > 0 && a.inflight < int(a.limit) {
+		a.inflight++
+		close(a.waiters[0])
+		a.waiters = a.waiters[1:]
+	}
+}
+
+// congested reports whether a request outcome signals that the API is overloaded:
+// rate limiting, unavailability or a timeout. Cancellation by the caller does not.
+func congested(resp *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
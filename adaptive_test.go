@@ -0,0 +1,132 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestAdaptiveConcurrency_AIMD tests additive increases and one multiplicative decrease
+// per round of requests.
+func TestAdaptiveConcurrency_AIMD(t *testing.T) {
+	a := NewAdaptiveConcurrency(WithAdaptiveBounds(1, 8), WithAdaptiveLatency(20*time.Millisecond))
+	ctx := context.Background()
+	ok := &http.Response{StatusCode: http.StatusOK}
+	limited := &http.Response{StatusCode: http.StatusTooManyRequests}
+
+	if a.Limit() != 4 {
+		t.Fatalf("initial limit = %d, want 4", a.Limit())
+	}
+	for range 100 {
+		a.acquire(ctx)
+		a.release(time.Now(), ok, nil)
+	}
+	if a.Limit() != 8 {
+		t.Fatalf("limit after successes = %d, want the maximum of 8", a.Limit())
+	}
+
+	sentBefore := time.Now()
+	a.acquire(ctx)
+	a.acquire(ctx)
+	a.release(time.Now(), limited, nil)
+	if a.Limit() != 4 {
+		t.Errorf("limit after a 429 = %d, want 4", a.Limit())
+	}
+	a.release(sentBefore, nil, context.DeadlineExceeded)
+	if a.Limit() != 4 {
+		t.Errorf("a request sent before the decrease backed off again: limit %d", a.Limit())
+	}
+
+	a.acquire(ctx)
+	start := time.Now()
+	time.Sleep(25 * time.Millisecond)
+	a.release(start, ok, nil)
+	if a.Limit() != 2 {
+		t.Errorf("limit after a slow response = %d, want 2", a.Limit())
+	}
+	a.acquire(ctx)
+	a.release(time.Now(), nil, context.Canceled)
+	if stats := a.Stats(); stats.Limit != 2 || stats.InFlight != 0 || stats.Decreases != 2 || stats.Increases != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// TestAdaptiveConcurrency_Wait tests that requests over the limit wait in order and
+// can be cancelled.
+func TestAdaptiveConcurrency_Wait(t *testing.T) {
+	a := NewAdaptiveConcurrency(WithAdaptiveBounds(1, 1))
+	ctx := context.Background()
+	a.acquire(ctx)
+
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := a.acquire(cancelled); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to be cancelled, got %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		a.acquire(ctx)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired a slot over the limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	a.release(time.Now(), &http.Response{StatusCode: http.StatusOK}, nil)
+	<-acquired
+	if stats := a.Stats(); stats.InFlight != 1 || stats.Waiting != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// TestWithAdaptiveConcurrency tests that a batch with a high static concurrency backs
+// off when the API starts rate limiting.
+func TestWithAdaptiveConcurrency(t *testing.T) {
+	var inflight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(2 * time.Millisecond)
+		if n > 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		p := mockProvider()
+		p.Number = r.URL.Query().Get("number")
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{p}))
+	}))
+	defer server.Close()
+
+	adaptive := NewAdaptiveConcurrency(WithAdaptiveBounds(1, 16))
+	client := NewClient(WithBaseURL(server.URL), WithAdaptiveConcurrency(adaptive),
+		WithRetry(RetryConfig{MaxRetries: 10, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, BackoffMultiplier: 2}))
+	defer client.Close()
+
+	npis := make([]string, 60)
+	for i := range npis {
+		npis[i] = fmt.Sprintf("10000000%02d", i)
+	}
+	items, err := client.GetProvidersByNPIsOrdered(context.Background(), npis, WithBatchConcurrency(32))
+	if err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if len(items) != len(npis) {
+		t.Fatalf("got %d items", len(items))
+	}
+	if stats := adaptive.Stats(); stats.Decreases == 0 || stats.InFlight != 0 {
+		t.Errorf("expected the controller to back off: %+v", stats)
+	}
+	if p := peak.Load(); p > 16 {
+		t.Errorf("peak concurrency %d exceeded the adaptive maximum", p)
+	}
+}
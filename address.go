@@ -0,0 +1,250 @@
+package gonpi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// usPostalCountries are the ISO codes of US territories and freely associated states,
+// which some records use as their country instead of US with the territory as state.
+// They are served by USPS and use ZIP codes.
+var usPostalCountries = map[CountryCode]bool{
+	"AS": true, "FM": true, "GU": true, "MH": true, "MP": true,
+	"PR": true, "PW": true, "UM": true, "VI": true,
+}
+
+// USPostal reports whether addresses in c use the US postal system: the US itself,
+// its territories and the freely associated states. The registry leaves the country
+// code empty on some older records, so "" counts as the US.
+func (c CountryCode) USPostal() bool {
+	c = c.normalize()
+	return c == "" || c == CountryUS || usPostalCountries[c]
+}
+
+// postalCodePatterns are the postal code formats of the countries most often found in
+// NPPES foreign addresses, matched after FormatPostalCode.
+var postalCodePatterns = map[CountryCode]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-\d{3}$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] \d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"CN": regexp.MustCompile(`^\d{6}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^([A-Z]{1,2}\d[A-Z\d]? \d[A-Z]{2}|GIR 0AA)$`),
+	"IL": regexp.MustCompile(`^\d{7}$|^\d{5}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-\d{4}$`),
+	"KR": regexp.MustCompile(`^\d{5}$`),
+	"MX": regexp.MustCompile(`^\d{5}$`),
+	"NL": regexp.MustCompile(`^\d{4} [A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"NZ": regexp.MustCompile(`^\d{4}$`),
+	"PH": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} \d{2}$`),
+	"SG": regexp.MustCompile(`^\d{6}$`),
+}
+
+// genericPostalCode matches postal codes of countries without a known format.
+var genericPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{0,10}[A-Z0-9]$|^[A-Z0-9]$`)
+
+// ValidPostalCode reports whether code is a well-formed postal code for country c,
+// in any of the spellings FormatPostalCode accepts. US, territory and military
+// addresses take 5 or 9 digit ZIP codes, and countries with a known format, such as
+// Canada, Mexico, the UK and most of Europe, must match it. Other countries accept
+// any code of up to 12 letters, digits, spaces and hyphens.
+func (c CountryCode) ValidPostalCode(code string) bool {
+	if c.USPostal() {
+		return validUSPostalCode(strings.TrimSpace(code))
+	}
+	formatted := c.FormatPostalCode(code)
+	if pattern, ok := postalCodePatterns[c.normalize()]; ok {
+		return pattern.MatchString(formatted)
+	}
+	return genericPostalCode.MatchString(formatted)
+}
+
+// validUSPostalCode reports whether code is a 5 or 9 digit ZIP code, with or without
+// a dash.
+func validUSPostalCode(code string) bool {
+	if len(code) == 10 && code[5] == '-' {
+		code = code[:5] + code[6:]
+	}
+	if len(code) != 5 && len(code) != 9 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatPostalCode returns code in the canonical form of country c: ZIP+4 codes with a
+// hyphen, and Canadian, UK, Dutch and Swedish codes uppercased with the space the
+// registry often drops. Codes that do not fit the country's format are returned
+// trimmed and uppercased.
+func (c CountryCode) FormatPostalCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if c.USPostal() {
+		if validUSPostalCode(code) && len(code) == 9 {
+			return code[:5] + "-" + code[5:]
+		}
+		return code
+	}
+
+	compact := strings.NewReplacer(" ", "", "-", "").Replace(code)
+	split := func(at int, sep string) string {
+		if at <= 0 || at >= len(compact) {
+			return code
+		}
+		return compact[:at] + sep + compact[at:]
+	}
+	switch c.normalize() {
+	case "CA":
+		if len(compact) == 6 {
+			return split(3, " ")
+		}
+	case "NL":
+		if len(compact) == 6 {
+			return split(4, " ")
+		}
+	case "GB":
+		if len(compact) >= 5 && len(compact) <= 7 {
+			return split(len(compact)-3, " ")
+		}
+	case "SE":
+		if len(compact) == 5 {
+			return split(3, " ")
+		}
+	case "BR":
+		if len(compact) == 8 {
+			return split(5, "-")
+		}
+	case "JP":
+		if len(compact) == 7 {
+			return split(3, "-")
+		}
+	case "PL":
+		if len(compact) == 5 {
+			return split(2, "-")
+		}
+	case "PT":
+		if len(compact) == 7 {
+			return split(4, "-")
+		}
+	}
+	return code
+}
+
+// normalize returns c trimmed and uppercased.
+func (c CountryCode) normalize() CountryCode {
+	return CountryCode(strings.ToUpper(strings.TrimSpace(string(c))))
+}
+
+// postalCodeFirst lists countries whose addresses put the postal code before the city.
+var postalCodeFirst = map[CountryCode]bool{
+	"AT": true, "BE": true, "CH": true, "DE": true, "DK": true, "ES": true, "FI": true,
+	"FR": true, "IL": true, "IT": true, "NL": true, "NO": true, "PL": true,
+	"PT": true, "SE": true,
+}
+
+// IsUS reports whether the address uses the US postal system, including territories
+// and military post offices (see CountryCode.USPostal).
+func (a Address) IsUS() bool {
+	return CountryCode(a.CountryCode).USPostal()
+}
+
+// IsMilitary reports whether the address is an APO, FPO or DPO address, whose state is
+// one of the military codes AA, AE or AP.
+func (a Address) IsMilitary() bool {
+	switch State(strings.ToUpper(strings.TrimSpace(a.State))) {
+	case StateAA, StateAE, StateAP:
+		return a.IsUS()
+	}
+	return false
+}
+
+// Lines returns the address as the lines of a mailing label, following the
+// conventions of its country:
+//
+//	US and territories:  CITY, ST 12345-6789
+//	military:            APO AE 09012
+//	Canada:              TORONTO ON  M5V 2T6, then CANADA
+//	UK:                  LONDON, then SW1A 1AA, then UNITED KINGDOM
+//	most of Europe:      10115 BERLIN, then GERMANY
+//	Mexico:              06600 CIUDAD DE MEXICO, CDMX, then MEXICO
+//	elsewhere:           CITY STATE POSTAL CODE, then the country
+//
+// Foreign addresses end with the country name, or the country code if the record has
+// no name. Empty fields are left out.
+func (a Address) Lines() []string {
+	var lines []string
+	add := func(line string) {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	add(strings.TrimSpace(a.Address1))
+	add(strings.TrimSpace(a.Address2))
+
+	country := CountryCode(a.CountryCode).normalize()
+	city, state := strings.TrimSpace(a.City), strings.TrimSpace(a.State)
+	postal := country.FormatPostalCode(a.PostalCode)
+	switch {
+	case a.IsMilitary():
+		add(joinNonEmpty(" ", city, state, postal))
+		return lines
+	case a.IsUS():
+		if state == "" && usPostalCountries[country] {
+			state = string(country)
+		}
+		add(joinNonEmpty(", ", city, joinNonEmpty(" ", state, postal)))
+		return lines
+	case country == "CA":
+		// Canada Post separates the postal code by two spaces
+		add(joinNonEmpty("  ", joinNonEmpty(" ", city, state), postal))
+	case country == "GB":
+		add(city)
+		add(state)
+		add(postal)
+	case country == "MX":
+		add(joinNonEmpty(", ", joinNonEmpty(" ", postal, city), state))
+	case postalCodeFirst[country]:
+		add(joinNonEmpty(" ", postal, city))
+		add(state)
+	default:
+		add(joinNonEmpty(" ", city, state, postal))
+	}
+
+	name := strings.TrimSpace(a.CountryName)
+	if name == "" {
+		name = string(country)
+	}
+	add(strings.ToUpper(name))
+	return lines
+}
+
+// joinNonEmpty joins the trimmed, non-empty parts with sep.
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// Format returns Lines joined by newlines.
+func (a Address) Format() string {
+	return strings.Join(a.Lines(), "\n")
+}
@@ -0,0 +1,158 @@
+package gonpi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// foreignAddressesJSON is a registry search response in the API's wire format with the
+// kinds of non-US addresses NPPES holds: a Canadian location with the postal code
+// space dropped, a UK mailing address, a German clinic, a Mexican office, an APO
+// mailing address and a Puerto Rico location recorded under its own country code.
+const foreignAddressesJSON = `{
+  "result_count": 2,
+  "results": [
+    {
+      "number": "1234567893",
+      "enumeration_type": "NPI-1",
+      "basic": {"first_name": "JANE", "last_name": "DOE", "status": "A"},
+      "addresses": [
+        {"country_code": "CA", "country_name": "Canada", "address_purpose": "LOCATION", "address_type": "DOM",
+         "address_1": "200 ELIZABETH ST", "address_2": "", "city": "TORONTO", "state": "ON", "postal_code": "m5g2c4",
+         "telephone_number": "416-555-0100"},
+        {"country_code": "GB", "country_name": "United Kingdom", "address_purpose": "MAILING", "address_type": "FGN",
+         "address_1": "10 HARLEY ST", "address_2": "", "city": "LONDON", "state": "", "postal_code": "W1G9PF"}
+      ]
+    },
+    {
+      "number": "1245319599",
+      "enumeration_type": "NPI-2",
+      "basic": {"organization_name": "EXAMPLE HEALTH", "status": "A"},
+      "addresses": [
+        {"country_code": "DE", "country_name": "Germany", "address_purpose": "LOCATION", "address_type": "FGN",
+         "address_1": "CHARITEPLATZ 1", "address_2": "", "city": "BERLIN", "state": "", "postal_code": "10117"},
+        {"country_code": "MX", "country_name": "Mexico", "address_purpose": "LOCATION", "address_type": "FGN",
+         "address_1": "AV INSURGENTES SUR 3700", "address_2": "", "city": "CIUDAD DE MEXICO", "state": "CDMX", "postal_code": "14080"},
+        {"country_code": "US", "country_name": "United States", "address_purpose": "MAILING", "address_type": "DOM",
+         "address_1": "UNIT 3050 BOX 1", "address_2": "", "city": "APO", "state": "AE", "postal_code": "090120001"},
+        {"country_code": "PR", "country_name": "Puerto Rico", "address_purpose": "LOCATION", "address_type": "DOM",
+         "address_1": "1 CALLE DEL PARQUE", "address_2": "STE 2", "city": "SAN JUAN", "state": "", "postal_code": "00907"}
+      ]
+    }
+  ]
+}`
+
+// TestAddress_Lines tests country-aware formatting of the foreign addresses returned
+// by a search with a non-US country code.
+func TestAddress_Lines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("country_code"); got != "CA" {
+			t.Errorf("country_code = %q, want CA", got)
+		}
+		if got := r.URL.Query().Get("state"); got != "ON" {
+			t.Errorf("state = %q, want ON", got)
+		}
+		w.Write([]byte(foreignAddressesJSON))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+	defer client.Close()
+
+	providers, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "DOE", State: "ON", CountryCode: "CA"})
+	if err != nil {
+		t.Fatalf("search with a Canadian province failed: %v", err)
+	}
+	var addresses []Address
+	for _, p := range providers {
+		addresses = append(addresses, p.Addresses...)
+	}
+
+	want := []string{
+		"200 ELIZABETH ST\nTORONTO ON  M5G 2C4\nCANADA",
+		"10 HARLEY ST\nLONDON\nW1G 9PF\nUNITED KINGDOM",
+		"CHARITEPLATZ 1\n10117 BERLIN\nGERMANY",
+		"AV INSURGENTES SUR 3700\n14080 CIUDAD DE MEXICO, CDMX\nMEXICO",
+		"UNIT 3050 BOX 1\nAPO AE 09012-0001",
+		"1 CALLE DEL PARQUE\nSTE 2\nSAN JUAN, PR 00907",
+	}
+	if len(addresses) != len(want) {
+		t.Fatalf("got %d addresses", len(addresses))
+	}
+	for i, a := range addresses {
+		if got := a.Format(); got != want[i] {
+			t.Errorf("address %d:\n%s\nwant:\n%s", i, got, want[i])
+		}
+	}
+
+	if !addresses[4].IsMilitary() || !addresses[4].IsUS() || !addresses[5].IsUS() || addresses[0].IsUS() {
+		t.Error("unexpected IsUS or IsMilitary results")
+	}
+}
+
+// TestValidUSPostalCode tests ZIP code formats.
+func TestValidUSPostalCode(t *testing.T) {
+	for code, want := range map[string]bool{
+		"02139": true, "021391234": true, "02139-1234": true,
+		"0213": false, "021-391234": false, "0213A": false, "021391234-": false,
+	} {
+		if got := validUSPostalCode(code); got != want {
+			t.Errorf("validUSPostalCode(%q) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+// TestCountryCode_ValidPostalCode tests postal code validation and formatting per country.
+func TestCountryCode_ValidPostalCode(t *testing.T) {
+	tests := []struct {
+		country   CountryCode
+		code      string
+		valid     bool
+		formatted string
+	}{
+		{"", "021391234", true, "02139-1234"},
+		{"US", "02139", true, "02139"},
+		{"PR", "00907", true, "00907"},
+		{"US", "M5G 2C4", false, "M5G 2C4"},
+		{"CA", "m5g2c4", true, "M5G 2C4"},
+		{"CA", "12345", false, "12345"},
+		{"GB", "sw1a1aa", true, "SW1A 1AA"},
+		{"GB", "W1G 9PF", true, "W1G 9PF"},
+		{"DE", "10117", true, "10117"},
+		{"DE", "1011", false, "1011"},
+		{"NL", "1012ab", true, "1012 AB"},
+		{"JP", "1000001", true, "100-0001"},
+		{"BR", "01310-100", true, "01310-100"},
+		{"MX", "14080", true, "14080"},
+		{"KY", "KY1-1104", true, "KY1-1104"},
+		{"KY", "KY1/1104", false, "KY1/1104"},
+	}
+	for _, tt := range tests {
+		if got := tt.country.ValidPostalCode(tt.code); got != tt.valid {
+			t.Errorf("%s %q: valid = %v, want %v", tt.country, tt.code, got, tt.valid)
+		}
+		if got := tt.country.FormatPostalCode(tt.code); got != tt.formatted {
+			t.Errorf("%s %q: formatted = %q, want %q", tt.country, tt.code, got, tt.formatted)
+		}
+	}
+}
+
+// TestLinter_ForeignPostalCodes tests that the postal code rule checks foreign formats.
+func TestLinter_ForeignPostalCodes(t *testing.T) {
+	p := Provider{
+		Number:          "1234567893",
+		EnumerationType: "NPI-2",
+		Basic:           BasicInfo{OrganizationName: "EXAMPLE HEALTH", Status: "A"},
+		Addresses: []Address{
+			{AddressPurpose: "LOCATION", Address1: "CHARITEPLATZ 1", City: "BERLIN", PostalCode: "1011", CountryCode: "DE"},
+			{AddressPurpose: "MAILING", Address1: "200 ELIZABETH ST", City: "TORONTO", State: "ON", PostalCode: "M5G2C4", CountryCode: "CA"},
+		},
+		Taxonomies: []Taxonomy{{Code: "261QP2300X", Primary: true}},
+	}
+	findings := NewLinter().Lint(p)
+	if len(findings) != 1 || findings[0].Rule != RuleInvalidPostalCode || !strings.Contains(findings[0].Message, `"1011" is not valid for DE`) {
+		t.Errorf("findings = %v", findings)
+	}
+}
@@ -0,0 +1,146 @@
+package gonpi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Column names of the CMS Doctors and Clinicians Facility Affiliation file.
+const (
+	facilityTypeColumn = "facility_type"
+	facilityCCNColumn  = "Facility Affiliations Certification Number"
+	parentCCNColumn    = "Facility Type Certification Number"
+)
+
+// HospitalAffiliation is a facility a provider is affiliated with.
+type HospitalAffiliation struct {
+	// CCN is the facility's CMS Certification Number.
+	CCN string `json:"ccn"`
+
+	// Name is the facility name, if a facility name table was loaded.
+	Name string `json:"name,omitempty"`
+
+	// FacilityType is the facility type, e.g. "Hospital" or "Skilled nursing facility".
+	FacilityType string `json:"facility_type"`
+
+	// ParentCCN is the certification number of the parent facility for
+	// units such as inpatient rehabilitation, if present.
+	ParentCCN string `json:"parent_ccn,omitempty"`
+}
+
+// Affiliations is an in-memory index of provider facility affiliations loaded from the
+// CMS Facility Affiliation file. It is safe for concurrent use.
+type Affiliations struct {
+	mu    sync.RWMutex
+	byNPI map[string][]HospitalAffiliation
+	names map[string]string
+}
+
+// LoadAffiliations reads the CMS Doctors and Clinicians Facility Affiliation file.
+// Duplicate NPI/CCN pairs are collapsed.
+func LoadAffiliations(r io.Reader) (*Affiliations, error) {
+	records, err := NewCSVRecordReader(r, NationalDownloadableFileNPIColumn, facilityTypeColumn, facilityCCNColumn, parentCCNColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Affiliations{
+		byNPI: make(map[string][]HospitalAffiliation),
+		names: make(map[string]string),
+	}
+	seen := make(map[string]bool)
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ccn := strings.TrimSpace(record.Fields[facilityCCNColumn])
+		if ccn == "" || seen[record.NPI+"|"+ccn] {
+			continue
+		}
+		seen[record.NPI+"|"+ccn] = true
+		a.byNPI[record.NPI] = append(a.byNPI[record.NPI], HospitalAffiliation{
+			CCN:          ccn,
+			FacilityType: strings.TrimSpace(record.Fields[facilityTypeColumn]),
+			ParentCCN:    strings.TrimSpace(record.Fields[parentCCNColumn]),
+		})
+	}
+	return a, nil
+}
+
+// LoadFacilityNames reads facility names from a file with a CCN column, such as the CMS
+// Hospital General Information file ("Facility ID" and "Facility Name" columns), so that
+// affiliations carry names. It can be called more than once with different files.
+func (a *Affiliations) LoadFacilityNames(r io.Reader) error {
+	reader, cols, err := newHeaderReader(r)
+	if err != nil {
+		return err
+	}
+	idCol := firstColumn(cols, "facility id", "ccn", "cms certification number (ccn)", "provider id")
+	nameCol := firstColumn(cols, "facility name", "provider name", "name")
+	if idCol < 0 || nameCol < 0 {
+		return errors.New("missing facility ID or name column")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read facility names: %w", err)
+		}
+		if idCol < len(row) && nameCol < len(row) {
+			a.names[strings.TrimSpace(row[idCol])] = strings.TrimSpace(row[nameCol])
+		}
+	}
+}
+
+// Affiliations returns the facilities the NPI is affiliated with, or nil if none.
+func (a *Affiliations) Affiliations(npi string) []HospitalAffiliation {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	affiliations := a.byNPI[npi]
+	if len(affiliations) == 0 {
+		return nil
+	}
+	results := make([]HospitalAffiliation, len(affiliations))
+	for i, affiliation := range affiliations {
+		affiliation.Name = a.names[affiliation.CCN]
+		results[i] = affiliation
+	}
+	return results
+}
+
+// Len returns the number of NPIs with at least one affiliation.
+func (a *Affiliations) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.byNPI)
+}
+
+// EnrichAffiliations attaches facility affiliations to the provider.
+// Results replace provider.Extensions.Affiliations.
+func EnrichAffiliations(provider *Provider, affiliations *Affiliations) error {
+	if provider == nil {
+		return &ValidationError{Field: "provider", Message: "provider cannot be nil"}
+	}
+	if affiliations == nil {
+		return errors.New("no affiliation table configured")
+	}
+
+	if provider.Extensions == nil {
+		provider.Extensions = &Extensions{}
+	}
+	provider.Extensions.Affiliations = affiliations.Affiliations(provider.Number)
+	return nil
+}
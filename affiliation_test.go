@@ -0,0 +1,56 @@
+package gonpi
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAffiliations tests loading affiliations and facility names.
+func TestAffiliations(t *testing.T) {
+	data := "NPI,Ind_PAC_ID,Provider Last Name,Provider First Name,Provider Middle Name,suff,facility_type,Facility Affiliations Certification Number,Facility Type Certification Number\n" +
+		"1234567890,111,DOE,JOHN,,,Hospital,050001,\n" +
+		"1234567890,111,DOE,JOHN,,,Hospital,050001,\n" +
+		"1234567890,111,DOE,JOHN,,,Inpatient rehabilitation facility,05T001,050001\n" +
+		"0987654321,222,ROE,JANE,,,Skilled nursing facility,055001,\n"
+
+	affiliations, err := LoadAffiliations(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if affiliations.Len() != 2 {
+		t.Errorf("expected 2 NPIs, got %d", affiliations.Len())
+	}
+
+	names := "Facility ID,Facility Name,Address,City/Town,State\n050001,GENERAL HOSPITAL,1 MAIN ST,ANYTOWN,CA\n"
+	if err := affiliations.LoadFacilityNames(strings.NewReader(names)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := affiliations.Affiliations("1234567890")
+	if len(got) != 2 {
+		t.Fatalf("expected 2 affiliations, got %d: %+v", len(got), got)
+	}
+	if got[0].CCN != "050001" || got[0].Name != "GENERAL HOSPITAL" || got[0].FacilityType != "Hospital" {
+		t.Errorf("unexpected first affiliation: %+v", got[0])
+	}
+	if got[1].ParentCCN != "050001" {
+		t.Errorf("expected parent CCN 050001, got %+v", got[1])
+	}
+
+	if affiliations.Affiliations("5555555555") != nil {
+		t.Error("expected nil for unknown NPI")
+	}
+}
+
+// TestEnrichAffiliations tests attaching affiliations to a provider.
+func TestEnrichAffiliations(t *testing.T) {
+	affiliations, _ := LoadAffiliations(strings.NewReader("NPI,facility_type,Facility Affiliations Certification Number\n1234567890,Hospital,050001\n"))
+
+	provider := mockProvider()
+	if err := EnrichAffiliations(&provider, affiliations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(provider.Extensions.Affiliations) != 1 {
+		t.Errorf("expected 1 affiliation, got %+v", provider.Extensions.Affiliations)
+	}
+}
@@ -0,0 +1,128 @@
+package gonpi
+
+import (
+	"sort"
+	"strings"
+)
+
+// CityCount is the number of providers practicing in a city.
+type CityCount struct {
+	City  string `json:"city"`
+	State string `json:"state"`
+	Count int    `json:"count"`
+}
+
+// CountByTaxonomy returns the number of stored providers per taxonomy code, counting
+// each provider once per distinct code. If state is not empty, only providers with a
+// practice address in that state are counted.
+func (s *MemoryStore) CountByTaxonomy(state string) map[string]int {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	counts := make(map[string]int)
+	s.scan(func(provider *Provider) {
+		if state != "" && !practicesInState(provider, state) {
+			return
+		}
+		for _, code := range uniqueKeys(taxonomyKeys(provider)) {
+			counts[code]++
+		}
+	})
+	return counts
+}
+
+// CountByState returns the number of stored providers per practice state, counting
+// each provider once per distinct state. If taxonomyCode is not empty, only providers
+// listing that taxonomy are counted.
+func (s *MemoryStore) CountByState(taxonomyCode string) map[string]int {
+	counts := make(map[string]int)
+	s.scan(func(provider *Provider) {
+		if !hasTaxonomy(provider, taxonomyCode) {
+			return
+		}
+		var states []string
+		for _, addr := range practiceAddresses(provider) {
+			states = append(states, strings.ToUpper(strings.TrimSpace(addr.State)))
+		}
+		for _, st := range uniqueKeys(states) {
+			counts[st]++
+		}
+	})
+	return counts
+}
+
+// TopCities returns up to n practice cities with the most stored providers, ordered by
+// count and then by state and city. taxonomyCode and state restrict the providers and
+// cities considered when not empty; n <= 0 returns every city. City names are compared
+// case-insensitively and reported uppercased.
+func (s *MemoryStore) TopCities(taxonomyCode, state string, n int) []CityCount {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	counts := make(map[CityCount]int)
+	s.scan(func(provider *Provider) {
+		if !hasTaxonomy(provider, taxonomyCode) {
+			return
+		}
+		seen := make(map[CityCount]bool)
+		for _, addr := range practiceAddresses(provider) {
+			key := CityCount{
+				City:  strings.ToUpper(strings.TrimSpace(addr.City)),
+				State: strings.ToUpper(strings.TrimSpace(addr.State)),
+			}
+			if key.City == "" || (state != "" && key.State != state) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[key]++
+		}
+	})
+
+	cities := make([]CityCount, 0, len(counts))
+	for key, count := range counts {
+		key.Count = count
+		cities = append(cities, key)
+	}
+	sort.Slice(cities, func(i, j int) bool {
+		if cities[i].Count != cities[j].Count {
+			return cities[i].Count > cities[j].Count
+		}
+		if cities[i].State != cities[j].State {
+			return cities[i].State < cities[j].State
+		}
+		return cities[i].City < cities[j].City
+	})
+	if n > 0 && len(cities) > n {
+		cities = cities[:n]
+	}
+	return cities
+}
+
+// scan calls fn for every stored provider under the read lock.
+func (s *MemoryStore) scan(fn func(provider *Provider)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, provider := range s.providers {
+		fn(provider)
+	}
+}
+
+func practicesInState(provider *Provider, state string) bool {
+	for _, addr := range practiceAddresses(provider) {
+		if strings.EqualFold(strings.TrimSpace(addr.State), state) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTaxonomy reports whether provider lists code; an empty code matches everyone.
+func hasTaxonomy(provider *Provider, code string) bool {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return true
+	}
+	for _, key := range taxonomyKeys(provider) {
+		if key == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package gonpi
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func aggregateStore() *MemoryStore {
+	store := NewMemoryStore()
+	add := func(number, code, city, state string) {
+		p := mockProvider()
+		p.Number = number
+		p.Taxonomies = []Taxonomy{{Code: code, Primary: true}}
+		p.Addresses = []Address{
+			{AddressPurpose: "LOCATION", City: city, State: state},
+			{AddressPurpose: "MAILING", City: "PO BOX CITY", State: "DE"},
+		}
+		store.Put(context.Background(), p)
+	}
+	add("1", "207Q00000X", "Boston", "MA")
+	add("2", "207Q00000X", "BOSTON", "MA")
+	add("3", "207Q00000X", "Worcester", "MA")
+	add("4", "207RC0000X", "Boston", "MA")
+	add("5", "207Q00000X", "Nashua", "NH")
+	return store
+}
+
+// TestMemoryStore_CountByTaxonomy tests taxonomy counts with and without a state filter.
+func TestMemoryStore_CountByTaxonomy(t *testing.T) {
+	store := aggregateStore()
+
+	if got, want := store.CountByTaxonomy("ma"), map[string]int{"207Q00000X": 3, "207RC0000X": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CountByTaxonomy(MA) = %v, want %v", got, want)
+	}
+	if got := store.CountByTaxonomy(""); got["207Q00000X"] != 4 {
+		t.Errorf("CountByTaxonomy() = %v", got)
+	}
+}
+
+// TestMemoryStore_CountByState tests state counts over practice addresses only.
+func TestMemoryStore_CountByState(t *testing.T) {
+	store := aggregateStore()
+
+	if got, want := store.CountByState("207Q00000X"), map[string]int{"MA": 3, "NH": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CountByState() = %v, want %v", got, want)
+	}
+}
+
+// TestMemoryStore_TopCities tests ranking of practice cities.
+func TestMemoryStore_TopCities(t *testing.T) {
+	store := aggregateStore()
+
+	got := store.TopCities("207Q00000X", "MA", 5)
+	want := []CityCount{{City: "BOSTON", State: "MA", Count: 2}, {City: "WORCESTER", State: "MA", Count: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopCities() = %+v, want %+v", got, want)
+	}
+
+	if got := store.TopCities("", "", 1); len(got) != 1 || got[0].City != "BOSTON" || got[0].Count != 3 {
+		t.Errorf("TopCities(n=1) = %+v", got)
+	}
+}
@@ -0,0 +1,206 @@
+package gonpi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedactedValue replaces the values of query parameters redacted with
+// WithAuditRedaction.
+const RedactedValue = "REDACTED"
+
+// AuditRecord describes one outbound HTTP request, including each retry attempt and
+// requests made through Client.Transport.
+type AuditRecord struct {
+	// Time is when the request was sent.
+	Time time.Time `json:"time"`
+
+	// Method is the HTTP method.
+	Method string `json:"method"`
+
+	// URL is the request URL, with redacted query parameters replaced.
+	URL string `json:"url"`
+
+	// Params are the query parameters, with multiple values joined by commas.
+	Params map[string]string `json:"params,omitempty"`
+
+	// Status is the response status code, or 0 if no response was received.
+	Status int `json:"status"`
+
+	// Duration is how long the request took until response headers arrived.
+	Duration time.Duration `json:"duration_ns"`
+
+	// Error describes a transport failure, with redacted query parameters replaced in
+	// the URL it names.
+	Error string `json:"error,omitempty"`
+
+	// Caller, Tenant and Priority come from the request's CallMetadata.
+	Caller   string `json:"caller,omitempty"`
+	Tenant   string `json:"tenant,omitempty"`
+	Priority string `json:"priority,omitempty"`
+}
+
+// AuditSink receives a record of every outbound request. Implementations must be safe
+// for concurrent use and should not block, since they run on the request path.
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+// AuditFunc adapts a function to the AuditSink interface.
+type AuditFunc func(record AuditRecord)
+
+// Audit calls f(record).
+func (f AuditFunc) Audit(record AuditRecord) {
+	f(record)
+}
+
+// WithAuditLog records every outbound request to sink.
+//
+// Example usage:
+//
+//	client := NewClient(
+//	    WithAuditLog(NewJSONAuditSink(auditFile)),
+//	    WithAuditRedaction("first_name", "last_name"),
+//	)
+func WithAuditLog(sink AuditSink) ClientOption {
+	return func(c *Client) {
+		c.audit = sink
+	}
+}
+
+// WithAuditRedaction replaces the values of the named query parameters with
+// RedactedValue in audit records and slow request reports, both in Params and in URL.
+func WithAuditRedaction(params ...string) ClientOption {
+	return func(c *Client) {
+		redact := make(map[string]bool, len(c.auditRedact)+len(params))
+		for name := range c.auditRedact {
+			redact[name] = true
+		}
+		for _, name := range params {
+			redact[name] = true
+		}
+		c.auditRedact = redact
+	}
+}
+
+// recordAudit reports a sent request to the audit sink, if one is configured.
+func (c *Client) recordAudit(req *http.Request, resp *http.Response, err error, start time.Time, duration time.Duration) {
+	if c.audit == nil {
+		return
+	}
+
+	record := AuditRecord{
+		Time:     start,
+		Method:   req.Method,
+		Duration: duration,
+	}
+	record.URL, record.Params = c.redactURL(req.URL)
+	if resp != nil {
+		record.Status = resp.StatusCode
+	}
+	if err != nil {
+		record.Error = c.redactError(err).Error()
+	}
+	if md, ok := CallMetadataFromContext(req.Context()); ok || c.priority != PriorityNormal {
+		record.Caller = md.Caller
+		record.Tenant = md.Tenant
+		record.Priority = c.priorityFor(md).String()
+	}
+	c.audit.Audit(record)
+}
+
+// redactURL returns u and its query parameters, with multiple values joined by commas,
+// after replacing the parameters named by WithAuditRedaction.
+func (c *Client) redactURL(u *url.URL) (string, map[string]string) {
+	query := u.Query()
+	for name := range query {
+		if c.auditRedact[name] {
+			query[name] = []string{RedactedValue}
+		}
+	}
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+
+	var params map[string]string
+	if len(query) > 0 {
+		params = make(map[string]string, len(query))
+		for name, values := range query {
+			params[name] = strings.Join(values, ",")
+		}
+	}
+	return redacted.String(), params
+}
+
+// JSONAuditSink is an AuditSink writing one JSON object per line to an io.Writer,
+// such as an append-only file.
+type JSONAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONAuditSink creates a JSONAuditSink writing to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{enc: json.NewEncoder(w)}
+}
+
+// Audit writes record as a JSON line. Write errors are kept and reported by Err.
+func (s *JSONAuditSink) Audit(record AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(record); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Err returns the first error encountered writing records.
+func (s *JSONAuditSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// redactError returns err with the URL named by its *url.Error, as in
+// `Get "https://...": dial tcp: ...`, replaced by the redactURL form, so error
+// messages do not leak the parameters redacted with WithAuditRedaction. The result
+// unwraps to a copy of the *url.Error holding the redacted URL; errors without one are
+// returned unchanged.
+func (c *Client) redactError(err error) error {
+	var urlErr *url.Error
+	if len(c.auditRedact) == 0 || !errors.As(err, &urlErr) {
+		return err
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return err
+	}
+	redacted, _ := c.redactURL(u)
+	if redacted == urlErr.URL {
+		return err
+	}
+	return &redactedError{
+		msg: strings.ReplaceAll(err.Error(), urlErr.URL, redacted),
+		err: &url.Error{Op: urlErr.Op, URL: redacted, Err: urlErr.Err},
+	}
+}
+
+// redactedError is an error whose message and *url.Error have a redacted URL.
+type redactedError struct {
+	msg string
+	err *url.Error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the redacted *url.Error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
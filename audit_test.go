@@ -0,0 +1,109 @@
+package gonpi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestAuditLog tests that every attempt is recorded with redaction and caller metadata.
+func TestAuditLog(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse(nil))
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var records []AuditRecord
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetry(fastRetry),
+		WithAuditLog(AuditFunc(func(r AuditRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			records = append(records, r)
+		})),
+		WithAuditRedaction("last_name"),
+	)
+
+	ctx := ContextWithCallMetadata(context.Background(), CallMetadata{Caller: "claims-intake", Tenant: "acme"})
+	if _, err := client.SearchProviders(ctx, SearchOptions{LastName: "Doe", State: "MA"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 audit records, got %d", len(records))
+	}
+	if records[0].Status != http.StatusServiceUnavailable || records[1].Status != http.StatusOK {
+		t.Errorf("statuses = %d, %d", records[0].Status, records[1].Status)
+	}
+	r := records[1]
+	if r.Params["last_name"] != RedactedValue || r.Params["state"] != "MA" {
+		t.Errorf("params = %v", r.Params)
+	}
+	if strings.Contains(r.URL, "Doe") || !strings.Contains(r.URL, "last_name="+RedactedValue) {
+		t.Errorf("URL not redacted: %s", r.URL)
+	}
+	if r.Method != http.MethodGet || r.Time.IsZero() || r.Duration <= 0 {
+		t.Errorf("incomplete record %+v", r)
+	}
+	if r.Caller != "claims-intake" || r.Tenant != "acme" || r.Priority != "normal" {
+		t.Errorf("caller metadata = %q %q %q", r.Caller, r.Tenant, r.Priority)
+	}
+}
+
+// TestAuditLog_TransportError tests that transport errors do not leak redacted parameters.
+func TestAuditLog_TransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	var records []AuditRecord
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{}),
+		WithAuditLog(AuditFunc(func(r AuditRecord) { records = append(records, r) })),
+		WithAuditRedaction("last_name"),
+	)
+	if _, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Doe", State: "MA"}); err == nil {
+		t.Fatal("expected transport error")
+	}
+	if len(records) == 0 {
+		t.Fatal("expected an audit record")
+	}
+	for _, r := range records {
+		if r.Error == "" || strings.Contains(r.Error, "Doe") || !strings.Contains(r.Error, "last_name="+RedactedValue) {
+			t.Errorf("error not redacted: %q", r.Error)
+		}
+	}
+}
+
+// TestJSONAuditSink tests that records are written as JSON lines.
+func TestJSONAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONAuditSink(&buf)
+
+	client := NewClient(WithBaseURL("http://127.0.0.1:1"), WithRetry(RetryConfig{}), WithAuditLog(sink))
+	client.GetProviderByNPI(context.Background(), "1234567890")
+
+	var record AuditRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid audit line %q: %v", buf.String(), err)
+	}
+	if record.Status != 0 || record.Error == "" || record.Params["number"] != "1234567890" {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if sink.Err() != nil {
+		t.Errorf("unexpected sink error: %v", sink.Err())
+	}
+}
@@ -0,0 +1,267 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrBatchAborted is joined into the error returned by a batch operation that stopped
+// early because its failure budget was exhausted.
+var ErrBatchAborted = errors.New("batch aborted after too many failures")
+
+// BatchOption configures the failure semantics of a batch operation such as
+// GetProvidersByNPIs.
+type BatchOption func(*batchConfig)
+
+// batchConfig holds the settings applied by BatchOptions.
+type batchConfig struct {
+	// maxFailures is the number of failures after which outstanding work is
+	// cancelled. Zero means the batch continues through all failures.
+	maxFailures int64
+
+	// concurrency is the number of calls run at once.
+	concurrency int
+}
+
+// defaultBatchConcurrency is the number of calls a batch runs at once by default.
+const defaultBatchConcurrency = 5
+
+// newBatchConfig applies opts to the default configuration.
+func newBatchConfig(opts []BatchOption) batchConfig {
+	config := batchConfig{concurrency: defaultBatchConcurrency}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+// WithBatchContinueOnError makes the batch attempt every item regardless of failures.
+// This is the default behavior.
+func WithBatchContinueOnError() BatchOption {
+	return func(c *batchConfig) {
+		c.maxFailures = 0
+	}
+}
+
+// WithBatchFailFast cancels outstanding work on the first failure, errgroup-style.
+func WithBatchFailFast() BatchOption {
+	return WithBatchMaxFailures(1)
+}
+
+// WithBatchMaxFailures cancels outstanding work once n items have failed.
+// Values less than 1 restore the default continue-on-error behavior.
+func WithBatchMaxFailures(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n < 1 {
+			n = 0
+		}
+		c.maxFailures = int64(n)
+	}
+}
+
+// WithBatchConcurrency sets the number of calls the batch runs at once. Values less
+// than 1 restore the default of 5.
+func WithBatchConcurrency(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n < 1 {
+			n = defaultBatchConcurrency
+		}
+		c.concurrency = n
+	}
+}
+
+// exhausted reports whether failures has reached the failure budget.
+func (c batchConfig) exhausted(failures int64) bool {
+	return c.maxFailures > 0 && failures >= c.maxFailures
+}
+
+// aborted reports whether the batch should stop scheduling work.
+func (c batchConfig) aborted(failures *atomic.Int64) bool {
+	return c.exhausted(failures.Load())
+}
+
+// normalizeNPIs applies NormalizeNPI to each input. Invalid inputs are left empty in
+// normalized and their validation errors are returned in errs.
+func normalizeNPIs(npis []string) (normalized []string, errs []error) {
+	normalized = make([]string, len(npis))
+	for i, npi := range npis {
+		clean, err := NormalizeNPI(npi)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		normalized[i] = clean
+	}
+	return normalized, errs
+}
+
+// BatchItem is the outcome of a single input of an ordered batch operation.
+type BatchItem struct {
+	// NPI is the input NPI at this position, as given by the caller.
+	NPI string
+
+	// Provider is the fetched provider, or nil if it was not found or the lookup failed.
+	Provider *Provider
+
+	// Err is the lookup error for this item, or ErrBatchAborted if the item was skipped
+	// because the batch stopped early.
+	Err error
+}
+
+// GetProvidersByNPIsOrdered retrieves multiple providers by NPI number and returns one
+// BatchItem per input, in input order. Inputs are cleaned up with NormalizeNPI, so
+// duplicates in different formats each get their own item but are fetched from the
+// API only once.
+//
+// The returned error joins every failed item's error (and ErrBatchAborted if the batch
+// stopped early); per-item errors are also available on each BatchItem.
+func (c *Client) GetProvidersByNPIsOrdered(ctx context.Context, npis []string, opts ...BatchOption) ([]BatchItem, error) {
+	ctx, span := c.tracer.Start(ctx, "GetProvidersByNPIsOrdered",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.Int("npi_count", len(npis)),
+		)...),
+	)
+	defer span.End()
+
+	if len(npis) == 0 {
+		err := &ValidationError{Field: "npis", Message: "npi list cannot be empty"}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	normalized, errs := normalizeNPIs(npis)
+	unique := uniqueNPIs(normalized)
+	outcomes, aborted := c.runBatch(ctx, unique, newBatchConfig(opts))
+
+	items := make([]BatchItem, len(npis))
+	for i, npi := range npis {
+		items[i].NPI = npi
+		if normalized[i] == "" {
+			_, items[i].Err = NormalizeNPI(npi)
+			continue
+		}
+		outcome, ok := outcomes[normalized[i]]
+		items[i].Provider, items[i].Err = outcome.provider, outcome.err
+		if !ok {
+			items[i].Err = ErrBatchAborted
+		}
+	}
+
+	for _, npi := range unique {
+		if err := outcomes[npi].err; err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch NPI %s: %w", npi, err))
+		}
+	}
+
+	span.SetAttributes(c.traceAttrs(
+		attribute.Int("unique_npis", len(unique)),
+		attribute.Int("failed_fetches", len(errs)),
+	)...)
+
+	if aborted {
+		errs = append(errs, ErrBatchAborted)
+	}
+	if len(errs) > 0 {
+		err := errors.Join(errs...)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "partial batch failure")
+		return items, err
+	}
+	return items, nil
+}
+
+// batchOutcome is the result of fetching a single NPI within a batch.
+type batchOutcome struct {
+	provider *Provider
+	err      error
+}
+
+// uniqueNPIs returns the non-empty npis with duplicates removed, preserving first
+// occurrence order.
+func uniqueNPIs(npis []string) []string {
+	seen := make(map[string]bool, len(npis))
+	unique := make([]string, 0, len(npis))
+	for _, npi := range npis {
+		if npi != "" && !seen[npi] {
+			seen[npi] = true
+			unique = append(unique, npi)
+		}
+	}
+	return unique
+}
+
+// runBatch fetches each NPI concurrently, limiting the number of in-flight requests.
+// A lookup that panics fails with a *PanicError like any other error.
+// NPIs skipped because the failure budget was exhausted have no entry in the returned
+// map, and aborted is true.
+func (c *Client) runBatch(ctx context.Context, npis []string, config batchConfig) (outcomes map[string]batchOutcome, aborted bool) {
+	// Admit the whole batch, so that a draining client finishes its lookups; after
+	// Close they fail one by one with ErrClientClosed
+	ctx, done, _ := c.background.enter(ctx)
+	defer done()
+
+	// Backend cache writes are buffered and flushed together
+	ctx, flush := c.withCacheWriteBuffer(ctx)
+	defer flush(ctx)
+
+	// Cancel outstanding lookups once the failure budget is exhausted
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var resultMap sync.Map
+	var wg sync.WaitGroup
+	var failures atomic.Int64
+
+	// Limit concurrent requests to avoid overwhelming the API
+	semaphore := make(chan struct{}, config.concurrency)
+
+	for _, npi := range npis {
+		wg.Add(1)
+		go func(npi string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if config.aborted(&failures) {
+				return
+			}
+
+			var provider *Provider
+			err := safeCall(func() (err error) {
+				provider, err = c.GetProviderByNPI(ctx, npi)
+				return err
+			})
+			if err != nil {
+				if config.aborted(&failures) {
+					// Lookups cancelled by the abort are not failures of their own
+					return
+				}
+				resultMap.Store(npi, batchOutcome{err: err})
+				if config.exhausted(failures.Add(1)) {
+					cancel()
+				}
+				return
+			}
+			resultMap.Store(npi, batchOutcome{provider: provider})
+		}(npi)
+	}
+
+	wg.Wait()
+
+	// Collect results from sync.Map
+	outcomes = make(map[string]batchOutcome, len(npis))
+	resultMap.Range(func(key, value any) bool {
+		outcomes[key.(string)] = value.(batchOutcome)
+		return true
+	})
+	return outcomes, config.aborted(&failures)
+}
@@ -0,0 +1,213 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newBatchTestServer returns a server that fails lookups for NPIs starting with "9".
+func newBatchTestServer(requests *atomic.Int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		npi := r.URL.Query().Get("number")
+		if npi[0] == '9' {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		provider := mockProvider()
+		provider.Number = npi
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{provider}))
+	}))
+}
+
+// batchTestNPIs returns n NPIs where the first is failing and the rest succeed.
+func batchTestNPIs(n int) []string {
+	npis := []string{"9000000000"}
+	for i := 1; i < n; i++ {
+		npis = append(npis, fmt.Sprintf("10000000%02d", i))
+	}
+	return npis
+}
+
+// TestGetProvidersByNPIs_ContinueOnError tests the default continue-through-failures mode.
+func TestGetProvidersByNPIs_ContinueOnError(t *testing.T) {
+	var requests atomic.Int64
+	server := newBatchTestServer(&requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	npis := append(batchTestNPIs(20), "9111111111")
+
+	results, err := client.GetProvidersByNPIs(context.Background(), npis, WithBatchContinueOnError())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if errors.Is(err, ErrBatchAborted) {
+		t.Error("did not expect ErrBatchAborted in continue mode")
+	}
+	if len(results) != 19 {
+		t.Errorf("expected 19 results, got %d", len(results))
+	}
+	if requests.Load() != 21 {
+		t.Errorf("expected 21 requests, got %d", requests.Load())
+	}
+}
+
+// TestGetProvidersByNPIs_FailFast tests cancelling outstanding work on the first failure.
+func TestGetProvidersByNPIs_FailFast(t *testing.T) {
+	var requests atomic.Int64
+	server := newBatchTestServer(&requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	npis := batchTestNPIs(50)
+
+	_, err := client.GetProvidersByNPIs(context.Background(), npis, WithBatchFailFast())
+	if !errors.Is(err, ErrBatchAborted) {
+		t.Fatalf("expected ErrBatchAborted, got %v", err)
+	}
+	if !errors.As(err, new(*APIError)) {
+		t.Errorf("expected the triggering failure to be reported, got %v", err)
+	}
+}
+
+// TestGetProvidersByNPIs_MaxFailures tests stopping after N failures.
+func TestGetProvidersByNPIs_MaxFailures(t *testing.T) {
+	var requests atomic.Int64
+	server := newBatchTestServer(&requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	npis := append(batchTestNPIs(5), "9111111111")
+
+	// One failure is tolerated with a budget of 3
+	results, err := client.GetProvidersByNPIs(context.Background(), npis, WithBatchMaxFailures(3))
+	if err == nil || errors.Is(err, ErrBatchAborted) {
+		t.Fatalf("expected failures without abort, got %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("expected 4 results, got %d", len(results))
+	}
+
+	_, err = client.GetProvidersByNPIs(context.Background(), npis, WithBatchMaxFailures(2))
+	if !errors.Is(err, ErrBatchAborted) {
+		t.Errorf("expected ErrBatchAborted, got %v", err)
+	}
+}
+
+// TestGetProvidersByNPIsOrdered tests input-aligned results with deduplicated requests.
+func TestGetProvidersByNPIsOrdered(t *testing.T) {
+	var requests atomic.Int64
+	server := newBatchTestServer(&requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	npis := []string{"1000000001", "9000000000", "1000000002", "1000000001"}
+
+	items, err := client.GetProvidersByNPIsOrdered(context.Background(), npis)
+	if err == nil {
+		t.Fatal("expected error for failing NPI")
+	}
+	if len(items) != len(npis) {
+		t.Fatalf("expected %d items, got %d", len(npis), len(items))
+	}
+	for i, item := range items {
+		if item.NPI != npis[i] {
+			t.Errorf("item %d: expected NPI %s, got %s", i, npis[i], item.NPI)
+		}
+	}
+	if items[0].Provider == nil || items[3].Provider == nil || items[0].Provider != items[3].Provider {
+		t.Error("expected duplicate NPIs to share the fetched provider")
+	}
+	if items[1].Err == nil || items[1].Provider != nil {
+		t.Errorf("expected failing item to carry its error, got %+v", items[1])
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 upstream requests, got %d", requests.Load())
+	}
+}
+
+// TestGetProvidersByNPIs_Dedup tests that duplicate NPIs are fetched once.
+func TestGetProvidersByNPIs_Dedup(t *testing.T) {
+	var requests atomic.Int64
+	server := newBatchTestServer(&requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	results, err := client.GetProvidersByNPIs(context.Background(), []string{"1000000001", "1000000001", "1000000002"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || requests.Load() != 2 {
+		t.Errorf("expected 2 results from 2 requests, got %d from %d", len(results), requests.Load())
+	}
+}
+
+// TestGetProvidersByNPIsOrdered_Aborted tests that skipped items report ErrBatchAborted.
+func TestGetProvidersByNPIsOrdered_Aborted(t *testing.T) {
+	var requests atomic.Int64
+	server := newBatchTestServer(&requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	items, err := client.GetProvidersByNPIsOrdered(context.Background(), batchTestNPIs(30), WithBatchFailFast())
+	if !errors.Is(err, ErrBatchAborted) {
+		t.Fatalf("expected ErrBatchAborted, got %v", err)
+	}
+	for _, item := range items {
+		if item.Provider == nil && item.Err == nil {
+			t.Errorf("item %s has neither provider nor error", item.NPI)
+		}
+	}
+}
+
+// panickingTransport is an http.RoundTripper that panics for NPIs starting with "9"
+// and otherwise forwards to the wrapped transport.
+type panickingTransport struct {
+	next http.RoundTripper
+}
+
+func (t panickingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Query().Get("number")[0] == '9' {
+		panic("transport exploded")
+	}
+	return t.next.RoundTrip(r)
+}
+
+// TestGetProvidersByNPIs_Panic tests that a panicking lookup fails only its own item.
+func TestGetProvidersByNPIs_Panic(t *testing.T) {
+	var requests atomic.Int64
+	server := newBatchTestServer(&requests)
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: panickingTransport{next: http.DefaultTransport}}
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(httpClient), WithRetry(RetryConfig{}))
+
+	// More panicking items than semaphore slots, so leaked slots would deadlock
+	npis := batchTestNPIs(5)
+	for i := 0; i < 10; i++ {
+		npis = append(npis, fmt.Sprintf("90000000%02d", i+1))
+	}
+	items, err := client.GetProvidersByNPIsOrdered(context.Background(), npis)
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected PanicError, got %v", err)
+	}
+	if panicErr.Value != "transport exploded" || len(panicErr.Stack) == 0 {
+		t.Errorf("unexpected panic error %v with %d byte stack", panicErr.Value, len(panicErr.Stack))
+	}
+	for i, item := range items {
+		failing := npis[i][0] == '9'
+		if failing != (item.Err != nil) {
+			t.Errorf("item %s: err = %v", npis[i], item.Err)
+		}
+	}
+}
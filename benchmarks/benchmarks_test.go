@@ -0,0 +1,138 @@
+package benchmarks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestProviders_Deterministic tests that fixtures are identical across calls.
+func TestProviders_Deterministic(t *testing.T) {
+	a, b := SearchResponse(Providers(FullPage)), SearchResponse(Providers(FullPage))
+	if string(a) != string(b) {
+		t.Fatal("expected identical fixtures")
+	}
+	seen := make(map[string]bool)
+	for _, p := range Providers(FullPage) {
+		if seen[p.Number] {
+			t.Fatalf("duplicate NPI %s", p.Number)
+		}
+		seen[p.Number] = true
+	}
+}
+
+// TestServer tests that the fake server answers lookups and pages.
+func TestServer(t *testing.T) {
+	providers := Providers(25)
+	server := NewServer(providers, 0)
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	p, err := client.GetProviderByNPI(ctx, providers[3].Number)
+	if err != nil || p == nil || p.Number != providers[3].Number {
+		t.Fatalf("GetProviderByNPI = %v, %v", p, err)
+	}
+	count := 0
+	for _, err := range client.SearchAll(ctx, gonpi.SearchOptions{State: "MA", Limit: 10}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 25 {
+		t.Errorf("SearchAll returned %d providers, want 25", count)
+	}
+}
+
+// BenchmarkDecode benchmarks decoding search responses of realistic providers.
+func BenchmarkDecode(b *testing.B) {
+	for _, n := range []int{SmallPage, FullPage} {
+		body := SearchResponse(Providers(n))
+		b.Run(fmt.Sprintf("providers=%d", n), func(b *testing.B) {
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			for b.Loop() {
+				var response gonpi.APIResponse
+				if err := json.Unmarshal(body, &response); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSearchPage benchmarks a search round trip against the fake server, from
+// building the URL to decoding the page.
+func BenchmarkSearchPage(b *testing.B) {
+	for _, n := range []int{SmallPage, FullPage} {
+		server := NewServer(Providers(n), 0)
+		client := server.Client()
+		opts := gonpi.SearchOptions{State: "MA", Limit: n}
+		b.Run(fmt.Sprintf("providers=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := client.SearchProviders(context.Background(), opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		client.Close()
+		server.Close()
+	}
+}
+
+// BenchmarkCacheHit benchmarks lookups served from the in-memory cache.
+func BenchmarkCacheHit(b *testing.B) {
+	providers := Providers(FullPage)
+	server := NewServer(providers, 0)
+	defer server.Close()
+	client := server.Client(gonpi.WithCache(time.Hour))
+	defer client.Close()
+
+	ctx := context.Background()
+	for _, p := range providers {
+		if _, err := client.GetProviderByNPI(ctx, p.Number); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		if _, err := client.GetProviderByNPI(ctx, providers[i%len(providers)].Number); err != nil {
+			b.Fatal(err)
+		}
+		i++
+	}
+	if server.Requests.Load() != int64(len(providers)) {
+		b.Fatalf("expected only warm-up requests, got %d", server.Requests.Load())
+	}
+}
+
+// BenchmarkBatch benchmarks concurrent batch lookups of a full page of NPIs against a
+// server with a simulated 1ms round trip, reporting lookups per second.
+func BenchmarkBatch(b *testing.B) {
+	providers := Providers(FullPage)
+	npis := make([]string, len(providers))
+	for i, p := range providers {
+		npis[i] = p.Number
+	}
+	server := NewServer(providers, time.Millisecond)
+	defer server.Close()
+	client := server.Client()
+	defer client.Close()
+
+	b.ReportAllocs()
+	start := time.Now()
+	for b.Loop() {
+		results, err := client.GetProvidersByNPIs(context.Background(), npis)
+		if err != nil || len(results) != len(npis) {
+			b.Fatalf("got %d results, %v", len(results), err)
+		}
+	}
+	b.ReportMetric(float64(b.N*len(npis))/time.Since(start).Seconds(), "lookups/s")
+}
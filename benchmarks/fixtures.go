@@ -0,0 +1,154 @@
+// Package benchmarks provides deterministic fixtures and a fake registry server for
+// measuring gonpi's performance. The fixtures are generated from a fixed seed, so every
+// run sees identical payloads and results from different commits are comparable.
+//
+// Run the harness with:
+//
+//	go test ./benchmarks -run '^$' -bench . -benchmem -count 10 > new.txt
+//	benchstat old.txt new.txt
+package benchmarks
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// Seed is the seed every fixture is generated from.
+const Seed = 3694
+
+// Fixture sizes used by the benchmarks: a typical single-page search and a full page.
+const (
+	SmallPage = 10
+	FullPage  = gonpi.MaxLimit
+)
+
+var (
+	firstNames = []string{"JAMES", "MARY", "ROBERT", "PATRICIA", "JOHN", "JENNIFER", "MICHAEL", "LINDA", "DAVID", "ELIZABETH"}
+	lastNames  = []string{"SMITH", "JOHNSON", "WILLIAMS", "BROWN", "JONES", "GARCIA", "MILLER", "DAVIS", "RODRIGUEZ", "MARTINEZ"}
+	orgWords   = []string{"COMMUNITY", "REGIONAL", "MEMORIAL", "VALLEY", "SAINT", "GENERAL", "CHILDRENS", "UNIVERSITY"}
+	orgKinds   = []string{"HOSPITAL", "MEDICAL CENTER", "CLINIC", "HEALTH SYSTEM", "PHARMACY", "LABORATORY"}
+	streets    = []string{"MAIN ST", "OAK AVE", "PARK BLVD", "WASHINGTON ST", "MAPLE DR", "LAKE RD", "HIGHLAND AVE"}
+	cities     = []struct{ city, state, zip string }{
+		{"BOSTON", "MA", "02115"}, {"SPRINGFIELD", "IL", "62701"}, {"AUSTIN", "TX", "78701"},
+		{"FRESNO", "CA", "93701"}, {"ROCHESTER", "MN", "55902"}, {"MIAMI", "FL", "33101"},
+		{"DENVER", "CO", "80202"}, {"SEATTLE", "WA", "98101"},
+	}
+	taxonomies = []gonpi.Taxonomy{
+		{Code: "207Q00000X", Desc: "Family Medicine"},
+		{Code: "207R00000X", Desc: "Internal Medicine"},
+		{Code: "207RC0000X", Desc: "Internal Medicine, Cardiovascular Disease"},
+		{Code: "208D00000X", Desc: "General Practice"},
+		{Code: "2084P0800X", Desc: "Psychiatry & Neurology, Psychiatry"},
+		{Code: "363L00000X", Desc: "Nurse Practitioner"},
+		{Code: "282N00000X", Desc: "General Acute Care Hospital"},
+		{Code: "3336C0003X", Desc: "Pharmacy, Community/Retail Pharmacy"},
+	}
+)
+
+// Providers returns n realistic providers, the same for every call with the same n.
+// About one in five is an organization; individuals have one to three taxonomies,
+// practice and mailing addresses, and some have other identifiers and endpoints.
+func Providers(n int) []gonpi.Provider {
+	rng := rand.New(rand.NewPCG(Seed, uint64(n)))
+	pick := func(items []string) string { return items[rng.IntN(len(items))] }
+
+	providers := make([]gonpi.Provider, n)
+	for i := range providers {
+		place := cities[rng.IntN(len(cities))]
+		p := gonpi.Provider{
+			Number:           npi(1000000000 + i*7919%900000000),
+			EnumerationType:  "NPI-1",
+			CreatedEpoch:     gonpi.FlexInt(1100000000000 + rng.Int64N(600000000000)),
+			LastUpdatedEpoch: gonpi.FlexInt(1600000000000 + rng.Int64N(100000000000)),
+			LastUpdated:      fmt.Sprintf("20%02d-%02d-%02d", 15+rng.IntN(10), 1+rng.IntN(12), 1+rng.IntN(28)),
+			Basic: gonpi.BasicInfo{
+				EnumerationDate: fmt.Sprintf("20%02d-%02d-%02d", 5+rng.IntN(10), 1+rng.IntN(12), 1+rng.IntN(28)),
+				Status:          "A",
+			},
+		}
+		p.Basic.LastUpdated = p.LastUpdated
+
+		phone := fmt.Sprintf("%03d-555-%04d", 200+rng.IntN(800), rng.IntN(10000))
+		location := gonpi.Address{
+			CountryCode: "US", CountryName: "United States", AddressPurpose: "LOCATION", AddressType: "DOM",
+			Address1: fmt.Sprintf("%d %s", 1+rng.IntN(9999), pick(streets)),
+			City:     place.city, State: place.state, PostalCode: place.zip + fmt.Sprintf("%04d", rng.IntN(10000)),
+			TelephoneNumber: phone,
+		}
+		mailing := location
+		mailing.AddressPurpose = "MAILING"
+		if rng.IntN(3) == 0 {
+			mailing.Address1 = fmt.Sprintf("PO BOX %d", 1+rng.IntN(9999))
+		}
+		p.Addresses = []gonpi.Address{location, mailing}
+
+		if rng.IntN(5) == 0 {
+			p.EnumerationType = "NPI-2"
+			p.Basic.OrganizationName = pick(orgWords) + " " + pick(orgWords) + " " + pick(orgKinds)
+			p.Basic.OrganizationalSubpart = "NO"
+			p.Basic.AuthorizedOfficialFirstName = pick(firstNames)
+			p.Basic.AuthorizedOfficialLastName = pick(lastNames)
+			p.Basic.AuthorizedOfficialTitleOrPosition = "CHIEF EXECUTIVE OFFICER"
+			p.Basic.AuthorizedOfficialTelephoneNumber = phone
+		} else {
+			p.Basic.FirstName = pick(firstNames)
+			p.Basic.LastName = pick(lastNames)
+			p.Basic.MiddleName = string(rune('A' + rng.IntN(26)))
+			p.Basic.Credential = []string{"M.D.", "D.O.", "NP", "PA-C", "MD, PHD"}[rng.IntN(5)]
+			p.Basic.Gender = []string{"F", "M"}[rng.IntN(2)]
+			p.Basic.SoleProprietor = "NO"
+		}
+
+		for t := range 1 + rng.IntN(3) {
+			taxonomy := taxonomies[rng.IntN(len(taxonomies))]
+			taxonomy.State = place.state
+			taxonomy.License = strconv.Itoa(10000 + rng.IntN(90000))
+			taxonomy.Primary = t == 0
+			p.Taxonomies = append(p.Taxonomies, taxonomy)
+		}
+		if rng.IntN(3) == 0 {
+			p.Identifiers = append(p.Identifiers, gonpi.Identifier{
+				Code: "05", Desc: "MEDICAID", Identifier: fmt.Sprintf("%08d", rng.IntN(100000000)), State: place.state,
+			})
+		}
+		if rng.IntN(4) == 0 {
+			p.Endpoints = append(p.Endpoints, gonpi.Endpoint{
+				EndpointType: "DIRECT", EndpointTypeDescription: "Direct Messaging Address",
+				Endpoint: fmt.Sprintf("provider%d@direct.example.org", i), Affiliation: "N",
+				Address: location.Address1, City: place.city, State: place.state, Zip: place.zip,
+			})
+		}
+		providers[i] = p
+	}
+	return providers
+}
+
+// SearchResponse returns the encoded API response for a search returning providers.
+func SearchResponse(providers []gonpi.Provider) []byte {
+	body, err := json.Marshal(gonpi.APIResponse{ResultCount: len(providers), Results: providers})
+	if err != nil {
+		panic(err) // fixtures always encode
+	}
+	return body
+}
+
+// npi returns the 10-digit NPI with the check digit for the 9-digit prefix of base.
+func npi(base int) string {
+	prefix := strconv.Itoa(base)[:9]
+	sum := 24 // the constant for the 80840 prefix
+	for i := range 9 {
+		d := int(prefix[8-i] - '0')
+		if i%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return prefix + strconv.Itoa((10-sum%10)%10)
+}
@@ -0,0 +1,88 @@
+package benchmarks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// Server is a fake NPI Registry serving a fixed set of providers. Lookups by number
+// and searches paged with limit and skip are answered from pre-encoded payloads, so the
+// server adds little CPU time of its own to client measurements.
+type Server struct {
+	*httptest.Server
+
+	// Requests counts the requests served.
+	Requests atomic.Int64
+
+	latency time.Duration
+	byNPI   map[string][]byte
+	all     []gonpi.Provider
+
+	mu    sync.Mutex
+	pages map[[2]int][]byte
+}
+
+// NewServer starts a Server for providers. Each response is delayed by latency, which
+// may be zero, to model the network round trip. Close the server when done.
+func NewServer(providers []gonpi.Provider, latency time.Duration) *Server {
+	s := &Server{
+		latency: latency,
+		byNPI:   make(map[string][]byte, len(providers)),
+		all:     providers,
+		pages:   make(map[[2]int][]byte),
+	}
+	for _, p := range providers {
+		s.byNPI[p.Number] = SearchResponse([]gonpi.Provider{p})
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Client returns a gonpi client for the server, with the given options applied after
+// the base URL.
+func (s *Server) Client(opts ...gonpi.ClientOption) *gonpi.Client {
+	return gonpi.NewClient(append([]gonpi.ClientOption{gonpi.WithBaseURL(s.URL)}, opts...)...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.Requests.Add(1)
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	if number := query.Get("number"); number != "" {
+		body, ok := s.byNPI[number]
+		if !ok {
+			body = SearchResponse(nil)
+		}
+		w.Write(body)
+		return
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	skip, _ := strconv.Atoi(query.Get("skip"))
+	w.Write(s.page(skip, limit))
+}
+
+// page returns the encoded page of providers at skip, encoding it on first use.
+func (s *Server) page(skip, limit int) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := [2]int{skip, limit}
+	if body, ok := s.pages[key]; ok {
+		return body
+	}
+	start := min(skip, len(s.all))
+	end := min(start+max(limit, 0), len(s.all))
+	body := SearchResponse(s.all[start:end])
+	s.pages[key] = body
+	return body
+}
@@ -0,0 +1,64 @@
+// Package bulkutil provides disk-backed building blocks for working with more records
+// than fit in memory, such as every NPI in a dissemination file: Sorter, an external
+// sort of byte records, and SeenSet, a set of keys that spills to disk.
+//
+// Both hold up to a memory budget of records in memory and write sorted runs to
+// temporary files beyond it, so that tens of millions of NPIs can be sorted,
+// deduplicated or compared against earlier extracts with a bounded footprint. Close
+// removes the files.
+package bulkutil
+
+// DefaultMemory is the default memory budget of a Sorter or SeenSet, in bytes.
+const DefaultMemory = 64 << 20
+
+// Option configures a Sorter or SeenSet.
+type Option func(*config)
+
+// config holds the settings applied by Options.
+type config struct {
+	memory  int
+	dir     string
+	compare func(a, b []byte) int
+	unique  bool
+}
+
+// WithMemory sets the approximate number of bytes of records held in memory before a
+// sorted run is written to disk. Default: DefaultMemory.
+func WithMemory(bytes int) Option {
+	return func(c *config) {
+		c.memory = bytes
+	}
+}
+
+// WithDir sets the directory runs are written to. Default: os.TempDir().
+func WithDir(dir string) Option {
+	return func(c *config) {
+		c.dir = dir
+	}
+}
+
+// WithCompare sets the order a Sorter sorts records in. Default: bytes.Compare.
+func WithCompare(compare func(a, b []byte) int) Option {
+	return func(c *config) {
+		c.compare = compare
+	}
+}
+
+// WithUnique makes a Sorter drop records that compare equal to an earlier one.
+func WithUnique() Option {
+	return func(c *config) {
+		c.unique = true
+	}
+}
+
+// newConfig applies opts to the defaults.
+func newConfig(opts []Option) config {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.memory <= 0 {
+		c.memory = DefaultMemory
+	}
+	return c
+}
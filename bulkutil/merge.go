@@ -0,0 +1,98 @@
+package bulkutil
+
+import (
+	"container/heap"
+	"io"
+)
+
+// source yields sorted records, each valid until the following call, then io.EOF.
+type source interface {
+	next() ([]byte, error)
+}
+
+// sliceSource yields records held in memory.
+type sliceSource [][]byte
+
+func (s *sliceSource) next() ([]byte, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	record := (*s)[0]
+	*s = (*s)[1:]
+	return record, nil
+}
+
+// mergeSources calls yield with the records of sources in order until yield returns
+// false. If unique is set, records comparing equal to the previous one are dropped;
+// among equal records, those of earlier sources come first.
+func mergeSources(sources []source, compare func(a, b []byte) int, unique bool, yield func([]byte) bool) error {
+	h := &mergeHeap{compare: compare}
+	for i, src := range sources {
+		record, err := src.next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		h.heads = append(h.heads, mergeHead{record: record, source: i})
+	}
+	heap.Init(h)
+
+	var last []byte
+	started := false
+	for h.Len() > 0 {
+		top := &h.heads[0]
+		if !unique || !started || compare(top.record, last) != 0 {
+			if unique {
+				last = append(last[:0], top.record...)
+				started = true
+			}
+			if !yield(top.record) {
+				return nil
+			}
+		}
+		record, err := sources[top.source].next()
+		switch {
+		case err == io.EOF:
+			heap.Pop(h)
+		case err != nil:
+			return err
+		default:
+			top.record = record
+			heap.Fix(h, 0)
+		}
+	}
+	return nil
+}
+
+// mergeHead is the current record of one source.
+type mergeHead struct {
+	record []byte
+	source int
+}
+
+// mergeHeap orders sources by their current record, then by source order.
+type mergeHeap struct {
+	heads   []mergeHead
+	compare func(a, b []byte) int
+}
+
+func (h *mergeHeap) Len() int { return len(h.heads) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	if c := h.compare(h.heads[i].record, h.heads[j].record); c != 0 {
+		return c < 0
+	}
+	return h.heads[i].source < h.heads[j].source
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+
+func (h *mergeHeap) Push(x any) { h.heads = append(h.heads, x.(mergeHead)) }
+
+func (h *mergeHeap) Pop() any {
+	head := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return head
+}
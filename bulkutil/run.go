@@ -0,0 +1,139 @@
+package bulkutil
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// run is a file of length-prefixed records in sorted order.
+type run struct {
+	path  string
+	count int
+	size  int64
+
+	// index holds the first record of every indexInterval records and its offset,
+	// for the runs of a SeenSet
+	index []indexEntry
+	bloom *bloom
+}
+
+// indexEntry locates a block of records in a run.
+type indexEntry struct {
+	key    string
+	offset int64
+}
+
+// indexInterval is the number of records per indexed block.
+const indexInterval = 128
+
+// runWriter writes records to a new run.
+type runWriter struct {
+	file    *os.File
+	writer  *bufio.Writer
+	run     *run
+	indexed bool
+	prefix  [binary.MaxVarintLen64]byte
+}
+
+// createRun creates a run file in dir. If indexed is set, the run gets a block index;
+// its bloom filter is sized for expected records.
+func createRun(dir string, indexed bool, expected int) (*runWriter, error) {
+	f, err := os.CreateTemp(dir, "gonpi-bulk-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+	w := &runWriter{file: f, writer: bufio.NewWriterSize(f, 256<<10), run: &run{path: f.Name()}, indexed: indexed}
+	if indexed {
+		w.run.bloom = newBloom(expected)
+	}
+	return w, nil
+}
+
+// write appends record, which must not sort before the previous one.
+func (w *runWriter) write(record []byte) error {
+	if w.indexed {
+		if w.run.count%indexInterval == 0 {
+			w.run.index = append(w.run.index, indexEntry{key: string(record), offset: w.run.size})
+		}
+		w.run.bloom.add(record)
+	}
+	n := binary.PutUvarint(w.prefix[:], uint64(len(record)))
+	if _, err := w.writer.Write(w.prefix[:n]); err != nil {
+		return err
+	}
+	if _, err := w.writer.Write(record); err != nil {
+		return err
+	}
+	w.run.count++
+	w.run.size += int64(n + len(record))
+	return nil
+}
+
+// finish flushes and closes the file and returns the run.
+func (w *runWriter) finish() (*run, error) {
+	if err := errors.Join(w.writer.Flush(), w.file.Close()); err != nil {
+		os.Remove(w.file.Name())
+		return nil, fmt.Errorf("failed to write run: %w", err)
+	}
+	return w.run, nil
+}
+
+// abort closes and removes the file.
+func (w *runWriter) abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+// runReader reads the records of a run in order.
+type runReader struct {
+	file   *os.File
+	reader *bufio.Reader
+	buf    []byte
+}
+
+// openRun opens r for reading from the start.
+func openRun(r *run) (*runReader, error) {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open run: %w", err)
+	}
+	return &runReader{file: f, reader: bufio.NewReaderSize(f, 64<<10)}, nil
+}
+
+// next returns the next record, valid until the following call, or io.EOF.
+func (r *runReader) next() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.reader)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err == nil {
+		if cap(r.buf) < int(size) {
+			r.buf = make([]byte, size)
+		}
+		r.buf = r.buf[:size]
+		_, err = io.ReadFull(r.reader, r.buf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+	return r.buf, nil
+}
+
+func (r *runReader) close() error {
+	return r.file.Close()
+}
+
+// removeRuns deletes the files of runs.
+func removeRuns(runs []*run) error {
+	var errs []error
+	for _, r := range runs {
+		if err := os.Remove(r.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
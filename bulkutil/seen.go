@@ -0,0 +1,326 @@
+package bulkutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"iter"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// maxSeenRuns is the number of runs a SeenSet keeps before merging them into one,
+// bounding the files probed by each lookup.
+const maxSeenRuns = 8
+
+// keyOverhead approximates the memory a key in the in-memory table uses besides its
+// bytes.
+const keyOverhead = 64
+
+// SeenSet is a set of keys, such as NPIs, that may not fit in memory. Keys are held in
+// memory up to the memory budget, then written to a sorted run on disk. Each run keeps
+// a bloom filter and a sparse index in memory, about 2 bytes per key, so most lookups
+// of absent keys do not touch the disk and the others read one small block. A
+// SeenSet is safe for concurrent use, but Add must not be called while iterating over
+// All.
+//
+// Example usage:
+//
+//	seen := bulkutil.NewSeenSet(bulkutil.WithDir(scratch))
+//	defer seen.Close()
+//	for provider, err := range client.SearchAll(ctx, opts) {
+//	    ...
+//	    if added, err := seen.Add(provider.Number); err == nil && added {
+//	        writer.Write(provider)
+//	    }
+//	}
+type SeenSet struct {
+	config
+
+	mu     sync.Mutex
+	keys   map[string]struct{}
+	bytes  int
+	runs   []*seenRun
+	count  int
+	block  []byte
+	closed bool
+}
+
+// seenRun is a run of a SeenSet, kept open for lookups.
+type seenRun struct {
+	*run
+	file *os.File
+}
+
+// NewSeenSet creates an empty SeenSet.
+func NewSeenSet(opts ...Option) *SeenSet {
+	return &SeenSet{config: newConfig(opts), keys: make(map[string]struct{})}
+}
+
+// Add adds key to the set, reporting whether it was absent.
+func (s *SeenSet) Add(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false, ErrClosed
+	}
+	if found, err := s.contains(key); found || err != nil {
+		return false, err
+	}
+	s.keys[key] = struct{}{}
+	s.bytes += len(key) + keyOverhead
+	s.count++
+	if s.bytes >= s.memory {
+		return true, s.spill()
+	}
+	return true, nil
+}
+
+// Contains reports whether key is in the set.
+func (s *SeenSet) Contains(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false, ErrClosed
+	}
+	return s.contains(key)
+}
+
+// Len returns the number of keys in the set.
+func (s *SeenSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// All returns an iterator over the keys in sorted order. Iteration stops after the
+// first error, which is yielded with an empty key.
+func (s *SeenSet) All() iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			yield("", ErrClosed)
+			return
+		}
+		runs := make([]*run, len(s.runs))
+		for i, r := range s.runs {
+			runs[i] = r.run
+		}
+		sources, readers, err := openSources(runs)
+		if err == nil {
+			memory := sliceSource(s.sortedKeys())
+			sources = append(sources, &memory)
+		}
+		s.mu.Unlock()
+		defer closeReaders(readers)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		err = mergeSources(sources, bytes.Compare, false, func(key []byte) bool {
+			return yield(string(key), nil)
+		})
+		if err != nil {
+			yield("", err)
+		}
+	}
+}
+
+// Close removes the runs. The SeenSet cannot be used afterwards.
+func (s *SeenSet) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]*run, len(s.runs))
+	for i, r := range s.runs {
+		r.file.Close()
+		runs[i] = r.run
+	}
+	err := removeRuns(runs)
+	s.runs, s.keys, s.closed = nil, nil, true
+	return err
+}
+
+// contains reports whether key is in memory or in a run. The caller must hold s.mu.
+func (s *SeenSet) contains(key string) (bool, error) {
+	if _, ok := s.keys[key]; ok {
+		return true, nil
+	}
+	for _, r := range s.runs {
+		if found, err := s.lookup(r, key); found || err != nil {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+// lookup reports whether key is in run r, reading at most one block. The caller must
+// hold s.mu.
+func (s *SeenSet) lookup(r *seenRun, key string) (bool, error) {
+	if !r.bloom.has(key) {
+		return false, nil
+	}
+	i := sort.Search(len(r.index), func(i int) bool { return r.index[i].key > key }) - 1
+	if i < 0 {
+		return false, nil
+	}
+	start, end := r.index[i].offset, r.size
+	if i+1 < len(r.index) {
+		end = r.index[i+1].offset
+	}
+	s.block = slices.Grow(s.block[:0], int(end-start))[:end-start]
+	if _, err := r.file.ReadAt(s.block, start); err != nil {
+		return false, fmt.Errorf("failed to read run: %w", err)
+	}
+	for block := s.block; len(block) > 0; {
+		size, n := binary.Uvarint(block)
+		if n <= 0 || uint64(len(block)-n) < size {
+			return false, fmt.Errorf("failed to read run: corrupt block at offset %d", start)
+		}
+		record := block[n : n+int(size)]
+		switch c := compareKey(record, key); {
+		case c == 0:
+			return true, nil
+		case c > 0:
+			return false, nil
+		}
+		block = block[n+int(size):]
+	}
+	return false, nil
+}
+
+// compareKey compares record with key, returning a negative, zero or positive number
+// as bytes.Compare does, without converting either.
+func compareKey(record []byte, key string) int {
+	for i := range min(len(record), len(key)) {
+		if record[i] != key[i] {
+			return int(record[i]) - int(key[i])
+		}
+	}
+	return len(record) - len(key)
+}
+
+// sortedKeys returns the keys held in memory in order. The caller must hold s.mu.
+func (s *SeenSet) sortedKeys() [][]byte {
+	keys := make([][]byte, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, []byte(key))
+	}
+	slices.SortFunc(keys, bytes.Compare)
+	return keys
+}
+
+// spill writes the keys held in memory to a new run, then merges the runs into one if
+// there are too many. The caller must hold s.mu.
+func (s *SeenSet) spill() error {
+	memory := sliceSource(s.sortedKeys())
+	if err := s.writeRun([]source{&memory}, len(s.keys)); err != nil {
+		return err
+	}
+	clear(s.keys)
+	s.bytes = 0
+
+	if len(s.runs) <= maxSeenRuns {
+		return nil
+	}
+	old := s.runs
+	runs := make([]*run, len(old))
+	for i, r := range old {
+		runs[i] = r.run
+	}
+	sources, readers, err := openSources(runs)
+	if err != nil {
+		return err
+	}
+	s.runs = nil
+	err = s.writeRun(sources, s.count)
+	closeReaders(readers)
+	if err != nil {
+		s.runs = old
+		return err
+	}
+	for _, r := range old {
+		r.file.Close()
+	}
+	return removeRuns(runs)
+}
+
+// writeRun writes the merged keys of sources, expected in number, to a new indexed
+// run and adds it to the set. The caller must hold s.mu.
+func (s *SeenSet) writeRun(sources []source, expected int) error {
+	w, err := createRun(s.dir, true, expected)
+	if err != nil {
+		return err
+	}
+	var writeErr error
+	err = mergeSources(sources, bytes.Compare, true, func(key []byte) bool {
+		writeErr = w.write(key)
+		return writeErr == nil
+	})
+	if err = errors.Join(err, writeErr); err != nil {
+		w.abort()
+		return err
+	}
+	r, err := w.finish()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(r.path)
+	if err != nil {
+		removeRuns([]*run{r})
+		return fmt.Errorf("failed to open run: %w", err)
+	}
+	s.runs = append(s.runs, &seenRun{run: r, file: f})
+	return nil
+}
+
+// bloomBitsPerKey and bloomHashes give a false positive rate of about 1%.
+const (
+	bloomBitsPerKey = 10
+	bloomHashes     = 7
+)
+
+// bloom is a bloom filter over the keys of a run.
+type bloom struct {
+	bits []uint64
+	seed maphash.Seed
+}
+
+// newBloom returns a bloom filter sized for n keys.
+func newBloom(n int) *bloom {
+	return &bloom{bits: make([]uint64, (max(64, n*bloomBitsPerKey)+63)/64), seed: maphash.MakeSeed()}
+}
+
+// add adds key to the filter.
+func (b *bloom) add(key []byte) {
+	h := maphash.Bytes(b.seed, key)
+	for i := range uint64(bloomHashes) {
+		bit := b.bit(h, i)
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// has reports whether key may have been added.
+func (b *bloom) has(key string) bool {
+	h := maphash.String(b.seed, key)
+	for i := range uint64(bloomHashes) {
+		bit := b.bit(h, i)
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bit returns the i-th bit position for hash h, by double hashing its two halves.
+func (b *bloom) bit(h, i uint64) uint64 {
+	return ((h & 0xffffffff) + i*(h>>32|1)) % uint64(len(b.bits)*64)
+}
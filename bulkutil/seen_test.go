@@ -0,0 +1,102 @@
+package bulkutil
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+)
+
+// TestSeenSet tests adding and looking up keys across spilled and merged runs.
+func TestSeenSet(t *testing.T) {
+	keys := randomNPIs(20000)
+	dir := t.TempDir()
+	// About 200 keys per run, so that runs are merged several times
+	seen := NewSeenSet(WithMemory(200*(10+keyOverhead)), WithDir(dir))
+
+	added := map[string]bool{}
+	for _, key := range keys {
+		ok, err := seen.Add(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok == added[key] {
+			t.Fatalf("Add(%s) = %v, already added %v", key, ok, added[key])
+		}
+		added[key] = true
+	}
+	if seen.Len() != len(added) {
+		t.Errorf("Len = %d, want %d", seen.Len(), len(added))
+	}
+	if len(seen.runs) == 0 || len(seen.runs) > maxSeenRuns {
+		t.Errorf("unexpected number of runs: %d", len(seen.runs))
+	}
+
+	for _, key := range []string{keys[0], keys[len(keys)/2], keys[len(keys)-1]} {
+		if ok, err := seen.Contains(key); !ok || err != nil {
+			t.Errorf("Contains(%s) = %v, %v", key, ok, err)
+		}
+	}
+	absent := 0
+	for i := range 1000 {
+		key := string(rune('A'+i%26)) + keys[i]
+		if ok, _ := seen.Contains(key); !ok {
+			absent++
+		}
+	}
+	if absent != 1000 {
+		t.Errorf("found %d absent keys", 1000-absent)
+	}
+
+	var all []string
+	for key, err := range seen.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, key)
+	}
+	want := slices.Compact(slices.Sorted(slices.Values(keys)))
+	if !slices.Equal(all, want) {
+		t.Errorf("All returned %d keys, want %d", len(all), len(want))
+	}
+
+	if err := seen.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("runs left behind: %d files", len(entries))
+	}
+	if _, err := seen.Add("x"); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+// TestSeenSet_Memory tests that keys stay in memory within the budget.
+func TestSeenSet_Memory(t *testing.T) {
+	dir := t.TempDir()
+	seen := NewSeenSet(WithDir(dir))
+	defer seen.Close()
+	for _, key := range randomNPIs(1000) {
+		seen.Add(key)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 || len(seen.runs) != 0 {
+		t.Errorf("expected no runs, got %d files", len(entries))
+	}
+}
+
+// BenchmarkSeenSet benchmarks adding a million NPIs, half of them twice, within a 4MB
+// budget.
+func BenchmarkSeenSet(b *testing.B) {
+	keys := randomNPIs(1_000_000)
+	dir := b.TempDir()
+	b.ReportAllocs()
+	for b.Loop() {
+		seen := NewSeenSet(WithMemory(4<<20), WithDir(dir))
+		for _, key := range keys {
+			if _, err := seen.Add(key); err != nil {
+				b.Fatal(err)
+			}
+		}
+		seen.Close()
+	}
+}
@@ -0,0 +1,232 @@
+package bulkutil
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// ErrClosed is returned when using a closed Sorter or SeenSet.
+var ErrClosed = errors.New("already closed")
+
+// maxFanIn is the number of runs merged at once. Sorts spilling more runs merge
+// them in several passes, bounding open files and read buffers.
+const maxFanIn = 64
+
+// chunkSize is the size of the blocks buffered records are copied into.
+const chunkSize = 1 << 20
+
+// recordOverhead approximates the memory a buffered record uses besides its bytes.
+const recordOverhead = 24
+
+// Sorter sorts records, such as encoded NPIs or provider rows keyed by NPI, that may
+// not fit in memory. Records are buffered up to the memory budget, then sorted and
+// written to a run on disk; Sorted merges the runs. A Sorter is safe for concurrent
+// use, but Add must not be called while iterating over Sorted.
+//
+// Example usage:
+//
+//	sorter := bulkutil.NewSorter(bulkutil.WithDir(scratch), bulkutil.WithUnique())
+//	defer sorter.Close()
+//	for provider, err := range client.SearchAll(ctx, opts) {
+//	    ...
+//	    sorter.Add([]byte(provider.Number))
+//	}
+//	for npi, err := range sorter.Sorted() {
+//	    ...
+//	}
+type Sorter struct {
+	config
+
+	mu      sync.Mutex
+	chunks  [][]byte
+	records [][]byte
+	bytes   int
+	runs    []*run
+	count   int
+	closed  bool
+}
+
+// NewSorter creates an empty Sorter.
+func NewSorter(opts ...Option) *Sorter {
+	s := &Sorter{config: newConfig(opts)}
+	if s.compare == nil {
+		s.compare = bytes.Compare
+	}
+	return s
+}
+
+// Add adds a copy of record to the sort.
+func (s *Sorter) Add(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	s.records = append(s.records, s.copy(record))
+	s.bytes += len(record) + recordOverhead
+	s.count++
+	if s.bytes >= s.memory {
+		return s.spill()
+	}
+	return nil
+}
+
+// Len returns the number of records added, including duplicates.
+func (s *Sorter) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Sorted returns an iterator over the records in order. Each record is only valid
+// until the iteration continues. Iteration stops after the first error, which is
+// yielded with a nil record.
+func (s *Sorter) Sorted() iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			yield(nil, ErrClosed)
+			return
+		}
+		if err := s.compact(); err != nil {
+			s.mu.Unlock()
+			yield(nil, err)
+			return
+		}
+		slices.SortStableFunc(s.records, s.compare)
+		sources, readers, err := openSources(s.runs)
+		if err == nil {
+			memory := sliceSource(slices.Clone(s.records))
+			sources = append(sources, &memory)
+		}
+		s.mu.Unlock()
+		defer closeReaders(readers)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		err = mergeSources(sources, s.compare, s.unique, func(record []byte) bool {
+			return yield(record, nil)
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// Close removes the runs. The Sorter cannot be used afterwards.
+func (s *Sorter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := removeRuns(s.runs)
+	s.runs, s.chunks, s.records, s.closed = nil, nil, nil, true
+	return err
+}
+
+// copy copies record into the chunk buffer. The caller must hold s.mu.
+func (s *Sorter) copy(record []byte) []byte {
+	if n := len(s.chunks); n == 0 || cap(s.chunks[n-1])-len(s.chunks[n-1]) < len(record) {
+		s.chunks = append(s.chunks, make([]byte, 0, max(chunkSize, len(record))))
+	}
+	chunk := &s.chunks[len(s.chunks)-1]
+	start := len(*chunk)
+	*chunk = append(*chunk, record...)
+	return (*chunk)[start:len(*chunk):len(*chunk)]
+}
+
+// spill writes the buffered records to a new run. The caller must hold s.mu.
+func (s *Sorter) spill() error {
+	slices.SortStableFunc(s.records, s.compare)
+	w, err := createRun(s.dir, false, 0)
+	if err != nil {
+		return err
+	}
+	for i, record := range s.records {
+		if s.unique && i > 0 && s.compare(record, s.records[i-1]) == 0 {
+			continue
+		}
+		if err := w.write(record); err != nil {
+			w.abort()
+			return err
+		}
+	}
+	r, err := w.finish()
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, r)
+
+	clear(s.records)
+	s.records, s.bytes = s.records[:0], 0
+	for i := range s.chunks {
+		s.chunks[i] = s.chunks[i][:0]
+	}
+	// Keep one chunk for the next records
+	s.chunks = s.chunks[:min(1, len(s.chunks))]
+	return nil
+}
+
+// compact merges the oldest runs until at most maxFanIn remain, keeping the merged
+// run first so that equal records stay in the order they were added. The caller must
+// hold s.mu.
+func (s *Sorter) compact() error {
+	for len(s.runs) > maxFanIn {
+		sources, readers, err := openSources(s.runs[:maxFanIn])
+		if err != nil {
+			return err
+		}
+		w, err := createRun(s.dir, false, 0)
+		if err == nil {
+			var writeErr error
+			err = mergeSources(sources, s.compare, s.unique, func(record []byte) bool {
+				writeErr = w.write(record)
+				return writeErr == nil
+			})
+			err = errors.Join(err, writeErr)
+		}
+		closeReaders(readers)
+		if err != nil {
+			if w != nil {
+				w.abort()
+			}
+			return err
+		}
+		merged, err := w.finish()
+		if err != nil {
+			return err
+		}
+		removeRuns(s.runs[:maxFanIn])
+		s.runs = append([]*run{merged}, s.runs[maxFanIn:]...)
+	}
+	return nil
+}
+
+// openSources opens a reader for each of runs.
+func openSources(runs []*run) ([]source, []*runReader, error) {
+	sources := make([]source, 0, len(runs)+1)
+	readers := make([]*runReader, 0, len(runs))
+	for _, r := range runs {
+		reader, err := openRun(r)
+		if err != nil {
+			closeReaders(readers)
+			return nil, nil, err
+		}
+		sources = append(sources, reader)
+		readers = append(readers, reader)
+	}
+	return sources, readers, nil
+}
+
+// closeReaders closes the files of readers.
+func closeReaders(readers []*runReader) {
+	for _, r := range readers {
+		r.close()
+	}
+}
@@ -0,0 +1,129 @@
+package bulkutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"testing"
+)
+
+// randomNPIs returns n NPI-like keys in random order, with repeats.
+func randomNPIs(n int) []string {
+	r := rand.New(rand.NewPCG(1, 2))
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%010d", 1000000000+r.IntN(n))
+	}
+	return keys
+}
+
+// sorted collects the records of s, failing on errors.
+func sorted(t *testing.T, s *Sorter) []string {
+	t.Helper()
+	var records []string
+	for record, err := range s.Sorted() {
+		if err != nil {
+			t.Fatalf("Sorted: %v", err)
+		}
+		records = append(records, string(record))
+	}
+	return records
+}
+
+// TestSorter tests sorting across many spilled runs, with and without duplicates.
+func TestSorter(t *testing.T) {
+	keys := randomNPIs(20000)
+	want := slices.Sorted(slices.Values(keys))
+
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"all", nil, want},
+		{"unique", []Option{WithUnique()}, slices.Compact(slices.Clone(want))},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// About 100 records per run, so that runs are merged in several passes
+			sorter := NewSorter(append(tt.opts, WithMemory(100*(10+recordOverhead)), WithDir(dir))...)
+			for _, key := range keys {
+				if err := sorter.Add([]byte(key)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if sorter.Len() != len(keys) {
+				t.Errorf("Len = %d", sorter.Len())
+			}
+			if got := sorted(t, sorter); !slices.Equal(got, tt.want) {
+				t.Fatalf("got %d records, want %d", len(got), len(tt.want))
+			}
+			// Sorted can be repeated
+			if got := sorted(t, sorter); !slices.Equal(got, tt.want) {
+				t.Error("second pass differs")
+			}
+
+			if err := sorter.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("runs left behind: %d files", len(entries))
+			}
+			if err := sorter.Add([]byte("x")); !errors.Is(err, ErrClosed) {
+				t.Errorf("expected ErrClosed, got %v", err)
+			}
+		})
+	}
+}
+
+// TestSorter_Compare tests a custom order that ties records, which must keep the
+// order they were added in.
+func TestSorter_Compare(t *testing.T) {
+	byFirstByte := func(a, b []byte) int { return int(a[0]) - int(b[0]) }
+	sorter := NewSorter(WithCompare(byFirstByte), WithMemory(2*(2+recordOverhead)), WithDir(t.TempDir()))
+	defer sorter.Close()
+	for _, record := range []string{"b1", "a1", "b2", "a2", "c1", "a3"} {
+		sorter.Add([]byte(record))
+	}
+	want := []string{"a1", "a2", "a3", "b1", "b2", "c1"}
+	if got := sorted(t, sorter); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestSorter_Stop tests stopping iteration early.
+func TestSorter_Stop(t *testing.T) {
+	sorter := NewSorter(WithMemory(1), WithDir(t.TempDir()))
+	defer sorter.Close()
+	for _, record := range []string{"c", "a", "b"} {
+		sorter.Add([]byte(record))
+	}
+	for record := range sorter.Sorted() {
+		if !bytes.Equal(record, []byte("a")) {
+			t.Errorf("first record = %q", record)
+		}
+		break
+	}
+}
+
+// BenchmarkSorter benchmarks sorting a million NPIs within a 4MB budget.
+func BenchmarkSorter(b *testing.B) {
+	keys := randomNPIs(1_000_000)
+	dir := b.TempDir()
+	b.ReportAllocs()
+	for b.Loop() {
+		sorter := NewSorter(WithMemory(4<<20), WithDir(dir), WithUnique())
+		for _, key := range keys {
+			sorter.Add([]byte(key))
+		}
+		for _, err := range sorter.Sorted() {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+		sorter.Close()
+	}
+}
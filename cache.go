@@ -0,0 +1,51 @@
+package gonpi
+
+import (
+	"context"
+	"time"
+)
+
+// CacheBackend stores cached providers outside the client, such as in Redis or
+// memcached, so that several processes can share lookups. Keys already include the
+// namespace set with WithCacheNamespace. Implementations must be safe for concurrent
+// use.
+type CacheBackend interface {
+	// Get returns the cached provider for key, or false if there is none.
+	Get(ctx context.Context, key string) (*Provider, bool, error)
+
+	// Set caches provider under key for ttl.
+	Set(ctx context.Context, key string, provider *Provider, ttl time.Duration) error
+}
+
+// WithCacheBackend caches NPI lookups in backend, with the TTL configured by WithCache
+// (5 minutes by default). When combined with WithCache, the in-memory cache is checked
+// first and the backend second. Backend errors are recorded on the span and treated as
+// cache misses. If backend implements io.Closer, Client.Close closes it.
+func WithCacheBackend(backend CacheBackend) ClientOption {
+	return func(c *Client) {
+		c.cacheBackend = backend
+		c.background.own(backend)
+	}
+}
+
+// WithCacheNamespace prefixes every cache key with namespace, so that tenants or API
+// versions sharing one cache backend do not read each other's entries.
+// Keys have the form "<namespace>:<npi>".
+func WithCacheNamespace(namespace string) ClientOption {
+	return func(c *Client) {
+		c.cacheNamespace = namespace
+	}
+}
+
+// caching reports whether any cache layer is configured.
+func (c *Client) caching() bool {
+	return c.cache.enabled || c.cacheBackend != nil
+}
+
+// cacheKey returns the cache key for npi, including the namespace.
+func (c *Client) cacheKey(npi string) string {
+	if c.cacheNamespace == "" {
+		return npi
+	}
+	return c.cacheNamespace + ":" + npi
+}
@@ -0,0 +1,108 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mapCacheBackend is an in-memory CacheBackend standing in for a shared cache.
+type mapCacheBackend struct {
+	mu      sync.Mutex
+	entries map[string]*Provider
+	ttls    map[string]time.Duration
+	err     error
+}
+
+func newMapCacheBackend() *mapCacheBackend {
+	return &mapCacheBackend{entries: make(map[string]*Provider), ttls: make(map[string]time.Duration)}
+}
+
+func (b *mapCacheBackend) Get(_ context.Context, key string) (*Provider, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return nil, false, b.err
+	}
+	p, ok := b.entries[key]
+	return p, ok, nil
+}
+
+func (b *mapCacheBackend) Set(_ context.Context, key string, provider *Provider, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	b.entries[key] = provider
+	b.ttls[key] = ttl
+	return nil
+}
+
+// TestCacheNamespace tests that namespaced clients sharing a backend do not collide.
+func TestCacheNamespace(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	backend := newMapCacheBackend()
+	tenantA := NewClient(WithBaseURL(server.URL), WithCacheBackend(backend), WithCacheNamespace("tenant-a"), WithCache(time.Hour))
+	defer tenantA.Close()
+	tenantB := NewClient(WithBaseURL(server.URL), WithCacheBackend(backend), WithCacheNamespace("tenant-b"))
+	ctx := context.Background()
+
+	if _, err := tenantA.GetProviderByNPI(ctx, "1234567893"); err != nil {
+		t.Fatalf("tenant A: %v", err)
+	}
+	if _, ok := backend.entries["tenant-a:1234567893"]; !ok {
+		t.Fatalf("expected namespaced key in backend, got %v", backend.entries)
+	}
+	if backend.ttls["tenant-a:1234567893"] != time.Hour {
+		t.Errorf("backend TTL = %v, want 1h", backend.ttls["tenant-a:1234567893"])
+	}
+
+	if _, err := tenantB.GetProviderByNPI(ctx, "1234567893"); err != nil {
+		t.Fatalf("tenant B: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected a separate fetch per namespace, got %d requests", requests.Load())
+	}
+
+	// A second client in the same namespace is served from the shared backend
+	sameTenant := NewClient(WithBaseURL(server.URL), WithCacheBackend(backend), WithCacheNamespace("tenant-b"))
+	if _, err := sameTenant.GetProviderByNPI(ctx, "1234567893"); err != nil {
+		t.Fatalf("same tenant: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected backend hit, got %d requests", requests.Load())
+	}
+}
+
+// TestCacheBackend_ErrorsAreMisses tests that backend failures fall through to the API.
+func TestCacheBackend_ErrorsAreMisses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	backend := newMapCacheBackend()
+	backend.err = errors.New("connection refused")
+	client := NewClient(WithBaseURL(server.URL), WithCacheBackend(backend))
+
+	provider, err := client.GetProviderByNPI(context.Background(), "1234567893")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider == nil {
+		t.Fatal("expected provider from API")
+	}
+}
@@ -0,0 +1,126 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheEntry is one provider to cache under Key.
+type CacheEntry struct {
+	Key      string
+	Provider *Provider
+}
+
+// CacheBatchSetter is implemented by cache backends that can store several entries in
+// one round trip, such as a Redis pipeline or a multi-put to a key-value store. Batch
+// lookups buffer their backend writes and flush them with SetMany; backends without it
+// get one Set per entry at flush time.
+type CacheBatchSetter interface {
+	SetMany(ctx context.Context, entries []CacheEntry, ttl time.Duration) error
+}
+
+// defaultCacheWriteBatch is the number of backend writes buffered by a batch lookup
+// before they are flushed.
+const defaultCacheWriteBatch = 100
+
+// WithCacheWriteBatch sets how many backend cache writes GetProvidersByNPIs and
+// GetProvidersByNPIsOrdered buffer before flushing them together; the rest are flushed
+// when the batch ends. Buffering keeps writes to remote caches off each lookup's
+// critical path. The in-memory cache is always written immediately. 1 disables
+// buffering. Default: 100.
+func WithCacheWriteBatch(n int) ClientOption {
+	return func(c *Client) {
+		c.cacheWriteBatch = n
+	}
+}
+
+// cacheWriteBuffer collects the backend writes of one batch lookup.
+type cacheWriteBuffer struct {
+	c    *Client
+	size int
+
+	mu      sync.Mutex
+	pending []CacheEntry
+	errs    []error
+}
+
+type cacheWriteBufferKey struct{}
+
+// withCacheWriteBuffer returns ctx carrying a buffer for backend writes, and a function
+// flushing what remains in it, or ctx unchanged and a no-op if buffering does not apply.
+func (c *Client) withCacheWriteBuffer(ctx context.Context) (context.Context, func(context.Context) error) {
+	if c.cacheBackend == nil || c.cacheWriteBatch == 1 {
+		return ctx, func(context.Context) error { return nil }
+	}
+	size := c.cacheWriteBatch
+	if size <= 0 {
+		size = defaultCacheWriteBatch
+	}
+	buf := &cacheWriteBuffer{c: c, size: size}
+	return context.WithValue(ctx, cacheWriteBufferKey{}, buf), buf.close
+}
+
+// cacheWriteBufferFrom returns the buffer attached to ctx, if any.
+func cacheWriteBufferFrom(ctx context.Context) *cacheWriteBuffer {
+	buf, _ := ctx.Value(cacheWriteBufferKey{}).(*cacheWriteBuffer)
+	return buf
+}
+
+// add buffers an entry, flushing the buffer once it is full.
+func (b *cacheWriteBuffer) add(ctx context.Context, entry CacheEntry) {
+	b.mu.Lock()
+	b.pending = append(b.pending, entry)
+	var full []CacheEntry
+	if len(b.pending) >= b.size {
+		full, b.pending = b.pending, nil
+	}
+	b.mu.Unlock()
+
+	if full != nil {
+		b.write(ctx, full)
+	}
+}
+
+// write stores entries in the backend, recording any error.
+func (b *cacheWriteBuffer) write(ctx context.Context, entries []CacheEntry) {
+	var err error
+	if setter, ok := b.c.cacheBackend.(CacheBatchSetter); ok {
+		err = setter.SetMany(ctx, entries, b.c.cache.ttl)
+	} else {
+		var errs []error
+		for _, entry := range entries {
+			errs = append(errs, b.c.cacheBackend.Set(ctx, entry.Key, entry.Provider, b.c.cache.ttl))
+		}
+		err = errors.Join(errs...)
+	}
+	if err != nil {
+		b.mu.Lock()
+		b.errs = append(b.errs, fmt.Errorf("cache backend set failed: %w", err))
+		b.mu.Unlock()
+	}
+}
+
+// close flushes the remaining entries and returns every write error. Writes complete
+// even if the batch was cancelled.
+func (b *cacheWriteBuffer) close(ctx context.Context) error {
+	b.mu.Lock()
+	rest := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+	if len(rest) > 0 {
+		b.write(context.WithoutCancel(ctx), rest)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := errors.Join(b.errs...)
+	if err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+	}
+	return err
+}
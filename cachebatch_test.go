@@ -0,0 +1,116 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchCacheBackend is a mapCacheBackend recording SetMany calls.
+type batchCacheBackend struct {
+	*mapCacheBackend
+
+	mu      sync.Mutex
+	batches [][]CacheEntry
+	sets    int
+}
+
+func (b *batchCacheBackend) Set(ctx context.Context, key string, provider *Provider, ttl time.Duration) error {
+	b.mu.Lock()
+	b.sets++
+	b.mu.Unlock()
+	return b.mapCacheBackend.Set(ctx, key, provider, ttl)
+}
+
+func (b *batchCacheBackend) SetMany(ctx context.Context, entries []CacheEntry, ttl time.Duration) error {
+	b.mu.Lock()
+	b.batches = append(b.batches, entries)
+	b.mu.Unlock()
+	for _, entry := range entries {
+		b.mapCacheBackend.Set(ctx, entry.Key, entry.Provider, ttl)
+	}
+	return b.mapCacheBackend.err
+}
+
+// newEchoServer returns a server answering every NPI lookup with a provider of that
+// number.
+func newEchoServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := mockProvider()
+		p.Number = r.URL.Query().Get("number")
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{p}))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// batchNPIs are valid NPIs for batch tests.
+var batchNPIs = []string{"1234567893", "1245319599", "1003000126", "1043218118", "1111111112"}
+
+// TestCacheWriteBatch tests that batch lookups flush backend writes in batches.
+func TestCacheWriteBatch(t *testing.T) {
+	server := newEchoServer(t)
+	backend := &batchCacheBackend{mapCacheBackend: newMapCacheBackend()}
+	client := NewClient(WithBaseURL(server.URL), WithCacheBackend(backend), WithCacheWriteBatch(2))
+	defer client.Close()
+
+	results, err := client.GetProvidersByNPIs(context.Background(), batchNPIs)
+	if err != nil || len(results) != len(batchNPIs) {
+		t.Fatalf("GetProvidersByNPIs() = %d results, %v", len(results), err)
+	}
+	if backend.sets != 0 {
+		t.Errorf("expected no single writes, got %d", backend.sets)
+	}
+	var sizes []int
+	for _, batch := range backend.batches {
+		sizes = append(sizes, len(batch))
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Errorf("batch sizes = %v, want [2 2 1]", sizes)
+	}
+	for _, npi := range batchNPIs {
+		if backend.entries[npi] == nil {
+			t.Errorf("%s not cached", npi)
+		}
+	}
+
+	// Single lookups still write through immediately
+	if _, err := client.GetProviderByNPI(context.Background(), "1588667638"); err != nil {
+		t.Fatal(err)
+	}
+	if backend.sets != 1 {
+		t.Errorf("expected 1 single write, got %d", backend.sets)
+	}
+}
+
+// TestCacheWriteBatch_Fallback tests flushing to backends without SetMany, disabling
+// buffering, and that flush errors do not fail the batch.
+func TestCacheWriteBatch_Fallback(t *testing.T) {
+	server := newEchoServer(t)
+	backend := newMapCacheBackend()
+	client := NewClient(WithBaseURL(server.URL), WithCacheBackend(backend))
+	defer client.Close()
+
+	if _, err := client.GetProvidersByNPIsOrdered(context.Background(), batchNPIs); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.entries) != len(batchNPIs) {
+		t.Errorf("cached %d entries, want %d", len(backend.entries), len(batchNPIs))
+	}
+
+	batching := &batchCacheBackend{mapCacheBackend: newMapCacheBackend()}
+	batching.err = errors.New("cache down")
+	client = NewClient(WithBaseURL(server.URL), WithCacheBackend(batching), WithCacheWriteBatch(1))
+	defer client.Close()
+	if _, err := client.GetProvidersByNPIs(context.Background(), batchNPIs[:2]); err != nil {
+		t.Fatalf("cache errors should not fail the batch: %v", err)
+	}
+	if len(batching.batches) != 0 || batching.sets != 2 {
+		t.Errorf("unbuffered client made %d batches and %d writes", len(batching.batches), batching.sets)
+	}
+}
@@ -0,0 +1,179 @@
+package gonpi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheTTLs sets how long each tier of cached results stays fresh. Records found by
+// NPI change rarely, while search pages shift as providers enroll and move, and
+// counts feed dashboards that should track those shifts closely, so one TTL either
+// refetches stable records or serves stale pages.
+type CacheTTLs struct {
+	// NPI applies to GetProviderByNPI and batch lookups, in memory and in any
+	// CacheBackend.
+	NPI time.Duration
+
+	// Search applies to the result pages of SearchProviders, including the pages
+	// fetched by SearchAll and SearchPage. Pages are cached in memory only.
+	Search time.Duration
+
+	// Count applies to CountProviders, in memory only.
+	Count time.Duration
+}
+
+// DefaultCacheTTLs returns the tier TTLs used by WithCacheTTLs for zero fields: a day
+// for NPI lookups, an hour for searches and five minutes for counts.
+func DefaultCacheTTLs() CacheTTLs {
+	return CacheTTLs{NPI: 24 * time.Hour, Search: time.Hour, Count: 5 * time.Minute}
+}
+
+// WithCacheTTLs enables in-memory caching of NPI lookups, searches and counts, each
+// with its own TTL. Zero fields take their DefaultCacheTTLs values. Searches are
+// keyed by their normalized query, so options differing only in case or surrounding
+// spaces share an entry, and a different Skip or Limit is a different page. Lookups
+// by NPI are cached in the NPI tier only, and searches are not cached while
+// WithProjection trims records. WithCacheBypass and WithCacheRefresh apply to
+// every tier.
+// On a derived client (see With), it replaces the shared cache with a private one.
+//
+// Example usage:
+//
+//	client := gonpi.NewClient(gonpi.WithCacheTTLs(gonpi.CacheTTLs{
+//	    NPI:    7 * 24 * time.Hour,
+//	    Search: 30 * time.Minute,
+//	    Count:  time.Minute,
+//	}))
+func WithCacheTTLs(ttls CacheTTLs) ClientOption {
+	return func(c *Client) {
+		defaults := DefaultCacheTTLs()
+		if ttls.NPI <= 0 {
+			ttls.NPI = defaults.NPI
+		}
+		if ttls.Search <= 0 {
+			ttls.Search = defaults.Search
+		}
+		if ttls.Count <= 0 {
+			ttls.Count = defaults.Count
+		}
+		c.enableCache(ttls)
+	}
+}
+
+// searchCacheKey returns the search tier key for opts, which must have the client
+// defaults applied, or "" if the search should not be cached.
+func (c *Client) searchCacheKey(ctx context.Context, opts SearchOptions) string {
+	if !c.cache.enabled || c.cache.searchTTL <= 0 || opts.Number != "" || c.projecting() ||
+		callOptionsFromContext(ctx).cache == cacheOff {
+		return ""
+	}
+	return c.cacheKey("search:" + c.normalizedQuery(opts))
+}
+
+// normalizedQuery returns the API query for opts with its values uppercased and
+// trimmed and Pretty unset, so equivalent options compare equal.
+func (c *Client) normalizedQuery(opts SearchOptions) string {
+	for _, field := range []*string{
+		&opts.EnumerationType, &opts.FirstName, &opts.LastName, &opts.OrganizationName,
+		&opts.TaxonomyDescription, &opts.AddressPurpose, &opts.City, &opts.State,
+		&opts.PostalCode, &opts.CountryCode,
+	} {
+		*field = strings.ToUpper(strings.TrimSpace(*field))
+	}
+	opts.Pretty = false
+	return strings.TrimPrefix(c.searchURL(opts), c.searchPrefix)
+}
+
+// entry returns the unexpired entry cached under key.
+func (s *cacheStore) entry(key string) (*cacheEntry, bool) {
+	s.mu.RLock()
+	entry, ok := s.data[key]
+	s.mu.RUnlock()
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// put caches entry under key for ttl.
+func (s *cacheStore) put(key string, entry *cacheEntry, ttl time.Duration) {
+	entry.expiresAt = time.Now().Add(ttl)
+	s.mu.Lock()
+	s.data[key] = entry
+	s.mu.Unlock()
+}
+
+// getResults returns a copy of the search page cached under key.
+func (s *cacheStore) getResults(ctx context.Context, key string) ([]Provider, bool) {
+	entry, ok := s.entry(key)
+	if !ok {
+		return nil, false
+	}
+	recordRetrievals(ctx, RetrievalMeta{Source: SourceCache, FetchedAt: entry.fetchedAt}, entry.providers...)
+	return slices.Clone(entry.providers), true
+}
+
+// setResults caches a search page under key for the search TTL.
+func (s *cacheStore) setResults(key string, providers []Provider) {
+	s.put(key, &cacheEntry{providers: slices.Clone(providers), fetchedAt: time.Now()}, s.searchTTL)
+}
+
+// CountProviders returns how many providers match opts, counted by paging through the
+// results with SearchAll, since the registry does not report totals. Paging stops at
+// MaxSkip, so complete is false when more providers may match than the registry
+// returns; narrow the query to count them. Skip, Limit, MaxResults and Cursor are
+// ignored.
+//
+// With WithCacheTTLs, counts are cached for the Count TTL. The pages fetched to count
+// are not cached as searches, so a count is never rebuilt from pages older than it.
+//
+// Example usage:
+//
+//	n, complete, err := client.CountProviders(ctx, gonpi.SearchOptions{State: "VT", TaxonomyDescription: "Cardiology"})
+func (c *Client) CountProviders(ctx context.Context, opts SearchOptions) (count int, complete bool, err error) {
+	ctx, span := c.tracer.Start(ctx, "CountProviders",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.String("state", opts.State),
+			attribute.String("taxonomy_description", opts.TaxonomyDescription),
+		)...),
+	)
+	defer span.End()
+
+	opts.Skip, opts.Limit, opts.MaxResults, opts.Cursor = 0, MaxLimit, 0, ""
+	opts.SortByRelevance = false
+	mode := callOptionsFromContext(ctx).cache
+	key := ""
+	if c.cache.enabled && c.cache.countTTL > 0 && mode != cacheOff {
+		key = c.cacheKey("count:" + c.normalizedQuery(c.applyDefaults(opts)))
+	}
+	if key != "" && mode.reads() {
+		entry, ok := c.cache.entry(key)
+		span.SetAttributes(c.traceAttrs(attribute.Bool("cache_hit", ok))...)
+		if ok {
+			return entry.count, entry.complete, nil
+		}
+	}
+
+	pages := ContextWithCallOptions(ctx, withCacheOff())
+	for _, err := range c.SearchAll(pages, opts) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "count failed")
+			return count, false, fmt.Errorf("count providers failed: %w", err)
+		}
+		count++
+	}
+	complete = count < MaxSkip+MaxLimit
+	span.SetAttributes(c.traceAttrs(attribute.Int("count", count), attribute.Bool("complete", complete))...)
+	if key != "" && mode.writes() {
+		c.cache.put(key, &cacheEntry{count: count, complete: complete}, c.cache.countTTL)
+	}
+	return count, complete, nil
+}
@@ -0,0 +1,82 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWithCacheTTLs tests search pages and counts cached in their own tiers.
+func TestWithCacheTTLs(t *testing.T) {
+	var searches, npis atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("number") != "" {
+			npis.Add(1)
+			json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+			return
+		}
+		searches.Add(1)
+		var providers []Provider
+		if r.URL.Query().Get("skip") == "" {
+			for _, npi := range []string{"1111111111", "2222222222", "3333333333"} {
+				p := mockProvider()
+				p.Number = npi
+				providers = append(providers, p)
+			}
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse(providers))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL), WithCacheTTLs(CacheTTLs{Count: 20 * time.Millisecond}))
+	defer client.Close()
+	ctx := context.Background()
+
+	if client.cache.ttl != DefaultCacheTTLs().NPI || client.cache.searchTTL != DefaultCacheTTLs().Search {
+		t.Errorf("tier TTLs = %v, %v", client.cache.ttl, client.cache.searchTTL)
+	}
+
+	first, err := client.SearchProviders(ctx, SearchOptions{LastName: "smith", State: "MA"})
+	if err != nil {
+		t.Fatalf("SearchProviders() error = %v", err)
+	}
+	first[0].Number = "changed"
+	retrievals := NewRetrievals()
+	again, _ := client.SearchProviders(ContextWithCallOptions(ctx, WithRetrievals(retrievals)), SearchOptions{LastName: " SMITH", State: "MA"})
+	if searches.Load() != 1 || len(again) != 3 || again[0].Number != "1111111111" || retrievals.Meta("1111111111").Source != SourceCache {
+		t.Errorf("cached search: %d requests, results %+v", searches.Load(), again)
+	}
+	client.SearchProviders(ctx, SearchOptions{LastName: "SMITH", State: "MA", Skip: 10})
+	client.SearchProviders(ContextWithCallOptions(ctx, WithCacheBypass()), SearchOptions{LastName: "SMITH", State: "MA"})
+	if searches.Load() != 2 {
+		t.Errorf("expected other pages to miss and bypassed searches to read the cache, got %d requests", searches.Load())
+	}
+	client.SearchProviders(ContextWithCallOptions(ctx, WithCacheBypass()), SearchOptions{LastName: "SMITH", State: "NH"})
+	client.SearchProviders(ctx, SearchOptions{LastName: "SMITH", State: "NH"})
+	if searches.Load() != 4 {
+		t.Errorf("expected a bypassed miss not to be cached, got %d requests", searches.Load())
+	}
+
+	client.GetProviderByNPI(ctx, "1234567893")
+	client.GetProviderByNPI(ctx, "1234567893")
+	if npis.Load() != 1 {
+		t.Errorf("expected one NPI request, got %d", npis.Load())
+	}
+
+	searches.Store(0)
+	count, complete, err := client.CountProviders(ctx, SearchOptions{LastName: "SMITH", State: "MA"})
+	if err != nil || count != 3 || !complete {
+		t.Fatalf("CountProviders() = %d, %v, %v", count, complete, err)
+	}
+	client.CountProviders(ctx, SearchOptions{LastName: "smith", State: "MA"})
+	if searches.Load() != 1 {
+		t.Errorf("expected the count to be cached, got %d requests", searches.Load())
+	}
+	time.Sleep(30 * time.Millisecond)
+	if count, _, _ := client.CountProviders(ctx, SearchOptions{LastName: "SMITH", State: "MA"}); count != 3 || searches.Load() != 2 {
+		t.Errorf("expected an expired count to be recounted from the API, got %d requests", searches.Load())
+	}
+}
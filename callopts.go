@@ -0,0 +1,112 @@
+package gonpi
+
+import "context"
+
+// CallOption adjusts how the client handles the lookups of one call. Attach options to
+// a context with ContextWithCallOptions.
+type CallOption func(*callOptions)
+
+// callOptions holds the settings applied by CallOptions.
+type callOptions struct {
+	cache      cacheMode
+	retrievals *Retrievals
+}
+
+// cacheMode selects how a call uses the cache.
+type cacheMode int
+
+const (
+	// cacheDefault reads from and writes to the cache.
+	cacheDefault cacheMode = iota
+
+	// cacheBypass reads from the cache but does not write fetched results to it.
+	cacheBypass
+
+	// cacheRefresh fetches from the API and overwrites the cache.
+	cacheRefresh
+
+	// cacheOff neither reads nor writes the cache. CountProviders uses it for the
+	// pages it counts.
+	cacheOff
+)
+
+// String returns the name recorded on spans.
+func (m cacheMode) String() string {
+	switch m {
+	case cacheBypass:
+		return "bypass"
+	case cacheRefresh:
+		return "refresh"
+	case cacheOff:
+		return "off"
+	}
+	return "default"
+}
+
+// reads reports whether lookups in mode may be answered from the cache.
+func (m cacheMode) reads() bool {
+	return m == cacheDefault || m == cacheBypass
+}
+
+// writes reports whether lookups in mode store fetched results in the cache.
+func (m cacheMode) writes() bool {
+	return m == cacheDefault || m == cacheRefresh
+}
+
+// CacheDeleter is implemented by cache backends that can remove entries. WithCacheRefresh
+// uses it to drop entries for providers that no longer exist; entries in other backends
+// expire with their TTL.
+type CacheDeleter interface {
+	Delete(ctx context.Context, key string) error
+}
+
+// WithCacheBypass makes lookups read through the cache without writing to it: cached
+// entries are still returned, but results fetched from the API on a miss are not
+// stored, leaving the cache as it was. Use it for one-off or bulk reads, such as
+// exports, that should not fill the cache or evict what other callers rely on; use
+// WithCacheRefresh to force a fetch from the API.
+func WithCacheBypass() CallOption {
+	return func(o *callOptions) {
+		o.cache = cacheBypass
+	}
+}
+
+// WithCacheRefresh makes lookups refetch from the API and overwrite the cached entry,
+// or remove it if the provider no longer exists, for UI refresh buttons and
+// reconciliation jobs.
+func WithCacheRefresh() CallOption {
+	return func(o *callOptions) {
+		o.cache = cacheRefresh
+	}
+}
+
+// withCacheOff makes lookups neither read nor write the cache.
+func withCacheOff() CallOption {
+	return func(o *callOptions) {
+		o.cache = cacheOff
+	}
+}
+
+type callOptionsKey struct{}
+
+// ContextWithCallOptions returns a copy of ctx carrying opts, applied on top of any
+// options already attached to ctx. Lookups made with the context, including those of
+// GetProvidersByNPIs, follow them.
+//
+// Example usage:
+//
+//	ctx = gonpi.ContextWithCallOptions(ctx, gonpi.WithCacheRefresh())
+//	provider, err := client.GetProviderByNPI(ctx, npi)
+func ContextWithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	o := callOptionsFromContext(ctx)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, o)
+}
+
+// callOptionsFromContext returns the options attached to ctx.
+func callOptionsFromContext(ctx context.Context) callOptions {
+	o, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return o
+}
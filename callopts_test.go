@@ -0,0 +1,89 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCallOptions_Cache tests reading through and refreshing the cache per call.
+func TestCallOptions_Cache(t *testing.T) {
+	var requests atomic.Int32
+	var missing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if missing.Load() {
+			json.NewEncoder(w).Encode(mockAPIResponse(nil))
+			return
+		}
+		p := mockProvider()
+		p.Basic.LastName = fmt.Sprint("Doe", n)
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{p}))
+	}))
+	defer server.Close()
+
+	backend := newMapCacheBackend()
+	client := NewClient(WithBaseURL(server.URL), WithCache(time.Hour), WithCacheBackend(backend))
+	defer client.Close()
+	ctx := context.Background()
+	lastName := func(ctx context.Context) string {
+		t.Helper()
+		p, err := client.GetProviderByNPI(ctx, "1234567893")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p == nil {
+			return ""
+		}
+		return p.Basic.LastName
+	}
+
+	bypass := ContextWithCallOptions(ctx, WithCacheBypass())
+	if got := lastName(bypass); got != "Doe1" {
+		t.Fatalf("bypass on a miss = %s, want a fetch", got)
+	}
+	if _, ok := backend.entries["1234567893"]; ok {
+		t.Error("bypass stored the fetched provider")
+	}
+	if got := lastName(ctx); got != "Doe2" {
+		t.Errorf("after bypass = %s, want a fetch since nothing was cached", got)
+	}
+	if got := lastName(bypass); got != "Doe2" {
+		t.Errorf("bypass on a hit = %s, want the cached entry", got)
+	}
+	if got := lastName(ContextWithCallOptions(ctx, WithCacheRefresh())); got != "Doe3" {
+		t.Errorf("refresh = %s, want a fresh fetch", got)
+	}
+	if got := lastName(ctx); got != "Doe3" {
+		t.Errorf("after refresh = %s, want the refreshed entry", got)
+	}
+	if backend.entries["1234567893"].Basic.LastName != "Doe3" {
+		t.Errorf("backend holds %s", backend.entries["1234567893"].Basic.LastName)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+
+	// Refreshing a provider that no longer exists drops the in-memory entry
+	missing.Store(true)
+	if got := lastName(ContextWithCallOptions(ctx, WithCacheRefresh())); got != "" {
+		t.Errorf("refresh of missing provider = %s", got)
+	}
+	client.cache.mu.RLock()
+	_, cached := client.cache.data["1234567893"]
+	client.cache.mu.RUnlock()
+	if cached {
+		t.Error("refresh left the entry of a missing provider cached")
+	}
+
+	// Later options override earlier ones
+	ctx = ContextWithCallOptions(ContextWithCallOptions(ctx, WithCacheBypass()), WithCacheRefresh())
+	if callOptionsFromContext(ctx).cache != cacheRefresh {
+		t.Error("later option did not override earlier one")
+	}
+}
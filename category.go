@@ -0,0 +1,223 @@
+package gonpi
+
+import "maps"
+
+// ServiceCategory is a coarse service category used to bucket providers in
+// network-adequacy reports.
+type ServiceCategory string
+
+// Service categories.
+const (
+	CategoryPrimaryCare      ServiceCategory = "primary_care"
+	CategoryBehavioralHealth ServiceCategory = "behavioral_health"
+	CategorySpecialist       ServiceCategory = "specialist"
+	CategoryFacility         ServiceCategory = "facility"
+	CategoryPharmacy         ServiceCategory = "pharmacy"
+	CategoryDME              ServiceCategory = "dme"
+
+	// CategoryOther covers taxonomies no rule matches, such as transportation and
+	// managed care organizations.
+	CategoryOther ServiceCategory = "other"
+)
+
+// categoryLabels are the English display names of the service categories.
+var categoryLabels = map[ServiceCategory]string{
+	CategoryPrimaryCare:      "Primary care",
+	CategoryBehavioralHealth: "Behavioral health",
+	CategorySpecialist:       "Specialist",
+	CategoryFacility:         "Facility",
+	CategoryPharmacy:         "Pharmacy",
+	CategoryDME:              "Durable medical equipment",
+	CategoryOther:            "Other",
+}
+
+// Label returns the category's English display name, such as "Primary care", or the
+// category itself if it is not built in. TaxonomyBundle.Category translates it.
+func (c ServiceCategory) Label() string {
+	if label, ok := categoryLabels[c]; ok {
+		return label
+	}
+	return string(c)
+}
+
+// AgeGroup is the patient population a taxonomy serves.
+type AgeGroup string
+
+// Age groups.
+const (
+	AgeAll       AgeGroup = "all"
+	AgePediatric AgeGroup = "pediatric"
+	AgeAdult     AgeGroup = "adult"
+)
+
+// ageGroupLabels are the English display names of the age groups.
+var ageGroupLabels = map[AgeGroup]string{
+	AgeAll:       "All ages",
+	AgePediatric: "Pediatric",
+	AgeAdult:     "Adult",
+}
+
+// Label returns the age group's English display name, such as "All ages", or the age
+// group itself if it is not built in. TaxonomyBundle.AgeGroup translates it.
+func (a AgeGroup) Label() string {
+	if label, ok := ageGroupLabels[a]; ok {
+		return label
+	}
+	return string(a)
+}
+
+// TaxonomyClass is the service category and age group of a taxonomy.
+type TaxonomyClass struct {
+	Category ServiceCategory `json:"category"`
+	AgeGroup AgeGroup        `json:"age_group"`
+}
+
+// defaultTaxonomyClasses maps taxonomy code prefixes to classes. The longest matching
+// prefix wins, so full codes override the groups they belong to.
+var defaultTaxonomyClasses = map[string]TaxonomyClass{
+	// Allopathic and osteopathic physicians are specialists unless listed below
+	"20": {CategorySpecialist, AgeAll},
+
+	// Primary care physicians
+	"207Q00000X": {CategoryPrimaryCare, AgeAll},       // Family Medicine
+	"207QA0505X": {CategoryPrimaryCare, AgeAdult},     // Family Medicine, Adult Medicine
+	"207QG0300X": {CategoryPrimaryCare, AgeAdult},     // Family Medicine, Geriatric Medicine
+	"207QA0000X": {CategoryPrimaryCare, AgePediatric}, // Family Medicine, Adolescent Medicine
+	"207R00000X": {CategoryPrimaryCare, AgeAdult},     // Internal Medicine
+	"207RG0300X": {CategoryPrimaryCare, AgeAdult},     // Internal Medicine, Geriatric Medicine
+	"207R":       {CategorySpecialist, AgeAdult},      // Internal Medicine subspecialties
+	"208D00000X": {CategoryPrimaryCare, AgeAll},       // General Practice
+	"208000000X": {CategoryPrimaryCare, AgePediatric}, // Pediatrics
+	"2080":       {CategorySpecialist, AgePediatric},  // Pediatric subspecialties
+
+	// Psychiatry
+	"2084P0800X": {CategoryBehavioralHealth, AgeAll},       // Psychiatry
+	"2084P0804X": {CategoryBehavioralHealth, AgePediatric}, // Child & Adolescent Psychiatry
+	"2084P0805X": {CategoryBehavioralHealth, AgeAdult},     // Geriatric Psychiatry
+	"2084A0401X": {CategoryBehavioralHealth, AgeAll},       // Addiction Medicine
+	"2084P0802X": {CategoryBehavioralHealth, AgeAll},       // Addiction Psychiatry
+
+	// Behavioral health and social service providers
+	"10": {CategoryBehavioralHealth, AgeAll},
+
+	// Physician assistants and advanced practice nurses
+	"363A00000X": {CategoryPrimaryCare, AgeAll},       // Physician Assistant
+	"363AM0700X": {CategoryPrimaryCare, AgeAll},       // Physician Assistant, Medical
+	"363AS0400X": {CategorySpecialist, AgeAll},        // Physician Assistant, Surgical
+	"363L":       {CategorySpecialist, AgeAll},        // Nurse Practitioner specialties
+	"363L00000X": {CategoryPrimaryCare, AgeAll},       // Nurse Practitioner
+	"363LF0000X": {CategoryPrimaryCare, AgeAll},       // Family
+	"363LP2300X": {CategoryPrimaryCare, AgeAll},       // Primary Care
+	"363LA2200X": {CategoryPrimaryCare, AgeAdult},     // Adult Health
+	"363LG0600X": {CategoryPrimaryCare, AgeAdult},     // Gerontology
+	"363LP0200X": {CategoryPrimaryCare, AgePediatric}, // Pediatrics
+	"363LP0808X": {CategoryBehavioralHealth, AgeAll},  // Psychiatric/Mental Health
+	"364S":       {CategorySpecialist, AgeAll},        // Clinical Nurse Specialist
+
+	// Pharmacists and pharmacies
+	"1835": {CategoryPharmacy, AgeAll},
+	"3336": {CategoryPharmacy, AgeAll},
+
+	// Suppliers of durable medical equipment, prosthetics and orthotics
+	"332B": {CategoryDME, AgeAll},
+	"335E": {CategoryDME, AgeAll},
+	"332S": {CategoryDME, AgeAll}, // Hearing aid equipment
+
+	// Facilities: clinics, hospitals, hospital units, laboratories, agencies,
+	// nursing and residential treatment facilities
+	"25":         {CategoryFacility, AgeAll},
+	"26":         {CategoryFacility, AgeAll},
+	"27":         {CategoryFacility, AgeAll},
+	"28":         {CategoryFacility, AgeAll},
+	"282NC2000X": {CategoryFacility, AgePediatric}, // Children's Hospital
+	"29":         {CategoryFacility, AgeAll},
+	"31":         {CategoryFacility, AgeAll},
+	"32":         {CategoryFacility, AgeAll},
+
+	// Dentists, podiatrists, chiropractors, optometrists and therapists
+	"12":         {CategorySpecialist, AgeAll},
+	"1223P0221X": {CategorySpecialist, AgePediatric}, // Pediatric Dentistry
+	"21":         {CategorySpecialist, AgeAll},
+	"22":         {CategorySpecialist, AgeAll},
+	"23":         {CategorySpecialist, AgeAll},
+}
+
+// TaxonomyClassifier maps taxonomy codes to service categories and age groups by
+// longest matching code prefix. It is safe for concurrent use.
+type TaxonomyClassifier struct {
+	classes map[string]TaxonomyClass
+	longest int
+}
+
+// defaultClassifier backs ClassifyTaxonomy.
+var defaultClassifier = NewTaxonomyClassifier(nil)
+
+// DefaultTaxonomyClasses returns a copy of the built-in mapping of taxonomy code
+// prefixes to classes, for inspection or as the base of a custom table.
+func DefaultTaxonomyClasses() map[string]TaxonomyClass {
+	return maps.Clone(defaultTaxonomyClasses)
+}
+
+// NewTaxonomyClassifier returns a classifier using the built-in mapping with overrides
+// applied on top. Keys of overrides are taxonomy code prefixes, from a group such as
+// "20" to a full code such as "207Q00000X"; the longest matching prefix wins.
+//
+// Example usage:
+//
+//	// Count OB/GYNs as primary care
+//	classifier := gonpi.NewTaxonomyClassifier(map[string]gonpi.TaxonomyClass{
+//	    "207V": {Category: gonpi.CategoryPrimaryCare, AgeGroup: gonpi.AgeAdult},
+//	})
+func NewTaxonomyClassifier(overrides map[string]TaxonomyClass) *TaxonomyClassifier {
+	classes := maps.Clone(defaultTaxonomyClasses)
+	maps.Copy(classes, overrides)
+	c := &TaxonomyClassifier{classes: classes}
+	for prefix := range classes {
+		c.longest = max(c.longest, len(prefix))
+	}
+	return c
+}
+
+// Classify returns the class of taxonomy code, or CategoryOther and AgeAll if no
+// prefix matches.
+func (c *TaxonomyClassifier) Classify(code string) TaxonomyClass {
+	for n := min(len(code), c.longest); n > 0; n-- {
+		if class, ok := c.classes[code[:n]]; ok {
+			return class
+		}
+	}
+	return TaxonomyClass{Category: CategoryOther, AgeGroup: AgeAll}
+}
+
+// ClassifyProvider returns the class of the provider's primary taxonomy, or of the
+// first listed if none is primary.
+func (c *TaxonomyClassifier) ClassifyProvider(provider Provider) TaxonomyClass {
+	code := ""
+	for i, taxonomy := range provider.Taxonomies {
+		if i == 0 || taxonomy.Primary {
+			code = taxonomy.Code
+		}
+		if taxonomy.Primary {
+			break
+		}
+	}
+	return c.Classify(code)
+}
+
+// InCategory matches providers whose primary taxonomy falls in one of categories.
+func (c *TaxonomyClassifier) InCategory(categories ...ServiceCategory) ProviderFilter {
+	return func(p Provider) bool {
+		category := c.ClassifyProvider(p).Category
+		for _, want := range categories {
+			if category == want {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ClassifyTaxonomy returns the class of taxonomy code under the built-in mapping.
+func ClassifyTaxonomy(code string) TaxonomyClass {
+	return defaultClassifier.Classify(code)
+}
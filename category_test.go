@@ -0,0 +1,63 @@
+package gonpi
+
+import "testing"
+
+// TestClassifyTaxonomy tests the built-in mapping, including full codes overriding
+// their groups.
+func TestClassifyTaxonomy(t *testing.T) {
+	tests := []struct {
+		code string
+		want TaxonomyClass
+	}{
+		{"207Q00000X", TaxonomyClass{CategoryPrimaryCare, AgeAll}},
+		{"207R00000X", TaxonomyClass{CategoryPrimaryCare, AgeAdult}},
+		{"207RC0000X", TaxonomyClass{CategorySpecialist, AgeAdult}},
+		{"208000000X", TaxonomyClass{CategoryPrimaryCare, AgePediatric}},
+		{"2080P0207X", TaxonomyClass{CategorySpecialist, AgePediatric}},
+		{"207X00000X", TaxonomyClass{CategorySpecialist, AgeAll}},
+		{"2084P0804X", TaxonomyClass{CategoryBehavioralHealth, AgePediatric}},
+		{"103T00000X", TaxonomyClass{CategoryBehavioralHealth, AgeAll}},
+		{"363LP0808X", TaxonomyClass{CategoryBehavioralHealth, AgeAll}},
+		{"282N00000X", TaxonomyClass{CategoryFacility, AgeAll}},
+		{"282NC2000X", TaxonomyClass{CategoryFacility, AgePediatric}},
+		{"333600000X", TaxonomyClass{CategoryPharmacy, AgeAll}},
+		{"183500000X", TaxonomyClass{CategoryPharmacy, AgeAll}},
+		{"332B00000X", TaxonomyClass{CategoryDME, AgeAll}},
+		{"341600000X", TaxonomyClass{CategoryOther, AgeAll}},
+		{"", TaxonomyClass{CategoryOther, AgeAll}},
+	}
+	for _, tt := range tests {
+		if got := ClassifyTaxonomy(tt.code); got != tt.want {
+			t.Errorf("ClassifyTaxonomy(%q) = %+v, want %+v", tt.code, got, tt.want)
+		}
+	}
+}
+
+// TestTaxonomyClassifier_Overrides tests overrides, provider classification and
+// filtering.
+func TestTaxonomyClassifier_Overrides(t *testing.T) {
+	classifier := NewTaxonomyClassifier(map[string]TaxonomyClass{
+		"207V": {CategoryPrimaryCare, AgeAdult},
+	})
+	if got := classifier.Classify("207VG0400X"); got != (TaxonomyClass{CategoryPrimaryCare, AgeAdult}) {
+		t.Errorf("override = %+v", got)
+	}
+	if got := ClassifyTaxonomy("207VG0400X"); got.Category != CategorySpecialist {
+		t.Errorf("override leaked into the default classifier: %+v", got)
+	}
+	if _, ok := DefaultTaxonomyClasses()["207V"]; ok {
+		t.Error("override leaked into the default table")
+	}
+
+	p := mockProvider()
+	p.Taxonomies = []Taxonomy{{Code: "207RC0000X"}, {Code: "207VG0400X", Primary: true}}
+	if got := classifier.ClassifyProvider(p).Category; got != CategoryPrimaryCare {
+		t.Errorf("ClassifyProvider() = %s", got)
+	}
+	if matched := FilterProviders([]Provider{p, mockProvider()}, classifier.InCategory(CategoryPrimaryCare)); len(matched) != 2 {
+		t.Errorf("InCategory matched %d providers, want 2", len(matched))
+	}
+	if got := ClassifyTaxonomy("").Category; got != CategoryOther {
+		t.Errorf("empty code = %s", got)
+	}
+}
@@ -2,20 +2,26 @@ package gonpi
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -44,33 +50,206 @@ const (
 
 // Client is the NPI Registry API client.
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
-	retry      RetryConfig
-	cache      *cacheStore
-	tracer     trace.Tracer
-	mu         sync.RWMutex
+	baseURL      string
+	searchPrefix string // baseURL + searchPath, the fixed start of every API URL
+	httpClient   *http.Client
+	retry        RetryConfig
+	cache        *cacheStore
+	cacheBackend CacheBackend
+	tracer       trace.Tracer
+	traceFilter  TraceAttributeFilter
+	stats        StatsSink
+	geocoder     Geocoder
+	zipCentroids *ZIPCentroids
+	enrollment   EnrollmentChecker
+	store        ProviderStore
+	headers      http.Header
+	limiter      *rateLimiter
+	priority     Priority
+	adaptive     *AdaptiveConcurrency
+	dns          *DNSCache
+	dnsInstalled dnsInstall
+	audit        AuditSink
+	auditRedact  map[string]bool
+	presets      *presetRegistry // shared with derived clients
+	hydrate      bool
+	drift        *schemaDrift   // shared with derived clients
+	status       *statusTracker // shared with derived clients
+	jsonDecoder  JSONDecoder
+	projection   *projection
+
+	slowThreshold time.Duration
+	slowReport    func(SlowRequest)
+	retryHook     func(RetryEvent)
+
+	cacheNamespace     string
+	cacheWriteBatch    int
+	defaultLimit       int
+	preconnect         int
+	defaultCountryCode string
+	strictDecoding     bool
+
+	// background tracks goroutines owned by this client, stopped by Close. Derived
+	// clients get their own, nested in their parent's.
+	background *background
 }
 
-// cacheStore provides simple in-memory caching for NPI lookups.
+// background holds the long-running work and resources owned by a client.
+type background struct {
+	mu         sync.Mutex
+	closed     bool
+	stopped    bool
+	schedulers []*Scheduler
+
+	// parent is the background of the client this one's was derived from, or nil.
+	// Work entered here is also entered there, so the parent's Shutdown and Close
+	// drain and stop it too.
+	parent *background
+
+	// draining is closed once the client, or its parent, stops accepting new calls,
+	// telling idle watchers and schedulers to exit. It is drainCtx.Done().
+	draining     <-chan struct{}
+	drainCtx     context.Context
+	stopDraining context.CancelFunc
+
+	// ctx is cancelled by Close, or the parent's Close; calls and work registered
+	// with enter derive from it, and wg counts them
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// closers are the cache backends and stores given to the client that implement
+	// io.Closer
+	closers []io.Closer
+}
+
+// admittedKey marks contexts of calls and work accepted by a background, whose own
+// calls are still accepted while it, or any background it is nested in, drains.
+type admittedKey struct{}
+
+// newBackground returns an empty background for a new client, or for a client
+// derived from the one owning parent if parent is not nil.
+func newBackground(parent *background) *background {
+	ctx, drainCtx := context.Background(), context.Background()
+	if parent != nil {
+		ctx, drainCtx = parent.ctx, parent.drainCtx
+	}
+	b := &background{parent: parent}
+	b.ctx, b.cancel = context.WithCancel(ctx)
+	b.drainCtx, b.stopDraining = context.WithCancel(drainCtx)
+	b.draining = b.drainCtx.Done()
+	return b
+}
+
+// admits reports whether ctx belongs to work accepted by b or by a background
+// nested in it.
+func (b *background) admits(ctx context.Context) bool {
+	for a, _ := ctx.Value(admittedKey{}).(*background); a != nil; a = a.parent {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// enter registers a call or long-running work started with ctx. The returned context
+// is cancelled when the client is closed, and Close and Shutdown wait until done is
+// called. Once the client drains, only work started from an admitted context is
+// accepted; anything else fails with ErrClientClosed.
+func (b *background) enter(ctx context.Context) (admitted context.Context, done func(), err error) {
+	parentDone := func() {}
+	if b.parent != nil {
+		if ctx, parentDone, err = b.parent.enter(ctx); err != nil {
+			return ctx, parentDone, err
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped || (b.closed && !b.admits(ctx)) {
+		parentDone()
+		return ctx, func() {}, ErrClientClosed
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, admittedKey{}, b))
+	stop := context.AfterFunc(b.ctx, cancel)
+	b.wg.Add(1)
+	return ctx, func() {
+		stop()
+		cancel()
+		b.wg.Done()
+		parentDone()
+	}, nil
+}
+
+// track registers long-running work like enter. Work started after Close gets a
+// cancelled context.
+func (b *background) track(ctx context.Context) (tracked context.Context, done func()) {
+	tracked, done, err := b.enter(ctx)
+	if err != nil {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		return ctx, done
+	}
+	return tracked, done
+}
+
+// drain stops accepting new calls and work.
+func (b *background) drain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		b.stopDraining()
+	}
+}
+
+// own records v to be closed by Close if it implements io.Closer.
+func (b *background) own(v any) {
+	closer, ok := v.(io.Closer)
+	if !ok {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closers = append(b.closers, closer)
+}
+
+// cacheStore provides simple in-memory caching for NPI lookups and, with
+// WithCacheTTLs, for search pages and counts.
 type cacheStore struct {
 	enabled       bool
 	data          map[string]*cacheEntry
 	mu            sync.RWMutex
 	ttl           time.Duration
+	searchTTL     time.Duration
+	countTTL      time.Duration
 	cleanupCtx    context.Context
 	cleanupCancel context.CancelFunc
+	cleanupDone   chan struct{}
+
+	// owner is the client that created the cache and stops its cleanup goroutine.
+	owner *Client
 }
 
 type cacheEntry struct {
 	provider  *Provider
 	expiresAt time.Time
+
+	// fetchedAt is when the cached provider or search page was fetched from the API.
+	fetchedAt time.Time
+
+	// providers holds a cached search page, and count and complete a cached count.
+	providers []Provider
+	count     int
+	complete  bool
 }
 
 // NewClient creates a new NPI Registry API client with optional configuration.
 func NewClient(opts ...ClientOption) *Client {
 	client := &Client{
-		baseURL: DefaultBaseURL,
+		baseURL:      DefaultBaseURL,
+		searchPrefix: DefaultBaseURL + searchPath,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
@@ -85,12 +264,17 @@ func NewClient(opts ...ClientOption) *Client {
 			data:    make(map[string]*cacheEntry),
 			ttl:     5 * time.Minute,
 		},
-		tracer: otel.Tracer(TracerName),
+		tracer:     otel.Tracer(TracerName),
+		presets:    &presetRegistry{},
+		background: newBackground(nil),
+		status:     &statusTracker{},
 	}
 
 	for _, opt := range opts {
 		opt(client)
 	}
+	client.installDNSCache()
+	client.startPreconnect()
 
 	return client
 }
@@ -106,6 +290,7 @@ func WithHTTPClient(httpClient *http.Client) ClientOption {
 func WithBaseURL(baseURL string) ClientOption {
 	return func(c *Client) {
 		c.baseURL = baseURL
+		c.searchPrefix = baseURL + searchPath
 	}
 }
 
@@ -116,15 +301,58 @@ func WithRetry(config RetryConfig) ClientOption {
 	}
 }
 
-// WithCache enables in-memory caching with the specified TTL.
+// WithCache enables in-memory caching of NPI lookups with the specified TTL. Use
+// WithCacheTTLs to also cache searches and counts.
+// On a derived client (see With), it replaces the shared cache with a private one.
 func WithCache(ttl time.Duration) ClientOption {
 	return func(c *Client) {
-		c.cache.enabled = true
-		c.cache.ttl = ttl
-		// Create context for cleanup goroutine lifecycle
-		c.cache.cleanupCtx, c.cache.cleanupCancel = context.WithCancel(context.Background())
-		// Start background cleanup goroutine
-		go c.cleanupCache()
+		c.enableCache(CacheTTLs{NPI: ttl})
+	}
+}
+
+// enableCache replaces the client's cache with an enabled one using ttls, of which
+// zero Search and Count TTLs leave those tiers uncached.
+func (c *Client) enableCache(ttls CacheTTLs) {
+	if c.cache.owner == c {
+		c.cache.stopCleanup()
+	}
+	c.cache = &cacheStore{
+		enabled:     true,
+		data:        make(map[string]*cacheEntry),
+		ttl:         ttls.NPI,
+		searchTTL:   ttls.Search,
+		countTTL:    ttls.Count,
+		owner:       c,
+		cleanupDone: make(chan struct{}),
+	}
+	// Create context for cleanup goroutine lifecycle
+	c.cache.cleanupCtx, c.cache.cleanupCancel = context.WithCancel(context.Background())
+	// Start background cleanup goroutine
+	go c.cache.cleanup()
+}
+
+// WithDefaultLimit sets the result limit used when SearchOptions.Limit is zero.
+// Values above MaxLimit are capped at MaxLimit.
+func WithDefaultLimit(limit int) ClientOption {
+	return func(c *Client) {
+		c.defaultLimit = limit
+	}
+}
+
+// WithDefaultCountryCode sets the country code used when SearchOptions.CountryCode
+// is empty. It is not applied to lookups by NPI number.
+func WithDefaultCountryCode(code string) ClientOption {
+	return func(c *Client) {
+		c.defaultCountryCode = code
+	}
+}
+
+// WithStrictDecoding rejects API responses containing fields that are not part of the
+// Provider types (see RegistrySchema), returning an error wrapping ErrSchemaMismatch.
+// It is intended for detecting drift between the live API and this client.
+func WithStrictDecoding() ClientOption {
+	return func(c *Client) {
+		c.strictDecoding = true
 	}
 }
 
@@ -135,42 +363,163 @@ func WithTracer(tracer trace.Tracer) ClientOption {
 	}
 }
 
-// Close gracefully shuts down the client and stops background goroutines.
-// Call this when the client is no longer needed to prevent goroutine leaks.
-func (c *Client) Close() {
-	if c.cache.cleanupCancel != nil {
-		c.cache.cleanupCancel()
+// WithTracerProvider sets the OpenTelemetry TracerProvider used to create the client's tracer.
+// The tracer is resolved when the client is constructed, so spans are not affected by
+// changes to the global provider made afterwards.
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(c *Client) {
+		c.tracer = provider.Tracer(TracerName)
 	}
 }
 
+// Close shuts down the client immediately. New API calls fail with ErrClientClosed.
+// Close cancels in-flight calls, stops the in-memory cache cleanup goroutine,
+// schedulers created with NewScheduler, watchers running with Watcher.Run and any
+// WithPreconnect warm-up, and waits for their work to return. It then closes the cache
+// backend and store given to this client if they implement io.Closer, returning their
+// errors. Use Shutdown to let in-flight work finish first.
+//
+// Call Close when the client is no longer needed to prevent goroutine leaks; further
+// calls do nothing. Close and Shutdown also stop the calls, watchers and schedulers of
+// clients derived from this one (see With), but a private cache or backend given to a
+// derived client is only released by closing that client. Closing a derived client
+// leaves resources shared with its parent running.
+func (c *Client) Close() error {
+	b := c.background
+	b.drain()
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return nil
+	}
+	b.stopped = true
+	schedulers := b.schedulers
+	b.schedulers = nil
+	closers := b.closers
+	b.closers = nil
+	b.mu.Unlock()
+
+	b.cancel()
+	for _, s := range schedulers {
+		s.Stop()
+	}
+	b.wg.Wait()
+
+	if c.cache.owner == c {
+		c.cache.stopCleanup()
+	}
+
+	var errs []error
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown drains the client for a clean rolling deploy. New API calls fail with
+// ErrClientClosed at once, while calls already in flight, including the remaining
+// lookups of a batch or pages of a search, run to completion. Idle watchers and
+// schedulers stop, and running scheduled tasks and watcher polls finish. Once all of
+// that work returns, or when ctx is done, Shutdown cancels whatever is left and closes
+// the client like Close. It returns ctx.Err() if the deadline cut draining short,
+// joined with any Close error.
+//
+// Example usage:
+//
+//	httpServer.Shutdown(ctx) // stop taking proxy requests first
+//	client.Shutdown(ctx)
+func (c *Client) Shutdown(ctx context.Context) error {
+	b := c.background
+	b.drain()
+	b.mu.Lock()
+	schedulers := slices.Clone(b.schedulers)
+	b.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		for _, s := range schedulers {
+			s.wg.Wait()
+		}
+		b.wg.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return errors.Join(err, c.Close())
+}
+
+// With returns a derived client that shares this client's HTTP transport, caches,
+// store and telemetry but applies opts on top of its settings. Deriving is cheap, so
+// background jobs can use gentler retry or rate-limit settings than interactive
+// traffic without a second connection pool or cache:
+//
+//	background := client.With(
+//	    WithRetry(RetryConfig{MaxRetries: 10, InitialDelay: time.Second, MaxDelay: time.Minute, BackoffMultiplier: 2}),
+//	    WithRateLimit(2, 1),
+//	)
+//
+// Options replace shared settings only on the derived client; for example WithCache
+// gives it a private in-memory cache and WithHeader adds to a copy of the headers.
+// Shutting down or closing this client also drains and stops the derived client.
+func (c *Client) With(opts ...ClientOption) *Client {
+	derived := *c
+	derived.headers = c.headers.Clone()
+	derived.background = newBackground(c.background)
+	derived.preconnect = 0
+	for _, opt := range opts {
+		opt(&derived)
+	}
+	derived.installDNSCache()
+	derived.startPreconnect()
+	return &derived
+}
+
 // GetProviderByNPI retrieves a provider by NPI number, checking the cache first
 // if the cache is enabled. If no providers are found, nil is returned along
-// with a nil error.
+// with a nil error. The NPI is cleaned up with NormalizeNPI first; malformed
+// NPIs return a ValidationError without contacting the API.
 //
 // The function returns the first matching provider. If the cache is not enabled,
 // the function will always make an API request.
 func (c *Client) GetProviderByNPI(ctx context.Context, npi string) (*Provider, error) {
 	ctx, span := c.tracer.Start(ctx, "GetProviderByNPI",
-		trace.WithAttributes(
+		trace.WithAttributes(c.traceAttrs(
 			attribute.String("npi", npi),
-		),
+		)...),
 	)
 	defer span.End()
 
-	if npi == "" {
-		err := fmt.Errorf("npi cannot be empty")
+	npi, err := NormalizeNPI(npi)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
 	// Check cache first
-	if c.cache.enabled {
-		if provider := c.getCached(npi); provider != nil {
-			span.SetAttributes(attribute.Bool("cache_hit", true))
+	mode := callOptionsFromContext(ctx).cache
+	if mode != cacheDefault {
+		span.SetAttributes(c.traceAttrs(attribute.String("cache_mode", mode.String()))...)
+	}
+	if c.caching() && mode.reads() {
+		provider, err := c.getCached(ctx, npi)
+		if err != nil {
+			span.RecordError(err)
+		}
+		if provider != nil {
+			span.SetAttributes(c.traceAttrs(attribute.Bool("cache_hit", true))...)
+			c.increment(MetricCacheHits)
 			return provider, nil
 		}
-		span.SetAttributes(attribute.Bool("cache_hit", false))
+		span.SetAttributes(c.traceAttrs(attribute.Bool("cache_hit", false))...)
+		c.increment(MetricCacheMisses)
 	}
 
 	opts := SearchOptions{
@@ -186,14 +535,21 @@ func (c *Client) GetProviderByNPI(ctx context.Context, npi string) (*Provider, e
 	}
 
 	if len(providers) == 0 {
+		if c.caching() && mode == cacheRefresh {
+			if err := c.deleteCached(ctx, npi); err != nil {
+				span.RecordError(err)
+			}
+		}
 		return nil, nil
 	}
 
 	provider := &providers[0]
 
-	// Cache the result
-	if c.cache.enabled {
-		c.setCached(npi, provider)
+	// Cache the result, unless it is partial
+	if c.caching() && mode.writes() && !c.projecting() {
+		if err := c.setCached(ctx, npi, provider); err != nil {
+			span.RecordError(err)
+		}
 	}
 
 	return provider, nil
@@ -206,11 +562,13 @@ func (c *Client) GetProviderByNPI(ctx context.Context, npi string) (*Provider, e
 //
 // The function returns a slice of Provider structs, which contain detailed information about each provider.
 // If no providers are found, an empty slice is returned along with a nil error.
+// Queries the registry rejects, which it reports in the body of a 200 response,
+// return a ValidationError.
 //
 // The function also returns an error if the API request fails or if the response cannot be decoded into a slice of Provider structs.
 func (c *Client) SearchProviders(ctx context.Context, opts SearchOptions) ([]Provider, error) {
 	ctx, span := c.tracer.Start(ctx, "SearchProviders",
-		trace.WithAttributes(
+		trace.WithAttributes(c.traceAttrs(
 			attribute.String("last_name", opts.LastName),
 			attribute.String("first_name", opts.FirstName),
 			attribute.String("organization_name", opts.OrganizationName),
@@ -218,16 +576,30 @@ func (c *Client) SearchProviders(ctx context.Context, opts SearchOptions) ([]Pro
 			attribute.String("city", opts.City),
 			attribute.Int("limit", opts.Limit),
 			attribute.Int("skip", opts.Skip),
-		),
+		)...),
 	)
 	defer span.End()
 
-	// Build query parameters
-	params := c.buildQueryParams(opts)
+	opts = c.applyDefaults(opts)
+	if err := validateSearchOptions(opts); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
 
-	// Construct URL
-	apiURL := fmt.Sprintf("%s/?%s", c.baseURL, params.Encode())
-	span.SetAttributes(attribute.String("url", apiURL))
+	apiURL := c.searchURL(opts)
+	span.SetAttributes(c.traceAttrs(semconv.URLFull(apiURL))...)
+
+	cacheKey := c.searchCacheKey(ctx, opts)
+	mode := callOptionsFromContext(ctx).cache
+	if cacheKey != "" && mode.reads() {
+		providers, ok := c.cache.getResults(ctx, cacheKey)
+		span.SetAttributes(c.traceAttrs(attribute.Bool("cache_hit", ok))...)
+		if ok {
+			span.SetAttributes(c.traceAttrs(attribute.Int("result_count", len(providers)))...)
+			return sortResults(providers, opts), nil
+		}
+	}
 
 	// Make request with retry logic
 	var response APIResponse
@@ -237,59 +609,89 @@ func (c *Client) SearchProviders(ctx context.Context, opts SearchOptions) ([]Pro
 		span.SetStatus(codes.Error, "search request failed")
 		return nil, fmt.Errorf("search providers failed: %w", err)
 	}
-
-	span.SetAttributes(attribute.Int("result_count", len(response.Results)))
-	return response.Results, nil
-}
-
-// buildQueryParams converts SearchOptions to URL query parameters.
-func (c *Client) buildQueryParams(opts SearchOptions) url.Values {
-	params := url.Values{}
-
-	params.Set("version", "2.1")
-
-	if opts.Number != "" {
-		params.Set("number", opts.Number)
-	}
-
-	if opts.EnumerationType != "" {
-		params.Set("enumeration_type", opts.EnumerationType)
-	}
-
-	if opts.FirstName != "" {
-		params.Set("first_name", opts.FirstName)
+	if err := response.validationError(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "query rejected")
+		return nil, err
 	}
 
-	if opts.LastName != "" {
-		params.Set("last_name", opts.LastName)
+	span.SetAttributes(c.traceAttrs(attribute.Int("result_count", len(response.Results)))...)
+	recordRetrievals(ctx, RetrievalMeta{Source: SourceAPI, FetchedAt: time.Now(), Partial: c.projecting()}, response.Results...)
+	c.hydrateStore(ctx, span, response.Results)
+	if cacheKey != "" && mode.writes() {
+		c.cache.setResults(cacheKey, response.Results)
 	}
+	return sortResults(response.Results, opts), nil
+}
 
-	if opts.OrganizationName != "" {
-		params.Set("organization_name", opts.OrganizationName)
+// sortResults orders providers by relevance in place if opts.SortByRelevance is set,
+// and returns them.
+func sortResults(providers []Provider, opts SearchOptions) []Provider {
+	if opts.SortByRelevance {
+		for i, ranked := range RankProviders(providers, opts) {
+			providers[i] = ranked.Provider
+		}
 	}
+	return providers
+}
 
-	if opts.TaxonomyDescription != "" {
-		params.Set("taxonomy_description", opts.TaxonomyDescription)
+// applyDefaults fills zero-valued Limit and CountryCode with the client defaults
+// and lowers Limit to MaxResults when set.
+// The default country code is not applied to NPI number lookups, which must match
+// regardless of the provider's country.
+func (c *Client) applyDefaults(opts SearchOptions) SearchOptions {
+	if opts.Limit == 0 && c.defaultLimit != 0 {
+		opts.Limit = c.defaultLimit
 	}
-
-	if opts.AddressPurpose != "" {
-		params.Set("address_purpose", opts.AddressPurpose)
+	if opts.CountryCode == "" && opts.Number == "" {
+		opts.CountryCode = c.defaultCountryCode
 	}
-
-	if opts.City != "" {
-		params.Set("city", opts.City)
+	if opts.MaxResults > 0 && (opts.Limit == 0 || opts.Limit > opts.MaxResults) {
+		opts.Limit = opts.MaxResults
 	}
+	return opts
+}
 
-	if opts.State != "" {
-		params.Set("state", opts.State)
+// searchPath is the path and fixed version parameter that start every API query.
+const searchPath = "/?version=2.1"
+
+// searchURL returns the API URL for opts. It writes the query directly into a
+// pre-sized buffer rather than going through url.Values, since it runs on every
+// lookup and search.
+func (c *Client) searchURL(opts SearchOptions) string {
+	params := [...]struct{ key, value string }{
+		{"number", opts.Number},
+		{"enumeration_type", opts.EnumerationType},
+		{"first_name", opts.FirstName},
+		{"last_name", opts.LastName},
+		{"organization_name", opts.OrganizationName},
+		{"taxonomy_description", opts.TaxonomyDescription},
+		{"address_purpose", opts.AddressPurpose},
+		{"city", opts.City},
+		{"state", opts.State},
+		{"postal_code", opts.PostalCode},
+		{"country_code", opts.CountryCode},
 	}
 
-	if opts.PostalCode != "" {
-		params.Set("postal_code", opts.PostalCode)
+	// Room for every key, value and "&" plus limit, skip and pretty; values needing
+	// escapes may still grow the buffer
+	size := len(c.searchPrefix) + len("&limit=000&skip=0000000000&pretty=true")
+	for _, p := range params {
+		if p.value != "" {
+			size += len(p.key) + len(p.value) + 2
+		}
 	}
 
-	if opts.CountryCode != "" {
-		params.Set("country_code", opts.CountryCode)
+	var b strings.Builder
+	b.Grow(size)
+	b.WriteString(c.searchPrefix)
+	for _, p := range params {
+		if p.value != "" {
+			b.WriteByte('&')
+			b.WriteString(p.key)
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(p.value))
+		}
 	}
 
 	// Set limit with validation
@@ -299,59 +701,73 @@ func (c *Client) buildQueryParams(opts SearchOptions) url.Values {
 	} else if limit > MaxLimit {
 		limit = MaxLimit
 	}
-	params.Set("limit", strconv.Itoa(limit))
+	var digits [20]byte
+	b.WriteString("&limit=")
+	b.Write(strconv.AppendInt(digits[:0], int64(limit), 10))
 
 	if opts.Skip > 0 {
-		params.Set("skip", strconv.Itoa(opts.Skip))
+		b.WriteString("&skip=")
+		b.Write(strconv.AppendInt(digits[:0], int64(opts.Skip), 10))
 	}
 
 	if opts.Pretty {
-		params.Set("pretty", "true")
+		b.WriteString("&pretty=true")
 	}
 
-	return params
+	return b.String()
 }
 
 // doRequestWithRetry performs an HTTP request with exponential backoff retry.
-func (c *Client) doRequestWithRetry(ctx context.Context, url string, result interface{}) error {
+func (c *Client) doRequestWithRetry(ctx context.Context, url string, result interface{}) (err error) {
 	ctx, span := c.tracer.Start(ctx, "doRequestWithRetry",
-		trace.WithAttributes(
-			attribute.String("url", url),
+		trace.WithAttributes(c.traceAttrs(
+			semconv.URLFull(url),
 			attribute.Int("max_retries", c.retry.MaxRetries),
-		),
+		)...),
 	)
 	defer span.End()
 
+	ctx, done, err := c.background.enter(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "client closed")
+		return err
+	}
+	defer done()
+
 	var lastErr error
+	var info responseInfo
+	start, attempts := time.Now(), 0
+	defer func() {
+		c.checkSlow(ctx, span, url, start, attempts, info, err)
+		c.recordCall(url, err)
+	}()
 
 	for attempt := 0; attempt <= c.retry.MaxRetries; attempt++ {
 		if attempt > 0 {
-			// Calculate delay with exponential backoff
-			delay := time.Duration(float64(c.retry.InitialDelay) * math.Pow(c.retry.BackoffMultiplier, float64(attempt-1)))
-			if delay > c.retry.MaxDelay {
-				delay = c.retry.MaxDelay
-			}
+			c.increment(MetricRetries)
 
 			// Wait before retry, respecting context cancellation
 			select {
 			case <-ctx.Done():
 				return fmt.Errorf("request cancelled: %w", ctx.Err())
-			case <-time.After(delay):
+			case <-time.After(c.retry.backoff(attempt)):
 			}
 		}
 
-		err := c.doRequest(ctx, url, result)
+		attempts++
+		err := c.doRequest(ctx, url, result, &info)
 		if err == nil {
-			span.SetAttributes(attribute.Int("attempts", attempt+1))
+			span.SetAttributes(c.traceAttrs(attribute.Int("attempts", attempt+1))...)
 			return nil
 		}
 
 		lastErr = err
 		span.AddEvent("retry_attempt",
-			trace.WithAttributes(
+			trace.WithAttributes(c.traceAttrs(
 				attribute.Int("attempt", attempt+1),
-				attribute.String("error", err.Error()),
-			),
+				semconv.ErrorTypeKey.String(errorType(err)),
+			)...),
 		)
 
 		// Don't retry on client errors (4xx)
@@ -360,6 +776,9 @@ func (c *Client) doRequestWithRetry(ctx context.Context, url string, result inte
 			span.SetStatus(codes.Error, "non-retryable error")
 			return err
 		}
+		if attempt < c.retry.MaxRetries {
+			c.notifyRetry(ctx, url, attempt+1, err)
+		}
 	}
 
 	span.RecordError(lastErr)
@@ -367,13 +786,23 @@ func (c *Client) doRequestWithRetry(ctx context.Context, url string, result inte
 	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-// doRequest performs a single HTTP GET request.
-func (c *Client) doRequest(ctx context.Context, url string, result interface{}) error {
+// backoff returns the delay before retry number attempt, counting from 1.
+func (r RetryConfig) backoff(attempt int) time.Duration {
+	delay := time.Duration(float64(r.InitialDelay) * math.Pow(r.BackoffMultiplier, float64(attempt-1)))
+	if delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+	return delay
+}
+
+// doRequest performs a single HTTP GET request, describing the response in info.
+func (c *Client) doRequest(ctx context.Context, url string, result interface{}, info *responseInfo) error {
 	ctx, span := c.tracer.Start(ctx, "doRequest",
-		trace.WithAttributes(
-			attribute.String("http.method", "GET"),
-			attribute.String("http.url", url),
-		),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(c.traceAttrs(
+			semconv.HTTPRequestMethodGet,
+			semconv.URLFull(url),
+		)...),
 	)
 	defer span.End()
 
@@ -381,82 +810,295 @@ func (c *Client) doRequest(ctx context.Context, url string, result interface{})
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to create request")
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w: %w", errInvalidURL, err)
+	}
+
+	if (req.URL.Scheme != "http" && req.URL.Scheme != "https") || req.URL.Host == "" {
+		err := fmt.Errorf("%w: %q", errInvalidURL, url)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request URL")
+		return err
 	}
 
+	span.SetAttributes(serverAttributes(req.URL)...)
+
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "gonpi/1.0")
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	if err := c.prepare(ctx, req, span, true); err != nil {
+		return err
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req, c.httpClient.Do)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "http request failed")
 		return fmt.Errorf("http request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	*info = responseInfo{status: resp.StatusCode}
+	body := countingReader{r: resp.Body, n: &info.bytes}
 
-	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	span.SetAttributes(c.traceAttrs(semconv.HTTPResponseStatusCode(resp.StatusCode))...)
 
 	if resp.StatusCode != http.StatusOK {
+		c.increment(MetricRequestErrors)
 		// Limit response body size to prevent memory exhaustion
-		limitedReader := io.LimitReader(resp.Body, MaxResponseBodySize)
+		limitedReader := io.LimitReader(body, MaxResponseBodySize)
 		body, _ := io.ReadAll(limitedReader)
 		apiErr := &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    fmt.Sprintf("API returned status %d: %s", resp.StatusCode, string(body)),
 		}
+		span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
 		span.RecordError(apiErr)
 		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
 		return apiErr
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+	if c.drift != nil && !c.strictDecoding {
+		return c.decodeChecked(body, result, span)
+	}
+	if response, ok := result.(*APIResponse); ok && c.projecting() {
+		return c.decodeProjected(body, response, span)
+	}
+	if c.jsonDecoder != nil && !c.strictDecoding {
+		return c.decodeWith(body, result, span)
+	}
+
+	decoder := json.NewDecoder(body)
+	if c.strictDecoding {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(result); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to decode response")
+		if c.strictDecoding {
+			return fmt.Errorf("failed to decode response: %w: %w", ErrSchemaMismatch, err)
+		}
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return nil
 }
 
+// prepare applies the call metadata in ctx to req and waits for the rate limiter.
+// Metadata headers replace those already on req if override is set and are otherwise
+// only added where missing.
+func (c *Client) prepare(ctx context.Context, req *http.Request, span trace.Span, override bool) error {
+	md, _ := CallMetadataFromContext(ctx)
+	md.Priority = c.priorityFor(md)
+	for key, values := range md.Headers {
+		if override || req.Header.Get(key) == "" {
+			req.Header[key] = values
+		}
+	}
+	span.SetAttributes(c.traceAttrs(metadataAttributes(md)...)...)
+
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx, md.Priority); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "rate limit wait cancelled")
+			return fmt.Errorf("rate limit wait cancelled: %w", err)
+		}
+	}
+
+	c.increment(MetricRequests)
+	if md.Caller != "" {
+		c.increment(metricRequestsByCaller + metricName(md.Caller))
+	}
+	return nil
+}
+
+// send sends req with do, recording its duration and transport errors and reporting
+// it to the audit log.
+func (c *Client) send(req *http.Request, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if c.adaptive != nil {
+		if err := c.adaptive.acquire(req.Context()); err != nil {
+			return nil, fmt.Errorf("concurrency wait cancelled: %w", err)
+		}
+	}
+	start := time.Now()
+	resp, err := do(req)
+	duration := time.Since(start)
+	if c.adaptive != nil {
+		c.adaptive.release(start, resp, err)
+		c.observe(MetricConcurrencyLimit, float64(c.adaptive.Limit()))
+	}
+	c.observe(MetricRequestDuration, float64(duration)/float64(time.Millisecond))
+	if err != nil {
+		c.increment(MetricRequestErrors)
+	}
+	c.recordAudit(req, resp, err, start, duration)
+	return resp, err
+}
+
+// serverAttributes returns the semantic-convention server.address and server.port
+// attributes for u, inferring the port from the scheme when it is not explicit.
+func serverAttributes(u *url.URL) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.ServerAddress(u.Hostname())}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		switch u.Scheme {
+		case "https":
+			port = 443
+		case "http":
+			port = 80
+		}
+	}
+	if port > 0 {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+	return attrs
+}
+
 // shouldRetry determines if an error is retryable.
 func (c *Client) shouldRetry(err error) bool {
-	if apiErr, ok := err.(*APIError); ok {
-		// Retry on 5xx server errors and 429 rate limit
-		return apiErr.StatusCode >= 500 || apiErr.StatusCode == 429
+	return isRetryable(err)
+}
+
+// isRetryable reports whether err is worth another attempt.
+func isRetryable(err error) bool {
+	if IsValidation(err) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrSchemaMismatch) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		// Retry on 5xx server errors, 429 rate limit and 408 request timeout
+		return apiErr.StatusCode >= 500 ||
+			apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode == http.StatusRequestTimeout
+	}
+	return isTransientError(err)
+}
+
+// isTransientError classifies non-API errors. Timeouts, refused or reset
+// connections and truncated responses are considered transient; DNS lookups
+// for hosts that do not exist, malformed URLs, TLS/certificate failures and
+// caller cancellation are permanent and retrying them only adds latency.
+func isTransientError(err error) bool {
+	if errors.Is(err, errInvalidURL) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
 	}
-	// Retry on network errors
+
+	var (
+		certVerifyErr   *tls.CertificateVerificationError
+		recordHeaderErr tls.RecordHeaderError
+		unknownAuthErr  x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		certInvalidErr  x509.CertificateInvalidError
+	)
+	if errors.As(err, &certVerifyErr) || errors.As(err, &recordHeaderErr) ||
+		errors.As(err, &unknownAuthErr) || errors.As(err, &hostnameErr) ||
+		errors.As(err, &certInvalidErr) {
+		return false
+	}
+
+	// Timeouts, resets and other network errors are retried
 	return true
 }
 
-// getCached retrieves a provider from cache if available and not expired.
-func (c *Client) getCached(npi string) *Provider {
-	c.cache.mu.RLock()
-	defer c.cache.mu.RUnlock()
+// getCached retrieves a provider from the in-memory cache or the backend, if available
+// and not expired.
+func (c *Client) getCached(ctx context.Context, npi string) (*Provider, error) {
+	key := c.cacheKey(npi)
+	if c.cache.enabled {
+		c.cache.mu.RLock()
+		entry, exists := c.cache.data[key]
+		c.cache.mu.RUnlock()
+		if exists && time.Now().Before(entry.expiresAt) {
+			recordRetrievals(ctx, RetrievalMeta{Source: SourceCache, FetchedAt: entry.fetchedAt}, *entry.provider)
+			return entry.provider, nil
+		}
+	}
 
-	entry, exists := c.cache.data[npi]
-	if !exists || time.Now().After(entry.expiresAt) {
-		return nil
+	if c.cacheBackend != nil {
+		provider, ok, err := c.cacheBackend.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("cache backend get failed: %w", err)
+		}
+		if ok && provider != nil {
+			recordRetrievals(ctx, RetrievalMeta{Source: SourceCache}, *provider)
+			return provider, nil
+		}
 	}
+	return nil, nil
+}
 
-	return entry.provider
+// setCached stores a provider in every configured cache layer.
+func (c *Client) setCached(ctx context.Context, npi string, provider *Provider) error {
+	key := c.cacheKey(npi)
+	if c.cache.enabled {
+		// Hits share one copy, unaffected by changes to provider
+		cached := *provider
+		now := time.Now()
+		c.cache.mu.Lock()
+		c.cache.data[key] = &cacheEntry{
+			provider:  &cached,
+			expiresAt: now.Add(c.cache.ttl),
+			fetchedAt: now,
+		}
+		c.cache.mu.Unlock()
+	}
+
+	if buf := cacheWriteBufferFrom(ctx); buf != nil {
+		buf.add(ctx, CacheEntry{Key: key, Provider: provider})
+		return nil
+	}
+	if c.cacheBackend != nil {
+		if err := c.cacheBackend.Set(ctx, key, provider, c.cache.ttl); err != nil {
+			return fmt.Errorf("cache backend set failed: %w", err)
+		}
+	}
+	return nil
 }
 
-// setCached stores a provider in cache.
-func (c *Client) setCached(npi string, provider *Provider) {
-	c.cache.mu.Lock()
-	defer c.cache.mu.Unlock()
+// deleteCached removes a provider from the in-memory cache and from the backend, if it
+// implements CacheDeleter.
+func (c *Client) deleteCached(ctx context.Context, npi string) error {
+	key := c.cacheKey(npi)
+	if c.cache.enabled {
+		c.cache.mu.Lock()
+		delete(c.cache.data, key)
+		c.cache.mu.Unlock()
+	}
+
+	if deleter, ok := c.cacheBackend.(CacheDeleter); ok {
+		if err := deleter.Delete(ctx, key); err != nil {
+			return fmt.Errorf("cache backend delete failed: %w", err)
+		}
+	}
+	return nil
+}
 
-	c.cache.data[npi] = &cacheEntry{
-		provider:  provider,
-		expiresAt: time.Now().Add(c.cache.ttl),
+// stopCleanup stops the cleanup goroutine, if running, and waits for it to exit.
+func (s *cacheStore) stopCleanup() {
+	if s.cleanupCancel == nil {
+		return
 	}
+	s.cleanupCancel()
+	<-s.cleanupDone
 }
 
-// cleanupCache periodically removes expired cache entries.
-func (c *Client) cleanupCache() {
-	// Use cache TTL as cleanup interval, minimum 1 minute
-	interval := c.cache.ttl
+// cleanup periodically removes expired cache entries until the cache is stopped.
+func (s *cacheStore) cleanup() {
+	defer close(s.cleanupDone)
+
+	// Use the shortest tier TTL as cleanup interval, minimum 1 minute
+	interval := s.ttl
+	for _, ttl := range []time.Duration{s.searchTTL, s.countTTL} {
+		if ttl > 0 && ttl < interval {
+			interval = ttl
+		}
+	}
 	if interval < time.Minute {
 		interval = time.Minute
 	}
@@ -466,96 +1108,68 @@ func (c *Client) cleanupCache() {
 
 	for {
 		select {
-		case <-c.cache.cleanupCtx.Done():
+		case <-s.cleanupCtx.Done():
 			return
 		case <-ticker.C:
-			c.cache.mu.Lock()
+			s.mu.Lock()
 			now := time.Now()
-			for key, entry := range c.cache.data {
+			for key, entry := range s.data {
 				if now.After(entry.expiresAt) {
-					delete(c.cache.data, key)
+					delete(s.data, key)
 				}
 			}
-			c.cache.mu.Unlock()
+			s.mu.Unlock()
 		}
 	}
 }
 
-// APIError represents an error from the NPI Registry API.
-type APIError struct {
-	StatusCode int
-	Message    string
-}
-
-func (e *APIError) Error() string {
-	return e.Message
-}
-
 // GetProvidersByNPIs retrieves multiple providers by NPI number in a single batch operation.
 // The function takes a list of NPI numbers and returns a map of successfully fetched providers.
-// If any of the NPI numbers result in an error, the function will return an error containing the first error encountered.
+// If any of the NPI numbers result in an error, the function will return the successfully fetched
+// providers along with an error joining every failure.
+// By default the batch continues through failures; use WithBatchFailFast or WithBatchMaxFailures
+// to cancel outstanding lookups early, in which case the error also wraps ErrBatchAborted.
+// NPIs are cleaned up with NormalizeNPI and results are keyed by the normalized NPI;
+// malformed NPIs are reported as validation errors. Duplicate NPIs are fetched once.
 // The function is designed to be safe for concurrent use and will limit the number of concurrent requests to the API.
-func (c *Client) GetProvidersByNPIs(ctx context.Context, npis []string) (map[string]*Provider, error) {
+func (c *Client) GetProvidersByNPIs(ctx context.Context, npis []string, opts ...BatchOption) (map[string]*Provider, error) {
 	ctx, span := c.tracer.Start(ctx, "GetProvidersByNPIs",
-		trace.WithAttributes(
+		trace.WithAttributes(c.traceAttrs(
 			attribute.Int("npi_count", len(npis)),
-		),
+		)...),
 	)
 	defer span.End()
 
 	if len(npis) == 0 {
-		err := fmt.Errorf("npi list cannot be empty")
+		err := &ValidationError{Field: "npis", Message: "npi list cannot be empty"}
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	var resultMap sync.Map
-	var wg sync.WaitGroup
-
-	// Limit concurrent requests to avoid overwhelming the API
-	semaphore := make(chan struct{}, 5)
-	errChan := make(chan error, len(npis))
-
-	for _, npi := range npis {
-		wg.Add(1)
-		go func(npi string) {
-			defer wg.Done()
-
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			provider, err := c.GetProviderByNPI(ctx, npi)
-			if err != nil {
-				errChan <- fmt.Errorf("failed to fetch NPI %s: %w", npi, err)
-				return
-			}
-			if provider != nil {
-				resultMap.Store(npi, provider)
-			}
-		}(npi)
-	}
-
-	wg.Wait()
-	close(errChan)
+	normalized, errs := normalizeNPIs(npis)
+	unique := uniqueNPIs(normalized)
+	outcomes, aborted := c.runBatch(ctx, unique, newBatchConfig(opts))
 
-	// Collect results from sync.Map
 	results := make(map[string]*Provider)
-	resultMap.Range(func(key, value any) bool {
-		results[key.(string)] = value.(*Provider)
-		return true
-	})
-
-	// Collect any errors
-	var errs []error
-	for err := range errChan {
-		errs = append(errs, err)
+	for _, npi := range unique {
+		outcome := outcomes[npi]
+		switch {
+		case outcome.err != nil:
+			errs = append(errs, fmt.Errorf("failed to fetch NPI %s: %w", npi, outcome.err))
+		case outcome.provider != nil:
+			results[npi] = outcome.provider
+		}
 	}
 
-	span.SetAttributes(
+	span.SetAttributes(c.traceAttrs(
 		attribute.Int("successful_fetches", len(results)),
 		attribute.Int("failed_fetches", len(errs)),
-	)
+	)...)
+
+	if aborted {
+		errs = append(errs, ErrBatchAborted)
+	}
 
 	if len(errs) > 0 {
 		err := errors.Join(errs...)
@@ -2,13 +2,18 @@ package gonpi
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -752,8 +757,8 @@ func TestAPIResponse_Unmarshal(t *testing.T) {
 // Extended Client Tests
 // ============================================================================
 
-// TestBuildQueryParams tests the URL parameter building logic.
-func TestBuildQueryParams(t *testing.T) {
+// TestSearchURL tests the URL parameter building logic.
+func TestSearchURL(t *testing.T) {
 	tests := []struct {
 		name string
 		opts SearchOptions
@@ -832,6 +837,19 @@ func TestBuildQueryParams(t *testing.T) {
 				"limit":     "10",
 			},
 		},
+		{
+			name: "values are escaped",
+			opts: SearchOptions{
+				OrganizationName: "Smith & Sons Clinic",
+				City:             "St. Louis=MO",
+			},
+			want: map[string]string{
+				"version":           "2.1",
+				"organization_name": "Smith & Sons Clinic",
+				"city":              "St. Louis=MO",
+				"limit":             "10",
+			},
+		},
 		{
 			name: "skip zero not included",
 			opts: SearchOptions{
@@ -849,7 +867,14 @@ func TestBuildQueryParams(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := NewClient()
-			params := client.buildQueryParams(tt.opts)
+			apiURL := client.searchURL(tt.opts)
+			if !strings.HasPrefix(apiURL, DefaultBaseURL+"/?") {
+				t.Fatalf("unexpected URL %s", apiURL)
+			}
+			params, err := url.ParseQuery(strings.TrimPrefix(apiURL, DefaultBaseURL+"/?"))
+			if err != nil {
+				t.Fatalf("invalid query in %s: %v", apiURL, err)
+			}
 
 			for key, expectedValue := range tt.want {
 				if got := params.Get(key); got != expectedValue {
@@ -1231,11 +1256,49 @@ func TestShouldRetry(t *testing.T) {
 			},
 			wantRetry: false,
 		},
+		{
+			name: "API error 408",
+			err: &APIError{
+				StatusCode: 408,
+				Message:    "Request Timeout",
+			},
+			wantRetry: true,
+		},
 		{
 			name:      "network error",
 			err:       errors.New("network error"),
 			wantRetry: true,
 		},
+		{
+			name:      "wrapped API error 503",
+			err:       fmt.Errorf("wrapped: %w", &APIError{StatusCode: 503}),
+			wantRetry: true,
+		},
+		{
+			name:      "DNS not found",
+			err:       &url.Error{Op: "Get", URL: "https://nope.invalid", Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}},
+			wantRetry: false,
+		},
+		{
+			name:      "DNS timeout",
+			err:       &url.Error{Op: "Get", URL: "https://slow.example", Err: &net.DNSError{Err: "i/o timeout", Name: "slow.example", IsTimeout: true}},
+			wantRetry: true,
+		},
+		{
+			name:      "TLS unknown authority",
+			err:       &url.Error{Op: "Get", URL: "https://self-signed.example", Err: x509.UnknownAuthorityError{}},
+			wantRetry: false,
+		},
+		{
+			name:      "invalid URL",
+			err:       fmt.Errorf("failed to create request: %w", errInvalidURL),
+			wantRetry: false,
+		},
+		{
+			name:      "context canceled",
+			err:       &url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled},
+			wantRetry: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1248,6 +1311,35 @@ func TestShouldRetry(t *testing.T) {
 	}
 }
 
+// TestInvalidBaseURL_NotRetried tests that malformed base URLs fail immediately.
+func TestInvalidBaseURL_NotRetried(t *testing.T) {
+	for _, baseURL := range []string{"://bad", "ftp://example.com", "no-scheme"} {
+		t.Run(baseURL, func(t *testing.T) {
+			client := NewClient(
+				WithBaseURL(baseURL),
+				WithRetry(RetryConfig{
+					MaxRetries:        3,
+					InitialDelay:      time.Second,
+					MaxDelay:          time.Second,
+					BackoffMultiplier: 1.0,
+				}),
+			)
+
+			start := time.Now()
+			_, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Smith"})
+			if err == nil {
+				t.Fatal("expected error for invalid base URL")
+			}
+			if !errors.Is(err, errInvalidURL) {
+				t.Errorf("expected errInvalidURL, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed >= time.Second {
+				t.Errorf("expected no retries, took %v", elapsed)
+			}
+		})
+	}
+}
+
 // TestAPIError_Error tests the APIError error message.
 func TestAPIError_Error(t *testing.T) {
 	err := &APIError{
@@ -1344,7 +1436,7 @@ func TestSearchProviders_EmptyResults(t *testing.T) {
 
 	opts := SearchOptions{
 		LastName: "NonExistentName",
-		State:    "XX",
+		State:    "WY",
 	}
 
 	results, err := client.SearchProviders(context.Background(), opts)
@@ -1357,6 +1449,37 @@ func TestSearchProviders_EmptyResults(t *testing.T) {
 	}
 }
 
+// TestSearchProviders_ResponseErrors tests that errors reported in a 200 response are
+// returned as a ValidationError rather than an empty result set.
+func TestSearchProviders_ResponseErrors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Errors": [{"description": "Field state requires additional search criteria", "field": "state", "number": "07"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithCache(time.Minute))
+
+	results, err := client.SearchProviders(context.Background(), SearchOptions{State: "WY"})
+	if !IsValidation(err) || results != nil {
+		t.Fatalf("expected ValidationError and no results, got %v, %v", results, err)
+	}
+	var validationErr *ValidationError
+	errors.As(err, &validationErr)
+	if validationErr.Field != "state" || !strings.Contains(validationErr.Message, "requires additional search criteria") {
+		t.Errorf("unexpected error: %+v", validationErr)
+	}
+	if requests != 1 {
+		t.Errorf("expected no retries, got %d requests", requests)
+	}
+
+	if _, err := client.GetProviderByNPI(context.Background(), "1234567893"); !IsValidation(err) {
+		t.Errorf("expected ValidationError from GetProviderByNPI, got %v", err)
+	}
+}
+
 // TestConcurrentRequests tests thread safety of concurrent requests.
 func TestConcurrentRequests(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1554,7 +1677,7 @@ func TestBatchOperationsSyncMap(t *testing.T) {
 	// Test with many NPIs to ensure sync.Map handles concurrent writes
 	npis := make([]string, 20)
 	for i := 0; i < 20; i++ {
-		npis[i] = fmt.Sprintf("123456789%d", i)
+		npis[i] = fmt.Sprintf("12345678%02d", i)
 	}
 
 	results, err := client.GetProvidersByNPIs(context.Background(), npis)
@@ -1777,3 +1900,328 @@ func TestMultipleCacheConfigurations(t *testing.T) {
 		})
 	}
 }
+
+// TestClientDefaults tests WithDefaultLimit and WithDefaultCountryCode.
+func TestClientDefaults(t *testing.T) {
+	var last url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r.URL.Query()
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithDefaultLimit(200),
+		WithDefaultCountryCode("MX"),
+	)
+
+	if _, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Garcia"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last.Get("limit") != "200" || last.Get("country_code") != "MX" {
+		t.Errorf("expected defaults to be applied, got %v", last)
+	}
+
+	if _, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Garcia", Limit: 5, CountryCode: "US"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last.Get("limit") != "5" || last.Get("country_code") != "US" {
+		t.Errorf("expected explicit values to win, got %v", last)
+	}
+
+	if _, err := client.GetProviderByNPI(context.Background(), "1234567890"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last.Get("limit") != "1" || last.Has("country_code") {
+		t.Errorf("expected NPI lookup to skip defaults, got %v", last)
+	}
+}
+
+// TestClientWith tests that derived clients share caches but override settings.
+func TestClientWith(t *testing.T) {
+	var requests int
+	var lastAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		lastAgent = r.Header.Get("User-Agent")
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	parent := NewClient(WithBaseURL(server.URL), WithCache(time.Minute), WithHeader("User-Agent", "interactive"))
+	defer parent.Close()
+	background := parent.With(WithHeader("User-Agent", "batch-job"), WithRetry(RetryConfig{MaxRetries: 7}))
+
+	if background.retry.MaxRetries != 7 || parent.retry.MaxRetries != DefaultMaxRetries {
+		t.Errorf("retry override leaked: parent=%d derived=%d", parent.retry.MaxRetries, background.retry.MaxRetries)
+	}
+
+	ctx := context.Background()
+	if _, err := background.GetProviderByNPI(ctx, "1234567890"); err != nil {
+		t.Fatalf("derived lookup: %v", err)
+	}
+	if lastAgent != "batch-job" {
+		t.Errorf("derived User-Agent = %q", lastAgent)
+	}
+
+	// The parent is served from the cache filled by the derived client
+	if _, err := parent.GetProviderByNPI(ctx, "1234567890"); err != nil {
+		t.Fatalf("parent lookup: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected shared cache hit, got %d requests", requests)
+	}
+
+	// Closing the derived client leaves the parent's cache running
+	background.Close()
+	if parent.cache.cleanupCtx.Err() != nil {
+		t.Error("closing a derived client stopped the parent's cache")
+	}
+
+	if _, err := parent.SearchProviders(ctx, SearchOptions{LastName: "Doe"}); err != nil {
+		t.Fatalf("parent search: %v", err)
+	}
+	if lastAgent != "interactive" {
+		t.Errorf("parent User-Agent = %q", lastAgent)
+	}
+}
+
+// closingBackend is a CacheBackend that records Close calls.
+type closingBackend struct {
+	*mapCacheBackend
+	closed atomic.Int32
+	err    error
+}
+
+func (b *closingBackend) Close() error {
+	b.closed.Add(1)
+	return b.err
+}
+
+// TestClientClose_Lifecycle tests that Close stops watchers, schedulers and the cache
+// cleanup goroutine, then closes backends exactly once.
+func TestClientClose_Lifecycle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	backend := &closingBackend{mapCacheBackend: newMapCacheBackend(), err: errors.New("flush failed")}
+	client := NewClient(WithBaseURL(server.URL), WithCache(time.Minute), WithCacheBackend(backend))
+
+	watcher := client.NewWatcher([]string{"1234567890"})
+	runErr := make(chan error, 1)
+	go func() { runErr <- watcher.Run(context.Background(), time.Millisecond, nil) }()
+
+	var runs atomic.Int32
+	scheduler := client.NewScheduler()
+	scheduler.Add("tick", Every(time.Millisecond, 0), func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	scheduler.Start(context.Background())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runs.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := client.Close(); err == nil || err.Error() != "flush failed" {
+		t.Errorf("Close error = %v, want backend error", err)
+	}
+	select {
+	case err := <-runErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("watcher Run returned %v", err)
+		}
+	default:
+		t.Error("Close returned before the watcher stopped")
+	}
+	select {
+	case <-client.cache.cleanupDone:
+	default:
+		t.Error("Close returned before the cache cleanup goroutine exited")
+	}
+
+	stopped := runs.Load()
+	time.Sleep(10 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Error("scheduled task kept running after Close")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+	if n := backend.closed.Load(); n != 1 {
+		t.Errorf("backend closed %d times, want 1", n)
+	}
+
+	// Watchers started after Close return immediately
+	if err := watcher.Run(context.Background(), time.Millisecond, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run after Close = %v", err)
+	}
+}
+
+// TestClientShutdown tests that Shutdown refuses new calls and lets in-flight calls,
+// scheduled tasks and Transport responses finish.
+func TestClientShutdown(t *testing.T) {
+	release := make(chan struct{})
+	var arrived sync.WaitGroup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+		<-release
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	arrived.Add(3)
+	lookup := make(chan error, 1)
+	go func() {
+		_, err := client.GetProviderByNPI(ctx, "1234567890")
+		lookup <- err
+	}()
+	task := make(chan error, 1)
+	scheduler := client.NewScheduler()
+	scheduler.Add("refresh", Every(time.Millisecond, 0), func(ctx context.Context) error {
+		_, err := client.GetProviderByNPI(ctx, "1234567891")
+		task <- err
+		return err
+	})
+	scheduler.Start(ctx)
+	files := &http.Client{Transport: client.Transport()}
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := files.Get(server.URL + "/?number=1234567892")
+		if err != nil {
+			t.Error(err)
+		}
+		responses <- resp
+	}()
+	arrived.Wait()
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- client.Shutdown(ctx) }()
+	<-client.background.draining
+	if _, err := client.GetProviderByNPI(ctx, "1234567893"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("new call while draining = %v, want ErrClientClosed", err)
+	}
+
+	close(release)
+	if err := <-lookup; err != nil {
+		t.Errorf("in-flight lookup failed: %v", err)
+	}
+	if err := <-task; err != nil {
+		t.Errorf("running scheduled task failed: %v", err)
+	}
+	resp := <-responses
+	if resp == nil {
+		t.FailNow()
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned before the Transport response was read: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("reading the response while draining: %v", err)
+	}
+	resp.Body.Close()
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+	if _, err := files.Get(server.URL); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Transport after Shutdown = %v", err)
+	}
+}
+
+// TestClientShutdown_Derived tests that a parent's Shutdown drains the calls of its
+// derived clients and refuses new ones, and that its Close stops them.
+func TestClientShutdown_Derived(t *testing.T) {
+	release := make(chan struct{})
+	arrived := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+	derived := client.With(WithHeader("X-Job", "refresh"))
+	ctx := context.Background()
+
+	lookup := make(chan error, 1)
+	go func() {
+		_, err := derived.GetProviderByNPI(ctx, "1234567890")
+		lookup <- err
+	}()
+	<-arrived
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- client.Shutdown(ctx) }()
+	<-derived.background.draining
+	if _, err := derived.GetProviderByNPI(ctx, "1234567891"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("new derived call while draining = %v, want ErrClientClosed", err)
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned before the derived call finished: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-lookup; err != nil {
+		t.Errorf("in-flight derived lookup failed: %v", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown = %v", err)
+	}
+
+	// Clients derived after Close are closed too
+	if _, err := client.With().GetProviderByNPI(ctx, "1234567892"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("call on a client derived after Close = %v, want ErrClientClosed", err)
+	}
+
+	// Closing a derived client leaves its parent open
+	parent := NewClient(WithBaseURL(server.URL))
+	defer parent.Close()
+	parent.With().Close()
+	if _, err := parent.GetProviderByNPI(ctx, "1234567893"); err != nil {
+		t.Errorf("parent call after closing a derived client = %v", err)
+	}
+}
+
+// TestClientShutdown_Deadline tests that Shutdown cancels calls still in flight at the
+// deadline.
+func TestClientShutdown_Deadline(t *testing.T) {
+	arrived := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(arrived)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL), WithRetry(RetryConfig{MaxRetries: 0}))
+
+	lookup := make(chan error, 1)
+	go func() {
+		_, err := client.GetProviderByNPI(context.Background(), "1234567890")
+		lookup <- err
+	}()
+	<-arrived
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want the deadline", err)
+	}
+	select {
+	case err := <-lookup:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled lookup = %v", err)
+		}
+	default:
+		t.Error("Shutdown returned before cancelling the in-flight lookup")
+	}
+}
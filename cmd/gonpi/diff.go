@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+	"github.com/sdsvn/gonpi/nppes"
+)
+
+// runDiff implements "gonpi diff", which compares two NPPES provider files, or a store
+// snapshot and a file, and writes the change events as NDJSON.
+func runDiff(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gonpi diff [flags] OLD NEW")
+		fmt.Fprintln(stderr, "       gonpi diff [flags] -snapshot FILE NEW")
+		fs.PrintDefaults()
+	}
+	snapshot := fs.String("snapshot", "", "store snapshot to compare NEW with instead of an OLD file")
+	out := fs.String("out", "-", "file to write NDJSON events to, or - for stdout")
+	tmp := fs.String("tmp", "", "directory for temporary sort files (default: system temp directory)")
+	memory := fs.Int("memory", 64, "memory budget for each input, in MiB")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	want := 2
+	if *snapshot != "" {
+		want = 1
+	}
+	if fs.NArg() != want {
+		fs.Usage()
+		return errors.New("diff: wrong number of files")
+	}
+
+	w := stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	publisher := &ndjsonPublisher{enc: json.NewEncoder(w)}
+	opts := []nppes.DiffOption{nppes.WithDiffMemory(*memory << 20)}
+	if *tmp != "" {
+		opts = append(opts, nppes.WithDiffDir(*tmp))
+	}
+
+	newFile, err := openProviderFile(fs.Arg(fs.NArg() - 1))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	defer newFile.Close()
+
+	var stats nppes.DiffStats
+	if *snapshot != "" {
+		store := gonpi.NewMemoryStore()
+		if err := restoreFile(store, *snapshot); err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+		stats, err = nppes.DiffStore(ctx, store, newFile, publisher, opts...)
+	} else {
+		oldFile, openErr := openProviderFile(fs.Arg(0))
+		if openErr != nil {
+			return fmt.Errorf("diff: %w", openErr)
+		}
+		defer oldFile.Close()
+		stats, err = nppes.Diff(ctx, oldFile, newFile, publisher, opts...)
+	}
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	fmt.Fprintf(stderr, "%d created, %d updated, %d deactivated, %d reactivated, %d unchanged (%d old, %d new) in %s\n",
+		stats.Created, stats.Updated, stats.Deactivated, stats.Reactivated, stats.Unchanged,
+		stats.Old, stats.New, stats.Duration.Round(time.Millisecond))
+	return nil
+}
+
+// openProviderFile opens a provider file, or the provider file in a dissemination
+// archive if path ends in .zip.
+func openProviderFile(path string) (io.ReadCloser, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return os.Open(path)
+	}
+	archive, err := nppes.OpenArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := archive.Open(nppes.EntryData)
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return archiveFile{ReadCloser: data, archive: archive}, nil
+}
+
+// archiveFile is an archive entry that closes its archive when closed.
+type archiveFile struct {
+	io.ReadCloser
+	archive *nppes.Archive
+}
+
+func (f archiveFile) Close() error {
+	return errors.Join(f.ReadCloser.Close(), f.archive.Close())
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+	"github.com/sdsvn/gonpi/nppes"
+)
+
+// writeProviderFile writes a provider file in the newest layout with one row per
+// entry of rows, each mapping fields to values, and returns its path.
+func writeProviderFile(t *testing.T, name string, rows ...map[nppes.Field]string) string {
+	t.Helper()
+	fields := []nppes.Field{
+		nppes.FieldNPI, nppes.FieldEntityType, nppes.FieldReplacementNPI, nppes.FieldOrganizationName,
+		nppes.FieldLastName, nppes.FieldFirstName, nppes.FieldMiddleName, nppes.FieldNamePrefix,
+		nppes.FieldNameSuffix, nppes.FieldCredential, nppes.FieldMailingAddress1, nppes.FieldMailingAddress2,
+		nppes.FieldMailingCity, nppes.FieldMailingState, nppes.FieldMailingPostalCode,
+		nppes.FieldMailingCountryCode, nppes.FieldMailingTelephone, nppes.FieldMailingFax,
+		nppes.FieldLocationAddress1, nppes.FieldLocationAddress2, nppes.FieldLocationCity,
+		nppes.FieldLocationState, nppes.FieldLocationPostalCode, nppes.FieldLocationCountryCode,
+		nppes.FieldLocationTelephone, nppes.FieldLocationFax, nppes.FieldEnumerationDate,
+		nppes.FieldLastUpdateDate, nppes.FieldDeactivationReason, nppes.FieldDeactivationDate,
+		nppes.FieldReactivationDate, nppes.FieldGender, nppes.FieldOfficialLastName,
+		nppes.FieldOfficialFirstName, nppes.FieldOfficialMiddleName, nppes.FieldOfficialTitle,
+		nppes.FieldOfficialTelephone, nppes.FieldOfficialCredential, nppes.FieldSoleProprietor,
+		nppes.FieldOrganizationalSubpart, nppes.FieldCertificationDate,
+	}
+	for n := 1; n <= nppes.MaxTaxonomies; n++ {
+		for _, group := range []nppes.Field{nppes.FieldTaxonomyCode, nppes.FieldTaxonomyLicense, nppes.FieldTaxonomyState, nppes.FieldTaxonomyPrimary, nppes.FieldTaxonomyGroup} {
+			fields = append(fields, nppes.Indexed(group, n))
+		}
+	}
+	for n := 1; n <= nppes.MaxOtherIdentifiers; n++ {
+		for _, group := range []nppes.Field{nppes.FieldIdentifier, nppes.FieldIdentifierType, nppes.FieldIdentifierState, nppes.FieldIdentifierIssuer} {
+			fields = append(fields, nppes.Indexed(group, n))
+		}
+	}
+
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i], _ = nppes.Layouts[0].Column(field)
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(header)
+	for _, values := range rows {
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = values[field]
+		}
+		w.Write(row)
+	}
+	w.Flush()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestRunDiff tests diffing two provider files into an NDJSON file.
+func TestRunDiff(t *testing.T) {
+	row := func(npi, city string) map[nppes.Field]string {
+		return map[nppes.Field]string{nppes.FieldNPI: npi, nppes.FieldEntityType: "1", nppes.FieldLastName: "DOE", nppes.FieldLocationCity: city}
+	}
+	old := writeProviderFile(t, "old.csv", row("1234567893", "BOSTON"), row("1245319599", "BOSTON"))
+	new := writeProviderFile(t, "new.csv", row("1245319599", "CAMBRIDGE"), row("1356789012", "BOSTON"))
+	out := filepath.Join(t.TempDir(), "events.ndjson")
+
+	var stderr bytes.Buffer
+	args := []string{"diff", "-out", out, "-tmp", t.TempDir(), old, new}
+	if err := run(context.Background(), args, &bytes.Buffer{}, &stderr); err != nil {
+		t.Fatalf("diff failed: %v (%s)", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "1 created, 1 updated, 1 deactivated") {
+		t.Errorf("unexpected summary: %s", stderr.String())
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event gonpi.ChangeEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event line: %v", err)
+		}
+		got = append(got, event.NPI+" "+string(event.Type))
+	}
+	want := []string{
+		"1234567893 " + string(gonpi.EventProviderDeactivated),
+		"1245319599 " + string(gonpi.EventProviderUpdated),
+		"1356789012 " + string(gonpi.EventProviderCreated),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+// TestRunDiff_Args tests that diff requires two files, or one with -snapshot.
+func TestRunDiff_Args(t *testing.T) {
+	for _, args := range [][]string{{"diff", "old.csv"}, {"diff", "-snapshot", "store.snap", "old.csv", "new.csv"}} {
+		if err := run(context.Background(), args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "wrong number of files") {
+			t.Errorf("%v: expected an argument error, got %v", args, err)
+		}
+	}
+}
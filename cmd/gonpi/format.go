@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// column is a named provider field that can be rendered in table or CSV output.
+type column struct {
+	header string
+	value  func(p gonpi.Provider) string
+}
+
+var columns = map[string]column{
+	"number":       {"NPI", func(p gonpi.Provider) string { return p.Number }},
+	"type":         {"TYPE", func(p gonpi.Provider) string { return p.EnumerationType }},
+	"name":         {"NAME", func(p gonpi.Provider) string { return p.FullName() }},
+	"first_name":   {"FIRST NAME", func(p gonpi.Provider) string { return p.Basic.FirstName }},
+	"last_name":    {"LAST NAME", func(p gonpi.Provider) string { return p.Basic.LastName }},
+	"organization": {"ORGANIZATION", func(p gonpi.Provider) string { return p.Basic.OrganizationName }},
+	"credential":   {"CREDENTIAL", func(p gonpi.Provider) string { return p.Basic.Credential }},
+	"status":       {"STATUS", func(p gonpi.Provider) string { return p.Basic.Status }},
+	"taxonomy":     {"TAXONOMY", func(p gonpi.Provider) string { return primaryTaxonomy(p).Desc }},
+	"taxonomy_code": {"TAXONOMY CODE", func(p gonpi.Provider) string {
+		return primaryTaxonomy(p).Code
+	}},
+	"address":      {"ADDRESS", func(p gonpi.Provider) string { return locationAddress(p).Address1 }},
+	"city":         {"CITY", func(p gonpi.Provider) string { return locationAddress(p).City }},
+	"state":        {"STATE", func(p gonpi.Provider) string { return locationAddress(p).State }},
+	"postal_code":  {"POSTAL CODE", func(p gonpi.Provider) string { return locationAddress(p).PostalCode }},
+	"country":      {"COUNTRY", func(p gonpi.Provider) string { return locationAddress(p).CountryCode }},
+	"phone":        {"PHONE", func(p gonpi.Provider) string { return locationAddress(p).TelephoneNumber }},
+	"last_updated": {"LAST UPDATED", func(p gonpi.Provider) string { return p.Basic.LastUpdated }},
+}
+
+const defaultColumns = "number,type,name,taxonomy,city,state"
+
+// outputFlags holds the formatting flags shared by commands that print providers.
+type outputFlags struct {
+	format     string
+	columns    string
+	template   string
+	redact     string
+	redactMode string
+}
+
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "table", "output format: json, csv or table")
+	fs.StringVar(&o.columns, "columns", defaultColumns, "comma-separated columns for table and csv output ("+columnNames()+")")
+	fs.StringVar(&o.template, "template", "", "Go template applied to each provider, e.g. '{{.Number}} {{.Basic.LastName}}'; overrides -format")
+	fs.StringVar(&o.redact, "redact", "", "comma-separated field groups to remove from output: phones, official, individual_name, street_address, identifiers, licenses")
+	fs.StringVar(&o.redactMode, "redact-mode", "drop", "drop or hash redacted fields; hashes are keyed by $GONPI_REDACT_KEY when set")
+}
+
+// formatter returns the providerWriter selected by the flags.
+func (o *outputFlags) formatter(w io.Writer) (providerWriter, error) {
+	pw, err := o.writer(w)
+	if err != nil || o.redact == "" {
+		return pw, err
+	}
+	fields, err := gonpi.ParseExportFields(o.redact)
+	if err != nil {
+		return nil, err
+	}
+	redaction := gonpi.Redaction{Fields: fields, Key: []byte(os.Getenv("GONPI_REDACT_KEY"))}
+	switch o.redactMode {
+	case "drop":
+	case "hash":
+		redaction.Mode = gonpi.RedactHash
+	default:
+		return nil, fmt.Errorf("unknown redact mode %q (want drop or hash)", o.redactMode)
+	}
+	return &redactingWriter{providerWriter: pw, redaction: redaction}, nil
+}
+
+// exportFormat names the output format for export manifests.
+func (o *outputFlags) exportFormat() string {
+	switch {
+	case o.template != "":
+		return "template"
+	case o.format == "json":
+		return "ndjson"
+	}
+	return o.format
+}
+
+// writer returns the providerWriter for the format flags.
+func (o *outputFlags) writer(w io.Writer) (providerWriter, error) {
+	if o.template != "" {
+		tmpl, err := template.New("provider").Parse(o.template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		return &templateWriter{w: w, tmpl: tmpl}, nil
+	}
+
+	switch o.format {
+	case "json":
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case "csv", "table":
+		cols, err := parseColumns(o.columns)
+		if err != nil {
+			return nil, err
+		}
+		if o.format == "csv" {
+			return &csvWriter{w: csv.NewWriter(w), cols: cols}, nil
+		}
+		return &tableWriter{w: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0), cols: cols}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q (want json, csv or table)", o.format)
+}
+
+func parseColumns(spec string) ([]column, error) {
+	var cols []column
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		col, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, columnNames())
+		}
+		cols = append(cols, col)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return cols, nil
+}
+
+func columnNames() string {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// providerWriter renders providers one at a time; Flush must be called at the end.
+type providerWriter interface {
+	Write(p gonpi.Provider) error
+	Flush() error
+}
+
+// redactingWriter applies a redaction to each provider before writing it.
+type redactingWriter struct {
+	providerWriter
+	redaction gonpi.Redaction
+}
+
+func (w *redactingWriter) Write(p gonpi.Provider) error {
+	return w.providerWriter.Write(w.redaction.Apply(p))
+}
+
+// jsonWriter writes one JSON object per line.
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonWriter) Write(p gonpi.Provider) error { return w.enc.Encode(p) }
+func (w *jsonWriter) Flush() error                 { return nil }
+
+type csvWriter struct {
+	w           *csv.Writer
+	cols        []column
+	wroteHeader bool
+}
+
+func (w *csvWriter) Write(p gonpi.Provider) error {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if err := w.w.Write(headers(w.cols)); err != nil {
+			return err
+		}
+	}
+	return w.w.Write(values(w.cols, p))
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type tableWriter struct {
+	w           *tabwriter.Writer
+	cols        []column
+	wroteHeader bool
+}
+
+func (w *tableWriter) Write(p gonpi.Provider) error {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if _, err := fmt.Fprintln(w.w, strings.Join(headers(w.cols), "\t")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w.w, strings.Join(values(w.cols, p), "\t"))
+	return err
+}
+
+func (w *tableWriter) Flush() error { return w.w.Flush() }
+
+type templateWriter struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+func (w *templateWriter) Write(p gonpi.Provider) error {
+	if err := w.tmpl.Execute(w.w, p); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+func (w *templateWriter) Flush() error { return nil }
+
+func headers(cols []column) []string {
+	out := make([]string, len(cols))
+	for i, col := range cols {
+		out[i] = col.header
+	}
+	return out
+}
+
+func values(cols []column, p gonpi.Provider) []string {
+	out := make([]string, len(cols))
+	for i, col := range cols {
+		out[i] = col.value(p)
+	}
+	return out
+}
+
+// primaryTaxonomy returns the provider's primary taxonomy, or the first one listed.
+func primaryTaxonomy(p gonpi.Provider) gonpi.Taxonomy {
+	for _, t := range p.Taxonomies {
+		if t.Primary {
+			return t
+		}
+	}
+	if len(p.Taxonomies) > 0 {
+		return p.Taxonomies[0]
+	}
+	return gonpi.Taxonomy{}
+}
+
+// locationAddress returns the provider's practice address, or the first one listed.
+func locationAddress(p gonpi.Provider) gonpi.Address {
+	for _, a := range p.Addresses {
+		if a.AddressPurpose == "LOCATION" {
+			return a
+		}
+	}
+	if len(p.Addresses) > 0 {
+		return p.Addresses[0]
+	}
+	return gonpi.Address{}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+func testProvider() gonpi.Provider {
+	return gonpi.Provider{
+		Number:          "1234567893",
+		EnumerationType: "NPI-1",
+		Basic:           gonpi.BasicInfo{FirstName: "Jane", LastName: "Doe", Credential: "MD"},
+		Addresses: []gonpi.Address{
+			{AddressPurpose: "MAILING", City: "BOX TOWN", State: "NV"},
+			{AddressPurpose: "LOCATION", City: "SPRINGFIELD", State: "IL"},
+		},
+		Taxonomies: []gonpi.Taxonomy{
+			{Code: "207Q00000X", Desc: "Family Medicine", Primary: true},
+		},
+	}
+}
+
+// TestOutputFormats tests the table, CSV, JSON and template renderers.
+func TestOutputFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   outputFlags
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "table",
+			flags: outputFlags{format: "table", columns: "number,last_name,city"},
+			want:  []string{"NPI", "LAST NAME", "CITY", "1234567893", "Doe", "SPRINGFIELD"},
+		},
+		{
+			name:    "csv",
+			flags:   outputFlags{format: "csv", columns: "number,taxonomy_code,state"},
+			want:    []string{"NPI,TAXONOMY CODE,STATE\n1234567893,207Q00000X,IL\n"},
+			notWant: []string{"Doe"},
+		},
+		{
+			name:  "json",
+			flags: outputFlags{format: "json", columns: defaultColumns},
+			want:  []string{`"number":"1234567893"`},
+		},
+		{
+			name:  "template",
+			flags: outputFlags{format: "table", template: "{{.Number}} {{.Basic.LastName}}"},
+			want:  []string{"1234567893 Doe\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			pw, err := tt.flags.formatter(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := pw.Write(testProvider()); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if err := pw.Flush(); err != nil {
+				t.Fatalf("flush: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("output should not contain %q:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+// TestOutputFlags_Invalid tests that bad formats, columns and templates are rejected.
+func TestOutputFlags_Invalid(t *testing.T) {
+	for _, flags := range []outputFlags{
+		{format: "xml", columns: defaultColumns},
+		{format: "table", columns: "number,nope"},
+		{format: "csv", columns: ","},
+		{format: "table", template: "{{.Number"},
+		{format: "json", redact: "ssn"},
+		{format: "json", redact: "phones", redactMode: "mask"},
+	} {
+		if _, err := flags.formatter(&bytes.Buffer{}); err == nil {
+			t.Errorf("expected error for %+v", flags)
+		}
+	}
+}
+
+// TestOutputFlags_Redact tests that redaction is applied before rendering.
+func TestOutputFlags_Redact(t *testing.T) {
+	p := testProvider()
+	p.Addresses[1].TelephoneNumber = "2175550100"
+
+	var out bytes.Buffer
+	flags := outputFlags{format: "csv", columns: "number,last_name,phone", redact: "phones,individual_name", redactMode: "drop"}
+	pw, err := flags.formatter(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pw.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	pw.Flush()
+	if got := out.String(); got != "NPI,LAST NAME,PHONE\n1234567893,,\n" {
+		t.Errorf("output = %q", got)
+	}
+
+	out.Reset()
+	flags = outputFlags{format: "json", redact: "phones", redactMode: "hash"}
+	if pw, err = flags.formatter(&out); err != nil {
+		t.Fatal(err)
+	}
+	pw.Write(p)
+	if strings.Contains(out.String(), "2175550100") || !strings.Contains(out.String(), `"last_name":"Doe"`) {
+		t.Errorf("output = %s", out.String())
+	}
+}
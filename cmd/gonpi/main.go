@@ -0,0 +1,84 @@
+// Command gonpi is a command-line client for the NPI Registry.
+//
+// Usage:
+//
+//	gonpi <command> [flags]
+//
+// Commands:
+//
+//	diff     compare two NPPES provider files, or a snapshot and a file, as NDJSON events
+//	get      look up providers by NPI
+//	search   search the registry
+//	store    build, verify and compact local store snapshots
+//	validate check the NPIs in a file, optionally against the registry or a snapshot
+//	watch    poll a roster of NPIs and append change events as NDJSON
+//
+// The get and search commands print a table by default; use -format json|csv|table,
+// -columns to choose table and CSV columns, or -template for a Go template applied to
+// each provider.
+//
+// Run "gonpi <command> -h" for the flags of a command.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+// command runs one subcommand with its arguments.
+type command func(ctx context.Context, args []string, stdout, stderr io.Writer) error
+
+var commands = map[string]command{
+	"diff":     runDiff,
+	"get":      runGet,
+	"search":   runSearch,
+	"store":    runStore,
+	"validate": runValidate,
+	"watch":    runWatch,
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "gonpi:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return errors.New("missing command")
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd(ctx, args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: gonpi <command> [flags]")
+	fmt.Fprintln(w, "commands:")
+	for _, name := range names {
+		fmt.Fprintln(w, "  "+name)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// runGet implements "gonpi get NPI...".
+func runGet(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var output outputFlags
+	output.register(fs)
+	baseURL := fs.String("base-url", gonpi.DefaultBaseURL, "NPI Registry API base URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("get: at least one NPI is required")
+	}
+
+	pw, err := output.formatter(stdout)
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+
+	client := gonpi.NewClient(gonpi.WithBaseURL(*baseURL))
+	defer client.Close()
+
+	items, batchErr := client.GetProvidersByNPIsOrdered(ctx, fs.Args())
+	for _, item := range items {
+		if item.Provider == nil {
+			continue
+		}
+		if err := pw.Write(*item.Provider); err != nil {
+			return fmt.Errorf("get: %w", err)
+		}
+	}
+	if err := pw.Flush(); err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	if batchErr != nil {
+		return fmt.Errorf("get: %w", batchErr)
+	}
+	return nil
+}
+
+// runSearch implements "gonpi search".
+func runSearch(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var output outputFlags
+	output.register(fs)
+	var opts gonpi.SearchOptions
+	registerSearchFlags(fs, &opts)
+	baseURL := fs.String("base-url", gonpi.DefaultBaseURL, "NPI Registry API base URL")
+	preset := fs.String("preset", "", "named preset to run; other criteria flags override its fields")
+	presetsFile := fs.String("presets", os.Getenv("GONPI_PRESETS"), "JSON presets file (default $GONPI_PRESETS)")
+	out := fs.String("out", "-", "file to write results to, or - for stdout")
+	manifestPath := fs.String("manifest", "", "export manifest to write with record count, query and SHA-256 of -out")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *manifestPath != "" && *out == "-" {
+		return errors.New("search: -manifest requires -out")
+	}
+
+	export, err := openExport(stdout, *out, *manifestPath, output.exportFormat())
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	defer export.close()
+	pw, err := output.formatter(export.w)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	client := gonpi.NewClient(gonpi.WithBaseURL(*baseURL))
+	defer client.Close()
+
+	if *preset != "" {
+		if opts, err = resolvePreset(fs, client, *presetsFile, *preset, opts); err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+	}
+	if export.manifest != nil {
+		export.manifest.SetSearch(opts)
+		export.manifest.Source = *baseURL
+	}
+
+	retrievals := gonpi.NewRetrievals()
+	ctx = gonpi.ContextWithCallOptions(ctx, gonpi.WithRetrievals(retrievals))
+	for provider, err := range client.SearchAll(ctx, opts) {
+		if err != nil {
+			pw.Flush()
+			return fmt.Errorf("search: %w", err)
+		}
+		if err := pw.Write(provider); err != nil {
+			return fmt.Errorf("search: %w", err)
+		}
+		export.record(retrievals.Meta(provider.Number))
+	}
+	if err := pw.Flush(); err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	if err := export.finish(); err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	return nil
+}
+
+// export is the destination of a command's results: stdout or a file, optionally
+// described by a manifest.
+type export struct {
+	w            io.Writer
+	file         *os.File
+	manifest     *gonpi.Manifest
+	writer       *gonpi.ManifestWriter
+	manifestPath string
+}
+
+// openExport opens the export file path, or uses stdout if path is "-". If
+// manifestPath is set, the file is recorded in a manifest written there by finish.
+func openExport(stdout io.Writer, path, manifestPath, format string) (*export, error) {
+	e := &export{w: stdout, manifestPath: manifestPath}
+	if path == "-" {
+		return e, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	e.w, e.file = f, f
+	if manifestPath != "" {
+		// Name the file relative to the manifest so the pair can be moved together
+		name, err := filepath.Rel(filepath.Dir(manifestPath), path)
+		if err != nil {
+			name = path
+		}
+		e.manifest = gonpi.NewManifest(format)
+		e.writer = e.manifest.AddFile(filepath.ToSlash(name), f)
+		e.w = e.writer
+	}
+	return e, nil
+}
+
+// record counts a record retrieved as meta describes in the manifest, if any.
+func (e *export) record(meta gonpi.RetrievalMeta) {
+	if e.writer != nil {
+		e.writer.Record(meta)
+	}
+}
+
+// finish closes the export file and writes the manifest.
+func (e *export) finish() error {
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	if err != nil || e.manifest == nil {
+		return err
+	}
+	e.manifest.Complete()
+	f, err := os.Create(e.manifestPath)
+	if err != nil {
+		return err
+	}
+	if _, err := e.manifest.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// close closes the export file if finish was not reached.
+func (e *export) close() {
+	if e.file != nil {
+		e.file.Close()
+	}
+}
+
+// resolvePreset registers the presets in path with client and returns the named one
+// with the criteria given on the command line applied over it.
+func resolvePreset(fs *flag.FlagSet, client *gonpi.Client, path, name string, overrides gonpi.SearchOptions) (gonpi.SearchOptions, error) {
+	if path == "" {
+		return overrides, errors.New("-preset requires a presets file (-presets or $GONPI_PRESETS)")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return overrides, err
+	}
+	defer f.Close()
+	presets, err := gonpi.LoadPresets(f)
+	if err != nil {
+		return overrides, err
+	}
+	for presetName, opts := range presets {
+		if err := client.RegisterPreset(presetName, opts); err != nil {
+			return overrides, fmt.Errorf("preset %q: %w", presetName, err)
+		}
+	}
+
+	// Keep the preset's cap unless -max was given
+	maxSet := false
+	fs.Visit(func(f *flag.Flag) { maxSet = maxSet || f.Name == "max" })
+	if !maxSet {
+		overrides.MaxResults = 0
+	}
+	opts, err := client.ResolvePreset(name, overrides)
+	if err != nil {
+		return opts, err
+	}
+	if opts.MaxResults == 0 {
+		opts.MaxResults = defaultMaxResults
+	}
+	return opts, nil
+}
+
+// defaultMaxResults is the default of the -max flag.
+const defaultMaxResults = 200
+
+// registerSearchFlags binds the search criteria flags shared by commands that query
+// the registry.
+func registerSearchFlags(fs *flag.FlagSet, opts *gonpi.SearchOptions) {
+	fs.StringVar(&opts.FirstName, "first-name", "", "provider first name")
+	fs.StringVar(&opts.LastName, "last-name", "", "provider last name")
+	fs.StringVar(&opts.OrganizationName, "organization", "", "organization name")
+	fs.StringVar(&opts.EnumerationType, "type", "", "enumeration type: NPI-1 or NPI-2")
+	fs.StringVar(&opts.TaxonomyDescription, "taxonomy", "", "taxonomy description")
+	fs.StringVar(&opts.City, "city", "", "city")
+	fs.StringVar(&opts.State, "state", "", "two-letter state code")
+	fs.StringVar(&opts.PostalCode, "postal-code", "", "postal code")
+	fs.StringVar(&opts.CountryCode, "country", "", "two-letter ISO country code, e.g. CA for Canadian addresses")
+	fs.IntVar(&opts.MaxResults, "max", defaultMaxResults, "maximum number of results")
+}
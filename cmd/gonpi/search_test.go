@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestRunGet tests the get command end to end against a mock registry.
+func TestRunGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gonpi.APIResponse{ResultCount: 1, Results: []gonpi.Provider{testProvider()}})
+	}))
+	defer server.Close()
+
+	var stdout bytes.Buffer
+	args := []string{"get", "-base-url", server.URL, "-template", "{{.Number}}|{{.Basic.Credential}}", "1234567893"}
+	if err := run(context.Background(), args, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got := stdout.String(); got != "1234567893|MD\n" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+// TestRunSearch_Preset tests running a preset from a presets file with flag overrides.
+func TestRunSearch_Preset(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode(gonpi.APIResponse{ResultCount: 1, Results: []gonpi.Provider{testProvider()}})
+	}))
+	defer server.Close()
+
+	presets := filepath.Join(t.TempDir(), "presets.json")
+	if err := os.WriteFile(presets, []byte(`{"ca-cardiologists": {"State": "CA", "TaxonomyDescription": "Cardiology", "MaxResults": 5}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	args := []string{"search", "-base-url", server.URL, "-presets", presets, "-preset", "ca-cardiologists",
+		"-city", "Fresno", "-template", "{{.Number}}"}
+	if err := run(context.Background(), args, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if query.Get("state") != "CA" || query.Get("taxonomy_description") != "Cardiology" || query.Get("city") != "Fresno" || query.Get("limit") != "5" {
+		t.Errorf("query = %v", query)
+	}
+	if got := stdout.String(); got != "1234567893\n" {
+		t.Errorf("output = %q", got)
+	}
+
+	args = []string{"search", "-base-url", server.URL, "-presets", presets, "-preset", "missing"}
+	if err := run(context.Background(), args, &bytes.Buffer{}, &bytes.Buffer{}); !errors.Is(err, gonpi.ErrUnknownPreset) {
+		t.Errorf("expected ErrUnknownPreset, got %v", err)
+	}
+}
+
+// TestRunSearch_Manifest tests writing an export manifest alongside the results.
+func TestRunSearch_Manifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gonpi.APIResponse{ResultCount: 1, Results: []gonpi.Provider{testProvider()}})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "cardiologists.csv")
+	manifestPath := filepath.Join(dir, "manifest.json")
+	args := []string{"search", "-base-url", server.URL, "-state", "CA", "-format", "csv", "-out", out, "-manifest", manifestPath}
+	if err := run(context.Background(), args, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	manifest, err := gonpi.ReadManifest(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Export != "csv" || manifest.Records != 1 || manifest.Source != server.URL || manifest.Parameters["State"] != "CA" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	if len(manifest.Files) != 1 || manifest.Files[0].Name != "cardiologists.csv" {
+		t.Fatalf("files = %+v", manifest.Files)
+	}
+	if err := manifest.Verify(os.DirFS(dir)); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	args = []string{"search", "-base-url", server.URL, "-manifest", manifestPath}
+	if err := run(context.Background(), args, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("expected -manifest without -out to fail")
+	}
+}
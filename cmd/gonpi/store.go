@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// storeCommands are the subcommands of "gonpi store".
+var storeCommands = map[string]command{
+	"snapshot": runStoreSnapshot,
+	"verify":   runStoreVerify,
+	"compact":  runStoreCompact,
+}
+
+// runStore implements "gonpi store <subcommand>".
+func runStore(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("store: missing subcommand (snapshot, verify or compact)")
+	}
+	cmd, ok := storeCommands[args[0]]
+	if !ok {
+		return fmt.Errorf("store: unknown subcommand %q", args[0])
+	}
+	return cmd(ctx, args[1:], stdout, stderr)
+}
+
+// runStoreSnapshot implements "gonpi store snapshot", which loads the results of a
+// registry search into a store and writes its snapshot.
+func runStoreSnapshot(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("store snapshot", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var opts gonpi.SearchOptions
+	registerSearchFlags(fs, &opts)
+	from := fs.String("from", "", "existing snapshot to extend with the search results")
+	out := fs.String("out", "", "snapshot file to write (required)")
+	baseURL := fs.String("base-url", gonpi.DefaultBaseURL, "NPI Registry API base URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("store snapshot: -out is required")
+	}
+
+	store := gonpi.NewMemoryStore()
+	if *from != "" {
+		if err := restoreFile(store, *from); err != nil {
+			return fmt.Errorf("store snapshot: %w", err)
+		}
+	}
+
+	client := gonpi.NewClient(gonpi.WithBaseURL(*baseURL))
+	defer client.Close()
+
+	for provider, err := range client.SearchAll(ctx, opts) {
+		if err != nil {
+			return fmt.Errorf("store snapshot: %w", err)
+		}
+		if err := store.Put(ctx, provider); err != nil {
+			return fmt.Errorf("store snapshot: %w", err)
+		}
+	}
+
+	if err := writeSnapshotFile(store, *out); err != nil {
+		return fmt.Errorf("store snapshot: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "wrote %d providers to %s\n", store.Len(), *out)
+	return nil
+}
+
+// runStoreVerify implements "gonpi store verify FILE", which restores a snapshot
+// into memory to verify it and reports its contents.
+func runStoreVerify(_ context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("store verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("store verify: exactly one snapshot file is required")
+	}
+
+	store := gonpi.NewMemoryStore()
+	if err := restoreFile(store, fs.Arg(0)); err != nil {
+		return fmt.Errorf("store verify: %w", err)
+	}
+	fmt.Fprintf(stdout, "restored %d providers from %s\n", store.Len(), fs.Arg(0))
+	return nil
+}
+
+// runStoreCompact implements "gonpi store compact FILE", which restores a snapshot,
+// drops its deactivated providers and writes it back, reporting sizes before and
+// after. A snapshot restores at its live size, so dropping records is what shrinks it.
+func runStoreCompact(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("store compact", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("store compact: exactly one snapshot file is required")
+	}
+	path := fs.Arg(0)
+
+	before, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("store compact: %w", err)
+	}
+	store := gonpi.NewMemoryStore()
+	if err := restoreFile(store, path); err != nil {
+		return fmt.Errorf("store compact: %w", err)
+	}
+	result, err := store.Compact(ctx, gonpi.WithDropDeactivated(), gonpi.WithCompactProgress(func(done, total int) {
+		fmt.Fprintf(stderr, "compacted %d/%d providers\n", done, total)
+	}))
+	if err != nil {
+		return fmt.Errorf("store compact: %w", err)
+	}
+	if err := writeSnapshotFile(store, path); err != nil {
+		return fmt.Errorf("store compact: %w", err)
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("store compact: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "dropped %d deactivated providers; %d providers, %d index entries; %d bytes -> %d bytes in %s\n",
+		result.Dropped, result.After.Providers, result.After.IndexEntries, before.Size(), after.Size(), result.Duration.Round(time.Millisecond))
+	return nil
+}
+
+func restoreFile(store *gonpi.MemoryStore, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return store.Restore(f)
+}
+
+// writeSnapshotFile writes a snapshot of store to path via a temporary file, so an
+// interrupted write never replaces a good snapshot.
+func writeSnapshotFile(store *gonpi.MemoryStore, path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := store.Snapshot(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestStoreSnapshotAndVerify tests building, verifying and compacting a snapshot.
+func TestStoreSnapshotAndVerify(t *testing.T) {
+	deactivated := testProvider()
+	deactivated.Number = "1245319599"
+	deactivated.Basic.Status = "D"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var providers []gonpi.Provider
+		if r.URL.Query().Get("skip") == "" {
+			providers = []gonpi.Provider{testProvider(), deactivated}
+		}
+		json.NewEncoder(w).Encode(gonpi.APIResponse{ResultCount: len(providers), Results: providers})
+	}))
+	defer server.Close()
+
+	out := filepath.Join(t.TempDir(), "ma.snap")
+	var stdout bytes.Buffer
+	args := []string{"store", "snapshot", "-base-url", server.URL, "-state", "MA", "-last-name", "Doe", "-out", out}
+	if err := run(context.Background(), args, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "wrote 2 providers") {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := run(context.Background(), []string{"store", "verify", out}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "restored 2 providers") {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := run(context.Background(), []string{"store", "compact", out}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("compact failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "dropped 1 deactivated providers; 1 providers") {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+	stdout.Reset()
+	run(context.Background(), []string{"store", "verify", out}, &stdout, &bytes.Buffer{})
+	if !strings.Contains(stdout.String(), "restored 1 providers") {
+		t.Errorf("expected the compacted snapshot to keep only the active provider: %q", stdout.String())
+	}
+
+	if err := run(context.Background(), []string{"store", "verify", filepath.Join(t.TempDir(), "missing")}, &stdout, &bytes.Buffer{}); err == nil {
+		t.Error("expected error for missing snapshot")
+	}
+}
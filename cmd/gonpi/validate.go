@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// Row dispositions reported by "gonpi validate".
+const (
+	dispositionValid       = "valid"
+	dispositionFormat      = "invalid_format"
+	dispositionCheckDigit  = "invalid_check_digit"
+	dispositionActive      = "active"
+	dispositionDeactivated = "deactivated"
+	dispositionNotFound    = "not_found"
+	dispositionError       = "error"
+)
+
+// validationRow is the disposition of one input row.
+type validationRow struct {
+	Line        int    `json:"line"`
+	Input       string `json:"input"`
+	NPI         string `json:"npi,omitempty"`
+	Disposition string `json:"disposition"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// ok reports whether the row passed validation.
+func (r validationRow) ok() bool {
+	return r.Disposition == dispositionValid || r.Disposition == dispositionActive
+}
+
+// runValidate implements "gonpi validate FILE", which checks the format and check
+// digit of every NPI in a file and optionally verifies that each exists and is active.
+func runValidate(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	column := fs.String("column", "npi", "header of the NPI column; files without it are read from the first column")
+	verify := fs.String("verify", "none", "check existence and status against: none, api or store")
+	storeFile := fs.String("store", "", "snapshot file for -verify store")
+	rate := fs.Float64("rate", 5, "maximum API requests per second for -verify api")
+	format := fs.String("format", "csv", "report format: csv or json (one object per line)")
+	baseURL := fs.String("base-url", gonpi.DefaultBaseURL, "NPI Registry API base URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("validate: exactly one input file is required")
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("validate: unknown format %q", *format)
+	}
+
+	rows, err := readValidationRows(fs.Arg(0), *column)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	for i := range rows {
+		checkNPIFormat(&rows[i])
+	}
+
+	switch *verify {
+	case "none":
+	case "api":
+		client := gonpi.NewClient(gonpi.WithBaseURL(*baseURL), gonpi.WithRateLimit(*rate, 1))
+		defer client.Close()
+		err = verifyWithAPI(ctx, client, rows)
+	case "store":
+		if *storeFile == "" {
+			return errors.New("validate: -verify store requires -store")
+		}
+		store := gonpi.NewMemoryStore()
+		if err := restoreFile(store, *storeFile); err != nil {
+			return fmt.Errorf("validate: %w", err)
+		}
+		err = verifyWithStore(ctx, store, rows)
+	default:
+		return fmt.Errorf("validate: unknown -verify source %q", *verify)
+	}
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	if err := writeValidationReport(stdout, *format, rows); err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	failed := 0
+	for _, row := range rows {
+		if !row.ok() {
+			failed++
+		}
+	}
+	fmt.Fprintf(stderr, "validated %d rows: %d passed, %d failed\n", len(rows), len(rows)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("validate: %d of %d rows failed", failed, len(rows))
+	}
+	return nil
+}
+
+// readValidationRows reads the NPI column of a CSV file, or its first column if the
+// header does not name column. Blank rows and lines starting with # are skipped.
+func readValidationRows(path, column string) ([]validationRow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+
+	var rows []validationRow
+	index, first := 0, true
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first {
+			first = false
+			found := false
+			for i, name := range record {
+				if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")), column) {
+					index, found = i, true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+		}
+		line, _ := r.FieldPos(0)
+		value := ""
+		if index < len(record) {
+			value = strings.TrimSpace(record[index])
+		}
+		if value == "" && len(record) <= 1 {
+			continue
+		}
+		rows = append(rows, validationRow{Line: line, Input: value})
+	}
+}
+
+// checkNPIFormat sets the row's NPI and a format disposition.
+func checkNPIFormat(row *validationRow) {
+	npi, err := gonpi.NormalizeNPI(row.Input)
+	switch {
+	case err != nil:
+		row.Disposition, row.Detail = dispositionFormat, err.Error()
+	case !gonpi.ValidCheckDigit(npi):
+		row.NPI, row.Disposition = npi, dispositionCheckDigit
+	default:
+		row.NPI, row.Disposition = npi, dispositionValid
+	}
+}
+
+// verifyWithAPI looks up the rows that passed the format checks.
+func verifyWithAPI(ctx context.Context, client *gonpi.Client, rows []validationRow) error {
+	var npis []string
+	for _, row := range rows {
+		if row.ok() {
+			npis = append(npis, row.NPI)
+		}
+	}
+	if len(npis) == 0 {
+		return nil
+	}
+	// Lookup failures are reported per row
+	items, _ := client.GetProvidersByNPIsOrdered(ctx, npis)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	i := 0
+	for r := range rows {
+		if !rows[r].ok() {
+			continue
+		}
+		item := items[i]
+		i++
+		if item.Err != nil {
+			rows[r].Disposition, rows[r].Detail = dispositionError, item.Err.Error()
+			continue
+		}
+		setStatus(&rows[r], item.Provider)
+	}
+	return nil
+}
+
+// verifyWithStore looks up the rows that passed the format checks in store.
+func verifyWithStore(ctx context.Context, store *gonpi.MemoryStore, rows []validationRow) error {
+	for i := range rows {
+		if !rows[i].ok() {
+			continue
+		}
+		provider, err := store.Get(ctx, rows[i].NPI)
+		if err != nil {
+			return err
+		}
+		setStatus(&rows[i], provider)
+	}
+	return nil
+}
+
+// setStatus sets the disposition of a verified row from its provider, which is nil if
+// it was not found.
+func setStatus(row *validationRow, provider *gonpi.Provider) {
+	switch {
+	case provider == nil:
+		row.Disposition = dispositionNotFound
+	case provider.Basic.Status == "D":
+		row.Disposition = dispositionDeactivated
+	default:
+		row.Disposition = dispositionActive
+	}
+}
+
+// writeValidationReport writes the rows as CSV or JSON lines.
+func writeValidationReport(w io.Writer, format string, rows []validationRow) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"line", "input", "npi", "disposition", "detail"})
+	for _, row := range rows {
+		cw.Write([]string{strconv.Itoa(row.Line), row.Input, row.NPI, row.Disposition, row.Detail})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// validateInput is a roster with a valid, a deactivated, a missing, a mistyped and a
+// malformed NPI.
+const validateInput = "name,NPI\nDoe,1234567893\nClinic,1245319599\nGone,1003000126\nTypo,1234567890\nBad,12-34\n"
+
+// TestRunValidate tests local format and check digit validation.
+func TestRunValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "npis.csv")
+	os.WriteFile(path, []byte(validateInput), 0o644)
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), []string{"validate", path}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "2 of 5 rows failed") {
+		t.Errorf("expected failed rows error, got %v", err)
+	}
+	for _, want := range []string{
+		"line,input,npi,disposition,detail\n",
+		"2,1234567893,1234567893,valid,\n",
+		"5,1234567890,1234567890,invalid_check_digit,\n",
+		"6,12-34,,invalid_format,",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if !strings.Contains(stderr.String(), "3 passed, 2 failed") {
+		t.Errorf("unexpected summary: %q", stderr.String())
+	}
+}
+
+// TestRunValidate_Verify tests verifying NPIs against the API and a snapshot.
+func TestRunValidate_Verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var providers []gonpi.Provider
+		switch number := r.URL.Query().Get("number"); number {
+		case "1234567893":
+			providers = []gonpi.Provider{{Number: number, Basic: gonpi.BasicInfo{Status: "A"}}}
+		case "1245319599":
+			providers = []gonpi.Provider{{Number: number, Basic: gonpi.BasicInfo{Status: "D"}}}
+		}
+		json.NewEncoder(w).Encode(gonpi.APIResponse{ResultCount: len(providers), Results: providers})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "npis.csv")
+	os.WriteFile(path, []byte(validateInput), 0o644)
+
+	dispositions := func(report string) map[string]string {
+		got := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(report), "\n") {
+			var row validationRow
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				t.Fatalf("invalid report line %q: %v", line, err)
+			}
+			got[row.Input] = row.Disposition
+		}
+		return got
+	}
+	want := map[string]string{
+		"1234567893": dispositionActive,
+		"1245319599": dispositionDeactivated,
+		"1003000126": dispositionNotFound,
+		"1234567890": dispositionCheckDigit,
+		"12-34":      dispositionFormat,
+	}
+
+	var stdout bytes.Buffer
+	args := []string{"validate", "-verify", "api", "-rate", "0", "-format", "json", "-base-url", server.URL, path}
+	run(context.Background(), args, &stdout, &bytes.Buffer{})
+	for input, disposition := range dispositions(stdout.String()) {
+		if want[input] != disposition {
+			t.Errorf("api: %s = %s, want %s", input, disposition, want[input])
+		}
+	}
+
+	store := gonpi.NewMemoryStore()
+	store.Put(context.Background(), gonpi.Provider{Number: "1234567893", Basic: gonpi.BasicInfo{Status: "A"}})
+	store.Put(context.Background(), gonpi.Provider{Number: "1245319599", Basic: gonpi.BasicInfo{Status: "D"}})
+	snapshot := filepath.Join(dir, "store.snap")
+	if err := writeSnapshotFile(store, snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout.Reset()
+	args = []string{"validate", "-verify", "store", "-store", snapshot, "-format", "json", path}
+	run(context.Background(), args, &stdout, &bytes.Buffer{})
+	for input, disposition := range dispositions(stdout.String()) {
+		if want[input] != disposition {
+			t.Errorf("store: %s = %s, want %s", input, disposition, want[input])
+		}
+	}
+}
+
+// TestRunValidate_PlainList tests files without a header, one NPI per line.
+func TestRunValidate_PlainList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "npis.txt")
+	os.WriteFile(path, []byte("# roster\n1234567893\n\nNPI: 1245-319-599\n"), 0o644)
+
+	var stdout bytes.Buffer
+	if err := run(context.Background(), []string{"validate", path}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "4,NPI: 1245-319-599,1245319599,valid,") {
+		t.Errorf("unexpected report:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// runWatch implements "gonpi watch".
+func runWatch(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	npisFile := fs.String("npis-file", "", "file with one NPI per line (required; # starts a comment)")
+	interval := fs.Duration("interval", 24*time.Hour, "time between polls")
+	jitter := fs.Duration("jitter", 0, "maximum random delay added to each interval")
+	cronExpr := fs.String("cron", "", "cron expression to poll on instead of -interval")
+	out := fs.String("out", "-", "file to append NDJSON events to, or - for stdout")
+	once := fs.Bool("once", false, "poll once and exit")
+	stateDir := fs.String("state", "", "directory to keep the watcher's state in, so a restart reports only new changes")
+	baseURL := fs.String("base-url", gonpi.DefaultBaseURL, "NPI Registry API base URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *npisFile == "" {
+		return errors.New("watch: -npis-file is required")
+	}
+
+	var schedule gonpi.Schedule
+	switch {
+	case *cronExpr != "":
+		var err error
+		if schedule, err = gonpi.ParseCron(*cronExpr); err != nil {
+			return fmt.Errorf("watch: %w", err)
+		}
+	case *interval > 0:
+		schedule = gonpi.Every(*interval, *jitter)
+	case !*once:
+		return errors.New("watch: -interval must be positive")
+	}
+
+	npis, err := readNPIsFile(*npisFile)
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	if len(npis) == 0 {
+		return fmt.Errorf("watch: no NPIs in %s", *npisFile)
+	}
+
+	w := stdout
+	if *out != "-" {
+		f, err := os.OpenFile(*out, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("watch: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	client := gonpi.NewClient(gonpi.WithBaseURL(*baseURL))
+	defer client.Close()
+
+	watcher := client.NewWatcher(npis, &ndjsonPublisher{enc: json.NewEncoder(w)})
+	if *stateDir != "" {
+		state, err := gonpi.NewFileStateStore(*stateDir)
+		if err != nil {
+			return fmt.Errorf("watch: %w", err)
+		}
+		// Key the state by roster so several watchers can share a directory
+		if err := watcher.Persist(ctx, state, "watch/"+filepath.Base(*npisFile)); err != nil {
+			return fmt.Errorf("watch: %w", err)
+		}
+	}
+	logError := func(err error) { fmt.Fprintln(stderr, "gonpi watch:", err) }
+
+	// Without saved state, the first poll records the baseline and reports every
+	// provider as created
+	if _, err := watcher.Poll(ctx); err != nil {
+		if *once {
+			return fmt.Errorf("watch: %w", err)
+		}
+		logError(err)
+	}
+	if *once {
+		return nil
+	}
+
+	scheduler := client.NewScheduler(gonpi.WithSchedulerErrorHandler(func(_ string, err error) {
+		logError(err)
+	}))
+	scheduler.Add("watch", schedule, func(ctx context.Context) error {
+		_, err := watcher.Poll(ctx)
+		return err
+	})
+	scheduler.Start(ctx)
+
+	<-ctx.Done()
+	return nil
+}
+
+// readNPIsFile reads one NPI per line, skipping blank lines and # comments.
+func readNPIsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var npis []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			npis = append(npis, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return npis, nil
+}
+
+// ndjsonPublisher writes each event as one JSON line.
+type ndjsonPublisher struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (p *ndjsonPublisher) Publish(_ context.Context, event gonpi.ChangeEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enc.Encode(event)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestRunWatch_Once tests that a single poll appends created events to the output file.
+func TestRunWatch_Once(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		number := r.URL.Query().Get("number")
+		json.NewEncoder(w).Encode(gonpi.APIResponse{
+			ResultCount: 1,
+			Results:     []gonpi.Provider{{Number: number, EnumerationType: "NPI-1"}},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	roster := filepath.Join(dir, "roster.txt")
+	os.WriteFile(roster, []byte("# roster\n1234567893\n\n1245319599 # clinic\n"), 0o644)
+	out := filepath.Join(dir, "events.ndjson")
+	os.WriteFile(out, []byte(`{"existing":true}`+"\n"), 0o644)
+
+	args := []string{"--npis-file", roster, "--out", out, "--once", "--base-url", server.URL}
+	var stderr bytes.Buffer
+	if err := run(context.Background(), append([]string{"watch"}, args...), &bytes.Buffer{}, &stderr); err != nil {
+		t.Fatalf("watch failed: %v (%s)", err, stderr.String())
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected existing line plus 2 events, got %d: %v", len(lines), lines)
+	}
+	var event gonpi.ChangeEvent
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatalf("invalid event line: %v", err)
+	}
+	if event.Type != gonpi.EventProviderCreated {
+		t.Errorf("event type = %s", event.Type)
+	}
+}
+
+// TestRunWatch_Flags tests flag validation.
+func TestRunWatch_Flags(t *testing.T) {
+	if err := run(context.Background(), []string{"watch"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("expected error without -npis-file")
+	}
+	if err := run(context.Background(), []string{"watch", "-npis-file", "x", "-cron", "bad"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("expected error for invalid cron")
+	}
+	if err := run(context.Background(), []string{"watch", "-npis-file", "x", "-interval", "0s"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "interval") {
+		t.Errorf("expected error for a zero interval, got %v", err)
+	}
+	if err := run(context.Background(), []string{"nope"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
+
+// TestRunWatch_State tests that a watcher restarted with -state reports only changes.
+func TestRunWatch_State(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gonpi.APIResponse{
+			ResultCount: 1,
+			Results:     []gonpi.Provider{{Number: r.URL.Query().Get("number"), EnumerationType: "NPI-1"}},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	roster := filepath.Join(dir, "roster.txt")
+	os.WriteFile(roster, []byte("1234567893\n"), 0o644)
+	args := []string{"watch", "--npis-file", roster, "--once", "--state", filepath.Join(dir, "state"), "--base-url", server.URL}
+
+	for i, want := range []int{1, 0} {
+		var stdout, stderr bytes.Buffer
+		if err := run(context.Background(), args, &stdout, &stderr); err != nil {
+			t.Fatalf("run %d: %v (%s)", i, err, stderr.String())
+		}
+		if got := bytes.Count(stdout.Bytes(), []byte("\n")); got != want {
+			t.Errorf("run %d: %d events, want %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Codec encodes providers, change events and other gonpi types for storage and
+// transport. JSONCodec, MsgPackCodec and ProtobufCodec are provided; the binary codecs
+// are several times smaller and faster than JSON for full provider records, which
+// matters for remote cache backends and event sinks.
+type Codec interface {
+	// Name identifies the codec, e.g. "json".
+	Name() string
+
+	// ContentType is the MIME type of encoded values.
+	ContentType() string
+
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec encodes values with encoding/json.
+type JSONCodec struct{}
+
+// Name implements Codec.
+func (JSONCodec) Name() string { return "json" }
+
+// ContentType implements Codec.
+func (JSONCodec) ContentType() string { return "application/json" }
+
+// Marshal implements Codec.
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements Codec.
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// CodecByName returns the codec named name: "json", "msgpack" or "protobuf".
+func CodecByName(name string) (Codec, error) {
+	switch name {
+	case "json":
+		return JSONCodec{}, nil
+	case "msgpack":
+		return MsgPackCodec{}, nil
+	case "protobuf":
+		return ProtobufCodec{}, nil
+	}
+	return nil, &ValidationError{Field: "codec", Message: fmt.Sprintf("unknown codec %q", name)}
+}
+
+// BlobStore is a key-value store of encoded values with expiry, such as Redis or
+// memcached. Get returns false for missing keys.
+type BlobStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NewCodecCacheBackend returns a CacheBackend storing providers in store, encoded with
+// codec. If store implements io.Closer, closing the backend closes it.
+//
+// Example usage:
+//
+//	backend := gonpi.NewCodecCacheBackend(redisStore, gonpi.MsgPackCodec{})
+//	client := gonpi.NewClient(gonpi.WithCacheBackend(backend))
+func NewCodecCacheBackend(store BlobStore, codec Codec) CacheBackend {
+	return &codecCacheBackend{store: store, codec: codec}
+}
+
+type codecCacheBackend struct {
+	store BlobStore
+	codec Codec
+}
+
+// Get implements CacheBackend.
+func (b *codecCacheBackend) Get(ctx context.Context, key string) (*Provider, bool, error) {
+	data, ok, err := b.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	var provider Provider
+	if err := b.codec.Unmarshal(data, &provider); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return &provider, true, nil
+}
+
+// Set implements CacheBackend.
+func (b *codecCacheBackend) Set(ctx context.Context, key string, provider *Provider, ttl time.Duration) error {
+	data, err := b.codec.Marshal(provider)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	return b.store.Set(ctx, key, data, ttl)
+}
+
+// Close closes the store if it is an io.Closer.
+func (b *codecCacheBackend) Close() error {
+	if closer, ok := b.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// encodeEvent encodes event with codec, or as JSON if codec is nil.
+func encodeEvent(codec Codec, event ChangeEvent) ([]byte, error) {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	data, err := codec.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+	return data, nil
+}
+
+// codecField is an exported struct field as seen by the binary codecs.
+type codecField struct {
+	index int
+	name  string // JSON name
+}
+
+var codecFieldCache sync.Map // reflect.Type -> []codecField
+
+// codecFields returns the exported fields of struct type t with their JSON names,
+// skipping fields tagged "-".
+func codecFields(t reflect.Type) []codecField {
+	if fields, ok := codecFieldCache.Load(t); ok {
+		return fields.([]codecField)
+	}
+	var fields []codecField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, codecField{index: i, name: name})
+	}
+	codecFieldCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,250 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// codecProvider returns a provider with every list and extension populated, so that
+// round trips through the binary codecs can be compared with reflect.DeepEqual.
+func codecProvider() Provider {
+	return Provider{
+		Number:          "1234567893",
+		EnumerationType: "NPI-1",
+		Basic: BasicInfo{
+			FirstName: "JANE", LastName: "DOE", Credential: "M.D.", Gender: "F",
+			EnumerationDate: "2010-01-01", Status: "A",
+		},
+		Addresses: []Address{
+			{CountryCode: "US", AddressPurpose: "LOCATION", Address1: "1 MAIN ST", City: "SPRINGFIELD", State: "IL", PostalCode: "627010000"},
+			{CountryCode: "US", AddressPurpose: "MAILING", Address1: "PO BOX 1", City: "SPRINGFIELD", State: "IL"},
+		},
+		Taxonomies:        []Taxonomy{{Code: "207Q00000X", Desc: "Family Medicine", State: "IL", License: "036-123", Primary: true}, {Code: "208D00000X"}},
+		Identifiers:       []Identifier{{Code: "05", Desc: "MEDICAID", Identifier: "X1", State: "IL"}},
+		Endpoints:         []Endpoint{{EndpointType: "DIRECT", Endpoint: "jane@direct.example.com"}},
+		PracticeLocations: []PracticeLocation{{Address1: "2 SIDE ST", City: "CHAMPAIGN", State: "IL"}},
+		OtherNames:        []OtherName{{Type: "Former Name", LastName: "ROE"}},
+		CreatedEpoch:      1262304000000,
+		LastUpdated:       "2024-05-01",
+		LastUpdatedEpoch:  -1,
+		Extensions: &Extensions{
+			Geo:          []GeoLocation{{City: "SPRINGFIELD", Coordinates: Coordinates{Latitude: 39.7817, Longitude: -89.6501}, Source: GeoSourceZIPCentroid}},
+			Counties:     []CountyLocation{{PostalCode: "62701", County: County{FIPS: "17167", Name: "Sangamon"}}},
+			Affiliations: []HospitalAffiliation{{CCN: "140001", FacilityType: "Hospital"}},
+		},
+	}
+}
+
+// codecEvent returns a change event carrying two providers and field changes.
+func codecEvent() ChangeEvent {
+	provider, previous := codecProvider(), codecProvider()
+	previous.Basic.LastName = "ROE"
+	return ChangeEvent{
+		Version:  EventSchemaVersion,
+		Type:     EventProviderUpdated,
+		NPI:      provider.Number,
+		Time:     time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC),
+		Provider: &provider,
+		Previous: &previous,
+		Changes: []FieldChange{
+			{Field: "basic.last_name", Old: "ROE", New: "DOE"},
+			{Field: "taxonomies", Old: []any{map[string]any{"code": "207Q00000X"}}, New: nil},
+		},
+	}
+}
+
+// TestCodecs_RoundTrip tests that every codec decodes what it encodes.
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec{}, MsgPackCodec{}, ProtobufCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			provider := codecProvider()
+			data, err := codec.Marshal(&provider)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var decoded Provider
+			if err := codec.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, provider) {
+				t.Errorf("provider round trip mismatch:\n got %+v\nwant %+v", decoded, provider)
+			}
+
+			event := codecEvent()
+			if data, err = codec.Marshal(event); err != nil {
+				t.Fatalf("Marshal event: %v", err)
+			}
+			var decodedEvent ChangeEvent
+			if err := codec.Unmarshal(data, &decodedEvent); err != nil {
+				t.Fatalf("Unmarshal event: %v", err)
+			}
+			if !decodedEvent.Time.Equal(event.Time) {
+				t.Errorf("time = %v, want %v", decodedEvent.Time, event.Time)
+			}
+			decodedEvent.Time = event.Time
+			if !reflect.DeepEqual(decodedEvent, event) {
+				t.Errorf("event round trip mismatch:\n got %+v\nwant %+v", decodedEvent, event)
+			}
+		})
+	}
+}
+
+// TestCodecs_Smaller tests that the binary codecs produce smaller records than JSON.
+func TestCodecs_Smaller(t *testing.T) {
+	provider := codecProvider()
+	jsonData, _ := JSONCodec{}.Marshal(provider)
+	for _, codec := range []Codec{MsgPackCodec{}, ProtobufCodec{}} {
+		data, err := codec.Marshal(provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) >= len(jsonData) {
+			t.Errorf("%s: %d bytes, not smaller than %d bytes of JSON", codec.Name(), len(data), len(jsonData))
+		}
+	}
+}
+
+// TestCodecByName tests codec lookup by name.
+func TestCodecByName(t *testing.T) {
+	for _, name := range []string{"json", "msgpack", "protobuf"} {
+		codec, err := CodecByName(name)
+		if err != nil || codec.Name() != name {
+			t.Errorf("CodecByName(%q) = %v, %v", name, codec, err)
+		}
+	}
+	var validationErr *ValidationError
+	if _, err := CodecByName("xml"); !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+}
+
+type mapBlobStore map[string][]byte
+
+func (m mapBlobStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	data, ok := m[key]
+	return data, ok, nil
+}
+
+func (m mapBlobStore) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+// TestCodecCacheBackend tests storing providers in a BlobStore.
+func TestCodecCacheBackend(t *testing.T) {
+	store := mapBlobStore{}
+	backend := NewCodecCacheBackend(store, MsgPackCodec{})
+	ctx := context.Background()
+
+	provider := codecProvider()
+	if err := backend.Set(ctx, "npi:1234567893", &provider, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if json.Valid(store["npi:1234567893"]) {
+		t.Error("expected a MessagePack record")
+	}
+	got, ok, err := backend.Get(ctx, "npi:1234567893")
+	if err != nil || !ok || !reflect.DeepEqual(*got, provider) {
+		t.Errorf("Get = %+v, %v, %v", got, ok, err)
+	}
+	if _, ok, err := backend.Get(ctx, "npi:missing"); ok || err != nil {
+		t.Errorf("expected miss, got %v, %v", ok, err)
+	}
+
+	store["npi:bad"] = []byte{0xc1}
+	if _, _, err := backend.Get(ctx, "npi:bad"); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+// TestPublishers_Codec tests that publishers encode events with their codec.
+func TestPublishers_Codec(t *testing.T) {
+	event := codecEvent()
+
+	nats := &fakeNATS{}
+	if err := (&NATSPublisher{Conn: nats, Subject: "npi.changes", Codec: ProtobufCodec{}}).Publish(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ChangeEvent
+	if err := (ProtobufCodec{}).Unmarshal(nats.data, &decoded); err != nil || decoded.NPI != event.NPI {
+		t.Errorf("unexpected nats payload: %+v, %v", decoded, err)
+	}
+
+	var gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+	if err := (&WebhookPublisher{URL: server.URL, Codec: MsgPackCodec{}}).Publish(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if gotType != "application/x-msgpack" {
+		t.Errorf("Content-Type = %q", gotType)
+	}
+}
+
+// TestDiskCache_Codec tests that entries are written with the configured codec and
+// that entries written with another codec stay readable.
+func TestDiskCache_Codec(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	provider := codecProvider()
+
+	plain, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Set(ctx, "json", &provider, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	binary, err := NewDiskCache(dir, WithDiskCacheCodec(ProtobufCodec{}), WithDiskCacheEncryption(make([]byte, 16)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := binary.Set(ctx, "proto", &provider, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := binary.Get(ctx, "proto")
+	if err != nil || !ok || !reflect.DeepEqual(*got, provider) {
+		t.Errorf("Get = %+v, %v, %v", got, ok, err)
+	}
+
+	msgpack, _ := NewDiskCache(dir, WithDiskCacheCodec(MsgPackCodec{}))
+	if got, ok, err := msgpack.Get(ctx, "json"); err != nil || !ok || got.Number != provider.Number {
+		t.Errorf("reading a JSON entry = %v, %v, %v", got, ok, err)
+	}
+	if err := msgpack.Set(ctx, "msgpack", &provider, -time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := plain.Get(ctx, "msgpack"); ok {
+		t.Error("expected the expiry time to survive the codec")
+	}
+}
+
+// BenchmarkCodecs compares encoding and decoding a full provider with each codec.
+func BenchmarkCodecs(b *testing.B) {
+	provider := codecProvider()
+	for _, codec := range []Codec{JSONCodec{}, MsgPackCodec{}, ProtobufCodec{}} {
+		data, _ := codec.Marshal(&provider)
+		b.Run(codec.Name()+"/marshal", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				codec.Marshal(&provider)
+			}
+		})
+		b.Run(codec.Name()+"/unmarshal", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				var p Provider
+				codec.Unmarshal(data, &p)
+			}
+		})
+	}
+}
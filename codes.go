@@ -0,0 +1,201 @@
+package gonpi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// State is a two-letter state, territory or military postal code accepted by the
+// NPI Registry State filter.
+type State string
+
+// US states and the District of Columbia.
+const (
+	StateAL State = "AL"
+	StateAK State = "AK"
+	StateAZ State = "AZ"
+	StateAR State = "AR"
+	StateCA State = "CA"
+	StateCO State = "CO"
+	StateCT State = "CT"
+	StateDE State = "DE"
+	StateDC State = "DC"
+	StateFL State = "FL"
+	StateGA State = "GA"
+	StateHI State = "HI"
+	StateID State = "ID"
+	StateIL State = "IL"
+	StateIN State = "IN"
+	StateIA State = "IA"
+	StateKS State = "KS"
+	StateKY State = "KY"
+	StateLA State = "LA"
+	StateME State = "ME"
+	StateMD State = "MD"
+	StateMA State = "MA"
+	StateMI State = "MI"
+	StateMN State = "MN"
+	StateMS State = "MS"
+	StateMO State = "MO"
+	StateMT State = "MT"
+	StateNE State = "NE"
+	StateNV State = "NV"
+	StateNH State = "NH"
+	StateNJ State = "NJ"
+	StateNM State = "NM"
+	StateNY State = "NY"
+	StateNC State = "NC"
+	StateND State = "ND"
+	StateOH State = "OH"
+	StateOK State = "OK"
+	StateOR State = "OR"
+	StatePA State = "PA"
+	StateRI State = "RI"
+	StateSC State = "SC"
+	StateSD State = "SD"
+	StateTN State = "TN"
+	StateTX State = "TX"
+	StateUT State = "UT"
+	StateVT State = "VT"
+	StateVA State = "VA"
+	StateWA State = "WA"
+	StateWV State = "WV"
+	StateWI State = "WI"
+	StateWY State = "WY"
+)
+
+// US territories and freely associated states.
+const (
+	StateAS State = "AS" // American Samoa
+	StateFM State = "FM" // Federated States of Micronesia
+	StateGU State = "GU" // Guam
+	StateMH State = "MH" // Marshall Islands
+	StateMP State = "MP" // Northern Mariana Islands
+	StatePW State = "PW" // Palau
+	StatePR State = "PR" // Puerto Rico
+	StateVI State = "VI" // U.S. Virgin Islands
+)
+
+// Military post office codes.
+const (
+	StateAA State = "AA" // Armed Forces Americas
+	StateAE State = "AE" // Armed Forces Europe, Middle East, Africa and Canada
+	StateAP State = "AP" // Armed Forces Pacific
+)
+
+// validStates is the set of State codes accepted by the registry.
+var validStates = map[State]bool{
+	StateAL: true, StateAK: true, StateAZ: true, StateAR: true, StateCA: true,
+	StateCO: true, StateCT: true, StateDE: true, StateDC: true, StateFL: true,
+	StateGA: true, StateHI: true, StateID: true, StateIL: true, StateIN: true,
+	StateIA: true, StateKS: true, StateKY: true, StateLA: true, StateME: true,
+	StateMD: true, StateMA: true, StateMI: true, StateMN: true, StateMS: true,
+	StateMO: true, StateMT: true, StateNE: true, StateNV: true, StateNH: true,
+	StateNJ: true, StateNM: true, StateNY: true, StateNC: true, StateND: true,
+	StateOH: true, StateOK: true, StateOR: true, StatePA: true, StateRI: true,
+	StateSC: true, StateSD: true, StateTN: true, StateTX: true, StateUT: true,
+	StateVT: true, StateVA: true, StateWA: true, StateWV: true, StateWI: true,
+	StateWY: true,
+	StateAS: true, StateFM: true, StateGU: true, StateMH: true, StateMP: true,
+	StatePW: true, StatePR: true, StateVI: true,
+	StateAA: true, StateAE: true, StateAP: true,
+}
+
+// Valid reports whether s is a state code accepted by the registry.
+func (s State) Valid() bool {
+	return validStates[s]
+}
+
+// States returns every valid State code in alphabetical order.
+func States() []State {
+	states := make([]State, 0, len(validStates))
+	for s := range validStates {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+	return states
+}
+
+// CountryCode is an ISO 3166-1 alpha-2 country code accepted by the NPI Registry
+// CountryCode filter.
+type CountryCode string
+
+// Commonly used country codes.
+const (
+	CountryUS CountryCode = "US"
+	CountryCA CountryCode = "CA"
+	CountryMX CountryCode = "MX"
+)
+
+// countryCodes lists the ISO 3166-1 alpha-2 codes.
+const countryCodes = "" +
+	"AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ " +
+	"BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ " +
+	"CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ " +
+	"DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR " +
+	"GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY " +
+	"HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO JP " +
+	"KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY " +
+	"MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ " +
+	"NA NC NE NF NG NI NL NO NP NR NU NZ OM " +
+	"PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO RS RU RW " +
+	"SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ " +
+	"TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ " +
+	"UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW"
+
+// validCountryCodes is the set of ISO 3166-1 alpha-2 codes.
+var validCountryCodes = func() map[CountryCode]bool {
+	codes := make(map[CountryCode]bool)
+	for _, code := range strings.Fields(countryCodes) {
+		codes[CountryCode(code)] = true
+	}
+	return codes
+}()
+
+// Valid reports whether c is an ISO 3166-1 alpha-2 country code.
+func (c CountryCode) Valid() bool {
+	return validCountryCodes[c]
+}
+
+// CountryCodes returns every valid CountryCode in alphabetical order.
+func CountryCodes() []CountryCode {
+	codes := make([]CountryCode, 0, len(validCountryCodes))
+	for code := range validCountryCodes {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// validateSearchOptions checks search filters that can be verified locally, so that
+// typos return a helpful error instead of an empty result set from the API.
+func validateSearchOptions(opts SearchOptions) error {
+	// Foreign addresses hold free-form provinces and regions in State
+	usState := CountryCode(opts.CountryCode).USPostal()
+	if opts.State != "" && usState && !State(opts.State).Valid() {
+		msg := fmt.Sprintf("invalid state %q", opts.State)
+		if upper := strings.ToUpper(opts.State); State(upper).Valid() {
+			msg += fmt.Sprintf(": state codes must be uppercase, use %q", upper)
+		} else {
+			valid := make([]string, 0, len(validStates))
+			for _, s := range States() {
+				valid = append(valid, string(s))
+			}
+			msg += ": valid values are " + strings.Join(valid, ", ")
+		}
+		return &ValidationError{Field: "State", Message: msg}
+	}
+
+	if opts.CountryCode != "" && !CountryCode(opts.CountryCode).Valid() {
+		msg := fmt.Sprintf("invalid country code %q", opts.CountryCode)
+		if upper := strings.ToUpper(opts.CountryCode); CountryCode(upper).Valid() {
+			msg += fmt.Sprintf(": country codes must be uppercase, use %q", upper)
+		} else {
+			msg += ": must be a two-letter ISO 3166-1 alpha-2 code such as US, CA or MX (see CountryCodes)"
+		}
+		return &ValidationError{Field: "CountryCode", Message: msg}
+	}
+
+	return nil
+}
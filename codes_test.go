@@ -0,0 +1,66 @@
+package gonpi
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestStateValid tests the state value set.
+func TestStateValid(t *testing.T) {
+	for _, s := range []State{StateCA, StateDC, StatePR, StateGU, StateAE} {
+		if !s.Valid() {
+			t.Errorf("expected %s to be valid", s)
+		}
+	}
+	for _, s := range []State{"XX", "ca", "", "USA"} {
+		if s.Valid() {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+	if len(States()) != 62 {
+		t.Errorf("expected 62 states, got %d", len(States()))
+	}
+}
+
+// TestCountryCodeValid tests the country code value set.
+func TestCountryCodeValid(t *testing.T) {
+	for _, c := range []CountryCode{CountryUS, CountryCA, CountryMX, "DE", "JP"} {
+		if !c.Valid() {
+			t.Errorf("expected %s to be valid", c)
+		}
+	}
+	for _, c := range []CountryCode{"XX", "us", "USA"} {
+		if c.Valid() {
+			t.Errorf("expected %q to be invalid", c)
+		}
+	}
+}
+
+// TestSearchProviders_InvalidCodes tests local validation of state and country codes.
+func TestSearchProviders_InvalidCodes(t *testing.T) {
+	client := NewClient(WithBaseURL("http://127.0.0.1:1"))
+
+	tests := []struct {
+		name     string
+		opts     SearchOptions
+		contains string
+	}{
+		{"unknown state", SearchOptions{LastName: "Smith", State: "XX"}, "valid values are AA, AE, AK"},
+		{"lowercase state", SearchOptions{LastName: "Smith", State: "ca"}, `use "CA"`},
+		{"unknown country", SearchOptions{LastName: "Smith", CountryCode: "XX"}, "ISO 3166-1"},
+		{"lowercase country", SearchOptions{LastName: "Smith", CountryCode: "mx"}, `use "MX"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.SearchProviders(context.Background(), tt.opts)
+			if !IsValidation(err) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error to contain %q, got %q", tt.contains, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package gonpi
+
+import (
+	"context"
+	"time"
+)
+
+// StoreStats describes the size of a MemoryStore.
+type StoreStats struct {
+	// Providers is the number of stored providers.
+	Providers int `json:"providers"`
+
+	// IndexKeys is the number of distinct keys across all secondary indexes.
+	IndexKeys int `json:"index_keys"`
+
+	// IndexEntries is the number of key-to-provider entries across all indexes.
+	IndexEntries int `json:"index_entries"`
+
+	// Allocated is the high-water mark of providers since the store was created or
+	// last compacted. Go maps do not shrink, so memory is held in proportion to
+	// Allocated rather than Providers until Compact runs.
+	Allocated int `json:"allocated"`
+}
+
+// CompactResult reports the outcome of a compaction.
+type CompactResult struct {
+	Before   StoreStats    `json:"before"`
+	After    StoreStats    `json:"after"`
+	Duration time.Duration `json:"duration"`
+
+	// Dropped is the number of deactivated providers removed with WithDropDeactivated.
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// CompactOption configures Compact.
+type CompactOption func(*compactConfig)
+
+type compactConfig struct {
+	progress        func(done, total int)
+	dropDeactivated bool
+}
+
+// WithCompactProgress sets a function called periodically during compaction with the
+// number of providers processed so far and the total.
+func WithCompactProgress(fn func(done, total int)) CompactOption {
+	return func(c *compactConfig) {
+		c.progress = fn
+	}
+}
+
+// WithDropDeactivated makes Compact also remove providers the registry lists as
+// deactivated. Synced stores keep the last record of a deactivated NPI forever
+// otherwise, since the registry stops returning it.
+func WithDropDeactivated() CompactOption {
+	return func(c *compactConfig) {
+		c.dropDeactivated = true
+	}
+}
+
+// compactProgressInterval is how many providers are processed between progress calls.
+const compactProgressInterval = 10000
+
+// Stats returns the current size of the store.
+func (s *MemoryStore) Stats() StoreStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stats()
+}
+
+// stats computes StoreStats. s.mu must be held.
+func (s *MemoryStore) stats() StoreStats {
+	stats := StoreStats{Providers: len(s.providers), Allocated: s.peak}
+	for _, entries := range s.indexes {
+		stats.IndexKeys += len(entries)
+		for _, npis := range entries {
+			stats.IndexEntries += len(npis)
+		}
+	}
+	return stats
+}
+
+// Compact reclaims memory left behind by deleted and replaced providers by rebuilding
+// the store's maps and indexes at their live size, and drops index entries that no
+// longer point at a stored provider. With WithDropDeactivated it also removes
+// deactivated providers. Long-running stores that churn through many
+// updates should compact periodically, e.g. with CompactTask on a Scheduler.
+//
+// Writers are blocked while Compact runs. If ctx is cancelled midway the store is
+// left unchanged and ctx.Err() is returned.
+func (s *MemoryStore) Compact(ctx context.Context, opts ...CompactOption) (CompactResult, error) {
+	var config compactConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := time.Now()
+	result := CompactResult{Before: s.stats()}
+	dropped := func(provider *Provider) bool {
+		return config.dropDeactivated && !isActive(provider)
+	}
+	total := len(s.providers)
+	if config.dropDeactivated {
+		for _, provider := range s.providers {
+			if dropped(provider) {
+				total--
+			}
+		}
+	}
+
+	providers := make(map[string]*Provider, total)
+	for npi, provider := range s.providers {
+		if !dropped(provider) {
+			providers[npi] = provider
+		}
+	}
+
+	rebuilt := &MemoryStore{
+		providers: providers,
+		defs:      s.defs,
+		indexes:   make(map[string]map[string]map[string]struct{}, len(s.indexes)),
+		keys:      make(map[string]map[string][]string, total),
+	}
+	done := 0
+	for _, provider := range providers {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		for _, idx := range s.defs {
+			rebuilt.index(idx, provider)
+		}
+		done++
+		if config.progress != nil && done%compactProgressInterval == 0 {
+			config.progress(done, total)
+		}
+	}
+	if config.progress != nil {
+		config.progress(done, total)
+	}
+
+	result.Dropped = len(s.providers) - total
+	s.providers = rebuilt.providers
+	s.indexes = rebuilt.indexes
+	s.keys = rebuilt.keys
+	s.peak = total
+
+	result.After = s.stats()
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// CompactTask returns a scheduler Task that compacts the store and passes each result
+// to report, which may be nil.
+func (s *MemoryStore) CompactTask(report func(CompactResult)) Task {
+	return func(ctx context.Context) error {
+		result, err := s.Compact(ctx)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			report(result)
+		}
+		return nil
+	}
+}
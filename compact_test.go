@@ -0,0 +1,98 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestMemoryStore_Compact tests that compaction shrinks to the live size and keeps indexes.
+func TestMemoryStore_Compact(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for i := 0; i < 100; i++ {
+		p := mockProvider()
+		p.Number = fmt.Sprintf("%010d", i)
+		p.Addresses = []Address{{AddressPurpose: "LOCATION", TelephoneNumber: "617-555-0100"}}
+		store.Put(ctx, p)
+	}
+	for i := 10; i < 100; i++ {
+		store.Delete(ctx, fmt.Sprintf("%010d", i))
+	}
+
+	var lastDone, lastTotal int
+	result, err := store.Compact(ctx, WithCompactProgress(func(done, total int) {
+		lastDone, lastTotal = done, total
+	}))
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if result.Before.Allocated != 100 || result.After.Allocated != 10 {
+		t.Errorf("allocated before/after = %d/%d, want 100/10", result.Before.Allocated, result.After.Allocated)
+	}
+	if result.After.Providers != 10 || result.After.IndexEntries != result.Before.IndexEntries {
+		t.Errorf("unexpected stats after compaction: %+v (before %+v)", result.After, result.Before)
+	}
+	if lastDone != 10 || lastTotal != 10 {
+		t.Errorf("final progress = %d/%d, want 10/10", lastDone, lastTotal)
+	}
+
+	found, _ := store.Lookup(ctx, IndexPhone, "6175550100")
+	if len(found) != 10 {
+		t.Errorf("phone lookup after compaction = %d results, want 10", len(found))
+	}
+}
+
+// TestMemoryStore_CompactDropDeactivated tests that WithDropDeactivated removes
+// deactivated providers and their index entries.
+func TestMemoryStore_CompactDropDeactivated(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	active, deactivated := mockProvider(), mockProvider()
+	deactivated.Number = "1111111111"
+	deactivated.Basic.Status = "D"
+	for _, p := range []*Provider{&active, &deactivated} {
+		p.Addresses = []Address{{AddressPurpose: "LOCATION", TelephoneNumber: "617-555-0100"}}
+	}
+	store.Put(ctx, active, deactivated)
+
+	if result, err := store.Compact(ctx); err != nil || result.Dropped != 0 || result.After.Providers != 2 {
+		t.Fatalf("compact without the option = %+v, %v", result, err)
+	}
+	result, err := store.Compact(ctx, WithDropDeactivated())
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if result.Dropped != 1 || result.After.Providers != 1 || result.After.Allocated != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if found, _ := store.Lookup(ctx, IndexPhone, "6175550100"); len(found) != 1 || found[0].Number != active.Number {
+		t.Errorf("phone lookup after dropping = %+v", found)
+	}
+}
+
+// TestMemoryStore_CompactCancelled tests that a cancelled compaction leaves the store unchanged.
+func TestMemoryStore_CompactCancelled(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(context.Background(), mockProvider(), Provider{Number: "1111111111"})
+	store.Delete(context.Background(), mockProvider().Number)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.Compact(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if stats := store.Stats(); stats.Allocated != 2 || stats.Providers != 1 {
+		t.Errorf("store changed after cancelled compaction: %+v", stats)
+	}
+
+	var reported *CompactResult
+	task := store.CompactTask(func(r CompactResult) { reported = &r })
+	if err := task(context.Background()); err != nil {
+		t.Fatalf("task: %v", err)
+	}
+	if reported == nil || reported.After.Allocated != 1 {
+		t.Errorf("unexpected task report: %+v", reported)
+	}
+}
@@ -0,0 +1,130 @@
+package gonpi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConsistencyCheck names a data-quality check run by Provider.ConsistencyIssues.
+type ConsistencyCheck string
+
+// Consistency checks.
+const (
+	// CheckTaxonomyLicenseState flags a taxonomy whose license was issued in a state
+	// with none of the provider's practice locations.
+	CheckTaxonomyLicenseState ConsistencyCheck = "taxonomy_license_state"
+
+	// CheckIdentifierState flags an identifier issued in a state where the provider
+	// has no address.
+	CheckIdentifierState ConsistencyCheck = "identifier_state"
+)
+
+// ConsistencyIssue describes one record detail that fails a consistency check.
+type ConsistencyIssue struct {
+	// Check is the check that failed.
+	Check ConsistencyCheck
+
+	// State is the issuing state that matched no address.
+	State string
+
+	// Code is the taxonomy code, or the identifier, that was checked.
+	Code string
+
+	// Message is a human-readable description of the issue.
+	Message string
+}
+
+// String returns the issue's message.
+func (i ConsistencyIssue) String() string {
+	return i.Message
+}
+
+// ConsistencyIssues cross-checks the states of the provider's licenses and identifiers
+// against its addresses. A taxonomy license is expected in the state of a practice
+// location (a LOCATION address or an entry of PracticeLocations), and an identifier
+// in the state of any address. States are compared case-insensitively, and checks
+// are skipped for a provider with no addresses in the corresponding set, since
+// there is nothing to compare with.
+//
+// Issues are data-quality signals, not errors: providers licensed in several states
+// for telehealth, for example, legitimately fail CheckTaxonomyLicenseState.
+//
+// Example usage:
+//
+//	for _, issue := range provider.ConsistencyIssues() {
+//	    log.Printf("%s: %s", provider.Number, issue)
+//	}
+func (p Provider) ConsistencyIssues() []ConsistencyIssue {
+	practice := make(map[string]bool)
+	addressed := make(map[string]bool)
+	for _, address := range p.Addresses {
+		state := normalizeState(address.State)
+		if state == "" {
+			continue
+		}
+		addressed[state] = true
+		if strings.EqualFold(address.AddressPurpose, "LOCATION") {
+			practice[state] = true
+		}
+	}
+	for _, location := range p.PracticeLocations {
+		if state := normalizeState(location.State); state != "" {
+			addressed[state] = true
+			practice[state] = true
+		}
+	}
+
+	var issues []ConsistencyIssue
+	if len(practice) > 0 {
+		for _, taxonomy := range p.Taxonomies {
+			state := normalizeState(taxonomy.State)
+			if state == "" || strings.TrimSpace(taxonomy.License) == "" || practice[state] {
+				continue
+			}
+			issues = append(issues, ConsistencyIssue{
+				Check:   CheckTaxonomyLicenseState,
+				State:   state,
+				Code:    taxonomy.Code,
+				Message: fmt.Sprintf("taxonomy %s is licensed in %s, which has no practice location", taxonomy.Code, state),
+			})
+		}
+	}
+	if len(addressed) > 0 {
+		for _, identifier := range p.Identifiers {
+			state := normalizeState(identifier.State)
+			if state == "" || addressed[state] {
+				continue
+			}
+			issues = append(issues, ConsistencyIssue{
+				Check:   CheckIdentifierState,
+				State:   state,
+				Code:    identifier.Identifier,
+				Message: fmt.Sprintf("identifier %s was issued in %s, which has no address", identifier.Identifier, state),
+			})
+		}
+	}
+	return issues
+}
+
+// Inconsistent matches providers with at least one issue from ConsistencyIssues,
+// limited to the given checks if any are listed.
+func Inconsistent(checks ...ConsistencyCheck) ProviderFilter {
+	return func(p Provider) bool {
+		for _, issue := range p.ConsistencyIssues() {
+			if len(checks) == 0 {
+				return true
+			}
+			for _, check := range checks {
+				if issue.Check == check {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// normalizeState folds a state code for comparison.
+func normalizeState(state string) string {
+	return strings.ToUpper(strings.TrimSpace(state))
+}
@@ -0,0 +1,58 @@
+package gonpi
+
+import "testing"
+
+// TestProvider_ConsistencyIssues tests cross-checking license and identifier states
+// against addresses.
+func TestProvider_ConsistencyIssues(t *testing.T) {
+	p := Provider{
+		Number: "1234567890",
+		Addresses: []Address{
+			{AddressPurpose: "LOCATION", State: "MA"},
+			{AddressPurpose: "MAILING", State: "nh"},
+		},
+		PracticeLocations: []PracticeLocation{{State: "RI"}},
+		Taxonomies: []Taxonomy{
+			{Code: "207R00000X", State: "ma", License: "123"},
+			{Code: "207Q00000X", State: "RI", License: "456"},
+			{Code: "208D00000X", State: "NH", License: "789"},
+			{Code: "2084P0800X", State: "NY"},
+		},
+		Identifiers: []Identifier{
+			{Identifier: "MC1", State: "NH"},
+			{Identifier: "MC2", State: "CT"},
+			{Identifier: "MC3"},
+		},
+	}
+
+	issues := p.ConsistencyIssues()
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %v", issues)
+	}
+	if issues[0].Check != CheckTaxonomyLicenseState || issues[0].Code != "208D00000X" || issues[0].State != "NH" {
+		t.Errorf("taxonomy issue = %+v", issues[0])
+	}
+	if issues[1].Check != CheckIdentifierState || issues[1].Code != "MC2" || issues[1].State != "CT" {
+		t.Errorf("identifier issue = %+v", issues[1])
+	}
+
+	if !Inconsistent()(p) || !Inconsistent(CheckIdentifierState)(p) {
+		t.Error("expected Inconsistent to match")
+	}
+	if Inconsistent("other")(p) {
+		t.Error("expected Inconsistent to ignore unlisted checks")
+	}
+}
+
+// TestProvider_ConsistencyIssues_NoAddresses tests that checks are skipped without
+// addresses to compare with.
+func TestProvider_ConsistencyIssues_NoAddresses(t *testing.T) {
+	p := Provider{
+		Addresses:   []Address{{AddressPurpose: "MAILING", State: "MA"}},
+		Taxonomies:  []Taxonomy{{Code: "207R00000X", State: "NY", License: "123"}},
+		Identifiers: []Identifier{{Identifier: "MC1", State: "MA"}},
+	}
+	if issues := p.ConsistencyIssues(); len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
@@ -0,0 +1,160 @@
+package gonpi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// County identifies a US county by its 5-digit FIPS code.
+type County struct {
+	// FIPS is the 5-digit state+county FIPS code (e.g., "06037").
+	FIPS string `json:"fips"`
+
+	// Name is the county name, if present in the source table.
+	Name string `json:"name,omitempty"`
+}
+
+// StateFIPS returns the 2-digit state FIPS prefix of the county code.
+func (c County) StateFIPS() string {
+	if len(c.FIPS) < 2 {
+		return ""
+	}
+	return c.FIPS[:2]
+}
+
+// CountyLocation associates a practice location with the county it falls in.
+type CountyLocation struct {
+	Address1   string `json:"address_1"`
+	PostalCode string `json:"postal_code"`
+	County     County `json:"county"`
+}
+
+// ZIPCounties is an in-memory ZIP code to county lookup table.
+// ZIP codes crossing county lines resolve to the county holding the largest share
+// of addresses. It is safe for concurrent use.
+type ZIPCounties struct {
+	mu     sync.RWMutex
+	data   map[string]County
+	ratios map[string]float64
+}
+
+// NewZIPCounties creates an empty ZIP to county table.
+func NewZIPCounties() *ZIPCounties {
+	return &ZIPCounties{
+		data:   make(map[string]County),
+		ratios: make(map[string]float64),
+	}
+}
+
+// Set stores the county for a ZIP code, replacing any existing entry.
+func (z *ZIPCounties) Set(zip string, county County) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.data[zip5(zip)] = county
+	delete(z.ratios, zip5(zip))
+}
+
+// Lookup returns the county for a ZIP code. Only the first five digits of zip are used.
+func (z *ZIPCounties) Lookup(zip string) (County, bool) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	county, ok := z.data[zip5(zip)]
+	return county, ok
+}
+
+// Len returns the number of ZIP codes in the table.
+func (z *ZIPCounties) Len() int {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return len(z.data)
+}
+
+// LoadZIPCounties reads a ZIP to county crosswalk with a header row, such as the HUD
+// USPS ZIP-County crosswalk (ZIP, COUNTY, RES_RATIO/TOT_RATIO columns) or a CSV with
+// zip, county_fips/fips and optional county_name columns. When a ZIP code appears more
+// than once, the row with the highest ratio wins.
+func LoadZIPCounties(r io.Reader) (*ZIPCounties, error) {
+	reader, cols, err := newHeaderReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zipCol := firstColumn(cols, "zip", "zipcode", "zip_code", "zcta", "zcta5")
+	fipsCol := firstColumn(cols, "county", "county_fips", "fips", "geoid")
+	nameCol := firstColumn(cols, "county_name", "countyname", "name")
+	ratioCol := firstColumn(cols, "res_ratio", "tot_ratio", "ratio")
+	if zipCol < 0 || fipsCol < 0 {
+		return nil, errors.New("missing zip or county column")
+	}
+
+	table := NewZIPCounties()
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRowLength(reader, row, zipCol, fipsCol); err != nil {
+			return nil, err
+		}
+		zip := zip5(row[zipCol])
+		fips := strings.TrimSpace(row[fipsCol])
+		if len(fips) != 5 {
+			return nil, fmt.Errorf("invalid county FIPS %q for %s", fips, zip)
+		}
+
+		ratio := 1.0
+		if ratioCol >= 0 && ratioCol < len(row) {
+			if ratio, err = strconv.ParseFloat(strings.TrimSpace(row[ratioCol]), 64); err != nil {
+				return nil, fmt.Errorf("invalid ratio for %s: %w", zip, err)
+			}
+		}
+		if existing, ok := table.ratios[zip]; ok && existing >= ratio {
+			continue
+		}
+
+		county := County{FIPS: fips}
+		if nameCol >= 0 && nameCol < len(row) {
+			county.Name = strings.TrimSpace(row[nameCol])
+		}
+		table.data[zip] = county
+		table.ratios[zip] = ratio
+	}
+	return table, nil
+}
+
+// EnrichCounty attaches county identifiers to the provider's practice locations using
+// the given ZIP to county table. Locations whose ZIP code is not in the table are skipped.
+// Results replace provider.Extensions.Counties.
+func EnrichCounty(provider *Provider, counties *ZIPCounties) error {
+	if provider == nil {
+		return &ValidationError{Field: "provider", Message: "provider cannot be nil"}
+	}
+	if counties == nil {
+		return errors.New("no ZIP county table configured")
+	}
+
+	var locations []CountyLocation
+	for _, addr := range practiceAddresses(provider) {
+		county, ok := counties.Lookup(addr.PostalCode)
+		if !ok {
+			continue
+		}
+		locations = append(locations, CountyLocation{
+			Address1:   addr.Address1,
+			PostalCode: addr.PostalCode,
+			County:     county,
+		})
+	}
+
+	if provider.Extensions == nil {
+		provider.Extensions = &Extensions{}
+	}
+	provider.Extensions.Counties = locations
+	return nil
+}
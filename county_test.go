@@ -0,0 +1,72 @@
+package gonpi
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLoadZIPCounties tests loading a HUD-style crosswalk with split ZIP codes.
+func TestLoadZIPCounties(t *testing.T) {
+	data := "ZIP,COUNTY,USPS_ZIP_PREF_CITY,USPS_ZIP_PREF_STATE,RES_RATIO,BUS_RATIO,OTH_RATIO,TOT_RATIO\n" +
+		"12345,06037,ANYTOWN,CA,0.25,0.3,0,0.26\n" +
+		"12345,06059,ANYTOWN,CA,0.75,0.7,1,0.74\n" +
+		"54321,36061,OTHERTOWN,NY,1,1,1,1\n"
+
+	table, err := LoadZIPCounties(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Len() != 2 {
+		t.Errorf("expected 2 ZIP codes, got %d", table.Len())
+	}
+
+	county, ok := table.Lookup("12345-0001")
+	if !ok || county.FIPS != "06059" {
+		t.Errorf("expected majority county 06059, got %+v", county)
+	}
+	if county.StateFIPS() != "06" {
+		t.Errorf("expected state FIPS 06, got %s", county.StateFIPS())
+	}
+}
+
+// TestLoadZIPCounties_Invalid tests rejection of malformed crosswalks.
+func TestLoadZIPCounties_Invalid(t *testing.T) {
+	inputs := map[string]string{
+		"missing columns": "zip,city\n12345,ANYTOWN\n",
+		"bad fips":        "zip,fips\n12345,637\n",
+		"bad ratio":       "zip,fips,ratio\n12345,06037,abc\n",
+		"short row":       "zip,fips\n12345\n",
+	}
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadZIPCounties(strings.NewReader(data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// TestEnrichCounty tests attaching counties to practice locations.
+func TestEnrichCounty(t *testing.T) {
+	table := NewZIPCounties()
+	table.Set("12345", County{FIPS: "06037", Name: "Los Angeles"})
+
+	provider := mockProvider()
+	provider.PracticeLocations = []PracticeLocation{{Address1: "1 UNKNOWN RD", PostalCode: "99999"}}
+
+	if err := EnrichCounty(&provider, table); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counties := provider.Extensions.Counties
+	if len(counties) != 1 {
+		t.Fatalf("expected 1 county location, got %d", len(counties))
+	}
+	if counties[0].County.Name != "Los Angeles" || counties[0].Address1 != "123 MAIN ST" {
+		t.Errorf("unexpected county location: %+v", counties[0])
+	}
+
+	if err := EnrichCounty(&provider, nil); err == nil {
+		t.Error("expected error for missing table")
+	}
+}
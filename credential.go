@@ -0,0 +1,164 @@
+package gonpi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CredentialInfo describes a standardized credential code.
+type CredentialInfo struct {
+	// Code is the canonical spelling, e.g. "MD" or "PharmD".
+	Code string
+
+	// Description is the full credential name.
+	Description string
+}
+
+// credentialTable maps normalized credential keys (upper-case letters and digits only)
+// to their canonical form.
+var credentialTable = map[string]CredentialInfo{
+	"MD":     {"MD", "Doctor of Medicine"},
+	"DO":     {"DO", "Doctor of Osteopathic Medicine"},
+	"MBBS":   {"MBBS", "Bachelor of Medicine, Bachelor of Surgery"},
+	"NP":     {"NP", "Nurse Practitioner"},
+	"FNP":    {"FNP", "Family Nurse Practitioner"},
+	"FNPC":   {"FNP-C", "Family Nurse Practitioner, Certified"},
+	"FNPBC":  {"FNP-BC", "Family Nurse Practitioner, Board Certified"},
+	"APRN":   {"APRN", "Advanced Practice Registered Nurse"},
+	"CRNA":   {"CRNA", "Certified Registered Nurse Anesthetist"},
+	"CNM":    {"CNM", "Certified Nurse Midwife"},
+	"CNS":    {"CNS", "Clinical Nurse Specialist"},
+	"RN":     {"RN", "Registered Nurse"},
+	"LPN":    {"LPN", "Licensed Practical Nurse"},
+	"PA":     {"PA", "Physician Assistant"},
+	"PAC":    {"PA-C", "Physician Assistant, Certified"},
+	"DDS":    {"DDS", "Doctor of Dental Surgery"},
+	"DMD":    {"DMD", "Doctor of Dental Medicine"},
+	"DPM":    {"DPM", "Doctor of Podiatric Medicine"},
+	"OD":     {"OD", "Doctor of Optometry"},
+	"DC":     {"DC", "Doctor of Chiropractic"},
+	"PHARMD": {"PharmD", "Doctor of Pharmacy"},
+	"RPH":    {"RPh", "Registered Pharmacist"},
+	"PHD":    {"PhD", "Doctor of Philosophy"},
+	"PSYD":   {"PsyD", "Doctor of Psychology"},
+	"LCSW":   {"LCSW", "Licensed Clinical Social Worker"},
+	"LMSW":   {"LMSW", "Licensed Master Social Worker"},
+	"MSW":    {"MSW", "Master of Social Work"},
+	"LMFT":   {"LMFT", "Licensed Marriage and Family Therapist"},
+	"LPC":    {"LPC", "Licensed Professional Counselor"},
+	"LMHC":   {"LMHC", "Licensed Mental Health Counselor"},
+	"DPT":    {"DPT", "Doctor of Physical Therapy"},
+	"PT":     {"PT", "Physical Therapist"},
+	"OT":     {"OT", "Occupational Therapist"},
+	"OTR":    {"OTR", "Occupational Therapist, Registered"},
+	"OTRL":   {"OTR/L", "Occupational Therapist, Registered and Licensed"},
+	"SLP":    {"SLP", "Speech-Language Pathologist"},
+	"CCCSLP": {"CCC-SLP", "Certificate of Clinical Competence in Speech-Language Pathology"},
+	"AUD":    {"AuD", "Doctor of Audiology"},
+	"RD":     {"RD", "Registered Dietitian"},
+	"RDN":    {"RDN", "Registered Dietitian Nutritionist"},
+	"MPH":    {"MPH", "Master of Public Health"},
+	"MBA":    {"MBA", "Master of Business Administration"},
+}
+
+// credentialAliases maps alternate spellings to normalized keys in credentialTable.
+var credentialAliases = map[string]string{
+	"PHYSICIANASSISTANT": "PA",
+	"NURSEPRACTITIONER":  "NP",
+}
+
+// ParseCredentials splits a free-text credential string such as "M.D., PH.D." into
+// standardized credential codes ("MD", "PhD"). Commas, semicolons and ampersands
+// always separate credentials. Slashes and whitespace separate them too, except
+// within a credential in the reference table, so "OTR/L", "Pharm. D." and "Physician
+// Assistant" each parse as one code; the longest known run of words wins. Periods are
+// ignored. Codes not in the reference table are returned upper-cased with punctuation
+// removed. Duplicates are removed and the original order is preserved.
+func ParseCredentials(s string) []string {
+	segments := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == '&'
+	})
+
+	var codes []string
+	seen := make(map[string]bool)
+	for _, segment := range segments {
+		words := strings.FieldsFunc(segment, func(r rune) bool {
+			return r == '/' || unicode.IsSpace(r)
+		})
+		for i := 0; i < len(words); {
+			n := knownCredentialRun(words[i:])
+			code := StandardizeCredential(strings.Join(words[i:i+n], " "))
+			i += n
+			if code == "" || seen[code] {
+				continue
+			}
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// knownCredentialRun returns the number of leading words that together spell the
+// longest credential in the reference table, or 1 if none does.
+func knownCredentialRun(words []string) int {
+	for n := len(words); n > 1; n-- {
+		if _, ok := LookupCredential(strings.Join(words[:n], "")); ok {
+			return n
+		}
+	}
+	return 1
+}
+
+// StandardizeCredential returns the canonical form of a single credential code,
+// e.g. "pharm.d" becomes "PharmD" and "pa-c" becomes "PA-C". Unknown codes are returned
+// upper-cased with periods removed.
+func StandardizeCredential(code string) string {
+	key := credentialKey(code)
+	if alias, ok := credentialAliases[key]; ok {
+		key = alias
+	}
+	if info, ok := credentialTable[key]; ok {
+		return info.Code
+	}
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), ".", ""))
+}
+
+// LookupCredential returns the reference entry for a credential code in any spelling.
+func LookupCredential(code string) (CredentialInfo, bool) {
+	key := credentialKey(code)
+	if alias, ok := credentialAliases[key]; ok {
+		key = alias
+	}
+	info, ok := credentialTable[key]
+	return info, ok
+}
+
+// credentialKey normalizes a credential to upper-case letters and digits only.
+func credentialKey(code string) string {
+	var b strings.Builder
+	for _, r := range code {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// Credentials returns the provider's standardized credential codes parsed from
+// Basic.Credential.
+func (p Provider) Credentials() []string {
+	return ParseCredentials(p.Basic.Credential)
+}
+
+// HasCredential reports whether the provider holds the given credential. The comparison
+// ignores case and punctuation, so "MD", "M.D." and "md" are equivalent.
+func (p Provider) HasCredential(code string) bool {
+	want := credentialKey(StandardizeCredential(code))
+	for _, have := range p.Credentials() {
+		if credentialKey(have) == want {
+			return true
+		}
+	}
+	return false
+}
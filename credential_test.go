@@ -0,0 +1,64 @@
+package gonpi
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseCredentials tests splitting and standardizing credential strings.
+func TestParseCredentials(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"M.D., PH.D.", []string{"MD", "PhD"}},
+		{"MD", []string{"MD"}},
+		{"D.O.", []string{"DO"}},
+		{"pharm.d; rph", []string{"PharmD", "RPh"}},
+		{"PA-C", []string{"PA-C"}},
+		{"FNP-BC/APRN", []string{"FNP-BC", "APRN"}},
+		{"MD, M.D.", []string{"MD"}},
+		{"M.D. FACS", []string{"MD", "FACS"}},
+		{"OTR/L", []string{"OTR/L"}},
+		{"Pharm. D.", []string{"PharmD"}},
+		{"Physician Assistant", []string{"PA"}},
+		{"MD/PhD", []string{"MD", "PhD"}},
+		{"M.D. PH.D.", []string{"MD", "PhD"}},
+		{"CCC SLP & MPH", []string{"CCC-SLP", "MPH"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseCredentials(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCredentials(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLookupCredential tests the credential reference table.
+func TestLookupCredential(t *testing.T) {
+	info, ok := LookupCredential("Pharm.D.")
+	if !ok || info.Code != "PharmD" || info.Description != "Doctor of Pharmacy" {
+		t.Errorf("unexpected lookup result: %+v, %v", info, ok)
+	}
+	if _, ok := LookupCredential("XYZ"); ok {
+		t.Error("expected unknown credential to be missing")
+	}
+}
+
+// TestProvider_HasCredential tests credential matching on providers.
+func TestProvider_HasCredential(t *testing.T) {
+	provider := mockProvider()
+	provider.Basic.Credential = "M.D., PH.D."
+
+	for _, code := range []string{"MD", "m.d.", "PhD", "PHD"} {
+		if !provider.HasCredential(code) {
+			t.Errorf("expected HasCredential(%q) to be true", code)
+		}
+	}
+	if provider.HasCredential("DO") {
+		t.Error("expected HasCredential(DO) to be false")
+	}
+}
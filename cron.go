@@ -0,0 +1,188 @@
+package gonpi
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule determines when a scheduled task runs next.
+type Schedule interface {
+	// Next returns the first run time strictly after t, or the zero time if the
+	// schedule has no further runs.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a Schedule that runs every interval plus a random delay in [0, jitter).
+// Jitter spreads refreshes from many processes so they don't hit the registry at once.
+// Every panics if interval is not positive, as time.NewTicker does, rather than
+// running the task back to back.
+func Every(interval, jitter time.Duration) Schedule {
+	if interval <= 0 {
+		panic("gonpi: non-positive interval for Every")
+	}
+	return intervalSchedule{interval: interval, jitter: jitter}
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+	jitter   time.Duration
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	next := t.Add(s.interval)
+	if s.jitter > 0 {
+		next = next.Add(rand.N(s.jitter))
+	}
+	return next
+}
+
+// cronDescriptors maps the predefined cron shorthands to their expressions.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is a parsed five-field cron expression. Each field is a bitmask of
+// the values it matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny record whether the day fields were "*". Following cron, a
+	// day matches if either day field matches when both are restricted.
+	domAny, dowAny bool
+}
+
+// ParseCron parses a standard five-field cron expression
+// ("minute hour day-of-month month day-of-week") or one of the descriptors @yearly,
+// @annually, @monthly, @weekly, @daily, @midnight and @hourly.
+//
+// Fields accept "*", single values, ranges ("1-5"), lists ("1,15") and steps
+// ("*/15", "0-30/10"). Day of week runs from 0 (Sunday) to 6; 7 is also accepted
+// as Sunday. Times are evaluated in the location of the time passed to Next.
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@") {
+		expanded, ok := cronDescriptors[expr]
+		if !ok {
+			return nil, &ValidationError{Field: "cron", Message: fmt.Sprintf("unknown descriptor %q", expr)}
+		}
+		expr = expanded
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, &ValidationError{Field: "cron", Message: fmt.Sprintf("expected 5 fields, got %d", len(fields))}
+	}
+
+	var s cronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, cronFieldError("minute", err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, cronFieldError("hour", err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, cronFieldError("day of month", err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, cronFieldError("month", err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, cronFieldError("day of week", err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return s, nil
+}
+
+func cronFieldError(field string, err error) error {
+	return &ValidationError{Field: "cron", Message: fmt.Sprintf("invalid %s field: %v", field, err)}
+}
+
+// parseCronField parses one comma-separated cron field into a bitmask.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			loStr, hiStr, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, fmt.Errorf("invalid value %q", loStr)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, fmt.Errorf("invalid value %q", hiStr)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", rangePart, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, nil
+}
+
+// cronSearchLimit bounds how far Next looks ahead for expressions that can never
+// match, such as "0 0 30 2 *".
+const cronSearchLimit = 5
+
+func (s cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchLimit, 0, 0)
+
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case s.month&(1<<month) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<t.Weekday()) != 0
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,86 @@
+package gonpi
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseCron_Next tests next-run calculation for common cron expressions.
+func TestParseCron_Next(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, 1, 16, 3, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match
+		{"0 0 20 * 3", time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := schedule.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseCron_Invalid tests that malformed expressions are rejected.
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@fortnightly", "a * * * *"} {
+		if _, err := ParseCron(expr); !IsValidation(err) {
+			t.Errorf("ParseCron(%q) error = %v, want ValidationError", expr, err)
+		}
+	}
+
+	schedule, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("impossible schedule returned %v", next)
+	}
+}
+
+// TestEvery tests that interval schedules stay within the jitter window.
+func TestEvery(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := Every(time.Hour, 10*time.Minute)
+	for i := 0; i < 100; i++ {
+		next := schedule.Next(base)
+		if next.Before(base.Add(time.Hour)) || !next.Before(base.Add(70*time.Minute)) {
+			t.Fatalf("Next() = %v outside jitter window", next)
+		}
+	}
+	if next := Every(time.Minute, 0).Next(base); !next.Equal(base.Add(time.Minute)) {
+		t.Errorf("Next() without jitter = %v", next)
+	}
+}
+
+// TestEvery_NonPositive tests that Every panics on a non-positive interval.
+func TestEvery_NonPositive(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Every(%v) did not panic", interval)
+				}
+			}()
+			Every(interval, time.Second)
+		}()
+	}
+}
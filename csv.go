@@ -0,0 +1,210 @@
+package gonpi
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// newDelimitedReader returns a CSV reader positioned after the header row along with the
+// header names. Tab or comma delimiters are detected from the header row.
+func newDelimitedReader(r io.Reader) (*csv.Reader, []string, error) {
+	buffered := bufio.NewReader(r)
+	peek, _ := buffered.Peek(4096)
+	header, _, _ := strings.Cut(string(peek), "\n")
+
+	reader := csv.NewReader(buffered)
+	if strings.Contains(header, "\t") {
+		reader.Comma = '\t'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	names, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	return reader, append([]string(nil), names...), nil
+}
+
+// newHeaderReader returns a CSV reader positioned after the header row and a map of
+// lower-cased column names to indexes. Tab or comma delimiters are detected.
+func newHeaderReader(r io.Reader) (*csv.Reader, map[string]int, error) {
+	reader, names, err := newDelimitedReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	cols := make(map[string]int, len(names))
+	for i, name := range names {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	return reader, cols, nil
+}
+
+// checkRowLength returns an error if row, read by reader, is too short to hold every
+// column in cols. Readers from newDelimitedReader accept rows of any length, so a
+// truncated line must be caught before indexing it.
+func checkRowLength(reader *csv.Reader, row []string, cols ...int) error {
+	need := 0
+	for _, col := range cols {
+		need = max(need, col+1)
+	}
+	if len(row) < need {
+		line, _ := reader.FieldPos(0)
+		return fmt.Errorf("line %d has %d fields, want at least %d", line, len(row), need)
+	}
+	return nil
+}
+
+// firstColumn returns the index of the first of names present in cols, or -1.
+func firstColumn(cols map[string]int, names ...string) int {
+	for _, name := range names {
+		if i, ok := cols[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// CSVRecord is a single row from a public CMS dataset keyed by NPI.
+type CSVRecord struct {
+	// NPI is the value of the reader's NPI column.
+	NPI string
+
+	// Fields maps column names, as they appear in the header, to values.
+	Fields map[string]string
+}
+
+// CSVRecordReader streams rows from a large delimited file containing an NPI column,
+// such as the Open Payments detail files or the National Downloadable File.
+// Rows are read one at a time, so files larger than memory can be processed.
+type CSVRecordReader struct {
+	reader  *csv.Reader
+	header  []string
+	npiCol  int
+	columns map[int]bool
+}
+
+// Column names identifying the NPI in common CMS datasets.
+const (
+	// OpenPaymentsNPIColumn is the NPI column of the Open Payments general, research and
+	// ownership payment files.
+	OpenPaymentsNPIColumn = "Covered_Recipient_NPI"
+
+	// NationalDownloadableFileNPIColumn is the NPI column of the Doctors and Clinicians
+	// National Downloadable File (formerly Physician Compare).
+	NationalDownloadableFileNPIColumn = "NPI"
+)
+
+// NewCSVRecordReader creates a reader for a delimited file with a header row.
+// npiColumn names the column holding the NPI (matched case-insensitively). If columns
+// is not empty, only those columns are kept in CSVRecord.Fields, which reduces memory
+// for wide files.
+func NewCSVRecordReader(r io.Reader, npiColumn string, columns ...string) (*CSVRecordReader, error) {
+	reader, header, err := newDelimitedReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := &CSVRecordReader{reader: reader, header: header, npiCol: -1}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), npiColumn) {
+			rr.npiCol = i
+		}
+	}
+	if rr.npiCol < 0 {
+		return nil, fmt.Errorf("missing NPI column %q", npiColumn)
+	}
+
+	if len(columns) > 0 {
+		rr.columns = make(map[int]bool, len(columns))
+		for _, column := range columns {
+			for i, name := range header {
+				if strings.EqualFold(strings.TrimSpace(name), column) {
+					rr.columns[i] = true
+				}
+			}
+		}
+	}
+	return rr, nil
+}
+
+// NewOpenPaymentsReader creates a CSVRecordReader for an Open Payments detail file.
+func NewOpenPaymentsReader(r io.Reader, columns ...string) (*CSVRecordReader, error) {
+	return NewCSVRecordReader(r, OpenPaymentsNPIColumn, columns...)
+}
+
+// NewNationalDownloadableFileReader creates a CSVRecordReader for the Doctors and
+// Clinicians National Downloadable File.
+func NewNationalDownloadableFileReader(r io.Reader, columns ...string) (*CSVRecordReader, error) {
+	return NewCSVRecordReader(r, NationalDownloadableFileNPIColumn, columns...)
+}
+
+// Header returns the column names of the file.
+func (rr *CSVRecordReader) Header() []string {
+	return rr.header
+}
+
+// Next returns the next row. It returns io.EOF when no rows remain.
+func (rr *CSVRecordReader) Next() (CSVRecord, error) {
+	row, err := rr.reader.Read()
+	if err != nil {
+		return CSVRecord{}, err
+	}
+	if rr.npiCol >= len(row) {
+		line, _ := rr.reader.FieldPos(0)
+		return CSVRecord{}, fmt.Errorf("line %d: missing NPI column", line)
+	}
+
+	record := CSVRecord{
+		NPI:    strings.TrimSpace(row[rr.npiCol]),
+		Fields: make(map[string]string, len(row)),
+	}
+	for i, value := range row {
+		if i >= len(rr.header) || (rr.columns != nil && !rr.columns[i]) {
+			continue
+		}
+		record.Fields[rr.header[i]] = value
+	}
+	return record, nil
+}
+
+// JoinedProvider pairs a provider with the dataset rows sharing its NPI.
+type JoinedProvider struct {
+	Provider Provider
+	Records  []CSVRecord
+}
+
+// JoinByNPI streams every row from records and attaches matching rows to providers
+// by NPI. The result preserves the order of providers; providers without matching
+// rows have no Records. Only matching rows are held in memory.
+func JoinByNPI(providers []Provider, records *CSVRecordReader) ([]JoinedProvider, error) {
+	if records == nil {
+		return nil, errors.New("records reader cannot be nil")
+	}
+
+	joined := make([]JoinedProvider, len(providers))
+	index := make(map[string][]int, len(providers))
+	for i, p := range providers {
+		joined[i].Provider = p
+		index[p.Number] = append(index[p.Number], i)
+	}
+
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+		for _, i := range index[record.NPI] {
+			joined[i].Records = append(joined[i].Records, record)
+		}
+	}
+	return joined, nil
+}
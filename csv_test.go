@@ -0,0 +1,74 @@
+package gonpi
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestCSVRecordReader tests streaming rows with column projection.
+func TestCSVRecordReader(t *testing.T) {
+	data := "Change_Type,Covered_Recipient_NPI,Total_Amount_of_Payment_USDollars,Nature_of_Payment\n" +
+		"NEW,1234567890,25.50,Food and Beverage\n" +
+		"NEW,0987654321,100.00,Consulting Fee\n"
+
+	reader, err := NewOpenPaymentsReader(strings.NewReader(data), "Total_Amount_of_Payment_USDollars")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	record, err := reader.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record.NPI != "1234567890" {
+		t.Errorf("expected NPI 1234567890, got %s", record.NPI)
+	}
+	if len(record.Fields) != 1 || record.Fields["Total_Amount_of_Payment_USDollars"] != "25.50" {
+		t.Errorf("unexpected fields: %v", record.Fields)
+	}
+
+	if _, err := reader.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestCSVRecordReader_MissingNPIColumn tests header validation.
+func TestCSVRecordReader_MissingNPIColumn(t *testing.T) {
+	if _, err := NewNationalDownloadableFileReader(strings.NewReader("Ind_PAC_ID,lst_nm\n1,DOE\n")); err == nil {
+		t.Error("expected error for missing NPI column")
+	}
+}
+
+// TestJoinByNPI tests joining providers with dataset rows.
+func TestJoinByNPI(t *testing.T) {
+	data := "NPI\tInd_PAC_ID\tpri_spec\n" +
+		"1234567890\t111\tFAMILY PRACTICE\n" +
+		"5555555555\t222\tCARDIOLOGY\n" +
+		"1234567890\t111\tFAMILY PRACTICE\n"
+
+	reader, err := NewNationalDownloadableFileReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other := mockProvider()
+	other.Number = "0987654321"
+	joined, err := JoinByNPI([]Provider{mockProvider(), other}, reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(joined) != 2 {
+		t.Fatalf("expected 2 joined providers, got %d", len(joined))
+	}
+	if len(joined[0].Records) != 2 || joined[0].Records[0].Fields["pri_spec"] != "FAMILY PRACTICE" {
+		t.Errorf("unexpected records for first provider: %+v", joined[0].Records)
+	}
+	if len(joined[1].Records) != 0 {
+		t.Errorf("expected no records for second provider, got %d", len(joined[1].Records))
+	}
+}
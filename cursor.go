@@ -0,0 +1,186 @@
+package gonpi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Page is one page of search results returned by SearchPage.
+type Page struct {
+	// Providers are the results on this page.
+	Providers []Provider `json:"providers"`
+
+	// NextCursor fetches the following page when passed as SearchOptions.Cursor. It is
+	// empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Total is the number of providers returned by this page and the pages before it.
+	// The API does not report how many providers match a query, so Total is only the
+	// full count once NextCursor is empty.
+	Total int `json:"total"`
+}
+
+// pageCursor is the state encoded in a cursor.
+type pageCursor struct {
+	Version int    `json:"v"`
+	Query   string `json:"q"`
+	Skip    int    `json:"s"`
+	Limit   int    `json:"l"`
+
+	// Offset is the number of providers returned before this page.
+	Offset int `json:"o"`
+
+	// Last is the NPI ending the previous page, for checking consistency.
+	Last string `json:"n,omitempty"`
+}
+
+// cursorVersion is the version of the cursor encoding.
+const cursorVersion = 1
+
+// SearchPage returns one page of results for opts, with a cursor for the next, giving
+// REST and GraphQL layers a stateless pagination primitive to hand to their own
+// clients. The first call starts at opts.Skip with pages of opts.Limit results; later
+// calls pass the same options with Cursor set to the previous page's NextCursor, which
+// overrides Skip and Limit. A cursor used with different query options is rejected with
+// a ValidationError.
+//
+// Paging stops at MaxSkip and once SearchOptions.MaxResults providers have been
+// returned. With Consistency set, consecutive pages overlap by one result, as in
+// SearchAll, and a page whose results shifted fails with an error wrapping
+// ErrInconsistentPages, unless the policy is PagesTolerate. Cursors are opaque and
+// may change between releases.
+//
+// Example usage:
+//
+//	opts := gonpi.SearchOptions{LastName: "Smith", State: "MA", Limit: 50, Cursor: r.URL.Query().Get("cursor")}
+//	page, err := client.SearchPage(ctx, opts)
+func (c *Client) SearchPage(ctx context.Context, opts SearchOptions) (Page, error) {
+	ctx, span := c.tracer.Start(ctx, "SearchPage",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.Bool("cursor", opts.Cursor != ""),
+		)...),
+	)
+	defer span.End()
+
+	query := c.queryFingerprint(opts)
+	cur := pageCursor{Version: cursorVersion, Query: query, Skip: opts.Skip, Limit: c.pageSize(opts)}
+	if opts.Cursor != "" {
+		var err error
+		if cur, err = decodeCursor(opts.Cursor, query); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return Page{}, err
+		}
+	}
+
+	req := opts
+	req.Cursor, req.MaxResults = "", 0
+	req.Skip, req.Limit = cur.Skip, cur.Limit
+	if opts.MaxResults > 0 {
+		req.Limit = min(req.Limit, opts.MaxResults-cur.Offset)
+		if req.Limit <= 0 {
+			return Page{Total: cur.Offset}, nil
+		}
+	}
+	anchored := opts.Consistency != PagesUnchecked && cur.Last != "" && req.Skip > 0
+	if anchored {
+		req.Skip--
+		req.Limit++
+	}
+
+	providers, err := c.SearchProviders(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "page request failed")
+		return Page{}, err
+	}
+	full := len(providers) == req.Limit
+
+	if anchored {
+		switch {
+		case len(providers) > 0 && providers[0].Number == cur.Last:
+			providers = providers[1:]
+		case opts.Consistency != PagesTolerate:
+			err := fmt.Errorf("%w: results shifted before skip %d: expected NPI %s", ErrInconsistentPages, cur.Skip, cur.Last)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "inconsistent pages")
+			return Page{}, err
+		default:
+			span.AddEvent("inconsistent_page", trace.WithAttributes(attribute.Int("skip", cur.Skip)))
+			if len(providers) > cur.Limit {
+				providers = providers[:cur.Limit]
+			}
+		}
+	}
+
+	page := Page{Providers: providers, Total: cur.Offset + len(providers)}
+	next := cur
+	next.Skip, next.Offset = cur.Skip+len(providers), page.Total
+	if len(providers) > 0 {
+		next.Last = providers[len(providers)-1].Number
+	}
+	more := full && len(providers) > 0 && next.Skip <= MaxSkip && (opts.MaxResults == 0 || page.Total < opts.MaxResults)
+	if more {
+		page.NextCursor = next.encode()
+	}
+	span.SetAttributes(c.traceAttrs(
+		attribute.Int("skip", cur.Skip),
+		attribute.Int("result_count", len(providers)),
+		attribute.Bool("has_next", more),
+	)...)
+	return page, nil
+}
+
+// pageSize returns the page size for opts: its Limit, or the client default, capped
+// at MaxLimit.
+func (c *Client) pageSize(opts SearchOptions) int {
+	size := opts.Limit
+	if size == 0 {
+		size = c.defaultLimit
+	}
+	if size <= 0 {
+		size = DefaultLimit
+	}
+	return min(size, MaxLimit)
+}
+
+// queryFingerprint identifies the query parameters of opts, ignoring pagination, so a
+// cursor cannot be replayed against a different query.
+func (c *Client) queryFingerprint(opts SearchOptions) string {
+	opts = c.applyDefaults(opts)
+	opts.Skip, opts.Limit, opts.Pretty = 0, 0, false
+	query := strings.TrimPrefix(c.searchURL(opts), c.searchPrefix)
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encode returns the opaque form of the cursor.
+func (p pageCursor) encode() string {
+	data, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses cursor, checking that it belongs to query.
+func decodeCursor(cursor, query string) (pageCursor, error) {
+	var cur pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &cur)
+	}
+	switch {
+	case err != nil || cur.Version != cursorVersion || cur.Skip < 0 || cur.Limit <= 0 || cur.Limit > MaxLimit:
+		return pageCursor{}, &ValidationError{Field: "cursor", Message: "invalid page cursor"}
+	case cur.Query != query:
+		return pageCursor{}, &ValidationError{Field: "cursor", Message: "page cursor belongs to a different query"}
+	}
+	return cur, nil
+}
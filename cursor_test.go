@@ -0,0 +1,138 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestSearchPage tests following cursors through every page.
+func TestSearchPage(t *testing.T) {
+	var requests []string
+	server := newPagingServer(25, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	opts := SearchOptions{LastName: "Smith", Limit: 10}
+
+	var numbers []string
+	var page Page
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not terminate")
+		}
+		var err error
+		page, err = client.SearchPage(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, p := range page.Providers {
+			numbers = append(numbers, p.Number)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	if len(numbers) != 25 || numbers[24] != fmt.Sprintf("%010d", 24) || page.Total != 25 {
+		t.Errorf("expected 25 providers in order, got %d (total %d)", len(numbers), page.Total)
+	}
+	if want := []string{"0/10", "10/10", "20/10"}; fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+// TestSearchPage_MaxResults tests that the cursor stops at MaxResults.
+func TestSearchPage_MaxResults(t *testing.T) {
+	var requests []string
+	server := newPagingServer(100, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	opts := SearchOptions{LastName: "Smith", Limit: 10, MaxResults: 15}
+	first, err := client.SearchPage(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Cursor = first.NextCursor
+	second, err := client.SearchPage(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Providers) != 5 || second.NextCursor != "" || second.Total != 15 {
+		t.Errorf("unexpected last page: %d providers, cursor %q, total %d", len(second.Providers), second.NextCursor, second.Total)
+	}
+}
+
+// TestSearchPage_InvalidCursor tests that malformed cursors and cursors for another
+// query are rejected.
+func TestSearchPage_InvalidCursor(t *testing.T) {
+	var requests []string
+	server := newPagingServer(100, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	page, err := client.SearchPage(context.Background(), SearchOptions{LastName: "Smith", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, opts := range map[string]SearchOptions{
+		"malformed":   {LastName: "Smith", Cursor: "not-a-cursor!"},
+		"other query": {LastName: "Jones", Cursor: page.NextCursor},
+	} {
+		_, err := client.SearchPage(context.Background(), opts)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "cursor" {
+			t.Errorf("%s: expected cursor ValidationError, got %v", name, err)
+		}
+	}
+
+	// Changing Limit alongside a cursor keeps the cursor's page size
+	if _, err := client.SearchPage(context.Background(), SearchOptions{LastName: "Smith", Limit: 50, Cursor: page.NextCursor}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if last := requests[len(requests)-1]; last != "10/10" {
+		t.Errorf("expected cursor page size, got request %s", last)
+	}
+}
+
+// TestSearchPage_Consistency tests that checked cursors detect shifted results.
+func TestSearchPage_Consistency(t *testing.T) {
+	var requests []string
+	paging := newPagingServer(100, &requests)
+	defer paging.Close()
+
+	client := NewClient(WithBaseURL(paging.URL))
+	opts := SearchOptions{LastName: "Smith", Limit: 10, Consistency: PagesFail}
+	first, err := client.SearchPage(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Cursor = first.NextCursor
+	second, err := client.SearchPage(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Providers) != 10 || second.Providers[0].Number != fmt.Sprintf("%010d", 10) {
+		t.Errorf("expected overlap to be dropped, got %d providers starting %s", len(second.Providers), second.Providers[0].Number)
+	}
+	if requests[1] != "9/11" {
+		t.Errorf("expected overlapping request, got %s", requests[1])
+	}
+
+	// A cursor whose anchor no longer matches fails
+	shifting := newShiftingServer(100, func(numbers []string) []string { return numbers[1:] })
+	defer shifting.Close()
+	shifted := NewClient(WithBaseURL(shifting.URL))
+	opts = SearchOptions{LastName: "Smith", Limit: 10, Consistency: PagesFail}
+	if first, err = shifted.SearchPage(context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.Cursor = first.NextCursor
+	if _, err := shifted.SearchPage(context.Background(), opts); !errors.Is(err, ErrInconsistentPages) {
+		t.Errorf("expected ErrInconsistentPages, got %v", err)
+	}
+}
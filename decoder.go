@@ -0,0 +1,45 @@
+package gonpi
+
+import (
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// JSONDecoder decodes the JSON document in data into v, with the semantics of
+// json.Unmarshal. Compatible drop-in libraries such as json-iterator or sonic can be
+// plugged in through it, e.g. jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal.
+type JSONDecoder func(data []byte, v any) error
+
+// WithJSONDecoder decodes API responses with decode instead of encoding/json. The
+// decoder must honor json tags and json.Unmarshaler, which FlexInt relies on.
+// WithStrictDecoding and WithSchemaWarnings depend on encoding/json's handling of
+// unknown fields and type errors, so responses are still decoded with encoding/json
+// when either is set.
+//
+// Example usage:
+//
+//	client := gonpi.NewClient(gonpi.WithJSONDecoder(sonic.Unmarshal))
+func WithJSONDecoder(decode JSONDecoder) ClientOption {
+	return func(c *Client) {
+		c.jsonDecoder = decode
+	}
+}
+
+// decodeWith reads body and decodes it into result with the configured JSONDecoder.
+func (c *Client) decodeWith(body io.Reader, result any, span trace.Span) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read response")
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := c.jsonDecoder(data, result); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode response")
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
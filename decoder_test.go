@@ -0,0 +1,41 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestWithJSONDecoder tests that responses are decoded with the configured decoder,
+// except under strict decoding, and that its errors fail the request.
+func TestWithJSONDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	calls := 0
+	decode := func(data []byte, v any) error {
+		calls++
+		return json.Unmarshal(data, v)
+	}
+	client := NewClient(WithBaseURL(server.URL), WithJSONDecoder(decode))
+	provider, err := client.GetProviderByNPI(context.Background(), "1234567893")
+	if err != nil || provider == nil || calls != 1 {
+		t.Fatalf("GetProviderByNPI = %v, %v with %d decoder calls", provider, err, calls)
+	}
+
+	strict := NewClient(WithBaseURL(server.URL), WithJSONDecoder(decode), WithStrictDecoding())
+	if _, err := strict.GetProviderByNPI(context.Background(), "1234567893"); err != nil || calls != 1 {
+		t.Errorf("strict decoding should use encoding/json: %v, %d decoder calls", err, calls)
+	}
+
+	errDecode := errors.New("decoder failed")
+	failing := NewClient(WithBaseURL(server.URL), WithJSONDecoder(func([]byte, any) error { return errDecode }))
+	if _, err := failing.GetProviderByNPI(context.Background(), "1234567893"); !errors.Is(err, errDecode) {
+		t.Errorf("expected decoder error, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package gonpi
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrCacheDecrypt indicates that a DiskCache entry could not be decrypted, usually
+// because the cache directory was written with a different key.
+var ErrCacheDecrypt = errors.New("cache entry decryption failed")
+
+// diskCacheExt is the file extension of DiskCache entries.
+const diskCacheExt = ".npi"
+
+// DiskCache is a CacheBackend storing one file per provider in a directory, so that
+// cached lookups survive restarts. Entries expire after the TTL they were stored with
+// and, if WithDiskCacheMaxAge is set, after the retention period regardless of TTL.
+// WithDiskCacheEncryption encrypts entries at rest and WithDiskCacheCodec selects a
+// binary encoding.
+type DiskCache struct {
+	dir    string
+	maxAge time.Duration
+	aead   cipher.AEAD
+	codec  Codec
+	now    func() time.Time
+}
+
+// DiskCacheOption configures a DiskCache.
+type DiskCacheOption func(*DiskCache) error
+
+// WithDiskCacheMaxAge sets the retention period: entries stored longer than maxAge ago
+// are treated as expired and deleted, even if their TTL is longer. Zero keeps entries
+// until their TTL expires.
+func WithDiskCacheMaxAge(maxAge time.Duration) DiskCacheOption {
+	return func(d *DiskCache) error {
+		d.maxAge = maxAge
+		return nil
+	}
+}
+
+// WithDiskCacheEncryption encrypts entries with AES-GCM under key, which must be 16,
+// 24 or 32 bytes long for AES-128, AES-192 or AES-256. Each entry is bound to its
+// cache key, so files cannot be swapped between keys undetected.
+func WithDiskCacheEncryption(key []byte) DiskCacheOption {
+	return func(d *DiskCache) error {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return &ValidationError{Field: "key", Message: fmt.Sprintf("invalid encryption key: %v", err)}
+		}
+		d.aead, err = cipher.NewGCM(block)
+		return err
+	}
+}
+
+// WithDiskCacheCodec encodes providers with codec instead of JSON, e.g. MsgPackCodec
+// for smaller files and faster reads. Entries written with another codec remain
+// readable, so the codec can be changed on an existing cache directory.
+func WithDiskCacheCodec(codec Codec) DiskCacheOption {
+	return func(d *DiskCache) error {
+		d.codec = codec
+		return nil
+	}
+}
+
+// NewDiskCache creates a DiskCache in dir, creating the directory if needed.
+//
+// Example usage:
+//
+//	cache, err := NewDiskCache("/var/cache/gonpi",
+//	    WithDiskCacheMaxAge(30*24*time.Hour),
+//	    WithDiskCacheEncryption(key),
+//	)
+//	client := NewClient(WithCache(24*time.Hour), WithCacheBackend(cache))
+func NewDiskCache(dir string, opts ...DiskCacheOption) (*DiskCache, error) {
+	d := &DiskCache{dir: dir, now: time.Now}
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return d, nil
+}
+
+// diskCacheEntry is the stored form of a cached provider.
+type diskCacheEntry struct {
+	StoredAt  time.Time `json:"stored_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Provider  *Provider `json:"provider"`
+}
+
+// Get implements CacheBackend. Expired entries are deleted and reported as misses.
+func (d *DiskCache) Get(_ context.Context, key string) (*Provider, bool, error) {
+	path := d.path(key)
+	entry, err := d.read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if d.expired(entry) {
+		os.Remove(path)
+		return nil, false, nil
+	}
+	return entry.Provider, true, nil
+}
+
+// Set implements CacheBackend, replacing the entry atomically.
+func (d *DiskCache) Set(_ context.Context, key string, provider *Provider, ttl time.Duration) error {
+	now := d.now()
+	data, err := d.encode(diskCacheEntry{StoredAt: now, ExpiresAt: now.Add(ttl), Provider: provider})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	path := d.path(key)
+	if d.aead != nil {
+		nonce := make([]byte, d.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		data = d.aead.Seal(nonce, nonce, data, []byte(filepath.Base(path)))
+	}
+
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// SetMany implements CacheBatchSetter, writing each entry as Set does. It stops at the
+// first error.
+func (d *DiskCache) SetMany(ctx context.Context, entries []CacheEntry, ttl time.Duration) error {
+	for _, entry := range entries {
+		if err := d.Set(ctx, entry.Key, entry.Provider, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete implements CacheDeleter. Deleting a missing entry is not an error.
+func (d *DiskCache) Delete(_ context.Context, key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes every expired entry, and entries that cannot be read, and returns how
+// many were removed. Run it periodically, e.g. with PurgeTask on a Scheduler, so that
+// entries that are never looked up again still leave the disk.
+func (d *DiskCache) Purge(ctx context.Context) (int, error) {
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list cache directory: %w", err)
+	}
+
+	removed := 0
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		name := file.Name()
+		if !strings.HasSuffix(name, diskCacheExt) {
+			continue
+		}
+
+		path := filepath.Join(d.dir, name)
+		entry, err := d.read(path)
+		if err != nil || d.expired(entry) {
+			if os.Remove(path) == nil {
+				removed++
+			}
+		}
+	}
+	return removed, nil
+}
+
+// PurgeTask returns a scheduler Task that purges the cache and passes the number of
+// removed entries to report, if not nil.
+func (d *DiskCache) PurgeTask(report func(removed int)) Task {
+	return func(ctx context.Context) error {
+		removed, err := d.Purge(ctx)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			report(removed)
+		}
+		return nil
+	}
+}
+
+// path returns the file holding key. Keys are hashed so that namespaces and other
+// characters cannot escape the directory.
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+diskCacheExt)
+}
+
+// read loads and decrypts the entry stored at path. Encrypted entries are
+// authenticated against their file name, the hash of their key.
+func (d *DiskCache) read(path string) (diskCacheEntry, error) {
+	var entry diskCacheEntry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	if d.aead != nil {
+		size := d.aead.NonceSize()
+		if len(data) < size {
+			return entry, ErrCacheDecrypt
+		}
+		data, err = d.aead.Open(nil, data[:size], data[size:], []byte(filepath.Base(path)))
+		if err != nil {
+			return entry, ErrCacheDecrypt
+		}
+	}
+	if err := decodeDiskCacheEntry(data, &entry); err != nil {
+		return entry, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return entry, nil
+}
+
+// diskCacheMagic starts entries written with a codec. JSON entries start with '{'.
+const diskCacheMagic = "\x00npi"
+
+// encode serializes entry as JSON or, with a codec, as diskCacheMagic, the length and
+// name of the codec, the store and expiry times in Unix nanoseconds and the encoded
+// provider.
+func (d *DiskCache) encode(entry diskCacheEntry) ([]byte, error) {
+	if d.codec == nil {
+		return json.Marshal(entry)
+	}
+	provider, err := d.codec.Marshal(entry.Provider)
+	if err != nil {
+		return nil, err
+	}
+	name := d.codec.Name()
+	data := make([]byte, 0, len(diskCacheMagic)+1+len(name)+16+len(provider))
+	data = append(data, diskCacheMagic...)
+	data = append(data, byte(len(name)))
+	data = append(data, name...)
+	data = binary.BigEndian.AppendUint64(data, uint64(entry.StoredAt.UnixNano()))
+	data = binary.BigEndian.AppendUint64(data, uint64(entry.ExpiresAt.UnixNano()))
+	return append(data, provider...), nil
+}
+
+// decodeDiskCacheEntry reads an entry written by DiskCache.encode with any codec.
+func decodeDiskCacheEntry(data []byte, entry *diskCacheEntry) error {
+	rest, ok := bytes.CutPrefix(data, []byte(diskCacheMagic))
+	if !ok {
+		return json.Unmarshal(data, entry)
+	}
+	if len(rest) < 1 || len(rest) < 1+int(rest[0])+16 {
+		return errors.New("truncated entry")
+	}
+	codec, err := CodecByName(string(rest[1 : 1+rest[0]]))
+	if err != nil {
+		return err
+	}
+	rest = rest[1+rest[0]:]
+	entry.StoredAt = time.Unix(0, int64(binary.BigEndian.Uint64(rest)))
+	entry.ExpiresAt = time.Unix(0, int64(binary.BigEndian.Uint64(rest[8:])))
+	entry.Provider = &Provider{}
+	return codec.Unmarshal(rest[16:], entry.Provider)
+}
+
+// expired reports whether entry is past its TTL or the retention period.
+func (d *DiskCache) expired(entry diskCacheEntry) bool {
+	now := d.now()
+	return now.After(entry.ExpiresAt) || (d.maxAge > 0 && now.Sub(entry.StoredAt) > d.maxAge)
+}
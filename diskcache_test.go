@@ -0,0 +1,141 @@
+package gonpi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDiskCache tests storing, expiring and purging entries.
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, WithDiskCacheMaxAge(time.Hour))
+	if err != nil {
+		t.Fatalf("NewDiskCache: %v", err)
+	}
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	provider := mockProvider()
+	if err := cache.Set(ctx, "ns:1234567890", &provider, 24*time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := cache.Set(ctx, "ns:short", &provider, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, ok, err := cache.Get(ctx, "ns:1234567890")
+	if err != nil || !ok || got.Number != provider.Number {
+		t.Fatalf("Get = %v, %v, %v", got, ok, err)
+	}
+	if _, ok, _ := cache.Get(ctx, "ns:missing"); ok {
+		t.Error("expected miss for unknown key")
+	}
+
+	// Past the short TTL but within retention
+	now = now.Add(10 * time.Minute)
+	if _, ok, _ := cache.Get(ctx, "ns:short"); ok {
+		t.Error("expected TTL expiry")
+	}
+	if _, ok, _ := cache.Get(ctx, "ns:1234567890"); !ok {
+		t.Error("expected entry within TTL and retention")
+	}
+
+	// Past the retention period, though within the TTL
+	now = now.Add(time.Hour)
+	removed, err := cache.Purge(ctx)
+	if err != nil || removed != 1 {
+		t.Errorf("Purge = %d, %v, want 1 removed", removed, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("expected empty cache directory, got %v", files)
+	}
+}
+
+// TestDiskCache_Encryption tests that entries are encrypted at rest and unreadable
+// with another key.
+func TestDiskCache_Encryption(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{7}, 32)
+	cache, err := NewDiskCache(dir, WithDiskCacheEncryption(key))
+	if err != nil {
+		t.Fatalf("NewDiskCache: %v", err)
+	}
+	ctx := context.Background()
+
+	provider := mockProvider()
+	if err := cache.Set(ctx, "1234567890", &provider, time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	data, _ := os.ReadFile(cache.path("1234567890"))
+	if bytes.Contains(data, []byte(provider.Number)) || bytes.Contains(data, []byte(provider.Basic.LastName)) {
+		t.Error("entry stored in plaintext")
+	}
+	if got, ok, err := cache.Get(ctx, "1234567890"); err != nil || !ok || got.Number != provider.Number {
+		t.Fatalf("Get = %v, %v, %v", got, ok, err)
+	}
+
+	other, _ := NewDiskCache(dir, WithDiskCacheEncryption(bytes.Repeat([]byte{8}, 32)))
+	if _, _, err := other.Get(ctx, "1234567890"); !errors.Is(err, ErrCacheDecrypt) {
+		t.Errorf("expected ErrCacheDecrypt with the wrong key, got %v", err)
+	}
+
+	if _, err := NewDiskCache(dir, WithDiskCacheEncryption([]byte("short"))); !IsValidation(err) {
+		t.Errorf("expected validation error for a short key, got %v", err)
+	}
+}
+
+// TestDiskCache_Client tests a DiskCache as a client cache backend across clients.
+func TestDiskCache_Client(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		cache, err := NewDiskCache(dir)
+		if err != nil {
+			t.Fatalf("NewDiskCache: %v", err)
+		}
+		client := NewClient(WithBaseURL(server.URL), WithCacheBackend(cache))
+		if _, err := client.GetProviderByNPI(ctx, "1234567890"); err != nil {
+			t.Fatalf("lookup: %v", err)
+		}
+		client.Close()
+	}
+	if requests != 1 {
+		t.Errorf("expected the second client to hit the disk cache, got %d requests", requests)
+	}
+}
+
+// TestDiskCache_Delete tests that refreshing removes missing providers from disk.
+func TestDiskCache_Delete(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	p := mockProvider()
+	cache.Set(ctx, "k", &p, time.Hour)
+	if err := cache.Delete(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := cache.Get(ctx, "k"); ok {
+		t.Error("entry still present after Delete")
+	}
+	if err := cache.Delete(ctx, "k"); err != nil {
+		t.Errorf("deleting a missing entry: %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultDNSTTL is how long a DNSCache uses resolved addresses before refreshing them.
+const DefaultDNSTTL = 5 * time.Minute
+
+// DNSOption configures a DNSCache.
+type DNSOption func(*DNSCache)
+
+// WithDNSTTL sets how long resolved addresses are used before they are refreshed.
+// Go's resolver does not report record TTLs, so one TTL applies to every host.
+// Default: 5 minutes.
+func WithDNSTTL(ttl time.Duration) DNSOption {
+	return func(d *DNSCache) {
+		if ttl > 0 {
+			d.ttl = ttl
+		}
+	}
+}
+
+// WithDNSStale sets how long past their TTL addresses may still be used while a
+// refresh is pending or failing. Zero, the default, keeps them until a refresh
+// succeeds, so a resolver outage does not take down connections to a host that was
+// already resolved.
+func WithDNSStale(maxStale time.Duration) DNSOption {
+	return func(d *DNSCache) {
+		d.stale = maxStale
+	}
+}
+
+// WithDNSResolver sets the resolver used to look up hosts. Default: net.DefaultResolver.
+func WithDNSResolver(r *net.Resolver) DNSOption {
+	return func(d *DNSCache) {
+		d.lookup = r.LookupHost
+	}
+}
+
+// WithDNSDialer sets the dialer used to connect to resolved addresses, for its
+// timeout and keep-alive settings.
+func WithDNSDialer(dialer *net.Dialer) DNSOption {
+	return func(d *DNSCache) {
+		d.dialer = dialer
+	}
+}
+
+// DNSCache caches the addresses of the hosts a client connects to, so that new
+// connections in high-QPS deployments do not wait for the resolver. Addresses past
+// their TTL are still used while a single background lookup refreshes them, so
+// resolver latency never reaches requests after the first lookup of a host. Attach it
+// to a client with WithDNSCache.
+type DNSCache struct {
+	ttl    time.Duration
+	stale  time.Duration
+	lookup func(ctx context.Context, host string) ([]string, error)
+	dialer *net.Dialer
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+	stats   DNSStats
+}
+
+// dnsEntry holds the addresses of one host.
+type dnsEntry struct {
+	addrs      []string
+	resolved   time.Time
+	refreshing bool
+
+	// ready is closed once the first lookup of the host completes
+	ready chan struct{}
+	err   error
+}
+
+// DNSStats counts the lookups of a DNSCache.
+type DNSStats struct {
+	// Hits counts lookups answered from the cache, including with stale addresses.
+	Hits int64 `json:"hits"`
+
+	// Misses counts lookups that waited for the resolver.
+	Misses int64 `json:"misses"`
+
+	// Refreshes counts background refreshes, and RefreshErrors those that failed.
+	Refreshes     int64 `json:"refreshes"`
+	RefreshErrors int64 `json:"refresh_errors"`
+}
+
+// NewDNSCache creates an empty DNSCache.
+func NewDNSCache(opts ...DNSOption) *DNSCache {
+	d := &DNSCache{
+		ttl:     DefaultDNSTTL,
+		lookup:  net.DefaultResolver.LookupHost,
+		dialer:  &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		now:     time.Now,
+		entries: make(map[string]*dnsEntry),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// WithDNSCache makes the client resolve hosts through d. It replaces the dialer of
+// the client's *http.Transport, of a clone of http.DefaultTransport if the HTTP client
+// has none; custom RoundTrippers other than *http.Transport are left as they are.
+// Several clients may share d.
+//
+// Example usage:
+//
+//	dns := gonpi.NewDNSCache(gonpi.WithDNSTTL(time.Minute))
+//	client := gonpi.NewClient(gonpi.WithDNSCache(dns))
+func WithDNSCache(d *DNSCache) ClientOption {
+	return func(c *Client) {
+		c.dns = d
+	}
+}
+
+// installDNSCache points the HTTP client's transport at the DNS cache set with
+// WithDNSCache. It runs after the options, so that WithHTTPClient may come in any
+// order, and only once per HTTP client and cache.
+func (c *Client) installDNSCache() {
+	if c.dns == nil || (c.dnsInstalled.cache == c.dns && c.dnsInstalled.client == c.httpClient) {
+		return
+	}
+	var transport *http.Transport
+	switch t := c.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return
+	}
+	transport.DialContext = c.dns.DialContext
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	c.dnsInstalled = dnsInstall{cache: c.dns, client: c.httpClient}
+}
+
+// dnsInstall records the HTTP client a DNS cache was installed into.
+type dnsInstall struct {
+	cache  *DNSCache
+	client *http.Client
+}
+
+// Stats returns the cache's lookup counters.
+func (d *DNSCache) Stats() DNSStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// LookupHost returns the addresses of host, from the cache if possible. Expired
+// addresses are returned while a background lookup refreshes them.
+func (d *DNSCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	entry, ok := d.entries[host]
+	if !ok {
+		entry = &dnsEntry{ready: make(chan struct{})}
+		d.entries[host] = entry
+		d.stats.Misses++
+		d.mu.Unlock()
+		go d.resolve(host, entry)
+	} else {
+		d.mu.Unlock()
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entry.addrs == nil {
+		return nil, entry.err
+	}
+	if ok {
+		d.stats.Hits++
+	}
+	age := d.now().Sub(entry.resolved)
+	if age >= d.ttl && !entry.refreshing {
+		entry.refreshing = true
+		d.stats.Refreshes++
+		go d.refresh(host, entry)
+	}
+	if d.stale > 0 && age >= d.ttl+d.stale {
+		err := entry.err
+		if err == nil {
+			err = errors.New("refresh pending")
+		}
+		return nil, fmt.Errorf("failed to resolve %s: cached addresses expired: %w", host, err)
+	}
+	return entry.addrs, nil
+}
+
+// resolve performs the first lookup of host. Failed lookups are not cached, so the
+// next request tries again.
+func (d *DNSCache) resolve(host string, entry *dnsEntry) {
+	addrs, err := d.lookupHost(host)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		entry.err = fmt.Errorf("failed to resolve %s: %w", host, err)
+		delete(d.entries, host)
+	} else {
+		entry.addrs, entry.resolved = addrs, d.now()
+	}
+	close(entry.ready)
+}
+
+// refresh looks host up again in the background, keeping the previous addresses if
+// the lookup fails.
+func (d *DNSCache) refresh(host string, entry *dnsEntry) {
+	addrs, err := d.lookupHost(host)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entry.refreshing = false
+	if err != nil {
+		entry.err = err
+		d.stats.RefreshErrors++
+		return
+	}
+	entry.addrs, entry.resolved, entry.err = addrs, d.now(), nil
+}
+
+// lookupHost asks the resolver for the addresses of host. Lookups are detached from
+// the request that triggered them, since other requests share the result, and bounded
+// by the dial timeout instead.
+func (d *DNSCache) lookupHost(host string) ([]string, error) {
+	ctx := context.Background()
+	if d.dialer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.dialer.Timeout)
+		defer cancel()
+	}
+	addrs, err := d.lookup(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = errors.New("no addresses")
+	}
+	return addrs, err
+}
+
+// DialContext connects to addr, a host and port, trying each cached address of the
+// host in turn. It has the signature of http.Transport.DialContext.
+func (d *DNSCache) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, addr)
+	}
+	addrs, err := d.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, ip := range addrs {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
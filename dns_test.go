@@ -0,0 +1,165 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers lookups from a table and counts them.
+type fakeResolver struct {
+	mu      sync.Mutex
+	addrs   map[string][]string
+	err     error
+	lookups int
+}
+
+func (r *fakeResolver) lookup(_ context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.addrs[host], nil
+}
+
+func (r *fakeResolver) set(host string, addrs []string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addrs[host], r.err = addrs, err
+}
+
+func (r *fakeResolver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lookups
+}
+
+// newTestDNSCache returns a DNSCache on resolver with a clock the test advances.
+func newTestDNSCache(resolver *fakeResolver, opts ...DNSOption) (*DNSCache, func(time.Duration)) {
+	d := NewDNSCache(opts...)
+	d.lookup = resolver.lookup
+	var mu sync.Mutex
+	now := time.Now()
+	d.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	return d, func(elapsed time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(elapsed)
+	}
+}
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the background refresh")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestDNSCache tests caching, background refresh and keeping addresses when a
+// refresh fails.
+func TestDNSCache(t *testing.T) {
+	resolver := &fakeResolver{addrs: map[string][]string{"npiregistry.test": {"10.0.0.1"}}}
+	d, advance := newTestDNSCache(resolver, WithDNSTTL(time.Minute))
+	ctx := context.Background()
+
+	for range 3 {
+		if addrs, err := d.LookupHost(ctx, "npiregistry.test"); err != nil || addrs[0] != "10.0.0.1" {
+			t.Fatalf("LookupHost = %v, %v", addrs, err)
+		}
+	}
+	if n := resolver.count(); n != 1 {
+		t.Errorf("expected 1 resolver lookup, got %d", n)
+	}
+
+	resolver.set("npiregistry.test", []string{"10.0.0.2"}, nil)
+	advance(2 * time.Minute)
+	if addrs, _ := d.LookupHost(ctx, "npiregistry.test"); addrs[0] != "10.0.0.1" {
+		t.Errorf("expected the expired address while refreshing, got %v", addrs)
+	}
+	waitFor(t, func() bool {
+		addrs, _ := d.LookupHost(ctx, "npiregistry.test")
+		return addrs[0] == "10.0.0.2"
+	})
+
+	resolver.set("npiregistry.test", nil, errors.New("resolver unavailable"))
+	advance(2 * time.Minute)
+	d.LookupHost(ctx, "npiregistry.test")
+	waitFor(t, func() bool { return d.Stats().RefreshErrors == 1 })
+	if addrs, err := d.LookupHost(ctx, "npiregistry.test"); err != nil || addrs[0] != "10.0.0.2" {
+		t.Errorf("expected the last good address after a failed refresh, got %v, %v", addrs, err)
+	}
+
+	if _, err := d.LookupHost(ctx, "unknown.test"); err == nil {
+		t.Error("expected a failed first lookup to fail")
+	}
+	if stats := d.Stats(); stats.Misses != 2 || stats.Refreshes < 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// TestDNSCache_Stale tests that WithDNSStale bounds how long expired addresses are used.
+func TestDNSCache_Stale(t *testing.T) {
+	resolver := &fakeResolver{addrs: map[string][]string{"npiregistry.test": {"10.0.0.1"}}}
+	d, advance := newTestDNSCache(resolver, WithDNSTTL(time.Minute), WithDNSStale(time.Minute))
+	ctx := context.Background()
+	d.LookupHost(ctx, "npiregistry.test")
+
+	resolver.set("npiregistry.test", nil, errors.New("resolver unavailable"))
+	advance(3 * time.Minute)
+	_, err := d.LookupHost(ctx, "npiregistry.test")
+	if err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("expected expired addresses to fail, got %v", err)
+	}
+
+	resolver.set("npiregistry.test", []string{"10.0.0.2"}, nil)
+	waitFor(t, func() bool {
+		addrs, err := d.LookupHost(ctx, "npiregistry.test")
+		return err == nil && addrs[0] == "10.0.0.2"
+	})
+}
+
+// TestWithDNSCache tests that the client dials the API host through the cache.
+func TestWithDNSCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+	port := server.URL[strings.LastIndex(server.URL, ":"):]
+
+	resolver := &fakeResolver{addrs: map[string][]string{"npiregistry.test": {"127.0.0.1"}}}
+	d, _ := newTestDNSCache(resolver)
+	client := NewClient(WithDNSCache(d), WithBaseURL("http://npiregistry.test"+port))
+	defer client.Close()
+	ctx := context.Background()
+
+	if _, err := client.GetProviderByNPI(ctx, "1234567890"); err != nil {
+		t.Fatalf("lookup through the DNS cache: %v", err)
+	}
+	if d.Stats().Misses != 1 {
+		t.Errorf("expected the host to be resolved through the cache: %+v", d.Stats())
+	}
+	if client.httpClient.Transport == http.DefaultTransport {
+		t.Error("expected a transport dialing through the cache")
+	}
+
+	derived := client.With(WithHeader("X-Job", "sync"))
+	if derived.httpClient.Transport != client.httpClient.Transport {
+		t.Error("derived clients should keep sharing the transport")
+	}
+}
@@ -0,0 +1,214 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// EnrollmentStatus describes a provider's Medicare enrollment as published in the CMS
+// Medicare Order and Referring dataset, which lists PECOS-enrolled providers eligible
+// to order and refer.
+type EnrollmentStatus struct {
+	NPI string `json:"npi"`
+
+	// Enrolled reports whether the NPI appears in the dataset.
+	Enrolled bool `json:"enrolled"`
+
+	// Eligibility flags by service type. They are all false when Enrolled is false.
+	PartB   bool `json:"part_b"`
+	DME     bool `json:"dme"`
+	HHA     bool `json:"hha"`
+	PMD     bool `json:"pmd"`
+	Hospice bool `json:"hospice"`
+}
+
+// CanOrderAndRefer reports whether the provider is eligible to order or refer for any service type.
+func (s EnrollmentStatus) CanOrderAndRefer() bool {
+	return s.Enrolled && (s.PartB || s.DME || s.HHA || s.PMD || s.Hospice)
+}
+
+// EnrollmentChecker answers whether an NPI is Medicare-enrolled.
+// Implementations return an EnrollmentStatus with Enrolled false, not an error,
+// when the NPI is not found. They must be safe for concurrent use.
+type EnrollmentChecker interface {
+	CheckEnrollment(ctx context.Context, npi string) (*EnrollmentStatus, error)
+}
+
+// ProviderEnrollment pairs a registry record with its Medicare enrollment status.
+type ProviderEnrollment struct {
+	Provider   *Provider         `json:"provider"`
+	Enrollment *EnrollmentStatus `json:"enrollment"`
+}
+
+// WithEnrollmentChecker sets the EnrollmentChecker used by GetProviderWithEnrollment.
+func WithEnrollmentChecker(checker EnrollmentChecker) ClientOption {
+	return func(c *Client) {
+		c.enrollment = checker
+	}
+}
+
+// GetProviderWithEnrollment retrieves a provider by NPI together with its Medicare
+// enrollment status. If the provider is not found in the registry, nil is returned
+// along with a nil error, as with GetProviderByNPI.
+func (c *Client) GetProviderWithEnrollment(ctx context.Context, npi string) (*ProviderEnrollment, error) {
+	if c.enrollment == nil {
+		return nil, errors.New("no enrollment checker configured")
+	}
+
+	provider, err := c.GetProviderByNPI(ctx, npi)
+	if err != nil || provider == nil {
+		return nil, err
+	}
+
+	status, err := c.enrollment.CheckEnrollment(ctx, provider.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check enrollment for NPI %s: %w", provider.Number, err)
+	}
+
+	return &ProviderEnrollment{Provider: provider, Enrollment: status}, nil
+}
+
+// OrderReferringFile is an in-memory EnrollmentChecker backed by the downloadable CMS
+// Medicare Order and Referring CSV file (NPI, LAST_NAME, FIRST_NAME, PARTB, DME, HHA,
+// PMD, HOSPICE columns).
+type OrderReferringFile struct {
+	mu   sync.RWMutex
+	data map[string]EnrollmentStatus
+}
+
+// LoadOrderReferringFile reads the CMS Medicare Order and Referring CSV file.
+func LoadOrderReferringFile(r io.Reader) (*OrderReferringFile, error) {
+	reader, cols, err := newHeaderReader(r)
+	if err != nil {
+		return nil, err
+	}
+	npiCol := firstColumn(cols, "npi")
+	if npiCol < 0 {
+		return nil, errors.New("missing NPI column")
+	}
+	flagCols := map[string]int{}
+	for _, name := range []string{"partb", "dme", "hha", "pmd", "hospice"} {
+		flagCols[name] = firstColumn(cols, name)
+	}
+
+	file := &OrderReferringFile{data: make(map[string]EnrollmentStatus)}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Rows too short to hold an NPI are truncated lines; skip them
+		if npiCol >= len(row) {
+			continue
+		}
+		flag := func(name string) bool {
+			i := flagCols[name]
+			return i >= 0 && i < len(row) && strings.EqualFold(strings.TrimSpace(row[i]), "Y")
+		}
+		npi := strings.TrimSpace(row[npiCol])
+		file.data[npi] = EnrollmentStatus{
+			NPI:      npi,
+			Enrolled: true,
+			PartB:    flag("partb"),
+			DME:      flag("dme"),
+			HHA:      flag("hha"),
+			PMD:      flag("pmd"),
+			Hospice:  flag("hospice"),
+		}
+	}
+	return file, nil
+}
+
+// Len returns the number of NPIs in the file.
+func (f *OrderReferringFile) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.data)
+}
+
+// CheckEnrollment implements EnrollmentChecker.
+func (f *OrderReferringFile) CheckEnrollment(_ context.Context, npi string) (*EnrollmentStatus, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if status, ok := f.data[npi]; ok {
+		return &status, nil
+	}
+	return &EnrollmentStatus{NPI: npi}, nil
+}
+
+// DataCMSEnrollmentChecker is an EnrollmentChecker that queries the Medicare Order and
+// Referring dataset through the data.cms.gov data API.
+type DataCMSEnrollmentChecker struct {
+	dataURL    string
+	httpClient *http.Client
+}
+
+// NewDataCMSEnrollmentChecker creates a checker for the data.cms.gov dataset data endpoint,
+// for example "https://data.cms.gov/data-api/v1/dataset/<dataset-id>/data".
+// The dataset ID is published on the dataset's API page on data.cms.gov.
+// If httpClient is nil, a client with DefaultTimeout is used.
+func NewDataCMSEnrollmentChecker(dataURL string, httpClient *http.Client) *DataCMSEnrollmentChecker {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &DataCMSEnrollmentChecker{dataURL: dataURL, httpClient: httpClient}
+}
+
+// CheckEnrollment implements EnrollmentChecker.
+func (d *DataCMSEnrollmentChecker) CheckEnrollment(ctx context.Context, npi string) (*EnrollmentStatus, error) {
+	params := url.Values{}
+	params.Set("filter[NPI]", npi)
+	params.Set("size", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.dataURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "gonpi/1.0")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxResponseBodySize))
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("data.cms.gov returned status %d: %s", resp.StatusCode, string(body)),
+		}
+	}
+
+	var rows []map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	status := &EnrollmentStatus{NPI: npi}
+	if len(rows) == 0 {
+		return status, nil
+	}
+	row := rows[0]
+	flag := func(name string) bool {
+		return strings.EqualFold(row[name], "Y")
+	}
+	status.Enrolled = true
+	status.PartB = flag("PARTB")
+	status.DME = flag("DME")
+	status.HHA = flag("HHA")
+	status.PMD = flag("PMD")
+	status.Hospice = flag("HOSPICE")
+	return status, nil
+}
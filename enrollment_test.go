@@ -0,0 +1,127 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestLoadOrderReferringFile tests loading the Order and Referring CSV file.
+func TestLoadOrderReferringFile(t *testing.T) {
+	data := "NPI,LAST_NAME,FIRST_NAME,PARTB,DME,HHA,PMD,HOSPICE\n" +
+		"1234567890,DOE,JOHN,Y,Y,N,N,N\n" +
+		"1111111111,ROE,JANE,N,N,N,N,N\n"
+
+	file, err := LoadOrderReferringFile(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", file.Len())
+	}
+
+	status, _ := file.CheckEnrollment(context.Background(), "1234567890")
+	if !status.Enrolled || !status.PartB || !status.DME || status.HHA {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if !status.CanOrderAndRefer() {
+		t.Error("expected provider to be able to order and refer")
+	}
+
+	status, _ = file.CheckEnrollment(context.Background(), "1111111111")
+	if !status.Enrolled || status.CanOrderAndRefer() {
+		t.Errorf("expected enrolled without eligibility, got %+v", status)
+	}
+
+	status, _ = file.CheckEnrollment(context.Background(), "9999999999")
+	if status.Enrolled {
+		t.Error("expected unknown NPI to be not enrolled")
+	}
+}
+
+// TestLoadOrderReferringFile_ShortRow tests that rows too short to hold an NPI are skipped.
+func TestLoadOrderReferringFile_ShortRow(t *testing.T) {
+	data := "LAST_NAME,FIRST_NAME,NPI,PARTB\nDOE\nROE,JANE,1111111111,Y\n"
+	file, err := LoadOrderReferringFile(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Len() != 1 {
+		t.Errorf("expected 1 entry, got %d", file.Len())
+	}
+}
+
+// TestDataCMSEnrollmentChecker tests querying the data.cms.gov API.
+func TestDataCMSEnrollmentChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rows := []map[string]string{}
+		if r.URL.Query().Get("filter[NPI]") == "1234567890" {
+			rows = append(rows, map[string]string{"NPI": "1234567890", "PARTB": "Y", "HOSPICE": "Y"})
+		}
+		json.NewEncoder(w).Encode(rows)
+	}))
+	defer server.Close()
+
+	checker := NewDataCMSEnrollmentChecker(server.URL+"/data", nil)
+
+	status, err := checker.CheckEnrollment(context.Background(), "1234567890")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !status.Enrolled || !status.PartB || !status.Hospice || status.DME {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	status, err = checker.CheckEnrollment(context.Background(), "9999999999")
+	if err != nil || status.Enrolled {
+		t.Errorf("expected not enrolled, got %+v, %v", status, err)
+	}
+}
+
+// TestGetProviderWithEnrollment tests returning enrollment alongside the registry record.
+func TestGetProviderWithEnrollment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	file, _ := LoadOrderReferringFile(strings.NewReader("NPI,PARTB\n1234567890,Y\n"))
+	client := NewClient(WithBaseURL(server.URL), WithEnrollmentChecker(file))
+
+	result, err := client.GetProviderWithEnrollment(context.Background(), "1234567890")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provider.Number != "1234567890" || !result.Enrollment.PartB {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, err := NewClient().GetProviderWithEnrollment(context.Background(), "1234567890"); err == nil {
+		t.Error("expected error without enrollment checker")
+	}
+}
+
+// TestGetProviderWithEnrollment_FormattedNPI tests that the enrollment lookup uses the
+// normalized NPI rather than the caller's formatting.
+func TestGetProviderWithEnrollment_FormattedNPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider := mockProvider()
+		provider.Number = "1234567893"
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{provider}))
+	}))
+	defer server.Close()
+
+	file, _ := LoadOrderReferringFile(strings.NewReader("NPI,PARTB\n1234567893,Y\n"))
+	client := NewClient(WithBaseURL(server.URL), WithEnrollmentChecker(file))
+
+	result, err := client.GetProviderWithEnrollment(context.Background(), " NPI: 1234-567893")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Enrollment.Enrolled || !result.Enrollment.PartB {
+		t.Errorf("enrollment = %+v, want enrolled in Part B", result.Enrollment)
+	}
+}
@@ -0,0 +1,125 @@
+package gonpi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+)
+
+// ErrNotFound indicates that the requested provider does not exist in the registry.
+var ErrNotFound = errors.New("provider not found")
+
+// ErrSchemaMismatch indicates that an API response did not match the expected schema
+// while strict decoding is enabled.
+var ErrSchemaMismatch = errors.New("response does not match schema")
+
+// ErrClientClosed is returned by API calls made after Client.Close or Client.Shutdown.
+var ErrClientClosed = errors.New("client closed")
+
+// errInvalidURL marks requests that could not be built from the configured base URL.
+var errInvalidURL = errors.New("invalid request URL")
+
+// APIError represents an error from the NPI Registry API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// ValidationError indicates that a request was rejected because its input is invalid.
+// Validation errors are never retried.
+type ValidationError struct {
+	// Field is the name of the offending input, if known.
+	Field string
+
+	// Message describes the problem.
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// validationError returns the errors the registry reported in r as a ValidationError
+// naming the first offending field, or nil if there are none.
+func (r *APIResponse) validationError() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, len(r.Errors))
+	for i, e := range r.Errors {
+		messages[i] = e.Description
+		if e.Field != "" {
+			messages[i] += " (" + e.Field + ")"
+		}
+	}
+	return &ValidationError{
+		Field:   r.Errors[0].Field,
+		Message: "registry rejected query: " + strings.Join(messages, "; "),
+	}
+}
+
+// PanicError records a panic recovered from a background goroutine, such as a batch
+// lookup or a scheduled task, so that it fails that unit of work instead of crashing
+// the process.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the goroutine stack trace at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns Value if it is an error, so that errors.Is and errors.As see
+// through panics raised with an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// safeCall runs fn, converting a panic into a *PanicError.
+func safeCall(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// IsNotFound reports whether err indicates that a provider does not exist,
+// either as ErrNotFound or as an HTTP 404 from the API.
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsRetryable reports whether err is a transient failure (server errors,
+// rate limits, timeouts and network errors) that may succeed if retried.
+func IsRetryable(err error) bool {
+	return err != nil && isRetryable(err)
+}
+
+// IsRateLimited reports whether err is an HTTP 429 response from the API.
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// IsValidation reports whether err is a ValidationError.
+func IsValidation(err error) bool {
+	var validationErr *ValidationError
+	return errors.As(err, &validationErr)
+}
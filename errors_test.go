@@ -0,0 +1,62 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestErrorClassificationHelpers tests the exported error classification helpers.
+func TestErrorClassificationHelpers(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		notFound    bool
+		retryable   bool
+		rateLimited bool
+		validation  bool
+	}{
+		{name: "nil", err: nil},
+		{name: "ErrNotFound", err: fmt.Errorf("lookup: %w", ErrNotFound), notFound: true},
+		{name: "404", err: &APIError{StatusCode: 404}, notFound: true},
+		{name: "429", err: fmt.Errorf("search: %w", &APIError{StatusCode: 429}), retryable: true, rateLimited: true},
+		{name: "500", err: &APIError{StatusCode: 500}, retryable: true},
+		{name: "400", err: &APIError{StatusCode: 400}},
+		{name: "validation", err: fmt.Errorf("wrapped: %w", &ValidationError{Field: "npi", Message: "bad"}), validation: true},
+		{name: "canceled", err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.notFound {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.notFound)
+			}
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+			if got := IsRateLimited(tt.err); got != tt.rateLimited {
+				t.Errorf("IsRateLimited() = %v, want %v", got, tt.rateLimited)
+			}
+			if got := IsValidation(tt.err); got != tt.validation {
+				t.Errorf("IsValidation() = %v, want %v", got, tt.validation)
+			}
+		})
+	}
+}
+
+// TestEmptyInputReturnsValidationError tests that input validation failures are typed.
+func TestEmptyInputReturnsValidationError(t *testing.T) {
+	client := NewClient()
+
+	_, err := client.GetProviderByNPI(context.Background(), "")
+	if !IsValidation(err) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+
+	_, err = client.GetProvidersByNPIs(context.Background(), nil)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "npis" {
+		t.Errorf("expected validation error for npis, got %v", err)
+	}
+}
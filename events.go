@@ -0,0 +1,131 @@
+package gonpi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// EventType identifies the kind of change a ChangeEvent describes.
+type EventType string
+
+// Change event types. The string values are part of the stable JSON encoding.
+const (
+	// EventProviderCreated is emitted the first time a watched NPI is found in the registry.
+	EventProviderCreated EventType = "provider.created"
+
+	// EventProviderUpdated is emitted when a watched provider's record changes.
+	// Changes lists the fields that differ.
+	EventProviderUpdated EventType = "provider.updated"
+
+	// EventProviderDeactivated is emitted when a previously seen provider is no longer
+	// returned by the registry or its status changes away from active.
+	EventProviderDeactivated EventType = "provider.deactivated"
+
+	// EventProviderReactivated is emitted when a deactivated provider becomes active again.
+	EventProviderReactivated EventType = "provider.reactivated"
+)
+
+// EventSchemaVersion is the version of the ChangeEvent encoding. It is incremented
+// only for incompatible changes; new optional fields do not change it.
+const EventSchemaVersion = 1
+
+// ChangeEvent describes a change to a watched provider. It is the payload delivered to
+// every Publisher and is described by EventSchema.
+type ChangeEvent struct {
+	// Version is EventSchemaVersion at the time the event was produced.
+	Version int `json:"version"`
+
+	// Type is the kind of change.
+	Type EventType `json:"type"`
+
+	// NPI identifies the provider.
+	NPI string `json:"npi"`
+
+	// Time is when the change was observed.
+	Time time.Time `json:"time"`
+
+	// Provider is the current record. It is nil for deactivations where the registry
+	// no longer returns the provider.
+	Provider *Provider `json:"provider,omitempty"`
+
+	// Previous is the last record seen before the change, if any.
+	Previous *Provider `json:"previous,omitempty"`
+
+	// Changes lists field-level differences between Previous and Provider.
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is a single field-level difference between two provider records.
+type FieldChange struct {
+	// Field is the dotted JSON path of the field, e.g. "basic.last_updated".
+	// Arrays such as "addresses" are compared as a whole.
+	Field string `json:"field"`
+
+	// Old is the previous JSON value, or nil if the field was absent.
+	Old any `json:"old"`
+
+	// New is the current JSON value, or nil if the field was removed.
+	New any `json:"new"`
+}
+
+// DiffProviders returns the field-level differences between old and new, sorted by
+// field path. Either argument may be nil.
+func DiffProviders(old, new *Provider) []FieldChange {
+	oldFields := flattenJSON(old)
+	newFields := flattenJSON(new)
+
+	var changes []FieldChange
+	for field, oldValue := range oldFields {
+		newValue, ok := newFields[field]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	for field, newValue := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes = append(changes, FieldChange{Field: field, New: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// flattenJSON encodes p and returns its leaf values keyed by dotted path.
+func flattenJSON(p *Provider) map[string]any {
+	fields := make(map[string]any)
+	if p == nil {
+		return fields
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fields
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return fields
+	}
+	flattenInto(fields, "", root)
+	return fields
+}
+
+func flattenInto(fields map[string]any, prefix string, obj map[string]any) {
+	for key, value := range obj {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flattenInto(fields, path, nested)
+			continue
+		}
+		fields[path] = value
+	}
+}
+
+// isActive reports whether the registry lists p as active. The API only returns
+// active providers, so an empty status is treated as active.
+func isActive(p *Provider) bool {
+	return p.Basic.Status == "" || p.Basic.Status == "A"
+}
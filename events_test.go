@@ -0,0 +1,131 @@
+package gonpi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestDiffProviders tests field-level diffs between provider records.
+func TestDiffProviders(t *testing.T) {
+	old := mockProvider()
+	new := mockProvider()
+	new.Basic.LastUpdated = "2024-06-01"
+	new.Basic.Credential = "DO"
+
+	changes := DiffProviders(&old, &new)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Field != "basic.credential" || changes[0].Old != old.Basic.Credential || changes[0].New != "DO" {
+		t.Errorf("unexpected first change: %+v", changes[0])
+	}
+	if changes[1].Field != "basic.last_updated" {
+		t.Errorf("unexpected second change: %+v", changes[1])
+	}
+
+	if changes := DiffProviders(&old, &old); len(changes) != 0 {
+		t.Errorf("identical records should not differ, got %+v", changes)
+	}
+	if changes := DiffProviders(nil, &old); len(changes) == 0 {
+		t.Error("diff against nil should list every field")
+	}
+}
+
+// TestWatcher_DeactivationLifecycle tests deactivated and reactivated events.
+func TestWatcher_DeactivationLifecycle(t *testing.T) {
+	var mu sync.Mutex
+	listed := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var providers []Provider
+		if listed {
+			providers = []Provider{mockProvider()}
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse(providers))
+	}))
+	defer server.Close()
+
+	setListed := func(v bool) {
+		mu.Lock()
+		listed = v
+		mu.Unlock()
+	}
+
+	client := NewClient(WithBaseURL(server.URL))
+	watcher := client.NewWatcher([]string{"1234567890"})
+	ctx := context.Background()
+
+	steps := []struct {
+		listed bool
+		want   EventType
+	}{
+		{true, EventProviderCreated},
+		{false, EventProviderDeactivated},
+		{false, ""},
+		{true, EventProviderReactivated},
+	}
+	for i, step := range steps {
+		setListed(step.listed)
+		events, err := watcher.Poll(ctx)
+		if err != nil {
+			t.Fatalf("poll %d: %v", i, err)
+		}
+		if step.want == "" {
+			if len(events) != 0 {
+				t.Errorf("poll %d: expected no events, got %+v", i, events)
+			}
+			continue
+		}
+		if len(events) != 1 || events[0].Type != step.want {
+			t.Fatalf("poll %d: events = %+v, want %s", i, events, step.want)
+		}
+		if events[0].Version != EventSchemaVersion {
+			t.Errorf("poll %d: version = %d", i, events[0].Version)
+		}
+	}
+}
+
+// TestChangeEvent_JSON tests the stable JSON encoding of change events.
+func TestChangeEvent_JSON(t *testing.T) {
+	event := ChangeEvent{
+		Version: EventSchemaVersion,
+		Type:    EventProviderUpdated,
+		NPI:     "1234567890",
+		Changes: []FieldChange{{Field: "basic.status", Old: "A", New: "D"}},
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var raw map[string]any
+	json.Unmarshal(data, &raw)
+	for _, key := range []string{"version", "type", "npi", "time", "changes"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("missing key %q in %s", key, data)
+		}
+	}
+	if raw["type"] != "provider.updated" {
+		t.Errorf("type = %v", raw["type"])
+	}
+	if _, ok := raw["provider"]; ok {
+		t.Error("nil provider should be omitted")
+	}
+}
+
+// TestEventSchema_UpToDate tests that the embedded event schema matches ChangeEvent.
+// Run "go generate" to refresh it after changing types.
+func TestEventSchema_UpToDate(t *testing.T) {
+	generated, err := GenerateEventSchema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(EventSchema), generated) {
+		t.Error("embedded event schema is stale; run go generate")
+	}
+}
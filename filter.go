@@ -0,0 +1,136 @@
+package gonpi
+
+import (
+	"iter"
+	"time"
+)
+
+// registryDateLayout is the layout of date fields such as Basic.EnumerationDate.
+const registryDateLayout = "2006-01-02"
+
+// ProviderFilter reports whether a provider should be kept. Filters are applied
+// client-side, for criteria the NPI Registry API does not support.
+type ProviderFilter func(Provider) bool
+
+// Filter returns an iterator yielding only the providers from seq that match every
+// filter. Errors from seq are passed through unchanged.
+//
+// Example usage:
+//
+//	recent := Filter(client.SearchAll(ctx, opts), UpdatedSince(lastSync))
+//	for provider, err := range recent {
+//	    ...
+//	}
+func Filter(seq iter.Seq2[Provider, error], filters ...ProviderFilter) iter.Seq2[Provider, error] {
+	return func(yield func(Provider, error) bool) {
+		for provider, err := range seq {
+			if err == nil && !MatchesAll(provider, filters...) {
+				continue
+			}
+			if !yield(provider, err) {
+				return
+			}
+		}
+	}
+}
+
+// FilterProviders returns the providers matching every filter.
+func FilterProviders(providers []Provider, filters ...ProviderFilter) []Provider {
+	var matched []Provider
+	for _, provider := range providers {
+		if MatchesAll(provider, filters...) {
+			matched = append(matched, provider)
+		}
+	}
+	return matched
+}
+
+// MatchesAll reports whether provider matches every filter.
+func MatchesAll(provider Provider, filters ...ProviderFilter) bool {
+	for _, filter := range filters {
+		if !filter(provider) {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdatedSince matches providers whose record was last updated at or after t.
+// Providers without a parseable last-updated date do not match.
+func UpdatedSince(t time.Time) ProviderFilter {
+	return func(p Provider) bool {
+		updated, ok := lastUpdatedTime(p)
+		return ok && !updated.Before(t)
+	}
+}
+
+// EnumeratedBetween matches providers enumerated on or after from and before to.
+// A zero from or to leaves that side of the range open. Providers without a
+// parseable enumeration date do not match.
+func EnumeratedBetween(from, to time.Time) ProviderFilter {
+	return func(p Provider) bool {
+		enumerated, ok := enumerationTime(p)
+		if !ok {
+			return false
+		}
+		return (from.IsZero() || !enumerated.Before(from)) && (to.IsZero() || enumerated.Before(to))
+	}
+}
+
+// HasAnyCredential matches providers holding at least one of the given credentials,
+// compared like Provider.HasCredential, e.g. HasAnyCredential("MD", "DO") keeps
+// physicians. The registry cannot search by credential.
+func HasAnyCredential(codes ...string) ProviderFilter {
+	return func(p Provider) bool {
+		for _, code := range codes {
+			if p.HasCredential(code) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// parseRegistryDate parses a YYYY-MM-DD registry date as midnight UTC.
+func parseRegistryDate(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(registryDateLayout, s)
+	return t, err == nil
+}
+
+// epochTime converts a registry epoch value to a time. The API reports epochs in
+// milliseconds; values small enough to be seconds are accepted too.
+func epochTime(epoch FlexInt) (time.Time, bool) {
+	v := epoch.Int64()
+	switch {
+	case v <= 0:
+		return time.Time{}, false
+	case v < 1e11:
+		return time.Unix(v, 0).UTC(), true
+	default:
+		return time.UnixMilli(v).UTC(), true
+	}
+}
+
+// lastUpdatedTime returns when the provider record was last updated, preferring the
+// basic last_updated date and falling back to last_updated_epoch.
+func lastUpdatedTime(p Provider) (time.Time, bool) {
+	if t, ok := parseRegistryDate(p.Basic.LastUpdated); ok {
+		return t, true
+	}
+	if t, ok := parseRegistryDate(p.LastUpdated); ok {
+		return t, true
+	}
+	return epochTime(p.LastUpdatedEpoch)
+}
+
+// enumerationTime returns when the NPI was enumerated, preferring the basic
+// enumeration_date and falling back to created_epoch.
+func enumerationTime(p Provider) (time.Time, bool) {
+	if t, ok := parseRegistryDate(p.Basic.EnumerationDate); ok {
+		return t, true
+	}
+	return epochTime(p.CreatedEpoch)
+}
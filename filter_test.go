@@ -0,0 +1,108 @@
+package gonpi
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, _ := time.Parse(registryDateLayout, s)
+	return t
+}
+
+// TestUpdatedSince tests filtering by last-updated date.
+func TestUpdatedSince(t *testing.T) {
+	provider := mockProvider()
+	provider.Basic.LastUpdated = "2023-06-01"
+
+	if !UpdatedSince(date("2023-06-01"))(provider) {
+		t.Error("expected provider updated on the boundary to match")
+	}
+	if UpdatedSince(date("2023-06-02"))(provider) {
+		t.Error("expected provider updated before the cutoff not to match")
+	}
+
+	// Falls back to the epoch in milliseconds
+	provider.Basic.LastUpdated = ""
+	provider.LastUpdated = ""
+	provider.LastUpdatedEpoch = FlexInt(date("2024-01-01").UnixMilli())
+	if !UpdatedSince(date("2023-12-31"))(provider) {
+		t.Error("expected epoch fallback to match")
+	}
+
+	provider.LastUpdatedEpoch = 0
+	if UpdatedSince(time.Time{})(provider) {
+		t.Error("expected provider without dates not to match")
+	}
+}
+
+// TestHasAnyCredential tests filtering by credential.
+func TestHasAnyCredential(t *testing.T) {
+	provider := mockProvider()
+	provider.Basic.Credential = "D.O."
+
+	if !HasAnyCredential("MD", "do")(provider) {
+		t.Error("expected a DO to match MD or DO")
+	}
+	if HasAnyCredential("NP", "PA-C")(provider) {
+		t.Error("expected a DO not to match NP or PA-C")
+	}
+	if HasAnyCredential()(provider) {
+		t.Error("expected no credentials to match nothing")
+	}
+}
+
+// TestEnumeratedBetween tests filtering by enumeration date range.
+func TestEnumeratedBetween(t *testing.T) {
+	provider := mockProvider() // enumerated 2010-05-15
+
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"2010-01-01", "2011-01-01", true},
+		{"2010-05-15", "2010-05-16", true},
+		{"2010-05-16", "", false},
+		{"", "2010-05-15", false},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		if got := EnumeratedBetween(date(tt.from), date(tt.to))(provider); got != tt.want {
+			t.Errorf("EnumeratedBetween(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// TestFilter tests filtering an iterator and a slice.
+func TestFilter(t *testing.T) {
+	old, recent := mockProvider(), mockProvider()
+	old.Basic.LastUpdated = "2015-01-01"
+	recent.Basic.LastUpdated = "2024-01-01"
+	recent.Number = "2222222222"
+	boom := errors.New("boom")
+
+	seq := func(yield func(Provider, error) bool) {
+		_ = yield(old, nil) && yield(recent, nil) && yield(Provider{}, boom)
+	}
+
+	var got []string
+	var gotErr error
+	for provider, err := range Filter(seq, UpdatedSince(date("2020-01-01"))) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, provider.Number)
+	}
+	if len(got) != 1 || got[0] != "2222222222" {
+		t.Errorf("unexpected filtered providers: %v", got)
+	}
+	if gotErr != boom {
+		t.Errorf("expected error to pass through, got %v", gotErr)
+	}
+
+	if n := len(FilterProviders([]Provider{old, recent}, UpdatedSince(date("2020-01-01")))); n != 1 {
+		t.Errorf("expected 1 provider, got %d", n)
+	}
+}
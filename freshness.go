@@ -0,0 +1,117 @@
+package gonpi
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Source identifies where the client got a provider record from.
+type Source string
+
+// Retrieval sources.
+const (
+	// SourceAPI is a record fetched from the NPI Registry API by this call.
+	SourceAPI Source = "api"
+
+	// SourceCache is a record served from the in-memory cache or a cache backend.
+	SourceCache Source = "cache"
+
+	// SourceStore is a record served from the store set with WithStore.
+	SourceStore Source = "store"
+)
+
+// RetrievalMeta describes how and when the client retrieved a provider record, so
+// callers can decide whether it is fresh enough for their use.
+type RetrievalMeta struct {
+	// Source is where the record came from.
+	Source Source
+
+	// FetchedAt is when the record was fetched from the API. It is zero when unknown:
+	// for records from the store and from a CacheBackend.
+	FetchedAt time.Time
+
+	// Partial is set for records decoded with WithProjection, which lack the fields
+	// that were not projected.
+	Partial bool
+}
+
+// Age returns how long ago the record was fetched from the API. It returns false if
+// FetchedAt is unknown.
+func (m RetrievalMeta) Age() (time.Duration, bool) {
+	if m.FetchedAt.IsZero() {
+		return 0, false
+	}
+	return time.Since(m.FetchedAt), true
+}
+
+// Retrievals records how the client retrieved the providers returned by calls made
+// with WithRetrievals, keyed by NPI. The metadata is kept here rather than on
+// Provider so that records stay plain values: providers fetched at different times
+// compare equal with reflect.DeepEqual when their fields match. A Retrievals is safe
+// for concurrent use, such as by the lookups of GetProvidersByNPIs.
+//
+// Example usage:
+//
+//	retrievals := gonpi.NewRetrievals()
+//	provider, err := client.GetProviderByNPI(gonpi.ContextWithCallOptions(ctx, gonpi.WithRetrievals(retrievals)), npi)
+//	if age, ok := retrievals.Meta(npi).Age(); !ok || age > time.Hour {
+//	    ctx = gonpi.ContextWithCallOptions(ctx, gonpi.WithCacheRefresh())
+//	    provider, err = client.GetProviderByNPI(ctx, npi)
+//	}
+type Retrievals struct {
+	mu   sync.Mutex
+	meta map[string]RetrievalMeta
+}
+
+// NewRetrievals creates an empty Retrievals.
+func NewRetrievals() *Retrievals {
+	return &Retrievals{meta: make(map[string]RetrievalMeta)}
+}
+
+// WithRetrievals makes lookups record in r how each provider they return was
+// retrieved.
+func WithRetrievals(r *Retrievals) CallOption {
+	return func(o *callOptions) {
+		o.retrievals = r
+	}
+}
+
+// Meta returns how the provider with the given NPI was last retrieved, or the zero
+// RetrievalMeta if no call recorded it.
+func (r *Retrievals) Meta(npi string) RetrievalMeta {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.meta[npi]
+}
+
+// Len returns the number of providers recorded.
+func (r *Retrievals) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.meta)
+}
+
+// record notes that providers were retrieved as meta describes. It does nothing on a
+// nil Retrievals.
+func (r *Retrievals) record(meta RetrievalMeta, providers ...Provider) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, provider := range providers {
+		r.meta[provider.Number] = meta
+	}
+}
+
+// recordRetrievals notes how providers were retrieved in the Retrievals of ctx, if any.
+func recordRetrievals(ctx context.Context, meta RetrievalMeta, providers ...Provider) {
+	callOptionsFromContext(ctx).retrievals.record(meta, providers...)
+}
+
+// sameRecord reports whether a and b hold the same record.
+func sameRecord(a, b *Provider) bool {
+	return reflect.DeepEqual(a, b)
+}
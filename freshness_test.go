@@ -0,0 +1,89 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRetrievals tests the source and fetch time of API, cache and store records.
+func TestRetrievals(t *testing.T) {
+	server := newEchoServer(t)
+	store := NewMemoryStore()
+	client := NewClient(WithBaseURL(server.URL), WithCache(time.Hour), WithStore(store), WithStoreHydration())
+	defer client.Close()
+	retrievals := NewRetrievals()
+	ctx := ContextWithCallOptions(context.Background(), WithRetrievals(retrievals))
+
+	before := time.Now()
+	fetched, err := client.GetProviderByNPI(ctx, batchNPIs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := retrievals.Meta(batchNPIs[0])
+	if meta.Source != SourceAPI || meta.FetchedAt.Before(before) || meta.FetchedAt.After(time.Now()) {
+		t.Errorf("API meta = %+v", meta)
+	}
+
+	cached, err := client.GetProviderByNPI(ctx, batchNPIs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := retrievals.Meta(batchNPIs[0])
+	if got.Source != SourceCache || got.FetchedAt.Before(meta.FetchedAt) || got.FetchedAt.After(time.Now()) {
+		t.Errorf("cache meta = %+v, want fetched after %v", got, meta.FetchedAt)
+	}
+	if age, ok := got.Age(); !ok || age < 0 || age > time.Minute {
+		t.Errorf("Age() = %v, %v", age, ok)
+	}
+	if !reflect.DeepEqual(fetched, cached) {
+		t.Error("cached record differs from the fetched one")
+	}
+
+	local := mockProvider()
+	local.Addresses[0].TelephoneNumber = "617-555-0100"
+	store.Put(ctx, local)
+	stored, err := client.FindByPhone(ctx, "(617) 555-0100")
+	if err != nil || len(stored) == 0 {
+		t.Fatalf("FindByPhone() = %v, %v", stored, err)
+	}
+	if got := retrievals.Meta(stored[0].Number); got.Source != SourceStore {
+		t.Errorf("store meta = %+v", got)
+	}
+
+	if _, ok := retrievals.Meta("0000000000").Age(); ok {
+		t.Error("unknown NPI reported an age")
+	}
+	if _, err := client.GetProviderByNPI(context.Background(), batchNPIs[1]); err != nil || retrievals.Len() != 2 {
+		t.Errorf("calls without WithRetrievals were recorded: %d entries", retrievals.Len())
+	}
+}
+
+// TestRetrievals_Backend tests records served from a cache backend.
+func TestRetrievals_Backend(t *testing.T) {
+	server := newEchoServer(t)
+	backend := newMapCacheBackend()
+	client := NewClient(WithBaseURL(server.URL), WithCacheBackend(backend))
+	defer client.Close()
+	retrievals := NewRetrievals()
+	ctx := ContextWithCallOptions(context.Background(), WithRetrievals(retrievals))
+
+	fetched, _ := client.GetProviderByNPI(ctx, batchNPIs[0])
+	var decoded Provider
+	data, _ := json.Marshal(fetched)
+	json.Unmarshal(data, &decoded)
+	backend.entries[batchNPIs[0]] = &decoded
+
+	cached, err := client.GetProviderByNPI(ctx, batchNPIs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := retrievals.Meta(batchNPIs[0]); got.Source != SourceCache || !got.FetchedAt.IsZero() {
+		t.Errorf("backend meta = %+v", got)
+	}
+	if !reflect.DeepEqual(fetched, cached) {
+		t.Error("record decoded by the backend differs from the fetched one")
+	}
+}
@@ -0,0 +1,306 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Geo sources recorded on GeoLocation.
+const (
+	// GeoSourceGeocoder marks coordinates returned by the configured Geocoder.
+	GeoSourceGeocoder = "geocoder"
+
+	// GeoSourceZIPCentroid marks coordinates approximated by the ZIP code centroid.
+	GeoSourceZIPCentroid = "zip_centroid"
+
+	// GeoSourceZIPAreaCentroid marks coordinates approximated by the centroid of the
+	// three-digit ZIP area, for ZIP codes missing from the centroid table.
+	GeoSourceZIPAreaCentroid = "zip3_centroid"
+)
+
+// Coordinates is a WGS84 latitude/longitude pair.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geocoder resolves an address to coordinates.
+// Geocode returns false with a nil error when the address cannot be resolved.
+// Implementations must be safe for concurrent use.
+type Geocoder interface {
+	Geocode(ctx context.Context, addr Address) (Coordinates, bool, error)
+}
+
+// GeocoderFunc adapts a function to the Geocoder interface.
+type GeocoderFunc func(ctx context.Context, addr Address) (Coordinates, bool, error)
+
+// Geocode implements Geocoder.
+func (f GeocoderFunc) Geocode(ctx context.Context, addr Address) (Coordinates, bool, error) {
+	return f(ctx, addr)
+}
+
+// GeoLocation associates a practice location with its coordinates.
+type GeoLocation struct {
+	Address1    string      `json:"address_1"`
+	City        string      `json:"city"`
+	State       string      `json:"state"`
+	PostalCode  string      `json:"postal_code"`
+	Coordinates Coordinates `json:"coordinates"`
+	// Source is GeoSourceGeocoder, GeoSourceZIPCentroid or GeoSourceZIPAreaCentroid.
+	Source string `json:"source"`
+}
+
+// ZIPCentroids is an in-memory table of 5-digit ZIP code centroids.
+// It implements Geocoder by looking up the address postal code and is used as
+// the fallback when the primary Geocoder cannot resolve an address.
+// It is safe for concurrent use.
+type ZIPCentroids struct {
+	mu   sync.RWMutex
+	data map[string]Coordinates
+
+	// areas sums the centroids of each three-digit ZIP area for LookupArea.
+	areas map[string]areaSum
+}
+
+// areaSum accumulates the centroids of the ZIP codes in one three-digit area.
+type areaSum struct {
+	latitude, longitude float64
+	count               int
+}
+
+// NewZIPCentroids creates an empty ZIP centroid table.
+func NewZIPCentroids() *ZIPCentroids {
+	return &ZIPCentroids{data: make(map[string]Coordinates), areas: make(map[string]areaSum)}
+}
+
+// Set stores the centroid for a ZIP code. Only the first five digits of zip are used.
+func (z *ZIPCentroids) Set(zip string, coords Coordinates) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.set(zip5(zip), coords)
+}
+
+// set stores the centroid for a 5-digit ZIP code. The caller must hold z.mu or own z.
+func (z *ZIPCentroids) set(zip string, coords Coordinates) {
+	if key := zipArea(zip); key != "" {
+		area := z.areas[key]
+		if old, ok := z.data[zip]; ok {
+			area.latitude -= old.Latitude
+			area.longitude -= old.Longitude
+			area.count--
+		}
+		area.latitude += coords.Latitude
+		area.longitude += coords.Longitude
+		area.count++
+		z.areas[key] = area
+	}
+	z.data[zip] = coords
+}
+
+// LookupArea returns the mean centroid of the table's ZIP codes sharing zip's first
+// three digits, its sectional center area, as a coarser fallback for ZIP codes the
+// table does not list, such as newly created or PO box-only codes.
+func (z *ZIPCentroids) LookupArea(zip string) (Coordinates, bool) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	key := zipArea(zip5(zip))
+	if key == "" {
+		return Coordinates{}, false
+	}
+	area, ok := z.areas[key]
+	if !ok || area.count == 0 {
+		return Coordinates{}, false
+	}
+	n := float64(area.count)
+	return Coordinates{Latitude: area.latitude / n, Longitude: area.longitude / n}, true
+}
+
+// zipArea returns the three-digit area of a 5-digit ZIP code, or "" if it is shorter.
+func zipArea(zip string) string {
+	if len(zip) < 3 {
+		return ""
+	}
+	return zip[:3]
+}
+
+// Lookup returns the centroid for a ZIP code.
+func (z *ZIPCentroids) Lookup(zip string) (Coordinates, bool) {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	coords, ok := z.data[zip5(zip)]
+	return coords, ok
+}
+
+// Len returns the number of ZIP codes in the table.
+func (z *ZIPCentroids) Len() int {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return len(z.data)
+}
+
+// Geocode implements Geocoder using the address postal code.
+func (z *ZIPCentroids) Geocode(_ context.Context, addr Address) (Coordinates, bool, error) {
+	coords, ok := z.Lookup(addr.PostalCode)
+	return coords, ok, nil
+}
+
+// LoadZIPCentroids reads ZIP centroids from delimited text with a header row, such as the
+// Census Bureau ZCTA Gazetteer file (tab-separated GEOID, INTPTLAT and INTPTLONG columns)
+// or a CSV with zip, lat/latitude and lon/lng/longitude columns.
+// The delimiter is detected from the header row.
+func LoadZIPCentroids(r io.Reader) (*ZIPCentroids, error) {
+	reader, cols, err := newHeaderReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zipCol := firstColumn(cols, "geoid", "zip", "zipcode", "zip_code", "zcta")
+	latCol := firstColumn(cols, "intptlat", "lat", "latitude")
+	lonCol := firstColumn(cols, "intptlong", "lon", "lng", "longitude")
+	if zipCol < 0 || latCol < 0 || lonCol < 0 {
+		return nil, errors.New("missing zip, latitude or longitude column")
+	}
+
+	table := NewZIPCentroids()
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRowLength(reader, row, zipCol, latCol, lonCol); err != nil {
+			return nil, err
+		}
+		zip := strings.TrimSpace(row[zipCol])
+		lat, err := strconv.ParseFloat(strings.TrimSpace(row[latCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latitude for %s: %w", zip, err)
+		}
+		lon, err := strconv.ParseFloat(strings.TrimSpace(row[lonCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid longitude for %s: %w", zip, err)
+		}
+		table.set(zip5(zip), Coordinates{Latitude: lat, Longitude: lon})
+	}
+	return table, nil
+}
+
+// zip5 returns the 5-digit prefix of a ZIP or ZIP+4 code.
+func zip5(zip string) string {
+	zip = strings.TrimSpace(zip)
+	if len(zip) > 5 {
+		return zip[:5]
+	}
+	return zip
+}
+
+// WithGeocoder sets the Geocoder used by EnrichGeo.
+func WithGeocoder(geocoder Geocoder) ClientOption {
+	return func(c *Client) {
+		c.geocoder = geocoder
+	}
+}
+
+// WithZIPCentroids sets the ZIP centroid table EnrichGeo falls back to when the
+// Geocoder cannot resolve an address, or when no Geocoder is configured. ZIP codes
+// missing from the table fall back to their three-digit area (see LookupArea).
+func WithZIPCentroids(table *ZIPCentroids) ClientOption {
+	return func(c *Client) {
+		c.zipCentroids = table
+	}
+}
+
+// EnrichGeo attaches coordinates to the provider's practice locations: LOCATION
+// addresses and additional practice locations. Each location is resolved with the
+// configured Geocoder, falling back to the ZIP centroid table. Locations that cannot be
+// resolved are skipped. Results replace provider.Extensions.Geo.
+//
+// An error is returned if neither a Geocoder nor a ZIP centroid table is configured,
+// or if the Geocoder fails.
+func (c *Client) EnrichGeo(ctx context.Context, provider *Provider) error {
+	if provider == nil {
+		return &ValidationError{Field: "provider", Message: "provider cannot be nil"}
+	}
+	if c.geocoder == nil && c.zipCentroids == nil {
+		return errors.New("no geocoder or ZIP centroid table configured")
+	}
+
+	var geo []GeoLocation
+	for _, addr := range practiceAddresses(provider) {
+		coords, source, err := c.geocode(ctx, addr)
+		if err != nil {
+			return fmt.Errorf("failed to geocode %s: %w", addr.Address1, err)
+		}
+		if source == "" {
+			continue
+		}
+		geo = append(geo, GeoLocation{
+			Address1:    addr.Address1,
+			City:        addr.City,
+			State:       addr.State,
+			PostalCode:  addr.PostalCode,
+			Coordinates: coords,
+			Source:      source,
+		})
+	}
+
+	if provider.Extensions == nil {
+		provider.Extensions = &Extensions{}
+	}
+	provider.Extensions.Geo = geo
+	return nil
+}
+
+// geocode resolves addr, returning the source of the coordinates or "" if unresolved.
+func (c *Client) geocode(ctx context.Context, addr Address) (Coordinates, string, error) {
+	if c.geocoder != nil {
+		coords, ok, err := c.geocoder.Geocode(ctx, addr)
+		if err != nil {
+			return Coordinates{}, "", err
+		}
+		if ok {
+			return coords, GeoSourceGeocoder, nil
+		}
+	}
+	if c.zipCentroids != nil {
+		if coords, ok := c.zipCentroids.Lookup(addr.PostalCode); ok {
+			return coords, GeoSourceZIPCentroid, nil
+		}
+		if coords, ok := c.zipCentroids.LookupArea(addr.PostalCode); ok {
+			return coords, GeoSourceZIPAreaCentroid, nil
+		}
+	}
+	return Coordinates{}, "", nil
+}
+
+// practiceAddresses returns the provider's LOCATION addresses followed by its
+// additional practice locations, converted to Address.
+func practiceAddresses(provider *Provider) []Address {
+	var addrs []Address
+	for _, addr := range provider.Addresses {
+		if addr.AddressPurpose == "LOCATION" {
+			addrs = append(addrs, addr)
+		}
+	}
+	for _, loc := range provider.PracticeLocations {
+		addrs = append(addrs, Address{
+			CountryCode:     loc.CountryCode,
+			CountryName:     loc.CountryName,
+			AddressPurpose:  "LOCATION",
+			Address1:        loc.Address1,
+			Address2:        loc.Address2,
+			City:            loc.City,
+			State:           loc.State,
+			PostalCode:      loc.PostalCode,
+			TelephoneNumber: loc.TelephoneNumber,
+			FaxNumber:       loc.FaxNumber,
+		})
+	}
+	return addrs
+}
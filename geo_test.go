@@ -0,0 +1,135 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestLoadZIPCentroids tests loading Gazetteer and CSV centroid files.
+func TestLoadZIPCentroids(t *testing.T) {
+	t.Run("gazetteer", func(t *testing.T) {
+		data := "GEOID\tALAND\tAWATER\tALAND_SQMI\tAWATER_SQMI\tINTPTLAT\tINTPTLONG\n" +
+			"12345\t1\t0\t1\t0\t34.100000\t-118.200000\n"
+		table, err := LoadZIPCentroids(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		coords, ok := table.Lookup("12345-6789")
+		if !ok || coords.Latitude != 34.1 || coords.Longitude != -118.2 {
+			t.Errorf("unexpected lookup result: %v %v", coords, ok)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		data := "zip,lat,lng\n90210,34.09,-118.41\n10001,40.75,-73.99\n"
+		table, err := LoadZIPCentroids(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if table.Len() != 2 {
+			t.Errorf("expected 2 entries, got %d", table.Len())
+		}
+	})
+
+	t.Run("missing columns", func(t *testing.T) {
+		if _, err := LoadZIPCentroids(strings.NewReader("zip,name\n12345,x\n")); err == nil {
+			t.Error("expected error for missing coordinate columns")
+		}
+	})
+
+	t.Run("short row", func(t *testing.T) {
+		if _, err := LoadZIPCentroids(strings.NewReader("zip,lat,lng\n90210,34.09\n")); err == nil {
+			t.Error("expected error for a row missing its longitude")
+		}
+	})
+}
+
+// TestZIPCentroids_LookupArea tests the three-digit ZIP area fallback.
+func TestZIPCentroids_LookupArea(t *testing.T) {
+	centroids := NewZIPCentroids()
+	centroids.Set("54321", Coordinates{Latitude: 1, Longitude: 2})
+	centroids.Set("54399", Coordinates{Latitude: 9, Longitude: 9})
+	centroids.Set("54399", Coordinates{Latitude: 3, Longitude: 4})
+
+	coords, ok := centroids.LookupArea("543000000")
+	if !ok || coords.Latitude != 2 || coords.Longitude != 3 {
+		t.Errorf("unexpected area centroid: %v %v", coords, ok)
+	}
+	if _, ok := centroids.LookupArea("99999"); ok {
+		t.Error("expected no centroid for an unloaded area")
+	}
+
+	// ZIP codes too short to have an area are not pooled together
+	centroids.Set("5", Coordinates{Latitude: 5, Longitude: 5})
+	centroids.Set("12", Coordinates{Latitude: 7, Longitude: 7})
+	for _, zip := range []string{"", "5", "12"} {
+		if coords, ok := centroids.LookupArea(zip); ok {
+			t.Errorf("LookupArea(%q) = %v, want no centroid", zip, coords)
+		}
+	}
+	if coords, ok := centroids.Lookup("12"); !ok || coords.Latitude != 7 {
+		t.Errorf("Lookup(12) = %v %v", coords, ok)
+	}
+}
+
+// TestEnrichGeo tests geocoding with ZIP centroid fallback.
+func TestEnrichGeo(t *testing.T) {
+	provider := mockProvider()
+	provider.PracticeLocations = []PracticeLocation{
+		{Address1: "9 SIDE ST", City: "OTHERTOWN", State: "CA", PostalCode: "543210000"},
+		{Address1: "1 NOWHERE RD", PostalCode: "00000"},
+		{Address1: "5 NEW ST", PostalCode: "54309"},
+	}
+	provider.Addresses = append(provider.Addresses, Address{AddressPurpose: "MAILING", PostalCode: "12345"})
+
+	centroids := NewZIPCentroids()
+	centroids.Set("54321", Coordinates{Latitude: 1, Longitude: 2})
+
+	geocoder := GeocoderFunc(func(ctx context.Context, addr Address) (Coordinates, bool, error) {
+		if addr.Address1 == "123 MAIN ST" {
+			return Coordinates{Latitude: 10, Longitude: 20}, true, nil
+		}
+		return Coordinates{}, false, nil
+	})
+
+	client := NewClient(WithGeocoder(geocoder), WithZIPCentroids(centroids))
+	if err := client.EnrichGeo(context.Background(), &provider); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	geo := provider.Extensions.Geo
+	if len(geo) != 3 {
+		t.Fatalf("expected 3 geo locations, got %d: %+v", len(geo), geo)
+	}
+	if geo[0].Source != GeoSourceGeocoder || geo[0].Coordinates.Latitude != 10 {
+		t.Errorf("unexpected first location: %+v", geo[0])
+	}
+	if geo[1].Source != GeoSourceZIPCentroid || geo[1].Coordinates.Longitude != 2 {
+		t.Errorf("unexpected second location: %+v", geo[1])
+	}
+	if geo[2].Source != GeoSourceZIPAreaCentroid || geo[2].Coordinates.Latitude != 1 {
+		t.Errorf("unexpected third location: %+v", geo[2])
+	}
+}
+
+// TestEnrichGeo_Errors tests configuration and geocoder errors.
+func TestEnrichGeo_Errors(t *testing.T) {
+	provider := mockProvider()
+
+	if err := NewClient().EnrichGeo(context.Background(), &provider); err == nil {
+		t.Error("expected error when no geocoder is configured")
+	}
+
+	failing := GeocoderFunc(func(ctx context.Context, addr Address) (Coordinates, bool, error) {
+		return Coordinates{}, false, errors.New("quota exceeded")
+	})
+	if err := NewClient(WithGeocoder(failing)).EnrichGeo(context.Background(), &provider); err == nil {
+		t.Error("expected geocoder error to be returned")
+	}
+
+	if err := NewClient(WithGeocoder(failing)).EnrichGeo(context.Background(), nil); !IsValidation(err) {
+		t.Errorf("expected validation error for nil provider, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// Group runs many calls against a client, such as lookups and searches mixed
+// together, with shared cancellation, bounded concurrency and collected results. It
+// takes the BatchOptions of GetProvidersByNPIs: calls run five at a time by default,
+// and every call is attempted regardless of failures unless WithBatchFailFast or
+// WithBatchMaxFailures is given.
+//
+// A Group is created by Client.Group and must not be reused after Wait.
+//
+// Example usage:
+//
+//	g := client.Group(ctx, gonpi.WithBatchConcurrency(8), gonpi.WithBatchFailFast())
+//	provider := g.Lookup("1234567893")
+//	nearby := g.Search(gonpi.SearchOptions{PostalCode: "02139", TaxonomyDescription: "Pharmacy"})
+//	if err := g.Wait(); err != nil {
+//	    return err
+//	}
+//	p, _ := provider.Get()
+//	pharmacies, _ := nearby.Get()
+type Group struct {
+	client *Client
+	config batchConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	semaphore chan struct{}
+	wg        sync.WaitGroup
+	failures  atomic.Int64
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// Group returns a Group running calls against c. Its calls share a context derived
+// from ctx, which is cancelled when the failure budget is exhausted and once Wait
+// returns.
+func (c *Client) Group(ctx context.Context, opts ...BatchOption) *Group {
+	config := newBatchConfig(opts)
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{
+		client:    c,
+		config:    config,
+		ctx:       ctx,
+		cancel:    cancel,
+		semaphore: make(chan struct{}, config.concurrency),
+	}
+}
+
+// Go schedules fn. Its error is collected by Wait, and counts against the failure
+// budget. If the budget is exhausted before fn starts, fn is not called.
+func (g *Group) Go(fn func(ctx context.Context, client *Client) error) {
+	g.run(fn)
+}
+
+// Lookup schedules GetProviderByNPI for npi.
+func (g *Group) Lookup(npi string) *Future[*Provider] {
+	return GoResult(g, func(ctx context.Context, c *Client) (*Provider, error) {
+		return c.GetProviderByNPI(ctx, npi)
+	})
+}
+
+// Search schedules SearchProviders for opts.
+func (g *Group) Search(opts SearchOptions) *Future[[]Provider] {
+	return GoResult(g, func(ctx context.Context, c *Client) ([]Provider, error) {
+		return c.SearchProviders(ctx, opts)
+	})
+}
+
+// Wait waits for every scheduled call and returns their errors joined, with
+// ErrBatchAborted if calls were skipped because the failure budget was exhausted.
+// It then cancels the group's context.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	errs := g.errs
+	if g.config.aborted(&g.failures) {
+		errs = append(errs[:len(errs):len(errs)], ErrBatchAborted)
+	}
+	return errors.Join(errs...)
+}
+
+// run schedules fn and returns a channel closed once it has finished, with the error
+// it failed with, or ErrBatchAborted if it was skipped.
+func (g *Group) run(fn func(ctx context.Context, client *Client) error) (done <-chan struct{}, result *error) {
+	ch := make(chan struct{})
+	result = new(error)
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer close(ch)
+
+		g.semaphore <- struct{}{}
+		defer func() { <-g.semaphore }()
+
+		if g.config.aborted(&g.failures) {
+			*result = ErrBatchAborted
+			return
+		}
+		err := safeCall(func() error { return fn(g.ctx, g.client) })
+		if err == nil {
+			return
+		}
+		*result = err
+		if g.config.aborted(&g.failures) {
+			// Calls cancelled by the abort are not failures of their own
+			return
+		}
+		g.mu.Lock()
+		g.errs = append(g.errs, err)
+		g.mu.Unlock()
+		if g.config.exhausted(g.failures.Add(1)) {
+			g.cancel()
+		}
+	}()
+	return ch, result
+}
+
+// Future is the pending result of a call scheduled on a Group.
+type Future[T any] struct {
+	done  <-chan struct{}
+	value T
+	err   *error
+}
+
+// GoResult schedules fn on g and returns its pending result. It is the generic form of
+// Group.Lookup and Group.Search, for calls returning other types.
+//
+// Example usage:
+//
+//	items := gonpi.GoResult(g, func(ctx context.Context, c *gonpi.Client) ([]gonpi.BatchItem, error) {
+//	    return c.GetProvidersByNPIsOrdered(ctx, npis)
+//	})
+func GoResult[T any](g *Group, fn func(ctx context.Context, client *Client) (T, error)) *Future[T] {
+	f := &Future[T]{}
+	f.done, f.err = g.run(func(ctx context.Context, client *Client) (err error) {
+		f.value, err = fn(ctx, client)
+		return err
+	})
+	return f
+}
+
+// Get waits for the call to finish and returns its result. A call skipped because the
+// group's failure budget was exhausted returns ErrBatchAborted.
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.value, *f.err
+}
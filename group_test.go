@@ -0,0 +1,129 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGroup tests running mixed lookups and searches and collecting their results.
+func TestGroup(t *testing.T) {
+	server := newEchoServer(t)
+	client := NewClient(WithBaseURL(server.URL))
+
+	g := client.Group(context.Background())
+	lookups := make([]*Future[*Provider], len(batchNPIs))
+	for i, npi := range batchNPIs {
+		lookups[i] = g.Lookup(npi)
+	}
+	search := g.Search(SearchOptions{LastName: "Doe"})
+	count := GoResult(g, func(ctx context.Context, c *Client) (int, error) {
+		providers, err := c.SearchProviders(ctx, SearchOptions{LastName: "Smith"})
+		return len(providers), err
+	})
+
+	if err := g.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, lookup := range lookups {
+		if p, err := lookup.Get(); err != nil || p == nil || p.Number != batchNPIs[i] {
+			t.Errorf("lookup %d = %v, %v", i, p, err)
+		}
+	}
+	if providers, err := search.Get(); err != nil || len(providers) != 1 {
+		t.Errorf("search = %d providers, %v", len(providers), err)
+	}
+	if n, err := count.Get(); err != nil || n != 1 {
+		t.Errorf("count = %d, %v", n, err)
+	}
+}
+
+// TestGroup_Concurrency tests that no more than the configured number of calls run
+// at once.
+func TestGroup_Concurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	g := NewClient().Group(context.Background(), WithBatchConcurrency(2))
+	for range 10 {
+		g.Go(func(ctx context.Context, c *Client) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if peak.Load() != 2 {
+		t.Errorf("expected peak concurrency 2, got %d", peak.Load())
+	}
+}
+
+// TestGroup_FailFast tests that the first failure cancels running calls and skips
+// later ones.
+func TestGroup_FailFast(t *testing.T) {
+	boom := errors.New("boom")
+	g := NewClient().Group(context.Background(), WithBatchConcurrency(2), WithBatchFailFast())
+
+	started := make(chan struct{})
+	cancelled := GoResult(g, func(ctx context.Context, c *Client) (bool, error) {
+		close(started)
+		<-ctx.Done()
+		return true, ctx.Err()
+	})
+	<-started
+	g.Go(func(ctx context.Context, c *Client) error { return boom })
+	<-g.ctx.Done()
+	skipped := GoResult(g, func(ctx context.Context, c *Client) (bool, error) {
+		return true, nil
+	})
+
+	err := g.Wait()
+	if !errors.Is(err, boom) || !errors.Is(err, ErrBatchAborted) {
+		t.Fatalf("expected boom and ErrBatchAborted, got %v", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Error("calls cancelled by the abort should not be reported as failures")
+	}
+	if ok, err := cancelled.Get(); !ok || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected running call to be cancelled, got %v, %v", ok, err)
+	}
+	if ok, err := skipped.Get(); ok || !errors.Is(err, ErrBatchAborted) {
+		t.Errorf("expected queued call to be skipped, got %v, %v", ok, err)
+	}
+}
+
+// TestGroup_ContinueOnError tests that, by default, every call runs and every error
+// is collected, including panics.
+func TestGroup_ContinueOnError(t *testing.T) {
+	var ran atomic.Int32
+	g := NewClient().Group(context.Background())
+	for i := range 4 {
+		g.Go(func(ctx context.Context, c *Client) error {
+			ran.Add(1)
+			if i == 0 {
+				panic("bad call")
+			}
+			if i == 1 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+	err := g.Wait()
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || errors.Is(err, ErrBatchAborted) {
+		t.Errorf("expected joined errors with a PanicError, got %v", err)
+	}
+	if ran.Load() != 4 {
+		t.Errorf("expected every call to run, got %d", ran.Load())
+	}
+}
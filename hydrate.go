@@ -0,0 +1,37 @@
+package gonpi
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithStoreHydration upserts every provider returned by the API into the store set with
+// WithStore, so that a local database fills up from live traffic and store-backed
+// lookups, such as FindByPhone, cover more providers over time. Lookups, searches and
+// batch fetches all hydrate; cache hits do not, since they were stored when first
+// fetched. Store errors are recorded on the span and do not fail the call.
+//
+// Example usage:
+//
+//	client := gonpi.NewClient(gonpi.WithStore(store), gonpi.WithStoreHydration())
+func WithStoreHydration() ClientOption {
+	return func(c *Client) {
+		c.hydrate = true
+	}
+}
+
+// hydrateStore writes providers fetched from the API to the store if hydration is on
+// and the records are complete.
+func (c *Client) hydrateStore(ctx context.Context, span trace.Span, providers []Provider) {
+	if !c.hydrate || c.store == nil || len(providers) == 0 || c.projecting() {
+		return
+	}
+	if err := c.store.Put(ctx, providers...); err != nil {
+		span.RecordError(fmt.Errorf("store hydration failed: %w", err))
+		return
+	}
+	span.SetAttributes(c.traceAttrs(attribute.Int("hydrated", len(providers)))...)
+}
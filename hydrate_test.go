@@ -0,0 +1,64 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingStore is a MemoryStore whose Put fails.
+type failingStore struct {
+	*MemoryStore
+}
+
+func (s failingStore) Put(ctx context.Context, providers ...Provider) error {
+	return errors.New("store unavailable")
+}
+
+// TestWithStoreHydration tests that lookups, searches and batches fill the store, and
+// that cache hits and store errors do not affect calls.
+func TestWithStoreHydration(t *testing.T) {
+	server := newEchoServer(t)
+	store := NewMemoryStore()
+	client := NewClient(WithBaseURL(server.URL), WithStore(store), WithStoreHydration(), WithCache(time.Hour))
+	defer client.Close()
+	ctx := context.Background()
+
+	if _, err := client.GetProviderByNPI(ctx, batchNPIs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetProvidersByNPIs(ctx, batchNPIs[1:3]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SearchProviders(ctx, SearchOptions{Number: batchNPIs[3]}); err != nil {
+		t.Fatal(err)
+	}
+	for _, npi := range batchNPIs[:4] {
+		if p, _ := store.Get(ctx, npi); p == nil {
+			t.Errorf("%s not hydrated", npi)
+		}
+	}
+
+	// Cache hits are not written again
+	store.Delete(ctx, batchNPIs[0])
+	if _, err := client.GetProviderByNPI(ctx, batchNPIs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := store.Get(ctx, batchNPIs[0]); p != nil {
+		t.Error("cache hit hydrated the store")
+	}
+
+	failing := NewClient(WithBaseURL(server.URL), WithStore(failingStore{NewMemoryStore()}), WithStoreHydration())
+	defer failing.Close()
+	if p, err := failing.GetProviderByNPI(ctx, batchNPIs[0]); err != nil || p == nil {
+		t.Errorf("store errors should not fail lookups: %v, %v", p, err)
+	}
+
+	plain := NewClient(WithBaseURL(server.URL), WithStore(NewMemoryStore()))
+	defer plain.Close()
+	plain.GetProviderByNPI(ctx, batchNPIs[4])
+	if p, _ := plain.store.Get(ctx, batchNPIs[4]); p != nil {
+		t.Error("store hydrated without WithStoreHydration")
+	}
+}
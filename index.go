@@ -0,0 +1,179 @@
+package gonpi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Built-in secondary index names for ProviderStore.Lookup.
+const (
+	// IndexPhone indexes telephone numbers of addresses and practice locations,
+	// normalized with NormalizePhone.
+	IndexPhone = "phone"
+
+	// IndexAddress indexes the street addresses of practice addresses, keyed by
+	// AddressKey.
+	IndexAddress = "address"
+
+	// IndexEndpoint indexes Direct addresses and endpoint URLs, normalized with
+	// NormalizeEndpoint.
+	IndexEndpoint = "endpoint"
+
+	// IndexLicense indexes taxonomy license numbers by issuing state, keyed by
+	// LicenseKey.
+	IndexLicense = "license"
+
+	// IndexName indexes the trigrams of individual and organization names, as produced
+	// by NameNGrams.
+	IndexName = "name"
+
+	// IndexTaxonomy indexes taxonomy codes, uppercased.
+	IndexTaxonomy = "taxonomy"
+
+	// IndexZIP indexes the five-digit ZIP codes of practice addresses.
+	IndexZIP = "zip"
+)
+
+// Index derives secondary index keys from a provider. A store maps each key to the
+// providers that produced it, so adding a lookup dimension only needs a new Index.
+// Keys must be deterministic for a given provider.
+type Index interface {
+	// Name identifies the index in Lookup calls.
+	Name() string
+
+	// Keys returns the provider's keys. Empty and duplicate keys are ignored.
+	Keys(provider *Provider) []string
+}
+
+// NewIndex returns an Index named name whose keys are computed by keys.
+//
+// Example usage:
+//
+//	byCounty := NewIndex("county", func(p *Provider) []string {
+//	    if p.Extensions == nil {
+//	        return nil
+//	    }
+//	    var fips []string
+//	    for _, c := range p.Extensions.Counties {
+//	        fips = append(fips, c.FIPS)
+//	    }
+//	    return fips
+//	})
+//	store := NewMemoryStore(WithStoreIndexes(byCounty))
+func NewIndex(name string, keys func(provider *Provider) []string) Index {
+	return funcIndex{name: name, keys: keys}
+}
+
+type funcIndex struct {
+	name string
+	keys func(provider *Provider) []string
+}
+
+func (i funcIndex) Name() string                     { return i.name }
+func (i funcIndex) Keys(provider *Provider) []string { return i.keys(provider) }
+
+// DefaultIndexes returns the built-in secondary indexes: phone, endpoint, license,
+// name, taxonomy, ZIP and street address.
+func DefaultIndexes() []Index {
+	return []Index{
+		NewIndex(IndexPhone, phoneKeys),
+		NewIndex(IndexEndpoint, endpointKeys),
+		NewIndex(IndexLicense, licenseKeys),
+		NewIndex(IndexName, nameKeys),
+		NewIndex(IndexTaxonomy, taxonomyKeys),
+		NewIndex(IndexZIP, zipKeys),
+		NewIndex(IndexAddress, addressKeys),
+	}
+}
+
+func phoneKeys(provider *Provider) []string {
+	var keys []string
+	for _, addr := range provider.Addresses {
+		keys = append(keys, NormalizePhone(addr.TelephoneNumber))
+	}
+	for _, loc := range provider.PracticeLocations {
+		keys = append(keys, NormalizePhone(loc.TelephoneNumber))
+	}
+	return keys
+}
+
+func endpointKeys(provider *Provider) []string {
+	keys := make([]string, len(provider.Endpoints))
+	for i, endpoint := range provider.Endpoints {
+		keys[i] = NormalizeEndpoint(endpoint.Endpoint)
+	}
+	return keys
+}
+
+func licenseKeys(provider *Provider) []string {
+	keys := make([]string, len(provider.Taxonomies))
+	for i, taxonomy := range provider.Taxonomies {
+		keys[i] = LicenseKey(taxonomy.State, taxonomy.License)
+	}
+	return keys
+}
+
+func nameKeys(provider *Provider) []string {
+	var keys []string
+	for _, name := range []string{provider.Basic.FirstName, provider.Basic.LastName, provider.Basic.OrganizationName} {
+		keys = append(keys, NameNGrams(name)...)
+	}
+	for _, other := range provider.OtherNames {
+		keys = append(keys, NameNGrams(other.OrganizationName)...)
+		keys = append(keys, NameNGrams(other.LastName)...)
+	}
+	return keys
+}
+
+func taxonomyKeys(provider *Provider) []string {
+	keys := make([]string, len(provider.Taxonomies))
+	for i, taxonomy := range provider.Taxonomies {
+		keys[i] = strings.ToUpper(strings.TrimSpace(taxonomy.Code))
+	}
+	return keys
+}
+
+func zipKeys(provider *Provider) []string {
+	var keys []string
+	for _, addr := range practiceAddresses(provider) {
+		keys = append(keys, zip5(addr.PostalCode))
+	}
+	return keys
+}
+
+func addressKeys(provider *Provider) []string {
+	var keys []string
+	for _, addr := range practiceAddresses(provider) {
+		keys = append(keys, AddressKey(addr.Address1, addr.PostalCode, CountryCode(addr.CountryCode)))
+	}
+	return keys
+}
+
+// NameNGrams returns the distinct lowercase trigrams of each word in name, ignoring
+// punctuation. Words shorter than three letters are returned whole. Passing a search
+// term through NameNGrams and MemoryStore.LookupAll finds names containing it.
+func NameNGrams(name string) []string {
+	var grams []string
+	seen := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune(word)
+		if len(runes) < 3 {
+			if !seen[word] {
+				seen[word] = true
+				grams = append(grams, word)
+			}
+			continue
+		}
+		for i := 0; i+3 <= len(runes); i++ {
+			gram := string(runes[i : i+3])
+			if !seen[gram] {
+				seen[gram] = true
+				grams = append(grams, gram)
+			}
+		}
+	}
+	return grams
+}
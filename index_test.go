@@ -0,0 +1,138 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestNameNGrams tests trigram generation for names.
+func TestNameNGrams(t *testing.T) {
+	got := NameNGrams("O'Neil, Al")
+	want := []string{"o", "nei", "eil", "al"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NameNGrams() = %v, want %v", got, want)
+	}
+}
+
+// TestMemoryStore_DefaultIndexes tests the name, taxonomy and ZIP indexes.
+func TestMemoryStore_DefaultIndexes(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	smith := mockProvider()
+	smith.Number = "1111111111"
+	smith.Basic.LastName = "SMITHSON"
+	smith.Addresses = []Address{{AddressPurpose: "LOCATION", PostalCode: "021151234"}}
+	jones := mockProvider()
+	jones.Number = "2222222222"
+	jones.Basic.LastName = "JONES"
+	jones.Taxonomies = []Taxonomy{{Code: "207rc0000x"}}
+	store.Put(ctx, smith, jones)
+
+	grams := append(NameNGrams(smith.Basic.FirstName), NameNGrams("mith")...)
+	unsorted := slices.Clone(grams)
+	found, err := store.LookupAll(ctx, IndexName, grams...)
+	if err != nil {
+		t.Fatalf("name lookup: %v", err)
+	}
+	if !slices.Equal(grams, unsorted) {
+		t.Errorf("LookupAll reordered its keys: %v", grams)
+	}
+	if len(found) != 1 || found[0].Number != smith.Number {
+		t.Errorf("name lookup = %+v", found)
+	}
+
+	found, _ = store.Lookup(ctx, IndexZIP, "02115")
+	if len(found) != 1 || found[0].Number != smith.Number {
+		t.Errorf("zip lookup = %+v", found)
+	}
+
+	found, _ = store.Lookup(ctx, IndexTaxonomy, "207RC0000X")
+	if len(found) != 1 || found[0].Number != jones.Number {
+		t.Errorf("taxonomy lookup = %+v", found)
+	}
+
+	want := []string{IndexAddress, IndexEndpoint, IndexLicense, IndexName, IndexPhone, IndexTaxonomy, IndexZIP}
+	if got := store.Indexes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Indexes() = %v, want %v", got, want)
+	}
+}
+
+// TestMemoryStore_CustomIndexes tests registering, building, rebuilding and dropping indexes.
+func TestMemoryStore_CustomIndexes(t *testing.T) {
+	ctx := context.Background()
+	byGender := NewIndex("gender", func(p *Provider) []string { return []string{p.Basic.Gender} })
+	store := NewMemoryStore(WithStoreIndexes(byGender))
+
+	a := mockProvider()
+	a.Number = "1111111111"
+	a.Basic.Gender = "F"
+	b := mockProvider()
+	b.Number = "2222222222"
+	b.Basic.Gender = "M"
+	store.Put(ctx, a, b)
+
+	found, err := store.Lookup(ctx, "gender", "F")
+	if err != nil || len(found) != 1 || found[0].Number != a.Number {
+		t.Fatalf("gender lookup = %+v, %v", found, err)
+	}
+
+	// An index added later is built over existing records
+	byCredential := NewIndex("credential", func(p *Provider) []string { return []string{p.Basic.Credential} })
+	if err := store.AddIndex(ctx, byCredential); err != nil {
+		t.Fatalf("AddIndex: %v", err)
+	}
+	found, _ = store.Lookup(ctx, "credential", "MD")
+	if len(found) != 2 {
+		t.Errorf("credential lookup = %d results, want 2", len(found))
+	}
+
+	// Rebuilding picks up changed key derivation
+	switched := false
+	flagIndex := NewIndex("flag", func(p *Provider) []string {
+		if switched {
+			return []string{"on"}
+		}
+		return []string{"off"}
+	})
+	store.AddIndex(ctx, flagIndex)
+	switched = true
+	if err := store.RebuildIndexes(ctx); err != nil {
+		t.Fatalf("RebuildIndexes: %v", err)
+	}
+	if found, _ := store.Lookup(ctx, "flag", "on"); len(found) != 2 {
+		t.Errorf("rebuilt index lookup = %d results, want 2", len(found))
+	}
+	if found, _ := store.Lookup(ctx, "flag", "off"); len(found) != 0 {
+		t.Errorf("stale keys after rebuild: %d results", len(found))
+	}
+
+	store.DropIndex("gender")
+	if _, err := store.Lookup(ctx, "gender", "F"); !IsValidation(err) {
+		t.Errorf("expected ValidationError after DropIndex, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := store.AddIndex(cancelled, byGender); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := store.Lookup(ctx, "gender", "F"); !IsValidation(err) {
+		t.Error("index should not be registered after a cancelled build")
+	}
+
+	// A cancelled rebuild keeps the existing indexes
+	switched = false
+	if err := store.RebuildIndexes(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if found, _ := store.Lookup(ctx, "flag", "on"); len(found) != 2 {
+		t.Errorf("index lost after a cancelled rebuild: %d results", len(found))
+	}
+	if found, _ := store.Lookup(ctx, "credential", "MD"); len(found) != 2 {
+		t.Errorf("index lost after a cancelled rebuild: %d results", len(found))
+	}
+}
@@ -0,0 +1,302 @@
+package gonpi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Built-in integrity rule names.
+const (
+	// RuleAddressCluster flags a practice address listed by an unusual number of NPIs.
+	RuleAddressCluster = "address_cluster"
+
+	// RuleSharedOfficial flags newly enumerated organizations naming the same
+	// authorized official.
+	RuleSharedOfficial = "shared_official"
+
+	// RuleAddressChurn flags a provider whose practice address changed repeatedly in a
+	// short period.
+	RuleAddressChurn = "address_churn"
+)
+
+// Default IntegrityAnalyzer thresholds.
+const (
+	DefaultClusterThreshold  = 100
+	DefaultOfficialThreshold = 3
+	DefaultNewOrgWindow      = 365 * 24 * time.Hour
+	DefaultChurnThreshold    = 3
+	DefaultChurnWindow       = 365 * 24 * time.Hour
+)
+
+// IntegrityFinding is a suspicious pattern found by an IntegrityAnalyzer. Unlike a lint
+// Finding, it may involve many providers. It encodes to JSON as-is.
+type IntegrityFinding struct {
+	// Rule is the pattern found, such as RuleAddressCluster.
+	Rule string `json:"rule"`
+
+	// Severity ranks the finding.
+	Severity Severity `json:"severity"`
+
+	// Key identifies what the providers share: an AddressKey, an authorized official's
+	// name or, for RuleAddressChurn, the NPI.
+	Key string `json:"key"`
+
+	// NPIs are the providers involved, sorted.
+	NPIs []string `json:"npis"`
+
+	// Message describes the finding.
+	Message string `json:"message"`
+}
+
+// String returns the finding as "severity rule: message".
+func (f IntegrityFinding) String() string {
+	return fmt.Sprintf("%s %s: %s", f.Severity, f.Rule, f.Message)
+}
+
+// IntegrityOption configures an IntegrityAnalyzer.
+type IntegrityOption func(*IntegrityAnalyzer)
+
+// WithClusterThreshold sets how many NPIs must list one practice address for
+// RuleAddressCluster to flag it. The default is DefaultClusterThreshold.
+func WithClusterThreshold(n int) IntegrityOption {
+	return func(a *IntegrityAnalyzer) {
+		a.clusterThreshold = n
+	}
+}
+
+// WithResidentialCheck sets a classifier, such as a lookup in USPS residential
+// delivery indicator data, reporting whether an address is residential. With one set,
+// RuleAddressCluster only flags residential addresses, as warnings. Without one, it
+// flags every crowded address as info, since most are hospitals and medical office
+// buildings.
+func WithResidentialCheck(residential func(Address) bool) IntegrityOption {
+	return func(a *IntegrityAnalyzer) {
+		a.residential = residential
+	}
+}
+
+// WithSharedOfficialThreshold sets how many organizations enumerated within window
+// must name the same authorized official for RuleSharedOfficial to flag them. The
+// defaults are DefaultOfficialThreshold and DefaultNewOrgWindow.
+func WithSharedOfficialThreshold(n int, window time.Duration) IntegrityOption {
+	return func(a *IntegrityAnalyzer) {
+		a.officialThreshold = n
+		a.newOrgWindow = window
+	}
+}
+
+// WithChurnThreshold sets how many practice address changes within window
+// RuleAddressChurn flags. The defaults are DefaultChurnThreshold and
+// DefaultChurnWindow.
+func WithChurnThreshold(n int, window time.Duration) IntegrityOption {
+	return func(a *IntegrityAnalyzer) {
+		a.churnThreshold = n
+		a.churnWindow = window
+	}
+}
+
+// IntegrityAnalyzer flags patterns in a local store that program-integrity and fraud
+// analytics teams review: many NPIs enumerated at one address, newly enumerated
+// organizations sharing an authorized official, and rapid practice address churn.
+// Findings are leads for investigation, not evidence of fraud.
+//
+// A store holds only each provider's current record, so address churn is learned
+// from change events: register the analyzer as a Watcher publisher, or Publish
+// archived events to it. It is safe for concurrent use.
+type IntegrityAnalyzer struct {
+	clusterThreshold  int
+	residential       func(Address) bool
+	officialThreshold int
+	newOrgWindow      time.Duration
+	churnThreshold    int
+	churnWindow       time.Duration
+	now               func() time.Time
+
+	mu    sync.Mutex
+	moves map[string][]time.Time
+}
+
+// NewIntegrityAnalyzer returns an analyzer with the default thresholds, adjusted by opts.
+func NewIntegrityAnalyzer(opts ...IntegrityOption) *IntegrityAnalyzer {
+	a := &IntegrityAnalyzer{
+		clusterThreshold:  DefaultClusterThreshold,
+		officialThreshold: DefaultOfficialThreshold,
+		newOrgWindow:      DefaultNewOrgWindow,
+		churnThreshold:    DefaultChurnThreshold,
+		churnWindow:       DefaultChurnWindow,
+		now:               time.Now,
+		moves:             make(map[string][]time.Time),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Publish implements Publisher, recording a practice address change when an
+// EventProviderUpdated event moves the provider to a different set of practice
+// addresses, compared with AddressKey.
+func (a *IntegrityAnalyzer) Publish(ctx context.Context, event ChangeEvent) error {
+	if event.Type != EventProviderUpdated || event.Previous == nil || event.Provider == nil {
+		return nil
+	}
+	if slices.Equal(practiceAddressKeys(event.Previous), practiceAddressKeys(event.Provider)) {
+		return nil
+	}
+	at := event.Time
+	if at.IsZero() {
+		at = a.now()
+	}
+	a.mu.Lock()
+	a.moves[event.NPI] = append(a.moves[event.NPI], at)
+	a.mu.Unlock()
+	return nil
+}
+
+// practiceAddressKeys returns the provider's distinct practice AddressKeys, sorted.
+func practiceAddressKeys(provider *Provider) []string {
+	var keys []string
+	for _, key := range addressKeys(provider) {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// Analyze scans store and returns the findings, most severe first. Address churn
+// findings come from the events published so far; store may be nil to report only
+// those.
+//
+// Example usage:
+//
+//	analyzer := gonpi.NewIntegrityAnalyzer(gonpi.WithResidentialCheck(isResidential))
+//	findings, err := analyzer.Analyze(ctx, store)
+//	for _, f := range findings {
+//	    fmt.Println(f)
+//	}
+func (a *IntegrityAnalyzer) Analyze(ctx context.Context, store ProviderScanner) ([]IntegrityFinding, error) {
+	now := a.now()
+	clusters := make(map[string][]string)
+	clusterAddrs := make(map[string]Address)
+	officials := make(map[string][]string)
+	if store != nil {
+		for provider, err := range store.All(ctx) {
+			if err != nil {
+				return nil, fmt.Errorf("integrity scan failed: %w", err)
+			}
+			for _, addr := range practiceAddresses(&provider) {
+				key := AddressKey(addr.Address1, addr.PostalCode, CountryCode(addr.CountryCode))
+				npis := clusters[key]
+				if key == "" || len(npis) > 0 && npis[len(npis)-1] == provider.Number {
+					continue
+				}
+				if _, ok := clusterAddrs[key]; !ok {
+					clusterAddrs[key] = addr
+				}
+				clusters[key] = append(npis, provider.Number)
+			}
+			if key := officialKey(provider); key != "" {
+				if enumerated, ok := provider.EnumerationTime(); ok && now.Sub(enumerated) <= a.newOrgWindow {
+					officials[key] = append(officials[key], provider.Number)
+				}
+			}
+		}
+	}
+
+	var findings []IntegrityFinding
+	for key, npis := range clusters {
+		if len(npis) < a.clusterThreshold {
+			continue
+		}
+		slices.Sort(npis)
+		addr, severity := clusterAddrs[key], SeverityInfo
+		if a.residential != nil {
+			if !a.residential(addr) {
+				continue
+			}
+			severity = SeverityWarning
+		}
+		findings = append(findings, IntegrityFinding{
+			Rule: RuleAddressCluster, Severity: severity, Key: key, NPIs: npis,
+			Message: fmt.Sprintf("%d NPIs list the practice address %s", len(npis), strings.Join(addr.Lines(), ", ")),
+		})
+	}
+	for key, npis := range officials {
+		if len(npis) < a.officialThreshold {
+			continue
+		}
+		slices.Sort(npis)
+		findings = append(findings, IntegrityFinding{
+			Rule: RuleSharedOfficial, Severity: SeverityWarning, Key: key, NPIs: npis,
+			Message: fmt.Sprintf("%d organizations enumerated within %s name authorized official %s", len(npis), formatWindow(a.newOrgWindow), key),
+		})
+	}
+	findings = append(findings, a.churn(now)...)
+
+	sort.Slice(findings, func(i, j int) bool {
+		x, y := findings[i], findings[j]
+		if x.Severity.rank() != y.Severity.rank() {
+			return x.Severity.rank() > y.Severity.rank()
+		}
+		if x.Rule != y.Rule {
+			return x.Rule < y.Rule
+		}
+		return x.Key < y.Key
+	})
+	return findings, nil
+}
+
+// churn returns the RuleAddressChurn findings as of now, forgetting address changes
+// older than the churn window.
+func (a *IntegrityAnalyzer) churn(now time.Time) []IntegrityFinding {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var findings []IntegrityFinding
+	for npi, moves := range a.moves {
+		moves = slices.DeleteFunc(moves, func(at time.Time) bool { return now.Sub(at) > a.churnWindow })
+		if len(moves) == 0 {
+			delete(a.moves, npi)
+			continue
+		}
+		a.moves[npi] = moves
+		if len(moves) >= a.churnThreshold {
+			findings = append(findings, IntegrityFinding{
+				Rule: RuleAddressChurn, Severity: SeverityWarning, Key: npi, NPIs: []string{npi},
+				Message: fmt.Sprintf("practice address changed %d times within %s", len(moves), formatWindow(a.churnWindow)),
+			})
+		}
+	}
+	return findings
+}
+
+// officialKey returns the uppercased "FIRST LAST" of an organization's authorized
+// official, or "" for individuals and organizations without one. Middle names are
+// left out because records disagree on initials.
+func officialKey(provider Provider) string {
+	if !provider.IsOrganization() {
+		return ""
+	}
+	first := strings.ToUpper(strings.TrimSpace(provider.Basic.AuthorizedOfficialFirstName))
+	last := strings.ToUpper(strings.TrimSpace(provider.Basic.AuthorizedOfficialLastName))
+	if first == "" || last == "" {
+		return ""
+	}
+	return first + " " + last
+}
+
+// formatWindow returns d in days, such as "90 days".
+func formatWindow(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
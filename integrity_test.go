@@ -0,0 +1,77 @@
+package gonpi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestIntegrityAnalyzer tests the address cluster, shared official and churn rules.
+func TestIntegrityAnalyzer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+
+	house := Address{AddressPurpose: "LOCATION", Address1: "12 Maple Lane", City: "SPRINGFIELD", State: "IL", PostalCode: "62704"}
+	for i := range 4 {
+		p := mockProvider()
+		p.Number = fmt.Sprintf("10000000%02d", i)
+		p.Addresses = []Address{house}
+		store.Put(ctx, p)
+	}
+	for i, enumerated := range []string{"2026-01-15", "2026-03-02", "2026-04-20", "2019-05-01"} {
+		org := mockProvider()
+		org.Number = fmt.Sprintf("20000000%02d", i)
+		org.EnumerationType = "NPI-2"
+		org.Basic = BasicInfo{OrganizationName: fmt.Sprintf("CLINIC %d LLC", i), EnumerationDate: enumerated,
+			AuthorizedOfficialFirstName: "John", AuthorizedOfficialMiddleName: fmt.Sprint(i), AuthorizedOfficialLastName: "Doe"}
+		org.Addresses = []Address{{AddressPurpose: "LOCATION", Address1: fmt.Sprintf("%d Main St", i), PostalCode: "62701"}}
+		store.Put(ctx, org)
+	}
+
+	analyzer := NewIntegrityAnalyzer(
+		WithClusterThreshold(4),
+		WithResidentialCheck(func(a Address) bool { return NormalizeStreet(a.Address1) == "12 MAPLE LN" }),
+	)
+	analyzer.now = func() time.Time { return now }
+
+	mover := mockProvider()
+	mover.Number = "3000000000"
+	for i := range 4 {
+		previous, current := mover, mover
+		previous.Addresses = []Address{{AddressPurpose: "LOCATION", Address1: fmt.Sprintf("%d Oak St", i), PostalCode: "62701"}}
+		current.Addresses = []Address{{AddressPurpose: "LOCATION", Address1: fmt.Sprintf("%d Oak St", i+1), PostalCode: "62701"}}
+		analyzer.Publish(ctx, ChangeEvent{Type: EventProviderUpdated, NPI: mover.Number,
+			Time: now.AddDate(0, -i*5, 0), Previous: &previous, Provider: &current})
+	}
+	unchanged := mover
+	unchanged.Basic.LastUpdated = "2026-05-01"
+	analyzer.Publish(ctx, ChangeEvent{Type: EventProviderUpdated, NPI: mover.Number, Time: now, Previous: &mover, Provider: &unchanged})
+
+	findings, err := analyzer.Analyze(ctx, store)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(findings) != 3 {
+		t.Fatalf("findings = %v", findings)
+	}
+	byRule := map[string]IntegrityFinding{}
+	for _, f := range findings {
+		byRule[f.Rule] = f
+	}
+	if f := byRule[RuleAddressCluster]; f.Key != "US:62704:12 MAPLE LN" || len(f.NPIs) != 4 || f.Severity != SeverityWarning {
+		t.Errorf("address cluster = %+v", f)
+	}
+	if f := byRule[RuleSharedOfficial]; f.Key != "JOHN DOE" || len(f.NPIs) != 3 || f.NPIs[0] != "2000000000" {
+		t.Errorf("shared official = %+v", f)
+	}
+	if f := byRule[RuleAddressChurn]; f.Key != mover.Number || f.Message != "practice address changed 3 times within 365 days" {
+		t.Errorf("address churn = %+v", f)
+	}
+
+	quiet := NewIntegrityAnalyzer()
+	if findings, _ := quiet.Analyze(ctx, store); len(findings) != 1 || findings[0].Rule != RuleSharedOfficial {
+		t.Errorf("default findings = %v", findings)
+	}
+}
@@ -0,0 +1,20 @@
+package gonpi
+
+import "context"
+
+// NPIClient is the lookup API implemented by *Client. Depend on it instead of
+// *Client to substitute fakes in tests, local-store implementations, or decorators
+// that add behavior such as logging around a real client.
+type NPIClient interface {
+	// GetProviderByNPI retrieves a provider by NPI number, returning nil and a nil
+	// error if it does not exist.
+	GetProviderByNPI(ctx context.Context, npi string) (*Provider, error)
+
+	// SearchProviders returns one page of providers matching opts.
+	SearchProviders(ctx context.Context, opts SearchOptions) ([]Provider, error)
+
+	// GetProvidersByNPIs retrieves several providers, keyed by normalized NPI.
+	GetProvidersByNPIs(ctx context.Context, npis []string, opts ...BatchOption) (map[string]*Provider, error)
+}
+
+var _ NPIClient = (*Client)(nil)
@@ -0,0 +1,83 @@
+// Command genknown refreshes the known NPI list embedded as gonpi.KnownNPIs against the
+// live registry. Each listed NPI is looked up and its name, enumeration type and
+// practice state are rewritten from the current record; NPIs that are no longer active
+// are dropped with a warning. New NPIs can be added with -add.
+//
+// Usage:
+//
+//	go run ./internal/cmd/genknown -file data/known_npis.json [-add 1234567893,...]
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+func main() {
+	file := flag.String("file", "data/known_npis.json", "known NPI list to refresh")
+	add := flag.String("add", "", "comma-separated NPIs to add")
+	flag.Parse()
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var known []gonpi.KnownNPI
+	if err := json.Unmarshal(data, &known); err != nil {
+		log.Fatal(err)
+	}
+	for _, npi := range strings.Split(*add, ",") {
+		if npi = strings.TrimSpace(npi); npi != "" {
+			known = append(known, gonpi.KnownNPI{NPI: npi})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	client := gonpi.NewClient(gonpi.WithRateLimit(2, 1))
+	defer client.Close()
+
+	var refreshed []gonpi.KnownNPI
+	seen := make(map[string]bool)
+	for _, k := range known {
+		if seen[k.NPI] {
+			continue
+		}
+		seen[k.NPI] = true
+		provider, err := client.GetProviderByNPI(ctx, k.NPI)
+		if err != nil {
+			log.Fatalf("%s: %v", k.NPI, err)
+		}
+		if provider == nil || provider.Basic.Status != "A" {
+			log.Printf("dropping %s: no longer active", k.NPI)
+			continue
+		}
+		k.Name = provider.FullName()
+		k.EnumerationType = provider.EnumerationType
+		k.State = ""
+		for _, address := range provider.Addresses {
+			if address.AddressPurpose == "LOCATION" {
+				k.State = address.State
+			}
+		}
+		refreshed = append(refreshed, k)
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(refreshed); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*file, out.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,53 @@
+// Command genschema writes the JSON Schemas embedded as gonpi.RegistrySchema and
+// gonpi.EventSchema, and the Protocol Buffers schema embedded as gonpi.ProtoSchema.
+//
+// Usage:
+//
+//	go run ./internal/cmd/genschema -dir schema
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sdsvn/gonpi"
+)
+
+func main() {
+	dir := flag.String("dir", "schema", "output directory")
+	flag.Parse()
+
+	registry, err := gonpi.GenerateJSONSchema(gonpi.APIResponse{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	write(filepath.Join(*dir, "registry.schema.json"), registry)
+
+	event, err := gonpi.GenerateEventSchema()
+	if err != nil {
+		log.Fatal(err)
+	}
+	write(filepath.Join(*dir, "event.schema.json"), event)
+
+	// Field numbers are kept from the current schema, if there is one
+	protoPath := filepath.Join(*dir, "gonpi.proto")
+	previous, err := os.ReadFile(protoPath)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+	proto, err := gonpi.GenerateProtoSchema(previous)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(protoPath, proto, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func write(path string, data []byte) {
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
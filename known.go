@@ -0,0 +1,139 @@
+package gonpi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//go:generate go run ./internal/cmd/genknown -file data/known_npis.json
+
+// ErrKnownNPIMismatch indicates that the registry's record for a known NPI no longer
+// matches its expected values.
+var ErrKnownNPIMismatch = errors.New("known NPI does not match registry")
+
+// KnownNPI is a stable, well-known NPI with the values the registry is expected to
+// return for it, for smoke tests and health checks.
+type KnownNPI struct {
+	// NPI is the provider's NPI.
+	NPI string `json:"npi"`
+
+	// Name is the expected Provider.FullName, or "" to skip the check. Every word must
+	// appear in the registry's name, so middle names may be omitted.
+	Name string `json:"name"`
+
+	// EnumerationType is the expected NPI-1 or NPI-2, or "" to skip the check.
+	EnumerationType string `json:"enumeration_type,omitempty"`
+
+	// State is the expected practice location state, or "" to skip the check.
+	State string `json:"state,omitempty"`
+
+	// Note says why the NPI is on the list.
+	Note string `json:"note,omitempty"`
+}
+
+//go:embed data/known_npis.json
+var knownNPIsJSON []byte
+
+// KnownNPIs returns the embedded list of known NPIs. Expected values are refreshed
+// against the live registry by go generate; entries not yet refreshed only check that
+// the NPI exists. It returns a new slice on every call.
+func KnownNPIs() []KnownNPI {
+	var known []KnownNPI
+	if err := json.Unmarshal(knownNPIsJSON, &known); err != nil {
+		panic(fmt.Sprintf("gonpi: invalid embedded known NPIs: %v", err)) // checked by tests
+	}
+	return known
+}
+
+// Check returns an error wrapping ErrKnownNPIMismatch describing how p differs from
+// the expected values, or nil if it matches.
+func (k KnownNPI) Check(p *Provider) error {
+	if p == nil {
+		return fmt.Errorf("%w: %s was not found", ErrKnownNPIMismatch, k.NPI)
+	}
+	var problems []string
+	name := strings.Fields(strings.ToUpper(p.FullName()))
+	for _, word := range strings.Fields(strings.ToUpper(k.Name)) {
+		found := false
+		for _, have := range name {
+			found = found || have == word
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("name %q, want %q", p.FullName(), k.Name))
+			break
+		}
+	}
+	if k.EnumerationType != "" && p.EnumerationType != k.EnumerationType {
+		problems = append(problems, fmt.Sprintf("enumeration type %s, want %s", p.EnumerationType, k.EnumerationType))
+	}
+	if k.State != "" {
+		state := ""
+		for _, address := range p.Addresses {
+			if address.AddressPurpose == "LOCATION" {
+				state = address.State
+			}
+		}
+		if !strings.EqualFold(state, k.State) {
+			problems = append(problems, fmt.Sprintf("state %s, want %s", state, k.State))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s has %s", ErrKnownNPIMismatch, k.NPI, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// VerifyKnownNPIs looks up each of known, or KnownNPIs if none are given, and checks
+// the registry's records against them, bypassing the cache. It returns the joined
+// errors for every NPI that failed to load or match.
+//
+// Example usage:
+//
+//	if err := client.VerifyKnownNPIs(ctx); err != nil {
+//	    log.Printf("registry smoke test failed: %v", err)
+//	}
+func (c *Client) VerifyKnownNPIs(ctx context.Context, known ...KnownNPI) error {
+	if len(known) == 0 {
+		known = KnownNPIs()
+	}
+	ctx, span := c.tracer.Start(ctx, "VerifyKnownNPIs",
+		trace.WithAttributes(c.traceAttrs(attribute.Int("npi_count", len(known)))...),
+	)
+	defer span.End()
+
+	var errs []error
+	for _, k := range known {
+		providers, err := c.SearchProviders(ctx, SearchOptions{Number: k.NPI})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("known NPI %s: %w", k.NPI, err))
+			continue
+		}
+		var provider *Provider
+		if len(providers) > 0 {
+			provider = &providers[0]
+		}
+		if err := k.Check(provider); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "known NPI verification failed")
+	}
+	return err
+}
+
+// Ping checks that the registry is reachable and answering correctly by looking up
+// the first known NPI, bypassing the cache.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.VerifyKnownNPIs(ctx, KnownNPIs()[0])
+}
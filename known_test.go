@@ -0,0 +1,99 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestKnownNPIs tests that the embedded list parses and holds valid, unique NPIs.
+func TestKnownNPIs(t *testing.T) {
+	known := KnownNPIs()
+	if len(known) == 0 {
+		t.Fatal("no known NPIs")
+	}
+	seen := make(map[string]bool)
+	for _, k := range known {
+		if npi, err := NormalizeNPI(k.NPI); err != nil || npi != k.NPI {
+			t.Errorf("%s: %v", k.NPI, err)
+		}
+		if seen[k.NPI] {
+			t.Errorf("%s listed twice", k.NPI)
+		}
+		seen[k.NPI] = true
+	}
+	known[0].NPI = "changed"
+	if KnownNPIs()[0].NPI == "changed" {
+		t.Error("KnownNPIs returned shared slice")
+	}
+}
+
+// TestKnownNPI_Check tests name, enumeration type and state matching.
+func TestKnownNPI_Check(t *testing.T) {
+	p := mockProvider()
+	p.Basic.FirstName, p.Basic.MiddleName, p.Basic.LastName = "John", "Q", "Doe"
+	p.Addresses = []Address{{AddressPurpose: "MAILING", State: "NV"}, {AddressPurpose: "LOCATION", State: "IL"}}
+
+	tests := []struct {
+		name  string
+		known KnownNPI
+		ok    bool
+	}{
+		{"existence only", KnownNPI{NPI: p.Number}, true},
+		{"name without middle", KnownNPI{NPI: p.Number, Name: "john doe"}, true},
+		{"all fields", KnownNPI{NPI: p.Number, Name: "JOHN Q DOE", EnumerationType: "NPI-1", State: "IL"}, true},
+		{"wrong name", KnownNPI{NPI: p.Number, Name: "JANE DOE"}, false},
+		{"wrong type", KnownNPI{NPI: p.Number, EnumerationType: "NPI-2"}, false},
+		{"mailing state", KnownNPI{NPI: p.Number, State: "NV"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.known.Check(&p)
+			if (err == nil) != tt.ok {
+				t.Errorf("Check() = %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && !errors.Is(err, ErrKnownNPIMismatch) {
+				t.Errorf("error %v does not wrap ErrKnownNPIMismatch", err)
+			}
+		})
+	}
+	if err := (KnownNPI{NPI: p.Number}).Check(nil); !errors.Is(err, ErrKnownNPIMismatch) {
+		t.Errorf("Check(nil) = %v", err)
+	}
+}
+
+// TestVerifyKnownNPIs tests verification against a server that knows one of two NPIs.
+func TestVerifyKnownNPIs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := APIResponse{}
+		if r.URL.Query().Get("number") == "1234567893" {
+			p := mockProvider()
+			p.Number = "1234567893"
+			resp.Results = []Provider{p}
+			resp.ResultCount = 1
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+
+	if err := client.VerifyKnownNPIs(context.Background(), KnownNPI{NPI: "1234567893"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := client.VerifyKnownNPIs(context.Background(),
+		KnownNPI{NPI: "1234567893", EnumerationType: "NPI-2"},
+		KnownNPI{NPI: "1245319599"},
+	)
+	if !errors.Is(err, ErrKnownNPIMismatch) {
+		t.Fatalf("VerifyKnownNPIs() = %v", err)
+	}
+	for _, want := range []string{"1234567893 has enumeration type", "1245319599 was not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package gonpi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NormalizeLicense canonicalizes a state license number for matching: it is
+// uppercased and spaces, hyphens and periods are removed, so "md-12 345" and
+// "MD12345" compare equal.
+func NormalizeLicense(license string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(license)))
+}
+
+// LicenseKey returns the IndexLicense key for a license issued by state, or "" if
+// either part is empty.
+func LicenseKey(state, license string) string {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	license = NormalizeLicense(license)
+	if state == "" || license == "" {
+		return ""
+	}
+	return state + ":" + license
+}
+
+// HasLicense matches providers with a taxonomy listing the given license number issued
+// by state, compared with NormalizeLicense.
+func HasLicense(state, license string) ProviderFilter {
+	key := LicenseKey(state, license)
+	return func(p Provider) bool {
+		if key == "" {
+			return false
+		}
+		for _, taxonomy := range p.Taxonomies {
+			if LicenseKey(taxonomy.State, taxonomy.License) == key {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FindByLicense returns the stored providers holding the given license number
+// issued by state, as listed on their taxonomies. The NPI Registry API cannot search
+// by license and rejects searches by state alone, so, like FindByPhone, this requires
+// a store configured with WithStore; otherwise it returns ErrNoStore. Without a
+// store, SearchByLicense filters a narrower API search instead.
+func (c *Client) FindByLicense(ctx context.Context, state, license string) ([]Provider, error) {
+	ctx, span := c.tracer.Start(ctx, "FindByLicense",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.String("state", state),
+			attribute.String("license", license),
+		)...),
+	)
+	defer span.End()
+
+	key := LicenseKey(state, license)
+	if key == "" {
+		err := &ValidationError{Field: "license", Message: "state and license cannot be empty"}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return c.lookupStore(ctx, span, IndexLicense, key)
+}
+
+// SearchByLicense is the best-effort API counterpart of FindByLicense: it pages
+// through SearchAll(ctx, opts) at MaxLimit results per page and returns the providers
+// holding license as issued by opts.State. Since the registry rejects searches by
+// state alone, opts must also set a name, taxonomy description, city or postal code;
+// otherwise SearchByLicense returns a ValidationError without contacting the API.
+// Paging stops at MaxSkip, so providers beyond it are missed.
+//
+// Example usage:
+//
+//	found, err := client.SearchByLicense(ctx, gonpi.SearchOptions{State: "MA", LastName: "Smith"}, "12345")
+func (c *Client) SearchByLicense(ctx context.Context, opts SearchOptions, license string) ([]Provider, error) {
+	ctx, span := c.tracer.Start(ctx, "SearchByLicense",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.String("state", opts.State),
+			attribute.String("license", license),
+		)...),
+	)
+	defer span.End()
+
+	var err error
+	switch {
+	case LicenseKey(opts.State, license) == "":
+		err = &ValidationError{Field: "license", Message: "state and license cannot be empty"}
+	case opts.FirstName == "" && opts.LastName == "" && opts.OrganizationName == "" &&
+		opts.TaxonomyDescription == "" && opts.City == "" && opts.PostalCode == "":
+		err = &ValidationError{Field: "opts", Message: "license search needs a name, taxonomy description, city or postal code besides the state"}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	var matched []Provider
+	opts.Skip, opts.Limit, opts.Cursor = 0, MaxLimit, ""
+	for provider, err := range Filter(c.SearchAll(ctx, opts), HasLicense(opts.State, license)) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "license search failed")
+			return matched, fmt.Errorf("license search failed: %w", err)
+		}
+		matched = append(matched, provider)
+	}
+	span.SetAttributes(c.traceAttrs(attribute.Int("result_count", len(matched)))...)
+	return matched, nil
+}
@@ -0,0 +1,86 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func licensedProvider(number, state, license string) Provider {
+	p := mockProvider()
+	p.Number = number
+	p.Taxonomies = []Taxonomy{{Code: "207Q00000X", State: state, License: license, Primary: true}}
+	return p
+}
+
+// TestNormalizeLicense tests license key normalization.
+func TestNormalizeLicense(t *testing.T) {
+	if got := LicenseKey(" ma ", "md-12 345"); got != "MA:MD12345" {
+		t.Errorf("LicenseKey() = %q", got)
+	}
+	if LicenseKey("", "123") != "" || LicenseKey("MA", " ") != "" {
+		t.Error("expected empty key for missing parts")
+	}
+	if !HasLicense("MA", "MD12345")(licensedProvider("1", "MA", "md.12345")) {
+		t.Error("HasLicense should match normalized license")
+	}
+	if HasLicense("NY", "MD12345")(licensedProvider("1", "MA", "MD12345")) {
+		t.Error("HasLicense should not match another state")
+	}
+}
+
+// TestFindByLicense_Store tests license lookup through the store index.
+func TestFindByLicense_Store(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	store.Put(ctx, licensedProvider("1111111111", "MA", "12345"), licensedProvider("2222222222", "NH", "12345"))
+	client := NewClient(WithStore(store))
+
+	found, err := client.FindByLicense(ctx, "ma", "12-345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0].Number != "1111111111" {
+		t.Errorf("unexpected result: %+v", found)
+	}
+
+	if _, err := client.FindByLicense(ctx, "MA", ""); !IsValidation(err) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+}
+
+// TestSearchByLicense tests the post-filtered API search used without a store.
+func TestSearchByLicense(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("state") != "MA" || q.Get("last_name") != "SMITH" || q.Get("limit") != "200" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		var providers []Provider
+		if r.URL.Query().Get("skip") == "" || r.URL.Query().Get("skip") == "0" {
+			providers = []Provider{licensedProvider("1111111111", "MA", "999"), licensedProvider("2222222222", "MA", "12345")}
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse(providers))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	ctx := context.Background()
+	found, err := client.SearchByLicense(ctx, SearchOptions{State: "MA", LastName: "SMITH"}, "12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0].Number != "2222222222" {
+		t.Errorf("unexpected result: %+v", found)
+	}
+
+	// A state alone is rejected before contacting the API
+	if _, err := client.SearchByLicense(ctx, SearchOptions{State: "MA"}, "12345"); !IsValidation(err) {
+		t.Errorf("expected ValidationError for a state-only search, got %v", err)
+	}
+	if _, err := client.FindByLicense(ctx, "MA", "12345"); !errors.Is(err, ErrNoStore) {
+		t.Errorf("expected ErrNoStore without a store, got %v", err)
+	}
+}
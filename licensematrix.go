@@ -0,0 +1,113 @@
+package gonpi
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// StateLicense is one license number held in a state, merged from every place the
+// record lists it.
+type StateLicense struct {
+	// Number is the license number as first listed.
+	Number string `json:"number"`
+
+	// TaxonomyCodes are the taxonomies listing the license, in record order.
+	TaxonomyCodes []string `json:"taxonomy_codes,omitempty"`
+
+	// FromIdentifier reports whether the license is also, or only, listed among the
+	// provider's other identifiers.
+	FromIdentifier bool `json:"from_identifier,omitempty"`
+}
+
+// StateLicenses maps two-letter state codes to the distinct licenses held there.
+type StateLicenses map[string][]StateLicense
+
+// LicenseConflict is a state listing more than one distinct license number.
+type LicenseConflict struct {
+	State   string   `json:"state"`
+	Numbers []string `json:"numbers"`
+}
+
+// LicenseMatrix returns the provider's licenses by state, merged from its taxonomies
+// and from identifiers described as licenses (identifiers whose description or issuer
+// mentions "license", such as some state "Other" identifiers; Medicaid and Medicare
+// numbers are not licenses). Numbers are merged when they compare equal with
+// NormalizeLicense, and states are uppercased. Entries without a state or number are
+// skipped.
+//
+// Example usage:
+//
+//	for _, conflict := range gonpi.LicenseMatrix(provider).Conflicts() {
+//	    log.Printf("%s lists licenses %v in %s", provider.Number, conflict.Numbers, conflict.State)
+//	}
+func LicenseMatrix(provider Provider) StateLicenses {
+	matrix := make(StateLicenses)
+	add := func(state, number string) *StateLicense {
+		state = strings.ToUpper(strings.TrimSpace(state))
+		normalized := NormalizeLicense(number)
+		if state == "" || normalized == "" {
+			return nil
+		}
+		licenses := matrix[state]
+		for i := range licenses {
+			if NormalizeLicense(licenses[i].Number) == normalized {
+				return &licenses[i]
+			}
+		}
+		matrix[state] = append(licenses, StateLicense{Number: strings.TrimSpace(number)})
+		return &matrix[state][len(matrix[state])-1]
+	}
+
+	for _, taxonomy := range provider.Taxonomies {
+		license := add(taxonomy.State, taxonomy.License)
+		if license != nil && taxonomy.Code != "" && !slices.Contains(license.TaxonomyCodes, taxonomy.Code) {
+			license.TaxonomyCodes = append(license.TaxonomyCodes, taxonomy.Code)
+		}
+	}
+	for _, identifier := range provider.Identifiers {
+		if !isLicenseIdentifier(identifier) {
+			continue
+		}
+		if license := add(identifier.State, identifier.Identifier); license != nil {
+			license.FromIdentifier = true
+		}
+	}
+	return matrix
+}
+
+// isLicenseIdentifier reports whether identifier describes a license.
+func isLicenseIdentifier(identifier Identifier) bool {
+	return strings.Contains(strings.ToUpper(identifier.Desc), "LICENS") ||
+		strings.Contains(strings.ToUpper(identifier.Issuer), "LICENS")
+}
+
+// States returns the states with licenses, sorted.
+func (m StateLicenses) States() []string {
+	states := make([]string, 0, len(m))
+	for state := range m {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	return states
+}
+
+// Conflicts returns the states listing more than one distinct license number, sorted
+// by state. A conflict is a credentialing signal, not necessarily an error: a provider
+// holding licenses for several professions in one state, such as a registered nurse
+// who is also a nurse practitioner, legitimately lists different numbers.
+func (m StateLicenses) Conflicts() []LicenseConflict {
+	var conflicts []LicenseConflict
+	for _, state := range m.States() {
+		licenses := m[state]
+		if len(licenses) < 2 {
+			continue
+		}
+		conflict := LicenseConflict{State: state}
+		for _, license := range licenses {
+			conflict.Numbers = append(conflict.Numbers, license.Number)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
@@ -0,0 +1,57 @@
+package gonpi
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestLicenseMatrix tests merging taxonomy and identifier licenses by state.
+func TestLicenseMatrix(t *testing.T) {
+	p := mockProvider()
+	p.Taxonomies = []Taxonomy{
+		{Code: "207Q00000X", State: "ca", License: "A-12345"},
+		{Code: "207R00000X", State: "CA", License: "A12345"},
+		{Code: "207Q00000X", State: "NV", License: "NV-1"},
+		{Code: "208D00000X", State: "", License: "X1"},
+	}
+	p.Identifiers = []Identifier{
+		{Code: "01", Desc: "Other (non-Medicare)", Identifier: "a 12345", State: "CA", Issuer: "STATE MEDICAL LICENSE"},
+		{Code: "05", Desc: "MEDICAID", Identifier: "999", State: "CA"},
+		{Code: "01", Desc: "OTHER", Identifier: "OR-77", State: "OR", Issuer: "Oregon Licensing Board"},
+	}
+
+	got := LicenseMatrix(p)
+	want := StateLicenses{
+		"CA": {{Number: "A-12345", TaxonomyCodes: []string{"207Q00000X", "207R00000X"}, FromIdentifier: true}},
+		"NV": {{Number: "NV-1", TaxonomyCodes: []string{"207Q00000X"}}},
+		"OR": {{Number: "OR-77", FromIdentifier: true}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LicenseMatrix() = %+v, want %+v", got, want)
+	}
+	if states := got.States(); !reflect.DeepEqual(states, []string{"CA", "NV", "OR"}) {
+		t.Errorf("States() = %v", states)
+	}
+	if conflicts := got.Conflicts(); conflicts != nil {
+		t.Errorf("Conflicts() = %+v, want none", conflicts)
+	}
+}
+
+// TestStateLicenses_Conflicts tests detecting several numbers in one state.
+func TestStateLicenses_Conflicts(t *testing.T) {
+	p := mockProvider()
+	p.Taxonomies = []Taxonomy{
+		{Code: "163W00000X", State: "TX", License: "RN100"},
+		{Code: "363L00000X", State: "TX", License: "NP200"},
+		{Code: "163W00000X", State: "OK", License: "R1"},
+	}
+	p.Identifiers = []Identifier{{Desc: "STATE LICENSE", Identifier: "R2", State: "OK"}}
+
+	want := []LicenseConflict{
+		{State: "OK", Numbers: []string{"R1", "R2"}},
+		{State: "TX", Numbers: []string{"RN100", "NP200"}},
+	}
+	if got := LicenseMatrix(p).Conflicts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts() = %+v, want %+v", got, want)
+	}
+}
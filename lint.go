@@ -0,0 +1,315 @@
+package gonpi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity ranks how serious a lint finding is.
+type Severity string
+
+// Finding severities, from least to most serious.
+const (
+	// SeverityInfo is worth knowing but needs no action.
+	SeverityInfo Severity = "info"
+
+	// SeverityWarning is a likely data-quality problem.
+	SeverityWarning Severity = "warning"
+
+	// SeverityError is a record that is unusable for most purposes as is.
+	SeverityError Severity = "error"
+)
+
+// rank orders severities; unknown severities rank lowest.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityError:
+		return 3
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as serious as min or more.
+func (s Severity) AtLeast(min Severity) bool {
+	return s.rank() >= min.rank()
+}
+
+// LintRule is one check run by a Linter.
+type LintRule struct {
+	// Name identifies the rule in findings, such as "missing_location".
+	Name string
+
+	// Severity is the severity of the rule's findings.
+	Severity Severity
+
+	// Check returns a message for each problem found in the provider, or none if the
+	// provider passes.
+	Check func(Provider) []string
+}
+
+// PredicateRule returns a rule reporting message for every provider that fails
+// predicate, for user-defined checks.
+//
+// Example usage:
+//
+//	rule := gonpi.PredicateRule("has_endpoint", gonpi.SeverityInfo, "no electronic endpoint listed",
+//	    func(p gonpi.Provider) bool { return len(p.Endpoints) > 0 })
+func PredicateRule(name string, severity Severity, message string, predicate func(Provider) bool) LintRule {
+	return LintRule{
+		Name:     name,
+		Severity: severity,
+		Check: func(p Provider) []string {
+			if predicate(p) {
+				return nil
+			}
+			return []string{message}
+		},
+	}
+}
+
+// Finding is one problem reported by a LintRule.
+type Finding struct {
+	NPI      string   `json:"npi"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String returns the finding as "NPI severity rule: message".
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s %s: %s", f.NPI, f.Severity, f.Rule, f.Message)
+}
+
+// LintReport summarizes linting a set of providers. It encodes to JSON as-is.
+type LintReport struct {
+	// Providers is the number of providers linted, and Flagged the number with at
+	// least one finding.
+	Providers int `json:"providers"`
+	Flagged   int `json:"flagged"`
+
+	// Counts is the number of findings per severity.
+	Counts map[Severity]int `json:"counts"`
+
+	// Rules is the number of findings per rule.
+	Rules map[string]int `json:"rules"`
+
+	// Findings are every finding, in provider order and then rule order.
+	Findings []Finding `json:"findings"`
+}
+
+// Linter evaluates providers against a set of rules. It is safe for concurrent use
+// if its rules are.
+type Linter struct {
+	rules []LintRule
+}
+
+// NewLinter returns a linter running rules, or DefaultLintRules if none are given.
+// Add user-defined rules to the defaults with
+// NewLinter(append(gonpi.DefaultLintRules(), rules...)...).
+func NewLinter(rules ...LintRule) *Linter {
+	if len(rules) == 0 {
+		rules = DefaultLintRules()
+	}
+	return &Linter{rules: rules}
+}
+
+// Rules returns the rules the linter runs.
+func (l *Linter) Rules() []LintRule {
+	return append([]LintRule(nil), l.rules...)
+}
+
+// Lint returns the provider's findings, in rule order.
+func (l *Linter) Lint(p Provider) []Finding {
+	var findings []Finding
+	for _, rule := range l.rules {
+		for _, message := range rule.Check(p) {
+			findings = append(findings, Finding{NPI: p.Number, Rule: rule.Name, Severity: rule.Severity, Message: message})
+		}
+	}
+	return findings
+}
+
+// Report lints every provider and summarizes the findings.
+//
+// Example usage:
+//
+//	report := gonpi.NewLinter().Report(providers)
+//	json.NewEncoder(os.Stdout).Encode(report)
+func (l *Linter) Report(providers []Provider) LintReport {
+	report := LintReport{
+		Providers: len(providers),
+		Counts:    make(map[Severity]int),
+		Rules:     make(map[string]int),
+		Findings:  []Finding{},
+	}
+	for _, p := range providers {
+		findings := l.Lint(p)
+		if len(findings) > 0 {
+			report.Flagged++
+		}
+		for _, f := range findings {
+			report.Counts[f.Severity]++
+			report.Rules[f.Rule]++
+		}
+		report.Findings = append(report.Findings, findings...)
+	}
+	return report
+}
+
+// Linted matches providers with at least one finding of severity min or higher.
+func Linted(l *Linter, min Severity) ProviderFilter {
+	return func(p Provider) bool {
+		for _, f := range l.Lint(p) {
+			if f.Severity.AtLeast(min) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Built-in rule names.
+const (
+	RuleInvalidNPI           = "invalid_npi"
+	RuleMissingName          = "missing_name"
+	RuleNoTaxonomy           = "no_taxonomy"
+	RuleNoPrimaryTaxonomy    = "no_primary_taxonomy"
+	RuleMissingLocation      = "missing_location"
+	RuleIncompleteAddress    = "incomplete_address"
+	RuleInvalidPostalCode    = "invalid_postal_code"
+	RuleTaxonomyLicenseState = string(CheckTaxonomyLicenseState)
+	RuleIdentifierState      = string(CheckIdentifierState)
+	RuleLicenseConflict      = "license_conflict"
+	RuleDeactivated          = "deactivated"
+)
+
+// DefaultLintRules returns the built-in rules: NPI format and check digit, missing
+// names and taxonomies, practice location and address completeness, postal code
+// format, the ConsistencyIssues checks, LicenseMatrix conflicts, and deactivation.
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{Name: RuleInvalidNPI, Severity: SeverityError, Check: func(p Provider) []string {
+			npi, err := NormalizeNPI(p.Number)
+			switch {
+			case err != nil:
+				return []string{err.Error()}
+			case !ValidCheckDigit(npi):
+				return []string{fmt.Sprintf("NPI %s fails its check digit", npi)}
+			}
+			return nil
+		}},
+		PredicateRule(RuleMissingName, SeverityError, "record has no name",
+			func(p Provider) bool { return p.DisplayName() != "" }),
+		PredicateRule(RuleNoTaxonomy, SeverityError, "record lists no taxonomy",
+			func(p Provider) bool { return len(p.Taxonomies) > 0 }),
+		PredicateRule(RuleNoPrimaryTaxonomy, SeverityWarning, "no taxonomy is marked primary",
+			func(p Provider) bool {
+				for _, taxonomy := range p.Taxonomies {
+					if taxonomy.Primary {
+						return true
+					}
+				}
+				return len(p.Taxonomies) == 0
+			}),
+		PredicateRule(RuleMissingLocation, SeverityWarning, "record has no practice location address",
+			func(p Provider) bool {
+				for _, address := range p.Addresses {
+					if strings.EqualFold(address.AddressPurpose, "LOCATION") {
+						return true
+					}
+				}
+				return false
+			}),
+		{Name: RuleIncompleteAddress, Severity: SeverityWarning, Check: lintAddresses(func(a Address) string {
+			var missing []string
+			for _, field := range []struct{ name, value string }{
+				{"street", a.Address1}, {"city", a.City}, {"state", a.State}, {"postal code", a.PostalCode},
+			} {
+				if strings.TrimSpace(field.value) == "" && (field.name != "state" || isUS(a.CountryCode)) {
+					missing = append(missing, field.name)
+				}
+			}
+			if len(missing) == 0 {
+				return ""
+			}
+			return "missing " + strings.Join(missing, ", ")
+		})},
+		{Name: RuleInvalidPostalCode, Severity: SeverityWarning, Check: lintAddresses(func(a Address) string {
+			code, country := strings.TrimSpace(a.PostalCode), CountryCode(a.CountryCode).normalize()
+			switch {
+			case code == "" || country.ValidPostalCode(code):
+				return ""
+			case country.USPostal():
+				return fmt.Sprintf("postal code %q is not a 5 or 9 digit ZIP code", code)
+			}
+			return fmt.Sprintf("postal code %q is not valid for %s", code, country)
+		})},
+		consistencyRule(RuleTaxonomyLicenseState, CheckTaxonomyLicenseState),
+		consistencyRule(RuleIdentifierState, CheckIdentifierState),
+		{Name: RuleLicenseConflict, Severity: SeverityWarning, Check: func(p Provider) []string {
+			var messages []string
+			for _, conflict := range LicenseMatrix(p).Conflicts() {
+				messages = append(messages, fmt.Sprintf("%s lists licenses %s", conflict.State, strings.Join(conflict.Numbers, ", ")))
+			}
+			return messages
+		}},
+		PredicateRule(RuleDeactivated, SeverityInfo, "NPI is deactivated",
+			func(p Provider) bool { return p.Basic.Status != "D" }),
+	}
+}
+
+// consistencyRule returns a warning rule reporting the ConsistencyIssues of check.
+func consistencyRule(name string, check ConsistencyCheck) LintRule {
+	return LintRule{Name: name, Severity: SeverityWarning, Check: func(p Provider) []string {
+		var messages []string
+		for _, issue := range p.ConsistencyIssues() {
+			if issue.Check == check {
+				messages = append(messages, issue.Message)
+			}
+		}
+		return messages
+	}}
+}
+
+// lintAddresses returns a check applying problem to each address, prefixing its
+// non-empty results with the address purpose.
+func lintAddresses(problem func(Address) string) func(Provider) []string {
+	return func(p Provider) []string {
+		var messages []string
+		for _, address := range p.Addresses {
+			if message := problem(address); message != "" {
+				purpose := strings.ToLower(address.AddressPurpose)
+				if purpose == "" {
+					purpose = "unlabeled"
+				}
+				messages = append(messages, purpose+" address: "+message)
+			}
+		}
+		return messages
+	}
+}
+
+// isUS reports whether the country code is the US or unset.
+func isUS(code string) bool {
+	code = strings.TrimSpace(code)
+	return code == "" || strings.EqualFold(code, "US")
+}
+
+// SortFindings orders findings by descending severity, then NPI and rule.
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity.rank() != b.Severity.rank() {
+			return a.Severity.rank() > b.Severity.rank()
+		}
+		if a.NPI != b.NPI {
+			return a.NPI < b.NPI
+		}
+		return a.Rule < b.Rule
+	})
+}
@@ -0,0 +1,88 @@
+package gonpi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// lintRules returns the rule names of findings.
+func lintRules(findings []Finding) string {
+	names := make([]string, len(findings))
+	for i, f := range findings {
+		names[i] = f.Rule
+	}
+	return strings.Join(names, ",")
+}
+
+// TestLinter_DefaultRules tests the built-in rules against clean and broken records.
+func TestLinter_DefaultRules(t *testing.T) {
+	clean := Provider{
+		Number:          "1234567893",
+		EnumerationType: "NPI-1",
+		Basic:           BasicInfo{FirstName: "JANE", LastName: "DOE", Status: "A"},
+		Addresses: []Address{
+			{AddressPurpose: "LOCATION", Address1: "1 MAIN ST", City: "BOSTON", State: "MA", PostalCode: "021391234", CountryCode: "US"},
+			{AddressPurpose: "MAILING", Address1: "PO BOX 1", City: "TORONTO", PostalCode: "M5V 2T6", CountryCode: "CA"},
+		},
+		Taxonomies: []Taxonomy{{Code: "207Q00000X", Primary: true, State: "MA", License: "12345"}},
+	}
+	linter := NewLinter()
+	if findings := linter.Lint(clean); len(findings) != 0 {
+		t.Errorf("expected no findings for a clean record, got %v", findings)
+	}
+
+	broken := clean
+	broken.Number = "1234567890"
+	broken.Basic.Status = "D"
+	broken.Addresses = []Address{{AddressPurpose: "MAILING", City: "BOSTON", State: "MA", PostalCode: "0213"}}
+	broken.Taxonomies = []Taxonomy{
+		{Code: "207Q00000X", State: "NY", License: "1"},
+		{Code: "207R00000X", State: "NY", License: "2"},
+	}
+	want := "invalid_npi,no_primary_taxonomy,missing_location,incomplete_address,invalid_postal_code,license_conflict,deactivated"
+	if got := lintRules(linter.Lint(broken)); got != want {
+		t.Errorf("rules = %s, want %s", got, want)
+	}
+
+	located := broken
+	located.Addresses = []Address{{AddressPurpose: "LOCATION", Address1: "1 MAIN ST", City: "BOSTON", State: "MA", PostalCode: "02139"}}
+	findings := NewLinter(consistencyRule(RuleTaxonomyLicenseState, CheckTaxonomyLicenseState)).Lint(located)
+	if len(findings) != 2 || findings[0].Severity != SeverityWarning || !strings.Contains(findings[0].Message, "NY") {
+		t.Errorf("unexpected consistency findings: %v", findings)
+	}
+}
+
+// TestLinter_Report tests user-defined rules, severity filtering and the JSON report.
+func TestLinter_Report(t *testing.T) {
+	endpoint := PredicateRule("has_endpoint", SeverityInfo, "no endpoint", func(p Provider) bool { return len(p.Endpoints) > 0 })
+	named := PredicateRule(RuleMissingName, SeverityError, "no name", func(p Provider) bool { return p.DisplayName() != "" })
+	linter := NewLinter(endpoint, named)
+
+	providers := []Provider{
+		{Number: "1", Basic: BasicInfo{FirstName: "A", LastName: "B"}, Endpoints: []Endpoint{{}}},
+		{Number: "2", Basic: BasicInfo{FirstName: "A", LastName: "B"}},
+		{Number: "3"},
+	}
+	report := linter.Report(providers)
+	if report.Providers != 3 || report.Flagged != 2 || len(report.Findings) != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.Counts[SeverityInfo] != 2 || report.Counts[SeverityError] != 1 || report.Rules["has_endpoint"] != 2 {
+		t.Errorf("unexpected counts: %+v %+v", report.Counts, report.Rules)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil || !strings.Contains(string(data), `"severity":"error"`) || !strings.Contains(string(data), `"counts":{`) {
+		t.Errorf("unexpected JSON: %s %v", data, err)
+	}
+
+	if got := FilterProviders(providers, Linted(linter, SeverityError)); len(got) != 1 || got[0].Number != "3" {
+		t.Errorf("Linted(error) = %v", got)
+	}
+
+	SortFindings(report.Findings)
+	if report.Findings[0].Severity != SeverityError {
+		t.Errorf("expected errors first, got %v", report.Findings)
+	}
+}
@@ -0,0 +1,236 @@
+package gonpi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// manifestFormat identifies export manifests.
+const manifestFormat = "gonpi-export-manifest"
+
+// ManifestVersion is the version of the manifest encoding written by Manifest.WriteTo.
+const ManifestVersion = 1
+
+// ErrInvalidManifest indicates that ReadManifest was given data that is not a
+// readable export manifest.
+var ErrInvalidManifest = errors.New("invalid export manifest")
+
+// ErrManifestMismatch indicates that an export's files do not match its manifest.
+var ErrManifestMismatch = errors.New("export does not match manifest")
+
+// Manifest describes an export job, so downstream consumers can check that they
+// received every file intact and where the data came from. Manifests are
+// deterministic: files are listed by name and parameters by key, and times are UTC, so
+// two exports of the same data produce manifests that differ only in their timestamps.
+// A Manifest is not safe for concurrent use.
+//
+// Example usage:
+//
+//	manifest := gonpi.NewManifest("csv")
+//	manifest.SetSearch(opts)
+//	manifest.Source = gonpi.DefaultBaseURL
+//	out := manifest.AddFile("cardiologists.csv", f)
+//	writer := csv.NewWriter(out)
+//	retrievals := gonpi.NewRetrievals()
+//	for provider, err := range client.SearchAll(gonpi.ContextWithCallOptions(ctx, gonpi.WithRetrievals(retrievals)), opts) {
+//	    ...
+//	    writer.Write(row(provider))
+//	    out.Record(retrievals.Meta(provider.Number))
+//	}
+//	writer.Flush()
+//	manifest.Complete()
+//	manifest.WriteTo(manifestFile)
+type Manifest struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+
+	// Export is the format of the exported files, such as "csv", "ndjson" or
+	// "parquet".
+	Export string `json:"export"`
+
+	// Parameters are the query parameters of the export, such as those set by
+	// SetSearch.
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// Source identifies where the data came from, such as the API base URL, a store
+	// snapshot or an NPPES dissemination file.
+	Source string `json:"source,omitempty"`
+
+	// SourceVersion identifies the version of the source data, such as the NPPES
+	// release or the snapshot's creation time.
+	SourceVersion string `json:"source_version,omitempty"`
+
+	// FetchedFrom and FetchedTo are the oldest and newest fetch times of the recorded
+	// providers that came from the API.
+	FetchedFrom time.Time `json:"fetched_from,omitzero"`
+	FetchedTo   time.Time `json:"fetched_to,omitzero"`
+
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at,omitzero"`
+
+	// Records is the number of records in all files.
+	Records int64 `json:"records"`
+
+	Files []ManifestFile `json:"files"`
+
+	writers []*ManifestWriter
+}
+
+// ManifestFile describes one exported file.
+type ManifestFile struct {
+	// Name is the file's path relative to the manifest.
+	Name string `json:"name"`
+
+	Records int64  `json:"records"`
+	Bytes   int64  `json:"bytes"`
+	SHA256  string `json:"sha256"`
+}
+
+// NewManifest starts the manifest of an export in the given format.
+func NewManifest(export string) *Manifest {
+	return &Manifest{
+		Format:    manifestFormat,
+		Version:   ManifestVersion,
+		Export:    export,
+		StartedAt: time.Now().UTC(),
+	}
+}
+
+// SetSearch records the non-zero fields of opts as parameters, keyed by field name as
+// in preset files.
+func (m *Manifest) SetSearch(opts SearchOptions) {
+	v := reflect.ValueOf(opts)
+	for i := range v.NumField() {
+		field := v.Field(i)
+		if !v.Type().Field(i).IsExported() || field.IsZero() {
+			continue
+		}
+		if m.Parameters == nil {
+			m.Parameters = make(map[string]string)
+		}
+		m.Parameters[v.Type().Field(i).Name] = fmt.Sprint(field.Interface())
+	}
+}
+
+// AddFile adds the file name to the manifest and returns a writer that counts and
+// hashes what is written through it to w.
+func (m *Manifest) AddFile(name string, w io.Writer) *ManifestWriter {
+	mw := &ManifestWriter{manifest: m, name: name, w: w, hash: sha256.New()}
+	m.writers = append(m.writers, mw)
+	return mw
+}
+
+// Complete fills in the files added with AddFile and the completion time. Call it
+// once every file has been written and flushed.
+func (m *Manifest) Complete() {
+	for _, w := range m.writers {
+		m.Files = append(m.Files, ManifestFile{
+			Name:    w.name,
+			Records: w.records,
+			Bytes:   w.bytes,
+			SHA256:  hex.EncodeToString(w.hash.Sum(nil)),
+		})
+		m.Records += w.records
+	}
+	m.writers = nil
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Name < m.Files[j].Name })
+	m.CompletedAt = time.Now().UTC()
+}
+
+// WriteTo writes the manifest to w as indented JSON.
+func (m *Manifest) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Verify checks that every file of the manifest is in fsys with the recorded size and
+// SHA-256 digest, such as with os.DirFS(dir) for the manifest's directory. Failures
+// are reported together and match ErrManifestMismatch.
+func (m *Manifest) Verify(fsys fs.FS) error {
+	var errs []error
+	for _, file := range m.Files {
+		f, err := fsys.Open(file.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrManifestMismatch, file.Name, err))
+			continue
+		}
+		h := sha256.New()
+		n, err := io.Copy(h, f)
+		f.Close()
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", file.Name, err))
+		case n != file.Bytes:
+			errs = append(errs, fmt.Errorf("%w: %s has %d bytes, want %d", ErrManifestMismatch, file.Name, n, file.Bytes))
+		case hex.EncodeToString(h.Sum(nil)) != file.SHA256:
+			errs = append(errs, fmt.Errorf("%w: %s has a different SHA-256 digest", ErrManifestMismatch, file.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReadManifest reads a manifest written by Manifest.WriteTo. Malformed input and
+// manifests from a newer version are reported as ErrInvalidManifest.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	if m.Format != manifestFormat {
+		return nil, fmt.Errorf("%w: unexpected format %q", ErrInvalidManifest, m.Format)
+	}
+	if m.Version > ManifestVersion {
+		return nil, fmt.Errorf("%w: version %d is newer than supported version %d", ErrInvalidManifest, m.Version, ManifestVersion)
+	}
+	return &m, nil
+}
+
+// ManifestWriter writes one file of an export, counting and hashing its bytes for the
+// manifest.
+type ManifestWriter struct {
+	manifest *Manifest
+	name     string
+	w        io.Writer
+	hash     hash.Hash
+	bytes    int64
+	records  int64
+}
+
+// Write writes p to the underlying writer, counting and hashing the bytes written.
+func (w *ManifestWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.hash.Write(p[:n])
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Record counts a record written to the file and notes when it was fetched, from the
+// RetrievalMeta recorded by WithRetrievals.
+func (w *ManifestWriter) Record(meta RetrievalMeta) {
+	w.records++
+	fetched := meta.FetchedAt
+	if fetched.IsZero() {
+		return
+	}
+	fetched = fetched.UTC()
+	m := w.manifest
+	if m.FetchedFrom.IsZero() || fetched.Before(m.FetchedFrom) {
+		m.FetchedFrom = fetched
+	}
+	if fetched.After(m.FetchedTo) {
+		m.FetchedTo = fetched
+	}
+}
@@ -0,0 +1,89 @@
+package gonpi
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestManifest tests writing, reading and verifying an export manifest.
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := NewManifest("ndjson")
+	manifest.SetSearch(SearchOptions{State: "CA", TaxonomyDescription: "Cardiology", MaxResults: 500})
+	manifest.Source = DefaultBaseURL
+
+	fetched := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	var buffers [2]bytes.Buffer
+	for i, name := range []string{"part-2.ndjson", "part-1.ndjson"} {
+		w := manifest.AddFile(name, &buffers[i])
+		for j := range 3 {
+			p := mockProvider()
+			w.Write([]byte(p.Number + "\n"))
+			w.Record(RetrievalMeta{Source: SourceAPI, FetchedAt: fetched.Add(time.Duration(i*3+j) * time.Minute)})
+		}
+		w.Record(RetrievalMeta{})
+		os.WriteFile(filepath.Join(dir, name), buffers[i].Bytes(), 0o644)
+	}
+	manifest.Complete()
+
+	if manifest.Records != 8 || len(manifest.Files) != 2 || manifest.Files[0].Name != "part-1.ndjson" {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	if manifest.Files[0].Bytes != 33 || len(manifest.Files[0].SHA256) != 64 {
+		t.Errorf("unexpected file: %+v", manifest.Files[0])
+	}
+	if !manifest.FetchedFrom.Equal(fetched) || !manifest.FetchedTo.Equal(fetched.Add(5*time.Minute)) {
+		t.Errorf("fetched range = %s - %s", manifest.FetchedFrom, manifest.FetchedTo)
+	}
+	want := map[string]string{"State": "CA", "TaxonomyDescription": "Cardiology", "MaxResults": "500"}
+	if len(manifest.Parameters) != len(want) {
+		t.Errorf("parameters = %v, want %v", manifest.Parameters, want)
+	}
+	for key, value := range want {
+		if manifest.Parameters[key] != value {
+			t.Errorf("parameter %s = %q, want %q", key, manifest.Parameters[key], value)
+		}
+	}
+
+	var encoded bytes.Buffer
+	if _, err := manifest.WriteTo(&encoded); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadManifest(bytes.NewReader(encoded.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	var again bytes.Buffer
+	read.WriteTo(&again)
+	if again.String() != encoded.String() {
+		t.Errorf("manifest changed on round trip:\n%s\nwant:\n%s", again.String(), encoded.String())
+	}
+	if err := read.Verify(os.DirFS(dir)); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "part-1.ndjson"), []byte("tampered\n"), 0o644)
+	os.Remove(filepath.Join(dir, "part-2.ndjson"))
+	err = read.Verify(os.DirFS(dir))
+	if !errors.Is(err, ErrManifestMismatch) || !strings.Contains(err.Error(), "part-1.ndjson has 9 bytes") || !strings.Contains(err.Error(), "part-2.ndjson") {
+		t.Errorf("expected mismatches for both files, got %v", err)
+	}
+}
+
+// TestReadManifest_Invalid tests rejecting input that is not a supported manifest.
+func TestReadManifest_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"not json",
+		`{"format":"gonpi-store-snapshot","version":1}`,
+		`{"format":"gonpi-export-manifest","version":99}`,
+	} {
+		if _, err := ReadManifest(strings.NewReader(input)); !errors.Is(err, ErrInvalidManifest) {
+			t.Errorf("%s: expected ErrInvalidManifest, got %v", input, err)
+		}
+	}
+}
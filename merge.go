@@ -0,0 +1,414 @@
+package gonpi
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultMergeMemory is the number of providers a Merger holds in memory before it
+// spills them to disk.
+const DefaultMergeMemory = 100_000
+
+// ErrMergerClosed is returned when adding providers to a closed Merger.
+var ErrMergerClosed = errors.New("merger is closed")
+
+// MergeOption configures a Merger.
+type MergeOption func(*Merger)
+
+// WithMergeMemory sets the number of providers held in memory before a sorted run is
+// spilled to disk. Default: DefaultMergeMemory.
+func WithMergeMemory(n int) MergeOption {
+	return func(m *Merger) {
+		m.memory = n
+	}
+}
+
+// WithMergeDir sets the directory spilled runs are written to. Default: os.TempDir().
+func WithMergeDir(dir string) MergeOption {
+	return func(m *Merger) {
+		m.dir = dir
+	}
+}
+
+// WithMergeCodec sets the codec spilled providers are encoded with. Default:
+// MsgPackCodec.
+func WithMergeCodec(codec Codec) MergeOption {
+	return func(m *Merger) {
+		m.codec = codec
+	}
+}
+
+// MergeStats counts the work done by a Merger.
+type MergeStats struct {
+	// Added is the number of providers added.
+	Added int
+
+	// Duplicates is the number of added providers dropped because another record
+	// with the same NPI was kept. Duplicates in different spilled runs are only
+	// counted once an iteration of All has merged every run.
+	Duplicates int
+
+	// Runs is the number of sorted runs spilled to disk.
+	Runs int
+}
+
+// Merger deduplicates providers gathered from overlapping searches, such as searches
+// sharded by state or name, and returns them in NPI order. When several records share
+// an NPI, the most recently updated one is kept, or the first added if they were
+// updated at the same time.
+//
+// At most WithMergeMemory providers are held in memory. Beyond that, they are sorted
+// and spilled to temporary files, which All merges back, so extracts larger than
+// memory can be deduplicated. Spilled providers lose their Meta. Close removes the
+// files. A Merger is safe for concurrent use.
+//
+// Example usage:
+//
+//	merger := gonpi.NewMerger(gonpi.WithMergeDir(scratch))
+//	defer merger.Close()
+//	for _, state := range states {
+//	    for provider, err := range client.SearchAll(ctx, gonpi.SearchOptions{State: state, TaxonomyDescription: "Cardiology"}) {
+//	        ...
+//	        merger.Add(provider)
+//	    }
+//	}
+//	for provider, err := range merger.All() {
+//	    ...
+//	}
+type Merger struct {
+	memory int
+	dir    string
+	codec  Codec
+
+	mu     sync.Mutex
+	buffer map[string]Provider
+	runs   []string
+	stats  MergeStats
+	merged int // duplicates across runs found by the last complete merge
+	closed bool
+}
+
+// NewMerger creates an empty Merger.
+func NewMerger(opts ...MergeOption) *Merger {
+	m := &Merger{memory: DefaultMergeMemory, codec: MsgPackCodec{}}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.memory = max(1, m.memory)
+	m.buffer = make(map[string]Provider)
+	return m
+}
+
+// Add adds providers to the merger. It returns a ValidationError for a provider
+// without an NPI, and an error if a run could not be spilled.
+func (m *Merger) Add(providers ...Provider) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrMergerClosed
+	}
+	for _, provider := range providers {
+		if provider.Number == "" {
+			return &ValidationError{Field: "number", Message: "provider has no NPI"}
+		}
+		m.stats.Added++
+		if kept, ok := m.buffer[provider.Number]; ok {
+			m.stats.Duplicates++
+			if newer(&provider, &kept) {
+				m.buffer[provider.Number] = provider
+			}
+			continue
+		}
+		m.buffer[provider.Number] = provider
+		if len(m.buffer) >= m.memory {
+			if err := m.spill(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stats returns the merger's counters.
+func (m *Merger) Stats() MergeStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Duplicates += m.merged
+	return stats
+}
+
+// All returns an iterator over the deduplicated providers in NPI order. Iteration
+// stops after the first error, which is yielded with a zero Provider. Providers
+// added while iterating are not seen until the next call.
+func (m *Merger) All() iter.Seq2[Provider, error] {
+	return func(yield func(Provider, error) bool) {
+		m.mu.Lock()
+		if m.closed {
+			m.mu.Unlock()
+			yield(Provider{}, ErrMergerClosed)
+			return
+		}
+		sources := make([]mergeSource, 0, len(m.runs)+1)
+		for _, path := range m.runs {
+			f, err := os.Open(path)
+			if err != nil {
+				m.mu.Unlock()
+				closeSources(sources)
+				yield(Provider{}, fmt.Errorf("failed to open merge run: %w", err))
+				return
+			}
+			sources = append(sources, &runSource{file: f, reader: bufio.NewReader(f), codec: m.codec})
+		}
+		sources = append(sources, &sliceSource{providers: m.sorted()})
+		m.mu.Unlock()
+		defer closeSources(sources)
+
+		heads := make([]*Provider, len(sources))
+		advance := func(i int) error {
+			var err error
+			heads[i], err = sources[i].next()
+			return err
+		}
+		for i := range sources {
+			if err := advance(i); err != nil {
+				yield(Provider{}, err)
+				return
+			}
+		}
+
+		duplicates := 0
+		for {
+			// Runs are few, so the smallest head is found by scanning, earliest
+			// source first so that ties keep the first record added
+			first := -1
+			for i, head := range heads {
+				if head != nil && (first < 0 || head.Number < heads[first].Number) {
+					first = i
+				}
+			}
+			if first < 0 {
+				m.mu.Lock()
+				m.merged = duplicates
+				m.mu.Unlock()
+				return
+			}
+			kept := *heads[first]
+			for i, head := range heads {
+				if head == nil || head.Number != kept.Number {
+					continue
+				}
+				if i != first {
+					duplicates++
+					if newer(head, &kept) {
+						kept = *head
+					}
+				}
+				if err := advance(i); err != nil {
+					yield(Provider{}, err)
+					return
+				}
+			}
+			if !yield(kept, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close removes the spilled runs. The merger cannot be used afterwards.
+func (m *Merger) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for _, path := range m.runs {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	m.runs, m.buffer, m.closed = nil, nil, true
+	return errors.Join(errs...)
+}
+
+// sorted returns the buffered providers in NPI order. The caller must hold m.mu.
+func (m *Merger) sorted() []Provider {
+	providers := make([]Provider, 0, len(m.buffer))
+	for _, provider := range m.buffer {
+		providers = append(providers, provider)
+	}
+	slices.SortFunc(providers, func(a, b Provider) int {
+		return strings.Compare(a.Number, b.Number)
+	})
+	return providers
+}
+
+// spill writes the buffered providers to a new run in NPI order, each prefixed with
+// its encoded length. The caller must hold m.mu.
+func (m *Merger) spill() error {
+	f, err := os.CreateTemp(m.dir, "gonpi-merge-*")
+	if err != nil {
+		return fmt.Errorf("failed to create merge run: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	var prefix [binary.MaxVarintLen64]byte
+	for _, provider := range m.sorted() {
+		data, err := m.codec.Marshal(&provider)
+		if err != nil {
+			err = fmt.Errorf("failed to encode provider %s: %w", provider.Number, err)
+		} else if _, err = w.Write(prefix[:binary.PutUvarint(prefix[:], uint64(len(data)))]); err == nil {
+			_, err = w.Write(data)
+		}
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return err
+		}
+	}
+	if err := errors.Join(w.Flush(), f.Close()); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write merge run: %w", err)
+	}
+	m.runs = append(m.runs, f.Name())
+	m.stats.Runs++
+	clear(m.buffer)
+	return nil
+}
+
+// newer reports whether a was updated after b.
+func newer(a, b *Provider) bool {
+	if a.LastUpdatedEpoch != b.LastUpdatedEpoch {
+		return a.LastUpdatedEpoch > b.LastUpdatedEpoch
+	}
+	return a.LastUpdated > b.LastUpdated
+}
+
+// mergeSource yields providers in NPI order, returning nil when exhausted.
+type mergeSource interface {
+	next() (*Provider, error)
+}
+
+// sliceSource yields sorted providers held in memory.
+type sliceSource struct {
+	providers []Provider
+}
+
+func (s *sliceSource) next() (*Provider, error) {
+	if len(s.providers) == 0 {
+		return nil, nil
+	}
+	provider := &s.providers[0]
+	s.providers = s.providers[1:]
+	return provider, nil
+}
+
+// runSource reads a spilled run.
+type runSource struct {
+	file   *os.File
+	reader *bufio.Reader
+	codec  Codec
+	buf    []byte
+}
+
+func (s *runSource) next() (*Provider, error) {
+	size, err := binary.ReadUvarint(s.reader)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err == nil {
+		s.buf = slices.Grow(s.buf[:0], int(size))[:size]
+		_, err = io.ReadFull(s.reader, s.buf)
+	}
+	var provider Provider
+	if err == nil {
+		err = s.codec.Unmarshal(s.buf, &provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merge run: %w", err)
+	}
+	return &provider, nil
+}
+
+// closeSources closes the files of spilled runs.
+func closeSources(sources []mergeSource) {
+	for _, source := range sources {
+		if run, ok := source.(*runSource); ok {
+			run.file.Close()
+		}
+	}
+}
+
+// SearchMerged runs SearchAll for each shard and returns the combined results
+// deduplicated and in NPI order, using a Merger configured with opts. Shards may
+// overlap, for example a search by state and one by city within it. Results are
+// yielded once every shard has been searched; iteration stops after the first error,
+// which is yielded with a zero Provider.
+//
+// Example usage:
+//
+//	shards := []gonpi.SearchOptions{{State: "MA", LastName: "Smith"}, {City: "Boston", LastName: "Smith"}}
+//	for provider, err := range client.SearchMerged(ctx, shards, gonpi.WithMergeMemory(50_000)) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    writer.Write(provider)
+//	}
+func (c *Client) SearchMerged(ctx context.Context, shards []SearchOptions, opts ...MergeOption) iter.Seq2[Provider, error] {
+	return func(yield func(Provider, error) bool) {
+		ctx, span := c.tracer.Start(ctx, "SearchMerged",
+			trace.WithAttributes(c.traceAttrs(attribute.Int("shards", len(shards)))...),
+		)
+		defer span.End()
+
+		merger := NewMerger(opts...)
+		defer merger.Close()
+		defer func() {
+			stats := merger.Stats()
+			span.SetAttributes(c.traceAttrs(
+				attribute.Int("added", stats.Added),
+				attribute.Int("duplicates", stats.Duplicates),
+				attribute.Int("runs", stats.Runs),
+			)...)
+		}()
+		fail := func(err error) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "merged search failed")
+			yield(Provider{}, err)
+		}
+
+		for _, shard := range shards {
+			for provider, err := range c.SearchAll(ctx, shard) {
+				if err == nil {
+					err = merger.Add(provider)
+				}
+				if err != nil {
+					fail(err)
+					return
+				}
+			}
+		}
+		for provider, err := range merger.All() {
+			if err != nil {
+				fail(err)
+				return
+			}
+			if !yield(provider, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// mergeProvider returns a provider with the given NPI and update time.
+func mergeProvider(npi string, updated FlexInt, lastName string) Provider {
+	return Provider{Number: npi, LastUpdatedEpoch: updated, Basic: BasicInfo{LastName: lastName}}
+}
+
+// collect returns the providers yielded by seq, failing on errors.
+func collect(t *testing.T, seq func(func(Provider, error) bool)) []Provider {
+	t.Helper()
+	var providers []Provider
+	for provider, err := range seq {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// TestMerger tests deduplication and ordering across spilled runs and memory.
+func TestMerger(t *testing.T) {
+	dir := t.TempDir()
+	merger := NewMerger(WithMergeMemory(3), WithMergeDir(dir))
+
+	err := merger.Add(
+		mergeProvider("1000000005", 1, "OLD"),
+		mergeProvider("1000000001", 1, "FIRST"),
+		mergeProvider("1000000003", 1, "A"),
+		// Spilled here
+		mergeProvider("1000000002", 1, "B"),
+		mergeProvider("1000000005", 2, "NEW"),
+		mergeProvider("1000000005", 1, "STALE"),
+		mergeProvider("1000000004", 1, "C"),
+		// Spilled here
+		mergeProvider("1000000001", 1, "SECOND"),
+		mergeProvider("1000000006", 1, "D"),
+	)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	providers := collect(t, merger.All())
+	want := []struct{ npi, name string }{
+		{"1000000001", "FIRST"}, {"1000000002", "B"}, {"1000000003", "A"},
+		{"1000000004", "C"}, {"1000000005", "NEW"}, {"1000000006", "D"},
+	}
+	if len(providers) != len(want) {
+		t.Fatalf("got %d providers: %+v", len(providers), providers)
+	}
+	for i, w := range want {
+		if providers[i].Number != w.npi || providers[i].Basic.LastName != w.name {
+			t.Errorf("provider %d = %s %s, want %s %s", i, providers[i].Number, providers[i].Basic.LastName, w.npi, w.name)
+		}
+	}
+	if stats := merger.Stats(); stats.Added != 9 || stats.Duplicates != 3 || stats.Runs != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// All can be repeated
+	if again := collect(t, merger.All()); len(again) != len(want) {
+		t.Errorf("second pass returned %d providers", len(again))
+	}
+	if stats := merger.Stats(); stats.Duplicates != 3 {
+		t.Errorf("duplicates counted twice: %+v", stats)
+	}
+
+	if err := merger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("runs left behind: %v", entries)
+	}
+}
+
+// TestMerger_Errors tests invalid providers, corrupted runs and use after Close.
+func TestMerger_Errors(t *testing.T) {
+	dir := t.TempDir()
+	merger := NewMerger(WithMergeMemory(1), WithMergeDir(dir), WithMergeCodec(JSONCodec{}))
+	defer merger.Close()
+
+	var validationErr *ValidationError
+	if err := merger.Add(Provider{}); !errors.As(err, &validationErr) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+	if err := merger.Add(mergeProvider("1000000001", 1, "A")); err != nil {
+		t.Fatal(err)
+	}
+	runs, _ := filepath.Glob(filepath.Join(dir, "gonpi-merge-*"))
+	if len(runs) != 1 {
+		t.Fatalf("expected one run, got %v", runs)
+	}
+	os.WriteFile(runs[0], []byte{5, '{'}, 0o600)
+	var got error
+	for _, err := range merger.All() {
+		got = err
+	}
+	if got == nil {
+		t.Error("expected an error reading a corrupted run")
+	}
+
+	merger.Close()
+	if err := merger.Add(mergeProvider("1000000002", 1, "B")); !errors.Is(err, ErrMergerClosed) {
+		t.Errorf("expected ErrMergerClosed, got %v", err)
+	}
+	for _, err := range merger.All() {
+		if !errors.Is(err, ErrMergerClosed) {
+			t.Errorf("expected ErrMergerClosed, got %v", err)
+		}
+	}
+}
+
+// TestSearchMerged tests merging overlapping shard searches.
+func TestSearchMerged(t *testing.T) {
+	shards := map[string][]Provider{
+		"MA": {mergeProvider("1000000003", 1, "C"), mergeProvider("1000000001", 1, "A")},
+		"NH": {mergeProvider("1000000002", 1, "B"), mergeProvider("1000000003", 2, "C2")},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse(shards[r.URL.Query().Get("state")]))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	providers := collect(t, client.SearchMerged(context.Background(),
+		[]SearchOptions{{State: "MA"}, {State: "NH"}}, WithMergeMemory(1), WithMergeDir(t.TempDir())))
+
+	var got []string
+	for _, provider := range providers {
+		got = append(got, provider.Number+" "+provider.Basic.LastName)
+	}
+	want := []string{"1000000001 A", "1000000002 B", "1000000003 C2"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package gonpi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Priority orders requests competing for a rate limit set with WithRateLimit.
+type Priority int
+
+// Request priorities.
+const (
+	// PriorityLow requests wait until no normal or high priority request is queued.
+	PriorityLow Priority = -1
+
+	// PriorityNormal requests queue in arrival order. It is the default.
+	PriorityNormal Priority = 0
+
+	// PriorityHigh requests skip the queue and wait at most one token interval.
+	PriorityHigh Priority = 1
+)
+
+// Request tiers for clients shared by user-facing lookups and background work.
+const (
+	// PriorityInteractive is for user-facing lookups. It is PriorityNormal, the
+	// default.
+	PriorityInteractive = PriorityNormal
+
+	// PriorityBackground is for extracts, syncs and scheduled tasks, which only use
+	// the rate limit capacity that interactive requests leave idle. It is
+	// PriorityLow.
+	PriorityBackground = PriorityLow
+)
+
+// WithDefaultPriority sets the priority of calls whose CallMetadata leaves Priority at
+// PriorityNormal, or that have none. Give background work a derived client that
+// shares the parent's rate limit, so a running extract never delays the interactive
+// lookups of the parent:
+//
+//	extracts := client.With(gonpi.WithDefaultPriority(gonpi.PriorityBackground))
+//	for provider, err := range extracts.SearchAll(ctx, opts) {
+//	    ...
+//	}
+func WithDefaultPriority(p Priority) ClientOption {
+	return func(c *Client) {
+		c.priority = p
+	}
+}
+
+// ContextWithPriority returns a copy of ctx whose CallMetadata has priority p, keeping
+// the rest of any metadata already attached.
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	md, _ := CallMetadataFromContext(ctx)
+	md.Priority = p
+	return ContextWithCallMetadata(ctx, md)
+}
+
+// priorityFor returns the priority of a call with metadata md.
+func (c *Client) priorityFor(md CallMetadata) Priority {
+	if md.Priority == PriorityNormal {
+		return c.priority
+	}
+	return md.Priority
+}
+
+// String returns the lowercase name of the priority.
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	}
+	return "normal"
+}
+
+// CallMetadata describes who is making a call, for shared services that need
+// per-caller accounting. Attach it to a context with ContextWithCallMetadata; the
+// client records Caller and Tenant as span attributes, counts requests per caller,
+// applies Priority to rate limiting and sends Headers with every API request of the
+// call.
+type CallMetadata struct {
+	// Caller names the calling component, e.g. "claims-intake".
+	Caller string
+
+	// Tenant identifies the customer or tenant the call is made for.
+	Tenant string
+
+	// Priority orders the call's requests under a rate limit.
+	Priority Priority
+
+	// Headers are added to outbound API requests, after headers set with WithHeader.
+	Headers http.Header
+}
+
+type callMetadataKey struct{}
+
+// ContextWithCallMetadata returns a copy of ctx carrying md.
+//
+// Example usage:
+//
+//	ctx = ContextWithCallMetadata(ctx, CallMetadata{Caller: "nightly-sync", Priority: PriorityLow})
+//	providers, err := client.GetProvidersByNPIs(ctx, npis)
+func ContextWithCallMetadata(ctx context.Context, md CallMetadata) context.Context {
+	return context.WithValue(ctx, callMetadataKey{}, md)
+}
+
+// CallMetadataFromContext returns the metadata attached to ctx, if any.
+func CallMetadataFromContext(ctx context.Context) (CallMetadata, bool) {
+	md, ok := ctx.Value(callMetadataKey{}).(CallMetadata)
+	return md, ok
+}
+
+// metricRequestsByCaller prefixes the per-caller request counters.
+const metricRequestsByCaller = MetricRequests + ".by_caller."
+
+// metadataAttributes returns span attributes describing md.
+func metadataAttributes(md CallMetadata) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if md.Caller != "" {
+		attrs = append(attrs, attribute.String("gonpi.caller", md.Caller))
+	}
+	if md.Tenant != "" {
+		attrs = append(attrs, attribute.String("gonpi.tenant", md.Tenant))
+	}
+	if md.Priority != PriorityNormal {
+		attrs = append(attrs, attribute.String("gonpi.priority", md.Priority.String()))
+	}
+	return attrs
+}
+
+// metricName makes caller safe to embed in a metric name, replacing characters other
+// than letters, digits, "-", "_" and "." with "_".
+func metricName(caller string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, caller)
+}
@@ -0,0 +1,160 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCallMetadata tests that call metadata adds headers and per-caller metrics.
+func TestCallMetadata(t *testing.T) {
+	var gotTenant, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = r.Header.Get("X-Tenant")
+		gotKey = r.Header.Get("X-Api-Key")
+		json.NewEncoder(w).Encode(mockAPIResponse(nil))
+	}))
+	defer server.Close()
+
+	sink := newRecordingSink()
+	client := NewClient(WithBaseURL(server.URL), WithHeader("X-Api-Key", "shared"), WithStatsSink(sink))
+
+	ctx := ContextWithCallMetadata(context.Background(), CallMetadata{
+		Caller:  "claims intake",
+		Tenant:  "acme",
+		Headers: http.Header{"X-Tenant": {"acme"}},
+	})
+	if md, ok := CallMetadataFromContext(ctx); !ok || md.Caller != "claims intake" {
+		t.Fatalf("CallMetadataFromContext = %+v, %v", md, ok)
+	}
+	if _, err := client.SearchProviders(ctx, SearchOptions{LastName: "Doe"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotTenant != "acme" || gotKey != "shared" {
+		t.Errorf("headers X-Tenant = %q, X-Api-Key = %q", gotTenant, gotKey)
+	}
+	if n := sink.counters[metricRequestsByCaller+"claims_intake"]; n != 1 {
+		t.Errorf("per-caller requests = %d, want 1 (counters %v)", n, sink.counters)
+	}
+	if n := sink.counters[MetricRequests]; n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+
+	if _, ok := CallMetadataFromContext(context.Background()); ok {
+		t.Error("expected no metadata on a bare context")
+	}
+}
+
+// TestRateLimiter_Priority tests that high priority requests overtake queued normal
+// requests and low priority requests wait for all of them.
+func TestRateLimiter_Priority(t *testing.T) {
+	limiter := newRateLimiter(20, 1)
+	ctx := context.Background()
+	limiter.wait(ctx, PriorityNormal)
+
+	var (
+		mu    sync.Mutex
+		order []Priority
+		wg    sync.WaitGroup
+	)
+	start := func(p Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.wait(ctx, p); err != nil {
+				t.Errorf("wait: %v", err)
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+		}()
+	}
+
+	start(PriorityLow)
+	time.Sleep(5 * time.Millisecond)
+	for range 3 {
+		start(PriorityNormal)
+	}
+	time.Sleep(5 * time.Millisecond)
+	start(PriorityHigh)
+	wg.Wait()
+
+	// The normal request at the head of the queue may already be due; high priority
+	// must still beat the rest
+	if order[0] != PriorityHigh && order[1] != PriorityHigh {
+		t.Errorf("expected high priority ahead of the queue, got order %v", order)
+	}
+	if order[len(order)-1] != PriorityLow {
+		t.Errorf("expected low priority last, got order %v", order)
+	}
+}
+
+// TestPriority_String tests priority names.
+func TestPriority_String(t *testing.T) {
+	for p, want := range map[Priority]string{PriorityLow: "low", PriorityNormal: "normal", PriorityHigh: "high", 5: "high"} {
+		if got := p.String(); got != want {
+			t.Errorf("Priority(%d).String() = %q, want %q", p, got, want)
+		}
+	}
+}
+
+// TestWithDefaultPriority tests that a derived background client sharing the rate
+// limit yields to interactive lookups of its parent.
+func TestWithDefaultPriority(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		order = append(order, r.URL.Query().Get("number"))
+		mu.Unlock()
+		p := mockProvider()
+		p.Number = r.URL.Query().Get("number")
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{p}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(20, 1))
+	defer client.Close()
+	extracts := client.With(WithDefaultPriority(PriorityBackground))
+	ctx := context.Background()
+	client.limiter.wait(ctx, PriorityNormal)
+
+	var wg sync.WaitGroup
+	lookup := func(c *Client, npi string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetProviderByNPI(ctx, npi); err != nil {
+				t.Errorf("lookup %s: %v", npi, err)
+			}
+		}()
+	}
+	lookup(extracts, "1234567893")
+	time.Sleep(5 * time.Millisecond)
+	lookup(client, "1245319599")
+	lookup(client, "1356789012")
+	wg.Wait()
+
+	if len(order) != 3 || order[2] != "1234567893" {
+		t.Errorf("expected the background lookup last, got %v", order)
+	}
+
+	md := CallMetadata{Caller: "sync"}
+	if p := extracts.priorityFor(md); p != PriorityBackground {
+		t.Errorf("default priority = %s", p)
+	}
+	md.Priority = PriorityHigh
+	if p := extracts.priorityFor(md); p != PriorityHigh {
+		t.Errorf("explicit priority = %s", p)
+	}
+	if md, _ := CallMetadataFromContext(ContextWithPriority(ContextWithCallMetadata(ctx, CallMetadata{Caller: "ui"}), PriorityHigh)); md.Caller != "ui" || md.Priority != PriorityHigh {
+		t.Errorf("ContextWithPriority lost metadata: %+v", md)
+	}
+}
@@ -0,0 +1,711 @@
+package gonpi
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// MsgPackCodec encodes values as MessagePack. Structs become maps keyed by their JSON
+// field names, so records can be read by any MessagePack library, and fields holding
+// their zero value are left out. time.Time uses the MessagePack timestamp extension.
+type MsgPackCodec struct{}
+
+// Name implements Codec.
+func (MsgPackCodec) Name() string { return "msgpack" }
+
+// ContentType implements Codec.
+func (MsgPackCodec) ContentType() string { return "application/x-msgpack" }
+
+// Marshal implements Codec.
+func (MsgPackCodec) Marshal(v any) ([]byte, error) {
+	e := &msgpackEncoder{buf: make([]byte, 0, 1024)}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Unmarshal implements Codec. v must be a non-nil pointer.
+func (MsgPackCodec) Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("msgpack: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+	d := &msgpackDecoder{data: data}
+	if err := d.decode(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return fmt.Errorf("msgpack: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return nil
+}
+
+// msgpackTimestamp is the extension type of MessagePack timestamps.
+const msgpackTimestamp = -1
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+func (e *msgpackEncoder) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+	if v.Type() == timeType {
+		e.writeTime(v.Interface().(time.Time))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		e.writeString(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encode(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			e.writeBytes(v.Bytes())
+			return nil
+		}
+		e.writeHeader(v.Len(), 0x90, 0xdc, 0xdd, 15)
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("msgpack: unsupported map key type %v", v.Type().Key())
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(a.String(), b.String())
+		})
+		e.writeHeader(len(keys), 0x80, 0xde, 0xdf, 15)
+		for _, key := range keys {
+			e.writeString(key.String())
+			if err := e.encode(v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := codecFields(v.Type())
+		n := 0
+		for _, field := range fields {
+			if !v.Field(field.index).IsZero() {
+				n++
+			}
+		}
+		e.writeHeader(n, 0x80, 0xde, 0xdf, 15)
+		for _, field := range fields {
+			value := v.Field(field.index)
+			if value.IsZero() {
+				continue
+			}
+			e.writeString(field.name)
+			if err := e.encode(value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+// writeHeader writes a string, array or map length using the fix, 16-bit or 32-bit
+// format.
+func (e *msgpackEncoder) writeHeader(n int, fix, b16, b32 byte, fixMax int) {
+	switch {
+	case n <= fixMax:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, b16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, b32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) writeString(s string) {
+	if len(s) <= 31 {
+		e.buf = append(e.buf, 0xa0|byte(len(s)))
+	} else if len(s) <= math.MaxUint8 {
+		e.buf = append(e.buf, 0xd9, byte(len(s)))
+	} else {
+		e.writeHeader(len(s), 0xa0, 0xda, 0xdb, 31)
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) writeBytes(b []byte) {
+	switch {
+	case len(b) <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(len(b)))
+	case len(b) <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(len(b)))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(len(b)))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *msgpackEncoder) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		e.writeUint(uint64(i))
+	case i >= -32:
+		e.buf = append(e.buf, byte(i))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(i))
+	case i >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(i))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(i))
+	}
+}
+
+func (e *msgpackEncoder) writeUint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(u))
+	case u <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(u))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, u)
+	}
+}
+
+// writeTime writes t as a 96-bit timestamp extension, which keeps nanoseconds and
+// dates before 1970.
+func (e *msgpackEncoder) writeTime(t time.Time) {
+	e.buf = append(e.buf, 0xc7, 12, byte(msgpackTimestamp&0xff))
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(t.Unix()))
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errMsgpackShort
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errMsgpackShort
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads an n-byte big-endian unsigned integer.
+func (d *msgpackDecoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// peekNil consumes a nil if it is next.
+func (d *msgpackDecoder) peekNil() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xc0 {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *msgpackDecoder) decode(v reflect.Value) error {
+	if d.peekNil() {
+		v.SetZero()
+		return nil
+	}
+	if v.Type() == timeType {
+		t, err := d.readTime()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := d.byte()
+		if err != nil {
+			return err
+		}
+		if b != 0xc2 && b != 0xc3 {
+			return d.mismatch(b, v.Type())
+		}
+		v.SetBool(b == 0xc3)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := d.readInt(v.Type())
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("msgpack: %d overflows %v", i, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := d.readInt(v.Type())
+		if err != nil {
+			return err
+		}
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return fmt.Errorf("msgpack: %d overflows %v", i, v.Type())
+		}
+		v.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, err := d.readFloat(v.Type())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, err := d.readString()
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("msgpack: unsupported type %v", v.Type())
+		}
+		value, err := d.readAny()
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(value))
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+		n, err := d.readLen(0x90, 0xdc, 0xdd, v.Type())
+		if err != nil {
+			return err
+		}
+		if n > len(d.data)-d.pos {
+			return errMsgpackShort
+		}
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		n, err := d.readLen(0x90, 0xdc, 0xdd, v.Type())
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if i < v.Len() {
+				err = d.decode(v.Index(i))
+			} else {
+				err = d.skip()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("msgpack: unsupported map key type %v", v.Type().Key())
+		}
+		n, err := d.readLen(0x80, 0xde, 0xdf, v.Type())
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), min(n, len(d.data)-d.pos))
+		for i := 0; i < n; i++ {
+			key, err := d.readString()
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		n, err := d.readLen(0x80, 0xde, 0xdf, v.Type())
+		if err != nil {
+			return err
+		}
+		fields := codecFields(v.Type())
+		for i := 0; i < n; i++ {
+			key, err := d.readString()
+			if err != nil {
+				return err
+			}
+			index := -1
+			for _, field := range fields {
+				if field.name == key {
+					index = field.index
+					break
+				}
+			}
+			if index < 0 {
+				err = d.skip()
+			} else {
+				err = d.decode(v.Field(index))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+func (d *msgpackDecoder) mismatch(b byte, t reflect.Type) error {
+	return fmt.Errorf("msgpack: cannot decode format 0x%02x into %v", b, t)
+}
+
+// readLen reads an array or map header with the given fix, 16-bit and 32-bit formats.
+func (d *msgpackDecoder) readLen(fix, b16, b32 byte, t reflect.Type) (int, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	switch {
+	case b&0xf0 == fix:
+		n = uint64(b & 0x0f)
+	case b == b16:
+		n, err = d.uint(2)
+	case b == b32:
+		n, err = d.uint(4)
+	default:
+		return 0, d.mismatch(b, t)
+	}
+	return int(n), err
+}
+
+func (d *msgpackDecoder) readString() (string, error) {
+	b, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	var n uint64
+	switch {
+	case b&0xe0 == 0xa0:
+		n = uint64(b & 0x1f)
+	case b == 0xd9, b == 0xc4:
+		n, err = d.uint(1)
+	case b == 0xda, b == 0xc5:
+		n, err = d.uint(2)
+	case b == 0xdb, b == 0xc6:
+		n, err = d.uint(4)
+	default:
+		return "", d.mismatch(b, reflect.TypeFor[string]())
+	}
+	if err != nil {
+		return "", err
+	}
+	s, err := d.next(int(n))
+	return string(s), err
+}
+
+func (d *msgpackDecoder) readBytes() ([]byte, error) {
+	s, err := d.readString()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+func (d *msgpackDecoder) readInt(t reflect.Type) (int64, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	}
+	var u uint64
+	switch b {
+	case 0xcc, 0xd0:
+		u, err = d.uint(1)
+		if b == 0xd0 {
+			return int64(int8(u)), err
+		}
+	case 0xcd, 0xd1:
+		u, err = d.uint(2)
+		if b == 0xd1 {
+			return int64(int16(u)), err
+		}
+	case 0xce, 0xd2:
+		u, err = d.uint(4)
+		if b == 0xd2 {
+			return int64(int32(u)), err
+		}
+	case 0xcf, 0xd3:
+		u, err = d.uint(8)
+		if b == 0xcf && u > math.MaxInt64 {
+			return 0, fmt.Errorf("msgpack: %d overflows %v", u, t)
+		}
+	default:
+		return 0, d.mismatch(b, t)
+	}
+	return int64(u), err
+}
+
+func (d *msgpackDecoder) readFloat(t reflect.Type) (float64, error) {
+	if d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case 0xca:
+			d.pos++
+			u, err := d.uint(4)
+			return float64(math.Float32frombits(uint32(u))), err
+		case 0xcb:
+			d.pos++
+			u, err := d.uint(8)
+			return math.Float64frombits(u), err
+		}
+	}
+	i, err := d.readInt(t)
+	return float64(i), err
+}
+
+// readExt reads an extension header, returning its type and data.
+func (d *msgpackDecoder) readExt() (int8, []byte, error) {
+	b, err := d.byte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var n uint64
+	switch b {
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		n = 1 << (b - 0xd4)
+	case 0xc7:
+		n, err = d.uint(1)
+	case 0xc8:
+		n, err = d.uint(2)
+	case 0xc9:
+		n, err = d.uint(4)
+	default:
+		return 0, nil, d.mismatch(b, timeType)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	typ, err := d.byte()
+	if err != nil {
+		return 0, nil, err
+	}
+	data, err := d.next(int(n))
+	return int8(typ), data, err
+}
+
+func (d *msgpackDecoder) readTime() (time.Time, error) {
+	typ, data, err := d.readExt()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if typ != msgpackTimestamp {
+		return time.Time{}, fmt.Errorf("msgpack: extension type %d is not a timestamp", typ)
+	}
+	return parseTimestamp(data)
+}
+
+// parseTimestamp decodes the data of a timestamp extension in any of its three sizes.
+func parseTimestamp(data []byte) (time.Time, error) {
+	switch len(data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+	case 8:
+		u := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(u&(1<<34-1)), int64(u>>34)).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(nsec)).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("msgpack: invalid timestamp length %d", len(data))
+}
+
+// readAny reads a value of any type, as bool, int64, uint64, float64, string, []byte,
+// time.Time, []any or map[string]any.
+func (d *msgpackDecoder) readAny() (any, error) {
+	if d.pos >= len(d.data) {
+		return nil, errMsgpackShort
+	}
+	b := d.data[d.pos]
+	switch {
+	case b == 0xc0:
+		d.pos++
+		return nil, nil
+	case b == 0xc2 || b == 0xc3:
+		d.pos++
+		return b == 0xc3, nil
+	case b <= 0x7f || b >= 0xe0 || (b >= 0xd0 && b <= 0xd3) || (b >= 0xcc && b <= 0xce):
+		return d.readInt(reflect.TypeFor[int64]())
+	case b == 0xcf:
+		d.pos++
+		return d.uint(8)
+	case b == 0xca || b == 0xcb:
+		return d.readFloat(reflect.TypeFor[float64]())
+	case b&0xe0 == 0xa0 || (b >= 0xd9 && b <= 0xdb):
+		return d.readString()
+	case b >= 0xc4 && b <= 0xc6:
+		return d.readBytes()
+	case b&0xf0 == 0x90 || b == 0xdc || b == 0xdd:
+		var s []any
+		err := d.decode(reflect.ValueOf(&s).Elem())
+		return s, err
+	case b&0xf0 == 0x80 || b == 0xde || b == 0xdf:
+		var m map[string]any
+		err := d.decode(reflect.ValueOf(&m).Elem())
+		return m, err
+	case (b >= 0xd4 && b <= 0xd8) || (b >= 0xc7 && b <= 0xc9):
+		return d.readTime()
+	}
+	return nil, fmt.Errorf("msgpack: invalid format 0x%02x", b)
+}
+
+// skip discards the next value, including extensions of types gonpi does not use.
+func (d *msgpackDecoder) skip() error {
+	b, err := d.byte()
+	if err != nil {
+		return err
+	}
+	var n, items uint64
+	switch {
+	case b <= 0x7f, b >= 0xe0, b == 0xc0, b == 0xc2, b == 0xc3:
+	case b&0xe0 == 0xa0:
+		n = uint64(b & 0x1f)
+	case b&0xf0 == 0x90:
+		items = uint64(b & 0x0f)
+	case b&0xf0 == 0x80:
+		items = 2 * uint64(b&0x0f)
+	case b == 0xcc, b == 0xd0:
+		n = 1
+	case b == 0xcd, b == 0xd1:
+		n = 2
+	case b == 0xce, b == 0xd2, b == 0xca:
+		n = 4
+	case b == 0xcf, b == 0xd3, b == 0xcb:
+		n = 8
+	case b == 0xd9, b == 0xc4:
+		n, err = d.uint(1)
+	case b == 0xda, b == 0xc5:
+		n, err = d.uint(2)
+	case b == 0xdb, b == 0xc6:
+		n, err = d.uint(4)
+	case b == 0xdc:
+		items, err = d.uint(2)
+	case b == 0xdd:
+		items, err = d.uint(4)
+	case b == 0xde:
+		items, err = d.uint(2)
+		items *= 2
+	case b == 0xdf:
+		items, err = d.uint(4)
+		items *= 2
+	case b >= 0xd4 && b <= 0xd8, b >= 0xc7 && b <= 0xc9:
+		d.pos--
+		_, _, err = d.readExt()
+		return err
+	default:
+		return fmt.Errorf("msgpack: invalid format 0x%02x", b)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := d.next(int(n)); err != nil {
+		return err
+	}
+	for ; items > 0; items-- {
+		if err := d.skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package gonpi
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// TestMsgPackCodec_Format tests the encoding of values against the MessagePack spec.
+func TestMsgPackCodec_Format(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"bools", []bool{true, false}, []byte{0x92, 0xc3, 0xc2}},
+		{"fixint", 5, []byte{0x05}},
+		{"negative fixint", -3, []byte{0xfd}},
+		{"int16", -200, []byte{0xd1, 0xff, 0x38}},
+		{"uint32", 70000, []byte{0xce, 0x00, 0x01, 0x11, 0x70}},
+		{"float", 1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "abc", []byte{0xa3, 'a', 'b', 'c'}},
+		{"map", map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+		{"struct omits zero fields", County{FIPS: "1"}, []byte{0x81, 0xa4, 'f', 'i', 'p', 's', 0xa1, '1'}},
+		{"timestamp", time.Unix(1, 2), []byte{0xc7, 12, 0xff, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MsgPackCodec{}.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal(%v) = % x, want % x", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMsgPackCodec_Decode tests decoding other encoders' choices of format and
+// skipping unknown fields.
+func TestMsgPackCodec_Decode(t *testing.T) {
+	// {"fips": "06037", "extra": [1, {"x": ext(5, 1 byte)}], "name": "LA"}, with str8
+	// for name and a 32-bit timestamp nowhere in County
+	data := []byte{0x83,
+		0xa4, 'f', 'i', 'p', 's', 0xa5, '0', '6', '0', '3', '7',
+		0xa5, 'e', 'x', 't', 'r', 'a', 0x92, 0x01, 0x81, 0xa1, 'x', 0xd4, 0x05, 0x00,
+		0xa4, 'n', 'a', 'm', 'e', 0xd9, 0x02, 'L', 'A',
+	}
+	var county County
+	if err := (MsgPackCodec{}).Unmarshal(data, &county); err != nil {
+		t.Fatal(err)
+	}
+	if county != (County{FIPS: "06037", Name: "LA"}) {
+		t.Errorf("decoded %+v", county)
+	}
+
+	var ts time.Time
+	if err := (MsgPackCodec{}).Unmarshal([]byte{0xd6, 0xff, 0, 0, 0, 60}, &ts); err != nil || ts.Unix() != 60 {
+		t.Errorf("32-bit timestamp = %v, %v", ts, err)
+	}
+
+	var n int8
+	if err := (MsgPackCodec{}).Unmarshal([]byte{0xcd, 0x01, 0x00}, &n); err == nil {
+		t.Error("expected overflow error")
+	}
+	for _, bad := range [][]byte{{}, {0x82, 0xa1}, {0xa3, 'a'}, {0x01, 0x02}, {0xc1}} {
+		var v any
+		if err := (MsgPackCodec{}).Unmarshal(bad, &v); err == nil {
+			t.Errorf("expected error for % x", bad)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package gonpi
+
+import "strings"
+
+// IsOrganization reports whether p is an organization (NPI-2). Records without an
+// enumeration type are treated as organizations when they carry an organization name
+// but no individual name.
+func (p Provider) IsOrganization() bool {
+	switch p.EnumerationType {
+	case "NPI-2":
+		return true
+	case "NPI-1":
+		return false
+	}
+	return p.Basic.OrganizationName != "" && p.Basic.FirstName == "" && p.Basic.LastName == ""
+}
+
+// DisplayName returns a name for showing p, whichever fields the registry populated.
+// Organizations use their organization name, falling back to Basic.Name and then to
+// their first other organization name; their first and last name fields are empty,
+// and the people named on the record are authorized officials (see
+// AuthorizedOfficialName). Individuals use "FIRST MIDDLE LAST, CREDENTIAL", falling
+// back to Basic.Name when the name parts are empty.
+func (p Provider) DisplayName() string {
+	if p.IsOrganization() {
+		return p.organizationName()
+	}
+	name := joinName(p.Basic.FirstName, p.Basic.MiddleName, p.Basic.LastName)
+	if name == "" {
+		name = strings.TrimSpace(p.Basic.Name)
+	}
+	if name != "" && p.Basic.Credential != "" {
+		name += ", " + p.Basic.Credential
+	}
+	return name
+}
+
+// AuthorizedOfficialName returns "FIRST MIDDLE LAST" for the organization's authorized
+// official, or "" for individuals and organizations without one.
+func (p Provider) AuthorizedOfficialName() string {
+	return joinName(p.Basic.AuthorizedOfficialFirstName, p.Basic.AuthorizedOfficialMiddleName, p.Basic.AuthorizedOfficialLastName)
+}
+
+// organizationName returns the organization's name, falling back to Basic.Name and
+// then to its first other organization name.
+func (p Provider) organizationName() string {
+	if name := strings.TrimSpace(p.Basic.OrganizationName); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(p.Basic.Name); name != "" {
+		return name
+	}
+	for _, other := range p.OtherNames {
+		if name := strings.TrimSpace(other.OrganizationName); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// joinName joins the non-empty name parts with single spaces.
+func joinName(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// NameContains matches providers whose DisplayName or other names contain substr,
+// ignoring case. It applies the same way to individuals and organizations.
+func NameContains(substr string) ProviderFilter {
+	substr = strings.ToUpper(strings.TrimSpace(substr))
+	return func(p Provider) bool {
+		if strings.Contains(strings.ToUpper(p.DisplayName()), substr) {
+			return true
+		}
+		for _, other := range p.OtherNames {
+			name := joinName(other.FirstName, other.MiddleName, other.LastName, other.OrganizationName)
+			if strings.Contains(strings.ToUpper(name), substr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AuthorizedOfficialNamed matches organizations whose authorized official's first and
+// last names start with first and last, ignoring case, like the API's own name
+// parameters. An empty first or last matches any name. The API cannot search on
+// authorized officials, so this filters the results of an organization query:
+//
+//	opts := gonpi.SearchOptions{EnumerationType: "NPI-2", State: "MA", TaxonomyDescription: "Hospital"}
+//	for org, err := range gonpi.Filter(client.SearchAll(ctx, opts), gonpi.AuthorizedOfficialNamed("", "Smith")) {
+//	    ...
+//	}
+func AuthorizedOfficialNamed(first, last string) ProviderFilter {
+	first = strings.ToUpper(strings.TrimSpace(first))
+	last = strings.ToUpper(strings.TrimSpace(last))
+	return func(p Provider) bool {
+		if !p.IsOrganization() || p.AuthorizedOfficialName() == "" {
+			return false
+		}
+		return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(p.Basic.AuthorizedOfficialFirstName)), first) &&
+			strings.HasPrefix(strings.ToUpper(strings.TrimSpace(p.Basic.AuthorizedOfficialLastName)), last)
+	}
+}
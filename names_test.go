@@ -0,0 +1,96 @@
+package gonpi
+
+import "testing"
+
+// TestProvider_DisplayName tests names for individuals and organizations, including
+// records populating Basic.Name instead of the usual fields.
+func TestProvider_DisplayName(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		want     string
+	}{
+		{
+			name:     "individual",
+			provider: Provider{EnumerationType: "NPI-1", Basic: BasicInfo{FirstName: "JANE", MiddleName: "Q", LastName: "DOE", Credential: "MD"}},
+			want:     "JANE Q DOE, MD",
+		},
+		{
+			name:     "individual basic name",
+			provider: Provider{EnumerationType: "NPI-1", Basic: BasicInfo{Name: "DOE JANE"}},
+			want:     "DOE JANE",
+		},
+		{
+			name: "organization",
+			provider: Provider{EnumerationType: "NPI-2", Basic: BasicInfo{
+				OrganizationName: "GENERAL HOSPITAL", AuthorizedOfficialFirstName: "JOHN", AuthorizedOfficialLastName: "SMITH",
+			}},
+			want: "GENERAL HOSPITAL",
+		},
+		{
+			name:     "organization basic name",
+			provider: Provider{EnumerationType: "NPI-2", Basic: BasicInfo{Name: "GENERAL HOSPITAL"}},
+			want:     "GENERAL HOSPITAL",
+		},
+		{
+			name:     "organization other name",
+			provider: Provider{EnumerationType: "NPI-2", OtherNames: []OtherName{{OrganizationName: "GH CLINIC"}}},
+			want:     "GH CLINIC",
+		},
+		{
+			name:     "missing enumeration type",
+			provider: Provider{Basic: BasicInfo{OrganizationName: "GENERAL HOSPITAL"}},
+			want:     "GENERAL HOSPITAL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.provider.DisplayName(); got != tt.want {
+				t.Errorf("DisplayName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNameFilters tests that name filters apply uniformly and that authorized official
+// filters only match organizations.
+func TestNameFilters(t *testing.T) {
+	doctor := Provider{Number: "1", EnumerationType: "NPI-1", Basic: BasicInfo{FirstName: "JOHN", LastName: "SMITH"}}
+	hospital := Provider{Number: "2", EnumerationType: "NPI-2", Basic: BasicInfo{
+		OrganizationName:            "SMITHFIELD HOSPITAL",
+		AuthorizedOfficialFirstName: "MARY",
+		AuthorizedOfficialLastName:  "SMITH",
+	}}
+	clinic := Provider{Number: "3", EnumerationType: "NPI-2", Basic: BasicInfo{Name: "WESTSIDE CLINIC"}}
+	providers := []Provider{doctor, hospital, clinic}
+
+	numbers := func(filtered []Provider) string {
+		var s string
+		for _, p := range filtered {
+			s += p.Number
+		}
+		return s
+	}
+	tests := []struct {
+		name   string
+		filter ProviderFilter
+		want   string
+	}{
+		{"name contains", NameContains("smith"), "12"},
+		{"name contains basic name", NameContains("westside"), "3"},
+		{"official last name", AuthorizedOfficialNamed("", "smi"), "2"},
+		{"official full name", AuthorizedOfficialNamed("Mary", "Smith"), "2"},
+		{"official mismatch", AuthorizedOfficialNamed("John", "Smith"), ""},
+		{"official any", AuthorizedOfficialNamed("", ""), "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := numbers(FilterProviders(providers, tt.filter)); got != tt.want {
+				t.Errorf("matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := hospital.AuthorizedOfficialName(); got != "MARY SMITH" {
+		t.Errorf("AuthorizedOfficialName() = %q", got)
+	}
+}
@@ -0,0 +1,67 @@
+package gonpi
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NPILength is the number of digits in an NPI.
+const NPILength = 10
+
+// NormalizeNPI cleans up an NPI as it commonly appears in CSV and EDI files: surrounding
+// and embedded whitespace, dashes and a leading "NPI" label (e.g. "NPI: 1234-567-890")
+// are removed. It returns a ValidationError if the result is not exactly ten digits.
+func NormalizeNPI(s string) (string, error) {
+	cleaned := strings.TrimSpace(s)
+	if len(cleaned) >= 3 && strings.EqualFold(cleaned[:3], "NPI") {
+		cleaned = strings.TrimLeft(cleaned[3:], " \t:#")
+	}
+
+	var b strings.Builder
+	b.Grow(NPILength)
+	for _, r := range cleaned {
+		switch {
+		case r == '-' || unicode.IsSpace(r):
+			continue
+		case r < '0' || r > '9':
+			return "", &ValidationError{Field: "npi", Message: fmt.Sprintf("invalid NPI %q: must contain only digits", s)}
+		}
+		b.WriteRune(r)
+	}
+
+	npi := b.String()
+	if npi == "" {
+		return "", &ValidationError{Field: "npi", Message: "npi cannot be empty"}
+	}
+	if len(npi) != NPILength {
+		return "", &ValidationError{Field: "npi", Message: fmt.Sprintf("invalid NPI %q: must be %d digits, got %d", s, NPILength, len(npi))}
+	}
+	return npi, nil
+}
+
+// ValidCheckDigit reports whether npi, ten digits as returned by NormalizeNPI, ends in
+// the check digit computed with the Luhn algorithm over the NPI prefixed by the
+// health industry number 80840, as the NPI standard specifies. NPIs failing it were
+// mistyped and cannot exist in the registry.
+func ValidCheckDigit(npi string) bool {
+	if len(npi) != NPILength {
+		return false
+	}
+	// The prefix 80840 contributes a constant 24 to the sum
+	sum := 24
+	for i := NPILength - 2; i >= 0; i-- {
+		d := int(npi[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if (NPILength-2-i)%2 == 0 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	check := int(npi[NPILength-1] - '0')
+	return check == (10-sum%10)%10
+}
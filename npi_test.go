@@ -0,0 +1,103 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestNormalizeNPI tests cleanup and validation of NPI input.
+func TestNormalizeNPI(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"1234567890", "1234567890", false},
+		{"  1234567890\t", "1234567890", false},
+		{"123-456-7890", "1234567890", false},
+		{"NPI:1234567890", "1234567890", false},
+		{"npi: 1234 567 890", "1234567890", false},
+		{"NPI #1234567890", "1234567890", false},
+		{"", "", true},
+		{"NPI:", "", true},
+		{"123456789", "", true},
+		{"12345678901", "", true},
+		{"12345A7890", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeNPI(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeNPI(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil && !IsValidation(err) {
+				t.Errorf("expected ValidationError, got %T", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeNPI(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNormalizeNPI_LookupPaths tests that lookups and batches normalize their input.
+func TestNormalizeNPI_LookupPaths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		npi := r.URL.Query().Get("number")
+		if len(npi) != NPILength {
+			t.Errorf("expected normalized NPI in request, got %q", npi)
+		}
+		provider := mockProvider()
+		provider.Number = npi
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{provider}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	provider, err := client.GetProviderByNPI(context.Background(), "NPI: 123-456-7890")
+	if err != nil || provider.Number != "1234567890" {
+		t.Fatalf("unexpected result: %v, %v", provider, err)
+	}
+
+	if _, err := client.GetProviderByNPI(context.Background(), "12345"); !IsValidation(err) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+
+	results, err := client.GetProvidersByNPIs(context.Background(), []string{" 1234567890 ", "bad"})
+	if !IsValidation(err) {
+		t.Errorf("expected validation error for bad input, got %v", err)
+	}
+	if results["1234567890"] == nil {
+		t.Error("expected result keyed by normalized NPI")
+	}
+
+	items, _ := client.GetProvidersByNPIsOrdered(context.Background(), []string{"123-456-7890", "bad"})
+	if items[0].NPI != "123-456-7890" || items[0].Provider == nil {
+		t.Errorf("unexpected first item: %+v", items[0])
+	}
+	if !IsValidation(items[1].Err) {
+		t.Errorf("expected validation error on second item, got %v", items[1].Err)
+	}
+}
+
+// TestValidCheckDigit tests the Luhn check digit against known NPIs and typos.
+func TestValidCheckDigit(t *testing.T) {
+	for npi, want := range map[string]bool{
+		"1234567893": true,
+		"1003000126": true,
+		"1043218118": true,
+		"1234567890": false,
+		"1234567839": false,
+		"123456789":  false,
+		"12345678a3": false,
+	} {
+		if got := ValidCheckDigit(npi); got != want {
+			t.Errorf("ValidCheckDigit(%q) = %v, want %v", npi, got, want)
+		}
+	}
+}
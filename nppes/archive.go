@@ -0,0 +1,102 @@
+package nppes
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrEntryNotFound indicates that an archive has no entry of the requested kind.
+var ErrEntryNotFound = errors.New("archive entry not found")
+
+// EntryKind identifies the CSV files inside a dissemination archive.
+type EntryKind string
+
+// Entry kinds, named by the file name prefix CMS uses for them.
+const (
+	// EntryData is the main provider file, npidata_pfile_*.csv.
+	EntryData EntryKind = "npidata_pfile"
+
+	// EntryOtherNames lists other organization names, othername_pfile_*.csv.
+	EntryOtherNames EntryKind = "othername_pfile"
+
+	// EntryPracticeLocations lists secondary practice locations, pl_pfile_*.csv.
+	EntryPracticeLocations EntryKind = "pl_pfile"
+
+	// EntryEndpoints lists electronic endpoints, endpoint_pfile_*.csv.
+	EntryEndpoints EntryKind = "endpoint_pfile"
+)
+
+// Archive reads the CSV files of a dissemination archive without unpacking it: each
+// entry is decompressed as it is read, so the ~10GB provider file can be fed straight
+// into a parser from the ~1GB archive.
+type Archive struct {
+	reader *zip.Reader
+	closer io.Closer
+}
+
+// OpenArchive opens the dissemination archive at path.
+//
+// Example usage:
+//
+//	archive, err := nppes.OpenArchive("NPPES_Data_Dissemination_October_2025.zip")
+//	defer archive.Close()
+//	data, err := archive.Open(nppes.EntryData)
+//	defer data.Close()
+//	records, err := gonpi.NewCSVRecordReader(data, "NPI")
+func OpenArchive(path string) (*Archive, error) {
+	rc, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	return &Archive{reader: &rc.Reader, closer: rc}, nil
+}
+
+// NewArchive reads an archive of the given size from r, such as an *os.File or a
+// memory-mapped file. ZIP archives keep their directory at the end, so r must support
+// random access.
+func NewArchive(r io.ReaderAt, size int64) (*Archive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	return &Archive{reader: zr}, nil
+}
+
+// Close closes the archive file if it was opened with OpenArchive.
+func (a *Archive) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// Entry returns the archive entry of the given kind. Header-only companion files
+// (*_fileheader.csv) are skipped.
+func (a *Archive) Entry(kind EntryKind) (*zip.File, error) {
+	for _, f := range a.reader.File {
+		name := strings.ToLower(path.Base(f.Name))
+		if strings.HasPrefix(name, string(kind)+"_") && strings.HasSuffix(name, ".csv") &&
+			!strings.HasSuffix(name, "_fileheader.csv") {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no %s file", ErrEntryNotFound, kind)
+}
+
+// Open returns a stream of the decompressed CSV entry of the given kind. Several
+// entries may be open at once.
+func (a *Archive) Open(kind EntryKind) (io.ReadCloser, error) {
+	f, err := a.Entry(kind)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	return rc, nil
+}
@@ -0,0 +1,82 @@
+package nppes
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// writeTestArchive writes a dissemination-style archive with the given entries.
+func writeTestArchive(t *testing.T, entries map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "NPPES_Data_Dissemination_October_2025.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestArchive tests streaming an entry from the archive into the CSV reader.
+func TestArchive(t *testing.T) {
+	path := writeTestArchive(t, map[string]string{
+		"npidata_pfile_20050523-20251012_fileheader.csv": "\"NPI\",\"Entity Type Code\"\n",
+		"npidata_pfile_20050523-20251012.csv":            "\"NPI\",\"Entity Type Code\"\n\"1234567893\",\"1\"\n\"1245319599\",\"2\"\n",
+		"pl_pfile_20050523-20251012.csv":                 "\"NPI\",\"Provider Secondary Practice Location Address- Address Line 1\"\n",
+	})
+
+	archive, err := OpenArchive(path)
+	if err != nil {
+		t.Fatalf("OpenArchive: %v", err)
+	}
+	defer archive.Close()
+
+	data, err := archive.Open(EntryData)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer data.Close()
+
+	records, err := gonpi.NewCSVRecordReader(data, "NPI")
+	if err != nil {
+		t.Fatalf("NewCSVRecordReader: %v", err)
+	}
+	var npis []string
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		npis = append(npis, record.NPI)
+	}
+	if len(npis) != 2 || npis[0] != "1234567893" {
+		t.Errorf("records = %v", npis)
+	}
+
+	if _, err := archive.Entry(EntryPracticeLocations); err != nil {
+		t.Errorf("practice locations: %v", err)
+	}
+	if _, err := archive.Open(EntryEndpoints); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("expected ErrEntryNotFound, got %v", err)
+	}
+}
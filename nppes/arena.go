@@ -0,0 +1,76 @@
+package nppes
+
+import (
+	"slices"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// arena holds one Load batch: its rows and the providers converted from them. With
+// WithLoadArena the rows share one array of cells and the providers share arrays of
+// addresses, taxonomies and identifiers, and the arena is reused for a later batch once
+// the store has consumed this one, so a load allocates a handful of arrays per batch
+// instead of several slices per row.
+type arena struct {
+	shared bool
+
+	cells     []string
+	rows      [][]string
+	providers []gonpi.Provider
+
+	addresses   []gonpi.Address
+	taxonomies  []gonpi.Taxonomy
+	identifiers []gonpi.Identifier
+}
+
+// newArena returns an empty arena for batches of size rows.
+func newArena(size int, shared bool) *arena {
+	return &arena{shared: shared, rows: make([][]string, 0, size)}
+}
+
+// addRow appends row to the batch. A shared arena copies its cells, so the csv.Reader
+// may reuse row; otherwise row is kept as is.
+func (a *arena) addRow(row []string) {
+	if !a.shared {
+		a.rows = append(a.rows, row)
+		return
+	}
+	start := len(a.cells)
+	a.cells = append(a.cells, row...)
+	a.rows = append(a.rows, carve(a.cells, start))
+}
+
+// convert converts every row of the batch with rd.
+func (a *arena) convert(rd *Reader) {
+	a.providers = slices.Grow(a.providers[:0], len(a.rows))[:len(a.rows)]
+	slabs := a
+	if !a.shared {
+		slabs = nil
+	}
+	for i, row := range a.rows {
+		a.providers[i] = rd.provider(row, slabs)
+	}
+}
+
+// reset empties the arena for reuse, keeping its arrays but dropping references to
+// the previous batch's values.
+func (a *arena) reset() {
+	clear(a.cells)
+	clear(a.rows)
+	clear(a.providers)
+	clear(a.addresses)
+	clear(a.taxonomies)
+	clear(a.identifiers)
+	a.cells, a.rows, a.providers = a.cells[:0], a.rows[:0], a.providers[:0]
+	a.addresses, a.taxonomies, a.identifiers = a.addresses[:0], a.taxonomies[:0], a.identifiers[:0]
+}
+
+// carve returns the elements appended to slab since start, or nil if there are none.
+// The capacity is capped so that appending to the result copies it instead of
+// overwriting the values that follow.
+func carve[T any](slab []T, start int) []T {
+	if len(slab) == start {
+		return nil
+	}
+	return slab[start:len(slab):len(slab)]
+}
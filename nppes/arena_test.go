@@ -0,0 +1,152 @@
+package nppes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// encodingStore is a ProviderStore that keeps providers encoded, like a database, so
+// it does not retain their slices after Put.
+type encodingStore struct {
+	gonpi.ProviderStore
+	mu      sync.Mutex
+	records map[string][]byte
+}
+
+func (s *encodingStore) Put(_ context.Context, providers ...gonpi.Provider) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, provider := range providers {
+		data, err := json.Marshal(provider)
+		if err != nil {
+			return err
+		}
+		s.records[provider.Number] = data
+	}
+	return nil
+}
+
+// variedFile returns a provider file whose rows have varying numbers of addresses,
+// taxonomies and identifiers.
+func variedFile(n int) string {
+	rows := make([]map[Field]string, n)
+	for i := range rows {
+		row := map[Field]string{
+			FieldNPI:          fmt.Sprintf("%010d", 1000000000+i),
+			FieldEntityType:   "1",
+			FieldLastName:     fmt.Sprintf("DOE%d", i),
+			FieldLocationCity: "BOSTON",
+		}
+		if i%2 == 0 {
+			row[FieldMailingCity] = "CAMBRIDGE"
+			row[Indexed(FieldIdentifier, 1)] = fmt.Sprintf("MC%d", i)
+		}
+		for n := range i % 4 {
+			row[Indexed(FieldTaxonomyCode, n+1)] = fmt.Sprintf("20%d000000X", n)
+		}
+		rows[i] = row
+	}
+	return writeProviderFile(Layouts[0], rows...)
+}
+
+// TestLoad_Arena tests that loading with arenas stores the same providers as loading
+// without them, across recycled batches.
+func TestLoad_Arena(t *testing.T) {
+	file := variedFile(3 * loadBatchSize)
+	want := gonpi.NewMemoryStore()
+	if _, err := Load(context.Background(), strings.NewReader(file), want); err != nil {
+		t.Fatal(err)
+	}
+
+	got := &encodingStore{records: map[string][]byte{}}
+	stats, err := Load(context.Background(), strings.NewReader(file), got,
+		WithLoadArena(), WithLoadWorkers(2), WithLoadBuffer(1))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if stats.Stored != int64(3*loadBatchSize) || len(got.records) != 3*loadBatchSize {
+		t.Fatalf("stats = %+v, store has %d", stats, len(got.records))
+	}
+	for npi, data := range got.records {
+		var provider gonpi.Provider
+		if err := json.Unmarshal(data, &provider); err != nil {
+			t.Fatal(err)
+		}
+		expected, _ := want.Get(context.Background(), npi)
+		if !reflect.DeepEqual(&provider, expected) {
+			t.Fatalf("provider %s:\n got %+v\nwant %+v", npi, provider, expected)
+		}
+	}
+}
+
+// TestArena_Carve tests that appending to one provider's lists leaves the next
+// provider's alone.
+func TestArena_Carve(t *testing.T) {
+	reader, err := NewReader(strings.NewReader(variedFile(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.reader.ReuseRecord = true
+	batch := newArena(3, true)
+	for {
+		row, err := reader.reader.Read()
+		if err != nil {
+			break
+		}
+		batch.addRow(row)
+	}
+	batch.convert(reader)
+
+	first, second := batch.providers[1], batch.providers[2]
+	if len(first.Taxonomies) != 1 || len(second.Taxonomies) != 2 {
+		t.Fatalf("taxonomies = %+v, %+v", first.Taxonomies, second.Taxonomies)
+	}
+	if batch.providers[0].Taxonomies != nil {
+		t.Errorf("expected nil taxonomies, got %+v", batch.providers[0].Taxonomies)
+	}
+	_ = append(first.Taxonomies, gonpi.Taxonomy{Code: "OVERWRITE"})
+	if second.Taxonomies[0].Code != "200000000X" {
+		t.Errorf("append overwrote the next provider: %+v", second.Taxonomies)
+	}
+	npi := reader.mapping.Index(FieldNPI)
+	if batch.rows[0][npi] != "1000000000" || batch.rows[2][npi] != "1000000002" {
+		t.Errorf("rows were not copied out of the reused record: %q, %q", batch.rows[0][npi], batch.rows[2][npi])
+	}
+}
+
+// BenchmarkLoad_Arena compares loading with and without arenas, reporting garbage
+// collection cycles and pause time per load alongside allocations.
+func BenchmarkLoad_Arena(b *testing.B) {
+	file := variedFile(20000)
+	for _, tt := range []struct {
+		name string
+		opts []LoadOption
+	}{
+		{"default", nil},
+		{"arena", []LoadOption{WithLoadArena()}},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			b.SetBytes(int64(len(file)))
+			b.ReportAllocs()
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			for b.Loop() {
+				if _, err := Load(context.Background(), strings.NewReader(file), discardStore{}, tt.opts...); err != nil {
+					b.Fatal(err)
+				}
+			}
+			runtime.ReadMemStats(&after)
+			b.ReportMetric(float64(after.NumGC-before.NumGC)/float64(b.N), "gcs/op")
+			b.ReportMetric(float64(after.PauseTotalNs-before.PauseTotalNs)/float64(b.N), "gc-pause-ns/op")
+		})
+	}
+}
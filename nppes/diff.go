@@ -0,0 +1,435 @@
+package nppes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+	"github.com/sdsvn/gonpi/bulkutil"
+)
+
+// DiffOption configures Diff and DiffStore.
+type DiffOption func(*diffConfig)
+
+// diffConfig holds the settings applied by DiffOptions.
+type diffConfig struct {
+	dir        string
+	memory     int
+	projection Projection
+	now        time.Time
+}
+
+// WithDiffDir sets the directory for the temporary files used to sort the inputs.
+// Default: os.TempDir().
+func WithDiffDir(dir string) DiffOption {
+	return func(c *diffConfig) {
+		c.dir = dir
+	}
+}
+
+// WithDiffMemory sets the memory budget, in bytes, used for each input before it
+// spills to disk. Default: bulkutil.DefaultMemory.
+func WithDiffMemory(bytes int) DiffOption {
+	return func(c *diffConfig) {
+		c.memory = bytes
+	}
+}
+
+// WithDiffProjection limits the columns compared. Changes to columns left out are not
+// reported; DiffStore should use the projection the store was loaded with.
+func WithDiffProjection(p Projection) DiffOption {
+	return func(c *diffConfig) {
+		c.projection = p
+	}
+}
+
+// WithDiffTime sets the Time of the events produced. Default: when the diff started.
+func WithDiffTime(t time.Time) DiffOption {
+	return func(c *diffConfig) {
+		c.now = t
+	}
+}
+
+// DiffStats counts the providers compared by Diff and DiffStore and the events they
+// produced.
+type DiffStats struct {
+	// Old and New are the number of providers read from each side.
+	Old, New int64
+
+	// Created, Updated, Deactivated and Reactivated count the events of each type.
+	Created, Updated, Deactivated, Reactivated int64
+
+	// Unchanged is the number of providers present on both sides with equal records.
+	Unchanged int64
+
+	// Duration is the time the diff took.
+	Duration time.Duration
+}
+
+// count records an event of type typ.
+func (s *DiffStats) count(typ gonpi.EventType) {
+	switch typ {
+	case gonpi.EventProviderCreated:
+		s.Created++
+	case gonpi.EventProviderUpdated:
+		s.Updated++
+	case gonpi.EventProviderDeactivated:
+		s.Deactivated++
+	case gonpi.EventProviderReactivated:
+		s.Reactivated++
+	}
+}
+
+// Diff compares two provider files, such as the data files of consecutive monthly
+// dissemination archives, and publishes a gonpi.ChangeEvent for every provider that
+// changed, in NPI order, so that a change feed can be produced without the live API.
+// Providers only in new are created; providers only in old, or whose record in new is
+// deactivated, are deactivated; events for providers in both carry the field Changes.
+//
+// Both files are sorted by NPI on disk within the memory budget, so files of any size
+// can be compared. Diff stops at the first read or publish error.
+//
+// Example usage:
+//
+//	old, _ := previous.Open(nppes.EntryData)
+//	new, _ := current.Open(nppes.EntryData)
+//	stats, err := nppes.Diff(ctx, old, new, &gonpi.KafkaPublisher{Producer: producer, Topic: "npi.changes"},
+//	    nppes.WithDiffDir(scratch))
+func Diff(ctx context.Context, old, new io.Reader, publisher gonpi.Publisher, opts ...DiffOption) (DiffStats, error) {
+	config := newDiffConfig(opts)
+	start := time.Now()
+	var stats DiffStats
+
+	// Sort both sides at once; each has its own memory budget
+	var oldSorted, newSorted *bulkutil.Sorter
+	var oldErr, newErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		oldSorted, oldErr = sortProviders(ctx, old, config, &stats.Old)
+	}()
+	go func() {
+		defer wg.Done()
+		newSorted, newErr = sortProviders(ctx, new, config, &stats.New)
+	}()
+	wg.Wait()
+	for _, sorter := range []*bulkutil.Sorter{oldSorted, newSorted} {
+		if sorter != nil {
+			defer sorter.Close()
+		}
+	}
+	if oldErr != nil {
+		return stats, fmt.Errorf("old file: %w", oldErr)
+	}
+	if newErr != nil {
+		return stats, fmt.Errorf("new file: %w", newErr)
+	}
+
+	nextOld, stopOld := iter.Pull2(oldSorted.Sorted())
+	defer stopOld()
+	nextNew, stopNew := iter.Pull2(newSorted.Sorted())
+	defer stopNew()
+
+	emit := newEmitter(ctx, publisher, config.now, &stats)
+	oldRecord, oldErr, oldOK := nextOld()
+	newRecord, newErr, newOK := nextNew()
+	for oldOK || newOK {
+		if err := errors.Join(oldErr, newErr, ctx.Err()); err != nil {
+			stats.Duration = time.Since(start)
+			return stats, err
+		}
+
+		var previous, current []byte
+		switch c := compareRecords(oldRecord, newRecord); {
+		case !newOK || (oldOK && c < 0):
+			previous = oldRecord
+		case !oldOK || c > 0:
+			current = newRecord
+		default:
+			previous, current = oldRecord, newRecord
+		}
+		if err := emit.records(previous, current); err != nil {
+			stats.Duration = time.Since(start)
+			return stats, err
+		}
+		if previous != nil {
+			oldRecord, oldErr, oldOK = nextOld()
+		}
+		if current != nil {
+			newRecord, newErr, newOK = nextNew()
+		}
+	}
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// DiffStore compares a provider file with the providers loaded in store, for example by
+// Load from an earlier file, and publishes a gonpi.ChangeEvent for every provider that
+// changed, in file order. Providers in the store but not in the file are reported as
+// deactivated afterwards, in NPI order, if the store implements gonpi.ProviderScanner;
+// otherwise they are not detected. The store is not modified.
+//
+// The NPIs of the file are tracked in a bulkutil.SeenSet within the memory budget.
+// DiffStore stops at the first read, store or publish error.
+func DiffStore(ctx context.Context, store gonpi.ProviderStore, new io.Reader, publisher gonpi.Publisher, opts ...DiffOption) (DiffStats, error) {
+	config := newDiffConfig(opts)
+	start := time.Now()
+	var stats DiffStats
+	finish := func(err error) (DiffStats, error) {
+		stats.Duration = time.Since(start)
+		return stats, err
+	}
+
+	reader, err := NewReader(new, WithProjection(config.projection))
+	if err != nil {
+		return finish(err)
+	}
+	seen := bulkutil.NewSeenSet(bulkutil.WithDir(config.dir), bulkutil.WithMemory(config.memory))
+	defer seen.Close()
+
+	emit := newEmitter(ctx, publisher, config.now, &stats)
+	for {
+		current, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return finish(err)
+		}
+		stats.New++
+		if added, err := seen.Add(current.Number); err != nil || !added {
+			if err != nil {
+				return finish(err)
+			}
+			continue
+		}
+		previous, err := store.Get(ctx, current.Number)
+		if err != nil {
+			return finish(fmt.Errorf("failed to get NPI %s from store: %w", current.Number, err))
+		}
+		if err := emit.providers(previous, &current); err != nil {
+			return finish(err)
+		}
+	}
+
+	scanner, ok := store.(gonpi.ProviderScanner)
+	if !ok {
+		return finish(nil)
+	}
+	for previous, err := range scanner.All(ctx) {
+		if err != nil {
+			return finish(err)
+		}
+		stats.Old++
+		if found, err := seen.Contains(previous.Number); err != nil || found {
+			if err != nil {
+				return finish(err)
+			}
+			continue
+		}
+		if err := emit.providers(&previous, nil); err != nil {
+			return finish(err)
+		}
+	}
+	return finish(nil)
+}
+
+// newDiffConfig applies opts to the defaults.
+func newDiffConfig(opts []DiffOption) diffConfig {
+	var config diffConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.now.IsZero() {
+		config.now = time.Now().UTC()
+	}
+	return config
+}
+
+// sortProviders reads every provider of r into a Sorter ordered by NPI, counting them
+// in n. Rows repeating an NPI are dropped.
+func sortProviders(ctx context.Context, r io.Reader, config diffConfig, n *int64) (*bulkutil.Sorter, error) {
+	reader, err := NewReader(r, WithProjection(config.projection))
+	if err != nil {
+		return nil, err
+	}
+	sorter := bulkutil.NewSorter(bulkutil.WithDir(config.dir), bulkutil.WithMemory(config.memory),
+		bulkutil.WithCompare(compareRecords), bulkutil.WithUnique())
+	for {
+		provider, err := reader.Next()
+		if err == io.EOF {
+			return sorter, nil
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		var record []byte
+		if err == nil {
+			record, err = encodeRecord(&provider)
+		}
+		if err == nil {
+			err = sorter.Add(record)
+		}
+		if err != nil {
+			sorter.Close()
+			return nil, err
+		}
+		*n++
+	}
+}
+
+// encodeRecord encodes provider as a sort record: the NPI's length and bytes, then the
+// provider as MessagePack.
+func encodeRecord(provider *gonpi.Provider) ([]byte, error) {
+	if len(provider.Number) > 255 {
+		return nil, fmt.Errorf("invalid NPI %.20q", provider.Number)
+	}
+	data, err := gonpi.MsgPackCodec{}.Marshal(provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode NPI %s: %w", provider.Number, err)
+	}
+	record := make([]byte, 0, 1+len(provider.Number)+len(data))
+	record = append(record, byte(len(provider.Number)))
+	record = append(record, provider.Number...)
+	return append(record, data...), nil
+}
+
+// recordKey returns the NPI and the encoded provider of a sort record.
+func recordKey(record []byte) (key, payload []byte) {
+	if len(record) == 0 || len(record) < 1+int(record[0]) {
+		return record, nil
+	}
+	n := 1 + int(record[0])
+	return record[1:n], record[n:]
+}
+
+// compareRecords orders sort records by NPI.
+func compareRecords(a, b []byte) int {
+	keyA, _ := recordKey(a)
+	keyB, _ := recordKey(b)
+	return bytes.Compare(keyA, keyB)
+}
+
+// emitter publishes the events implied by pairs of records.
+type emitter struct {
+	ctx       context.Context
+	publisher gonpi.Publisher
+	now       time.Time
+	stats     *DiffStats
+}
+
+func newEmitter(ctx context.Context, publisher gonpi.Publisher, now time.Time, stats *DiffStats) *emitter {
+	return &emitter{ctx: ctx, publisher: publisher, now: now, stats: stats}
+}
+
+// records publishes the event for the sort records of one NPI; either may be nil.
+// Records with equal encodings are unchanged and are not decoded.
+func (e *emitter) records(previous, current []byte) error {
+	_, oldPayload := recordKey(previous)
+	_, newPayload := recordKey(current)
+	if previous != nil && current != nil && bytes.Equal(oldPayload, newPayload) {
+		e.stats.Unchanged++
+		return nil
+	}
+	decode := func(payload []byte) (*gonpi.Provider, error) {
+		if payload == nil {
+			return nil, nil
+		}
+		var provider gonpi.Provider
+		if err := (gonpi.MsgPackCodec{}).Unmarshal(payload, &provider); err != nil {
+			return nil, fmt.Errorf("failed to decode sorted provider: %w", err)
+		}
+		return &provider, nil
+	}
+	oldProvider, err := decode(oldPayload)
+	if err != nil {
+		return err
+	}
+	newProvider, err := decode(newPayload)
+	if err != nil {
+		return err
+	}
+	return e.publish(oldProvider, newProvider, false)
+}
+
+// providers publishes the event for the records of one NPI; either may be nil. The
+// records are compared by their encodings, like sort records.
+func (e *emitter) providers(previous, current *gonpi.Provider) error {
+	same := false
+	if previous != nil && current != nil {
+		oldData, err := gonpi.MsgPackCodec{}.Marshal(previous)
+		if err != nil {
+			return err
+		}
+		newData, err := gonpi.MsgPackCodec{}.Marshal(current)
+		if err != nil {
+			return err
+		}
+		same = bytes.Equal(oldData, newData)
+	}
+	return e.publish(previous, current, same)
+}
+
+// publish publishes the event implied by the records of one NPI, if any.
+func (e *emitter) publish(previous, current *gonpi.Provider, same bool) error {
+	typ, ok := eventFor(previous, current, same)
+	if !ok {
+		e.stats.Unchanged++
+		return nil
+	}
+	e.stats.count(typ)
+
+	event := gonpi.ChangeEvent{
+		Version:  gonpi.EventSchemaVersion,
+		Type:     typ,
+		Time:     e.now,
+		Provider: current,
+		Previous: previous,
+	}
+	if current != nil {
+		event.NPI = current.Number
+	} else {
+		event.NPI = previous.Number
+	}
+	if current != nil && previous != nil {
+		event.Changes = gonpi.DiffProviders(previous, current)
+	}
+	if err := e.publisher.Publish(e.ctx, event); err != nil {
+		return fmt.Errorf("failed to publish %s for NPI %s: %w", event.Type, event.NPI, err)
+	}
+	return nil
+}
+
+// eventFor returns the event implied by the previous and current records of an NPI,
+// either of which may be nil, following the rules of gonpi.Watcher. same reports
+// whether both records are equal. It returns false if nothing changed.
+func eventFor(previous, current *gonpi.Provider, same bool) (gonpi.EventType, bool) {
+	switch {
+	case previous == nil:
+		return gonpi.EventProviderCreated, true
+	case current == nil:
+		return gonpi.EventProviderDeactivated, active(previous)
+	case !active(previous) && active(current):
+		return gonpi.EventProviderReactivated, true
+	case active(previous) && !active(current):
+		return gonpi.EventProviderDeactivated, true
+	case !same:
+		return gonpi.EventProviderUpdated, true
+	}
+	return "", false
+}
+
+// active reports whether p is listed as active.
+func active(p *gonpi.Provider) bool {
+	return p.Basic.Status == "" || p.Basic.Status == "A"
+}
@@ -0,0 +1,148 @@
+package nppes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// diffFiles returns an old and a new provider file, out of NPI order, covering every
+// kind of change.
+func diffFiles() (old, new string) {
+	row := func(npi, city string, extra ...Field) map[Field]string {
+		r := map[Field]string{FieldNPI: npi, FieldEntityType: "1", FieldLastName: "DOE", FieldLocationCity: city}
+		for _, field := range extra {
+			r[field] = "01/01/2024"
+		}
+		return r
+	}
+	old = writeProviderFile(Layouts[0],
+		row("1000000004", "BOSTON"),                        // deactivated
+		row("1000000001", "BOSTON"),                        // unchanged
+		row("1000000003", "BOSTON"),                        // removed
+		row("1000000002", "BOSTON"),                        // updated
+		row("1000000005", "BOSTON", FieldDeactivationDate), // reactivated
+	)
+	new = writeProviderFile(Layouts[0],
+		row("1000000006", "BOSTON"), // created
+		row("1000000005", "BOSTON", FieldDeactivationDate, FieldReactivationDate),
+		row("1000000002", "CAMBRIDGE"),
+		row("1000000001", "BOSTON"),
+		row("1000000004", "BOSTON", FieldDeactivationDate),
+	)
+	return old, new
+}
+
+// recorder is a Publisher keeping the events it receives.
+type recorder struct {
+	events []gonpi.ChangeEvent
+}
+
+func (r *recorder) Publish(_ context.Context, event gonpi.ChangeEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+// summary returns "NPI type" for each event.
+func (r *recorder) summary() string {
+	var lines []string
+	for _, event := range r.events {
+		lines = append(lines, event.NPI+" "+string(event.Type))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestDiff tests diffing two files, sorting them on disk.
+func TestDiff(t *testing.T) {
+	old, new := diffFiles()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	events := &recorder{}
+	stats, err := Diff(context.Background(), strings.NewReader(old), strings.NewReader(new), events,
+		WithDiffDir(t.TempDir()), WithDiffMemory(1), WithDiffTime(now))
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"1000000002 provider.updated",
+		"1000000003 provider.deactivated",
+		"1000000004 provider.deactivated",
+		"1000000005 provider.reactivated",
+		"1000000006 provider.created",
+	}, "\n")
+	if got := events.summary(); got != want {
+		t.Errorf("events:\n%s\nwant:\n%s", got, want)
+	}
+	wantStats := DiffStats{Old: 5, New: 5, Created: 1, Updated: 1, Deactivated: 2, Reactivated: 1, Unchanged: 1}
+	stats.Duration = 0
+	if stats != wantStats {
+		t.Errorf("stats = %+v, want %+v", stats, wantStats)
+	}
+
+	updated := events.events[0]
+	if updated.Time != now || updated.Version != gonpi.EventSchemaVersion || updated.Previous == nil || updated.Provider == nil {
+		t.Fatalf("unexpected event: %+v", updated)
+	}
+	if len(updated.Changes) != 1 || updated.Changes[0].Field != "addresses" {
+		t.Errorf("changes = %+v", updated.Changes)
+	}
+	if removed := events.events[1]; removed.Provider != nil || removed.Previous == nil {
+		t.Errorf("expected a removal without a current record: %+v", removed)
+	}
+}
+
+// TestDiffStore tests diffing a file against a store loaded from an earlier file.
+func TestDiffStore(t *testing.T) {
+	old, new := diffFiles()
+	store := gonpi.NewMemoryStore()
+	if _, err := Load(context.Background(), strings.NewReader(old), store); err != nil {
+		t.Fatal(err)
+	}
+
+	events := &recorder{}
+	stats, err := DiffStore(context.Background(), store, strings.NewReader(new), events, WithDiffDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("DiffStore: %v", err)
+	}
+	want := strings.Join([]string{
+		"1000000006 provider.created",
+		"1000000005 provider.reactivated",
+		"1000000002 provider.updated",
+		"1000000004 provider.deactivated",
+		"1000000003 provider.deactivated",
+	}, "\n")
+	if got := events.summary(); got != want {
+		t.Errorf("events:\n%s\nwant:\n%s", got, want)
+	}
+	if stats.Old != 5 || stats.New != 5 || stats.Unchanged != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if store.Len() != 5 {
+		t.Errorf("store was modified: %d providers", store.Len())
+	}
+}
+
+// TestDiff_PublishError tests that a failing publisher stops the diff.
+func TestDiff_PublishError(t *testing.T) {
+	old, new := diffFiles()
+	calls := 0
+	failing := gonpi.PublisherFunc(func(context.Context, gonpi.ChangeEvent) error {
+		calls++
+		return errors.New("broker down")
+	})
+	_, err := Diff(context.Background(), strings.NewReader(old), strings.NewReader(new), failing, WithDiffDir(t.TempDir()))
+	if err == nil || !strings.Contains(err.Error(), "broker down") || !strings.Contains(err.Error(), "1000000002") {
+		t.Errorf("expected publish error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the diff to stop after one event, got %d", calls)
+	}
+
+	if _, err := Diff(context.Background(), strings.NewReader("not,a,provider,file\n"), strings.NewReader(new), failing); !errors.Is(err, ErrUnsupportedLayout) {
+		t.Errorf("expected ErrUnsupportedLayout, got %v", err)
+	}
+}
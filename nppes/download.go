@@ -0,0 +1,487 @@
+// Package nppes works with the NPPES Downloadable Files: the monthly full replacement
+// and weekly incremental data dissemination archives published by CMS. It discovers
+// and downloads the archives and streams the CSV files inside them, complementing the
+// registry API client for bulk loads.
+//
+// Example usage:
+//
+//	files, err := nppes.Discover(ctx, nil, nppes.DefaultFilesURL)
+//	monthly, ok := nppes.Latest(files, nppes.KindMonthly)
+//	d := &nppes.Downloader{Progress: func(p nppes.Progress) { log.Print(p) }}
+//	err = d.Download(ctx, monthly, "/data/nppes/"+monthly.Name)
+package nppes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// DefaultFilesURL is the CMS page listing the current dissemination archives.
+const DefaultFilesURL = "https://download.cms.gov/nppes/NPI_Files.html"
+
+// ErrChecksumMismatch indicates that a downloaded file's SHA-256 did not match
+// File.SHA256.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrSizeMismatch indicates that a download ended with a different size than the server
+// announced.
+var ErrSizeMismatch = errors.New("size mismatch")
+
+// Kind classifies dissemination archives.
+type Kind string
+
+// Archive kinds.
+const (
+	// KindMonthly is the full replacement file, published monthly.
+	KindMonthly Kind = "monthly"
+
+	// KindWeekly is an incremental file with the week's changes.
+	KindWeekly Kind = "weekly"
+
+	// KindDeactivation is the monthly deactivated NPI report.
+	KindDeactivation Kind = "deactivation"
+)
+
+// File is a dissemination archive found by Discover.
+type File struct {
+	// Name is the archive's file name, e.g. "NPPES_Data_Dissemination_October_2025.zip".
+	Name string
+
+	// URL is the absolute download URL.
+	URL string
+
+	// Kind classifies the archive.
+	Kind Kind
+
+	// Period is the month (monthly and deactivation files) or the end of the week
+	// (weekly files) the archive covers, or zero if the name does not say.
+	Period time.Time
+
+	// Size is the expected size in bytes, or 0 to trust the server's Content-Length.
+	Size int64
+
+	// SHA256 is the expected hex-encoded SHA-256 checksum, or empty to skip the check.
+	// CMS does not publish checksums, so callers mirroring archives set it themselves.
+	SHA256 string
+}
+
+var (
+	zipLink     = regexp.MustCompile(`(?i)href\s*=\s*["']([^"']+\.zip)["']`)
+	monthlyName = regexp.MustCompile(`(?i)_([a-z]+)_(\d{4})(?:_v\d+)?\.zip$`)
+	weeklyName  = regexp.MustCompile(`(?i)_\d{6}_(\d{6})_weekly(?:_v\d+)?\.zip$`)
+)
+
+// Discover fetches the CMS download page at pageURL and returns the dissemination
+// archives it links to, in page order. A nil httpClient uses one with a gonpi.Transport,
+// which retries transient failures.
+func Discover(ctx context.Context, httpClient *http.Client, pageURL string) ([]File, error) {
+	if httpClient == nil {
+		httpClient = defaultHTTPClient()
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, &gonpi.ValidationError{Field: "url", Message: fmt.Sprintf("invalid page URL: %v", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch download page: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &gonpi.APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("download page returned status %d", resp.StatusCode)}
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, gonpi.MaxResponseBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download page: %w", err)
+	}
+
+	var files []File
+	seen := make(map[string]bool)
+	for _, match := range zipLink.FindAllSubmatch(page, -1) {
+		ref, err := base.Parse(string(match[1]))
+		if err != nil || seen[ref.String()] {
+			continue
+		}
+		seen[ref.String()] = true
+		if file, ok := classify(ref); ok {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// classify turns a link into a File, reporting false for archives that are not
+// dissemination files.
+func classify(ref *url.URL) (File, bool) {
+	name := path.Base(ref.Path)
+	file := File{Name: name, URL: ref.String()}
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.Contains(lower, "deactivat"):
+		file.Kind = KindDeactivation
+	case strings.Contains(lower, "weekly"):
+		file.Kind = KindWeekly
+		if m := weeklyName.FindStringSubmatch(name); m != nil {
+			file.Period, _ = time.Parse("010206", m[1])
+		}
+		return file, true
+	case strings.Contains(lower, "dissemination"):
+		file.Kind = KindMonthly
+	default:
+		return File{}, false
+	}
+
+	if m := monthlyName.FindStringSubmatch(name); m != nil {
+		file.Period, _ = time.Parse("January 2006", m[1]+" "+m[2])
+	}
+	return file, true
+}
+
+// Latest returns the file of the given kind with the most recent Period.
+func Latest(files []File, kind Kind) (File, bool) {
+	var latest File
+	found := false
+	for _, file := range files {
+		if file.Kind == kind && (!found || file.Period.After(latest.Period)) {
+			latest, found = file, true
+		}
+	}
+	return latest, found
+}
+
+// Progress reports how much of a download has completed.
+type Progress struct {
+	// File is the file being downloaded.
+	File File
+
+	// Downloaded counts bytes on disk, including any resumed from a previous attempt.
+	Downloaded int64
+
+	// Total is the expected size, or -1 if unknown.
+	Total int64
+}
+
+// Downloader downloads dissemination archives, resuming interrupted downloads and
+// verifying their size and optional checksum.
+type Downloader struct {
+	// HTTPClient sends requests. If nil, a client with a gonpi.Transport is used.
+	HTTPClient *http.Client
+
+	// Progress, if not nil, is called as data arrives, at most every ProgressInterval.
+	Progress func(Progress)
+
+	// ProgressInterval rate-limits Progress calls. Default: 1 second.
+	ProgressInterval time.Duration
+}
+
+// partSuffix marks incomplete downloads, which Download resumes.
+const partSuffix = ".part"
+
+// validatorSuffix marks the file holding the ETag or Last-Modified value of a download,
+// kept next to the partial file to resume it with If-Range and next to the complete
+// file to check it is current.
+const validatorSuffix = ".validator"
+
+// Download saves file to dest. Data is written to dest+".part" first and renamed into
+// place once its size and checksum are verified; if that file exists from an earlier,
+// interrupted attempt, the download resumes from its end using an HTTP range request.
+// Resuming requires the server's ETag or Last-Modified from the interrupted attempt
+// and is conditional on it with If-Range, so a partial file is never completed with
+// data from a different version of the archive; without one, and whenever the
+// server's Content-Range does not continue the partial file, the download starts over.
+//
+// A complete file already at dest is not downloaded again if it matches file.Size and
+// file.SHA256 or, when neither is set, if it was saved by Download and a conditional
+// HEAD request shows it is still current.
+func (d *Downloader) Download(ctx context.Context, file File, dest string) error {
+	if _, err := os.Stat(dest); err == nil && d.complete(ctx, file, dest) {
+		return nil
+	}
+
+	part := dest + partSuffix
+	out, err := os.OpenFile(part, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", part, err)
+	}
+	defer out.Close()
+
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek %s: %w", part, err)
+	}
+	validator := readValidator(part)
+	if offset > 0 && validator == "" {
+		// Nothing ties the partial file to the current archive; start over
+		if offset, err = restart(out, part); err != nil {
+			return err
+		}
+	}
+
+	resp, err := d.request(ctx, http.MethodGet, file.URL, offset, validator)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := int64(-1)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, length, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			restart(out, part)
+			return fmt.Errorf("download of %s resumed at byte %d, expected %d; partial file discarded", file.Name, start, offset)
+		}
+		total = length
+	case http.StatusOK:
+		// The server ignored the range or the archive changed; start over
+		if offset, err = restart(out, part); err != nil {
+			return err
+		}
+		total = resp.ContentLength
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is complete only if it is as long as the archive, which
+		// the server reports as "bytes */length"
+		start, length, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start >= 0 || length != offset {
+			restart(out, part)
+			return fmt.Errorf("download of %s cannot resume at byte %d; partial file discarded", file.Name, offset)
+		}
+		total = offset
+	default:
+		return &gonpi.APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("download of %s returned status %d", file.Name, resp.StatusCode)}
+	}
+	if file.Size > 0 {
+		total = file.Size
+	}
+
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		if validator := responseValidator(resp.Header); validator != "" {
+			if err := os.WriteFile(part+validatorSuffix, []byte(validator), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", part+validatorSuffix, err)
+			}
+		}
+		body := &progressReader{r: resp.Body, file: file, done: offset, total: total, report: d.Progress, interval: d.ProgressInterval}
+		if body.interval <= 0 {
+			body.interval = time.Second
+		}
+		if _, err := io.Copy(out, body); err != nil {
+			return fmt.Errorf("download of %s interrupted at %d bytes: %w", file.Name, body.done, err)
+		}
+		body.flush()
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", part, err)
+	}
+
+	if total >= 0 {
+		file.Size = total
+	}
+	if err := verifyFile(part, file); err != nil {
+		if errors.Is(err, ErrChecksumMismatch) {
+			os.Remove(part)
+			os.Remove(part + validatorSuffix)
+		}
+		return err
+	}
+	os.Remove(dest + validatorSuffix)
+	if err := os.Rename(part, dest); err != nil {
+		return err
+	}
+	// Keep the validator to recognize the complete file later
+	os.Rename(part+validatorSuffix, dest+validatorSuffix)
+	return nil
+}
+
+// complete reports whether dest already holds file: it matches file's size and
+// checksum or, if file has neither, the server reports the archive unchanged since
+// Download saved dest.
+func (d *Downloader) complete(ctx context.Context, file File, dest string) bool {
+	if file.Size > 0 || file.SHA256 != "" {
+		return verifyFile(dest, file) == nil
+	}
+	validator := readValidator(dest)
+	if validator == "" {
+		return false
+	}
+	resp, err := d.request(ctx, http.MethodHead, file.URL, 0, validator)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusNotModified
+}
+
+// restart empties the partial download out, stored at part, and returns the new offset, 0.
+func restart(out *os.File, part string) (int64, error) {
+	os.Remove(part + validatorSuffix)
+	if err := out.Truncate(0); err != nil {
+		return 0, fmt.Errorf("failed to truncate %s: %w", part, err)
+	}
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek %s: %w", part, err)
+	}
+	return 0, nil
+}
+
+// request sends a method request for url. A GET from a non-zero offset is a range
+// request made conditional on validator with If-Range; a HEAD with a validator is a
+// conditional request answered with 304 Not Modified if the archive is unchanged.
+func (d *Downloader) request(ctx context.Context, method, url string, offset int64, validator string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	switch {
+	case method == http.MethodGet && offset > 0:
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		req.Header.Set("If-Range", validator)
+	case method == http.MethodHead && strings.HasPrefix(validator, `"`):
+		req.Header.Set("If-None-Match", validator)
+	case method == http.MethodHead && validator != "":
+		req.Header.Set("If-Modified-Since", validator)
+	}
+
+	httpClient := d.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient()
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// responseValidator returns the value identifying the version of the archive in a
+// response for If-Range: its strong ETag or else its Last-Modified date, or "" if it
+// has neither. Weak ETags cannot be used with If-Range.
+func responseValidator(header http.Header) string {
+	if etag := header.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// readValidator returns the validator saved next to path, or "" if there is none.
+func readValidator(path string) string {
+	data, err := os.ReadFile(path + validatorSuffix)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// defaultHTTPClient returns a client retrying transient failures, without the
+// registry client's request timeout, which is far too short for gigabyte downloads.
+func defaultHTTPClient() *http.Client {
+	return &http.Client{Transport: gonpi.NewTransport()}
+}
+
+// parseContentRange returns the first byte and complete length from a Content-Range
+// header such as "bytes 100-999/1000", or -1 for either one given as "*", as in
+// "bytes */1000". It reports false if the header is missing or malformed.
+func parseContentRange(contentRange string) (start, length int64, ok bool) {
+	spec, found := strings.CutPrefix(contentRange, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	byteRange, total, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	start, length = -1, -1
+	if byteRange != "*" {
+		first, _, found := strings.Cut(byteRange, "-")
+		n, err := strconv.ParseInt(first, 10, 64)
+		if !found || err != nil {
+			return 0, 0, false
+		}
+		start = n
+	}
+	if total != "*" {
+		n, err := strconv.ParseInt(total, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		length = n
+	}
+	return start, length, true
+}
+
+// verifyFile checks the size and checksum of the file at path against file.
+func verifyFile(path string, file File) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var h hash.Hash
+	var w io.Writer = io.Discard
+	if file.SHA256 != "" {
+		h = sha256.New()
+		w = h
+	}
+	size, err := io.Copy(w, f)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if file.Size > 0 && size != file.Size {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrSizeMismatch, file.Name, size, file.Size)
+	}
+	if h != nil {
+		if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, file.SHA256) {
+			return fmt.Errorf("%w: %s has SHA-256 %s, expected %s", ErrChecksumMismatch, file.Name, sum, file.SHA256)
+		}
+	}
+	return nil
+}
+
+// progressReader reports progress while reading a download body.
+type progressReader struct {
+	r        io.Reader
+	file     File
+	done     int64
+	total    int64
+	report   func(Progress)
+	interval time.Duration
+	last     time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if p.report != nil && time.Since(p.last) >= p.interval {
+		p.flush()
+	}
+	return n, err
+}
+
+// flush reports the current progress.
+func (p *progressReader) flush() {
+	if p.report != nil {
+		p.last = time.Now()
+		p.report(Progress{File: p.file, Downloaded: p.done, Total: p.total})
+	}
+}
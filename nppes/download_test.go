@@ -0,0 +1,248 @@
+package nppes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testPage = `<html><body>
+<a href="NPPES_Data_Dissemination_September_2025.zip">Full (Sep)</a>
+<a href='./NPPES_Data_Dissemination_October_2025_V2.zip'>Full (Oct)</a>
+<a href="/nppes/NPPES_Data_Dissemination_092925_100525_Weekly.zip">Weekly</a>
+<a href="/nppes/NPPES_Data_Dissemination_100625_101225_Weekly.zip">Weekly</a>
+<a href="NPPES_Deactivated_NPI_Report_20251013.zip">Deactivated</a>
+<a href="NPPES_Data_Dissemination_September_2025.zip">Duplicate</a>
+<a href="Readme.pdf">Readme</a>
+<a href="other.zip">Other</a>
+</body></html>`
+
+// TestDiscover tests finding and classifying archive links on the download page.
+func TestDiscover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testPage))
+	}))
+	defer server.Close()
+
+	files, err := Discover(context.Background(), server.Client(), server.URL+"/nppes/NPI_Files.html")
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(files) != 5 {
+		t.Fatalf("expected 5 files, got %+v", files)
+	}
+	if files[1].URL != server.URL+"/nppes/NPPES_Data_Dissemination_October_2025_V2.zip" {
+		t.Errorf("relative link resolved to %s", files[1].URL)
+	}
+
+	monthly, ok := Latest(files, KindMonthly)
+	if !ok || monthly.Period != time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("latest monthly = %+v", monthly)
+	}
+	weekly, ok := Latest(files, KindWeekly)
+	if !ok || weekly.Period != time.Date(2025, time.October, 12, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("latest weekly = %+v", weekly)
+	}
+	if _, ok := Latest(files, KindDeactivation); !ok {
+		t.Error("expected a deactivation report")
+	}
+}
+
+// newArchiveServer serves content with range support and counts requests with a
+// Range header.
+func newArchiveServer(content []byte, ranged *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			*ranged++
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+// TestDownload_Resume tests resuming a partial download and verifying its checksum.
+func TestDownload_Resume(t *testing.T) {
+	content := bytes.Repeat([]byte("npidata,"), 4096)
+	sum := sha256.Sum256(content)
+	var ranged int
+	server := newArchiveServer(content, &ranged)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "archive.zip")
+	if err := os.WriteFile(dest+partSuffix, content[:1000], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest+partSuffix+validatorSuffix, []byte(`"v1"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var last Progress
+	d := &Downloader{HTTPClient: server.Client(), Progress: func(p Progress) { last = p }, ProgressInterval: time.Nanosecond}
+	file := File{Name: "archive.zip", URL: server.URL + "/archive.zip", SHA256: hex.EncodeToString(sum[:])}
+	if err := d.Download(context.Background(), file, dest); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded %d bytes, want %d", len(got), len(content))
+	}
+	if ranged != 1 {
+		t.Errorf("expected a range request, got %d", ranged)
+	}
+	if last.Downloaded != int64(len(content)) || last.Total != int64(len(content)) {
+		t.Errorf("final progress = %+v", last)
+	}
+	if _, err := os.Stat(dest + partSuffix); !os.IsNotExist(err) {
+		t.Error("partial file left behind")
+	}
+
+	// A verified file is not fetched again
+	server.Close()
+	if err := d.Download(context.Background(), file, dest); err != nil {
+		t.Errorf("second Download: %v", err)
+	}
+}
+
+// TestDownload_ResumeUnsafe tests that partial files are not resumed when nothing
+// shows they belong to the current archive.
+func TestDownload_ResumeUnsafe(t *testing.T) {
+	content := bytes.Repeat([]byte("npidata,"), 512)
+	var ranged int
+	server := newArchiveServer(content, &ranged)
+	defer server.Close()
+	d := &Downloader{HTTPClient: server.Client()}
+	ctx := context.Background()
+	file := File{Name: "archive.zip", URL: server.URL}
+
+	tests := []struct {
+		name      string
+		part      []byte
+		validator string
+		wantErr   bool
+	}{
+		{"no validator", []byte("stale data"), "", false},
+		{"changed archive", []byte("stale data"), `"v0"`, false},
+		{"already complete", content, `"v1"`, false},
+		{"longer than archive", append(slices.Clone(content), "extra"...), `"v1"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "archive.zip")
+			os.WriteFile(dest+partSuffix, tt.part, 0o644)
+			if tt.validator != "" {
+				os.WriteFile(dest+partSuffix+validatorSuffix, []byte(tt.validator), 0o644)
+			}
+			err := d.Download(ctx, file, dest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if info, _ := os.Stat(dest + partSuffix); info != nil && info.Size() != 0 {
+					t.Errorf("partial file kept with %d bytes", info.Size())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Download: %v", err)
+			}
+			if got, _ := os.ReadFile(dest); !bytes.Equal(got, content) {
+				t.Errorf("downloaded %d bytes, want %d", len(got), len(content))
+			}
+		})
+	}
+}
+
+// TestDownload_Unverified tests that, without a size or checksum, an existing file is
+// kept only if Download saved it and the server reports it unchanged.
+func TestDownload_Unverified(t *testing.T) {
+	content := []byte(strings.Repeat("x", 500))
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets.Add(1)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "archive.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	d := &Downloader{HTTPClient: server.Client()}
+	ctx := context.Background()
+	file := File{Name: "archive.zip", URL: server.URL}
+
+	dest := filepath.Join(t.TempDir(), "archive.zip")
+	if err := os.WriteFile(dest, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Download(ctx, file, dest); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if got, _ := os.ReadFile(dest); !bytes.Equal(got, content) || gets.Load() != 1 {
+		t.Errorf("empty file not replaced: %d bytes after %d requests", len(got), gets.Load())
+	}
+
+	if err := d.Download(ctx, file, dest); err != nil {
+		t.Fatalf("second Download: %v", err)
+	}
+	if gets.Load() != 1 {
+		t.Errorf("unchanged file downloaded again")
+	}
+}
+
+// TestParseContentRange tests parsing Content-Range headers.
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header        string
+		start, length int64
+		ok            bool
+	}{
+		{"bytes 100-999/1000", 100, 1000, true},
+		{"bytes 0-99/*", 0, -1, true},
+		{"bytes */1000", -1, 1000, true},
+		{"", 0, 0, false},
+		{"bytes 100/1000", 0, 0, false},
+		{"items 0-9/10", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, length, ok := parseContentRange(tt.header)
+		if start != tt.start || length != tt.length || ok != tt.ok {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v", tt.header, start, length, ok)
+		}
+	}
+}
+
+// TestDownload_Verification tests checksum and size failures.
+func TestDownload_Verification(t *testing.T) {
+	content := []byte(strings.Repeat("x", 500))
+	var ranged int
+	server := newArchiveServer(content, &ranged)
+	defer server.Close()
+
+	dir := t.TempDir()
+	d := &Downloader{HTTPClient: server.Client()}
+	ctx := context.Background()
+
+	bad := File{Name: "a.zip", URL: server.URL, SHA256: strings.Repeat("0", 64)}
+	if err := d.Download(ctx, bad, filepath.Join(dir, "a.zip")); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.zip"+partSuffix)); !os.IsNotExist(err) {
+		t.Error("corrupt partial file kept")
+	}
+
+	short := File{Name: "b.zip", URL: server.URL, Size: 600}
+	if err := d.Download(ctx, short, filepath.Join(dir, "b.zip")); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("expected ErrSizeMismatch, got %v", err)
+	}
+}
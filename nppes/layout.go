@@ -0,0 +1,272 @@
+package nppes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnsupportedLayout indicates that a file's header matches no known layout of the
+// NPPES provider file.
+var ErrUnsupportedLayout = errors.New("unsupported NPPES file layout")
+
+// Field is a logical column of the NPPES provider file, independent of the header name
+// a particular layout gives it.
+type Field string
+
+// Single-valued fields of the provider file.
+const (
+	FieldNPI                   Field = "npi"
+	FieldEntityType            Field = "entity_type"
+	FieldReplacementNPI        Field = "replacement_npi"
+	FieldOrganizationName      Field = "organization_name"
+	FieldLastName              Field = "last_name"
+	FieldFirstName             Field = "first_name"
+	FieldMiddleName            Field = "middle_name"
+	FieldNamePrefix            Field = "name_prefix"
+	FieldNameSuffix            Field = "name_suffix"
+	FieldCredential            Field = "credential"
+	FieldMailingAddress1       Field = "mailing_address_1"
+	FieldMailingAddress2       Field = "mailing_address_2"
+	FieldMailingCity           Field = "mailing_city"
+	FieldMailingState          Field = "mailing_state"
+	FieldMailingPostalCode     Field = "mailing_postal_code"
+	FieldMailingCountryCode    Field = "mailing_country_code"
+	FieldMailingTelephone      Field = "mailing_telephone"
+	FieldMailingFax            Field = "mailing_fax"
+	FieldLocationAddress1      Field = "location_address_1"
+	FieldLocationAddress2      Field = "location_address_2"
+	FieldLocationCity          Field = "location_city"
+	FieldLocationState         Field = "location_state"
+	FieldLocationPostalCode    Field = "location_postal_code"
+	FieldLocationCountryCode   Field = "location_country_code"
+	FieldLocationTelephone     Field = "location_telephone"
+	FieldLocationFax           Field = "location_fax"
+	FieldEnumerationDate       Field = "enumeration_date"
+	FieldLastUpdateDate        Field = "last_update_date"
+	FieldDeactivationReason    Field = "deactivation_reason"
+	FieldDeactivationDate      Field = "deactivation_date"
+	FieldReactivationDate      Field = "reactivation_date"
+	FieldGender                Field = "gender"
+	FieldOfficialLastName      Field = "official_last_name"
+	FieldOfficialFirstName     Field = "official_first_name"
+	FieldOfficialMiddleName    Field = "official_middle_name"
+	FieldOfficialTitle         Field = "official_title"
+	FieldOfficialTelephone     Field = "official_telephone"
+	FieldOfficialCredential    Field = "official_credential"
+	FieldSoleProprietor        Field = "sole_proprietor"
+	FieldOrganizationalSubpart Field = "organizational_subpart"
+	FieldCertificationDate     Field = "certification_date"
+)
+
+// Repeated field groups of the provider file, numbered from 1. Use Indexed to name a
+// member of a group.
+const (
+	FieldTaxonomyCode     Field = "taxonomy_code"
+	FieldTaxonomyLicense  Field = "taxonomy_license"
+	FieldTaxonomyState    Field = "taxonomy_state"
+	FieldTaxonomyPrimary  Field = "taxonomy_primary"
+	FieldTaxonomyGroup    Field = "taxonomy_group"
+	FieldIdentifier       Field = "identifier"
+	FieldIdentifierType   Field = "identifier_type"
+	FieldIdentifierState  Field = "identifier_state"
+	FieldIdentifierIssuer Field = "identifier_issuer"
+)
+
+// Sizes of the repeated field groups.
+const (
+	// MaxTaxonomies is the number of taxonomy and license column groups.
+	MaxTaxonomies = 15
+
+	// MaxOtherIdentifiers is the number of other provider identifier column groups.
+	MaxOtherIdentifiers = 50
+)
+
+// Indexed returns member n of a repeated field group, e.g. Indexed(FieldTaxonomyCode, 1).
+func Indexed(group Field, n int) Field {
+	return Field(fmt.Sprintf("%s_%d", group, n))
+}
+
+// baseColumns are the header names shared by every layout.
+var baseColumns = map[Field]string{
+	FieldNPI:                   "NPI",
+	FieldEntityType:            "Entity Type Code",
+	FieldReplacementNPI:        "Replacement NPI",
+	FieldOrganizationName:      "Provider Organization Name (Legal Business Name)",
+	FieldLastName:              "Provider Last Name (Legal Name)",
+	FieldFirstName:             "Provider First Name",
+	FieldMiddleName:            "Provider Middle Name",
+	FieldNamePrefix:            "Provider Name Prefix Text",
+	FieldNameSuffix:            "Provider Name Suffix Text",
+	FieldCredential:            "Provider Credential Text",
+	FieldMailingAddress1:       "Provider First Line Business Mailing Address",
+	FieldMailingAddress2:       "Provider Second Line Business Mailing Address",
+	FieldMailingCity:           "Provider Business Mailing Address City Name",
+	FieldMailingState:          "Provider Business Mailing Address State Name",
+	FieldMailingPostalCode:     "Provider Business Mailing Address Postal Code",
+	FieldMailingCountryCode:    "Provider Business Mailing Address Country Code (If outside U.S.)",
+	FieldMailingTelephone:      "Provider Business Mailing Address Telephone Number",
+	FieldMailingFax:            "Provider Business Mailing Address Fax Number",
+	FieldLocationAddress1:      "Provider First Line Business Practice Location Address",
+	FieldLocationAddress2:      "Provider Second Line Business Practice Location Address",
+	FieldLocationCity:          "Provider Business Practice Location Address City Name",
+	FieldLocationState:         "Provider Business Practice Location Address State Name",
+	FieldLocationPostalCode:    "Provider Business Practice Location Address Postal Code",
+	FieldLocationCountryCode:   "Provider Business Practice Location Address Country Code (If outside U.S.)",
+	FieldLocationTelephone:     "Provider Business Practice Location Address Telephone Number",
+	FieldLocationFax:           "Provider Business Practice Location Address Fax Number",
+	FieldEnumerationDate:       "Provider Enumeration Date",
+	FieldLastUpdateDate:        "Last Update Date",
+	FieldDeactivationReason:    "NPI Deactivation Reason Code",
+	FieldDeactivationDate:      "NPI Deactivation Date",
+	FieldReactivationDate:      "NPI Reactivation Date",
+	FieldOfficialLastName:      "Authorized Official Last Name",
+	FieldOfficialFirstName:     "Authorized Official First Name",
+	FieldOfficialMiddleName:    "Authorized Official Middle Name",
+	FieldOfficialTitle:         "Authorized Official Title or Position",
+	FieldOfficialTelephone:     "Authorized Official Telephone Number",
+	FieldOfficialCredential:    "Authorized Official Credential Text",
+	FieldSoleProprietor:        "Is Sole Proprietor",
+	FieldOrganizationalSubpart: "Is Organization Subpart",
+}
+
+// Layout is one version of the provider file's header.
+type Layout struct {
+	// Name identifies the layout by the year it was introduced.
+	Name string
+
+	columns map[Field]string
+}
+
+// Column returns the header name the layout uses for field, or false if the layout
+// does not have it.
+func (l *Layout) Column(field Field) (string, bool) {
+	name, ok := l.columns[field]
+	return name, ok
+}
+
+// newLayout builds a layout from the base columns, the repeated groups and extra.
+// Taxonomy group columns are only included if taxonomyGroups is set.
+func newLayout(name string, taxonomyGroups bool, extra map[Field]string) *Layout {
+	columns := make(map[Field]string, len(baseColumns)+len(extra)+5*MaxTaxonomies+4*MaxOtherIdentifiers)
+	for field, column := range baseColumns {
+		columns[field] = column
+	}
+	for n := 1; n <= MaxTaxonomies; n++ {
+		columns[Indexed(FieldTaxonomyCode, n)] = fmt.Sprintf("Healthcare Provider Taxonomy Code_%d", n)
+		columns[Indexed(FieldTaxonomyLicense, n)] = fmt.Sprintf("Provider License Number_%d", n)
+		columns[Indexed(FieldTaxonomyState, n)] = fmt.Sprintf("Provider License Number State Code_%d", n)
+		columns[Indexed(FieldTaxonomyPrimary, n)] = fmt.Sprintf("Healthcare Provider Primary Taxonomy Switch_%d", n)
+		if taxonomyGroups {
+			columns[Indexed(FieldTaxonomyGroup, n)] = fmt.Sprintf("Healthcare Provider Taxonomy Group_%d", n)
+		}
+	}
+	for n := 1; n <= MaxOtherIdentifiers; n++ {
+		columns[Indexed(FieldIdentifier, n)] = fmt.Sprintf("Other Provider Identifier_%d", n)
+		columns[Indexed(FieldIdentifierType, n)] = fmt.Sprintf("Other Provider Identifier Type Code_%d", n)
+		columns[Indexed(FieldIdentifierState, n)] = fmt.Sprintf("Other Provider Identifier State_%d", n)
+		columns[Indexed(FieldIdentifierIssuer, n)] = fmt.Sprintf("Other Provider Identifier Issuer_%d", n)
+	}
+	for field, column := range extra {
+		columns[field] = column
+	}
+	return &Layout{Name: name, columns: columns}
+}
+
+// Layouts lists the known provider file layouts, newest first.
+var Layouts = []*Layout{
+	// Gender became sex
+	newLayout("2024", true, map[Field]string{
+		FieldGender:            "Provider Sex Code",
+		FieldCertificationDate: "Certification Date",
+	}),
+	// Certification date added
+	newLayout("2019", true, map[Field]string{
+		FieldGender:            "Provider Gender Code",
+		FieldCertificationDate: "Certification Date",
+	}),
+	// Taxonomy groups added
+	newLayout("2011", true, map[Field]string{
+		FieldGender: "Provider Gender Code",
+	}),
+	// Original layout
+	newLayout("2007", false, map[Field]string{
+		FieldGender: "Provider Gender Code",
+	}),
+}
+
+// Mapping locates a layout's fields in a particular header.
+type Mapping struct {
+	// Layout is the detected layout.
+	Layout *Layout
+
+	index map[Field]int
+}
+
+// Index returns the position of field in the header, or -1 if it is absent.
+func (m *Mapping) Index(field Field) int {
+	if i, ok := m.index[field]; ok {
+		return i
+	}
+	return -1
+}
+
+// Value returns field from row, or "" if it is absent.
+func (m *Mapping) Value(row []string, field Field) string {
+	if i, ok := m.index[field]; ok && i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+// normalizeColumn folds a header name for comparison.
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+// DetectLayout fingerprints header and returns the newest layout whose columns are all
+// present, mapped to their positions. Extra columns are ignored, so layouts gaining
+// columns keep loading. If no layout matches, the error wraps ErrUnsupportedLayout and
+// names the columns missing from the closest layout.
+func DetectLayout(header []string) (*Mapping, error) {
+	return detectLayout(header, nil)
+}
+
+// detectLayout is DetectLayout with columns overriding the header names of the
+// layouts' fields.
+func detectLayout(header []string, columns map[Field]string) (*Mapping, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[normalizeColumn(name)] = i
+	}
+
+	var closest *Layout
+	var closestMissing []string
+	for _, layout := range Layouts {
+		index := make(map[Field]int, len(layout.columns))
+		var missing []string
+		for field, column := range layout.columns {
+			if override, ok := columns[field]; ok {
+				column = override
+			}
+			if i, ok := positions[normalizeColumn(column)]; ok {
+				index[field] = i
+			} else {
+				missing = append(missing, column)
+			}
+		}
+		if len(missing) == 0 {
+			return &Mapping{Layout: layout, index: index}, nil
+		}
+		if closest == nil || len(missing) < len(closestMissing) {
+			closest, closestMissing = layout, missing
+		}
+	}
+
+	sort.Strings(closestMissing)
+	if len(closestMissing) > 5 {
+		closestMissing = append(closestMissing[:5], fmt.Sprintf("and %d more", len(closestMissing)-5))
+	}
+	return nil, fmt.Errorf("%w: closest is the %s layout, missing %s", ErrUnsupportedLayout, closest.Name, strings.Join(closestMissing, ", "))
+}
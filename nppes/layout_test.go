@@ -0,0 +1,80 @@
+package nppes
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// headerFor returns a header with every column of layout, in a stable order.
+func headerFor(layout *Layout) []string {
+	header := make([]string, 0, len(layout.columns))
+	for _, column := range layout.columns {
+		header = append(header, column)
+	}
+	sort.Strings(header)
+	return header
+}
+
+// layoutByName returns the known layout with the given name.
+func layoutByName(t *testing.T, name string) *Layout {
+	t.Helper()
+	for _, layout := range Layouts {
+		if layout.Name == name {
+			return layout
+		}
+	}
+	t.Fatalf("no layout %s", name)
+	return nil
+}
+
+// TestDetectLayout tests fingerprinting each known layout.
+func TestDetectLayout(t *testing.T) {
+	for _, layout := range Layouts {
+		t.Run(layout.Name, func(t *testing.T) {
+			header := headerFor(layout)
+			// Extra columns and quoting differences are tolerated
+			header = append(header, "Some Future Column")
+			header[0] = "\ufeff" + strings.ToUpper(header[0])
+
+			mapping, err := DetectLayout(header)
+			if err != nil {
+				t.Fatalf("DetectLayout: %v", err)
+			}
+			if mapping.Layout.Name != layout.Name {
+				t.Errorf("detected %s, want %s", mapping.Layout.Name, layout.Name)
+			}
+			if mapping.Index(FieldNPI) < 0 || mapping.Index(Indexed(FieldIdentifier, 50)) < 0 {
+				t.Error("expected NPI and identifier columns to be mapped")
+			}
+		})
+	}
+
+	if _, ok := layoutByName(t, "2007").Column(FieldTaxonomyGroup + "_1"); ok {
+		t.Error("2007 layout should not have taxonomy groups")
+	}
+}
+
+// TestDetectLayout_Unsupported tests the error for unknown headers.
+func TestDetectLayout_Unsupported(t *testing.T) {
+	header := headerFor(layoutByName(t, "2024"))
+	for i, column := range header {
+		if column == "Provider Sex Code" {
+			header = append(header[:i], header[i+1:]...)
+			break
+		}
+	}
+
+	_, err := DetectLayout(header)
+	if !errors.Is(err, ErrUnsupportedLayout) {
+		t.Fatalf("expected ErrUnsupportedLayout, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "2024") || !strings.Contains(err.Error(), "Provider Sex Code") {
+		t.Errorf("error should name the closest layout and missing column: %v", err)
+	}
+
+	if _, err := DetectLayout([]string{"NPI", "Name"}); !strings.Contains(err.Error(), "more") {
+		t.Errorf("expected a truncated missing column list: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package nppes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// LoadOption configures Load.
+type LoadOption func(*loadConfig)
+
+// loadConfig holds the settings applied by LoadOptions.
+type loadConfig struct {
+	workers    int
+	inserters  int
+	batch      int
+	buffer     int
+	progress   func(LoadStats)
+	interval   time.Duration
+	projection Projection
+	arena      bool
+}
+
+// WithLoadWorkers sets the number of goroutines converting rows to providers.
+// Default: runtime.GOMAXPROCS(0).
+func WithLoadWorkers(n int) LoadOption {
+	return func(c *loadConfig) {
+		c.workers = n
+	}
+}
+
+// WithLoadInserters sets the number of goroutines writing providers to the store.
+// Stores that serialize writes, such as MemoryStore, gain little from more than one;
+// database-backed stores usually benefit from several. Default: 1.
+func WithLoadInserters(n int) LoadOption {
+	return func(c *loadConfig) {
+		c.inserters = n
+	}
+}
+
+// WithLoadBuffer sets how many batches of rows may wait between stages, bounding
+// memory when a later stage falls behind. Default: 4 per worker.
+func WithLoadBuffer(batches int) LoadOption {
+	return func(c *loadConfig) {
+		c.buffer = batches
+	}
+}
+
+// WithLoadProgress calls report with running totals at most every interval, and once
+// more when the load ends.
+func WithLoadProgress(interval time.Duration, report func(LoadStats)) LoadOption {
+	return func(c *loadConfig) {
+		c.interval = interval
+		c.progress = report
+	}
+}
+
+// WithLoadProjection limits the columns materialized for each provider. See Projection.
+func WithLoadProjection(p Projection) LoadOption {
+	return func(c *loadConfig) {
+		c.projection = p
+	}
+}
+
+// WithLoadArena makes the providers of each batch share their backing arrays, and
+// recycles the arrays for a later batch once the store's Put returns. This removes
+// most per-row allocations, and with them much of the garbage collection time of
+// multi-million-row loads.
+//
+// The store must not keep the Addresses, Taxonomies or Identifiers slices of the
+// providers it is given after Put returns; strings and the Provider values themselves
+// may be kept. Stores that encode providers or write them to a database qualify;
+// MemoryStore does not.
+func WithLoadArena() LoadOption {
+	return func(c *loadConfig) {
+		c.arena = true
+	}
+}
+
+// LoadStats counts the work done by Load.
+type LoadStats struct {
+	// Rows is the number of rows parsed.
+	Rows int64
+
+	// Stored is the number of providers written to the store.
+	Stored int64
+
+	// Duration is the time spent so far.
+	Duration time.Duration
+}
+
+// loadBatchSize is the number of rows passed between stages at once.
+const loadBatchSize = 256
+
+// Load reads the provider file from r and writes every provider to store. Parsing,
+// conversion and store writes run as separate stages connected by bounded channels:
+// one goroutine parses CSV rows, a pool of workers converts them to providers, and
+// inserters write them to the store, so a full file keeps several cores busy without
+// buffering more than a few batches. Providers reach the store in no particular order.
+// The first error from any stage stops the load and is returned with the totals so far.
+//
+// Example usage:
+//
+//	data, err := archive.Open(nppes.EntryData)
+//	stats, err := nppes.Load(ctx, data, store, nppes.WithLoadWorkers(8))
+func Load(ctx context.Context, r io.Reader, store gonpi.ProviderStore, opts ...LoadOption) (LoadStats, error) {
+	config := loadConfig{workers: runtime.GOMAXPROCS(0), inserters: 1, batch: loadBatchSize}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	config.workers = max(1, config.workers)
+	config.inserters = max(1, config.inserters)
+	if config.buffer <= 0 {
+		config.buffer = 4 * config.workers
+	}
+
+	reader, err := NewReader(r, WithProjection(config.projection))
+	if err != nil {
+		return LoadStats{}, err
+	}
+	// Rows are handed to other goroutines, so each needs its own slice unless the
+	// arena copies it
+	reader.reader.ReuseRecord = config.arena
+	arenas := sync.Pool{New: func() any { return newArena(config.batch, config.arena) }}
+	nextBatch := func() *arena {
+		if config.arena {
+			return arenas.Get().(*arena)
+		}
+		return newArena(config.batch, false)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		failOnce sync.Once
+		failure  error
+	)
+	fail := func(err error) {
+		failOnce.Do(func() {
+			failure = err
+			cancel()
+		})
+	}
+
+	start := time.Now()
+	var rows, stored atomic.Int64
+	snapshot := func() LoadStats {
+		return LoadStats{Rows: rows.Load(), Stored: stored.Load(), Duration: time.Since(start)}
+	}
+
+	rowBatches := make(chan *arena, config.buffer)
+	providerBatches := make(chan *arena, config.buffer)
+	var stages, converters sync.WaitGroup
+
+	// Parse
+	stages.Add(1)
+	go func() {
+		defer stages.Done()
+		defer close(rowBatches)
+		batch := nextBatch()
+		for {
+			row, err := reader.reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				fail(fmt.Errorf("failed to parse provider file: %w", err))
+				return
+			}
+			rows.Add(1)
+			batch.addRow(row)
+			if len(batch.rows) == config.batch {
+				if !send(ctx, rowBatches, batch) {
+					return
+				}
+				batch = nextBatch()
+			}
+		}
+		if len(batch.rows) > 0 {
+			send(ctx, rowBatches, batch)
+		}
+	}()
+
+	// Convert
+	for range config.workers {
+		stages.Add(1)
+		converters.Add(1)
+		go func() {
+			defer stages.Done()
+			defer converters.Done()
+			for batch := range rowBatches {
+				batch.convert(reader)
+				if !send(ctx, providerBatches, batch) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		converters.Wait()
+		close(providerBatches)
+	}()
+
+	// Insert
+	for range config.inserters {
+		stages.Add(1)
+		go func() {
+			defer stages.Done()
+			for batch := range providerBatches {
+				if err := store.Put(ctx, batch.providers...); err != nil {
+					fail(err)
+					return
+				}
+				stored.Add(int64(len(batch.providers)))
+				if config.arena {
+					batch.reset()
+					arenas.Put(batch)
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		stages.Wait()
+		close(done)
+	}()
+	if config.progress != nil && config.interval > 0 {
+		ticker := time.NewTicker(config.interval)
+		defer ticker.Stop()
+	progress:
+		for {
+			select {
+			case <-done:
+				break progress
+			case <-ticker.C:
+				config.progress(snapshot())
+			}
+		}
+	}
+	<-done
+
+	stats := snapshot()
+	if config.progress != nil {
+		config.progress(stats)
+	}
+	if failure == nil {
+		failure = parent.Err()
+	}
+	return stats, failure
+}
+
+// send delivers v on ch, reporting false if ctx is done first.
+func send[T any](ctx context.Context, ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
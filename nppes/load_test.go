@@ -0,0 +1,134 @@
+package nppes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// syntheticFile returns a 2024-layout provider file with n individual providers.
+func syntheticFile(t testing.TB, n int) string {
+	layout := Layouts[0]
+	rows := make([]map[Field]string, n)
+	for i := range rows {
+		rows[i] = map[Field]string{
+			FieldNPI:                       fmt.Sprintf("%010d", 1000000000+i),
+			FieldEntityType:                "1",
+			FieldFirstName:                 "JANE",
+			FieldLastName:                  fmt.Sprintf("DOE%d", i),
+			FieldLocationCity:              "BOSTON",
+			FieldLocationState:             "MA",
+			FieldLocationPostalCode:        "02115",
+			FieldLocationTelephone:         "6175550100",
+			Indexed(FieldTaxonomyCode, 1):  "207R00000X",
+			Indexed(FieldTaxonomyState, 1): "MA",
+		}
+	}
+	return writeProviderFile(layout, rows...)
+}
+
+// TestLoad tests loading every row into a store with several workers.
+func TestLoad(t *testing.T) {
+	file := syntheticFile(t, 1000)
+	store := gonpi.NewMemoryStore()
+
+	var reports int
+	stats, err := Load(context.Background(), strings.NewReader(file), store,
+		WithLoadWorkers(4), WithLoadInserters(2), WithLoadBuffer(2),
+		WithLoadProgress(0, func(LoadStats) { reports++ }))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if stats.Rows != 1000 || stats.Stored != 1000 || store.Len() != 1000 {
+		t.Errorf("stats = %+v, store has %d", stats, store.Len())
+	}
+	if reports != 1 {
+		t.Errorf("expected a final progress report, got %d", reports)
+	}
+
+	p, err := store.Get(context.Background(), "1000000999")
+	if err != nil || p == nil || p.Basic.LastName != "DOE999" {
+		t.Errorf("Get = %+v, %v", p, err)
+	}
+}
+
+// failingStore is a ProviderStore whose Put fails after limit providers.
+type failingStore struct {
+	*gonpi.MemoryStore
+	limit int
+}
+
+func (s *failingStore) Put(ctx context.Context, providers ...gonpi.Provider) error {
+	if s.Len()+len(providers) > s.limit {
+		return errors.New("disk full")
+	}
+	return s.MemoryStore.Put(ctx, providers...)
+}
+
+// TestLoad_Errors tests that store and parse errors stop the load.
+func TestLoad_Errors(t *testing.T) {
+	file := syntheticFile(t, 2000)
+	store := &failingStore{MemoryStore: gonpi.NewMemoryStore(), limit: 600}
+	stats, err := Load(context.Background(), strings.NewReader(file), store, WithLoadWorkers(4))
+	if err == nil || err.Error() != "disk full" {
+		t.Fatalf("expected store error, got %v", err)
+	}
+	if stats.Stored > 600 {
+		t.Errorf("stored %d past the failure", stats.Stored)
+	}
+
+	broken := file + "\"unterminated\n"
+	if _, err := Load(context.Background(), strings.NewReader(broken), gonpi.NewMemoryStore()); err == nil || !strings.Contains(err.Error(), "line") {
+		t.Errorf("expected a parse error with line number, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Load(ctx, strings.NewReader(file), gonpi.NewMemoryStore()); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// discardStore is a ProviderStore that drops everything, isolating parse and
+// conversion throughput.
+type discardStore struct {
+	gonpi.ProviderStore
+}
+
+func (discardStore) Put(context.Context, ...gonpi.Provider) error {
+	return nil
+}
+
+// BenchmarkLoad benchmarks loading a provider file with one and several workers, into
+// a store that discards providers and into a MemoryStore, with and without a projection.
+func BenchmarkLoad(b *testing.B) {
+	file := syntheticFile(b, 5000)
+	stores := map[string]func() gonpi.ProviderStore{
+		"discard": func() gonpi.ProviderStore { return discardStore{} },
+		"memory":  func() gonpi.ProviderStore { return gonpi.NewMemoryStore() },
+	}
+	projections := map[string]Projection{
+		"all":       {},
+		"projected": {Skip: EntityIdentifiers | EntityMailingAddress | EntityAuthorizedOfficial},
+	}
+	for name, newStore := range stores {
+		for projectionName, projection := range projections {
+			for _, workers := range []int{1, 8} {
+				b.Run(fmt.Sprintf("%s/%s/workers=%d", name, projectionName, workers), func(b *testing.B) {
+					b.SetBytes(int64(len(file)))
+					for i := 0; i < b.N; i++ {
+						_, err := Load(context.Background(), strings.NewReader(file), newStore(),
+							WithLoadWorkers(workers), WithLoadProjection(projection))
+						if err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
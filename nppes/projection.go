@@ -0,0 +1,166 @@
+package nppes
+
+import "strings"
+
+// Entity is a group of related provider file columns that a Projection can leave out.
+// Entities combine with |.
+type Entity uint
+
+// Entities of the provider file.
+const (
+	// EntityLocationAddress is the practice location address and phone numbers.
+	EntityLocationAddress Entity = 1 << iota
+
+	// EntityMailingAddress is the business mailing address and phone numbers.
+	EntityMailingAddress
+
+	// EntityAuthorizedOfficial is the organization's authorized official.
+	EntityAuthorizedOfficial
+
+	// EntityTaxonomies is the 15 taxonomy and license column groups.
+	EntityTaxonomies
+
+	// EntityIdentifiers is the 50 other provider identifier column groups.
+	EntityIdentifiers
+)
+
+// officialFields are the columns of EntityAuthorizedOfficial.
+var officialFields = []Field{
+	FieldOfficialLastName, FieldOfficialFirstName, FieldOfficialMiddleName,
+	FieldOfficialTitle, FieldOfficialTelephone, FieldOfficialCredential,
+}
+
+// Projection selects the parts of each row a Reader materializes. The zero value
+// keeps everything. Leaving out unneeded entities, most often the other provider
+// identifiers, skips their conversion and keeps them out of the store.
+//
+// Example usage:
+//
+//	projection := nppes.Projection{Skip: nppes.EntityIdentifiers | nppes.EntityMailingAddress}
+//	stats, err := nppes.Load(ctx, data, store, nppes.WithLoadProjection(projection))
+type Projection struct {
+	// Skip lists the entities to leave out.
+	Skip Entity
+
+	// Fields, if not empty, keeps only these single-valued fields; FieldNPI is always
+	// kept. An address is left out when none of its fields are kept. Repeated groups
+	// are controlled by Skip alone.
+	Fields []Field
+
+	// Columns maps fields to the header names to read them from, for files whose
+	// columns were renamed by an export tool. Names replace those of the known
+	// layouts during detection.
+	Columns map[Field]string
+}
+
+// skips reports whether entity is left out.
+func (p Projection) skips(entity Entity) bool {
+	return p.Skip&entity != 0
+}
+
+// isZero reports whether p keeps everything.
+func (p Projection) isZero() bool {
+	return p.Skip == 0 && len(p.Fields) == 0
+}
+
+// apply returns m restricted to the single-valued fields p keeps. Repeated group
+// members pass through unchanged.
+func (p Projection) apply(m *Mapping) *Mapping {
+	if p.isZero() {
+		return m
+	}
+	var keep map[Field]bool
+	if len(p.Fields) > 0 {
+		keep = map[Field]bool{FieldNPI: true}
+		for _, field := range p.Fields {
+			keep[field] = true
+		}
+	}
+	drop := make(map[Field]bool)
+	if p.skips(EntityAuthorizedOfficial) {
+		for _, field := range officialFields {
+			drop[field] = true
+		}
+	}
+	for _, group := range [...]struct {
+		entity Entity
+		fields addressFields
+	}{
+		{EntityLocationAddress, locationFields},
+		{EntityMailingAddress, mailingFields},
+	} {
+		if p.skips(group.entity) {
+			for _, field := range group.fields.all() {
+				drop[field] = true
+			}
+		}
+	}
+
+	index := make(map[Field]int, len(m.index))
+	for field, i := range m.index {
+		if !repeatedFields[field] && (drop[field] || keep != nil && !keep[field]) {
+			continue
+		}
+		index[field] = i
+	}
+	return &Mapping{Layout: m.Layout, index: index}
+}
+
+// repeatedFields are the members of every repeated field group.
+var repeatedFields = func() map[Field]bool {
+	fields := make(map[Field]bool, 5*MaxTaxonomies+4*MaxOtherIdentifiers)
+	for n := 1; n <= MaxTaxonomies; n++ {
+		for _, group := range []Field{FieldTaxonomyCode, FieldTaxonomyLicense, FieldTaxonomyState, FieldTaxonomyPrimary, FieldTaxonomyGroup} {
+			fields[Indexed(group, n)] = true
+		}
+	}
+	for n := 1; n <= MaxOtherIdentifiers; n++ {
+		for _, group := range []Field{FieldIdentifier, FieldIdentifierType, FieldIdentifierState, FieldIdentifierIssuer} {
+			fields[Indexed(group, n)] = true
+		}
+	}
+	return fields
+}()
+
+// ReaderOption configures a Reader.
+type ReaderOption func(*readerConfig)
+
+// readerConfig holds the settings applied by ReaderOptions.
+type readerConfig struct {
+	projection Projection
+}
+
+// WithProjection limits the columns a Reader materializes. Values of a projected
+// Reader are copied out of the parsed row, so a provider holding a few fields does
+// not keep the whole row in memory.
+func WithProjection(p Projection) ReaderOption {
+	return func(c *readerConfig) {
+		c.projection = p
+	}
+}
+
+// addressFields are the columns of one address.
+type addressFields struct {
+	line1, line2, city, state, postal, country, phone, fax Field
+}
+
+var (
+	locationFields = addressFields{FieldLocationAddress1, FieldLocationAddress2, FieldLocationCity, FieldLocationState,
+		FieldLocationPostalCode, FieldLocationCountryCode, FieldLocationTelephone, FieldLocationFax}
+	mailingFields = addressFields{FieldMailingAddress1, FieldMailingAddress2, FieldMailingCity, FieldMailingState,
+		FieldMailingPostalCode, FieldMailingCountryCode, FieldMailingTelephone, FieldMailingFax}
+)
+
+// all returns every field of the address.
+func (a addressFields) all() []Field {
+	return []Field{a.line1, a.line2, a.city, a.state, a.postal, a.country, a.phone, a.fax}
+}
+
+// trimmed returns value with surrounding space removed, copied if clone is set.
+func trimmed(value string, clone bool) string {
+	value = strings.TrimSpace(value)
+	if clone {
+		value = strings.Clone(value)
+	}
+	return value
+}
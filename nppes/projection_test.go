@@ -0,0 +1,110 @@
+package nppes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestProjection tests leaving out entities and fields while reading rows.
+func TestProjection(t *testing.T) {
+	file := writeProviderFile(layoutByName(t, "2024"), map[Field]string{
+		FieldNPI:                          "1234567893",
+		FieldEntityType:                   "1",
+		FieldFirstName:                    "JANE",
+		FieldLastName:                     "DOE",
+		FieldCredential:                   "M.D.",
+		FieldLocationCity:                 "BOSTON",
+		FieldMailingCity:                  "CAMBRIDGE",
+		Indexed(FieldTaxonomyCode, 1):     "207R00000X",
+		Indexed(FieldIdentifier, 1):       "MC123",
+		Indexed(FieldIdentifierIssuer, 1): "MEDICAID",
+	})
+
+	tests := []struct {
+		name       string
+		projection Projection
+		check      func(t *testing.T, p gonpi.Provider)
+	}{
+		{
+			name:       "skip identifiers and mailing address",
+			projection: Projection{Skip: EntityIdentifiers | EntityMailingAddress},
+			check: func(t *testing.T, p gonpi.Provider) {
+				if len(p.Identifiers) != 0 || len(p.Taxonomies) != 1 {
+					t.Errorf("identifiers = %+v, taxonomies = %+v", p.Identifiers, p.Taxonomies)
+				}
+				if len(p.Addresses) != 1 || p.Addresses[0].AddressPurpose != "LOCATION" {
+					t.Errorf("addresses = %+v", p.Addresses)
+				}
+			},
+		},
+		{
+			name:       "fields",
+			projection: Projection{Fields: []Field{FieldLastName, FieldLocationCity}, Skip: EntityTaxonomies},
+			check: func(t *testing.T, p gonpi.Provider) {
+				if p.Number != "1234567893" || p.Basic.LastName != "DOE" || p.Basic.FirstName != "" || p.Basic.Credential != "" {
+					t.Errorf("basic = %s %+v", p.Number, p.Basic)
+				}
+				if len(p.Addresses) != 1 || p.Addresses[0].City != "BOSTON" {
+					t.Errorf("addresses = %+v", p.Addresses)
+				}
+				if len(p.Taxonomies) != 0 || len(p.Identifiers) != 1 {
+					t.Errorf("taxonomies = %+v, identifiers = %+v", p.Taxonomies, p.Identifiers)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(strings.NewReader(file), WithProjection(tt.projection))
+			if err != nil {
+				t.Fatalf("NewReader: %v", err)
+			}
+			p, err := reader.Next()
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			tt.check(t, p)
+		})
+	}
+}
+
+// TestProjection_Columns tests reading fields from renamed columns.
+func TestProjection_Columns(t *testing.T) {
+	file := writeProviderFile(layoutByName(t, "2024"), map[Field]string{
+		FieldNPI:      "1234567893",
+		FieldLastName: "DOE",
+	})
+	file = strings.Replace(file, "Provider Last Name (Legal Name)", "last_name", 1)
+
+	if _, err := NewReader(strings.NewReader(file)); err == nil {
+		t.Fatal("expected renamed column to fail detection")
+	}
+	reader, err := NewReader(strings.NewReader(file), WithProjection(Projection{
+		Columns: map[Field]string{FieldLastName: "last_name"},
+	}))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	p, err := reader.Next()
+	if err != nil || p.Basic.LastName != "DOE" {
+		t.Errorf("Next = %+v, %v", p.Basic, err)
+	}
+}
+
+// TestLoad_Projection tests that Load applies its projection.
+func TestLoad_Projection(t *testing.T) {
+	store := gonpi.NewMemoryStore()
+	_, err := Load(context.Background(), strings.NewReader(syntheticFile(t, 10)), store,
+		WithLoadProjection(Projection{Skip: EntityTaxonomies}))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p, err := store.Get(context.Background(), "1000000000")
+	if err != nil || p == nil || len(p.Taxonomies) != 0 || p.Basic.LastName != "DOE0" {
+		t.Errorf("Get = %+v, %v", p, err)
+	}
+}
@@ -0,0 +1,280 @@
+package nppes
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// Reader decodes rows of the NPPES provider file (npidata_pfile_*.csv) into
+// gonpi.Provider values, detecting the file's layout from its header.
+type Reader struct {
+	reader  *csv.Reader
+	mapping *Mapping
+	clone   bool
+
+	location, mailing bool
+	taxonomies        []taxonomyColumns
+	identifiers       []identifierColumns
+}
+
+// taxonomyColumns are the positions of one taxonomy group, -1 where absent.
+type taxonomyColumns struct {
+	code, license, state, primary, group int
+}
+
+// identifierColumns are the positions of one other identifier group.
+type identifierColumns struct {
+	identifier, kind, state, issuer int
+}
+
+// NewReader reads the header from r and returns a Reader for the rows that follow. It
+// returns an error wrapping ErrUnsupportedLayout if the header matches no known layout.
+// WithProjection limits the columns decoded.
+//
+// Example usage:
+//
+//	data, err := archive.Open(nppes.EntryData)
+//	reader, err := nppes.NewReader(data)
+//	for {
+//	    provider, err := reader.Next()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    store.Put(ctx, provider)
+//	}
+func NewReader(r io.Reader, opts ...ReaderOption) (*Reader, error) {
+	var config readerConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	projection := config.projection
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	mapping, err := detectLayout(header, projection.Columns)
+	if err != nil {
+		return nil, err
+	}
+	mapping = projection.apply(mapping)
+
+	rd := &Reader{
+		reader:   reader,
+		mapping:  mapping,
+		clone:    !projection.isZero(),
+		location: anyMapped(mapping, locationFields.all()),
+		mailing:  anyMapped(mapping, mailingFields.all()),
+	}
+	for n := range MaxTaxonomies {
+		if projection.skips(EntityTaxonomies) {
+			break
+		}
+		rd.taxonomies = append(rd.taxonomies, taxonomyColumns{
+			code:    mapping.Index(Indexed(FieldTaxonomyCode, n+1)),
+			license: mapping.Index(Indexed(FieldTaxonomyLicense, n+1)),
+			state:   mapping.Index(Indexed(FieldTaxonomyState, n+1)),
+			primary: mapping.Index(Indexed(FieldTaxonomyPrimary, n+1)),
+			group:   mapping.Index(Indexed(FieldTaxonomyGroup, n+1)),
+		})
+	}
+	for n := range MaxOtherIdentifiers {
+		if projection.skips(EntityIdentifiers) {
+			break
+		}
+		rd.identifiers = append(rd.identifiers, identifierColumns{
+			identifier: mapping.Index(Indexed(FieldIdentifier, n+1)),
+			kind:       mapping.Index(Indexed(FieldIdentifierType, n+1)),
+			state:      mapping.Index(Indexed(FieldIdentifierState, n+1)),
+			issuer:     mapping.Index(Indexed(FieldIdentifierIssuer, n+1)),
+		})
+	}
+	return rd, nil
+}
+
+// anyMapped reports whether any of fields is present in m.
+func anyMapped(m *Mapping, fields []Field) bool {
+	for _, field := range fields {
+		if m.Index(field) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Layout returns the detected layout.
+func (rd *Reader) Layout() *Layout {
+	return rd.mapping.Layout
+}
+
+// Next returns the next provider. It returns io.EOF when no rows remain.
+func (rd *Reader) Next() (gonpi.Provider, error) {
+	row, err := rd.reader.Read()
+	if err != nil {
+		if err != io.EOF {
+			err = fmt.Errorf("failed to parse provider file: %w", err)
+		}
+		return gonpi.Provider{}, err
+	}
+	return rd.provider(row, nil), nil
+}
+
+// provider converts row to a Provider shaped like a registry API result. If slabs is
+// not nil, the provider's lists are appended to its arrays instead of allocated.
+func (rd *Reader) provider(row []string, slabs *arena) gonpi.Provider {
+	get := func(field Field) string {
+		return rd.cell(row, rd.mapping.Index(field))
+	}
+
+	p := gonpi.Provider{
+		Number:      get(FieldNPI),
+		LastUpdated: isoDate(get(FieldLastUpdateDate)),
+		Basic: gonpi.BasicInfo{
+			FirstName:                         get(FieldFirstName),
+			LastName:                          get(FieldLastName),
+			MiddleName:                        get(FieldMiddleName),
+			NamePrefix:                        get(FieldNamePrefix),
+			NameSuffix:                        get(FieldNameSuffix),
+			Credential:                        get(FieldCredential),
+			Gender:                            get(FieldGender),
+			SoleProprietor:                    yesNo(get(FieldSoleProprietor)),
+			OrganizationName:                  get(FieldOrganizationName),
+			OrganizationalSubpart:             yesNo(get(FieldOrganizationalSubpart)),
+			EnumerationDate:                   isoDate(get(FieldEnumerationDate)),
+			LastUpdated:                       isoDate(get(FieldLastUpdateDate)),
+			CertificationDate:                 isoDate(get(FieldCertificationDate)),
+			AuthorizedOfficialFirstName:       get(FieldOfficialFirstName),
+			AuthorizedOfficialLastName:        get(FieldOfficialLastName),
+			AuthorizedOfficialMiddleName:      get(FieldOfficialMiddleName),
+			AuthorizedOfficialTitleOrPosition: get(FieldOfficialTitle),
+			AuthorizedOfficialTelephoneNumber: get(FieldOfficialTelephone),
+			AuthorizedOfficialCredential:      get(FieldOfficialCredential),
+			Status:                            "A",
+		},
+	}
+	switch get(FieldEntityType) {
+	case "1":
+		p.EnumerationType = "NPI-1"
+	case "2":
+		p.EnumerationType = "NPI-2"
+	}
+	if get(FieldDeactivationDate) != "" && get(FieldReactivationDate) == "" {
+		p.Basic.Status = "D"
+	}
+
+	var addresses []gonpi.Address
+	var taxonomies []gonpi.Taxonomy
+	var identifiers []gonpi.Identifier
+	if slabs != nil {
+		addresses, taxonomies, identifiers = slabs.addresses, slabs.taxonomies, slabs.identifiers
+	}
+	starts := [3]int{len(addresses), len(taxonomies), len(identifiers)}
+
+	if rd.location {
+		addresses = append(addresses, rd.address(row, "LOCATION", locationFields))
+	}
+	if rd.mailing {
+		addresses = append(addresses, rd.address(row, "MAILING", mailingFields))
+	}
+
+	for _, cols := range rd.taxonomies {
+		code := rd.cell(row, cols.code)
+		if code == "" {
+			continue
+		}
+		taxonomies = append(taxonomies, gonpi.Taxonomy{
+			Code:          code,
+			License:       rd.cell(row, cols.license),
+			State:         rd.cell(row, cols.state),
+			Primary:       rd.cell(row, cols.primary) == "Y",
+			TaxonomyGroup: rd.cell(row, cols.group),
+		})
+	}
+
+	for _, cols := range rd.identifiers {
+		identifier := rd.cell(row, cols.identifier)
+		if identifier == "" {
+			continue
+		}
+		identifiers = append(identifiers, gonpi.Identifier{
+			Identifier: identifier,
+			Code:       rd.cell(row, cols.kind),
+			State:      rd.cell(row, cols.state),
+			Issuer:     rd.cell(row, cols.issuer),
+		})
+	}
+
+	p.Addresses = carve(addresses, starts[0])
+	p.Taxonomies = carve(taxonomies, starts[1])
+	p.Identifiers = carve(identifiers, starts[2])
+	if slabs != nil {
+		slabs.addresses, slabs.taxonomies, slabs.identifiers = addresses, taxonomies, identifiers
+	}
+	return p
+}
+
+// address builds an address from the given fields of row.
+func (rd *Reader) address(row []string, purpose string, fields addressFields) gonpi.Address {
+	get := func(field Field) string {
+		return rd.cell(row, rd.mapping.Index(field))
+	}
+	address := gonpi.Address{
+		AddressPurpose:  purpose,
+		AddressType:     "DOM",
+		Address1:        get(fields.line1),
+		Address2:        get(fields.line2),
+		City:            get(fields.city),
+		State:           get(fields.state),
+		PostalCode:      get(fields.postal),
+		CountryCode:     get(fields.country),
+		TelephoneNumber: get(fields.phone),
+		FaxNumber:       get(fields.fax),
+	}
+	switch address.CountryCode {
+	case "", "US":
+		address.CountryCode = "US"
+	default:
+		address.AddressType = "FGN"
+	}
+	return address
+}
+
+// cell returns row[i] trimmed, or "" if i is out of range.
+func (rd *Reader) cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return trimmed(row[i], rd.clone)
+}
+
+// isoDate converts the file's MM/DD/YYYY dates to the API's YYYY-MM-DD.
+func isoDate(date string) string {
+	month, rest, ok := strings.Cut(date, "/")
+	if !ok {
+		return date
+	}
+	day, year, ok := strings.Cut(rest, "/")
+	if !ok || len(month) != 2 || len(day) != 2 || len(year) != 4 {
+		return date
+	}
+	return year + "-" + month + "-" + day
+}
+
+// yesNo converts the file's Y/N flags to the API's "YES"/"NO".
+func yesNo(flag string) string {
+	switch flag {
+	case "Y":
+		return "YES"
+	case "N":
+		return "NO"
+	}
+	return flag
+}
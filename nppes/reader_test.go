@@ -0,0 +1,111 @@
+package nppes
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+)
+
+// writeProviderFile returns a provider file in the given layout with one row per entry
+// of rows, each mapping fields to values.
+func writeProviderFile(layout *Layout, rows ...map[Field]string) string {
+	header := headerFor(layout)
+	positions := make(map[string]int, len(header))
+	for i, column := range header {
+		positions[column] = i
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(header)
+	for _, values := range rows {
+		row := make([]string, len(header))
+		for field, value := range values {
+			column, _ := layout.Column(field)
+			row[positions[column]] = value
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// TestReader tests decoding provider rows.
+func TestReader(t *testing.T) {
+	layout := layoutByName(t, "2024")
+	file := writeProviderFile(layout,
+		map[Field]string{
+			FieldNPI:                          "1234567893",
+			FieldEntityType:                   "1",
+			FieldFirstName:                    "JANE",
+			FieldLastName:                     "DOE",
+			FieldCredential:                   "M.D.",
+			FieldGender:                       "F",
+			FieldSoleProprietor:               "N",
+			FieldEnumerationDate:              "05/23/2005",
+			FieldLastUpdateDate:               "07/08/2024",
+			FieldLocationAddress1:             "1 MAIN ST",
+			FieldLocationCity:                 "BOSTON",
+			FieldLocationState:                "MA",
+			FieldLocationPostalCode:           "021151234",
+			FieldLocationTelephone:            "6175550100",
+			FieldMailingCountryCode:           "CA",
+			Indexed(FieldTaxonomyCode, 1):     "207R00000X",
+			Indexed(FieldTaxonomyLicense, 1):  "12345",
+			Indexed(FieldTaxonomyState, 1):    "MA",
+			Indexed(FieldTaxonomyPrimary, 1):  "Y",
+			Indexed(FieldTaxonomyCode, 3):     "208D00000X",
+			Indexed(FieldIdentifier, 2):       "MC123",
+			Indexed(FieldIdentifierType, 2):   "05",
+			Indexed(FieldIdentifierState, 2):  "MA",
+			Indexed(FieldIdentifierIssuer, 2): "MEDICAID",
+		},
+		map[Field]string{
+			FieldNPI:              "1245319599",
+			FieldEntityType:       "2",
+			FieldOrganizationName: "ACME CLINIC",
+			FieldDeactivationDate: "01/02/2020",
+		},
+	)
+
+	reader, err := NewReader(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if reader.Layout().Name != "2024" {
+		t.Errorf("layout = %s", reader.Layout().Name)
+	}
+
+	p, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if p.Number != "1234567893" || p.EnumerationType != "NPI-1" || p.Basic.FirstName != "JANE" || p.Basic.Gender != "F" {
+		t.Errorf("basic fields = %s %s %+v", p.Number, p.EnumerationType, p.Basic)
+	}
+	if p.Basic.EnumerationDate != "2005-05-23" || p.Basic.SoleProprietor != "NO" || p.Basic.Status != "A" {
+		t.Errorf("converted fields = %q %q %q", p.Basic.EnumerationDate, p.Basic.SoleProprietor, p.Basic.Status)
+	}
+	if len(p.Addresses) != 2 || p.Addresses[0].City != "BOSTON" || p.Addresses[0].CountryCode != "US" || p.Addresses[1].AddressType != "FGN" {
+		t.Errorf("addresses = %+v", p.Addresses)
+	}
+	if len(p.Taxonomies) != 2 || !p.Taxonomies[0].Primary || p.Taxonomies[0].License != "12345" || p.Taxonomies[1].Code != "208D00000X" {
+		t.Errorf("taxonomies = %+v", p.Taxonomies)
+	}
+	if len(p.Identifiers) != 1 || p.Identifiers[0].Issuer != "MEDICAID" {
+		t.Errorf("identifiers = %+v", p.Identifiers)
+	}
+
+	org, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if org.EnumerationType != "NPI-2" || org.FullName() != "ACME CLINIC" || org.Basic.Status != "D" {
+		t.Errorf("organization = %s %q %s", org.EnumerationType, org.FullName(), org.Basic.Status)
+	}
+
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package gonpi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// legalSuffixes are the entity suffixes stripped from the end of organization names,
+// after punctuation is removed. Multi-word forms cover spelled-out initials.
+var legalSuffixes = [][]string{
+	{"L", "L", "C"}, {"P", "L", "L", "C"}, {"P", "C"}, {"P", "A"}, {"L", "L", "P"},
+	{"LLC"}, {"PLLC"}, {"LLP"}, {"LP"}, {"PC"}, {"PA"}, {"PSC"}, {"SC"}, {"PLC"},
+	{"INC"}, {"INCORPORATED"}, {"CORP"}, {"CORPORATION"}, {"CO"}, {"COMPANY"},
+	{"LTD"}, {"LIMITED"}, {"MD", "PA"}, {"MD", "PC"},
+}
+
+// orgWordForms maps abbreviations and spelling variants in organization names to one
+// canonical form.
+var orgWordForms = map[string]string{
+	"SAINT":  "ST",
+	"STE":    "ST",
+	"MOUNT":  "MT",
+	"FORT":   "FT",
+	"CTR":    "CENTER",
+	"CNTR":   "CENTER",
+	"CENTRE": "CENTER",
+	"HOSP":   "HOSPITAL",
+	"MED":    "MEDICAL",
+	"UNIV":   "UNIVERSITY",
+	"DEPT":   "DEPARTMENT",
+	"ASSOC":  "ASSOCIATES",
+	"ASSOCS": "ASSOCIATES",
+	"SVCS":   "SERVICES",
+	"SVC":    "SERVICES",
+	"HLTH":   "HEALTH",
+	"NATL":   "NATIONAL",
+}
+
+// NormalizeOrganizationName canonicalizes an organization name for matching: it is
+// uppercased, "&" becomes AND, apostrophes are dropped and other punctuation becomes
+// spaces, common abbreviations are expanded or contracted to one form (SAINT to ST,
+// CTR to CENTER), a leading THE and trailing legal suffixes such as LLC, PC and INC are
+// removed, and a "DBA" clause is cut off, keeping the legal name. So "SAINT MARY'S
+// HOSPITAL, LLC" and "St. Marys Hospital" both become "ST MARYS HOSPITAL".
+func NormalizeOrganizationName(name string) string {
+	legal, _ := splitDBA(name)
+	return strings.Join(orgTokens(legal), " ")
+}
+
+// orgTokens returns the normalized words of name.
+func orgTokens(name string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		switch {
+		case r == '\'' || r == '’' || r == '.':
+			// Dropped so MARY'S matches MARYS and L.L.C. matches LLC
+		case r == '&' || r == '+':
+			b.WriteString(" AND ")
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	words := strings.Fields(b.String())
+	for i, word := range words {
+		if form, ok := orgWordForms[word]; ok {
+			words[i] = form
+		}
+	}
+	if len(words) > 1 && words[0] == "THE" {
+		words = words[1:]
+	}
+	for stripped := true; stripped; {
+		stripped = false
+		for _, suffix := range legalSuffixes {
+			rest := len(words) - len(suffix)
+			// "OF PA" and "OF CO" name a state, not an entity type
+			if rest > 0 && words[rest-1] != "OF" && equalWords(words[rest:], suffix) {
+				words = words[:rest]
+				stripped = true
+			}
+		}
+	}
+	return words
+}
+
+// equalWords reports whether a and b hold the same words.
+func equalWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitDBA splits "LEGAL NAME DBA TRADE NAME" into its parts. dba is "" if name has no
+// DBA clause.
+func splitDBA(name string) (legal, dba string) {
+	upper := strings.ToUpper(name)
+	for _, marker := range []string{" D/B/A ", " D.B.A. ", " DBA ", " D B A "} {
+		if i := strings.Index(upper, marker); i >= 0 {
+			return strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+len(marker):])
+		}
+	}
+	return name, ""
+}
+
+// OrganizationNames returns the normalized names an organization is known by: its
+// legal name, the trade name of a DBA clause in it, and its other names, such as
+// "Doing Business As" and former legal names. Duplicates are removed. Individuals
+// have none.
+func OrganizationNames(provider Provider) []string {
+	if provider.EnumerationType == "NPI-1" {
+		return nil
+	}
+	var names []string
+	add := func(name string) {
+		normalized := strings.Join(orgTokens(name), " ")
+		if normalized == "" {
+			return
+		}
+		for _, seen := range names {
+			if seen == normalized {
+				return
+			}
+		}
+		names = append(names, normalized)
+	}
+	legal, dba := splitDBA(provider.Basic.OrganizationName)
+	add(legal)
+	add(dba)
+	for _, other := range provider.OtherNames {
+		legal, dba := splitDBA(other.OrganizationName)
+		add(legal)
+		add(dba)
+	}
+	return names
+}
+
+// OrganizationNameSimilarity scores how alike two organization names are, from 0 to 1,
+// after NormalizeOrganizationName. It averages the overlap of whole words, so word
+// order does not matter, with the overlap of letter pairs, which tolerates typos.
+// Identical normalized names score 1.
+func OrganizationNameSimilarity(a, b string) float64 {
+	return tokenSimilarity(orgTokens(legalName(a)), orgTokens(legalName(b)))
+}
+
+// legalName returns the legal name part of name.
+func legalName(name string) string {
+	legal, _ := splitDBA(name)
+	return legal
+}
+
+// tokenSimilarity scores two normalized word lists.
+func tokenSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if equalWords(a, b) {
+		return 1
+	}
+	return (dice(wordSet(a), wordSet(b)) + dice(bigrams(strings.Join(a, "")), bigrams(strings.Join(b, "")))) / 2
+}
+
+// wordSet returns the distinct words.
+func wordSet(words []string) map[string]int {
+	set := make(map[string]int, len(words))
+	for _, word := range words {
+		set[word] = 1
+	}
+	return set
+}
+
+// bigrams counts the letter pairs of s.
+func bigrams(s string) map[string]int {
+	runes := []rune(s)
+	pairs := make(map[string]int, len(runes))
+	for i := 1; i < len(runes); i++ {
+		pairs[string(runes[i-1:i+1])]++
+	}
+	return pairs
+}
+
+// dice returns the Sørensen–Dice coefficient of two multisets.
+func dice(a, b map[string]int) float64 {
+	var common, total int
+	for key, n := range a {
+		common += min(n, b[key])
+		total += n
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(common) / float64(total)
+}
+
+// MatchOrganizationName returns the best OrganizationNameSimilarity between name and
+// any of the provider's OrganizationNames, so a facility matches by its DBA as well as
+// its legal name.
+func MatchOrganizationName(provider Provider, name string) float64 {
+	query := orgTokens(legalName(name))
+	var best float64
+	for _, known := range OrganizationNames(provider) {
+		best = max(best, tokenSimilarity(query, strings.Fields(known)))
+	}
+	return best
+}
+
+// OrganizationNamed matches organizations whose legal, DBA or other names score at
+// least threshold against name with OrganizationNameSimilarity.
+//
+// Example usage:
+//
+//	hospitals := gonpi.FilterProviders(results, gonpi.OrganizationNamed("Saint Mary's Hospital", 0.9))
+func OrganizationNamed(name string, threshold float64) ProviderFilter {
+	return func(p Provider) bool {
+		return MatchOrganizationName(p, name) >= threshold
+	}
+}
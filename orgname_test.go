@@ -0,0 +1,66 @@
+package gonpi
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNormalizeOrganizationName tests punctuation, abbreviations, suffixes and DBA
+// clauses.
+func TestNormalizeOrganizationName(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"SAINT MARY'S HOSPITAL, LLC", "ST MARYS HOSPITAL"},
+		{"St. Marys Hospital", "ST MARYS HOSPITAL"},
+		{"The Heart & Vascular Ctr, P.C.", "HEART AND VASCULAR CENTER"},
+		{"Smith Family Medicine, L.L.C., Inc.", "SMITH FAMILY MEDICINE"},
+		{"ACME HEALTH LLC DBA SUNRISE CLINIC", "ACME HEALTH"},
+		{"UNIVERSITY OF PA", "UNIVERSITY OF PA"},
+		{"LLC", "LLC"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeOrganizationName(tt.name); got != tt.want {
+			t.Errorf("NormalizeOrganizationName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestOrganizationNameSimilarity tests scoring of equivalent, close and unrelated names.
+func TestOrganizationNameSimilarity(t *testing.T) {
+	if got := OrganizationNameSimilarity("SAINT MARY'S HOSPITAL, LLC", "ST MARYS HOSPITAL"); got != 1 {
+		t.Errorf("equivalent names = %v, want 1", got)
+	}
+	typo := OrganizationNameSimilarity("ST MARYS HOSPITAL", "ST MARY HOSPTIAL")
+	reordered := OrganizationNameSimilarity("MEMORIAL HOSPITAL OF SPRINGFIELD", "SPRINGFIELD MEMORIAL HOSPITAL")
+	unrelated := OrganizationNameSimilarity("ST MARYS HOSPITAL", "LAKESIDE DENTAL GROUP")
+	if typo < 0.5 || reordered < 0.7 || unrelated > 0.2 || typo >= 1 {
+		t.Errorf("typo %.2f, reordered %.2f, unrelated %.2f", typo, reordered, unrelated)
+	}
+	if got := OrganizationNameSimilarity("", "ST MARYS HOSPITAL"); got != 0 {
+		t.Errorf("empty name = %v, want 0", got)
+	}
+}
+
+// TestMatchOrganizationName tests matching by legal, DBA and other names.
+func TestMatchOrganizationName(t *testing.T) {
+	p := Provider{
+		EnumerationType: "NPI-2",
+		Basic:           BasicInfo{OrganizationName: "ACME HEALTH LLC D/B/A SUNRISE CLINIC"},
+		OtherNames: []OtherName{
+			{Type: "Doing Business As", Code: "3", OrganizationName: "Saint Mary's Hospital"},
+			{Type: "Former Legal Business Name", Code: "4", OrganizationName: "ACME HEALTH, INC."},
+		},
+	}
+	if got := OrganizationNames(p); !reflect.DeepEqual(got, []string{"ACME HEALTH", "SUNRISE CLINIC", "ST MARYS HOSPITAL"}) {
+		t.Errorf("OrganizationNames() = %q", got)
+	}
+	for _, name := range []string{"Sunrise Clinic", "ST MARYS HOSPITAL", "Acme Health PC"} {
+		if got := MatchOrganizationName(p, name); got != 1 {
+			t.Errorf("MatchOrganizationName(%q) = %v, want 1", name, got)
+		}
+	}
+	matched := FilterProviders([]Provider{p, mockProvider()}, OrganizationNamed("st. mary's hospital", 0.9))
+	if len(matched) != 1 {
+		t.Errorf("OrganizationNamed matched %d providers", len(matched))
+	}
+}
@@ -0,0 +1,421 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaxSkip is the largest skip value accepted by the NPI Registry API. Searches matching
+// more than MaxSkip+MaxLimit providers must be narrowed to retrieve every result.
+const MaxSkip = 1000
+
+// ErrInconsistentPages indicates that search results changed while SearchAll was paging
+// through them, so that providers may have been skipped or repeated.
+var ErrInconsistentPages = errors.New("search results changed during pagination")
+
+// PageConsistency selects how SearchAll handles results that change between page
+// requests, for example when a failed page is retried after records were added or
+// removed. Exhaustive extracts can use it to state their consistency guarantee.
+type PageConsistency int
+
+const (
+	// PagesUnchecked pages through results without checking them. It is the default.
+	PagesUnchecked PageConsistency = iota
+
+	// PagesTolerate detects changes but keeps going, dropping repeated providers. Each
+	// provider is yielded at most once, but some may be missed.
+	PagesTolerate
+
+	// PagesRestart starts over from the first page when a change is detected, up to
+	// three times, without yielding any provider twice; it then fails like PagesFail.
+	// A completed search yields every provider present throughout it exactly once.
+	PagesRestart
+
+	// PagesFail stops with an error wrapping ErrInconsistentPages when a change is
+	// detected.
+	PagesFail
+)
+
+// maxPageRestarts is the number of times PagesRestart starts over.
+const maxPageRestarts = 3
+
+// String returns the policy name.
+func (p PageConsistency) String() string {
+	switch p {
+	case PagesUnchecked:
+		return "unchecked"
+	case PagesTolerate:
+		return "tolerate"
+	case PagesRestart:
+		return "restart"
+	case PagesFail:
+		return "fail"
+	}
+	return fmt.Sprintf("PageConsistency(%d)", int(p))
+}
+
+// pageChecker detects changes between the pages of one search. When checking is on,
+// each page after the first starts one result early, overlapping the previous page;
+// if the overlapping result differs, results before it were added or removed. Providers
+// repeated within a pass are also reported, and providers already yielded, including
+// by an abandoned pass, are filtered out.
+type pageChecker struct {
+	enabled bool
+
+	// stride is the distance between the skips of consecutive pages
+	stride int
+
+	// last is the NPI ending the previous page of this pass; pass holds the NPIs seen
+	// in this pass and yielded those yielded by any pass
+	last    string
+	pass    map[string]bool
+	yielded map[string]bool
+
+	inconsistencies int
+}
+
+// newPageChecker returns a checker for the given policy and page size.
+func newPageChecker(policy PageConsistency, pageSize int) *pageChecker {
+	check := &pageChecker{stride: pageSize}
+	if policy == PagesUnchecked {
+		return check
+	}
+	check.enabled = true
+	check.yielded = make(map[string]bool)
+	if pageSize > 1 {
+		check.stride = pageSize - 1
+	}
+	return check
+}
+
+// reset starts a new pass.
+func (p *pageChecker) reset() {
+	if p.enabled {
+		p.last = ""
+		p.pass = make(map[string]bool)
+	}
+}
+
+// page checks the providers returned for req against earlier pages and returns those
+// not seen before in this pass, with an error wrapping ErrInconsistentPages describing
+// the first change found.
+func (p *pageChecker) page(req SearchOptions, providers []Provider) ([]Provider, error) {
+	if !p.enabled {
+		return providers, nil
+	}
+	var err error
+	inconsistent := func(format string, args ...any) {
+		p.inconsistencies++
+		if err == nil {
+			err = fmt.Errorf("%w: "+format, append([]any{ErrInconsistentPages}, args...)...)
+		}
+	}
+
+	anchored := p.stride < req.Limit && p.last != ""
+	if anchored {
+		switch {
+		case len(providers) == 0:
+			inconsistent("page at skip %d is empty, expected NPI %s", req.Skip, p.last)
+		case providers[0].Number != p.last:
+			inconsistent("results shifted before skip %d: expected NPI %s, got %s", req.Skip, p.last, providers[0].Number)
+		default:
+			providers = providers[1:]
+		}
+	}
+
+	fresh := providers[:0:0]
+	for _, provider := range providers {
+		if p.pass[provider.Number] {
+			inconsistent("NPI %s repeated at skip %d", provider.Number, req.Skip)
+			continue
+		}
+		p.pass[provider.Number] = true
+		fresh = append(fresh, provider)
+	}
+	if len(providers) > 0 {
+		p.last = providers[len(providers)-1].Number
+	}
+	return fresh, err
+}
+
+// first reports whether provider has not been yielded before, recording it.
+func (p *pageChecker) first(provider Provider) bool {
+	if !p.enabled {
+		return true
+	}
+	if p.yielded[provider.Number] {
+		return false
+	}
+	p.yielded[provider.Number] = true
+	return true
+}
+
+// SearchAll returns an iterator over every provider matching opts, fetching pages of
+// opts.Limit results (or the client default) and advancing opts.Skip until the results
+// are exhausted, SearchOptions.MaxResults providers have been yielded, or MaxSkip is
+// reached. Iteration stops after the first error, which is yielded with a zero Provider.
+// Set SearchOptions.PageConcurrency to fetch large result sets several pages at a time,
+// and SearchOptions.Consistency to detect results changing between pages.
+//
+// Example usage:
+//
+//	for provider, err := range client.SearchAll(ctx, SearchOptions{LastName: "Smith", MaxResults: 25}) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(provider.FullName())
+//	}
+func (c *Client) SearchAll(ctx context.Context, opts SearchOptions) iter.Seq2[Provider, error] {
+	return func(yield func(Provider, error) bool) {
+		ctx, span := c.tracer.Start(ctx, "SearchAll",
+			trace.WithAttributes(c.traceAttrs(
+				attribute.Int("max_results", opts.MaxResults),
+				attribute.Int("page_concurrency", opts.PageConcurrency),
+				attribute.String("consistency", opts.Consistency.String()),
+			)...),
+		)
+		defer span.End()
+
+		// Admit the whole search, so that a draining client fetches its remaining pages
+		ctx, done, err := c.background.enter(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "client closed")
+			yield(Provider{}, err)
+			return
+		}
+		defer done()
+
+		// Cancel pages still in flight when the caller stops iterating
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		opts = c.applyDefaults(opts)
+		pageSize := opts.Limit
+		if pageSize <= 0 {
+			pageSize = DefaultLimit
+		} else if pageSize > MaxLimit {
+			pageSize = MaxLimit
+		}
+		concurrency := max(1, opts.PageConcurrency)
+		check := newPageChecker(opts.Consistency, pageSize)
+
+		yielded, pages, restarts := 0, 0, 0
+		defer func() {
+			span.SetAttributes(c.traceAttrs(
+				attribute.Int("pages", pages),
+				attribute.Int("yielded", yielded),
+				attribute.Int("restarts", restarts),
+				attribute.Int("inconsistencies", check.inconsistencies),
+			)...)
+		}()
+
+	run:
+		for {
+			check.reset()
+			// The first page is fetched alone so that small searches never pay for
+			// speculative requests
+			window := 1
+			for skip := opts.Skip; skip <= MaxSkip; {
+				batch := planPages(opts, skip, pageSize, check.stride, yielded, window)
+				if len(batch) == 0 {
+					return
+				}
+				results := c.fetchPages(ctx, batch)
+				pages += len(batch)
+
+				for i, result := range results {
+					if result.err != nil {
+						span.RecordError(result.err)
+						span.SetStatus(codes.Error, "page request failed")
+						yield(Provider{}, result.err)
+						return
+					}
+
+					short := len(result.providers) < batch[i].Limit
+					providers, err := check.page(batch[i], result.providers)
+					// A short page ends the results, so later pages must be empty
+					contradicted := check.enabled && short && i+1 < len(results) && len(results[i+1].providers) > 0
+					if contradicted && err == nil {
+						err = fmt.Errorf("%w: page at skip %d was short but later pages have results", ErrInconsistentPages, batch[i].Skip)
+						check.inconsistencies++
+					}
+					if err != nil {
+						span.AddEvent("inconsistent_page", trace.WithAttributes(attribute.Int("skip", batch[i].Skip)))
+						switch opts.Consistency {
+						case PagesRestart:
+							if restarts < maxPageRestarts {
+								restarts++
+								continue run
+							}
+							fallthrough
+						case PagesFail:
+							span.RecordError(err)
+							span.SetStatus(codes.Error, "inconsistent pages")
+							yield(Provider{}, err)
+							return
+						}
+						// PagesTolerate keeps going past a short page that later pages contradict
+						short = short && !contradicted
+					}
+
+					for _, provider := range providers {
+						if !check.first(provider) {
+							continue
+						}
+						if !yield(provider, nil) {
+							return
+						}
+						yielded++
+						if opts.MaxResults > 0 && yielded >= opts.MaxResults {
+							return
+						}
+					}
+
+					if short {
+						return
+					}
+				}
+
+				skip = batch[len(batch)-1].Skip + check.stride
+				window = concurrency
+			}
+			return
+		}
+	}
+}
+
+// planPages returns up to n page requests starting at skip and advancing by stride,
+// assuming every earlier page comes back full. It stops at MaxSkip and once MaxResults
+// would be reached. Pages advance by less than pageSize when consecutive pages overlap.
+func planPages(opts SearchOptions, skip, pageSize, stride, yielded, n int) []SearchOptions {
+	var batch []SearchOptions
+	for ; len(batch) < n && skip <= MaxSkip; skip += stride {
+		page := opts
+		page.Skip = skip
+		page.Limit = pageSize
+		page.MaxResults = 0
+		page.PageConcurrency = 0
+		if opts.MaxResults > 0 {
+			remaining := opts.MaxResults - yielded - len(batch)*stride
+			if remaining <= 0 {
+				break
+			}
+			if skip != opts.Skip {
+				// Overlapping pages repeat one result of the previous page
+				remaining += pageSize - stride
+			}
+			page.Limit = min(pageSize, remaining)
+		}
+		batch = append(batch, page)
+	}
+	return batch
+}
+
+// pageResult is the outcome of fetching one page.
+type pageResult struct {
+	providers []Provider
+	err       error
+}
+
+// fetchPages fetches each page concurrently and returns the results in page order.
+func (c *Client) fetchPages(ctx context.Context, batch []SearchOptions) []pageResult {
+	results := make([]pageResult, len(batch))
+	if len(batch) == 1 {
+		results[0].providers, results[0].err = c.SearchProviders(ctx, batch[0])
+		return results
+	}
+
+	var wg sync.WaitGroup
+	for i, page := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].err = safeCall(func() (err error) {
+				results[i].providers, err = c.SearchProviders(ctx, page)
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// SearchResult is one item delivered by SearchStream: a provider, or the error that
+// ended the stream.
+type SearchResult struct {
+	Provider Provider
+	Err      error
+}
+
+// SearchStream runs SearchAll in the background and delivers its results on the
+// returned channel, which is closed when the results are exhausted or after an error.
+// If ctx is cancelled or the client is closed first, the last result carries the
+// error, so a stream that ends without one is complete.
+//
+// Up to buffer results, plus one for the final error, are queued for a slow consumer,
+// such as a file writer; once the channel is full, page fetching pauses until the
+// consumer catches up. At most buffer providers plus the pages currently being fetched
+// (PageConcurrency pages of Limit) are held in memory. Consumers that stop reading
+// early must cancel ctx to release the background goroutine; queued results may then
+// be discarded to make room for the error.
+//
+// Example usage:
+//
+//	for result := range client.SearchStream(ctx, SearchOptions{State: "MA", PageConcurrency: 4}, 500) {
+//	    if result.Err != nil {
+//	        return result.Err
+//	    }
+//	    writer.Write(result.Provider)
+//	}
+func (c *Client) SearchStream(ctx context.Context, opts SearchOptions, buffer int) <-chan SearchResult {
+	results := make(chan SearchResult, max(0, buffer)+1)
+	ctx, done := c.background.track(ctx)
+	go func() {
+		defer done()
+		defer close(results)
+		for provider, err := range c.SearchAll(ctx, opts) {
+			if err != nil {
+				sendFinal(ctx, results, err)
+				return
+			}
+			select {
+			case results <- SearchResult{Provider: provider}:
+			case <-ctx.Done():
+				sendFinal(ctx, results, ctx.Err())
+				return
+			}
+		}
+	}()
+	return results
+}
+
+// sendFinal delivers err as the last result on results. Once ctx is done the consumer
+// may have stopped reading, so queued results are discarded to make room rather than
+// blocking forever.
+func sendFinal(ctx context.Context, results chan SearchResult, err error) {
+	final := SearchResult{Err: err}
+	for {
+		select {
+		case results <- final:
+			return
+		case <-ctx.Done():
+			select {
+			case results <- final:
+				return
+			default:
+			}
+			select {
+			case <-results:
+			default:
+			}
+		}
+	}
+}
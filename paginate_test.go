@@ -0,0 +1,401 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newPagingServer serves total providers, honoring limit and skip.
+func newPagingServer(total int, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		*requests = append(*requests, fmt.Sprintf("%d/%d", skip, limit))
+
+		var providers []Provider
+		for i := skip; i < total && i < skip+limit; i++ {
+			provider := mockProvider()
+			provider.Number = fmt.Sprintf("%010d", i)
+			providers = append(providers, provider)
+		}
+		json.NewEncoder(w).Encode(APIResponse{ResultCount: len(providers), Results: providers})
+	}))
+}
+
+// TestSearchAll tests iterating through every page.
+func TestSearchAll(t *testing.T) {
+	var requests []string
+	server := newPagingServer(25, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	count := 0
+	for provider, err := range client.SearchAll(context.Background(), SearchOptions{LastName: "Smith", Limit: 10}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if provider.Number != fmt.Sprintf("%010d", count) {
+			t.Errorf("unexpected provider order at %d: %s", count, provider.Number)
+		}
+		count++
+	}
+
+	if count != 25 {
+		t.Errorf("expected 25 providers, got %d", count)
+	}
+	if len(requests) != 3 {
+		t.Errorf("expected 3 page requests, got %v", requests)
+	}
+}
+
+// TestSearchAll_MaxResults tests stopping pagination after MaxResults providers.
+func TestSearchAll_MaxResults(t *testing.T) {
+	var requests []string
+	server := newPagingServer(100, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	count := 0
+	for _, err := range client.SearchAll(context.Background(), SearchOptions{LastName: "Smith", Limit: 10, MaxResults: 25}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+	}
+
+	if count != 25 {
+		t.Errorf("expected 25 providers, got %d", count)
+	}
+	want := []string{"0/10", "10/10", "20/5"}
+	if fmt.Sprint(requests) != fmt.Sprint(want) {
+		t.Errorf("expected requests %v, got %v", want, requests)
+	}
+}
+
+// TestSearchAll_EarlyBreak tests that breaking out of the loop stops fetching.
+func TestSearchAll_EarlyBreak(t *testing.T) {
+	var requests []string
+	server := newPagingServer(100, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	for range client.SearchAll(context.Background(), SearchOptions{LastName: "Smith", Limit: 10}) {
+		break
+	}
+	if len(requests) != 1 {
+		t.Errorf("expected 1 request, got %v", requests)
+	}
+}
+
+// TestSearchAll_Error tests that page errors are yielded.
+func TestSearchAll_Error(t *testing.T) {
+	client := NewClient(WithBaseURL("http://127.0.0.1:1"), WithRetry(RetryConfig{MaxRetries: 0}))
+
+	var gotErr error
+	for _, err := range client.SearchAll(context.Background(), SearchOptions{LastName: "Smith"}) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Error("expected error to be yielded")
+	}
+}
+
+// TestSearchProviders_MaxResults tests that MaxResults caps a single page.
+func TestSearchProviders_MaxResults(t *testing.T) {
+	var requests []string
+	server := newPagingServer(100, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	results, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Smith", Limit: 50, MaxResults: 7})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 7 {
+		t.Errorf("expected 7 results, got %d", len(results))
+	}
+}
+
+// TestSearchAll_PageConcurrency tests fetching pages concurrently with ordered output.
+func TestSearchAll_PageConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var requests, inFlight, maxInFlight int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		var providers []Provider
+		for i := skip; i < 95 && i < skip+limit; i++ {
+			provider := mockProvider()
+			provider.Number = fmt.Sprintf("%010d", i)
+			providers = append(providers, provider)
+		}
+		json.NewEncoder(w).Encode(APIResponse{ResultCount: len(providers), Results: providers})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	count := 0
+	for provider, err := range client.SearchAll(ctx, SearchOptions{LastName: "Smith", Limit: 10, PageConcurrency: 4}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if provider.Number != fmt.Sprintf("%010d", count) {
+			t.Fatalf("unexpected provider order at %d: %s", count, provider.Number)
+		}
+		count++
+	}
+	if count != 95 {
+		t.Errorf("expected 95 providers, got %d", count)
+	}
+	// One lone first page, then windows of 4 until the short page at skip 90
+	if requests != 13 {
+		t.Errorf("expected 13 page requests, got %d", requests)
+	}
+	if maxInFlight < 2 || maxInFlight > 4 {
+		t.Errorf("expected 2-4 concurrent requests, got %d", maxInFlight)
+	}
+
+	requests = 0
+	count = 0
+	for _, err := range client.SearchAll(ctx, SearchOptions{LastName: "Smith", Limit: 10, MaxResults: 35, PageConcurrency: 4}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+	}
+	if count != 35 || requests != 4 {
+		t.Errorf("with MaxResults 35: got %d providers in %d requests, want 35 in 4", count, requests)
+	}
+}
+
+// newShiftingServer serves the NPIs 0 to total-1 in order, and applies change to the
+// list once after the first request, as when records are added or removed mid-search.
+func newShiftingServer(total int, change func([]string) []string) *httptest.Server {
+	var mu sync.Mutex
+	var requests int
+	npis := make([]string, total)
+	for i := range npis {
+		npis[i] = fmt.Sprintf("%010d", i)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+
+		mu.Lock()
+		var providers []Provider
+		for i := skip; i < len(npis) && i < skip+limit; i++ {
+			provider := mockProvider()
+			provider.Number = npis[i]
+			providers = append(providers, provider)
+		}
+		if requests++; requests == 1 {
+			npis = change(npis)
+		}
+		mu.Unlock()
+		json.NewEncoder(w).Encode(APIResponse{ResultCount: len(providers), Results: providers})
+	}))
+}
+
+// TestSearchAll_Consistency tests detecting results that shift between pages under
+// each consistency policy.
+func TestSearchAll_Consistency(t *testing.T) {
+	insert := func(npis []string) []string { return append([]string{"9999999999"}, npis...) }
+	remove := func(npis []string) []string { return npis[1:] }
+
+	tests := []struct {
+		name        string
+		change      func([]string) []string
+		policy      PageConsistency
+		concurrency int
+		want        int
+		wantErr     bool
+	}{
+		{"unchecked insert repeats a provider", insert, PagesUnchecked, 0, 26, false},
+		{"unchecked remove misses a provider", remove, PagesUnchecked, 0, 24, false},
+		{"tolerate insert drops the repeat", insert, PagesTolerate, 0, 25, false},
+		{"fail on insert", insert, PagesFail, 0, 10, true},
+		{"fail on remove", remove, PagesFail, 0, 10, true},
+		{"restart on insert", insert, PagesRestart, 0, 26, false},
+		{"restart on remove", remove, PagesRestart, 0, 25, false}, // includes the removed provider, yielded before the change
+		{"restart with concurrent pages", insert, PagesRestart, 3, 26, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newShiftingServer(25, tt.change)
+			defer server.Close()
+			client := NewClient(WithBaseURL(server.URL))
+
+			seen := make(map[string]int)
+			count := 0
+			var gotErr error
+			for provider, err := range client.SearchAll(context.Background(), SearchOptions{LastName: "Smith", Limit: 10, Consistency: tt.policy, PageConcurrency: tt.concurrency}) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				seen[provider.Number]++
+				count++
+			}
+			if (gotErr != nil) != tt.wantErr || gotErr != nil && !errors.Is(gotErr, ErrInconsistentPages) {
+				t.Fatalf("error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if count != tt.want {
+				t.Errorf("expected %d providers, got %d", tt.want, count)
+			}
+			if tt.policy != PagesUnchecked {
+				for npi, n := range seen {
+					if n > 1 {
+						t.Errorf("NPI %s yielded %d times", npi, n)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestSearchStream_Backpressure tests that page fetching pauses while the consumer is
+// behind and resumes as it reads.
+func TestSearchStream_Backpressure(t *testing.T) {
+	var pageRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageRequests.Add(1)
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		var providers []Provider
+		for i := skip; i < 100 && i < skip+10; i++ {
+			provider := mockProvider()
+			provider.Number = fmt.Sprintf("%010d", i)
+			providers = append(providers, provider)
+		}
+		json.NewEncoder(w).Encode(APIResponse{ResultCount: len(providers), Results: providers})
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	defer client.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := client.SearchStream(ctx, SearchOptions{LastName: "Smith", Limit: 10}, 5)
+
+	// Without a reader the producer stalls on the first page: the buffer holds 5
+	// results and the sixth blocks
+	time.Sleep(20 * time.Millisecond)
+	if n := pageRequests.Load(); n != 1 {
+		t.Fatalf("expected fetching to pause after 1 page, got %d requests", n)
+	}
+
+	count := 0
+	for result := range stream {
+		if result.Err != nil {
+			t.Fatalf("unexpected error: %v", result.Err)
+		}
+		if result.Provider.Number != fmt.Sprintf("%010d", count) {
+			t.Fatalf("unexpected provider order at %d: %s", count, result.Provider.Number)
+		}
+		count++
+	}
+	if count != 100 {
+		t.Errorf("expected 100 providers, got %d", count)
+	}
+}
+
+// TestSearchStream_Cancel tests that cancelling the context releases the producer.
+func TestSearchStream_Cancel(t *testing.T) {
+	var requests []string
+	server := newPagingServer(100, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := client.SearchStream(ctx, SearchOptions{LastName: "Smith", Limit: 10}, 0)
+	<-stream
+	cancel()
+
+	closed := make(chan struct{})
+	var last SearchResult
+	go func() {
+		for result := range stream {
+			last = result
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not closed after cancel")
+	}
+	if !errors.Is(last.Err, context.Canceled) {
+		t.Errorf("expected the stream to end with context.Canceled, got %v", last.Err)
+	}
+	client.Close()
+}
+
+// TestSearchStream_Abandoned tests that a consumer that cancels and stops reading does
+// not block the producer on the final error.
+func TestSearchStream_Abandoned(t *testing.T) {
+	var requests []string
+	server := newPagingServer(100, &requests)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := client.SearchStream(ctx, SearchOptions{LastName: "Smith", Limit: 10}, 0)
+	<-stream
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		client.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("producer blocked after the consumer stopped reading")
+	}
+}
+
+// TestSearchStream_Error tests that a search error is delivered before the channel closes.
+func TestSearchStream_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	defer client.Close()
+	var last SearchResult
+	for result := range client.SearchStream(context.Background(), SearchOptions{LastName: "Smith"}, 0) {
+		last = result
+	}
+	var apiErr *APIError
+	if !errors.As(last.Err, &apiErr) {
+		t.Errorf("expected the stream to end with an APIError, got %v", last.Err)
+	}
+}
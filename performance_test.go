@@ -3,8 +3,11 @@ package gonpi
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -103,3 +106,44 @@ func BenchmarkProviderUnmarshal(b *testing.B) {
 		json.Unmarshal(jsonData, &provider)
 	}
 }
+
+// benchmarkSearchOptions is a typical proxied search.
+var benchmarkSearchOptions = SearchOptions{
+	FirstName:           "John",
+	LastName:            "Smith",
+	TaxonomyDescription: "Family Medicine",
+	City:                "Boston",
+	State:               "MA",
+	Limit:               200,
+	Skip:                1000,
+}
+
+// BenchmarkSearchURL benchmarks building an API URL from search options.
+func BenchmarkSearchURL(b *testing.B) {
+	client := NewClient()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		client.searchURL(benchmarkSearchOptions)
+	}
+}
+
+// BenchmarkSearchURL_URLValues benchmarks the url.Values approach that searchURL
+// replaces, for comparison.
+func BenchmarkSearchURL_URLValues(b *testing.B) {
+	opts := benchmarkSearchOptions
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		params := url.Values{}
+		params.Set("version", "2.1")
+		params.Set("first_name", opts.FirstName)
+		params.Set("last_name", opts.LastName)
+		params.Set("taxonomy_description", opts.TaxonomyDescription)
+		params.Set("city", opts.City)
+		params.Set("state", opts.State)
+		params.Set("limit", strconv.Itoa(opts.Limit))
+		params.Set("skip", strconv.Itoa(opts.Skip))
+		_ = fmt.Sprintf("%s/?%s", DefaultBaseURL, params.Encode())
+	}
+}
@@ -0,0 +1,95 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithPreconnect opens n connections to the API host in the background when the client
+// is created, completing TCP and TLS handshakes before the first lookup needs them.
+// Connections beyond the transport's MaxIdleConnsPerHost (2 for http.DefaultTransport)
+// are closed again, so raise it when preconnecting more. See Client.Preconnect.
+func WithPreconnect(n int) ClientOption {
+	return func(c *Client) {
+		c.preconnect = n
+	}
+}
+
+// startPreconnect runs the preconnect requested with WithPreconnect, if any. Errors
+// are recorded on the span only; lookups dial as usual if warm-up failed.
+func (c *Client) startPreconnect() {
+	if c.preconnect <= 0 {
+		return
+	}
+	ctx, done := c.background.track(context.Background())
+	go func() {
+		defer done()
+		c.Preconnect(ctx, c.preconnect)
+	}()
+}
+
+// Preconnect opens up to n idle connections to the API host by sending n concurrent
+// HEAD requests and returns when they have completed. Any response, including an error
+// status, leaves a warm connection behind; only transport failures are returned.
+// Warm-up requests bypass the rate limit and are not counted in metrics.
+func (c *Client) Preconnect(ctx context.Context, n int) error {
+	ctx, span := c.tracer.Start(ctx, "Preconnect",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.Int("connections", n),
+		)...),
+	)
+	defer span.End()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.warm(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		err := errors.Join(errs...)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "preconnect failed")
+		return err
+	}
+	return nil
+}
+
+// warm sends one HEAD request to the API host and drains the response so that its
+// connection returns to the idle pool.
+func (c *Client) warm(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL+"/", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w: %w", errInvalidURL, err)
+	}
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
@@ -0,0 +1,80 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newConnCountingServer returns a TLS server that counts new connections and holds
+// HEAD requests briefly so that concurrent warm-up requests cannot share one.
+func newConnCountingServer(conns *atomic.Int32) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			time.Sleep(20 * time.Millisecond)
+			return
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.StartTLS()
+	return server
+}
+
+// TestPreconnect tests that warm connections are reused by later lookups.
+func TestPreconnect(t *testing.T) {
+	var conns atomic.Int32
+	server := newConnCountingServer(&conns)
+	defer server.Close()
+
+	httpClient := server.Client()
+	httpClient.Transport.(*http.Transport).MaxIdleConnsPerHost = 3
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(httpClient))
+	ctx := context.Background()
+
+	if err := client.Preconnect(ctx, 3); err != nil {
+		t.Fatalf("Preconnect: %v", err)
+	}
+	if n := conns.Load(); n != 3 {
+		t.Errorf("expected 3 connections, got %d", n)
+	}
+
+	if _, err := client.GetProviderByNPI(ctx, "1234567890"); err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if n := conns.Load(); n != 3 {
+		t.Errorf("lookup opened a new connection: %d total", n)
+	}
+}
+
+// TestWithPreconnect tests that the client warms up in the background.
+func TestWithPreconnect(t *testing.T) {
+	var conns atomic.Int32
+	server := newConnCountingServer(&conns)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithPreconnect(2))
+	defer client.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for conns.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := conns.Load(); n != 2 {
+		t.Errorf("expected 2 connections, got %d", n)
+	}
+
+	unreachable := NewClient(WithBaseURL("http://127.0.0.1:1"), WithPreconnect(1))
+	if err := unreachable.Preconnect(context.Background(), 1); err == nil {
+		t.Error("expected error preconnecting to a closed port")
+	}
+	unreachable.Close()
+}
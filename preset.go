@@ -0,0 +1,182 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrUnknownPreset indicates that no search preset is registered under a name.
+var ErrUnknownPreset = errors.New("unknown search preset")
+
+// presetRegistry holds a client's named search presets.
+type presetRegistry struct {
+	mu      sync.RWMutex
+	presets map[string]SearchOptions
+}
+
+// set registers opts under name, replacing any existing preset.
+func (r *presetRegistry) set(name string, opts SearchOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.presets == nil {
+		r.presets = make(map[string]SearchOptions)
+	}
+	r.presets[name] = opts
+}
+
+// get returns the preset registered under name.
+func (r *presetRegistry) get(name string) (SearchOptions, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	opts, ok := r.presets[name]
+	return opts, ok
+}
+
+// WithSearchPreset registers a named search preset when the client is created. See
+// Client.RegisterPreset.
+func WithSearchPreset(name string, opts SearchOptions) ClientOption {
+	return func(c *Client) {
+		c.presets.set(name, opts)
+	}
+}
+
+// RegisterPreset stores opts under name for use with SearchPreset, replacing any
+// preset of the same name. Presets let services and the CLI share curated queries
+// maintained in one place. They are shared with clients derived by With.
+//
+// Example usage:
+//
+//	err := client.RegisterPreset("ca-cardiologists", gonpi.SearchOptions{
+//	    State:               "CA",
+//	    TaxonomyDescription: "Cardiology",
+//	})
+//	providers, err := client.SearchPreset(ctx, "ca-cardiologists", gonpi.SearchOptions{City: "Fresno"})
+func (c *Client) RegisterPreset(name string, opts SearchOptions) error {
+	if strings.TrimSpace(name) == "" {
+		return &ValidationError{Field: "preset", Message: "preset name cannot be empty"}
+	}
+	if err := validateSearchOptions(opts); err != nil {
+		return err
+	}
+	c.presets.set(name, opts)
+	return nil
+}
+
+// Preset returns the search options registered under name.
+func (c *Client) Preset(name string) (SearchOptions, bool) {
+	return c.presets.get(name)
+}
+
+// Presets returns the names of the registered presets, sorted.
+func (c *Client) Presets() []string {
+	c.presets.mu.RLock()
+	defer c.presets.mu.RUnlock()
+	names := make([]string, 0, len(c.presets.presets))
+	for name := range c.presets.presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolvePreset returns the preset registered under name with overrides applied by
+// MergeSearchOptions, for callers that run the query themselves, e.g. with SearchAll.
+// It returns an error wrapping ErrUnknownPreset if no preset has that name.
+func (c *Client) ResolvePreset(name string, overrides SearchOptions) (SearchOptions, error) {
+	opts, ok := c.presets.get(name)
+	if !ok {
+		return SearchOptions{}, fmt.Errorf("%w: %q", ErrUnknownPreset, name)
+	}
+	return MergeSearchOptions(opts, overrides), nil
+}
+
+// SearchPreset runs the preset registered under name, with the non-zero fields of
+// overrides replacing the preset's, through SearchProviders.
+func (c *Client) SearchPreset(ctx context.Context, name string, overrides SearchOptions) ([]Provider, error) {
+	ctx, span := c.tracer.Start(ctx, "SearchPreset",
+		trace.WithAttributes(c.traceAttrs(attribute.String("preset", name))...),
+	)
+	defer span.End()
+
+	opts, err := c.ResolvePreset(name, overrides)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return c.SearchProviders(ctx, opts)
+}
+
+// MergeSearchOptions returns base with every non-zero field of overrides replacing
+// the corresponding field. Boolean fields can be turned on but not off.
+func MergeSearchOptions(base, overrides SearchOptions) SearchOptions {
+	merged := base
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&merged.Number, overrides.Number},
+		{&merged.EnumerationType, overrides.EnumerationType},
+		{&merged.FirstName, overrides.FirstName},
+		{&merged.LastName, overrides.LastName},
+		{&merged.OrganizationName, overrides.OrganizationName},
+		{&merged.TaxonomyDescription, overrides.TaxonomyDescription},
+		{&merged.AddressPurpose, overrides.AddressPurpose},
+		{&merged.City, overrides.City},
+		{&merged.State, overrides.State},
+		{&merged.PostalCode, overrides.PostalCode},
+		{&merged.CountryCode, overrides.CountryCode},
+		{&merged.Credential, overrides.Credential},
+		{&merged.Cursor, overrides.Cursor},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	for _, f := range []struct {
+		dst *int
+		src int
+	}{
+		{&merged.Limit, overrides.Limit},
+		{&merged.Skip, overrides.Skip},
+		{&merged.MaxResults, overrides.MaxResults},
+		{&merged.PageConcurrency, overrides.PageConcurrency},
+	} {
+		if f.src != 0 {
+			*f.dst = f.src
+		}
+	}
+	if overrides.Consistency != PagesUnchecked {
+		merged.Consistency = overrides.Consistency
+	}
+	merged.SortByRelevance = merged.SortByRelevance || overrides.SortByRelevance
+	merged.Pretty = merged.Pretty || overrides.Pretty
+	return merged
+}
+
+// LoadPresets reads presets from a JSON object mapping names to search options,
+// keyed by SearchOptions field names:
+//
+//	{"ca-cardiologists": {"State": "CA", "TaxonomyDescription": "Cardiology"}}
+//
+// Unknown fields are rejected so that typos do not silently broaden a query. Register
+// the result with RegisterPreset or WithSearchPreset.
+func LoadPresets(r io.Reader) (map[string]SearchOptions, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var presets map[string]SearchOptions
+	if err := decoder.Decode(&presets); err != nil {
+		return nil, fmt.Errorf("failed to decode presets: %w", err)
+	}
+	return presets, nil
+}
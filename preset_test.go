@@ -0,0 +1,100 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSearchPreset tests running a registered preset with overrides.
+func TestSearchPreset(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL),
+		WithSearchPreset("ma-family", SearchOptions{State: "MA", TaxonomyDescription: "Family Medicine"}))
+	defer client.Close()
+	if err := client.RegisterPreset("ca-cardiologists", SearchOptions{State: "CA", TaxonomyDescription: "Cardiology", Limit: 50}); err != nil {
+		t.Fatalf("RegisterPreset: %v", err)
+	}
+	if got := client.With().Presets(); !reflect.DeepEqual(got, []string{"ca-cardiologists", "ma-family"}) {
+		t.Errorf("Presets = %v", got)
+	}
+
+	providers, err := client.SearchPreset(context.Background(), "ca-cardiologists", SearchOptions{City: "Fresno", Limit: 5})
+	if err != nil || len(providers) != 1 {
+		t.Fatalf("SearchPreset = %v, %v", providers, err)
+	}
+	for _, want := range []string{"state=CA", "taxonomy_description=Cardiology", "city=Fresno", "limit=5"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query %q missing %s", query, want)
+		}
+	}
+
+	if _, err := client.SearchPreset(context.Background(), "missing", SearchOptions{}); !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("expected ErrUnknownPreset, got %v", err)
+	}
+}
+
+// TestRegisterPreset_Invalid tests that invalid presets are rejected.
+func TestRegisterPreset_Invalid(t *testing.T) {
+	client := NewClient()
+	defer client.Close()
+	for _, err := range []error{
+		client.RegisterPreset("", SearchOptions{State: "CA"}),
+		client.RegisterPreset("bad-state", SearchOptions{State: "ca"}),
+	} {
+		if !IsValidation(err) {
+			t.Errorf("expected ValidationError, got %v", err)
+		}
+	}
+}
+
+// TestMergeSearchOptions tests that only non-zero overrides replace base fields.
+func TestMergeSearchOptions(t *testing.T) {
+	base := SearchOptions{State: "CA", City: "Fresno", Limit: 50, SortByRelevance: true}
+	got := MergeSearchOptions(base, SearchOptions{City: "Davis", MaxResults: 100})
+	want := SearchOptions{State: "CA", City: "Davis", Limit: 50, MaxResults: 100, SortByRelevance: true}
+	if got != want {
+		t.Errorf("MergeSearchOptions = %+v, want %+v", got, want)
+	}
+
+	// Every field set in overrides is copied
+	var overrides SearchOptions
+	v := reflect.ValueOf(&overrides).Elem()
+	for i := range v.NumField() {
+		switch f := v.Field(i); f.Kind() {
+		case reflect.String:
+			f.SetString("x")
+		case reflect.Int:
+			f.SetInt(1)
+		case reflect.Bool:
+			f.SetBool(true)
+		default:
+			t.Fatalf("unhandled field %s", v.Type().Field(i).Name)
+		}
+	}
+	if got := MergeSearchOptions(SearchOptions{}, overrides); got != overrides {
+		t.Errorf("MergeSearchOptions dropped overrides: %+v, want %+v", got, overrides)
+	}
+}
+
+// TestLoadPresets tests decoding presets and rejecting unknown fields.
+func TestLoadPresets(t *testing.T) {
+	presets, err := LoadPresets(strings.NewReader(`{"ca": {"State": "CA", "Limit": 20}}`))
+	if err != nil || presets["ca"] != (SearchOptions{State: "CA", Limit: 20}) {
+		t.Errorf("LoadPresets = %+v, %v", presets, err)
+	}
+	if _, err := LoadPresets(strings.NewReader(`{"ca": {"Sate": "CA"}}`)); err == nil {
+		t.Error("expected unknown field to fail")
+	}
+}
@@ -0,0 +1,131 @@
+package gonpi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProviderField names a group of Provider fields that WithProjection can keep.
+type ProviderField string
+
+// Projectable provider fields, named as in the API response.
+const (
+	FieldBasic             ProviderField = "basic"
+	FieldAddresses         ProviderField = "addresses"
+	FieldTaxonomies        ProviderField = "taxonomies"
+	FieldIdentifiers       ProviderField = "identifiers"
+	FieldEndpoints         ProviderField = "endpoints"
+	FieldPracticeLocations ProviderField = "practice_locations"
+	FieldOtherNames        ProviderField = "other_names"
+)
+
+// WithProjection decodes only the given fields of each provider returned by the API,
+// plus the number, enumeration type and dates, which are always kept. The other fields
+// are skipped while decoding instead of being allocated and discarded, which cuts
+// memory use for bulk jobs that only need, say, names and addresses.
+//
+// Projected records are partial, as reported by RetrievalMeta.Partial, so they are
+// neither cached nor written to the store; cached full records are still served.
+// Projection does not apply with WithStrictDecoding or WithSchemaWarnings, which need
+// every field.
+//
+// Example usage:
+//
+//	bulk := client.With(gonpi.WithProjection(gonpi.FieldBasic, gonpi.FieldAddresses))
+func WithProjection(fields ...ProviderField) ClientOption {
+	return func(c *Client) {
+		c.projection = newProjection(fields)
+	}
+}
+
+// projection is a partial APIResponse type holding only the projected fields.
+type projection struct {
+	fields   []ProviderField
+	response reflect.Type // APIResponse with Results of the partial provider type
+	index    []int        // Provider field index of each partial provider field
+}
+
+var (
+	providerType    = reflect.TypeFor[Provider]()
+	apiResponseType = reflect.TypeFor[APIResponse]()
+)
+
+func newProjection(fields []ProviderField) *projection {
+	p := &projection{fields: slices.Clone(fields)}
+	var partial []reflect.StructField
+	for _, field := range codecFields(providerType) {
+		sf := providerType.Field(field.index)
+		if !projectable(sf.Type) || slices.Contains(fields, ProviderField(field.name)) {
+			if field.name == "gonpi_extensions" {
+				continue
+			}
+			partial = append(partial, reflect.StructField{Name: sf.Name, Type: sf.Type, Tag: sf.Tag})
+			p.index = append(p.index, field.index)
+		}
+	}
+
+	var response []reflect.StructField
+	for i := 0; i < apiResponseType.NumField(); i++ {
+		sf := apiResponseType.Field(i)
+		if sf.Name == "Results" {
+			sf.Type = reflect.SliceOf(reflect.StructOf(partial))
+		}
+		response = append(response, reflect.StructField{Name: sf.Name, Type: sf.Type, Tag: sf.Tag})
+	}
+	p.response = reflect.StructOf(response)
+	return p
+}
+
+// projectable reports whether a Provider field of type t can be left out; scalar
+// fields are always kept.
+func projectable(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Struct || t.Kind() == reflect.Pointer
+}
+
+// projecting reports whether responses are decoded with the projection.
+func (c *Client) projecting() bool {
+	return c.projection != nil && !c.strictDecoding && c.drift == nil
+}
+
+// decodeProjected decodes the API response in body into result through the partial
+// response type.
+func (c *Client) decodeProjected(body io.Reader, result *APIResponse, span trace.Span) error {
+	partial := reflect.New(c.projection.response)
+	var err error
+	if c.jsonDecoder != nil {
+		var data []byte
+		if data, err = io.ReadAll(body); err == nil {
+			err = c.jsonDecoder(data, partial.Interface())
+		}
+	} else {
+		err = json.NewDecoder(body).Decode(partial.Interface())
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode response")
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	src := partial.Elem()
+	dst := reflect.ValueOf(result).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if src.Type().Field(i).Name != "Results" {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	results := src.FieldByName("Results")
+	result.Results = make([]Provider, results.Len())
+	for i := range result.Results {
+		from, to := results.Index(i), reflect.ValueOf(&result.Results[i]).Elem()
+		for j, index := range c.projection.index {
+			to.Field(index).Set(from.Field(j))
+		}
+	}
+	return nil
+}
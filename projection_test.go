@@ -0,0 +1,95 @@
+package gonpi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TestWithProjection tests that only projected fields are decoded and that partial
+// records are not cached.
+func TestWithProjection(t *testing.T) {
+	provider := codecProvider()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{provider}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithCache(time.Minute), WithProjection(FieldBasic, FieldAddresses))
+	defer client.Close()
+
+	retrievals := NewRetrievals()
+	ctx := ContextWithCallOptions(context.Background(), WithRetrievals(retrievals))
+	got, err := client.GetProviderByNPI(ctx, provider.Number)
+	if err != nil || got == nil {
+		t.Fatalf("GetProviderByNPI = %v, %v", got, err)
+	}
+	if got.Number != provider.Number || got.LastUpdatedEpoch != provider.LastUpdatedEpoch || got.Basic != provider.Basic || len(got.Addresses) != 2 {
+		t.Errorf("projected fields missing: %+v", got)
+	}
+	if got.Taxonomies != nil || got.Endpoints != nil || got.Identifiers != nil || got.OtherNames != nil || got.PracticeLocations != nil {
+		t.Errorf("unprojected fields decoded: %+v", got)
+	}
+	if !retrievals.Meta(provider.Number).Partial {
+		t.Error("expected a partial record")
+	}
+	if status := client.Status(); status.CacheEntries != 0 {
+		t.Errorf("partial record was cached: %d entries", status.CacheEntries)
+	}
+
+	full := client.With(func(c *Client) { c.projection = nil })
+	if got, _ := full.GetProviderByNPI(ctx, provider.Number); got == nil || retrievals.Meta(provider.Number).Partial || len(got.Endpoints) != 1 {
+		t.Errorf("expected a full record: %+v", got)
+	}
+}
+
+// TestWithProjection_Strict tests that strict decoding decodes every field.
+func TestWithProjection_Strict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithProjection(FieldBasic), WithStrictDecoding())
+	retrievals := NewRetrievals()
+	providers, err := client.SearchProviders(ContextWithCallOptions(context.Background(), WithRetrievals(retrievals)), SearchOptions{LastName: "Doe"})
+	if err != nil || len(providers) != 1 || len(providers[0].Taxonomies) != 1 || retrievals.Meta(providers[0].Number).Partial {
+		t.Errorf("SearchProviders = %+v, %v", providers, err)
+	}
+}
+
+// BenchmarkProjection compares decoding a search response in full, as doRequest does,
+// with decoding it through a projection.
+func BenchmarkProjection(b *testing.B) {
+	providers := make([]Provider, 200)
+	for i := range providers {
+		providers[i] = codecProvider()
+	}
+	data, _ := json.Marshal(mockAPIResponse(providers))
+	_, span := noop.NewTracerProvider().Tracer("").Start(context.Background(), "")
+	for _, tt := range []struct {
+		name   string
+		client *Client
+	}{
+		{"full", NewClient()},
+		{"names", NewClient(WithProjection(FieldBasic))},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				var response APIResponse
+				if tt.client.projecting() {
+					tt.client.decodeProjected(bytes.NewReader(data), &response, span)
+				} else {
+					json.NewDecoder(bytes.NewReader(data)).Decode(&response)
+				}
+			}
+		})
+	}
+}
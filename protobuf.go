@@ -0,0 +1,567 @@
+package gonpi
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProtoSchema is the Protocol Buffers (proto3) schema of the messages written by
+// ProtobufCodec, as produced by GenerateProtoSchema; run "go generate" after changing
+// types. Other languages can generate their bindings from it to read cached providers
+// and change events.
+//
+//go:embed schema/gonpi.proto
+var ProtoSchema []byte
+
+// ProtobufCodec encodes Provider, ChangeEvent, APIResponse and the types they contain
+// in the Protocol Buffers wire format described by ProtoSchema. Field numbers come from
+// the schema, so records stay readable as fields are added. As in proto3, empty lists
+// decode as nil, and FieldChange values are carried as JSON bytes.
+type ProtobufCodec struct{}
+
+// Name implements Codec.
+func (ProtobufCodec) Name() string { return "protobuf" }
+
+// ContentType implements Codec.
+func (ProtobufCodec) ContentType() string { return "application/x-protobuf" }
+
+// Marshal implements Codec. v must be a struct, or a pointer to one, with a message in
+// ProtoSchema.
+func (ProtobufCodec) Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("protobuf: cannot marshal %T", v)
+	}
+	return appendProtoMessage(make([]byte, 0, 1024), rv)
+}
+
+// Unmarshal implements Codec. v must be a pointer to a struct with a message in
+// ProtoSchema.
+func (ProtobufCodec) Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("protobuf: Unmarshal requires a non-nil struct pointer, got %T", v)
+	}
+	return decodeProtoMessage(data, rv.Elem())
+}
+
+// Protocol Buffers wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errProtoShort = errors.New("protobuf: unexpected end of data")
+
+// protoRoots are the types whose messages GenerateProtoSchema writes, with every
+// struct type they contain.
+var protoRoots = []reflect.Type{
+	reflect.TypeFor[APIResponse](),
+	reflect.TypeFor[ChangeEvent](),
+}
+
+// protoMessage is a message parsed from a schema.
+type protoMessage struct {
+	fields   map[string]int // field name to number
+	reserved []int
+}
+
+var protoSchema = sync.OnceValues(func() (map[string]*protoMessage, error) {
+	return parseProtoSchema(ProtoSchema)
+})
+
+// parseProtoSchema reads the message field numbers of a schema written by
+// GenerateProtoSchema.
+func parseProtoSchema(data []byte) (map[string]*protoMessage, error) {
+	messages := make(map[string]*protoMessage)
+	var current *protoMessage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(text, "//"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "message ") && strings.HasSuffix(text, "{"):
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(text, "message "), "{"))
+			current = &protoMessage{fields: make(map[string]int)}
+			messages[name] = current
+		case text == "}":
+			current = nil
+		case current != nil && strings.HasPrefix(text, "reserved "):
+			for _, n := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(text, "reserved "), ";"), ",") {
+				num, err := strconv.Atoi(strings.TrimSpace(n))
+				if err != nil {
+					return nil, fmt.Errorf("protobuf schema line %d: invalid reserved number", line)
+				}
+				current.reserved = append(current.reserved, num)
+			}
+		case current != nil:
+			decl, num, ok := strings.Cut(strings.TrimSuffix(text, ";"), "=")
+			words := strings.Fields(decl)
+			n, err := strconv.Atoi(strings.TrimSpace(num))
+			if !ok || len(words) < 2 || err != nil {
+				return nil, fmt.Errorf("protobuf schema line %d: invalid field %q", line, text)
+			}
+			current.fields[words[len(words)-1]] = n
+		}
+	}
+	return messages, scanner.Err()
+}
+
+// GenerateProtoSchema returns the proto3 schema of the gonpi messages. Fields already
+// in previous, a schema generated earlier, keep their numbers; new fields get unused
+// numbers and the numbers of removed fields are reserved, so that encoded records stay
+// compatible.
+func GenerateProtoSchema(previous []byte) ([]byte, error) {
+	prev, err := parseProtoSchema(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []reflect.Type
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t == timeType || seen[t] {
+			return
+		}
+		seen[t] = true
+		types = append(types, t)
+		for _, field := range codecFields(t) {
+			walk(t.Field(field.index).Type)
+		}
+	}
+	for _, root := range protoRoots {
+		walk(root)
+	}
+	slices.SortFunc(types, func(a, b reflect.Type) int { return strings.Compare(a.Name(), b.Name()) })
+
+	var b strings.Builder
+	b.WriteString("// Protocol Buffers schema of the gonpi types encoded by gonpi.ProtobufCodec.\n")
+	b.WriteString("// Generated by go run ./internal/cmd/genschema; do not edit. Regenerating keeps\n")
+	b.WriteString("// the numbers of existing fields.\n")
+	b.WriteString("syntax = \"proto3\";\n\npackage gonpi;\n\nimport \"google/protobuf/timestamp.proto\";\n")
+
+	for _, t := range types {
+		old := prev[t.Name()]
+		if old == nil {
+			old = &protoMessage{}
+		}
+		used := slices.Clone(old.reserved)
+		for _, num := range old.fields {
+			used = append(used, num)
+		}
+		next := 1
+		if len(used) > 0 {
+			next = slices.Max(used) + 1
+		}
+
+		fmt.Fprintf(&b, "\nmessage %s {\n", t.Name())
+		present := make(map[string]bool)
+		for _, field := range codecFields(t) {
+			typ, err := protoType(t.Field(field.index).Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name(), t.Field(field.index).Name, err)
+			}
+			num, ok := old.fields[field.name]
+			if !ok {
+				num = next
+				next++
+			}
+			present[field.name] = true
+			comment := ""
+			if t.Field(field.index).Type.Kind() == reflect.Interface {
+				comment = " // JSON-encoded"
+			}
+			fmt.Fprintf(&b, "  %s %s = %d;%s\n", typ, field.name, num, comment)
+		}
+
+		reserved := slices.Clone(old.reserved)
+		for name, num := range old.fields {
+			if !present[name] {
+				reserved = append(reserved, num)
+			}
+		}
+		if len(reserved) > 0 {
+			slices.Sort(reserved)
+			nums := make([]string, len(reserved))
+			for i, num := range reserved {
+				nums[i] = strconv.Itoa(num)
+			}
+			fmt.Fprintf(&b, "  reserved %s;\n", strings.Join(nums, ", "))
+		}
+		b.WriteString("}\n")
+	}
+	return []byte(b.String()), nil
+}
+
+// protoType returns the proto3 type of a field of Go type t.
+func protoType(t reflect.Type) (string, error) {
+	if t == timeType {
+		return "google.protobuf.Timestamp", nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int64", nil
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint64", nil
+	case reflect.Float32:
+		return "float", nil
+	case reflect.Float64:
+		return "double", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Interface:
+		return "bytes", nil
+	case reflect.Struct:
+		return t.Name(), nil
+	case reflect.Pointer:
+		if t.Elem().Kind() == reflect.Struct {
+			return protoType(t.Elem())
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+		elem, err := protoType(t.Elem())
+		if err != nil || strings.HasPrefix(elem, "repeated ") {
+			return "", fmt.Errorf("unsupported list type %v", t)
+		}
+		return "repeated " + elem, nil
+	}
+	return "", fmt.Errorf("unsupported type %v", t)
+}
+
+// protoField is a struct field with its field number.
+type protoField struct {
+	index int
+	num   int
+}
+
+var protoFieldCache sync.Map // reflect.Type -> []protoField
+
+// protoFields returns the fields of struct type t numbered as in ProtoSchema.
+func protoFields(t reflect.Type) ([]protoField, error) {
+	if fields, ok := protoFieldCache.Load(t); ok {
+		return fields.([]protoField), nil
+	}
+	schema, err := protoSchema()
+	if err != nil {
+		return nil, err
+	}
+	message, ok := schema[t.Name()]
+	if !ok {
+		return nil, fmt.Errorf("protobuf: no message for %v in ProtoSchema", t)
+	}
+	var fields []protoField
+	for _, field := range codecFields(t) {
+		num, ok := message.fields[field.name]
+		if !ok {
+			return nil, fmt.Errorf("protobuf: no field %s.%s in ProtoSchema", t.Name(), field.name)
+		}
+		fields = append(fields, protoField{index: field.index, num: num})
+	}
+	protoFieldCache.Store(t, fields)
+	return fields, nil
+}
+
+func appendTag(b []byte, num, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(num)<<3|uint64(wire))
+}
+
+// appendProtoMessage appends the fields of struct v.
+func appendProtoMessage(b []byte, v reflect.Value) ([]byte, error) {
+	fields, err := protoFields(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		if b, err = appendProtoField(b, field.num, v.Field(field.index), false); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendProtoField appends field num holding v. Zero values are left out unless the
+// value is a list element.
+func appendProtoField(b []byte, num int, v reflect.Value, element bool) ([]byte, error) {
+	if !element && v.IsZero() {
+		return b, nil
+	}
+	if v.Type() == timeType {
+		return appendNested(b, num, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b = appendTag(b, num, wireVarint)
+		if v.Bool() {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return binary.AppendUvarint(appendTag(b, num, wireVarint), uint64(v.Int())), nil
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return binary.AppendUvarint(appendTag(b, num, wireVarint), v.Uint()), nil
+	case reflect.Float32:
+		return binary.LittleEndian.AppendUint32(appendTag(b, num, wireFixed32), math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(appendTag(b, num, wireFixed64), math.Float64bits(v.Float())), nil
+	case reflect.String:
+		b = binary.AppendUvarint(appendTag(b, num, wireBytes), uint64(v.Len()))
+		return append(b, v.String()...), nil
+	case reflect.Interface:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("protobuf: %w", err)
+		}
+		b = binary.AppendUvarint(appendTag(b, num, wireBytes), uint64(len(data)))
+		return append(b, data...), nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, fmt.Errorf("protobuf: nil list element %v", v.Type())
+		}
+		return appendNested(b, num, v.Elem())
+	case reflect.Struct:
+		return appendNested(b, num, v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b = binary.AppendUvarint(appendTag(b, num, wireBytes), uint64(v.Len()))
+			return append(b, v.Bytes()...), nil
+		}
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if b, err = appendProtoField(b, num, v.Index(i), true); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("protobuf: unsupported type %v", v.Type())
+}
+
+// appendNested appends struct v as an embedded message in field num.
+func appendNested(b []byte, num int, v reflect.Value) ([]byte, error) {
+	var body []byte
+	var err error
+	if v.Type() == timeType {
+		body = appendTimestamp(nil, v)
+	} else if body, err = appendProtoMessage(nil, v); err != nil {
+		return nil, err
+	}
+	b = binary.AppendUvarint(appendTag(b, num, wireBytes), uint64(len(body)))
+	return append(b, body...), nil
+}
+
+// appendTimestamp appends a google.protobuf.Timestamp holding the time.Time in v.
+func appendTimestamp(b []byte, v reflect.Value) []byte {
+	t := v.Interface().(time.Time)
+	if sec := t.Unix(); sec != 0 {
+		b = binary.AppendUvarint(appendTag(b, 1, wireVarint), uint64(sec))
+	}
+	if nsec := t.Nanosecond(); nsec != 0 {
+		b = binary.AppendUvarint(appendTag(b, 2, wireVarint), uint64(nsec))
+	}
+	return b
+}
+
+// decodeProtoMessage decodes the fields in data into struct v, skipping fields
+// unknown to ProtoSchema.
+func decodeProtoMessage(data []byte, v reflect.Value) error {
+	fields, err := protoFields(v.Type())
+	if err != nil {
+		return err
+	}
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errProtoShort
+		}
+		data = data[n:]
+		num, wire := int(tag>>3), int(tag&7)
+
+		var varint uint64
+		var payload []byte
+		switch wire {
+		case wireVarint:
+			if varint, n = binary.Uvarint(data); n <= 0 {
+				return errProtoShort
+			}
+		case wireFixed64:
+			n = 8
+		case wireFixed32:
+			n = 4
+		case wireBytes:
+			size, m := binary.Uvarint(data)
+			if m <= 0 || size > uint64(len(data)-m) {
+				return errProtoShort
+			}
+			n = m + int(size)
+			payload = data[m:n]
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d", wire)
+		}
+		if n > len(data) {
+			return errProtoShort
+		}
+		if wire == wireFixed64 || wire == wireFixed32 {
+			payload = data[:n]
+		}
+		data = data[n:]
+
+		index := -1
+		for _, field := range fields {
+			if field.num == num {
+				index = field.index
+				break
+			}
+		}
+		if index < 0 {
+			continue
+		}
+		if err := decodeProtoField(v.Field(index), wire, varint, payload); err != nil {
+			return fmt.Errorf("%s.%s: %w", v.Type().Name(), v.Type().Field(index).Name, err)
+		}
+	}
+	return nil
+}
+
+// decodeProtoField stores one field occurrence with the given wire type in v.
+func decodeProtoField(v reflect.Value, wire int, varint uint64, payload []byte) error {
+	if v.Type() == timeType {
+		if wire != wireBytes {
+			return protoMismatch(wire, v)
+		}
+		return decodeTimestamp(payload, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if wire != wireVarint {
+			return protoMismatch(wire, v)
+		}
+		v.SetBool(varint != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if wire != wireVarint {
+			return protoMismatch(wire, v)
+		}
+		if v.OverflowInt(int64(varint)) {
+			return fmt.Errorf("protobuf: %d overflows %v", int64(varint), v.Type())
+		}
+		v.SetInt(int64(varint))
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if wire != wireVarint {
+			return protoMismatch(wire, v)
+		}
+		if v.OverflowUint(varint) {
+			return fmt.Errorf("protobuf: %d overflows %v", varint, v.Type())
+		}
+		v.SetUint(varint)
+	case reflect.Float32:
+		if wire != wireFixed32 {
+			return protoMismatch(wire, v)
+		}
+		v.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(payload))))
+	case reflect.Float64:
+		if wire != wireFixed64 {
+			return protoMismatch(wire, v)
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(payload)))
+	case reflect.String:
+		if wire != wireBytes {
+			return protoMismatch(wire, v)
+		}
+		v.SetString(string(payload))
+	case reflect.Interface:
+		if wire != wireBytes {
+			return protoMismatch(wire, v)
+		}
+		var value any
+		if err := json.Unmarshal(payload, &value); err != nil {
+			return fmt.Errorf("protobuf: %w", err)
+		}
+		if value != nil {
+			v.Set(reflect.ValueOf(value))
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeProtoField(v.Elem(), wire, varint, payload)
+	case reflect.Struct:
+		if wire != wireBytes {
+			return protoMismatch(wire, v)
+		}
+		return decodeProtoMessage(payload, v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if wire != wireBytes {
+				return protoMismatch(wire, v)
+			}
+			v.SetBytes(slices.Clone(payload))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeProtoField(elem, wire, varint, payload); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+	default:
+		return fmt.Errorf("protobuf: unsupported type %v", v.Type())
+	}
+	return nil
+}
+
+func protoMismatch(wire int, v reflect.Value) error {
+	return fmt.Errorf("protobuf: wire type %d does not match %v", wire, v.Type())
+}
+
+// decodeTimestamp decodes a google.protobuf.Timestamp into the time.Time in v.
+func decodeTimestamp(data []byte, v reflect.Value) error {
+	var sec, nsec int64
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 || tag&7 != wireVarint {
+			return errProtoShort
+		}
+		data = data[n:]
+		value, m := binary.Uvarint(data)
+		if m <= 0 {
+			return errProtoShort
+		}
+		data = data[m:]
+		switch tag >> 3 {
+		case 1:
+			sec = int64(value)
+		case 2:
+			nsec = int64(value)
+		}
+	}
+	v.Set(reflect.ValueOf(time.Unix(sec, nsec).UTC()))
+	return nil
+}
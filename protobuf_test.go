@@ -0,0 +1,80 @@
+package gonpi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestProtoSchema_UpToDate tests that the embedded Protocol Buffers schema matches
+// the Go types. Run "go generate" to refresh it after changing types.
+func TestProtoSchema_UpToDate(t *testing.T) {
+	generated, err := GenerateProtoSchema(ProtoSchema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(ProtoSchema, generated) {
+		t.Error("embedded proto schema is stale; run go generate")
+	}
+}
+
+// TestGenerateProtoSchema_StableNumbers tests that regenerating keeps field numbers,
+// numbers new fields after the highest number used and reserves removed fields.
+func TestGenerateProtoSchema_StableNumbers(t *testing.T) {
+	previous := []byte(`syntax = "proto3";
+
+message County {
+  string name = 4;
+  string population = 7;
+  reserved 9;
+}
+`)
+	generated, err := GenerateProtoSchema(previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "message County {\n  string fips = 10;\n  string name = 4;\n  reserved 7, 9;\n}\n"
+	if !strings.Contains(string(generated), want) {
+		t.Errorf("generated schema does not contain\n%s\ngot:\n%s", want, generated)
+	}
+
+	if _, err := GenerateProtoSchema([]byte("message County {\n  string name;\n}\n")); err == nil {
+		t.Error("expected error for a field without a number")
+	}
+}
+
+// TestProtobufCodec_Wire tests the wire format of a message and that unknown fields are
+// skipped.
+func TestProtobufCodec_Wire(t *testing.T) {
+	got, err := ProtobufCodec{}.Marshal(CountyLocation{PostalCode: "62701", County: County{FIPS: "17167"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// postal_code = 2, county = 3 { fips = 1 }
+	want := []byte{0x12, 5, '6', '2', '7', '0', '1', 0x1a, 7, 0x0a, 5, '1', '7', '1', '6', '7'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal = % x, want % x", got, want)
+	}
+
+	// An unknown varint field 15 and fixed64 field 14 before the known fields
+	withUnknown := append([]byte{0x78, 0x2a, 0x71, 1, 2, 3, 4, 5, 6, 7, 8}, want...)
+	var decoded CountyLocation
+	if err := (ProtobufCodec{}).Unmarshal(withUnknown, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.PostalCode != "62701" || decoded.County.FIPS != "17167" {
+		t.Errorf("decoded %+v", decoded)
+	}
+
+	for _, bad := range [][]byte{{0x12, 9, 'x'}, {0x12}, {0x10, 1}, {0x0b}} {
+		if err := (ProtobufCodec{}).Unmarshal(bad, &decoded); err == nil {
+			t.Errorf("expected error for % x", bad)
+		}
+	}
+	if _, err := (ProtobufCodec{}).Marshal(Coordinates{}); err != nil {
+		t.Errorf("empty message: %v", err)
+	}
+	if _, err := (ProtobufCodec{}).Marshal(map[string]string{}); err == nil {
+		t.Error("expected error for a non-struct value")
+	}
+}
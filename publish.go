@@ -0,0 +1,124 @@
+package gonpi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KafkaProducer is the minimal producer API needed by KafkaPublisher. Adapt the Kafka
+// client of your choice, e.g. a segmentio/kafka-go Writer or a franz-go Client.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// KafkaPublisher publishes change events to a Kafka topic, keyed by NPI so that events
+// for one provider stay ordered within a partition.
+type KafkaPublisher struct {
+	Producer KafkaProducer
+	Topic    string
+
+	// Codec encodes events. If nil, events are JSON-encoded.
+	Codec Codec
+}
+
+// Publish implements Publisher.
+func (p *KafkaPublisher) Publish(ctx context.Context, event ChangeEvent) error {
+	value, err := encodeEvent(p.Codec, event)
+	if err != nil {
+		return err
+	}
+	return p.Producer.Produce(ctx, p.Topic, []byte(event.NPI), value)
+}
+
+// NATSConn is the subset of *nats.Conn used by NATSPublisher.
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublisher publishes change events to a NATS subject.
+type NATSPublisher struct {
+	Conn    NATSConn
+	Subject string
+
+	// Codec encodes events. If nil, events are JSON-encoded.
+	Codec Codec
+}
+
+// Publish implements Publisher.
+func (p *NATSPublisher) Publish(_ context.Context, event ChangeEvent) error {
+	data, err := encodeEvent(p.Codec, event)
+	if err != nil {
+		return err
+	}
+	return p.Conn.Publish(p.Subject, data)
+}
+
+// WebhookPublisher POSTs each change event to a URL.
+// Any non-2xx response is returned as an *APIError.
+type WebhookPublisher struct {
+	URL string
+
+	// Secret, if set, signs each delivery with the WebhookSignatureHeader and
+	// WebhookTimestampHeader headers, which receivers check with VerifyWebhook.
+	Secret []byte
+
+	// HTTPClient is used to send requests. If nil, a client with DefaultTimeout is used.
+	HTTPClient *http.Client
+
+	// Header is added to every request, e.g. for authorization.
+	Header http.Header
+
+	// Codec encodes events and sets the Content-Type. If nil, events are JSON-encoded.
+	Codec Codec
+}
+
+// Publish implements Publisher.
+func (p *WebhookPublisher) Publish(ctx context.Context, event ChangeEvent) error {
+	body, err := encodeEvent(p.Codec, event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range p.Header {
+		req.Header[key] = values
+	}
+	contentType := "application/json"
+	if p.Codec != nil {
+		contentType = p.Codec.ContentType()
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "gonpi/1.0")
+	if len(p.Secret) > 0 {
+		now := time.Now()
+		req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(p.Secret, now, body))
+	}
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxResponseBodySize))
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("webhook returned status %d: %s", resp.StatusCode, string(body)),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package gonpi
+
+import (
+	"sort"
+	"strings"
+)
+
+// Relevance weights used by ScoreProvider.
+const (
+	scoreNameExact      = 10.0
+	scoreNamePrefix     = 5.0
+	scoreNameContains   = 2.0
+	scoreFirstExact     = 4.0
+	scoreFirstPrefix    = 2.0
+	scoreCity           = 3.0
+	scoreState          = 1.0
+	scorePostalCode     = 2.0
+	scoreCredential     = 3.0
+	scorePrimaryTaxon   = 4.0
+	scoreSecondaryTaxon = 1.0
+)
+
+// RankedProvider is a provider with its relevance score against a query.
+type RankedProvider struct {
+	Provider
+	Score float64
+}
+
+// ScoreProvider scores how well provider matches the criteria in opts. Higher is more
+// relevant; criteria left empty in opts contribute nothing. Comparisons ignore case and
+// trailing "*" wildcards.
+//
+// Surname (or organization name) matches dominate: an exact match outranks a prefix
+// match, which outranks a substring match. A matching first name, practice city, state
+// or postal code adds a smaller boost, as do holding the credential in opts and the
+// taxonomy description appearing in the primary taxonomy (or, less, in any other
+// taxonomy).
+func ScoreProvider(provider Provider, opts SearchOptions) float64 {
+	var score float64
+
+	score += nameScore(provider.Basic.LastName, opts.LastName, scoreNameExact, scoreNamePrefix, scoreNameContains)
+	score += nameScore(provider.organizationName(), opts.OrganizationName, scoreNameExact, scoreNamePrefix, scoreNameContains)
+	score += nameScore(provider.Basic.FirstName, opts.FirstName, scoreFirstExact, scoreFirstPrefix, 0)
+
+	if opts.City != "" || opts.State != "" || opts.PostalCode != "" {
+		var city, state, postal float64
+		for _, addr := range practiceAddresses(&provider) {
+			if opts.City != "" && strings.EqualFold(addr.City, opts.City) {
+				city = scoreCity
+			}
+			if opts.State != "" && strings.EqualFold(addr.State, opts.State) {
+				state = scoreState
+			}
+			if q := normalizeQuery(opts.PostalCode); q != "" && strings.HasPrefix(addr.PostalCode, q) {
+				postal = scorePostalCode
+			}
+		}
+		score += city + state + postal
+	}
+
+	if opts.Credential != "" && provider.HasCredential(opts.Credential) {
+		score += scoreCredential
+	}
+
+	if q := normalizeQuery(opts.TaxonomyDescription); q != "" {
+		var taxon float64
+		for _, t := range provider.Taxonomies {
+			if !strings.Contains(strings.ToLower(t.Desc), q) {
+				continue
+			}
+			if t.Primary {
+				taxon = scorePrimaryTaxon
+				break
+			}
+			taxon = scoreSecondaryTaxon
+		}
+		score += taxon
+	}
+	return score
+}
+
+// RankProviders scores providers against opts and returns them ordered from most to
+// least relevant. Providers with equal scores keep their original order.
+//
+// Example usage:
+//
+//	opts := SearchOptions{LastName: "Smith", City: "Boston", State: "MA"}
+//	results, err := client.SearchProviders(ctx, opts)
+//	ranked := RankProviders(results, opts)
+func RankProviders(providers []Provider, opts SearchOptions) []RankedProvider {
+	ranked := make([]RankedProvider, len(providers))
+	for i, provider := range providers {
+		ranked[i] = RankedProvider{Provider: provider, Score: ScoreProvider(provider, opts)}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	return ranked
+}
+
+// nameScore compares value with query, returning exact, prefix or contains.
+func nameScore(value, query string, exact, prefix, contains float64) float64 {
+	q := normalizeQuery(query)
+	if q == "" {
+		return 0
+	}
+	v := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case v == q:
+		return exact
+	case strings.HasPrefix(v, q):
+		return prefix
+	case strings.Contains(v, q):
+		return contains
+	}
+	return 0
+}
+
+// normalizeQuery lowercases a search term and strips the API's trailing wildcard.
+func normalizeQuery(s string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), "*"))
+}
@@ -0,0 +1,88 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func rankProvider(number, first, last, city, taxonomy string, primary bool) Provider {
+	return Provider{
+		Number:     number,
+		Basic:      BasicInfo{FirstName: first, LastName: last},
+		Addresses:  []Address{{AddressPurpose: "LOCATION", City: city, State: "MA"}},
+		Taxonomies: []Taxonomy{{Desc: taxonomy, Primary: primary}},
+	}
+}
+
+// TestRankProviders tests ordering by surname, city and taxonomy relevance.
+func TestRankProviders(t *testing.T) {
+	providers := []Provider{
+		rankProvider("1", "JOHN", "SMITHSON", "BOSTON", "Family Medicine", true),
+		rankProvider("2", "JOHN", "SMITH", "WORCESTER", "Cardiology", true),
+		rankProvider("3", "JANE", "SMITH", "BOSTON", "Family Medicine", true),
+		rankProvider("4", "JOHN", "SMITH", "BOSTON", "Family Medicine", false),
+		rankProvider("5", "JOHN", "SMITH", "BOSTON", "Family Medicine", true),
+	}
+	opts := SearchOptions{FirstName: "john", LastName: "Smith*", City: "Boston", TaxonomyDescription: "family"}
+
+	ranked := RankProviders(providers, opts)
+	var order []string
+	for _, r := range ranked {
+		order = append(order, r.Number)
+	}
+	want := []string{"5", "4", "3", "1", "2"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+	if ranked[0].Score <= ranked[1].Score {
+		t.Errorf("top score %v should exceed %v", ranked[0].Score, ranked[1].Score)
+	}
+}
+
+// TestScoreProvider_EmptyQuery tests that empty criteria contribute nothing.
+func TestScoreProvider_EmptyQuery(t *testing.T) {
+	if score := ScoreProvider(rankProvider("1", "A", "B", "C", "D", true), SearchOptions{}); score != 0 {
+		t.Errorf("score = %v, want 0", score)
+	}
+}
+
+// TestScoreProvider_Credential tests that holding the queried credential adds a boost.
+func TestScoreProvider_Credential(t *testing.T) {
+	md := rankProvider("1", "JOHN", "SMITH", "BOSTON", "", true)
+	md.Basic.Credential = "M.D."
+	np := rankProvider("2", "JOHN", "SMITH", "BOSTON", "", true)
+	np.Basic.Credential = "NP"
+
+	opts := SearchOptions{LastName: "Smith", Credential: "md"}
+	if ranked := RankProviders([]Provider{np, md}, opts); ranked[0].Number != "1" || ranked[0].Score != ranked[1].Score+scoreCredential {
+		t.Errorf("expected the MD ranked first by the credential boost, got %+v", ranked)
+	}
+}
+
+// TestSearchProviders_SortByRelevance tests that results are reordered when requested.
+func TestSearchProviders_SortByRelevance(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("sort_by_relevance") {
+			t.Error("relevance flag should not be sent to the API")
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{
+			rankProvider("1", "", "SMITHERS", "", "", false),
+			rankProvider("2", "", "SMITH", "", "", false),
+		}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	results, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Smith", SortByRelevance: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Number != "2" {
+		t.Errorf("expected exact surname match first, got %s", results[0].Number)
+	}
+}
@@ -0,0 +1,118 @@
+package gonpi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits the client to requestsPerSecond HTTP requests, including
+// retries, allowing bursts of up to burst requests. Requests wait for a slot or until
+// their context is cancelled, ordered by the Priority in their CallMetadata or set
+// with WithDefaultPriority.
+// A requestsPerSecond of zero or less removes the limit.
+//
+// Derived clients (see With) share the parent's limit unless they set their own.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(c *Client) {
+		if requestsPerSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithHeader adds a header sent with every API request, replacing any earlier value
+// for key. It can also override the default User-Agent.
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+		c.headers.Set(key, value)
+	}
+}
+
+// rateLimiter is a token bucket.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until a token is available for a request of the given priority or ctx
+// is done. A token taken in advance is returned if ctx is done first, so cancelled
+// requests do not delay the ones queued behind them.
+func (l *rateLimiter) wait(ctx context.Context, priority Priority) error {
+	for {
+		delay, done := l.reserve(priority)
+		if done && delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if done {
+				l.refund()
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// refund returns a token taken by reserve for a request that was not made.
+func (l *rateLimiter) refund() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(l.burst, l.tokens+1)
+}
+
+// reserve tries to take a token. It returns how long to wait and whether the token
+// is already taken; if not, the caller must wait and try again.
+func (l *rateLimiter) reserve(priority Priority) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	interval := time.Duration(float64(time.Second) / l.rate)
+
+	switch {
+	case priority < PriorityNormal:
+		// Low priority never goes into debt, so it only proceeds once every queued
+		// request has been served
+		if l.tokens >= 1 {
+			l.tokens--
+			return 0, true
+		}
+		return time.Duration((1 - l.tokens) / l.rate * float64(time.Second)), false
+	case priority > PriorityNormal:
+		// High priority takes a token ahead of the queue, waiting at most one interval
+		wait := time.Duration(-(l.tokens - 1) / l.rate * float64(time.Second))
+		l.tokens--
+		return min(wait, interval), true
+	default:
+		// Take the token now, going into debt if necessary, so that concurrent waiters
+		// queue up behind each other instead of all waking at once
+		l.tokens--
+		return time.Duration(-l.tokens / l.rate * float64(time.Second)), true
+	}
+}
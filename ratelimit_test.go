@@ -0,0 +1,74 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRateLimiter tests that the token bucket spaces requests after the burst.
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(50, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.wait(ctx, PriorityNormal); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	// Two requests pass immediately, the next two wait 20ms each
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("4 requests at 50/s with burst 2 took %v, want >= 40ms", elapsed)
+	}
+
+	slow := newRateLimiter(0.1, 1)
+	slow.wait(ctx, PriorityNormal)
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := slow.wait(cancelled, PriorityNormal); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+
+	// The cancelled request's token is refunded, so the limiter is not left in debt
+	slow.mu.Lock()
+	tokens := slow.tokens
+	slow.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("tokens after a cancelled wait = %v, want the token refunded", tokens)
+	}
+}
+
+// TestWithHeaderAndRateLimit tests custom headers and rate limiting on API requests.
+func TestWithHeaderAndRateLimit(t *testing.T) {
+	var gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("X-Api-Key")
+		json.NewEncoder(w).Encode(mockAPIResponse(nil))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithHeader("X-Api-Key", "secret"), WithRateLimit(0.1, 1), WithRetry(RetryConfig{}))
+	ctx := context.Background()
+	if _, err := client.SearchProviders(ctx, SearchOptions{LastName: "Doe"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotKey != "secret" {
+		t.Errorf("X-Api-Key = %q", gotKey)
+	}
+
+	limited, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.SearchProviders(limited, SearchOptions{LastName: "Doe"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected rate-limited request to time out, got %v", err)
+	}
+
+	unlimited := client.With(WithRateLimit(0, 0))
+	if _, err := unlimited.SearchProviders(ctx, SearchOptions{LastName: "Doe"}); err != nil {
+		t.Errorf("derived client without limit: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package gonpi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ExportField names a group of provider fields that a Redaction can drop or hash.
+type ExportField string
+
+// Redactable field groups.
+const (
+	// ExportPhones is every telephone and fax number: on addresses, practice
+	// locations and the authorized official.
+	ExportPhones ExportField = "phones"
+
+	// ExportOfficial is the authorized official's name, title, credential and phone.
+	ExportOfficial ExportField = "official"
+
+	// ExportIndividualName is an individual provider's name and other names.
+	// Organization names are kept.
+	ExportIndividualName ExportField = "individual_name"
+
+	// ExportStreetAddress is the street lines of addresses and practice locations.
+	// City, state and postal code are kept.
+	ExportStreetAddress ExportField = "street_address"
+
+	// ExportIdentifiers is the other provider identifiers, such as Medicaid numbers.
+	ExportIdentifiers ExportField = "identifiers"
+
+	// ExportLicenses is the license numbers listed on taxonomies.
+	ExportLicenses ExportField = "licenses"
+)
+
+// exportFields lists the redactable field groups, for parsing and help text.
+var exportFields = []ExportField{
+	ExportPhones, ExportOfficial, ExportIndividualName, ExportStreetAddress, ExportIdentifiers, ExportLicenses,
+}
+
+// RedactionMode selects what a Redaction does with a selected field.
+type RedactionMode int
+
+const (
+	// RedactDrop clears selected fields.
+	RedactDrop RedactionMode = iota
+
+	// RedactHash replaces non-empty selected fields with a hex-encoded SHA-256
+	// digest, so recipients can still match records without seeing the values.
+	RedactHash
+)
+
+// Redaction drops or hashes selected fields of providers before they are exported,
+// so extracts can be shared with vendors under minimal-necessary policies.
+//
+// Example usage:
+//
+//	redact := gonpi.Redaction{
+//	    Fields: []gonpi.ExportField{gonpi.ExportPhones, gonpi.ExportOfficial},
+//	    Mode:   gonpi.RedactHash,
+//	    Key:    secret,
+//	}
+//	writer.Write(redact.Apply(provider))
+type Redaction struct {
+	// Fields are the field groups to redact.
+	Fields []ExportField
+
+	// Mode selects dropping or hashing. Default: RedactDrop.
+	Mode RedactionMode
+
+	// Key, if set, makes RedactHash an HMAC-SHA256 under Key. Without a key, values
+	// with few possibilities, such as phone numbers, can be recovered by hashing
+	// every candidate.
+	Key []byte
+}
+
+// ParseExportFields parses a comma-separated list of field group names, such as
+// "phones,official".
+func ParseExportFields(spec string) ([]ExportField, error) {
+	var fields []ExportField
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		field := ExportField(name)
+		if !field.valid() {
+			names := make([]string, len(exportFields))
+			for i, f := range exportFields {
+				names[i] = string(f)
+			}
+			sort.Strings(names)
+			return nil, &ValidationError{
+				Field:   "fields",
+				Message: fmt.Sprintf("unknown export field %q: valid values are %s", name, strings.Join(names, ", ")),
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// valid reports whether f is a known field group.
+func (f ExportField) valid() bool {
+	for _, known := range exportFields {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns a copy of p with the selected fields redacted. p is not modified.
+func (r Redaction) Apply(p Provider) Provider {
+	if len(r.Fields) == 0 {
+		return p
+	}
+	redact := func(s *string) {
+		if *s == "" {
+			return
+		}
+		if r.Mode == RedactHash {
+			*s = r.hash(*s)
+		} else {
+			*s = ""
+		}
+	}
+
+	// Copy the slices that are edited in place
+	p.Addresses = slices.Clone(p.Addresses)
+	p.PracticeLocations = slices.Clone(p.PracticeLocations)
+	p.OtherNames = slices.Clone(p.OtherNames)
+	p.Identifiers = slices.Clone(p.Identifiers)
+	p.Taxonomies = slices.Clone(p.Taxonomies)
+
+	// Each value is redacted once, even if it belongs to several selected groups
+	selected := make(map[ExportField]bool, len(r.Fields))
+	for _, field := range r.Fields {
+		selected[field] = true
+	}
+	if selected[ExportPhones] {
+		for i := range p.Addresses {
+			redact(&p.Addresses[i].TelephoneNumber)
+			redact(&p.Addresses[i].FaxNumber)
+		}
+		for i := range p.PracticeLocations {
+			redact(&p.PracticeLocations[i].TelephoneNumber)
+			redact(&p.PracticeLocations[i].FaxNumber)
+		}
+	}
+	if selected[ExportPhones] || selected[ExportOfficial] {
+		redact(&p.Basic.AuthorizedOfficialTelephoneNumber)
+	}
+	if selected[ExportOfficial] {
+		redact(&p.Basic.AuthorizedOfficialFirstName)
+		redact(&p.Basic.AuthorizedOfficialLastName)
+		redact(&p.Basic.AuthorizedOfficialMiddleName)
+		redact(&p.Basic.AuthorizedOfficialTitleOrPosition)
+		redact(&p.Basic.AuthorizedOfficialCredential)
+	}
+	if selected[ExportIndividualName] && p.EnumerationType != "NPI-2" {
+		redact(&p.Basic.FirstName)
+		redact(&p.Basic.LastName)
+		redact(&p.Basic.MiddleName)
+		redact(&p.Basic.Name)
+		redact(&p.Basic.NamePrefix)
+		redact(&p.Basic.NameSuffix)
+		for i := range p.OtherNames {
+			redact(&p.OtherNames[i].FirstName)
+			redact(&p.OtherNames[i].LastName)
+			redact(&p.OtherNames[i].MiddleName)
+			redact(&p.OtherNames[i].Prefix)
+			redact(&p.OtherNames[i].Suffix)
+		}
+	}
+	if selected[ExportStreetAddress] {
+		for i := range p.Addresses {
+			redact(&p.Addresses[i].Address1)
+			redact(&p.Addresses[i].Address2)
+		}
+		for i := range p.PracticeLocations {
+			redact(&p.PracticeLocations[i].Address1)
+			redact(&p.PracticeLocations[i].Address2)
+		}
+	}
+	if selected[ExportIdentifiers] {
+		for i := range p.Identifiers {
+			redact(&p.Identifiers[i].Identifier)
+		}
+	}
+	if selected[ExportLicenses] {
+		for i := range p.Taxonomies {
+			redact(&p.Taxonomies[i].License)
+		}
+	}
+	return p
+}
+
+// hash returns the hex-encoded digest of value.
+func (r Redaction) hash(value string) string {
+	if len(r.Key) > 0 {
+		mac := hmac.New(sha256.New, r.Key)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
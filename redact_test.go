@@ -0,0 +1,67 @@
+package gonpi
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRedaction_Apply tests dropping and hashing field groups.
+func TestRedaction_Apply(t *testing.T) {
+	p := mockProvider()
+	p.Basic.AuthorizedOfficialLastName = "ROE"
+	p.Addresses = []Address{{Address1: "1 MAIN ST", City: "BOSTON", TelephoneNumber: "617-555-0100"}}
+	p.Identifiers = []Identifier{{Identifier: "MC123", State: "MA"}}
+
+	dropped := Redaction{Fields: []ExportField{ExportPhones, ExportOfficial, ExportIndividualName}}.Apply(p)
+	if dropped.Addresses[0].TelephoneNumber != "" || dropped.Basic.AuthorizedOfficialLastName != "" || dropped.Basic.FirstName != "" {
+		t.Errorf("expected fields dropped, got %+v %+v", dropped.Basic, dropped.Addresses)
+	}
+	if dropped.Addresses[0].City != "BOSTON" || dropped.Identifiers[0].Identifier != "MC123" || dropped.Number != p.Number {
+		t.Errorf("expected other fields kept, got %+v", dropped)
+	}
+	if p.Addresses[0].TelephoneNumber != "617-555-0100" || p.Basic.FirstName == "" {
+		t.Error("Apply modified its argument")
+	}
+
+	hashed := Redaction{Fields: []ExportField{ExportPhones, ExportIdentifiers}, Mode: RedactHash}.Apply(p)
+	phone := hashed.Addresses[0].TelephoneNumber
+	if len(phone) != 64 || phone == p.Addresses[0].TelephoneNumber {
+		t.Errorf("expected hex digest, got %q", phone)
+	}
+	if again := (Redaction{Fields: []ExportField{ExportPhones}, Mode: RedactHash}).Apply(p); again.Addresses[0].TelephoneNumber != phone {
+		t.Error("expected hashing to be deterministic")
+	}
+	keyed := Redaction{Fields: []ExportField{ExportPhones}, Mode: RedactHash, Key: []byte("secret")}.Apply(p)
+	if keyed.Addresses[0].TelephoneNumber == phone {
+		t.Error("expected keyed hash to differ")
+	}
+
+	org := p
+	org.EnumerationType = "NPI-2"
+	if got := (Redaction{Fields: []ExportField{ExportIndividualName}}).Apply(org); got.Basic.FirstName != p.Basic.FirstName {
+		t.Error("expected organization records to keep names")
+	}
+}
+
+// TestParseExportFields tests parsing field group lists.
+func TestParseExportFields(t *testing.T) {
+	fields, err := ParseExportFields("phones, official,")
+	if err != nil || len(fields) != 2 || fields[1] != ExportOfficial {
+		t.Errorf("ParseExportFields = %v, %v", fields, err)
+	}
+	if _, err := ParseExportFields("phones,ssn"); !IsValidation(err) || !strings.Contains(err.Error(), "street_address") {
+		t.Errorf("expected ValidationError listing valid fields, got %v", err)
+	}
+}
+
+// TestRedaction_OverlappingGroups tests that a value in two selected groups is hashed
+// once.
+func TestRedaction_OverlappingGroups(t *testing.T) {
+	p := mockProvider()
+	p.Basic.AuthorizedOfficialTelephoneNumber = "6175550100"
+	one := Redaction{Fields: []ExportField{ExportOfficial}, Mode: RedactHash}.Apply(p)
+	both := Redaction{Fields: []ExportField{ExportPhones, ExportOfficial, ExportPhones}, Mode: RedactHash}.Apply(p)
+	if one.Basic.AuthorizedOfficialTelephoneNumber != both.Basic.AuthorizedOfficialTelephoneNumber {
+		t.Error("expected the official phone to be hashed once")
+	}
+}
@@ -0,0 +1,109 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryClass classifies the failure that caused a retry.
+type RetryClass string
+
+// Retry classes.
+const (
+	// RetryServerError is a 5xx response.
+	RetryServerError RetryClass = "server_error"
+
+	// RetryRateLimited is a 429 response.
+	RetryRateLimited RetryClass = "rate_limited"
+
+	// RetryTimeout is a 408 response or a request that timed out.
+	RetryTimeout RetryClass = "timeout"
+
+	// RetryNetwork is any other transient network failure, such as a refused or reset
+	// connection or a truncated response.
+	RetryNetwork RetryClass = "network"
+)
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	// Attempt is the number of the retry about to be made, counting from 1.
+	Attempt int
+
+	// Delay is how long the client waits before the retry.
+	Delay time.Duration
+
+	// Class classifies Err.
+	Class RetryClass
+
+	// Err is the error of the failed attempt. The URL of a transport failure's
+	// *url.Error has its parameters redacted like URL.
+	Err error
+
+	// URL is the request URL, with parameters redacted by WithAuditRedaction replaced.
+	URL string
+
+	// Caller and Tenant come from the call's CallMetadata.
+	Caller string
+	Tenant string
+}
+
+// WithRetryHook calls hook before every retry made by the client and its Transport,
+// so that applications can alert on sustained upstream degradation without scraping
+// logs or spans. hook runs on the request path and should not block.
+//
+// Example usage:
+//
+//	client := NewClient(WithRetryHook(func(e RetryEvent) {
+//	    retries.WithLabelValues(string(e.Class)).Inc()
+//	}))
+func WithRetryHook(hook func(RetryEvent)) ClientOption {
+	return func(c *Client) {
+		c.retryHook = hook
+	}
+}
+
+// notifyRetry calls the retry hook, if any, for retry number attempt of rawURL after err.
+func (c *Client) notifyRetry(ctx context.Context, rawURL string, attempt int, err error) {
+	if c.retryHook == nil {
+		return
+	}
+	event := RetryEvent{
+		Attempt: attempt,
+		Delay:   c.retry.backoff(attempt),
+		Class:   classifyRetry(err),
+		Err:     c.redactError(err),
+		URL:     rawURL,
+	}
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		event.URL, _ = c.redactURL(u)
+	}
+	if md, ok := CallMetadataFromContext(ctx); ok {
+		event.Caller = md.Caller
+		event.Tenant = md.Tenant
+	}
+	c.retryHook(event)
+}
+
+// classifyRetry returns the class of a retryable error.
+func classifyRetry(err error) RetryClass {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return RetryRateLimited
+		case apiErr.StatusCode == http.StatusRequestTimeout:
+			return RetryTimeout
+		case apiErr.StatusCode >= 500:
+			return RetryServerError
+		}
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return RetryTimeout
+	}
+	return RetryNetwork
+}
@@ -0,0 +1,140 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWithRetryHook tests that the hook sees every retry of the client and its
+// Transport, with classification, delay and redacted URL.
+func TestWithRetryHook(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1)-1) % len(statuses)
+		if statuses[n] != http.StatusOK {
+			w.WriteHeader(statuses[n])
+			return
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	var events []RetryEvent
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, BackoffMultiplier: 2}),
+		WithAuditRedaction("first_name"),
+		WithRetryHook(func(e RetryEvent) { events = append(events, e) }),
+	)
+	ctx := ContextWithCallMetadata(context.Background(), CallMetadata{Tenant: "acme"})
+
+	if _, err := client.SearchProviders(ctx, SearchOptions{FirstName: "Jane"}); err != nil {
+		t.Fatalf("SearchProviders: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 retry events, got %+v", events)
+	}
+	for i, want := range []struct {
+		class RetryClass
+		delay time.Duration
+	}{{RetryServerError, time.Millisecond}, {RetryRateLimited, 2 * time.Millisecond}} {
+		e := events[i]
+		if e.Attempt != i+1 || e.Class != want.class || e.Delay != want.delay || e.Tenant != "acme" || e.Err == nil {
+			t.Errorf("event %d = %+v", i, e)
+		}
+		if strings.Contains(e.URL, "Jane") || !strings.HasPrefix(e.URL, server.URL) {
+			t.Errorf("event %d URL = %s", i, e.URL)
+		}
+	}
+
+	events = nil
+	resp, err := (&http.Client{Transport: client.Transport()}).Get(server.URL + "/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(events) != 2 || events[0].Class != RetryServerError || events[1].Attempt != 2 {
+		t.Errorf("transport events = %+v", events)
+	}
+}
+
+// TestWithRetryHook_NotRetried tests that the hook is not called for errors that are
+// not retried or once retries are exhausted.
+func TestWithRetryHook_NotRetried(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	var events []RetryEvent
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{MaxRetries: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}),
+		WithRetryHook(func(e RetryEvent) { events = append(events, e) }),
+	)
+
+	status.Store(http.StatusBadRequest)
+	client.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"})
+	if len(events) != 0 {
+		t.Errorf("expected no events for 400, got %+v", events)
+	}
+
+	status.Store(http.StatusBadGateway)
+	client.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"})
+	if len(events) != 1 {
+		t.Errorf("expected 1 event with MaxRetries 1, got %+v", events)
+	}
+}
+
+// TestWithRetryHook_TransportError tests that transport errors passed to the hook
+// carry the redacted URL.
+func TestWithRetryHook_TransportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	var events []RetryEvent
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{MaxRetries: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}),
+		WithAuditRedaction("first_name"),
+		WithRetryHook(func(e RetryEvent) { events = append(events, e) }),
+	)
+	client.SearchProviders(context.Background(), SearchOptions{FirstName: "Jane"})
+	if len(events) != 1 || events[0].Class != RetryNetwork {
+		t.Fatalf("expected 1 network retry event, got %+v", events)
+	}
+	var urlErr *url.Error
+	err := events[0].Err
+	if strings.Contains(err.Error(), "Jane") || !errors.As(err, &urlErr) || strings.Contains(urlErr.URL, "Jane") {
+		t.Errorf("error not redacted: %v", err)
+	}
+}
+
+// TestClassifyRetry tests the classification of retryable errors.
+func TestClassifyRetry(t *testing.T) {
+	tests := []struct {
+		err  error
+		want RetryClass
+	}{
+		{&APIError{StatusCode: http.StatusInternalServerError}, RetryServerError},
+		{&APIError{StatusCode: http.StatusTooManyRequests}, RetryRateLimited},
+		{&APIError{StatusCode: http.StatusRequestTimeout}, RetryTimeout},
+		{context.DeadlineExceeded, RetryTimeout},
+		{errors.New("connection reset by peer"), RetryNetwork},
+	}
+	for _, tt := range tests {
+		if got := classifyRetry(tt.err); got != tt.want {
+			t.Errorf("classifyRetry(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
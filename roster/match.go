@@ -0,0 +1,106 @@
+package roster
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// Person is one row of a roster: someone to find in the registry.
+type Person struct {
+	// Line is the row's line number in the input, counting the header as 1.
+	Line int
+
+	FirstName  string
+	LastName   string
+	State      string
+	PostalCode string
+	Specialty  string
+}
+
+// Candidate is a provider that may be the person, with the confidence that it is.
+type Candidate struct {
+	gonpi.Provider
+
+	// Confidence is the provider's relevance score as a fraction of the best score
+	// possible for the person's details, from 0 to 1.
+	Confidence float64
+}
+
+// candidateLimit is the number of providers requested per search.
+const candidateLimit = 50
+
+// Match searches the registry for person and returns the candidates ordered from most
+// to least likely, scored with gonpi.ScoreProvider. If the full details find nobody,
+// the search is relaxed by dropping the postal code and then the specialty, since
+// rosters often hold a billing address or a specialty worded differently from the
+// registry's taxonomy; relaxed matches score lower because the dropped details still
+// count towards the best possible score.
+func Match(ctx context.Context, client gonpi.NPIClient, person Person) ([]Candidate, error) {
+	full := gonpi.SearchOptions{
+		EnumerationType:     "NPI-1",
+		FirstName:           person.FirstName,
+		LastName:            person.LastName,
+		State:               person.State,
+		PostalCode:          postalCode(person.PostalCode),
+		TaxonomyDescription: person.Specialty,
+	}
+	best := gonpi.ScoreProvider(ideal(full), full)
+
+	relaxed := []gonpi.SearchOptions{full}
+	if full.PostalCode != "" {
+		opts := relaxed[len(relaxed)-1]
+		opts.PostalCode = ""
+		relaxed = append(relaxed, opts)
+	}
+	if full.TaxonomyDescription != "" {
+		opts := relaxed[len(relaxed)-1]
+		opts.TaxonomyDescription = ""
+		relaxed = append(relaxed, opts)
+	}
+
+	for _, opts := range relaxed {
+		opts.Limit = candidateLimit
+		providers, err := client.SearchProviders(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(providers) == 0 {
+			continue
+		}
+		ranked := gonpi.RankProviders(providers, full)
+		candidates := make([]Candidate, len(ranked))
+		for i, r := range ranked {
+			candidates[i] = Candidate{Provider: r.Provider}
+			if best > 0 {
+				candidates[i].Confidence = r.Score / best
+			}
+		}
+		return candidates, nil
+	}
+	return nil, nil
+}
+
+// ideal returns a provider matching every criterion of opts, whose score is the best
+// possible.
+func ideal(opts gonpi.SearchOptions) gonpi.Provider {
+	return gonpi.Provider{
+		EnumerationType: opts.EnumerationType,
+		Basic:           gonpi.BasicInfo{FirstName: opts.FirstName, LastName: opts.LastName},
+		Addresses: []gonpi.Address{
+			{AddressPurpose: "LOCATION", State: opts.State, PostalCode: opts.PostalCode},
+		},
+		Taxonomies: []gonpi.Taxonomy{{Desc: opts.TaxonomyDescription, Primary: true}},
+	}
+}
+
+// postalCode returns the 5-digit ZIP code of zip, which the registry matches against
+// both 5- and 9-digit codes.
+func postalCode(zip string) string {
+	zip = strings.TrimSpace(zip)
+	if len(zip) > 5 {
+		zip = zip[:5]
+	}
+	return zip
+}
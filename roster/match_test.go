@@ -0,0 +1,120 @@
+package roster
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// fakeRegistry is a gonpi.NPIClient searching a fixed set of providers the way the
+// registry does: name prefixes, state, ZIP prefix and taxonomy substrings.
+type fakeRegistry struct {
+	providers []gonpi.Provider
+	err       error
+
+	mu       sync.Mutex
+	searches []gonpi.SearchOptions
+}
+
+func (f *fakeRegistry) GetProviderByNPI(ctx context.Context, npi string) (*gonpi.Provider, error) {
+	for _, p := range f.providers {
+		if p.Number == npi {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRegistry) GetProvidersByNPIs(ctx context.Context, npis []string, opts ...gonpi.BatchOption) (map[string]*gonpi.Provider, error) {
+	return nil, nil
+}
+
+func (f *fakeRegistry) SearchProviders(ctx context.Context, opts gonpi.SearchOptions) ([]gonpi.Provider, error) {
+	f.mu.Lock()
+	f.searches = append(f.searches, opts)
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	prefix := func(value, query string) bool {
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(query))
+	}
+	var out []gonpi.Provider
+	for _, p := range f.providers {
+		location := p.Addresses[0]
+		if !prefix(p.Basic.LastName, opts.LastName) || !prefix(p.Basic.FirstName, opts.FirstName) ||
+			opts.State != "" && location.State != opts.State || !prefix(location.PostalCode, opts.PostalCode) {
+			continue
+		}
+		if opts.TaxonomyDescription != "" && !strings.Contains(strings.ToLower(p.Taxonomies[0].Desc), strings.ToLower(opts.TaxonomyDescription)) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// person returns an individual provider practicing at state and zip.
+func person(npi, first, last, state, zip, specialty string) gonpi.Provider {
+	return gonpi.Provider{
+		Number:          npi,
+		EnumerationType: "NPI-1",
+		Basic:           gonpi.BasicInfo{FirstName: first, LastName: last},
+		Addresses:       []gonpi.Address{{AddressPurpose: "LOCATION", State: state, PostalCode: zip}},
+		Taxonomies:      []gonpi.Taxonomy{{Desc: specialty, Primary: true}},
+	}
+}
+
+// TestMatch tests scoring, ordering and relaxation of the search.
+func TestMatch(t *testing.T) {
+	registry := &fakeRegistry{providers: []gonpi.Provider{
+		person("1111111112", "JANE", "DOE", "IL", "627011234", "Family Medicine"),
+		person("2222222224", "JANET", "DOE", "IL", "606010000", "Cardiology"),
+	}}
+	ctx := context.Background()
+
+	candidates, err := Match(ctx, registry, Person{FirstName: "Jane", LastName: "Doe", State: "IL", PostalCode: "62701-1234", Specialty: "family"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].Number != "1111111112" || candidates[0].Confidence != 1 {
+		t.Errorf("full match = %+v", candidates)
+	}
+
+	// The ZIP code matches nobody, so the search drops it; JANET scores lower
+	registry.searches = nil
+	candidates, err = Match(ctx, registry, Person{FirstName: "Jane", LastName: "Doe", State: "IL", PostalCode: "99999"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 2 || candidates[0].Number != "1111111112" || candidates[0].Confidence >= 1 ||
+		candidates[1].Confidence >= candidates[0].Confidence {
+		t.Errorf("relaxed match = %+v", candidates)
+	}
+	if len(registry.searches) != 2 || registry.searches[1].PostalCode != "" || registry.searches[0].EnumerationType != "NPI-1" {
+		t.Errorf("searches = %+v", registry.searches)
+	}
+
+	if candidates, err := Match(ctx, registry, Person{LastName: "Nobody"}); err != nil || candidates != nil {
+		t.Errorf("no match = %+v, %v", candidates, err)
+	}
+}
+
+// TestSplitName tests splitting full names in both orders.
+func TestSplitName(t *testing.T) {
+	tests := []struct{ name, first, last string }{
+		{"Jane Doe", "Jane", "Doe"},
+		{"Jane Q. Doe", "Jane", "Doe"},
+		{"Doe, Jane Q", "Jane", "Doe"},
+		{"Doe", "", "Doe"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if first, last := splitName(tt.name); first != tt.first || last != tt.last {
+			t.Errorf("splitName(%q) = %q, %q", tt.name, first, last)
+		}
+	}
+}
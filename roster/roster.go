@@ -0,0 +1,415 @@
+// Package roster resolves rosters of people to NPIs: it reads a CSV of names with
+// optional state, ZIP code and specialty, matches each row against the registry, and
+// writes the rows back out with the matched NPI, its confidence and the alternates
+// considered.
+//
+// Input columns are recognized by name, ignoring case:
+//
+//	first_name, first         given name
+//	last_name, last, surname  family name
+//	name, full_name           "First Last" or "Last, First", if there are no name columns
+//	state                     two-letter state code
+//	zip, zip_code, postal_code
+//	specialty, taxonomy
+//
+// Example usage:
+//
+//	client := gonpi.NewClient(gonpi.WithRateLimit(5, 5))
+//	stats, err := roster.Resolve(ctx, client, in, out, roster.WithConcurrency(4))
+package roster
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// ErrNoNameColumn indicates that the input has neither first and last name columns
+// nor a full name column.
+var ErrNoNameColumn = errors.New("roster has no name column")
+
+// Output columns appended to each input row.
+var outputColumns = []string{"npi", "match_name", "confidence", "match_status", "alternates", "match_error"}
+
+// Match statuses written to the match_status column.
+const (
+	// StatusMatched means the best candidate clears the confidence threshold and is
+	// clearly ahead of the next.
+	StatusMatched = "matched"
+
+	// StatusAmbiguous means the best candidate clears the threshold but another is
+	// within the ambiguity margin of it.
+	StatusAmbiguous = "ambiguous"
+
+	// StatusUnmatched means no candidate clears the threshold.
+	StatusUnmatched = "unmatched"
+
+	// StatusError means the search failed.
+	StatusError = "error"
+)
+
+// Option configures Resolve.
+type Option func(*config)
+
+// config holds the settings applied by Options.
+type config struct {
+	concurrency   int
+	rate          time.Duration
+	threshold     float64
+	margin        float64
+	alternates    int
+	continueOnErr bool
+	progress      func(Stats)
+}
+
+// WithConcurrency sets how many rows are matched at once. Default: 4.
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
+
+// WithRateLimit starts at most perSecond row searches a second, in addition to any
+// rate limit of the client. Each row may take up to three searches. 0 means no limit.
+func WithRateLimit(perSecond float64) Option {
+	return func(c *config) {
+		c.rate = 0
+		if perSecond > 0 {
+			c.rate = time.Duration(float64(time.Second) / perSecond)
+		}
+	}
+}
+
+// WithThreshold sets the confidence the best candidate needs to be reported as the
+// match. Default: 0.6.
+func WithThreshold(confidence float64) Option {
+	return func(c *config) {
+		c.threshold = confidence
+	}
+}
+
+// WithAmbiguityMargin sets how close in confidence the second candidate must be for a
+// match to be ambiguous. Default: 0.05.
+func WithAmbiguityMargin(margin float64) Option {
+	return func(c *config) {
+		c.margin = margin
+	}
+}
+
+// WithAlternates sets how many candidates after the best are written to the
+// alternates column. Default: 3.
+func WithAlternates(n int) Option {
+	return func(c *config) {
+		c.alternates = n
+	}
+}
+
+// WithContinueOnError records failed searches in the match_error column and keeps
+// going instead of stopping at the first.
+func WithContinueOnError() Option {
+	return func(c *config) {
+		c.continueOnErr = true
+	}
+}
+
+// WithProgress calls report after each row is written.
+func WithProgress(report func(Stats)) Option {
+	return func(c *config) {
+		c.progress = report
+	}
+}
+
+// Stats counts the rows processed by Resolve, by status.
+type Stats struct {
+	Rows      int
+	Matched   int
+	Ambiguous int
+	Unmatched int
+	Errors    int
+}
+
+// add counts a row with the given status.
+func (s *Stats) add(status string) {
+	s.Rows++
+	switch status {
+	case StatusMatched:
+		s.Matched++
+	case StatusAmbiguous:
+		s.Ambiguous++
+	case StatusUnmatched:
+		s.Unmatched++
+	case StatusError:
+		s.Errors++
+	}
+}
+
+// row is one input row with its match.
+type row struct {
+	index      int
+	fields     []string
+	person     Person
+	candidates []Candidate
+	err        error
+}
+
+// Resolve reads a roster from r, matches every row with Match, and writes the roster to
+// w in input order with the output columns npi, match_name, confidence, match_status,
+// alternates ("NPI:confidence" separated by ";") and match_error appended. Rows are
+// matched concurrently. Unless WithContinueOnError is given, the first failed search
+// stops the run and is returned with the rows written so far.
+func Resolve(ctx context.Context, client gonpi.NPIClient, r io.Reader, w io.Writer, opts ...Option) (Stats, error) {
+	cfg := config{concurrency: 4, threshold: 0.6, margin: 0.05, alternates: 3}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.concurrency = max(1, cfg.concurrency)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to read header: %w", err)
+	}
+	cols, err := detectColumns(header)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string(nil), header...), outputColumns...)); err != nil {
+		return Stats{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var throttle <-chan time.Time
+	if cfg.rate > 0 {
+		ticker := time.NewTicker(cfg.rate)
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	// Read
+	pending := make(chan row)
+	var readErr error
+	go func() {
+		defer close(pending)
+		for index := 0; ; index++ {
+			fields, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr = fmt.Errorf("failed to parse roster: %w", err)
+				cancel()
+				return
+			}
+			line, _ := reader.FieldPos(0)
+			select {
+			case pending <- row{index: index, fields: fields, person: cols.person(fields, line)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Match
+	matched := make(chan row)
+	var workers sync.WaitGroup
+	for range cfg.concurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for rw := range pending {
+				if throttle != nil && rw.person.LastName != "" {
+					select {
+					case <-throttle:
+					case <-ctx.Done():
+					}
+				}
+				switch {
+				case ctx.Err() != nil:
+					rw.err = ctx.Err()
+				case rw.person.LastName != "":
+					// Rows without a name are written unmatched
+					rw.candidates, rw.err = Match(ctx, client, rw.person)
+				}
+				matched <- rw
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(matched)
+	}()
+
+	// Write in input order
+	var stats Stats
+	var failure error
+	waiting := make(map[int]row)
+	next := 0
+	for rw := range matched {
+		if failure != nil {
+			continue
+		}
+		waiting[rw.index] = rw
+		for {
+			rw, ok := waiting[next]
+			if !ok {
+				break
+			}
+			delete(waiting, next)
+			next++
+			if rw.err != nil && !cfg.continueOnErr {
+				failure = fmt.Errorf("line %d: %w", rw.person.Line, rw.err)
+				cancel()
+				break
+			}
+			status, out := cfg.output(rw)
+			if err := writer.Write(out); err != nil {
+				failure = err
+				cancel()
+				break
+			}
+			stats.add(status)
+			if cfg.progress != nil {
+				cfg.progress(stats)
+			}
+		}
+	}
+	writer.Flush()
+	if failure == nil {
+		failure = readErr
+	}
+	if failure == nil {
+		failure = writer.Error()
+	}
+	return stats, failure
+}
+
+// output returns the status of rw and the output row.
+func (cfg config) output(rw row) (string, []string) {
+	var npi, name, confidence, alternates, message string
+	status := StatusUnmatched
+	switch {
+	case rw.err != nil:
+		status, message = StatusError, rw.err.Error()
+	case len(rw.candidates) > 0 && rw.candidates[0].Confidence >= cfg.threshold:
+		best := rw.candidates[0]
+		npi, name, confidence = best.Number, best.FullName(), formatConfidence(best.Confidence)
+		status = StatusMatched
+		if len(rw.candidates) > 1 && best.Confidence-rw.candidates[1].Confidence < cfg.margin {
+			status = StatusAmbiguous
+		}
+	}
+
+	var alts []string
+	start := 1
+	if npi == "" {
+		// Without a match every candidate is an alternate
+		start = 0
+	}
+	for _, c := range rw.candidates[min(start, len(rw.candidates)):] {
+		if len(alts) == cfg.alternates {
+			break
+		}
+		alts = append(alts, c.Number+":"+formatConfidence(c.Confidence))
+	}
+	alternates = strings.Join(alts, ";")
+
+	out := append(append([]string(nil), rw.fields...), npi, name, confidence, status, alternates, message)
+	return status, out
+}
+
+// formatConfidence formats a confidence with two decimals.
+func formatConfidence(confidence float64) string {
+	return strconv.FormatFloat(confidence, 'f', 2, 64)
+}
+
+// columns are the input column positions, -1 where absent.
+type columns struct {
+	first, last, full, state, postal, specialty int
+}
+
+// detectColumns finds the input columns in header.
+func detectColumns(header []string) (columns, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+	find := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	cols := columns{
+		first:     find("first_name", "first"),
+		last:      find("last_name", "last", "surname"),
+		full:      find("name", "full_name"),
+		state:     find("state"),
+		postal:    find("zip", "zip_code", "postal_code"),
+		specialty: find("specialty", "taxonomy"),
+	}
+	if cols.last < 0 && cols.full < 0 {
+		return cols, ErrNoNameColumn
+	}
+	return cols, nil
+}
+
+// person returns the Person described by fields.
+func (cols columns) person(fields []string, line int) Person {
+	get := func(i int) string {
+		if i < 0 || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+	p := Person{
+		Line:       line,
+		FirstName:  get(cols.first),
+		LastName:   get(cols.last),
+		State:      strings.ToUpper(get(cols.state)),
+		PostalCode: get(cols.postal),
+		Specialty:  get(cols.specialty),
+	}
+	if p.LastName == "" {
+		p.FirstName, p.LastName = splitName(get(cols.full))
+	}
+	return p
+}
+
+// splitName splits a full name written "First Last" or "Last, First". Middle names and
+// initials are dropped.
+func splitName(name string) (first, last string) {
+	if last, rest, ok := strings.Cut(name, ","); ok {
+		fields := strings.Fields(rest)
+		if len(fields) > 0 {
+			first = fields[0]
+		}
+		return first, strings.TrimSpace(last)
+	}
+	fields := strings.Fields(name)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", fields[0]
+	}
+	return fields[0], fields[len(fields)-1]
+}
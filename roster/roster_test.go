@@ -0,0 +1,88 @@
+package roster
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestResolve tests the output columns, statuses and input order across workers.
+func TestResolve(t *testing.T) {
+	registry := &fakeRegistry{providers: []gonpi.Provider{
+		person("1111111112", "JANE", "DOE", "IL", "62701", "Family Medicine"),
+		person("2222222224", "JOHN", "SMITH", "NY", "10001", "Cardiology"),
+		person("3333333336", "JOHN", "SMITH", "NY", "10001", "Cardiology"),
+		person("4444444448", "ANN", "LEE", "CA", "90210", "Pediatrics"),
+	}}
+	in := strings.Join([]string{
+		"id,Name,State,ZIP,Specialty",
+		"a,Jane Doe,IL,62701,Family Medicine",
+		"b,\"Smith, John\",NY,10001,Cardiology",
+		"c,Nobody Here,TX,,",
+		"d,Ann Lee,WA,,",
+		"e,,,,",
+	}, "\n")
+
+	var out bytes.Buffer
+	var progress []Stats
+	stats, err := Resolve(context.Background(), registry, strings.NewReader(in), &out,
+		WithConcurrency(3), WithAlternates(1), WithProgress(func(s Stats) { progress = append(progress, s) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Stats{Rows: 5, Matched: 1, Ambiguous: 1, Unmatched: 3}); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+	if len(progress) != 5 || progress[4] != stats {
+		t.Errorf("progress = %+v", progress)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "Name", "State", "ZIP", "Specialty", "npi", "match_name", "confidence", "match_status", "alternates", "match_error"},
+		{"a", "Jane Doe", "IL", "62701", "Family Medicine", "1111111112", "JANE DOE", "1.00", StatusMatched, "", ""},
+		{"b", "Smith, John", "NY", "10001", "Cardiology", "2222222224", "JOHN SMITH", "1.00", StatusAmbiguous, "3333333336:1.00", ""},
+		{"c", "Nobody Here", "TX", "", "", "", "", "", StatusUnmatched, "", ""},
+		{"d", "Ann Lee", "WA", "", "", "", "", "", StatusUnmatched, "", ""},
+		{"e", "", "", "", "", "", "", "", StatusUnmatched, "", ""},
+	}
+	if fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Errorf("output:\n%v\nwant:\n%v", records, want)
+	}
+}
+
+// TestResolve_Errors tests stopping at and recording failed searches, and rejecting
+// rosters without names.
+func TestResolve_Errors(t *testing.T) {
+	boom := errors.New("registry unavailable")
+	registry := &fakeRegistry{err: boom}
+	in := "last_name,first_name\nDoe,Jane\nRoe,Rick\n"
+
+	var out bytes.Buffer
+	stats, err := Resolve(context.Background(), registry, strings.NewReader(in), &out)
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "line 2") || stats.Rows != 0 {
+		t.Errorf("Resolve() = %+v, %v", stats, err)
+	}
+
+	out.Reset()
+	stats, err = Resolve(context.Background(), registry, strings.NewReader(in), &out, WithContinueOnError())
+	if err != nil || stats.Errors != 2 {
+		t.Errorf("Resolve() = %+v, %v", stats, err)
+	}
+	if !strings.Contains(out.String(), ","+StatusError+",,registry unavailable") {
+		t.Errorf("output = %s", out.String())
+	}
+
+	if _, err := Resolve(context.Background(), registry, strings.NewReader("state,zip\nIL,62701\n"), &out); !errors.Is(err, ErrNoNameColumn) {
+		t.Errorf("expected ErrNoNameColumn, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package gonpi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RUCACategory is a coarse rural/urban label derived from a primary RUCA code.
+type RUCACategory string
+
+// RUCA categories, following the USDA ERS groupings of primary RUCA codes.
+const (
+	// RUCAMetropolitan covers primary codes 1-3 (metropolitan core, high and low commuting).
+	RUCAMetropolitan RUCACategory = "metropolitan"
+
+	// RUCAMicropolitan covers primary codes 4-6.
+	RUCAMicropolitan RUCACategory = "micropolitan"
+
+	// RUCASmallTown covers primary codes 7-9.
+	RUCASmallTown RUCACategory = "small_town"
+
+	// RUCARural covers primary code 10.
+	RUCARural RUCACategory = "rural"
+)
+
+// RUCACategoryForCode returns the category for a primary RUCA code (1-10).
+// Secondary codes such as 4.1 are truncated to their primary code.
+func RUCACategoryForCode(code float64) (RUCACategory, bool) {
+	switch primary := int(code); {
+	case primary >= 1 && primary <= 3:
+		return RUCAMetropolitan, true
+	case primary >= 4 && primary <= 6:
+		return RUCAMicropolitan, true
+	case primary >= 7 && primary <= 9:
+		return RUCASmallTown, true
+	case primary == 10:
+		return RUCARural, true
+	}
+	return "", false
+}
+
+// RUCAClassification labels a single practice location.
+type RUCAClassification struct {
+	Address1   string       `json:"address_1"`
+	PostalCode string       `json:"postal_code"`
+	Code       float64      `json:"ruca_code"`
+	Category   RUCACategory `json:"category"`
+}
+
+// RUCACodes is an in-memory ZIP code to Rural-Urban Commuting Area code table.
+// It is safe for concurrent use.
+type RUCACodes struct {
+	mu   sync.RWMutex
+	data map[string]float64
+}
+
+// NewRUCACodes creates an empty RUCA table.
+func NewRUCACodes() *RUCACodes {
+	return &RUCACodes{data: make(map[string]float64)}
+}
+
+// Set stores the RUCA code for a ZIP code.
+func (r *RUCACodes) Set(zip string, code float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data[zip5(zip)] = code
+}
+
+// Lookup returns the RUCA code for a ZIP code. Only the first five digits of zip are used.
+func (r *RUCACodes) Lookup(zip string) (float64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	code, ok := r.data[zip5(zip)]
+	return code, ok
+}
+
+// Len returns the number of ZIP codes in the table.
+func (r *RUCACodes) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.data)
+}
+
+// LoadRUCACodes reads the USDA ERS ZIP code RUCA file exported as CSV (ZIP_CODE and
+// RUCA1 or RUCA2 columns), or any delimited file with zip and ruca columns.
+// The secondary code (RUCA2) is preferred when both are present.
+func LoadRUCACodes(r io.Reader) (*RUCACodes, error) {
+	reader, cols, err := newHeaderReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zipCol := firstColumn(cols, "zip_code", "zip", "zipcode", "zcta")
+	codeCol := firstColumn(cols, "ruca2", "ruca1", "ruca", "ruca_code")
+	if zipCol < 0 || codeCol < 0 {
+		return nil, errors.New("missing zip or RUCA code column")
+	}
+
+	table := NewRUCACodes()
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRowLength(reader, row, zipCol, codeCol); err != nil {
+			return nil, err
+		}
+		zip := zip5(row[zipCol])
+		code, err := strconv.ParseFloat(strings.TrimSpace(row[codeCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RUCA code for %s: %w", zip, err)
+		}
+		// Code 99 marks ZIP codes without population; leave them unclassified
+		if _, ok := RUCACategoryForCode(code); !ok {
+			continue
+		}
+		table.data[zip] = code
+	}
+	return table, nil
+}
+
+// Classify labels each of the provider's practice locations as metropolitan,
+// micropolitan, small town or rural. Locations whose ZIP code is not in the table
+// are omitted.
+func (r *RUCACodes) Classify(provider Provider) []RUCAClassification {
+	var results []RUCAClassification
+	for _, addr := range practiceAddresses(&provider) {
+		code, ok := r.Lookup(addr.PostalCode)
+		if !ok {
+			continue
+		}
+		category, _ := RUCACategoryForCode(code)
+		results = append(results, RUCAClassification{
+			Address1:   addr.Address1,
+			PostalCode: addr.PostalCode,
+			Code:       code,
+			Category:   category,
+		})
+	}
+	return results
+}
@@ -0,0 +1,72 @@
+package gonpi
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRUCACategoryForCode tests RUCA code grouping.
+func TestRUCACategoryForCode(t *testing.T) {
+	tests := []struct {
+		code float64
+		want RUCACategory
+		ok   bool
+	}{
+		{1, RUCAMetropolitan, true},
+		{3, RUCAMetropolitan, true},
+		{4.1, RUCAMicropolitan, true},
+		{6, RUCAMicropolitan, true},
+		{7.2, RUCASmallTown, true},
+		{10.3, RUCARural, true},
+		{99, "", false},
+		{0, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := RUCACategoryForCode(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RUCACategoryForCode(%v) = %v, %v; want %v, %v", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestRUCACodes_Classify tests loading a RUCA file and classifying practice locations.
+func TestRUCACodes_Classify(t *testing.T) {
+	data := "ZIP_CODE,STATE,ZIP_TYPE,RUCA1,RUCA2\n" +
+		"12345,CA,Zip Code Area,1,1.0\n" +
+		"54321,CA,Zip Code Area,10,10.5\n" +
+		"00000,CA,Zip Code Area,99,99\n"
+
+	table, err := LoadRUCACodes(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Len() != 2 {
+		t.Errorf("expected 2 classified ZIP codes, got %d", table.Len())
+	}
+
+	provider := mockProvider()
+	provider.PracticeLocations = []PracticeLocation{
+		{Address1: "1 FARM RD", PostalCode: "543219999"},
+		{Address1: "2 EMPTY RD", PostalCode: "00000"},
+	}
+
+	results := table.Classify(provider)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 classifications, got %d: %+v", len(results), results)
+	}
+	if results[0].Category != RUCAMetropolitan {
+		t.Errorf("expected metropolitan, got %s", results[0].Category)
+	}
+	if results[1].Category != RUCARural || results[1].Code != 10.5 {
+		t.Errorf("expected rural 10.5, got %+v", results[1])
+	}
+}
+
+// TestLoadRUCACodes_ShortRow tests rejection of a row missing its RUCA code.
+func TestLoadRUCACodes_ShortRow(t *testing.T) {
+	data := "ZIP_CODE,STATE,ZIP_TYPE,RUCA1,RUCA2\n12345,CA\n"
+	if _, err := LoadRUCACodes(strings.NewReader(data)); err == nil {
+		t.Error("expected error for a short row")
+	}
+}
@@ -0,0 +1,245 @@
+package gonpi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Task is a unit of scheduled work, such as a Watcher poll or a registry refresh.
+// The context is cancelled when the scheduler stops.
+type Task func(ctx context.Context) error
+
+// SchedulerOption configures a Scheduler.
+type SchedulerOption func(*Scheduler)
+
+// WithSchedulerErrorHandler sets a function called with the task name and error
+// whenever a scheduled task fails. Failures never stop the schedule.
+func WithSchedulerErrorHandler(handler func(name string, err error)) SchedulerOption {
+	return func(s *Scheduler) {
+		s.onError = handler
+	}
+}
+
+// WithSchedulerPriority sets the request priority of scheduled tasks, for tasks whose
+// context does not set one. Default: PriorityBackground, so scheduled work never
+// delays interactive lookups sharing the client's rate limit.
+func WithSchedulerPriority(p Priority) SchedulerOption {
+	return func(s *Scheduler) {
+		s.priority = p
+	}
+}
+
+// Scheduler runs tasks on cron or interval schedules. Runs of the same task never
+// overlap: if a run takes longer than the gap to its next slot, that slot is skipped.
+// Tasks make their requests at PriorityBackground unless WithSchedulerPriority or
+// their context says otherwise.
+//
+// Example usage:
+//
+//	scheduler := client.NewScheduler()
+//	daily, _ := gonpi.ParseCron("0 3 * * *")
+//	scheduler.Add("watch", daily, func(ctx context.Context) error {
+//	    _, err := watcher.Poll(ctx)
+//	    return err
+//	})
+//	scheduler.Start(ctx)
+//	defer client.Close()
+type Scheduler struct {
+	client   *Client
+	onError  func(name string, err error)
+	priority Priority
+
+	mu      sync.Mutex
+	entries []scheduleEntry
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type scheduleEntry struct {
+	name     string
+	schedule Schedule
+	task     Task
+	state    *taskState
+}
+
+// TaskStatus describes a scheduled task, as returned by Scheduler.Status.
+type TaskStatus struct {
+	Name         string        `json:"name"`
+	Running      bool          `json:"running"`
+	Runs         int           `json:"runs"`
+	Failures     int           `json:"failures"`
+	LastRun      time.Time     `json:"last_run,omitzero"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+
+	// NextRun is zero if the scheduler is stopped or the schedule has ended.
+	NextRun time.Time `json:"next_run,omitzero"`
+}
+
+// taskState records the runs of one task.
+type taskState struct {
+	mu     sync.Mutex
+	status TaskStatus
+}
+
+// NewScheduler creates a Scheduler whose tasks are stopped by Client.Close.
+func (c *Client) NewScheduler(opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{client: c, priority: PriorityBackground}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	c.background.mu.Lock()
+	c.background.schedulers = append(c.background.schedulers, s)
+	c.background.mu.Unlock()
+	return s
+}
+
+// Add registers task under name. Tasks added after Start begin immediately.
+func (s *Scheduler) Add(name string, schedule Schedule, task Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := scheduleEntry{name: name, schedule: schedule, task: task, state: &taskState{status: TaskStatus{Name: name}}}
+	s.entries = append(s.entries, entry)
+	if s.cancel != nil {
+		s.launch(s.ctx, entry)
+	}
+}
+
+// Start runs every registered task on its schedule until ctx is cancelled or Stop is
+// called. Calling Start on a running scheduler has no effect.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.ctx = ctx
+	for _, entry := range s.entries {
+		s.launch(ctx, entry)
+	}
+}
+
+// Stop cancels all tasks and waits for in-flight runs to return. The scheduler can be
+// started again afterwards.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.ctx = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// Status returns the status of every registered task, in the order they were added.
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]TaskStatus, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entry.state.mu.Lock()
+		tasks = append(tasks, entry.state.status)
+		entry.state.mu.Unlock()
+	}
+	return tasks
+}
+
+// launch starts the run loop for entry. s.mu must be held.
+func (s *Scheduler) launch(ctx context.Context, entry scheduleEntry) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop(ctx, entry)
+	}()
+}
+
+func (s *Scheduler) loop(ctx context.Context, entry scheduleEntry) {
+	for {
+		next := entry.schedule.Next(time.Now())
+		entry.state.mu.Lock()
+		entry.state.status.NextRun = next
+		entry.state.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			if err := s.run(ctx, entry); err != nil && s.onError != nil {
+				s.onError(entry.name, err)
+			}
+			continue
+		case <-ctx.Done():
+		case <-s.client.background.draining:
+			// Client.Shutdown lets running tasks finish but starts no new runs
+		}
+		timer.Stop()
+		entry.state.mu.Lock()
+		entry.state.status.NextRun = time.Time{}
+		entry.state.mu.Unlock()
+		return
+	}
+}
+
+// run executes one scheduled run of entry inside its own span. A panicking task
+// fails the run with a *PanicError and is scheduled again as usual.
+func (s *Scheduler) run(ctx context.Context, entry scheduleEntry) error {
+	ctx, span := s.client.tracer.Start(ctx, "ScheduledTask",
+		trace.WithAttributes(s.client.traceAttrs(
+			attribute.String("task", entry.name),
+		)...),
+	)
+	defer span.End()
+	if md, _ := CallMetadataFromContext(ctx); md.Priority == PriorityNormal {
+		ctx = ContextWithPriority(ctx, s.priority)
+	}
+
+	// Admit the run, so that its calls finish while the client drains
+	ctx, done, err := s.client.background.enter(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	start := time.Now()
+	entry.state.mu.Lock()
+	entry.state.status.Running = true
+	entry.state.status.LastRun = start
+	entry.state.mu.Unlock()
+
+	err = safeCall(func() error { return entry.task(ctx) })
+
+	entry.state.mu.Lock()
+	status := &entry.state.status
+	status.Running = false
+	status.Runs++
+	status.LastDuration = time.Since(start)
+	status.LastError = ""
+	if err != nil {
+		status.Failures++
+		status.LastError = err.Error()
+	}
+	entry.state.mu.Unlock()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "scheduled task failed")
+		return err
+	}
+	return nil
+}
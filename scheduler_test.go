@@ -0,0 +1,136 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestScheduler_RunsAndStopsOnClose tests that tasks run on schedule and Close stops them.
+func TestScheduler_RunsAndStopsOnClose(t *testing.T) {
+	client := NewClient()
+
+	var runs atomic.Int32
+	var failures atomic.Int32
+	scheduler := client.NewScheduler(WithSchedulerErrorHandler(func(name string, err error) {
+		if name == "failing" {
+			failures.Add(1)
+		}
+	}))
+	scheduler.Add("counter", Every(5*time.Millisecond, 0), func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	scheduler.Add("failing", Every(5*time.Millisecond, 0), func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+	scheduler.Start(context.Background())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for (runs.Load() < 3 || failures.Load() < 1) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if runs.Load() < 3 {
+		t.Fatalf("expected at least 3 runs, got %d", runs.Load())
+	}
+	if failures.Load() < 1 {
+		t.Error("expected error handler to be called")
+	}
+
+	client.Close()
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != stopped {
+		t.Error("task kept running after Close")
+	}
+}
+
+// TestScheduler_StopWaitsForTask tests that Stop cancels the task context and waits for it.
+func TestScheduler_StopWaitsForTask(t *testing.T) {
+	client := NewClient()
+	scheduler := client.NewScheduler()
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	scheduler.Add("slow", Every(time.Millisecond, 0), func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Done()
+		finished.Store(true)
+		return ctx.Err()
+	})
+	scheduler.Start(context.Background())
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("task never started")
+	}
+	scheduler.Stop()
+	if !finished.Load() {
+		t.Error("Stop returned before in-flight task finished")
+	}
+}
+
+// TestScheduler_PanickingTask tests that a panicking task is reported and keeps its schedule.
+func TestScheduler_PanickingTask(t *testing.T) {
+	client := NewClient()
+	defer client.Close()
+
+	var panics atomic.Int32
+	scheduler := client.NewScheduler(WithSchedulerErrorHandler(func(name string, err error) {
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			panics.Add(1)
+		}
+	}))
+	scheduler.Add("panicking", Every(time.Millisecond, 0), func(ctx context.Context) error {
+		panic("boom")
+	})
+	scheduler.Start(context.Background())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for panics.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if panics.Load() < 2 {
+		t.Errorf("expected repeated PanicErrors, got %d", panics.Load())
+	}
+}
+
+// TestScheduler_Priority tests that tasks run at background priority unless configured
+// or their context says otherwise.
+func TestScheduler_Priority(t *testing.T) {
+	client := NewClient()
+	defer client.Close()
+
+	for _, tt := range []struct {
+		opts []SchedulerOption
+		ctx  context.Context
+		want Priority
+	}{
+		{nil, context.Background(), PriorityBackground},
+		{[]SchedulerOption{WithSchedulerPriority(PriorityInteractive)}, context.Background(), PriorityInteractive},
+		{nil, ContextWithPriority(context.Background(), PriorityHigh), PriorityHigh},
+	} {
+		got := make(chan Priority, 1)
+		scheduler := client.NewScheduler(tt.opts...)
+		scheduler.Add("task", Every(time.Millisecond, 0), func(ctx context.Context) error {
+			md, _ := CallMetadataFromContext(ctx)
+			select {
+			case got <- md.Priority:
+			default:
+			}
+			return nil
+		})
+		scheduler.Start(tt.ctx)
+		if p := <-got; p != tt.want {
+			t.Errorf("priority = %s, want %s", p, tt.want)
+		}
+		scheduler.Stop()
+	}
+}
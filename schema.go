@@ -0,0 +1,138 @@
+package gonpi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//go:generate go run ./internal/cmd/genschema -dir schema
+
+// RegistrySchema is the JSON Schema (draft 2020-12) describing APIResponse and, under
+// "#/$defs/Provider", the Provider type, as produced by GenerateJSONSchema.
+// It lets non-Go consumers of exported payloads validate their shape.
+//
+//go:embed schema/registry.schema.json
+var RegistrySchema []byte
+
+// SchemaID is the $id of RegistrySchema.
+const SchemaID = "https://github.com/sdsvn/gonpi/schema/registry.schema.json"
+
+// EventSchema is the JSON Schema describing ChangeEvent, the payload delivered to
+// watch publishers, as produced by GenerateEventSchema.
+//
+//go:embed schema/event.schema.json
+var EventSchema []byte
+
+// EventSchemaID is the $id of EventSchema.
+const EventSchemaID = "https://github.com/sdsvn/gonpi/schema/event.schema.json"
+
+// GenerateJSONSchema returns a JSON Schema document for the Go type of v, with every
+// named struct type placed under "$defs". Objects disallow additional properties so
+// that payloads carrying fields unknown to gonpi fail validation.
+func GenerateJSONSchema(v any) ([]byte, error) {
+	return generateSchema(v, SchemaID)
+}
+
+// GenerateEventSchema returns the JSON Schema document for ChangeEvent.
+func GenerateEventSchema() ([]byte, error) {
+	return generateSchema(ChangeEvent{}, EventSchemaID)
+}
+
+func generateSchema(v any, id string) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema root must be a struct, got %v", t)
+	}
+
+	g := &schemaGenerator{defs: make(map[string]any)}
+	root := g.schemaFor(t).(map[string]any)
+	doc := map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     id,
+		"title":   t.Name(),
+		"$ref":    root["$ref"],
+		"$defs":   g.defs,
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// schemaGenerator accumulates definitions for named struct types.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+var (
+	flexIntType = reflect.TypeOf(FlexInt(0))
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// schemaFor returns the schema for t, registering struct definitions as a side effect.
+func (g *schemaGenerator) schemaFor(t reflect.Type) any {
+	if t == flexIntType {
+		return map[string]any{
+			"description": "Integer that the API may encode as a JSON string.",
+			"type":        []string{"integer", "string"},
+		}
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": []string{"array", "null"}, "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			// Reserve the name first so recursive types terminate
+			g.defs[name] = nil
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+	return map[string]any{}
+}
+
+// structSchema returns the object schema for a struct's JSON-visible fields.
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schemaFor(field.Type)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,76 @@
+package gonpi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRegistrySchema_UpToDate tests that the embedded schema matches the Go types.
+// Run "go generate" to refresh it after changing types.
+func TestRegistrySchema_UpToDate(t *testing.T) {
+	generated, err := GenerateJSONSchema(APIResponse{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(RegistrySchema), generated) {
+		t.Error("embedded schema is stale; run go generate")
+	}
+}
+
+// TestGenerateJSONSchema tests the generated schema structure.
+func TestGenerateJSONSchema(t *testing.T) {
+	var doc struct {
+		Ref  string                     `json:"$ref"`
+		Defs map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(RegistrySchema, &doc); err != nil {
+		t.Fatalf("invalid schema JSON: %v", err)
+	}
+	if doc.Ref != "#/$defs/APIResponse" {
+		t.Errorf("unexpected root ref: %s", doc.Ref)
+	}
+	for _, name := range []string{"Provider", "BasicInfo", "Address", "Taxonomy", "Extensions"} {
+		if _, ok := doc.Defs[name]; !ok {
+			t.Errorf("missing definition %s", name)
+		}
+	}
+
+	var provider struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	json.Unmarshal(doc.Defs["Provider"], &provider)
+	if _, ok := provider.Properties["created_epoch"]["type"].([]any); !ok {
+		t.Errorf("expected FlexInt to accept integer or string, got %v", provider.Properties["created_epoch"])
+	}
+
+	if _, err := GenerateJSONSchema("not a struct"); err == nil {
+		t.Error("expected error for non-struct root")
+	}
+}
+
+// TestStrictDecoding tests that unknown response fields are rejected in strict mode.
+func TestStrictDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result_count":1,"results":[{"number":"1234567890","new_field":"x"}]}`))
+	}))
+	defer server.Close()
+
+	lenient := NewClient(WithBaseURL(server.URL))
+	if _, err := lenient.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"}); err != nil {
+		t.Fatalf("unexpected error in lenient mode: %v", err)
+	}
+
+	strict := NewClient(WithBaseURL(server.URL), WithStrictDecoding())
+	_, err := strict.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"})
+	if !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected ErrSchemaMismatch, got %v", err)
+	}
+	if IsRetryable(err) {
+		t.Error("schema mismatches should not be retried")
+	}
+}
@@ -0,0 +1,306 @@
+package gonpi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SchemaWarningKind classifies a difference between an API response and the Go types.
+type SchemaWarningKind string
+
+// Schema warning kinds.
+const (
+	// SchemaUnknownField is a response field with no corresponding Go field. Its value
+	// is dropped.
+	SchemaUnknownField SchemaWarningKind = "unknown_field"
+
+	// SchemaTypeMismatch is a field whose JSON type cannot be decoded into its Go
+	// field, which is left at its zero value.
+	SchemaTypeMismatch SchemaWarningKind = "type_mismatch"
+)
+
+// SchemaWarning describes one kind of drift seen in API responses, aggregated across
+// requests.
+type SchemaWarning struct {
+	// Kind classifies the drift.
+	Kind SchemaWarningKind `json:"kind"`
+
+	// Path is the field's dotted JSON path, with "[]" for array elements, such as
+	// "results[].basic.nickname".
+	Path string `json:"path"`
+
+	// Expected is the JSON type the Go field accepts, and Got the type received. Both
+	// are empty for unknown fields.
+	Expected string `json:"expected,omitempty"`
+	Got      string `json:"got,omitempty"`
+
+	// Count is the number of occurrences seen, and FirstSeen and LastSeen when the
+	// first and latest were seen.
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// WithSchemaWarnings compares every API response with the Go types and records
+// unknown fields and type mismatches, so that changes to the registry's format are
+// noticed before they become silent data loss. Warnings are returned by
+// Client.SchemaWarnings; report, if not nil, is also called the first time each
+// distinct warning is seen. Type mismatches no longer fail the request: the field is
+// left at its zero value and the rest of the response is kept. Detection decodes each
+// response twice, and is ignored with WithStrictDecoding, which rejects drift instead.
+//
+// Example usage:
+//
+//	client := gonpi.NewClient(gonpi.WithSchemaWarnings(func(w gonpi.SchemaWarning) {
+//	    slog.Warn("registry schema drift", "kind", w.Kind, "path", w.Path)
+//	}))
+func WithSchemaWarnings(report func(SchemaWarning)) ClientOption {
+	return func(c *Client) {
+		c.drift = &schemaDrift{report: report, warnings: make(map[schemaWarningKey]*SchemaWarning)}
+	}
+}
+
+// SchemaWarnings returns the drift recorded since the client was created, sorted by
+// path and kind. It is empty unless WithSchemaWarnings is set.
+func (c *Client) SchemaWarnings() []SchemaWarning {
+	if c.drift == nil {
+		return nil
+	}
+	return c.drift.list()
+}
+
+// schemaDrift aggregates schema warnings. It is shared with derived clients.
+type schemaDrift struct {
+	report func(SchemaWarning)
+
+	mu       sync.Mutex
+	warnings map[schemaWarningKey]*SchemaWarning
+}
+
+// schemaWarningKey identifies a distinct warning.
+type schemaWarningKey struct {
+	kind           SchemaWarningKind
+	path, expected string
+	got            string
+}
+
+// check compares the JSON document data with the Go type of result.
+func (d *schemaDrift) check(data []byte, result any) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if decoder.Decode(&doc) != nil {
+		return
+	}
+	var found []SchemaWarning
+	walkSchema(doc, reflect.TypeOf(result), "", &found)
+	if len(found) == 0 {
+		return
+	}
+
+	now := time.Now()
+	var first []SchemaWarning
+	d.mu.Lock()
+	for _, w := range found {
+		key := schemaWarningKey{w.Kind, w.Path, w.Expected, w.Got}
+		seen, ok := d.warnings[key]
+		if !ok {
+			seen = &w
+			seen.FirstSeen = now
+			d.warnings[key] = seen
+		}
+		seen.Count++
+		seen.LastSeen = now
+		if !ok {
+			first = append(first, *seen)
+		}
+	}
+	d.mu.Unlock()
+
+	if d.report != nil {
+		for _, w := range first {
+			d.report(w)
+		}
+	}
+}
+
+// list returns a sorted copy of the warnings.
+func (d *schemaDrift) list() []SchemaWarning {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	warnings := make([]SchemaWarning, 0, len(d.warnings))
+	for _, w := range d.warnings {
+		warnings = append(warnings, *w)
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Path != warnings[j].Path {
+			return warnings[i].Path < warnings[j].Path
+		}
+		return warnings[i].Kind < warnings[j].Kind
+	})
+	return warnings
+}
+
+// decodeChecked decodes body into result, recording drift. Type mismatches are
+// recorded instead of failing the request.
+func (c *Client) decodeChecked(body io.Reader, result any, span trace.Span) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to read response")
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(data, result); err != nil && !errors.As(err, &typeErr) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to decode response")
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	c.drift.check(data, result)
+	return nil
+}
+
+// walkSchema appends the differences between value and Go type t at path to found.
+// Differences are recorded once per response for each path.
+func walkSchema(value any, t reflect.Type, path string, found *[]SchemaWarning) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if value == nil || t.Kind() == reflect.Interface {
+		return
+	}
+	got := jsonKind(value)
+	expected := expectedKinds(t)
+	if !strings.Contains(expected, got) {
+		addWarning(found, SchemaWarning{Kind: SchemaTypeMismatch, Path: path, Expected: expected, Got: got})
+		return
+	}
+
+	switch v := value.(type) {
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for _, elem := range v {
+			walkSchema(elem, t.Elem(), path+"[]", found)
+		}
+	case map[string]any:
+		if t.Kind() == reflect.Map {
+			for key, elem := range v {
+				walkSchema(elem, t.Elem(), joinPath(path, key), found)
+			}
+			return
+		}
+		for key, elem := range v {
+			field, ok := jsonField(t, key)
+			if !ok {
+				addWarning(found, SchemaWarning{Kind: SchemaUnknownField, Path: joinPath(path, key)})
+				continue
+			}
+			walkSchema(elem, field.Type, joinPath(path, key), found)
+		}
+	}
+}
+
+// addWarning appends w unless found already has it.
+func addWarning(found *[]SchemaWarning, w SchemaWarning) {
+	for _, seen := range *found {
+		if seen == w {
+			return
+		}
+	}
+	*found = append(*found, w)
+}
+
+// joinPath appends key to path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonKind returns the JSON type of a value decoded with UseNumber.
+func jsonKind(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "null"
+}
+
+// expectedKinds returns the JSON types t decodes from, separated by "|".
+func expectedKinds(t reflect.Type) string {
+	if t == flexIntType {
+		return "number|string"
+	}
+	if t == timeType {
+		return "string"
+	}
+	if t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return "string|number|boolean|array|object"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return "string|number|boolean|array|object"
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// jsonField returns the field of struct type t that decodes key, matching names
+// case-insensitively as encoding/json does.
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fold reflect.StructField
+	folded := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return field, true
+		}
+		if !folded && strings.EqualFold(name, key) {
+			fold, folded = field, true
+		}
+	}
+	return fold, folded
+}
@@ -0,0 +1,103 @@
+package gonpi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSchemaWarnings tests that unknown fields and type mismatches are aggregated
+// across requests and reported once each.
+func TestSchemaWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result_count":2,"results":[
+			{"number":"1234567890","created_epoch":"1700000000","basic":{"first_name":"JANE","nickname":"J"}},
+			{"number":"1234567891","enumeration_type":2,"basic":{"first_name":"JOHN","nickname":"JJ"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	var reported []SchemaWarning
+	client := NewClient(WithBaseURL(server.URL), WithSchemaWarnings(func(w SchemaWarning) {
+		reported = append(reported, w)
+	}))
+	for range 2 {
+		providers, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(providers) != 2 || providers[1].Basic.FirstName != "JOHN" || providers[0].CreatedEpoch != 1700000000 {
+			t.Fatalf("expected the rest of the response to be decoded, got %+v", providers)
+		}
+	}
+
+	warnings := client.SchemaWarnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %+v", warnings)
+	}
+	if w := warnings[0]; w.Kind != SchemaUnknownField || w.Path != "results[].basic.nickname" || w.Count != 2 {
+		t.Errorf("unexpected unknown field warning: %+v", w)
+	}
+	w := warnings[1]
+	if w.Kind != SchemaTypeMismatch || w.Path != "results[].enumeration_type" || w.Expected != "string" || w.Got != "number" {
+		t.Errorf("unexpected type mismatch warning: %+v", w)
+	}
+	if w.FirstSeen.IsZero() || w.LastSeen.Before(w.FirstSeen) {
+		t.Errorf("unexpected timestamps: %+v", w)
+	}
+	if len(reported) != 2 {
+		t.Errorf("expected each warning to be reported once, got %d", len(reported))
+	}
+}
+
+// TestSchemaWarnings_Disabled tests that nothing is recorded without the option or in
+// strict mode.
+func TestSchemaWarnings_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result_count":1,"results":[{"number":"1234567890","new_field":"x"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	if _, err := client.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if warnings := client.SchemaWarnings(); warnings != nil {
+		t.Errorf("expected no warnings without WithSchemaWarnings, got %+v", warnings)
+	}
+
+	strict := NewClient(WithBaseURL(server.URL), WithStrictDecoding(), WithSchemaWarnings(nil))
+	if _, err := strict.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"}); err == nil {
+		t.Error("expected strict decoding to still reject unknown fields")
+	}
+	if warnings := strict.SchemaWarnings(); len(warnings) != 0 {
+		t.Errorf("expected no warnings in strict mode, got %+v", warnings)
+	}
+}
+
+// TestWalkSchema tests matching of JSON values against Go types.
+func TestWalkSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want int
+	}{
+		{"clean", `{"result_count":1,"results":[{"number":"1","basic":{"first_name":"A"}}]}`, 0},
+		{"nulls", `{"results":[{"number":null,"addresses":null}]}`, 0},
+		{"case-insensitive", `{"Result_Count":1}`, 0},
+		{"flexint number", `{"results":[{"created_epoch":1700000000}]}`, 0},
+		{"object for array", `{"results":{"number":"1"}}`, 1},
+		{"nested unknown", `{"results":[{"addresses":[{"geo":{}}]}]}`, 1},
+		{"duplicate paths", `{"results":[{"x":1},{"x":2}]}`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &schemaDrift{warnings: make(map[schemaWarningKey]*SchemaWarning)}
+			d.check([]byte(tt.json), &APIResponse{})
+			if got := d.list(); len(got) != tt.want {
+				t.Errorf("expected %d warnings, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// StoreSchemaVersion is the version of the provider data layout this release expects
+// persistent stores to hold. Stores implementing VersionedStore report theirs, and
+// SelfCheck fails if they differ.
+const StoreSchemaVersion = 1
+
+// VersionedStore is implemented by persistent ProviderStores that record the schema
+// version of the data they hold, so that a service started against a store written by
+// an incompatible release fails its self-check instead of serving bad lookups.
+type VersionedStore interface {
+	SchemaVersion(ctx context.Context) (int, error)
+}
+
+// Pinger is implemented by cache backends and stores that can check their connection
+// cheaply, such as with a Redis PING. SelfCheck uses it when available.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// CheckStatus is the outcome of one self-check.
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped" // the component is not configured
+)
+
+// SelfCheckResult is the outcome of one check of a SelfCheckReport.
+type SelfCheckResult struct {
+	// Name is "upstream", "cache_backend", "store", "taxonomy" or "zip_centroids".
+	Name     string        `json:"name"`
+	Status   CheckStatus   `json:"status"`
+	Duration time.Duration `json:"duration"`
+
+	// Detail describes what was checked, such as the base URL or the store's indexes.
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+	err    error
+}
+
+// SelfCheckReport is the result of Client.SelfCheck.
+type SelfCheckReport struct {
+	Time time.Time `json:"time"`
+
+	// OK is true if no check failed.
+	OK     bool              `json:"ok"`
+	Checks []SelfCheckResult `json:"checks"`
+}
+
+// Err returns the joined errors of the failed checks, or nil if all passed.
+func (r SelfCheckReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if check.Status == CheckFailed {
+			errs = append(errs, fmt.Errorf("%s: %w", check.Name, check.err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// selfCheckNPI is the key probed in cache backends and stores without a Pinger. It is
+// not a valid NPI, so it never collides with a cached or stored provider.
+const selfCheckNPI = "gonpi-selfcheck"
+
+// SelfCheck validates the client's configuration and dependencies, for running in
+// service init before taking traffic. It checks that the registry at the base URL is
+// reachable and answering correctly (see Ping), that the cache backend and store
+// respond, that a VersionedStore holds StoreSchemaVersion data, and that the built-in
+// taxonomy table and any ZIP centroid table are loaded. Components that are not
+// configured are skipped. Every check runs even if an earlier one fails.
+//
+// Example usage:
+//
+//	report := client.SelfCheck(ctx)
+//	if !report.OK {
+//	    log.Fatalf("self-check failed: %v", report.Err())
+//	}
+func (c *Client) SelfCheck(ctx context.Context) SelfCheckReport {
+	ctx, span := c.tracer.Start(ctx, "SelfCheck")
+	defer span.End()
+
+	report := SelfCheckReport{Time: time.Now(), OK: true}
+	run := func(name string, check func() (detail string, err error)) {
+		result := SelfCheckResult{Name: name, Status: CheckOK}
+		start := time.Now()
+		result.Detail, result.err = check()
+		result.Duration = time.Since(start)
+		switch {
+		case errors.Is(result.err, errCheckSkipped):
+			result.Status, result.err = CheckSkipped, nil
+		case result.err != nil:
+			result.Status, result.Error = CheckFailed, result.err.Error()
+			report.OK = false
+		}
+		span.SetAttributes(c.traceAttrs(attribute.String("check."+name, string(result.Status)))...)
+		report.Checks = append(report.Checks, result)
+	}
+
+	run("upstream", func() (string, error) {
+		return c.baseURL, c.Ping(ctx)
+	})
+	run("cache_backend", func() (string, error) {
+		if c.cacheBackend == nil {
+			return "", errCheckSkipped
+		}
+		detail := fmt.Sprintf("%T", c.cacheBackend)
+		if pinger, ok := c.cacheBackend.(Pinger); ok {
+			return detail, pinger.Ping(ctx)
+		}
+		_, _, err := c.cacheBackend.Get(ctx, c.cacheKey(selfCheckNPI))
+		return detail, err
+	})
+	run("store", func() (string, error) {
+		if c.store == nil {
+			return "", errCheckSkipped
+		}
+		return c.checkStore(ctx)
+	})
+	run("taxonomy", func() (string, error) {
+		if len(defaultTaxonomyClasses) == 0 {
+			return "", errors.New("taxonomy table is empty")
+		}
+		return fmt.Sprintf("%d taxonomy classes", len(defaultTaxonomyClasses)), nil
+	})
+	run("zip_centroids", func() (string, error) {
+		if c.zipCentroids == nil {
+			return "", errCheckSkipped
+		}
+		if c.zipCentroids.Len() == 0 {
+			return "", errors.New("ZIP centroid table is empty")
+		}
+		return fmt.Sprintf("%d ZIP codes", c.zipCentroids.Len()), nil
+	})
+
+	if err := report.Err(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "self-check failed")
+	}
+	return report
+}
+
+// errCheckSkipped is returned by checks of components that are not configured.
+var errCheckSkipped = errors.New("not configured")
+
+// checkStore checks that the store responds and holds data of the expected schema
+// version, describing its type and indexes.
+func (c *Client) checkStore(ctx context.Context) (string, error) {
+	detail := fmt.Sprintf("%T", c.store)
+	if indexed, ok := c.store.(interface{ Indexes() []string }); ok {
+		if indexes := indexed.Indexes(); len(indexes) > 0 {
+			detail += ", indexes: " + strings.Join(indexes, ", ")
+		}
+	}
+
+	var err error
+	if pinger, ok := c.store.(Pinger); ok {
+		err = pinger.Ping(ctx)
+	} else {
+		_, err = c.store.Get(ctx, selfCheckNPI)
+	}
+	if err != nil {
+		return detail, err
+	}
+
+	if versioned, ok := c.store.(VersionedStore); ok {
+		version, err := versioned.SchemaVersion(ctx)
+		if err != nil {
+			return detail, fmt.Errorf("failed to read schema version: %w", err)
+		}
+		detail += fmt.Sprintf(", schema version %d", version)
+		if version != StoreSchemaVersion {
+			return detail, fmt.Errorf("store schema version %d, want %d", version, StoreSchemaVersion)
+		}
+	}
+	return detail, nil
+}
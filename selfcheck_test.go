@@ -0,0 +1,78 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// versionedStore is a MemoryStore reporting a fixed schema version.
+type versionedStore struct {
+	*MemoryStore
+	version int
+}
+
+func (s versionedStore) SchemaVersion(context.Context) (int, error) { return s.version, nil }
+
+// failingBackend is a cache backend whose connection is down.
+type failingBackend struct{ *mapCacheBackend }
+
+func (failingBackend) Ping(context.Context) error { return errors.New("connection refused") }
+
+// TestSelfCheck tests a healthy configuration and one where every component fails.
+func TestSelfCheck(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := mockProvider()
+		p.Number = r.URL.Query().Get("number")
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{p}))
+	}))
+	defer healthy.Close()
+	ctx := context.Background()
+
+	known := KnownNPIs()[0]
+	if known.Name != "" || known.EnumerationType != "" || known.State != "" {
+		t.Skip("the first known NPI checks more than the mock provider returns")
+	}
+
+	client := NewClient(WithBaseURL(healthy.URL), WithCacheBackend(newMapCacheBackend()),
+		WithStore(versionedStore{NewMemoryStore(), StoreSchemaVersion}))
+	defer client.Close()
+	report := client.SelfCheck(ctx)
+	if !report.OK || report.Err() != nil {
+		t.Fatalf("healthy self-check failed: %+v", report)
+	}
+	statuses := map[string]CheckStatus{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	want := map[string]CheckStatus{"upstream": CheckOK, "cache_backend": CheckOK, "store": CheckOK, "taxonomy": CheckOK, "zip_centroids": CheckSkipped}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("check %s = %q, want %q", name, statuses[name], status)
+		}
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	broken := NewClient(WithBaseURL(down.URL), WithRetry(RetryConfig{MaxRetries: 0}),
+		WithCacheBackend(failingBackend{newMapCacheBackend()}),
+		WithStore(versionedStore{NewMemoryStore(), StoreSchemaVersion + 1}),
+		WithZIPCentroids(NewZIPCentroids()))
+	defer broken.Close()
+	report = broken.SelfCheck(ctx)
+	if report.OK {
+		t.Fatal("expected the self-check to fail")
+	}
+	err := report.Err()
+	for _, name := range []string{"upstream:", "cache_backend: connection refused", "store: store schema version 2, want 1", "zip_centroids:"} {
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("expected %q in %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// APIKey describes a client of the proxy allowed to call it.
+type APIKey struct {
+	// ID names the key in logs and metrics; it is recorded as the CallMetadata caller
+	// of the key's requests. It must not be the secret key itself.
+	ID string
+
+	// Tenant is recorded as the CallMetadata tenant of the key's requests.
+	Tenant string
+
+	// RateLimit is the number of requests per second the key may make, with bursts of
+	// up to Burst requests. 0 means unlimited.
+	RateLimit float64
+	Burst     int
+
+	// DailyQuota is the number of requests the key may make per UTC day. 0 means
+	// unlimited.
+	DailyQuota int
+}
+
+// KeyStore looks up API keys. Implementations must be safe for concurrent use.
+type KeyStore interface {
+	// LookupKey returns the key for the secret presented by a client, or nil and a nil
+	// error if it is unknown.
+	LookupKey(ctx context.Context, secret string) (*APIKey, error)
+}
+
+// StaticKeyStore is a KeyStore backed by a map from secret to key, for keys loaded
+// from configuration.
+type StaticKeyStore map[string]APIKey
+
+// LookupKey implements KeyStore.
+func (s StaticKeyStore) LookupKey(_ context.Context, secret string) (*APIKey, error) {
+	key, ok := s[secret]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// WithAPIKeys requires an API key from store on every endpoint except /openapi.json,
+// sent as "Authorization: Bearer KEY" or in an X-API-Key header. Requests without a
+// valid key get a 401, and requests over the key's rate limit or daily quota a 429
+// with a Retry-After header, so that one tenant cannot starve the others of the
+// shared upstream budget. Each request's CallMetadata names the key and its tenant.
+func WithAPIKeys(store KeyStore) Option {
+	return func(s *Server) {
+		s.keys = store
+		s.usage = &keyUsage{keys: make(map[string]*keyState)}
+	}
+}
+
+// keyUsage tracks the rate limit and quota of each key.
+type keyUsage struct {
+	mu   sync.Mutex
+	keys map[string]*keyState
+}
+
+// keyState is the usage of one key.
+type keyState struct {
+	tokens float64
+	last   time.Time
+
+	day   time.Time
+	count int
+}
+
+// allow records a request by key at now, returning false and how long to wait if it
+// is over the key's rate limit or quota.
+func (u *keyUsage) allow(key *APIKey, now time.Time) (bool, time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	state, ok := u.keys[key.ID]
+	if !ok {
+		state = &keyState{tokens: float64(max(1, key.Burst)), last: now}
+		u.keys[key.ID] = state
+	}
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !state.day.Equal(day) {
+		state.day, state.count = day, 0
+	}
+	if key.DailyQuota > 0 && state.count >= key.DailyQuota {
+		return false, day.Add(24 * time.Hour).Sub(now)
+	}
+
+	if key.RateLimit > 0 {
+		burst := float64(max(1, key.Burst))
+		state.tokens = math.Min(burst, state.tokens+now.Sub(state.last).Seconds()*key.RateLimit)
+		state.last = now
+		if state.tokens < 1 {
+			return false, time.Duration((1 - state.tokens) / key.RateLimit * float64(time.Second))
+		}
+		state.tokens--
+	}
+	state.count++
+	return true, 0
+}
+
+// authenticate checks the request's API key and applies its limits, writing an error
+// response and returning false if the request may not proceed. On success it returns
+// the request with the key's CallMetadata attached.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	secret := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); secret == "" && len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		secret = strings.TrimSpace(auth[7:])
+	}
+	if secret == "" {
+		writeStatus(w, http.StatusUnauthorized, "missing API key")
+		return r, false
+	}
+	key, err := s.keys.LookupKey(r.Context(), secret)
+	if err != nil {
+		writeStatus(w, http.StatusServiceUnavailable, "key store unavailable")
+		return r, false
+	}
+	if key == nil {
+		writeStatus(w, http.StatusUnauthorized, "invalid API key")
+		return r, false
+	}
+
+	if ok, wait := s.usage.allow(key, time.Now()); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeStatus(w, http.StatusTooManyRequests, "rate limit or quota exceeded for key "+key.ID)
+		return r, false
+	}
+
+	md, _ := gonpi.CallMetadataFromContext(r.Context())
+	md.Caller, md.Tenant = key.ID, key.Tenant
+	return r.WithContext(gonpi.ContextWithCallMetadata(r.Context(), md)), true
+}
+
+// writeStatus writes an ErrorResponse with status and message.
+func writeStatus(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Status: status, Error: message})
+}
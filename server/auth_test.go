@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// metadataClient is an NPIClient recording the CallMetadata of lookups.
+type metadataClient struct {
+	fakeClient
+	md gonpi.CallMetadata
+}
+
+func (m *metadataClient) GetProviderByNPI(ctx context.Context, npi string) (*gonpi.Provider, error) {
+	m.md, _ = gonpi.CallMetadataFromContext(ctx)
+	return &gonpi.Provider{Number: npi}, nil
+}
+
+// TestAPIKeys tests authentication and that requests carry the key's metadata.
+func TestAPIKeys(t *testing.T) {
+	client := &metadataClient{}
+	s := New(client, WithAPIKeys(StaticKeyStore{"secret-a": {ID: "key-a", Tenant: "acme"}}))
+
+	request := func(header, value, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request("", "", "/v1/providers/1234567893"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("missing key: expected 401, got %d", rec.Code)
+	}
+	if rec := request("X-API-Key", "wrong", "/v1/providers/1234567893"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("invalid key: expected 401, got %d", rec.Code)
+	}
+	if rec := request("Authorization", "Bearer secret-a", "/v1/providers/1234567893"); rec.Code != http.StatusOK {
+		t.Errorf("bearer key: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if client.md.Caller != "key-a" || client.md.Tenant != "acme" {
+		t.Errorf("expected key metadata on the call, got %+v", client.md)
+	}
+	if rec := request("X-API-Key", "secret-a", "/v1/providers/1234567893"); rec.Code != http.StatusOK {
+		t.Errorf("header key: expected 200, got %d", rec.Code)
+	}
+	if rec := request("", "", "/openapi.json"); rec.Code != http.StatusOK {
+		t.Errorf("openapi.json: expected 200 without a key, got %d", rec.Code)
+	}
+}
+
+// TestAPIKeys_Limits tests that each key has its own rate limit and quota.
+func TestAPIKeys_Limits(t *testing.T) {
+	s := New(&metadataClient{}, WithAPIKeys(StaticKeyStore{
+		"limited": {ID: "limited", RateLimit: 0.001, Burst: 2},
+		"quota":   {ID: "quota", DailyQuota: 1},
+		"free":    {ID: "free"},
+	}))
+	status := func(secret string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/providers/1234567893", nil)
+		req.Header.Set("X-API-Key", secret)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec.Code, rec.Header().Get("Retry-After")
+	}
+
+	for i, want := range []int{200, 200, 429} {
+		if got, _ := status("limited"); got != want {
+			t.Errorf("limited request %d: got %d, want %d", i, got, want)
+		}
+	}
+	if _, retry := status("limited"); retry == "" {
+		t.Error("expected Retry-After on rate-limited requests")
+	}
+	for i, want := range []int{200, 429} {
+		if got, _ := status("quota"); got != want {
+			t.Errorf("quota request %d: got %d, want %d", i, got, want)
+		}
+	}
+	for range 5 {
+		if got, _ := status("free"); got != http.StatusOK {
+			t.Errorf("other keys should not be limited, got %d", got)
+		}
+	}
+}
+
+// TestKeyUsage_QuotaReset tests that daily quotas reset at midnight UTC.
+func TestKeyUsage_QuotaReset(t *testing.T) {
+	usage := &keyUsage{keys: make(map[string]*keyState)}
+	key := &APIKey{ID: "k", DailyQuota: 1}
+	day := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+
+	if ok, _ := usage.allow(key, day); !ok {
+		t.Fatal("expected first request to be allowed")
+	}
+	ok, wait := usage.allow(key, day)
+	if ok || wait != time.Minute {
+		t.Errorf("expected quota exhaustion until midnight, got %v, %v", ok, wait)
+	}
+	if ok, _ := usage.allow(key, day.Add(2*time.Minute)); !ok {
+		t.Error("expected quota to reset the next day")
+	}
+}
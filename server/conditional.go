@@ -0,0 +1,69 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// writeCached writes v as a 200 JSON response with an ETag of its body and, if
+// modified is not zero, a Last-Modified header. Requests whose If-None-Match or,
+// without one, If-Modified-Since header shows that the client has the current body
+// get a 304 Not Modified instead, so polling clients skip unchanged bodies.
+func writeCached(w http.ResponseWriter, r *http.Request, v any, modified time.Time) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		writeError(w, err)
+		return
+	}
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	h := w.Header()
+	h.Set("ETag", etag)
+	if !modified.IsZero() {
+		h.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// notModified evaluates the request's conditional headers against the response's
+// etag and modification time, as RFC 9110 specifies for GET.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !modified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !modified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// lastModified returns when the provider record last changed, from its
+// last_updated_epoch or, without one, its last updated date.
+func lastModified(p gonpi.Provider) time.Time {
+	if t, ok := p.LastUpdatedEpochTime(); ok {
+		return t
+	}
+	t, _ := p.LastUpdatedTime()
+	return t
+}
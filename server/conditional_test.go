@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestConditionalRequests tests ETag and Last-Modified validation of provider lookups.
+func TestConditionalRequests(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	s := New(&fakeClient{providers: map[string]*gonpi.Provider{
+		"1234567893": {Number: "1234567893", LastUpdatedEpoch: gonpi.FlexInt(updated.UnixMilli())},
+	}})
+	request := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/providers/1234567893", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := request("", "")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %d %q", first.Code, etag)
+	}
+	if got := first.Header().Get("Last-Modified"); got != "Wed, 01 May 2024 12:30:00 GMT" {
+		t.Errorf("Last-Modified = %q", got)
+	}
+
+	tests := []struct {
+		name, header, value string
+		want                int
+	}{
+		{"matching etag", "If-None-Match", etag, http.StatusNotModified},
+		{"weak etag in list", "If-None-Match", `"other", W/` + etag, http.StatusNotModified},
+		{"other etag", "If-None-Match", `"other"`, http.StatusOK},
+		{"not modified since", "If-Modified-Since", "Wed, 01 May 2024 12:30:00 GMT", http.StatusNotModified},
+		{"modified since", "If-Modified-Since", "Tue, 30 Apr 2024 00:00:00 GMT", http.StatusOK},
+		{"malformed date", "If-Modified-Since", "yesterday", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(tt.header, tt.value)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("expected an empty 304 body, got %q", rec.Body)
+			}
+		})
+	}
+}
+
+// TestConditionalRequests_Search tests that search responses are validated too.
+func TestConditionalRequests_Search(t *testing.T) {
+	s := newTestServer(t)
+	first := get(s, "/v1/providers?last_name=Doe")
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag on search responses")
+	}
+	if first.Header().Get("Last-Modified") != "" {
+		t.Error("expected no Last-Modified on search responses")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/providers?last_name=Doe", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %d", rec.Code)
+	}
+
+	// If-Modified-Since cannot show that a result set is unchanged
+	req = httptest.NewRequest(http.MethodGet, "/v1/providers?last_name=Doe", nil)
+	req.Header.Set("If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 for If-Modified-Since on a search, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// OpenAPIDocument returns the OpenAPI 3.1 document describing the proxy endpoints.
+// Component schemas are derived from gonpi.RegistrySchema, so they track the Go types.
+func OpenAPIDocument() ([]byte, error) {
+	var registry struct {
+		Defs map[string]json.RawMessage `json:"$defs"`
+	}
+	// OpenAPI components live under #/components/schemas rather than #/$defs
+	rewritten := bytes.ReplaceAll(gonpi.RegistrySchema, []byte(`"#/$defs/`), []byte(`"#/components/schemas/`))
+	if err := json.Unmarshal(rewritten, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse registry schema: %w", err)
+	}
+
+	schemas := map[string]any{
+		"SearchResponse": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"result_count": map[string]any{"type": "integer"},
+				"results":      map[string]any{"type": "array", "items": ref("Provider")},
+			},
+		},
+		"ErrorResponse": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"status": map[string]any{"type": "integer"},
+				"error":  map[string]any{"type": "string"},
+			},
+		},
+	}
+	for name, schema := range registry.Defs {
+		schemas[name] = schema
+	}
+
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "gonpi NPI Registry proxy",
+			"description": "REST proxy in front of the CMS NPI Registry API.",
+			"version":     "1.0.0",
+		},
+		"paths": map[string]any{
+			"/v1/providers/{npi}": map[string]any{
+				"get": map[string]any{
+					"operationId": "getProvider",
+					"summary":     "Look up a provider by NPI",
+					"parameters": []any{map[string]any{
+						"name": "npi", "in": "path", "required": true,
+						"schema": map[string]any{"type": "string", "pattern": "^[0-9]{10}$"},
+					}},
+					"responses": responses(ref("Provider")),
+				},
+			},
+			"/v1/providers": map[string]any{
+				"get": map[string]any{
+					"operationId": "searchProviders",
+					"summary":     "Search providers",
+					"parameters":  searchParameters(),
+					"responses":   responses(ref("SearchResponse")),
+				},
+			},
+		},
+		"components": map[string]any{"schemas": schemas},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ref returns a reference to a component schema.
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// responses returns the standard response set with the given success schema.
+func responses(success map[string]any) map[string]any {
+	content := func(schema map[string]any) map[string]any {
+		return map[string]any{"application/json": map[string]any{"schema": schema}}
+	}
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{"description": description, "content": content(ref("ErrorResponse"))}
+	}
+	return map[string]any{
+		"200": map[string]any{"description": "OK", "content": content(success)},
+		"304": map[string]any{"description": "Not modified since the ETag or date in If-None-Match or If-Modified-Since"},
+		"400": errorResponse("Invalid request"),
+		"404": errorResponse("Provider not found"),
+		"429": errorResponse("Upstream rate limit"),
+		"502": errorResponse("Upstream error"),
+		"504": errorResponse("Upstream timeout"),
+	}
+}
+
+// searchParameters describes the search query parameters.
+func searchParameters() []any {
+	var params []any
+	for _, name := range []string{
+		"number", "enumeration_type", "first_name", "last_name", "organization_name",
+		"taxonomy_description", "address_purpose", "city", "state", "postal_code", "country_code",
+	} {
+		params = append(params, map[string]any{"name": name, "in": "query", "schema": map[string]any{"type": "string"}})
+	}
+	params = append(params,
+		map[string]any{"name": "limit", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 0, "maximum": gonpi.MaxLimit}},
+		map[string]any{"name": "skip", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 0, "maximum": gonpi.MaxSkip}},
+	)
+	return params
+}
@@ -0,0 +1,219 @@
+// Package server exposes a gonpi.NPIClient, usually a *gonpi.Client, as a small REST
+// proxy in front of the NPI Registry API, so that non-Go services can share one
+// client's caching, retries and rate limiting.
+//
+// Endpoints:
+//
+//	GET /v1/providers/{npi}   look up a single provider
+//	GET /v1/providers         search providers (query parameters mirror the registry API)
+//	GET /openapi.json         OpenAPI 3.1 description of these endpoints
+//
+// Provider and search responses carry an ETag and honor If-None-Match with 304 Not
+// Modified. Provider responses also carry a Last-Modified header when the record's
+// update time is known, and honor If-Modified-Since.
+//
+// With WithWebhooks, clients can also subscribe to change events for NPIs, delivered
+// as signed POSTs by a WebhookHub:
+//
+//	POST   /v1/webhooks        subscribe a URL to a list of NPIs
+//	GET    /v1/webhooks        list subscriptions
+//	GET    /v1/webhooks/{id}   get a subscription
+//	DELETE /v1/webhooks/{id}   unsubscribe
+//
+// With WithStatus, operators can check upstream health, cache and rate-limit usage,
+// recent errors and scheduled tasks:
+//
+//	GET /debug/status          HTML status page
+//	GET /debug/status.json     the same report as JSON
+//
+// With WithAPIKeys, every endpoint but /openapi.json requires an API key, and each key
+// has its own rate limit and daily quota.
+//
+// Example usage:
+//
+//	client := gonpi.NewClient(gonpi.WithCache(5 * time.Minute))
+//	defer client.Close()
+//	log.Fatal(http.ListenAndServe(":8080", server.New(client)))
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// Server is an http.Handler serving the proxy endpoints.
+type Server struct {
+	client   gonpi.NPIClient
+	webhooks *WebhookHub
+	status   *statusSource
+	keys     KeyStore
+	usage    *keyUsage
+	mux      *http.ServeMux
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithWebhooks serves the webhook subscription endpoints backed by hub. The caller
+// runs the hub, e.g. with hub.Run. With WithAPIKeys, each tenant, or each key without
+// a tenant, sees and removes only the subscriptions it created.
+func WithWebhooks(hub *WebhookHub) Option {
+	return func(s *Server) {
+		s.webhooks = hub
+	}
+}
+
+// New creates a Server backed by client.
+func New(client gonpi.NPIClient, opts ...Option) *Server {
+	s := &Server{
+		client: client,
+		mux:    http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.mux.HandleFunc("GET /v1/providers/{npi}", s.handleGetProvider)
+	s.mux.HandleFunc("GET /v1/providers", s.handleSearch)
+	s.mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	if s.webhooks != nil {
+		s.mux.HandleFunc("POST /v1/webhooks", s.handleSubscribe)
+		s.mux.HandleFunc("GET /v1/webhooks", s.handleListSubscriptions)
+		s.mux.HandleFunc("GET /v1/webhooks/{id}", s.handleGetSubscription)
+		s.mux.HandleFunc("DELETE /v1/webhooks/{id}", s.handleUnsubscribe)
+	}
+	if s.status != nil {
+		s.mux.HandleFunc("GET /debug/status", s.handleStatusPage)
+		s.mux.HandleFunc("GET /debug/status.json", s.handleStatusJSON)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.keys != nil && r.URL.Path != "/openapi.json" {
+		var ok bool
+		if r, ok = s.authenticate(w, r); !ok {
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// SearchResponse is the body returned by the search endpoint.
+type SearchResponse struct {
+	ResultCount int              `json:"result_count"`
+	Results     []gonpi.Provider `json:"results"`
+}
+
+// ErrorResponse is the body returned for failed requests.
+type ErrorResponse struct {
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+func (s *Server) handleGetProvider(w http.ResponseWriter, r *http.Request) {
+	provider, err := s.client.GetProviderByNPI(r.Context(), r.PathValue("npi"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if provider == nil {
+		writeError(w, gonpi.ErrNotFound)
+		return
+	}
+	writeCached(w, r, provider, lastModified(*provider))
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	opts, err := searchOptionsFromQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	providers, err := s.client.SearchProviders(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if providers == nil {
+		providers = []gonpi.Provider{}
+	}
+	// A search result set can change without any record in it changing, as when a
+	// provider is added or leaves, so it is validated by ETag only
+	writeCached(w, r, SearchResponse{ResultCount: len(providers), Results: providers}, time.Time{})
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := OpenAPIDocument()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(doc)
+}
+
+// searchOptionsFromQuery maps query parameters, named as in the registry API, to
+// gonpi.SearchOptions.
+func searchOptionsFromQuery(r *http.Request) (gonpi.SearchOptions, error) {
+	q := r.URL.Query()
+	opts := gonpi.SearchOptions{
+		Number:              q.Get("number"),
+		EnumerationType:     q.Get("enumeration_type"),
+		FirstName:           q.Get("first_name"),
+		LastName:            q.Get("last_name"),
+		OrganizationName:    q.Get("organization_name"),
+		TaxonomyDescription: q.Get("taxonomy_description"),
+		AddressPurpose:      q.Get("address_purpose"),
+		City:                q.Get("city"),
+		State:               q.Get("state"),
+		PostalCode:          q.Get("postal_code"),
+		CountryCode:         q.Get("country_code"),
+	}
+
+	for name, dst := range map[string]*int{"limit": &opts.Limit, "skip": &opts.Skip} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return opts, &gonpi.ValidationError{Field: name, Message: name + " must be a non-negative integer"}
+			}
+			*dst = n
+		}
+	}
+	return opts, nil
+}
+
+// statusForError maps client errors to proxy response status codes.
+func statusForError(err error) int {
+	switch {
+	case gonpi.IsValidation(err):
+		return http.StatusBadRequest
+	case gonpi.IsNotFound(err), errors.Is(err, ErrSubscriptionNotFound):
+		return http.StatusNotFound
+	case gonpi.IsRateLimited(err):
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, gonpi.ErrClientClosed):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadGateway
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := statusForError(err)
+	writeJSON(w, status, ErrorResponse{Status: status, Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
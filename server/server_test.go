@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// newUpstream returns a fake registry returning one provider for NPI 1234567890.
+func newUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var results []gonpi.Provider
+		if n := r.URL.Query().Get("number"); n == "" || n == "1234567890" {
+			results = append(results, gonpi.Provider{
+				Number:          "1234567890",
+				EnumerationType: "NPI-1",
+				Basic:           gonpi.BasicInfo{FirstName: "John", LastName: "Doe"},
+			})
+		}
+		json.NewEncoder(w).Encode(gonpi.APIResponse{ResultCount: len(results), Results: results})
+	}))
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	upstream := newUpstream(t)
+	t.Cleanup(upstream.Close)
+	return New(gonpi.NewClient(gonpi.WithBaseURL(upstream.URL)))
+}
+
+func get(s *Server, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+// TestGetProvider tests single provider lookups.
+func TestGetProvider(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := get(s, "/v1/providers/1234567890")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var provider gonpi.Provider
+	json.Unmarshal(rec.Body.Bytes(), &provider)
+	if provider.Basic.LastName != "Doe" {
+		t.Errorf("unexpected provider: %+v", provider)
+	}
+
+	if rec := get(s, "/v1/providers/9999999999"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+	if rec := get(s, "/v1/providers/123"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+// TestSearch tests the search endpoint.
+func TestSearch(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := get(s, "/v1/providers?last_name=Doe&state=CA&limit=5")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp SearchResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.ResultCount != 1 || len(resp.Results) != 1 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if rec := get(s, "/v1/providers?last_name=Doe&limit=abc"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for bad limit, got %d", rec.Code)
+	}
+	if rec := get(s, "/v1/providers?last_name=Doe&state=XX"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for bad state, got %d", rec.Code)
+	}
+}
+
+// TestOpenAPIDocument tests the served OpenAPI document.
+func TestOpenAPIDocument(t *testing.T) {
+	rec := get(newTestServer(t), "/openapi.json")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("unexpected version %s", doc.OpenAPI)
+	}
+	for _, path := range []string{"/v1/providers", "/v1/providers/{npi}"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Errorf("missing path %s", path)
+		}
+	}
+	for _, name := range []string{"Provider", "SearchResponse", "ErrorResponse"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("missing schema %s", name)
+		}
+	}
+	if strings.Contains(rec.Body.String(), "#/$defs/") {
+		t.Error("expected $defs references to be rewritten")
+	}
+}
+
+// fakeClient is an NPIClient serving canned providers without an upstream API.
+type fakeClient struct {
+	providers map[string]*gonpi.Provider
+}
+
+func (f *fakeClient) GetProviderByNPI(_ context.Context, npi string) (*gonpi.Provider, error) {
+	return f.providers[npi], nil
+}
+
+func (f *fakeClient) SearchProviders(context.Context, gonpi.SearchOptions) ([]gonpi.Provider, error) {
+	var results []gonpi.Provider
+	for _, p := range f.providers {
+		results = append(results, *p)
+	}
+	return results, nil
+}
+
+func (f *fakeClient) GetProvidersByNPIs(_ context.Context, npis []string, _ ...gonpi.BatchOption) (map[string]*gonpi.Provider, error) {
+	results := make(map[string]*gonpi.Provider)
+	for _, npi := range npis {
+		if p, ok := f.providers[npi]; ok {
+			results[npi] = p
+		}
+	}
+	return results, nil
+}
+
+// TestServer_FakeClient tests that the server works over any NPIClient.
+func TestServer_FakeClient(t *testing.T) {
+	fake := &fakeClient{providers: map[string]*gonpi.Provider{
+		"1234567893": {Number: "1234567893", EnumerationType: "NPI-2"},
+	}}
+	srv := New(fake)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/providers/1234567893", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var provider gonpi.Provider
+	json.Unmarshal(rec.Body.Bytes(), &provider)
+	if provider.EnumerationType != "NPI-2" {
+		t.Errorf("unexpected provider: %+v", provider)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/providers/1245319599", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing provider status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// StatusResponse is the body returned by the status endpoint.
+type StatusResponse struct {
+	Time   time.Time          `json:"time"`
+	Client gonpi.ClientStatus `json:"client"`
+	Tasks  []gonpi.TaskStatus `json:"tasks"`
+
+	// Webhooks is nil unless the server has a WebhookHub.
+	Webhooks *HubStatus `json:"webhooks,omitempty"`
+}
+
+// statusSource is what the status endpoints report on.
+type statusSource struct {
+	client     *gonpi.Client
+	schedulers []*gonpi.Scheduler
+}
+
+// WithStatus serves an operator status page at /debug/status, and the same report as
+// JSON at /debug/status.json, showing client's upstream health, cache and rate-limit
+// usage, recent errors, the tasks of schedulers and, with WithWebhooks, the hub's
+// polling. With WithAPIKeys, the pages require an API key like every other endpoint.
+func WithStatus(client *gonpi.Client, schedulers ...*gonpi.Scheduler) Option {
+	return func(s *Server) {
+		s.status = &statusSource{client: client, schedulers: schedulers}
+	}
+}
+
+// statusReport collects the current status.
+func (s *Server) statusReport() StatusResponse {
+	report := StatusResponse{
+		Time:   time.Now(),
+		Client: s.status.client.Status(),
+		Tasks:  []gonpi.TaskStatus{},
+	}
+	for _, scheduler := range s.status.schedulers {
+		report.Tasks = append(report.Tasks, scheduler.Status()...)
+	}
+	if s.webhooks != nil {
+		hub := s.webhooks.Status()
+		report.Webhooks = &hub
+	}
+	return report
+}
+
+func (s *Server) handleStatusJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, s.statusReport())
+}
+
+func (s *Server) handleStatusPage(w http.ResponseWriter, r *http.Request) {
+	var page bytes.Buffer
+	if err := statusPage.Execute(&page, s.statusReport()); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(page.Bytes())
+}
+
+// statusPage renders a StatusResponse for operators.
+var statusPage = template.Must(template.New("status").Funcs(template.FuncMap{
+	"when": func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return t.UTC().Format(time.RFC3339)
+	},
+	"round": func(d time.Duration) string {
+		return d.Round(time.Millisecond).String()
+	},
+	"percent": func(f float64) string {
+		return fmt.Sprintf("%.0f%%", f*100)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gonpi status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+.ok { color: #080; }
+.bad { color: #c00; }
+</style>
+</head>
+<body>
+<h1>gonpi status</h1>
+<p>Generated {{when .Time}}. <a href="status.json">JSON</a></p>
+
+<h2>Upstream</h2>
+{{with .Client.Upstream}}
+<table>
+<tr><th>Base URL</th><td>{{.BaseURL}}</td></tr>
+<tr><th>Health</th><td>{{if .Healthy}}<span class="ok">healthy</span>{{else}}<span class="bad">failing ({{.ConsecutiveFailures}} consecutive)</span>{{end}}</td></tr>
+<tr><th>Last success</th><td>{{when .LastSuccess}}</td></tr>
+<tr><th>Last failure</th><td>{{when .LastFailure}}</td></tr>
+</table>
+{{end}}
+
+<h2>Requests and cache</h2>
+{{with .Client}}
+<table>
+<tr><th>Requests</th><td>{{.Requests}}</td></tr>
+<tr><th>Errors</th><td>{{.Errors}}</td></tr>
+<tr><th>Retries</th><td>{{.Retries}}</td></tr>
+<tr><th>Cache hits</th><td>{{.CacheHits}}</td></tr>
+<tr><th>Cache misses</th><td>{{.CacheMisses}}</td></tr>
+<tr><th>Cache entries</th><td>{{if lt .CacheEntries 0}}disabled{{else}}{{.CacheEntries}}{{end}}</td></tr>
+</table>
+
+<h2>Rate limit</h2>
+{{with .RateLimit}}
+<table>
+<tr><th>Rate</th><td>{{.Rate}}/s, burst {{.Burst}}</td></tr>
+<tr><th>Available</th><td>{{printf "%.1f" .Available}}</td></tr>
+<tr><th>Utilization</th><td>{{percent .Utilization}}</td></tr>
+</table>
+{{else}}
+<p>No rate limit configured.</p>
+{{end}}
+
+<h2>Recent errors</h2>
+{{if .RecentErrors}}
+<table>
+<tr><th>Time</th><th>URL</th><th>Error</th></tr>
+{{range .RecentErrors}}<tr><td>{{when .Time}}</td><td>{{.URL}}</td><td>{{.Error}}</td></tr>
+{{end}}
+</table>
+{{else}}
+<p>None.</p>
+{{end}}
+{{end}}
+
+<h2>Scheduled tasks</h2>
+{{if .Tasks}}
+<table>
+<tr><th>Task</th><th>Runs</th><th>Failures</th><th>Last run</th><th>Took</th><th>Next run</th><th>Last error</th></tr>
+{{range .Tasks}}<tr><td>{{.Name}}{{if .Running}} (running){{end}}</td><td>{{.Runs}}</td><td>{{.Failures}}</td><td>{{when .LastRun}}</td><td>{{round .LastDuration}}</td><td>{{when .NextRun}}</td><td{{if .LastError}} class="bad"{{end}}>{{.LastError}}</td></tr>
+{{end}}
+</table>
+{{else}}
+<p>None.</p>
+{{end}}
+
+{{with .Webhooks}}
+<h2>Webhooks</h2>
+<table>
+<tr><th>Subscriptions</th><td>{{.Subscriptions}}</td></tr>
+<tr><th>Polls</th><td>{{.Polls}}</td></tr>
+<tr><th>Last poll</th><td>{{when .LastPoll}} ({{round .LastPollDuration}})</td></tr>
+<tr><th>Last error</th><td{{if .LastError}} class="bad"{{end}}>{{.LastError}}</td></tr>
+<tr><th>Dead letters</th><td>{{.DeadLetters}}</td></tr>
+</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// TestStatus tests the JSON and HTML status endpoints.
+func TestStatus(t *testing.T) {
+	upstream := newUpstream(t)
+	t.Cleanup(upstream.Close)
+	client := gonpi.NewClient(gonpi.WithBaseURL(upstream.URL), gonpi.WithCache(time.Minute), gonpi.WithRateLimit(100, 10))
+	defer client.Close()
+
+	scheduler := client.NewScheduler()
+	scheduler.Add("refresh", gonpi.Every(time.Hour, 0), func(ctx context.Context) error { return nil })
+	hub := newTestHub(client)
+	hub.Subscribe("https://example.com/hook", []string{"1234567890"}, "")
+	s := New(client, WithStatus(client, scheduler), WithWebhooks(hub))
+
+	get(s, "/v1/providers/1234567890")
+	get(s, "/v1/providers/1234567890")
+
+	rec := get(s, "/debug/status.json")
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("expected uncached 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var status StatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Client.Requests != 1 || status.Client.CacheHits != 1 || !status.Client.Upstream.Healthy {
+		t.Errorf("unexpected client status: %+v", status.Client)
+	}
+	if status.Client.RateLimit == nil || status.Client.RateLimit.Burst != 10 {
+		t.Errorf("unexpected rate limit status: %+v", status.Client.RateLimit)
+	}
+	if len(status.Tasks) != 1 || status.Tasks[0].Name != "refresh" {
+		t.Errorf("unexpected tasks: %+v", status.Tasks)
+	}
+	if status.Webhooks == nil || status.Webhooks.Subscriptions != 1 {
+		t.Errorf("unexpected webhook status: %+v", status.Webhooks)
+	}
+
+	rec = get(s, "/debug/status")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected HTML page, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{"healthy", upstream.URL, "refresh", "Dead letters", "100/s, burst 10"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("status page missing %q", want)
+		}
+	}
+}
+
+// TestStatus_Disabled tests that the status endpoints are only served with WithStatus.
+func TestStatus_Disabled(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/debug/status", "/debug/status.json"} {
+		if rec := get(s, path); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+// TestStatus_RequiresAPIKey tests that the status endpoints are protected by API keys.
+func TestStatus_RequiresAPIKey(t *testing.T) {
+	client := gonpi.NewClient()
+	s := New(client, WithStatus(client), WithAPIKeys(StaticKeyStore{"secret": {ID: "ops"}}))
+
+	if rec := get(s, "/debug/status.json"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a key, got %d", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/debug/status.json", nil)
+	req.Header.Set("X-API-Key", "secret")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with a key, got %d: %s", rec.Code, rec.Body)
+	}
+}
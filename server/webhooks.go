@@ -0,0 +1,547 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// ErrSubscriptionNotFound indicates that no webhook subscription has the requested ID.
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrDestinationNotAllowed indicates that a delivery was refused because its address
+// is not allowed by the hub's destination policy.
+var ErrDestinationNotAllowed = errors.New("webhook destination not allowed")
+
+// Subscription is a webhook registered to receive change events for a set of NPIs.
+type Subscription struct {
+	ID      string    `json:"id"`
+	URL     string    `json:"url"`
+	NPIs    []string  `json:"npis"`
+	Created time.Time `json:"created"`
+
+	// KeyID and Tenant name the API key, and its tenant, that created the
+	// subscription on a server with WithAPIKeys. Only requests from the same tenant,
+	// or from the same key if it has no tenant, can see or remove the subscription.
+	KeyID  string `json:"key_id,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+
+	// Secret signs deliveries; see gonpi.VerifyWebhook. It is only returned when the
+	// subscription is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+// DeadLetter records an event that could not be delivered. The hub writes one JSON
+// line per dead letter to its dead-letter log.
+type DeadLetter struct {
+	Time         time.Time         `json:"time"`
+	Subscription string            `json:"subscription"`
+	URL          string            `json:"url"`
+	Attempts     int               `json:"attempts"`
+	Error        string            `json:"error"`
+	Event        gonpi.ChangeEvent `json:"event"`
+}
+
+// WebhookHub watches the NPIs of each webhook subscription and POSTs their change
+// events, signed with the subscription's secret, to the subscription URL. Failed
+// deliveries are retried if the failure is transient and are then written to the
+// dead-letter log. Subscriptions are held in memory.
+//
+// Like gonpi.Watcher, the first poll after subscribing delivers a provider.created
+// event for every NPI found.
+type WebhookHub struct {
+	client     *gonpi.Client
+	httpClient *http.Client
+	attempts   int
+	retryDelay time.Duration
+
+	// allow is the destination policy, and lookup resolves subscription hosts
+	allow  func(netip.Addr) bool
+	lookup func(ctx context.Context, host string) ([]netip.Addr, error)
+
+	deadLetterMu sync.Mutex
+	deadLetter   io.Writer
+	deadLetters  int
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+	polls         int
+	lastPoll      time.Time
+	lastPollTook  time.Duration
+	lastPollErr   error
+}
+
+// HubStatus describes a WebhookHub's polling, as returned by WebhookHub.Status.
+type HubStatus struct {
+	Subscriptions    int           `json:"subscriptions"`
+	Polls            int           `json:"polls"`
+	LastPoll         time.Time     `json:"last_poll,omitzero"`
+	LastPollDuration time.Duration `json:"last_poll_duration"`
+	LastError        string        `json:"last_error,omitempty"`
+	DeadLetters      int           `json:"dead_letters"`
+}
+
+// subscription is a Subscription with its watcher.
+type subscription struct {
+	Subscription
+	watcher *gonpi.Watcher
+}
+
+// HubOption configures a WebhookHub.
+type HubOption func(*WebhookHub)
+
+// WithDeliveryRetries sets how many times a failed delivery is retried, waiting delay
+// before the first retry and doubling it after each. Default: 3 retries after 1s.
+func WithDeliveryRetries(retries int, delay time.Duration) HubOption {
+	return func(h *WebhookHub) {
+		h.attempts = max(0, retries) + 1
+		h.retryDelay = delay
+	}
+}
+
+// WithDeadLetterLog sets where undeliverable events are written, as one DeadLetter
+// JSON object per line. By default they are dropped.
+func WithDeadLetterLog(w io.Writer) HubOption {
+	return func(h *WebhookHub) {
+		h.deadLetter = w
+	}
+}
+
+// WithWebhookHTTPClient sets the HTTP client used for deliveries. If its Transport is
+// nil or an *http.Transport, the hub uses a copy whose dialer enforces the destination
+// policy; a client with any other Transport must enforce it itself.
+func WithWebhookHTTPClient(client *http.Client) HubOption {
+	return func(h *WebhookHub) {
+		h.httpClient = client
+	}
+}
+
+// WithDestinationPolicy sets which addresses subscription URLs may point at, in place
+// of PublicDestination. The policy is checked against every address a subscription's
+// host resolves to when it subscribes, and again against the address each delivery
+// connects to, since DNS may answer differently later.
+//
+// Example usage, allowing a private network of internal receivers:
+//
+//	internal := netip.MustParsePrefix("10.20.0.0/16")
+//	hub := server.NewWebhookHub(client, server.WithDestinationPolicy(func(ip netip.Addr) bool {
+//	    return internal.Contains(ip) || server.PublicDestination(ip)
+//	}))
+func WithDestinationPolicy(allow func(netip.Addr) bool) HubOption {
+	return func(h *WebhookHub) {
+		h.allow = allow
+	}
+}
+
+// PublicDestination is the default destination policy. It rejects loopback, private
+// (RFC 1918 and unique local), link-local, multicast and unspecified addresses, so
+// that subscribers cannot make the proxy send requests into its own network, such as
+// to a cloud metadata service at 169.254.169.254.
+func PublicDestination(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// NewWebhookHub creates a hub polling client. Call Run, or Poll on a schedule, to
+// deliver events, and mount it on a Server with WithWebhooks.
+func NewWebhookHub(client *gonpi.Client, opts ...HubOption) *WebhookHub {
+	h := &WebhookHub{
+		client:        client,
+		attempts:      4,
+		retryDelay:    time.Second,
+		allow:         PublicDestination,
+		subscriptions: make(map[string]*subscription),
+	}
+	h.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.httpClient = h.deliveryClient(h.httpClient)
+	return h
+}
+
+// deliveryClient returns a copy of client, or a default client if it is nil, whose
+// transport refuses to connect to addresses outside the destination policy. Proxies
+// are not used by the default client, since the check would then apply to the proxy.
+func (h *WebhookHub) deliveryClient(client *http.Client) *http.Client {
+	var transport *http.Transport
+	if client == nil {
+		client = &http.Client{Timeout: gonpi.DefaultTimeout}
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil
+	} else {
+		copied := *client
+		client = &copied
+		switch t := client.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			return client
+		}
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: h.checkDial}
+	transport.DialContext = dialer.DialContext
+	client.Transport = transport
+	return client
+}
+
+// checkDial is the delivery dialer's Control hook, refusing connections to addresses
+// outside the destination policy.
+func (h *WebhookHub) checkDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !h.allow(ip) {
+		return fmt.Errorf("%w: %s", ErrDestinationNotAllowed, host)
+	}
+	return nil
+}
+
+// checkDestination reports a ValidationError unless every address host resolves to
+// is allowed by the destination policy.
+func (h *WebhookHub) checkDestination(host string) error {
+	var ips []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		ips = append(ips, ip)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if ips, err = h.lookup(ctx, host); err != nil || len(ips) == 0 {
+			return &gonpi.ValidationError{Field: "url", Message: fmt.Sprintf("url host %q does not resolve", host)}
+		}
+	}
+	for _, ip := range ips {
+		if !h.allow(ip) {
+			return &gonpi.ValidationError{Field: "url", Message: fmt.Sprintf("url host %q resolves to %s, which is not an allowed destination", host, ip)}
+		}
+	}
+	return nil
+}
+
+// Subscribe registers rawURL to receive events for npis. If secret is empty a random
+// one is generated. The returned Subscription includes the secret. URLs whose host
+// resolves to an address outside the destination policy (see WithDestinationPolicy)
+// are rejected with a ValidationError.
+func (h *WebhookHub) Subscribe(rawURL string, npis []string, secret string) (Subscription, error) {
+	return h.subscribe(owner{}, rawURL, npis, secret)
+}
+
+// Unsubscribe removes the subscription with id, returning ErrSubscriptionNotFound if
+// there is none.
+func (h *WebhookHub) Unsubscribe(id string) error {
+	return h.unsubscribe(owner{}, id)
+}
+
+// Subscription returns the subscription with id, without its secret.
+func (h *WebhookHub) Subscription(id string) (Subscription, error) {
+	return h.subscription(owner{}, id)
+}
+
+// Subscriptions returns every subscription, without secrets, oldest first.
+func (h *WebhookHub) Subscriptions() []Subscription {
+	return h.subscriptionsOf(owner{})
+}
+
+// owner identifies the API key making a subscription request. The zero owner, used
+// without WithAPIKeys and by the exported WebhookHub methods, is unscoped and can see
+// every subscription.
+type owner struct {
+	scoped        bool
+	keyID, tenant string
+}
+
+// owns reports whether o may see and remove sub.
+func (o owner) owns(sub *subscription) bool {
+	switch {
+	case !o.scoped:
+		return true
+	case sub.Tenant != "":
+		return sub.Tenant == o.tenant
+	}
+	return sub.KeyID == o.keyID
+}
+
+// subscribe registers a subscription on behalf of o.
+func (h *WebhookHub) subscribe(o owner, rawURL string, npis []string, secret string) (Subscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, &gonpi.ValidationError{Field: "url", Message: "url must be an absolute http or https URL"}
+	}
+	if len(npis) == 0 {
+		return Subscription{}, &gonpi.ValidationError{Field: "npis", Message: "npi list cannot be empty"}
+	}
+	if err := h.checkDestination(u.Hostname()); err != nil {
+		return Subscription{}, err
+	}
+	normalized := make([]string, len(npis))
+	for i, npi := range npis {
+		if normalized[i], err = gonpi.NormalizeNPI(npi); err != nil {
+			return Subscription{}, err
+		}
+	}
+	if secret == "" {
+		secret = randomHex(32)
+	}
+
+	sub := &subscription{Subscription: Subscription{
+		ID:      randomHex(8),
+		URL:     u.String(),
+		NPIs:    normalized,
+		Created: time.Now().UTC(),
+		KeyID:   o.keyID,
+		Tenant:  o.tenant,
+		Secret:  secret,
+	}}
+	sub.watcher = h.client.NewWatcher(normalized, h.publisher(sub.Subscription))
+
+	h.mu.Lock()
+	h.subscriptions[sub.ID] = sub
+	h.mu.Unlock()
+	return sub.Subscription, nil
+}
+
+// unsubscribe removes the subscription with id if o owns it. Subscriptions owned by
+// others are reported as not found, so their IDs are not disclosed.
+func (h *WebhookHub) unsubscribe(o owner, id string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sub, ok := h.subscriptions[id]; !ok || !o.owns(sub) {
+		return ErrSubscriptionNotFound
+	}
+	delete(h.subscriptions, id)
+	return nil
+}
+
+// subscription returns the subscription with id if o owns it.
+func (h *WebhookHub) subscription(o owner, id string) (Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sub, ok := h.subscriptions[id]
+	if !ok || !o.owns(sub) {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+	return sub.public(), nil
+}
+
+// subscriptionsOf returns the subscriptions o owns, oldest first.
+func (h *WebhookHub) subscriptionsOf(o owner) []Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := make([]Subscription, 0, len(h.subscriptions))
+	for _, sub := range h.subscriptions {
+		if o.owns(sub) {
+			subs = append(subs, sub.public())
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].Created.Equal(subs[j].Created) {
+			return subs[i].Created.Before(subs[j].Created)
+		}
+		return subs[i].ID < subs[j].ID
+	})
+	return subs
+}
+
+// public returns the subscription without its secret.
+func (s *subscription) public() Subscription {
+	sub := s.Subscription
+	sub.Secret = ""
+	sub.NPIs = append([]string(nil), sub.NPIs...)
+	return sub
+}
+
+// Poll polls every subscription once and delivers its events. It returns the lookup
+// and delivery errors joined.
+func (h *WebhookHub) Poll(ctx context.Context) error {
+	h.mu.Lock()
+	watchers := make([]*gonpi.Watcher, 0, len(h.subscriptions))
+	for _, sub := range h.subscriptions {
+		watchers = append(watchers, sub.watcher)
+	}
+	h.mu.Unlock()
+
+	start := time.Now()
+	var errs []error
+	for _, watcher := range watchers {
+		if _, err := watcher.Poll(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err := errors.Join(errs...)
+
+	h.mu.Lock()
+	h.polls++
+	h.lastPoll, h.lastPollTook, h.lastPollErr = start, time.Since(start), err
+	h.mu.Unlock()
+	return err
+}
+
+// Status returns the number of subscriptions, the outcome of the last poll and the
+// number of dead letters so far.
+func (h *WebhookHub) Status() HubStatus {
+	h.mu.Lock()
+	status := HubStatus{
+		Subscriptions:    len(h.subscriptions),
+		Polls:            h.polls,
+		LastPoll:         h.lastPoll,
+		LastPollDuration: h.lastPollTook,
+	}
+	if h.lastPollErr != nil {
+		status.LastError = h.lastPollErr.Error()
+	}
+	h.mu.Unlock()
+
+	h.deadLetterMu.Lock()
+	status.DeadLetters = h.deadLetters
+	h.deadLetterMu.Unlock()
+	return status
+}
+
+// Run polls every interval until ctx is cancelled, passing poll errors to onError if
+// it is not nil. It returns ctx.Err().
+func (h *WebhookHub) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := h.Poll(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// publisher returns the publisher delivering events to sub, retrying transient
+// failures and dead-lettering events that cannot be delivered.
+func (h *WebhookHub) publisher(sub Subscription) gonpi.Publisher {
+	webhook := &gonpi.WebhookPublisher{URL: sub.URL, Secret: []byte(sub.Secret), HTTPClient: h.httpClient}
+	return gonpi.PublisherFunc(func(ctx context.Context, event gonpi.ChangeEvent) error {
+		delay := h.retryDelay
+		var err error
+		attempt := 1
+		for ; ; attempt++ {
+			if err = webhook.Publish(ctx, event); err == nil {
+				return nil
+			}
+			if attempt >= h.attempts || !gonpi.IsRetryable(err) || ctx.Err() != nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		h.writeDeadLetter(DeadLetter{
+			Time:         time.Now().UTC(),
+			Subscription: sub.ID,
+			URL:          sub.URL,
+			Attempts:     attempt,
+			Error:        err.Error(),
+			Event:        event,
+		})
+		return fmt.Errorf("webhook %s: %w", sub.ID, err)
+	})
+}
+
+// writeDeadLetter counts letter and appends it to the dead-letter log.
+func (h *WebhookHub) writeDeadLetter(letter DeadLetter) {
+	h.deadLetterMu.Lock()
+	defer h.deadLetterMu.Unlock()
+	h.deadLetters++
+	if h.deadLetter == nil {
+		return
+	}
+	json.NewEncoder(h.deadLetter).Encode(letter)
+}
+
+// randomHex returns n random bytes, hex-encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// subscribeRequest is the body of a subscription request.
+type subscribeRequest struct {
+	URL    string   `json:"url"`
+	NPIs   []string `json:"npis"`
+	Secret string   `json:"secret"`
+}
+
+// SubscriptionsResponse is the body returned by the subscription list endpoint.
+type SubscriptionsResponse struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// maxRequestBody bounds request bodies read by the server.
+const maxRequestBody = 1 << 20
+
+// owner returns the API key making r, or the zero owner without WithAPIKeys.
+func (s *Server) owner(r *http.Request) owner {
+	if s.keys == nil {
+		return owner{}
+	}
+	md, _ := gonpi.CallMetadataFromContext(r.Context())
+	return owner{scoped: true, keyID: md.Caller, tenant: md.Tenant}
+}
+
+func (s *Server) handleSubscribe(w http.ResponseWriter, r *http.Request) {
+	var req subscribeRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBody)).Decode(&req); err != nil {
+		writeError(w, &gonpi.ValidationError{Field: "body", Message: "invalid request body: " + err.Error()})
+		return
+	}
+	sub, err := s.webhooks.subscribe(s.owner(r), req.URL, req.NPIs, req.Secret)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sub)
+}
+
+func (s *Server) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, SubscriptionsResponse{Subscriptions: s.webhooks.subscriptionsOf(s.owner(r))})
+}
+
+func (s *Server) handleGetSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, err := s.webhooks.subscription(s.owner(r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+func (s *Server) handleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := s.webhooks.unsubscribe(s.owner(r), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sdsvn/gonpi"
+)
+
+// newWebhookServer returns a server with webhooks enabled, and its hub.
+func newWebhookServer(t *testing.T, opts ...HubOption) (*Server, *WebhookHub) {
+	t.Helper()
+	upstream := newUpstream(t)
+	t.Cleanup(upstream.Close)
+	hub := newTestHub(gonpi.NewClient(gonpi.WithBaseURL(upstream.URL)), opts...)
+	return New(hub.client, WithWebhooks(hub)), hub
+}
+
+// newTestHub returns a hub whose subscription hosts resolve without DNS, to a public
+// documentation address, or to 127.0.0.1 for localhost.
+func newTestHub(client *gonpi.Client, opts ...HubOption) *WebhookHub {
+	hub := NewWebhookHub(client, opts...)
+	hub.lookup = func(_ context.Context, host string) ([]netip.Addr, error) {
+		if host == "localhost" {
+			return []netip.Addr{netip.MustParseAddr("127.0.0.1")}, nil
+		}
+		return []netip.Addr{netip.MustParseAddr("203.0.113.10")}, nil
+	}
+	return hub
+}
+
+// allowAll is a destination policy allowing the test receivers on loopback.
+func allowAll(netip.Addr) bool { return true }
+
+func do(s *Server, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+// TestWebhooks_Endpoints tests subscribing, listing and unsubscribing over HTTP.
+func TestWebhooks_Endpoints(t *testing.T) {
+	s, _ := newWebhookServer(t)
+
+	rec := do(s, http.MethodPost, "/v1/webhooks", `{"url":"https://example.com/hook","npis":["1234-567-890"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created Subscription
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.ID == "" || created.Secret == "" || created.NPIs[0] != "1234567890" {
+		t.Errorf("unexpected subscription: %+v", created)
+	}
+
+	rec = do(s, http.MethodGet, "/v1/webhooks", "")
+	var list SubscriptionsResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list.Subscriptions) != 1 || list.Subscriptions[0].Secret != "" {
+		t.Errorf("expected one subscription without its secret, got %+v", list)
+	}
+	if rec := do(s, http.MethodGet, "/v1/webhooks/"+created.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+
+	for _, body := range []string{`{"url":"ftp://x","npis":["1234567890"]}`, `{"url":"https://x","npis":[]}`, `{"url":"https://x","npis":["12"]}`, `nope`} {
+		if rec := do(s, http.MethodPost, "/v1/webhooks", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, rec.Code)
+		}
+	}
+
+	if rec := do(s, http.MethodDelete, "/v1/webhooks/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+	if rec := do(s, http.MethodGet, "/v1/webhooks/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after unsubscribing, got %d", rec.Code)
+	}
+
+	// Without WithWebhooks the endpoints are not served
+	if rec := do(newTestServer(t), http.MethodGet, "/v1/webhooks", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without webhooks, got %d", rec.Code)
+	}
+}
+
+// TestWebhooks_ScopedToKey tests that with API keys each tenant, or each key without
+// a tenant, sees and removes only its own subscriptions.
+func TestWebhooks_ScopedToKey(t *testing.T) {
+	upstream := newUpstream(t)
+	t.Cleanup(upstream.Close)
+	hub := newTestHub(gonpi.NewClient(gonpi.WithBaseURL(upstream.URL)))
+	s := New(hub.client, WithWebhooks(hub), WithAPIKeys(StaticKeyStore{
+		"secret-a1": {ID: "key-a1", Tenant: "acme"},
+		"secret-a2": {ID: "key-a2", Tenant: "acme"},
+		"secret-b":  {ID: "key-b", Tenant: "globex"},
+		"secret-c":  {ID: "key-c"},
+		"secret-d":  {ID: "key-d"},
+	}))
+	request := func(secret, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", secret)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+	subscribe := func(secret string) Subscription {
+		rec := request(secret, http.MethodPost, "/v1/webhooks", `{"url":"https://example.com/hook","npis":["1234567893"]}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("%s: expected 201, got %d: %s", secret, rec.Code, rec.Body)
+		}
+		var sub Subscription
+		json.Unmarshal(rec.Body.Bytes(), &sub)
+		return sub
+	}
+	list := func(secret string) []Subscription {
+		var resp SubscriptionsResponse
+		json.Unmarshal(request(secret, http.MethodGet, "/v1/webhooks", "").Body.Bytes(), &resp)
+		return resp.Subscriptions
+	}
+
+	acme, globex, untenanted := subscribe("secret-a1"), subscribe("secret-b"), subscribe("secret-c")
+	if acme.KeyID != "key-a1" || acme.Tenant != "acme" {
+		t.Errorf("expected the subscription to record its key and tenant, got %+v", acme)
+	}
+
+	// Keys of one tenant share its subscriptions
+	if subs := list("secret-a2"); len(subs) != 1 || subs[0].ID != acme.ID {
+		t.Errorf("expected acme's subscription only, got %+v", subs)
+	}
+	if subs := list("secret-d"); len(subs) != 0 {
+		t.Errorf("expected no subscriptions for a tenantless key, got %+v", subs)
+	}
+	if subs := list("secret-c"); len(subs) != 1 || subs[0].ID != untenanted.ID {
+		t.Errorf("expected key-c's subscription only, got %+v", subs)
+	}
+
+	// Other tenants' subscriptions are not found
+	for _, path := range []string{"/v1/webhooks/" + acme.ID, "/v1/webhooks/" + untenanted.ID} {
+		if rec := request("secret-b", http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s as globex: expected 404, got %d", path, rec.Code)
+		}
+		if rec := request("secret-b", http.MethodDelete, path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("DELETE %s as globex: expected 404, got %d", path, rec.Code)
+		}
+	}
+	if rec := request("secret-a2", http.MethodDelete, "/v1/webhooks/"+acme.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected acme to unsubscribe, got %d", rec.Code)
+	}
+
+	// The hub itself is unscoped
+	if subs := hub.Subscriptions(); len(subs) != 2 || subs[0].ID != globex.ID && subs[1].ID != globex.ID {
+		t.Errorf("expected the hub to list every remaining subscription, got %+v", subs)
+	}
+}
+
+// TestWebhookHub_Deliver tests signed delivery, retries and dead-lettering.
+func TestWebhookHub_Deliver(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	var verifyErr error
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = gonpi.VerifyWebhook([]byte("s3cret"), r.Header, body, time.Minute)
+	}))
+	defer receiver.Close()
+
+	var deadLetters bytes.Buffer
+	_, hub := newWebhookServer(t, WithDeliveryRetries(2, time.Millisecond), WithDeadLetterLog(&deadLetters), WithDestinationPolicy(allowAll))
+	if _, err := hub.Subscribe(receiver.URL, []string{"1234567890"}, "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hub.Poll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	if calls != 2 || verifyErr != nil {
+		t.Errorf("expected a retried, verified delivery, got %d calls, %v", calls, verifyErr)
+	}
+	mu.Unlock()
+
+	// Permanent failures are dead-lettered without retries
+	gone := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer gone.Close()
+	sub, _ := hub.Subscribe(gone.URL, []string{"1234567890"}, "")
+	err := hub.Poll(context.Background())
+	var apiErr *gonpi.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusGone {
+		t.Fatalf("expected delivery error, got %v", err)
+	}
+	var letter DeadLetter
+	if err := json.Unmarshal(deadLetters.Bytes(), &letter); err != nil {
+		t.Fatalf("invalid dead letter %q: %v", deadLetters.String(), err)
+	}
+	if letter.Subscription != sub.ID || letter.Attempts != 1 || letter.Event.Type != gonpi.EventProviderCreated {
+		t.Errorf("unexpected dead letter: %+v", letter)
+	}
+}
+
+// TestWebhookHub_DestinationPolicy tests that subscriptions and deliveries to internal
+// addresses are refused.
+func TestWebhookHub_DestinationPolicy(t *testing.T) {
+	_, hub := newWebhookServer(t)
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/admin",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"https://10.0.0.5/hook",
+		"https://192.168.1.1/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:172.16.0.1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		if _, err := hub.Subscribe(rawURL, []string{"1234567890"}, ""); !gonpi.IsValidation(err) {
+			t.Errorf("Subscribe(%s) = %v, want a ValidationError", rawURL, err)
+		}
+	}
+	if _, err := hub.Subscribe("https://example.com/hook", []string{"1234567890"}, ""); err != nil {
+		t.Errorf("public destination rejected: %v", err)
+	}
+
+	// A destination allowed at subscription is checked again when delivering
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+	var allowed atomic.Bool
+	allowed.Store(true)
+	_, hub = newWebhookServer(t, WithDeliveryRetries(0, 0), WithDestinationPolicy(func(netip.Addr) bool { return allowed.Load() }))
+	if _, err := hub.Subscribe(receiver.URL, []string{"1234567890"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	allowed.Store(false)
+	if err := hub.Poll(context.Background()); !errors.Is(err, ErrDestinationNotAllowed) {
+		t.Errorf("Poll = %v, want ErrDestinationNotAllowed", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("receiver got %d deliveries, want none", calls.Load())
+	}
+}
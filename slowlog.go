@@ -0,0 +1,117 @@
+package gonpi
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SlowRequest describes an API call that took longer than the threshold set with
+// WithSlowRequestLog. A call covers every attempt made for one lookup or search page,
+// including retries and the waits between them.
+type SlowRequest struct {
+	// Time is when the call started.
+	Time time.Time `json:"time"`
+
+	// URL is the request URL, with parameters redacted by WithAuditRedaction replaced.
+	URL string `json:"url"`
+
+	// Params are the query parameters, redacted like URL.
+	Params map[string]string `json:"params,omitempty"`
+
+	// Attempts is the number of HTTP requests made.
+	Attempts int `json:"attempts"`
+
+	// Status is the status code of the last response, or 0 if none was received.
+	Status int `json:"status"`
+
+	// ResponseBytes is the size of the last response body read.
+	ResponseBytes int64 `json:"response_bytes"`
+
+	// Duration is the time from the first attempt until the call returned.
+	Duration time.Duration `json:"duration_ns"`
+
+	// Error describes the failure of a call that did not succeed, with redacted query
+	// parameters replaced in any URL it names.
+	Error string `json:"error,omitempty"`
+
+	// Caller and Tenant come from the call's CallMetadata.
+	Caller string `json:"caller,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// WithSlowRequestLog reports every API call taking longer than threshold to report,
+// counts it as MetricSlowRequests and adds a "slow_request" event to its span, so that
+// search shapes the registry handles poorly stand out. report may be nil to keep only
+// the metric and span event. It runs on the request path and should not block.
+//
+// Example usage:
+//
+//	client := NewClient(WithSlowRequestLog(2*time.Second, func(r SlowRequest) {
+//	    slog.Warn("slow NPI registry call", "url", r.URL, "attempts", r.Attempts, "duration", r.Duration)
+//	}))
+func WithSlowRequestLog(threshold time.Duration, report func(SlowRequest)) ClientOption {
+	return func(c *Client) {
+		c.slowThreshold = threshold
+		c.slowReport = report
+	}
+}
+
+// responseInfo describes the last response received by doRequest.
+type responseInfo struct {
+	status int
+	bytes  int64
+}
+
+// checkSlow reports the call to rawURL if it ran past the slow request threshold.
+func (c *Client) checkSlow(ctx context.Context, span trace.Span, rawURL string, start time.Time, attempts int, info responseInfo, err error) {
+	duration := time.Since(start)
+	if c.slowThreshold <= 0 || duration < c.slowThreshold {
+		return
+	}
+	c.increment(MetricSlowRequests)
+	span.AddEvent("slow_request", trace.WithAttributes(c.traceAttrs(
+		attribute.Int("attempts", attempts),
+		attribute.Int64("response_bytes", info.bytes),
+		attribute.Int64("duration_ms", duration.Milliseconds()),
+	)...))
+	if c.slowReport == nil {
+		return
+	}
+
+	record := SlowRequest{
+		Time:          start,
+		URL:           rawURL,
+		Attempts:      attempts,
+		Status:        info.status,
+		ResponseBytes: info.bytes,
+		Duration:      duration,
+	}
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		record.URL, record.Params = c.redactURL(u)
+	}
+	if err != nil {
+		record.Error = c.redactError(err).Error()
+	}
+	if md, ok := CallMetadataFromContext(ctx); ok {
+		record.Caller = md.Caller
+		record.Tenant = md.Tenant
+	}
+	c.slowReport(record)
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	*r.n += int64(n)
+	return n, err
+}
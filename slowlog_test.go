@@ -0,0 +1,85 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWithSlowRequestLog tests reporting calls slower than the threshold, across
+// retries, with redacted parameters.
+func TestWithSlowRequestLog(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("last_name") == "Slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	var reports []SlowRequest
+	sink := newRecordingSink()
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}),
+		WithStatsSink(sink),
+		WithAuditRedaction("first_name"),
+		WithSlowRequestLog(20*time.Millisecond, func(r SlowRequest) { reports = append(reports, r) }),
+	)
+	ctx := ContextWithCallMetadata(context.Background(), CallMetadata{Caller: "extract"})
+
+	if _, err := client.SearchProviders(ctx, SearchOptions{FirstName: "Jane", LastName: "Slow"}); err != nil {
+		t.Fatalf("SearchProviders: %v", err)
+	}
+	if _, err := client.SearchProviders(ctx, SearchOptions{LastName: "Fast"}); err != nil {
+		t.Fatalf("SearchProviders: %v", err)
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 slow request, got %+v", reports)
+	}
+	r := reports[0]
+	if r.Attempts != 2 || r.Status != http.StatusOK || r.ResponseBytes == 0 || r.Duration < 20*time.Millisecond || r.Caller != "extract" {
+		t.Errorf("unexpected report: %+v", r)
+	}
+	if r.Params["last_name"] != "Slow" || r.Params["first_name"] != RedactedValue || strings.Contains(r.URL, "Jane") {
+		t.Errorf("unexpected params: %s %v", r.URL, r.Params)
+	}
+	if sink.counters[MetricSlowRequests] != 1 {
+		t.Errorf("expected 1 %s, got %d", MetricSlowRequests, sink.counters[MetricSlowRequests])
+	}
+}
+
+// TestWithSlowRequestLog_Error tests that failed slow calls do not leak redacted
+// parameters in their errors.
+func TestWithSlowRequestLog_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	var reports []SlowRequest
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRetry(RetryConfig{}),
+		WithAuditRedaction("first_name"),
+		WithSlowRequestLog(time.Nanosecond, func(r SlowRequest) { reports = append(reports, r) }),
+	)
+	if _, err := client.SearchProviders(context.Background(), SearchOptions{FirstName: "Jane", LastName: "Doe"}); err == nil {
+		t.Fatal("expected transport error")
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 slow request, got %+v", reports)
+	}
+	if r := reports[0]; r.Error == "" || strings.Contains(r.Error, "Jane") {
+		t.Errorf("error not redacted: %q", r.Error)
+	}
+}
@@ -0,0 +1,154 @@
+package gonpi
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// snapshotFormat identifies MemoryStore snapshot streams.
+const snapshotFormat = "gonpi-store-snapshot"
+
+// SnapshotVersion is the version of the snapshot encoding written by Snapshot.
+const SnapshotVersion = 1
+
+// ErrInvalidSnapshot indicates that Restore was given data that is not a readable
+// MemoryStore snapshot.
+var ErrInvalidSnapshot = errors.New("invalid store snapshot")
+
+// SnapshotHeader is the first record of a snapshot stream.
+type SnapshotHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Count     int       `json:"count"`
+}
+
+// Snapshot writes every stored provider to w as a gzip-compressed stream of JSON lines:
+// a SnapshotHeader followed by one provider per line, ordered by NPI. Indexes are not
+// written; Restore rebuilds them. Writers are blocked while the snapshot is taken.
+func (s *MemoryStore) Snapshot(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	npis := make([]string, 0, len(s.providers))
+	for npi := range s.providers {
+		npis = append(npis, npi)
+	}
+	sort.Strings(npis)
+
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	header := SnapshotHeader{
+		Format:    snapshotFormat,
+		Version:   SnapshotVersion,
+		CreatedAt: time.Now().UTC(),
+		Count:     len(npis),
+	}
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+	for _, npi := range npis {
+		if err := enc.Encode(s.providers[npi]); err != nil {
+			return fmt.Errorf("failed to write provider %s: %w", npi, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the store's contents with the snapshot read from r and rebuilds
+// every index. The snapshot is read completely before anything is replaced, so a
+// failed restore leaves the store unchanged. Malformed input is reported as
+// ErrInvalidSnapshot.
+func (s *MemoryStore) Restore(r io.Reader) error {
+	header, providers, err := readSnapshot(r)
+	if err != nil {
+		return err
+	}
+	if len(providers) != header.Count {
+		return fmt.Errorf("%w: header lists %d providers, found %d", ErrInvalidSnapshot, header.Count, len(providers))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.providers = providers
+	s.indexes = make(map[string]map[string]map[string]struct{})
+	s.keys = make(map[string]map[string][]string)
+	s.peak = len(providers)
+	for _, provider := range s.providers {
+		for _, idx := range s.defs {
+			s.index(idx, provider)
+		}
+	}
+	return nil
+}
+
+// ReadSnapshotHeader returns the header of the snapshot in r without reading the
+// providers.
+func ReadSnapshotHeader(r io.Reader) (SnapshotHeader, error) {
+	dec, closeFn, err := snapshotDecoder(r)
+	if err != nil {
+		return SnapshotHeader{}, err
+	}
+	defer closeFn()
+	return decodeSnapshotHeader(dec)
+}
+
+func readSnapshot(r io.Reader) (SnapshotHeader, map[string]*Provider, error) {
+	dec, closeFn, err := snapshotDecoder(r)
+	if err != nil {
+		return SnapshotHeader{}, nil, err
+	}
+	defer closeFn()
+
+	header, err := decodeSnapshotHeader(dec)
+	if err != nil {
+		return header, nil, err
+	}
+
+	providers := make(map[string]*Provider, header.Count)
+	for {
+		var provider Provider
+		if err := dec.Decode(&provider); err == io.EOF {
+			break
+		} else if err != nil {
+			return header, nil, fmt.Errorf("%w: record %d: %v", ErrInvalidSnapshot, len(providers)+1, err)
+		}
+		if provider.Number == "" {
+			return header, nil, fmt.Errorf("%w: record %d has no NPI", ErrInvalidSnapshot, len(providers)+1)
+		}
+		providers[provider.Number] = &provider
+	}
+	return header, providers, nil
+}
+
+func snapshotDecoder(r io.Reader) (*json.Decoder, func() error, error) {
+	zr, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	return json.NewDecoder(zr), zr.Close, nil
+}
+
+func decodeSnapshotHeader(dec *json.Decoder) (SnapshotHeader, error) {
+	var header SnapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return header, fmt.Errorf("%w: header: %v", ErrInvalidSnapshot, err)
+	}
+	if header.Format != snapshotFormat {
+		return header, fmt.Errorf("%w: unexpected format %q", ErrInvalidSnapshot, header.Format)
+	}
+	if header.Version > SnapshotVersion {
+		return header, fmt.Errorf("%w: version %d is newer than supported version %d", ErrInvalidSnapshot, header.Version, SnapshotVersion)
+	}
+	return header, nil
+}
@@ -0,0 +1,83 @@
+package gonpi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestMemoryStore_SnapshotRestore tests that a snapshot round-trips records and indexes.
+func TestMemoryStore_SnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	source := NewMemoryStore()
+	for _, number := range []string{"2222222222", "1111111111"} {
+		p := mockProvider()
+		p.Number = number
+		p.Addresses = []Address{{AddressPurpose: "LOCATION", TelephoneNumber: "617-555-0100"}}
+		source.Put(ctx, p)
+	}
+
+	var buf bytes.Buffer
+	if err := source.Snapshot(&buf); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	header, err := ReadSnapshotHeader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	if header.Count != 2 || header.Version != SnapshotVersion {
+		t.Errorf("unexpected header: %+v", header)
+	}
+
+	target := NewMemoryStore()
+	target.Put(ctx, Provider{Number: "9999999999"})
+	if err := target.Restore(&buf); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if target.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", target.Len())
+	}
+	if p, _ := target.Get(ctx, "9999999999"); p != nil {
+		t.Error("restore should replace existing contents")
+	}
+	found, _ := target.Lookup(ctx, IndexPhone, "6175550100")
+	if len(found) != 2 {
+		t.Errorf("phone index not rebuilt: %d results", len(found))
+	}
+}
+
+// TestMemoryStore_RestoreInvalid tests that bad snapshots leave the store unchanged.
+func TestMemoryStore_RestoreInvalid(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	store.Put(ctx, mockProvider())
+
+	gz := func(s string) *bytes.Buffer {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+		return &buf
+	}
+
+	inputs := map[string]*bytes.Buffer{
+		"not gzip":      bytes.NewBufferString("plain text"),
+		"wrong format":  gz(`{"format":"other","version":1}` + "\n"),
+		"future":        gz(`{"format":"gonpi-store-snapshot","version":99}` + "\n"),
+		"count":         gz(`{"format":"gonpi-store-snapshot","version":1,"count":2}` + "\n" + `{"number":"1"}` + "\n"),
+		"missing npi":   gz(`{"format":"gonpi-store-snapshot","version":1,"count":1}` + "\n" + `{"number":""}` + "\n"),
+		"corrupt entry": gz(`{"format":"gonpi-store-snapshot","version":1,"count":1}` + "\n" + strings.Repeat("{", 3)),
+	}
+	for name, input := range inputs {
+		if err := store.Restore(input); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("%s: expected ErrInvalidSnapshot, got %v", name, err)
+		}
+	}
+	if store.Len() != 1 {
+		t.Errorf("failed restore changed the store: Len() = %d", store.Len())
+	}
+}
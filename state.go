@@ -0,0 +1,246 @@
+package gonpi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrStateNotFound is returned by StateStore.Get for a key that has no value.
+var ErrStateNotFound = errors.New("state not found")
+
+// StateStore persists the small pieces of state that long-running features need to
+// resume after a restart, such as a Watcher's snapshots, a page cursor or the last
+// NPPES file applied. Values are opaque and replaced as a whole. Implementations must
+// be safe for concurrent use.
+type StateStore interface {
+	// Get returns the value stored under key, or ErrStateNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores value under key, replacing any previous value.
+	Put(ctx context.Context, key string, value []byte) error
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStateStore is a StateStore held in memory, for tests and single-process use.
+type MemoryStateStore struct {
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+// NewMemoryStateStore creates an empty MemoryStateStore.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{values: make(map[string][]byte)}
+}
+
+// Get implements StateStore.
+func (s *MemoryStateStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Put implements StateStore.
+func (s *MemoryStateStore) Put(_ context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete implements StateStore.
+func (s *MemoryStateStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// FileStateStore is a StateStore keeping one file per key in a directory. Values are
+// written to a temporary file and renamed into place, so a crash never leaves a
+// partial value.
+type FileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore creates a FileStateStore in dir, creating the directory if needed.
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+// path returns the file holding key. Keys are escaped so that any key, including
+// one with slashes, maps to a single file in the directory.
+func (s *FileStateStore) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key)+".state")
+}
+
+// Get implements StateStore.
+func (s *FileStateStore) Get(_ context.Context, key string) ([]byte, error) {
+	value, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrStateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// Put implements StateStore.
+func (s *FileStateStore) Put(_ context.Context, key string, value []byte) error {
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state %q: %w", key, err)
+	}
+	_, err = f.Write(value)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err = errors.Join(err, f.Close()); err == nil {
+		err = os.Rename(f.Name(), s.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write state %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete implements StateStore.
+func (s *FileStateStore) Delete(_ context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete state %q: %w", key, err)
+	}
+	return nil
+}
+
+// SQLDialect selects the placeholder and upsert syntax of a SQLStateStore.
+type SQLDialect int
+
+const (
+	// DialectSQLite uses ? placeholders and INSERT ... ON CONFLICT.
+	DialectSQLite SQLDialect = iota
+
+	// DialectPostgres uses $n placeholders and INSERT ... ON CONFLICT.
+	DialectPostgres
+
+	// DialectMySQL uses ? placeholders and INSERT ... ON DUPLICATE KEY UPDATE.
+	DialectMySQL
+)
+
+// DefaultStateTable is the table a SQLStateStore uses unless another is given.
+const DefaultStateTable = "gonpi_state"
+
+// validTable matches table names that are safe to interpolate into statements.
+var validTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLStateStore is a StateStore keeping each key in a row of a SQL table, for
+// deployments in containers without durable local disk. The caller registers the
+// database driver and owns db.
+//
+// Example usage:
+//
+//	db, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+//	state, err := gonpi.NewSQLStateStore(db, gonpi.DialectPostgres, "")
+//	err = state.CreateTable(ctx)
+type SQLStateStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+	table   string
+}
+
+// NewSQLStateStore creates a SQLStateStore on db using table, or DefaultStateTable if
+// table is empty. Table names are limited to letters, digits and underscores, with an
+// optional schema prefix.
+func NewSQLStateStore(db *sql.DB, dialect SQLDialect, table string) (*SQLStateStore, error) {
+	if table == "" {
+		table = DefaultStateTable
+	}
+	if !validTable.MatchString(table) {
+		return nil, &ValidationError{Field: "table", Message: fmt.Sprintf("invalid table name %q", table)}
+	}
+	if dialect < DialectSQLite || dialect > DialectMySQL {
+		return nil, &ValidationError{Field: "dialect", Message: fmt.Sprintf("unknown SQL dialect %d", dialect)}
+	}
+	return &SQLStateStore{db: db, dialect: dialect, table: table}, nil
+}
+
+// CreateTable creates the store's table if it does not exist.
+func (s *SQLStateStore) CreateTable(ctx context.Context) error {
+	keyType, valueType := "TEXT", "BLOB"
+	switch s.dialect {
+	case DialectPostgres:
+		valueType = "BYTEA"
+	case DialectMySQL:
+		// MySQL cannot index unbounded text columns
+		keyType, valueType = "VARCHAR(255)", "LONGBLOB"
+	}
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (state_key %s PRIMARY KEY, state_value %s NOT NULL)", s.table, keyType, valueType)
+	if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+		return fmt.Errorf("failed to create state table: %w", err)
+	}
+	return nil
+}
+
+// placeholder returns the n-th statement placeholder, numbered from 1, in the store's
+// dialect.
+func (s *SQLStateStore) placeholder(n int) string {
+	if s.dialect == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Get implements StateStore.
+func (s *SQLStateStore) Get(ctx context.Context, key string) ([]byte, error) {
+	stmt := fmt.Sprintf("SELECT state_value FROM %s WHERE state_key = %s", s.table, s.placeholder(1))
+	var value []byte
+	err := s.db.QueryRowContext(ctx, stmt, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// Put implements StateStore.
+func (s *SQLStateStore) Put(ctx context.Context, key string, value []byte) error {
+	var stmt strings.Builder
+	fmt.Fprintf(&stmt, "INSERT INTO %s (state_key, state_value) VALUES (%s, %s)", s.table, s.placeholder(1), s.placeholder(2))
+	if s.dialect == DialectMySQL {
+		stmt.WriteString(" ON DUPLICATE KEY UPDATE state_value = VALUES(state_value)")
+	} else {
+		stmt.WriteString(" ON CONFLICT (state_key) DO UPDATE SET state_value = excluded.state_value")
+	}
+	if _, err := s.db.ExecContext(ctx, stmt.String(), key, value); err != nil {
+		return fmt.Errorf("failed to write state %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete implements StateStore.
+func (s *SQLStateStore) Delete(ctx context.Context, key string) error {
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE state_key = %s", s.table, s.placeholder(1))
+	if _, err := s.db.ExecContext(ctx, stmt, key); err != nil {
+		return fmt.Errorf("failed to delete state %q: %w", key, err)
+	}
+	return nil
+}
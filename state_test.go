@@ -0,0 +1,229 @@
+package gonpi
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testStateStore runs the StateStore contract against store.
+func testStateStore(t *testing.T, store StateStore) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := store.Get(ctx, "watch/roster"); !errors.Is(err, ErrStateNotFound) {
+		t.Fatalf("expected ErrStateNotFound, got %v", err)
+	}
+	if err := store.Put(ctx, "watch/roster", []byte("one")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, "watch/roster", []byte("two")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, "cursor", []byte("abc")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if value, err := store.Get(ctx, "watch/roster"); err != nil || string(value) != "two" {
+		t.Errorf("Get = %q, %v", value, err)
+	}
+	if err := store.Delete(ctx, "watch/roster"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, "watch/roster"); err != nil {
+		t.Errorf("deleting a missing key: %v", err)
+	}
+	if _, err := store.Get(ctx, "watch/roster"); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("expected ErrStateNotFound after Delete, got %v", err)
+	}
+	if value, err := store.Get(ctx, "cursor"); err != nil || string(value) != "abc" {
+		t.Errorf("other key: Get = %q, %v", value, err)
+	}
+}
+
+// TestMemoryStateStore tests the in-memory state store.
+func TestMemoryStateStore(t *testing.T) {
+	testStateStore(t, NewMemoryStateStore())
+}
+
+// TestFileStateStore tests the file state store.
+func TestFileStateStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStateStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStateStore(t, store)
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "cursor.state" {
+		t.Errorf("unexpected files: %v", entries)
+	}
+}
+
+// TestSQLStateStore tests the statements of each SQL dialect against a fake driver.
+func TestSQLStateStore(t *testing.T) {
+	for _, tt := range []struct {
+		dialect SQLDialect
+		upsert  string
+	}{
+		{DialectSQLite, "INSERT INTO gonpi_state (state_key, state_value) VALUES (?, ?) ON CONFLICT (state_key) DO UPDATE SET state_value = excluded.state_value"},
+		{DialectPostgres, "INSERT INTO gonpi_state (state_key, state_value) VALUES ($1, $2) ON CONFLICT (state_key) DO UPDATE SET state_value = excluded.state_value"},
+		{DialectMySQL, "INSERT INTO gonpi_state (state_key, state_value) VALUES (?, ?) ON DUPLICATE KEY UPDATE state_value = VALUES(state_value)"},
+	} {
+		db, conn := openFakeSQL(t)
+		store, err := NewSQLStateStore(db, tt.dialect, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateTable(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		testStateStore(t, store)
+		if !conn.executed(tt.upsert) {
+			t.Errorf("dialect %d: upsert not executed; statements:\n%s", tt.dialect, strings.Join(conn.statements, "\n"))
+		}
+	}
+
+	if _, err := NewSQLStateStore(nil, DialectPostgres, "state; DROP TABLE users"); err == nil {
+		t.Error("expected an invalid table name to be rejected")
+	}
+}
+
+// TestWatcher_Persist tests that a restarted watcher resumes from its saved state.
+func TestWatcher_Persist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+	client := NewClient(WithBaseURL(server.URL))
+	defer client.Close()
+	ctx := context.Background()
+	state := NewMemoryStateStore()
+
+	first := client.NewWatcher([]string{"1234567890"})
+	if err := first.Persist(ctx, state, "watch"); err != nil {
+		t.Fatal(err)
+	}
+	if events, err := first.Poll(ctx); err != nil || len(events) != 1 || events[0].Type != EventProviderCreated {
+		t.Fatalf("first poll: %+v, %v", events, err)
+	}
+
+	restarted := client.NewWatcher([]string{"1234567890"})
+	if err := restarted.Persist(ctx, state, "watch"); err != nil {
+		t.Fatal(err)
+	}
+	if events, err := restarted.Poll(ctx); err != nil || len(events) != 0 {
+		t.Errorf("restarted poll should report no changes: %+v, %v", events, err)
+	}
+
+	failing := client.NewWatcher([]string{"1234567890"}, PublisherFunc(func(context.Context, ChangeEvent) error {
+		return errors.New("broker down")
+	}))
+	if err := failing.Persist(ctx, state, "failing"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := failing.Poll(ctx); err == nil {
+		t.Fatal("expected publish error")
+	}
+	retried := client.NewWatcher([]string{"1234567890"})
+	if err := retried.Persist(ctx, state, "failing"); err != nil {
+		t.Fatal(err)
+	}
+	if events, err := retried.Poll(ctx); err != nil || len(events) != 1 || events[0].Type != EventProviderCreated {
+		t.Errorf("unpublished event should survive a restart: %+v, %v", events, err)
+	}
+
+	state.Put(ctx, "broken", []byte("not json"))
+	if err := client.NewWatcher(nil).Persist(ctx, state, "broken"); err == nil {
+		t.Error("expected corrupt state to fail")
+	}
+}
+
+// fakeConn is a database/sql driver connection keeping one key-value table in memory
+// and recording the statements it runs.
+type fakeConn struct {
+	mu         sync.Mutex
+	rows       map[string][]byte
+	statements []string
+}
+
+// openFakeSQL opens a database backed by a new fakeConn.
+func openFakeSQL(t *testing.T) (*sql.DB, *fakeConn) {
+	conn := &fakeConn{rows: make(map[string][]byte)}
+	db := sql.OpenDB(fakeConnector{conn})
+	t.Cleanup(func() { db.Close() })
+	return db, conn
+}
+
+func (c *fakeConn) executed(stmt string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.statements {
+		if s == stmt {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+	case strings.HasPrefix(query, "INSERT"):
+		c.rows[args[0].Value.(string)] = args[1].Value.([]byte)
+	case strings.HasPrefix(query, "DELETE"):
+		delete(c.rows, args[0].Value.(string))
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = append(c.statements, query)
+	value, ok := c.rows[args[0].Value.(string)]
+	return &fakeRows{value: value, done: !ok}, nil
+}
+
+type fakeConnector struct{ conn *fakeConn }
+
+func (f fakeConnector) Connect(context.Context) (driver.Conn, error) { return f.conn, nil }
+func (f fakeConnector) Driver() driver.Driver                        { return nil }
+
+// fakeRows holds at most one row with a single value.
+type fakeRows struct {
+	value []byte
+	done  bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"state_value"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
@@ -0,0 +1,150 @@
+package gonpi
+
+import (
+	"expvar"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metric names reported to a StatsSink.
+const (
+	// MetricRequests counts HTTP requests sent to the API, including retries.
+	MetricRequests = "requests"
+
+	// MetricRequestErrors counts HTTP requests that failed or returned a non-200 status.
+	MetricRequestErrors = "request_errors"
+
+	// MetricRequestDuration observes the duration of each HTTP request in milliseconds.
+	MetricRequestDuration = "request_duration_ms"
+
+	// MetricRetries counts retry attempts.
+	MetricRetries = "retries"
+
+	// MetricCacheHits counts NPI lookups served from the cache.
+	MetricCacheHits = "cache_hits"
+
+	// MetricCacheMisses counts NPI lookups that missed the cache.
+	MetricCacheMisses = "cache_misses"
+
+	// MetricSlowRequests counts API calls slower than the WithSlowRequestLog threshold.
+	MetricSlowRequests = "slow_requests"
+
+	// MetricConcurrencyLimit observes the WithAdaptiveConcurrency limit after each
+	// HTTP request.
+	MetricConcurrencyLimit = "concurrency_limit"
+)
+
+// StatsSink receives request, error and cache metrics from the client.
+// It is a minimal abstraction for metrics stacks without OpenTelemetry support.
+// Implementations must be safe for concurrent use.
+type StatsSink interface {
+	// Increment adds one to the named counter.
+	Increment(name string)
+
+	// Observe records a single measurement for the named metric.
+	Observe(name string, value float64)
+}
+
+// WithStatsSink sets a StatsSink that receives client metrics.
+//
+// Example usage:
+//
+//	client := NewClient(
+//	    WithStatsSink(NewExpvarSink("gonpi")),
+//	)
+func WithStatsSink(sink StatsSink) ClientOption {
+	return func(c *Client) {
+		c.stats = sink
+	}
+}
+
+// increment updates the status counters and reports a counter increment if a
+// StatsSink is configured.
+func (c *Client) increment(name string) {
+	if c.status != nil {
+		c.status.count(name)
+	}
+	if c.stats != nil {
+		c.stats.Increment(name)
+	}
+}
+
+// observe reports a measurement if a StatsSink is configured.
+func (c *Client) observe(name string, value float64) {
+	if c.stats != nil {
+		c.stats.Observe(name, value)
+	}
+}
+
+// ExpvarSink is a StatsSink that publishes metrics through the expvar package,
+// making them available at /debug/vars. Counters are exposed as integers and
+// observations as "<name>_count" and "<name>_sum" pairs.
+type ExpvarSink struct {
+	vars *expvar.Map
+}
+
+// NewExpvarSink creates an ExpvarSink publishing a map under the given name.
+// If a map with that name is already published it is reused, so multiple clients
+// can share a sink name.
+func NewExpvarSink(name string) *ExpvarSink {
+	if existing, ok := expvar.Get(name).(*expvar.Map); ok {
+		return &ExpvarSink{vars: existing}
+	}
+	return &ExpvarSink{vars: expvar.NewMap(name)}
+}
+
+// Increment implements StatsSink.
+func (s *ExpvarSink) Increment(name string) {
+	s.vars.Add(name, 1)
+}
+
+// Observe implements StatsSink.
+func (s *ExpvarSink) Observe(name string, value float64) {
+	s.vars.Add(name+"_count", 1)
+	s.vars.AddFloat(name+"_sum", value)
+}
+
+// StatsDSink is a StatsSink that sends metrics to a StatsD daemon over UDP.
+// Counters are sent as "|c" and observations as timers ("|ms").
+// Send errors are ignored so that metrics never affect API calls.
+type StatsDSink struct {
+	prefix string
+	conn   net.Conn
+	mu     sync.Mutex
+}
+
+// NewStatsDSink creates a StatsDSink sending to addr (for example "127.0.0.1:8125").
+// Metric names are prefixed with prefix followed by a dot when prefix is not empty.
+func NewStatsDSink(addr, prefix string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	return &StatsDSink{prefix: prefix, conn: conn}, nil
+}
+
+// Increment implements StatsSink.
+func (s *StatsDSink) Increment(name string) {
+	s.send(name, "1", "c")
+}
+
+// Observe implements StatsSink.
+func (s *StatsDSink) Observe(name string, value float64) {
+	s.send(name, strconv.FormatFloat(value, 'f', -1, 64), "ms")
+}
+
+// Close closes the underlying UDP connection.
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsDSink) send(name, value, kind string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.conn.Write([]byte(s.prefix + name + ":" + value + "|" + kind))
+}
@@ -0,0 +1,142 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink is a StatsSink that records everything it receives.
+type recordingSink struct {
+	mu           sync.Mutex
+	counters     map[string]int
+	observations map[string][]float64
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{
+		counters:     make(map[string]int),
+		observations: make(map[string][]float64),
+	}
+}
+
+func (s *recordingSink) Increment(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[name]++
+}
+
+func (s *recordingSink) Observe(name string, value float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observations[name] = append(s.observations[name], value)
+}
+
+// TestStatsSink_RequestAndCacheMetrics tests that the client reports metrics to the sink.
+func TestStatsSink_RequestAndCacheMetrics(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	sink := newRecordingSink()
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithCache(time.Minute),
+		WithStatsSink(sink),
+		WithRetry(RetryConfig{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}),
+	)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetProviderByNPI(context.Background(), "1234567890"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := map[string]int{
+		MetricRequests:      2,
+		MetricRequestErrors: 1,
+		MetricRetries:       1,
+		MetricCacheHits:     1,
+		MetricCacheMisses:   1,
+	}
+	for name, count := range want {
+		if sink.counters[name] != count {
+			t.Errorf("expected %s = %d, got %d", name, count, sink.counters[name])
+		}
+	}
+	if len(sink.observations[MetricRequestDuration]) != 2 {
+		t.Errorf("expected 2 duration observations, got %d", len(sink.observations[MetricRequestDuration]))
+	}
+}
+
+// TestExpvarSink tests that the expvar sink publishes counters and observations.
+func TestExpvarSink(t *testing.T) {
+	sink := NewExpvarSink("gonpi_test_stats")
+	sink.Increment(MetricRequests)
+	sink.Increment(MetricRequests)
+	sink.Observe(MetricRequestDuration, 1.5)
+
+	// Reusing the name must not panic and must share the map
+	NewExpvarSink("gonpi_test_stats").Observe(MetricRequestDuration, 2.5)
+
+	vars := expvar.Get("gonpi_test_stats").(*expvar.Map)
+	if got := vars.Get(MetricRequests).String(); got != "2" {
+		t.Errorf("expected requests = 2, got %s", got)
+	}
+	if got := vars.Get(MetricRequestDuration + "_count").String(); got != "2" {
+		t.Errorf("expected duration count = 2, got %s", got)
+	}
+	if got := vars.Get(MetricRequestDuration + "_sum").String(); got != "4" {
+		t.Errorf("expected duration sum = 4, got %s", got)
+	}
+}
+
+// TestStatsDSink tests the StatsD line protocol output.
+func TestStatsDSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp not available: %v", err)
+	}
+	defer conn.Close()
+
+	sink, err := NewStatsDSink(conn.LocalAddr().String(), "gonpi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer sink.Close()
+
+	sink.Increment(MetricCacheHits)
+	sink.Observe(MetricRequestDuration, 12.5)
+
+	var lines []string
+	buf := make([]byte, 512)
+	for i := 0; i < 2; i++ {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("failed to read packet: %v", err)
+		}
+		lines = append(lines, string(buf[:n]))
+	}
+
+	got := strings.Join(lines, "\n")
+	want := "gonpi.cache_hits:1|c\ngonpi.request_duration_ms:12.5|ms"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,183 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxRecentErrors is the number of failed requests kept for Client.Status.
+const maxRecentErrors = 20
+
+// ClientStatus is a point-in-time summary of a Client's health, for status pages and
+// triage. Counters cover the client and every client derived from it.
+type ClientStatus struct {
+	Time     time.Time      `json:"time"`
+	Upstream UpstreamStatus `json:"upstream"`
+
+	Requests    int64 `json:"requests"`
+	Errors      int64 `json:"errors"`
+	Retries     int64 `json:"retries"`
+	CacheHits   int64 `json:"cache_hits"`
+	CacheMisses int64 `json:"cache_misses"`
+
+	// CacheEntries is the number of providers in the in-memory cache, or -1 if
+	// caching is disabled.
+	CacheEntries int `json:"cache_entries"`
+
+	// RateLimit is nil if no rate limit is configured.
+	RateLimit *RateLimitStatus `json:"rate_limit,omitempty"`
+
+	// RecentErrors lists the most recent failed calls, newest first.
+	RecentErrors []RecentError `json:"recent_errors"`
+}
+
+// UpstreamStatus describes the health of the registry API as seen by the client.
+// Only transient failures, such as timeouts and 5xx responses, count against it.
+type UpstreamStatus struct {
+	BaseURL             string    `json:"base_url"`
+	Healthy             bool      `json:"healthy"`
+	LastSuccess         time.Time `json:"last_success,omitzero"`
+	LastFailure         time.Time `json:"last_failure,omitzero"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+}
+
+// RateLimitStatus describes the client's rate limiter.
+type RateLimitStatus struct {
+	Rate      float64 `json:"rate"`
+	Burst     int     `json:"burst"`
+	Available float64 `json:"available"`
+
+	// Utilization is the fraction of the burst in use. Above 1, requests are queued
+	// waiting for tokens.
+	Utilization float64 `json:"utilization"`
+}
+
+// RecentError is a failed call reported by Client.Status. URL, and any URL named in
+// Error, have parameters redacted by WithAuditRedaction replaced.
+type RecentError struct {
+	Time  time.Time `json:"time"`
+	URL   string    `json:"url"`
+	Error string    `json:"error"`
+}
+
+// statusTracker counts requests and remembers recent failures. It is shared with
+// derived clients.
+type statusTracker struct {
+	requests    atomic.Int64
+	errors      atomic.Int64
+	retries     atomic.Int64
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+
+	mu                  sync.Mutex
+	lastSuccess         time.Time
+	lastFailure         time.Time
+	consecutiveFailures int
+	recent              [maxRecentErrors]RecentError
+	next                int // index of the next slot in recent
+	filled              int // number of filled slots in recent
+}
+
+// count updates the counter behind a metric name, if any.
+func (t *statusTracker) count(name string) {
+	switch name {
+	case MetricRequests:
+		t.requests.Add(1)
+	case MetricRequestErrors:
+		t.errors.Add(1)
+	case MetricRetries:
+		t.retries.Add(1)
+	case MetricCacheHits:
+		t.cacheHits.Add(1)
+	case MetricCacheMisses:
+		t.cacheMisses.Add(1)
+	}
+}
+
+// recordCall records the outcome of a call to rawURL. Cancelled calls are ignored.
+func (c *Client) recordCall(rawURL string, err error) {
+	t := c.status
+	if t == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err == nil {
+		t.lastSuccess = now
+		t.consecutiveFailures = 0
+		return
+	}
+	if c.shouldRetry(err) || errors.Is(err, context.DeadlineExceeded) {
+		t.lastFailure = now
+		t.consecutiveFailures++
+	}
+
+	redacted := rawURL
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		redacted, _ = c.redactURL(u)
+	}
+	t.recent[t.next] = RecentError{Time: now, URL: redacted, Error: c.redactError(err).Error()}
+	t.next = (t.next + 1) % maxRecentErrors
+	t.filled = min(t.filled+1, maxRecentErrors)
+}
+
+// Status returns a summary of the client's upstream health, request counters, cache,
+// rate limiter and recent errors.
+func (c *Client) Status() ClientStatus {
+	status := ClientStatus{
+		Time:         time.Now(),
+		Upstream:     UpstreamStatus{BaseURL: c.baseURL, Healthy: true},
+		CacheEntries: -1,
+		RecentErrors: []RecentError{},
+	}
+
+	if t := c.status; t != nil {
+		status.Requests = t.requests.Load()
+		status.Errors = t.errors.Load()
+		status.Retries = t.retries.Load()
+		status.CacheHits = t.cacheHits.Load()
+		status.CacheMisses = t.cacheMisses.Load()
+
+		t.mu.Lock()
+		status.Upstream.LastSuccess = t.lastSuccess
+		status.Upstream.LastFailure = t.lastFailure
+		status.Upstream.ConsecutiveFailures = t.consecutiveFailures
+		status.Upstream.Healthy = t.consecutiveFailures == 0
+		for i := 1; i <= t.filled; i++ {
+			status.RecentErrors = append(status.RecentErrors, t.recent[(t.next-i+maxRecentErrors)%maxRecentErrors])
+		}
+		t.mu.Unlock()
+	}
+
+	if c.cache != nil && c.cache.enabled {
+		c.cache.mu.RLock()
+		status.CacheEntries = len(c.cache.data)
+		c.cache.mu.RUnlock()
+	}
+
+	if c.limiter != nil {
+		rl := c.limiter.status()
+		status.RateLimit = &rl
+	}
+	return status
+}
+
+// status reports the limiter's current state without taking a token.
+func (l *rateLimiter) status() RateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tokens := min(l.burst, l.tokens+time.Since(l.last).Seconds()*l.rate)
+	return RateLimitStatus{
+		Rate:        l.rate,
+		Burst:       int(l.burst),
+		Available:   max(tokens, 0),
+		Utilization: (l.burst - tokens) / l.burst,
+	}
+}
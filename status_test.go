@@ -0,0 +1,165 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestClient_Status tests the counters, upstream health and recent errors reported by
+// Status.
+func TestClient_Status(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithCache(time.Minute),
+		WithRetry(RetryConfig{MaxRetries: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}),
+		WithAuditRedaction("last_name"),
+	)
+	defer client.Close()
+	ctx := context.Background()
+
+	status := client.Status()
+	if !status.Upstream.Healthy || status.Requests != 0 || status.CacheEntries != 0 || status.RateLimit != nil {
+		t.Errorf("unexpected initial status: %+v", status)
+	}
+
+	if _, err := client.GetProviderByNPI(ctx, "1234567893"); err != nil {
+		t.Fatal(err)
+	}
+	client.GetProviderByNPI(ctx, "1234567893")
+
+	failing.Store(true)
+	if _, err := client.SearchProviders(ctx, SearchOptions{LastName: "Doe"}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	status = client.Status()
+	if status.Requests != 3 || status.Errors != 2 || status.Retries != 1 {
+		t.Errorf("requests/errors/retries = %d/%d/%d, want 3/2/1", status.Requests, status.Errors, status.Retries)
+	}
+	if status.CacheHits != 1 || status.CacheMisses != 1 || status.CacheEntries != 1 {
+		t.Errorf("cache hits/misses/entries = %d/%d/%d, want 1/1/1", status.CacheHits, status.CacheMisses, status.CacheEntries)
+	}
+	if status.Upstream.Healthy || status.Upstream.ConsecutiveFailures != 1 || status.Upstream.LastSuccess.IsZero() {
+		t.Errorf("unexpected upstream status: %+v", status.Upstream)
+	}
+	if len(status.RecentErrors) != 1 {
+		t.Fatalf("expected 1 recent error, got %+v", status.RecentErrors)
+	}
+	if recent := status.RecentErrors[0]; strings.Contains(recent.URL, "Doe") || !strings.Contains(recent.Error, "503") {
+		t.Errorf("unexpected recent error: %+v", recent)
+	}
+
+	failing.Store(false)
+	if _, err := client.SearchProviders(ctx, SearchOptions{LastName: "Roe"}); err != nil {
+		t.Fatal(err)
+	}
+	if status := client.Status(); !status.Upstream.Healthy || len(status.RecentErrors) != 1 {
+		t.Errorf("expected recovery to keep the error history: %+v", status)
+	}
+}
+
+// TestClient_StatusRecentErrors tests that only the newest errors are kept, newest
+// first, and that client errors do not mark the upstream unhealthy.
+func TestClient_StatusRecentErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+	for i := range maxRecentErrors + 5 {
+		client.SearchProviders(context.Background(), SearchOptions{City: strings.Repeat("X", i+1)})
+	}
+
+	status := client.Status()
+	if !status.Upstream.Healthy {
+		t.Error("400 responses should not mark the upstream unhealthy")
+	}
+	if len(status.RecentErrors) != maxRecentErrors {
+		t.Fatalf("expected %d recent errors, got %d", maxRecentErrors, len(status.RecentErrors))
+	}
+	if newest := status.RecentErrors[0].URL; !strings.Contains(newest, strings.Repeat("X", maxRecentErrors+5)) {
+		t.Errorf("expected newest error first, got %s", newest)
+	}
+}
+
+// TestClient_StatusRecentErrorsRedacted tests that transport errors in the status do
+// not leak redacted parameters.
+func TestClient_StatusRecentErrorsRedacted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRetry(RetryConfig{}), WithAuditRedaction("last_name"))
+	client.SearchProviders(context.Background(), SearchOptions{LastName: "Doe"})
+
+	status := client.Status()
+	if len(status.RecentErrors) != 1 {
+		t.Fatalf("expected 1 recent error, got %+v", status.RecentErrors)
+	}
+	if e := status.RecentErrors[0]; strings.Contains(e.URL, "Doe") || strings.Contains(e.Error, "Doe") {
+		t.Errorf("recent error not redacted: %+v", e)
+	}
+}
+
+// TestClient_StatusRateLimit tests the reported rate-limit utilization.
+func TestClient_StatusRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(0.01, 4))
+	if rl := client.Status().RateLimit; rl == nil || rl.Burst != 4 || rl.Utilization != 0 {
+		t.Fatalf("unexpected initial rate limit status: %+v", rl)
+	}
+	client.GetProviderByNPI(context.Background(), "1234567893")
+	rl := client.Status().RateLimit
+	if rl.Available < 2.9 || rl.Available > 3.1 || rl.Utilization < 0.24 || rl.Utilization > 0.26 {
+		t.Errorf("unexpected rate limit status after one request: %+v", rl)
+	}
+}
+
+// TestScheduler_Status tests that task runs, failures and next runs are reported.
+func TestScheduler_Status(t *testing.T) {
+	client := NewClient()
+	defer client.Close()
+
+	scheduler := client.NewScheduler()
+	scheduler.Add("failing", Every(5*time.Millisecond, 0), func(ctx context.Context) error {
+		return context.DeadlineExceeded
+	})
+	if tasks := scheduler.Status(); len(tasks) != 1 || tasks[0].Name != "failing" || tasks[0].Runs != 0 {
+		t.Fatalf("unexpected status before start: %+v", tasks)
+	}
+
+	scheduler.Start(context.Background())
+	deadline := time.Now().Add(2 * time.Second)
+	for scheduler.Status()[0].Runs < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	task := scheduler.Status()[0]
+	if task.Runs < 2 || task.Failures != task.Runs || task.LastError == "" || task.LastRun.IsZero() {
+		t.Errorf("unexpected task status: %+v", task)
+	}
+
+	scheduler.Stop()
+	if task := scheduler.Status()[0]; !task.NextRun.IsZero() || task.Running {
+		t.Errorf("expected no next run after Stop: %+v", task)
+	}
+}
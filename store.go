@@ -0,0 +1,504 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNoStore is returned by lookups that need a local store when none is configured.
+var ErrNoStore = errors.New("no provider store configured")
+
+// ProviderStore is a local database of provider records, for lookups the NPI Registry
+// API cannot answer. Implementations must be safe for concurrent use.
+type ProviderStore interface {
+	// Get returns the provider with the given NPI, or nil if it is not stored.
+	Get(ctx context.Context, npi string) (*Provider, error)
+
+	// Put inserts or replaces providers, keyed by Provider.Number.
+	Put(ctx context.Context, providers ...Provider) error
+
+	// Delete removes the provider with the given NPI, if present.
+	Delete(ctx context.Context, npi string) error
+
+	// Lookup returns the providers whose entry in the named secondary index equals key,
+	// ordered by NPI. Keys must already be normalized for the index.
+	Lookup(ctx context.Context, index, key string) ([]Provider, error)
+}
+
+// ProviderScanner is implemented by stores that can list every stored provider, such
+// as MemoryStore. It lets tools like nppes.DiffStore find providers that are missing
+// from a file.
+type ProviderScanner interface {
+	// All returns an iterator over the stored providers, ordered by NPI.
+	All(ctx context.Context) iter.Seq2[Provider, error]
+}
+
+// WithStore sets the local store used by store-backed lookups such as FindByPhone.
+// If store implements io.Closer, Client.Close closes it.
+func WithStore(store ProviderStore) ClientOption {
+	return func(c *Client) {
+		c.store = store
+		c.background.own(store)
+	}
+}
+
+// StoreOption configures a MemoryStore.
+type StoreOption func(*MemoryStore)
+
+// WithStoreIndexes adds secondary indexes to a MemoryStore, in addition to
+// DefaultIndexes. An index with the same name as a default one replaces it.
+func WithStoreIndexes(indexes ...Index) StoreOption {
+	return func(s *MemoryStore) {
+		for _, idx := range indexes {
+			s.defs[idx.Name()] = idx
+		}
+	}
+}
+
+// MemoryStore is an in-memory ProviderStore with pluggable secondary indexes.
+// Fill it with Put, e.g. from SearchAll results.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	providers map[string]*Provider
+	defs      map[string]Index
+	indexes   map[string]map[string]map[string]struct{} // index -> key -> NPIs
+	keys      map[string]map[string][]string            // NPI -> index -> keys
+	peak      int                                       // high-water mark of len(providers)
+}
+
+// NewMemoryStore creates an empty MemoryStore with DefaultIndexes and any indexes
+// added by opts.
+func NewMemoryStore(opts ...StoreOption) *MemoryStore {
+	s := &MemoryStore{
+		providers: make(map[string]*Provider),
+		defs:      make(map[string]Index),
+		indexes:   make(map[string]map[string]map[string]struct{}),
+		keys:      make(map[string]map[string][]string),
+	}
+	for _, idx := range DefaultIndexes() {
+		s.defs[idx.Name()] = idx
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Get implements ProviderStore.
+func (s *MemoryStore) Get(_ context.Context, npi string) (*Provider, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	provider, ok := s.providers[npi]
+	if !ok {
+		return nil, nil
+	}
+	copied := *provider
+	return &copied, nil
+}
+
+// Put implements ProviderStore.
+func (s *MemoryStore) Put(_ context.Context, providers ...Provider) error {
+	for _, provider := range providers {
+		if provider.Number == "" {
+			return &ValidationError{Field: "number", Message: "provider has no NPI"}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range providers {
+		provider := providers[i]
+		s.unindex(provider.Number)
+		s.providers[provider.Number] = &provider
+		for _, idx := range s.defs {
+			s.index(idx, &provider)
+		}
+	}
+	s.peak = max(s.peak, len(s.providers))
+	return nil
+}
+
+// Delete implements ProviderStore.
+func (s *MemoryStore) Delete(_ context.Context, npi string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unindex(npi)
+	delete(s.keys, npi)
+	delete(s.providers, npi)
+	return nil
+}
+
+// Lookup implements ProviderStore.
+func (s *MemoryStore) Lookup(_ context.Context, index, key string) ([]Provider, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.defs[index]; !ok {
+		return nil, &ValidationError{Field: "index", Message: "unknown index " + index}
+	}
+	return s.collect(s.indexes[index][key]), nil
+}
+
+// LookupAll returns the providers present under every one of keys in the named index,
+// ordered by NPI. With IndexName and the output of NameNGrams this finds providers
+// whose name contains a substring.
+func (s *MemoryStore) LookupAll(_ context.Context, index string, keys ...string) ([]Provider, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.defs[index]; !ok {
+		return nil, &ValidationError{Field: "index", Message: "unknown index " + index}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	// Start from the smallest posting list and intersect the rest into it
+	entries := s.indexes[index]
+	keys = slices.Clone(keys)
+	sort.Slice(keys, func(i, j int) bool { return len(entries[keys[i]]) < len(entries[keys[j]]) })
+	matched := make(map[string]struct{}, len(entries[keys[0]]))
+	for npi := range entries[keys[0]] {
+		matched[npi] = struct{}{}
+	}
+	for _, key := range keys[1:] {
+		for npi := range matched {
+			if _, ok := entries[key][npi]; !ok {
+				delete(matched, npi)
+			}
+		}
+	}
+	return s.collect(matched), nil
+}
+
+// All implements ProviderScanner. Providers stored or deleted during iteration may or
+// may not be seen.
+func (s *MemoryStore) All(ctx context.Context) iter.Seq2[Provider, error] {
+	return func(yield func(Provider, error) bool) {
+		s.mu.RLock()
+		npis := make([]string, 0, len(s.providers))
+		for npi := range s.providers {
+			npis = append(npis, npi)
+		}
+		s.mu.RUnlock()
+		sort.Strings(npis)
+
+		for _, npi := range npis {
+			if err := ctx.Err(); err != nil {
+				yield(Provider{}, err)
+				return
+			}
+			s.mu.RLock()
+			provider, ok := s.providers[npi]
+			s.mu.RUnlock()
+			if ok && !yield(*provider, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of stored providers.
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.providers)
+}
+
+// Indexes returns the names of the store's secondary indexes, sorted.
+func (s *MemoryStore) Indexes() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.defs))
+	for name := range s.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddIndex registers idx and builds it over the stored providers, replacing any index
+// with the same name. New lookup dimensions can be added this way without reloading
+// the store. It returns ctx.Err() if ctx is cancelled mid-build, leaving the store's
+// indexes, including any index with the same name, as they were.
+func (s *MemoryStore) AddIndex(ctx context.Context, idx Index) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	built, err := s.build(ctx, idx)
+	if err != nil {
+		return err
+	}
+	s.install(built)
+	return nil
+}
+
+// DropIndex removes the named secondary index and its entries.
+func (s *MemoryStore) DropIndex(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clear(name)
+	delete(s.defs, name)
+}
+
+// RebuildIndexes rebuilds every secondary index from the stored providers, e.g. after
+// an Index implementation changed how it derives keys. The indexes are replaced only
+// once all of them are built, so if ctx is cancelled the old ones keep serving lookups.
+func (s *MemoryStore) RebuildIndexes(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	built := make([]*builtIndex, 0, len(s.defs))
+	for _, idx := range s.defs {
+		b, err := s.build(ctx, idx)
+		if err != nil {
+			return err
+		}
+		built = append(built, b)
+	}
+	for _, b := range built {
+		s.install(b)
+	}
+	return nil
+}
+
+// builtIndex is an index built by build and not yet installed in the store.
+type builtIndex struct {
+	idx     Index
+	entries map[string]map[string]struct{} // key -> NPIs
+	keys    map[string][]string            // NPI -> keys
+}
+
+// build creates idx over all stored providers, without changing the store's indexes.
+// s.mu must be held.
+func (s *MemoryStore) build(ctx context.Context, idx Index) (*builtIndex, error) {
+	built := &builtIndex{
+		idx:     idx,
+		entries: make(map[string]map[string]struct{}),
+		keys:    make(map[string][]string),
+	}
+	for npi, provider := range s.providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		keys := uniqueKeys(idx.Keys(provider))
+		if len(keys) == 0 {
+			continue
+		}
+		built.keys[npi] = keys
+		for _, key := range keys {
+			if built.entries[key] == nil {
+				built.entries[key] = make(map[string]struct{})
+			}
+			built.entries[key][npi] = struct{}{}
+		}
+	}
+	return built, nil
+}
+
+// install registers built, replacing any index with the same name. s.mu must be held.
+func (s *MemoryStore) install(built *builtIndex) {
+	name := built.idx.Name()
+	s.clear(name)
+	s.indexes[name] = built.entries
+	for npi, keys := range built.keys {
+		indexed := s.keys[npi]
+		if indexed == nil {
+			indexed = make(map[string][]string)
+			s.keys[npi] = indexed
+		}
+		indexed[name] = keys
+	}
+	s.defs[name] = built.idx
+}
+
+// clear removes every entry of the named index. s.mu must be held.
+func (s *MemoryStore) clear(name string) {
+	delete(s.indexes, name)
+	for _, indexed := range s.keys {
+		delete(indexed, name)
+	}
+}
+
+// index adds provider to idx, recording its keys. s.mu must be held.
+func (s *MemoryStore) index(idx Index, provider *Provider) {
+	keys := uniqueKeys(idx.Keys(provider))
+	if len(keys) == 0 {
+		return
+	}
+
+	name := idx.Name()
+	indexed := s.keys[provider.Number]
+	if indexed == nil {
+		indexed = make(map[string][]string)
+		s.keys[provider.Number] = indexed
+	}
+	indexed[name] = keys
+
+	entries := s.indexes[name]
+	if entries == nil {
+		entries = make(map[string]map[string]struct{})
+		s.indexes[name] = entries
+	}
+	for _, key := range keys {
+		if entries[key] == nil {
+			entries[key] = make(map[string]struct{})
+		}
+		entries[key][provider.Number] = struct{}{}
+	}
+}
+
+// unindex removes the provider with npi from the secondary indexes, using the keys
+// recorded when it was indexed since callers may have mutated shared slices.
+// s.mu must be held.
+func (s *MemoryStore) unindex(npi string) {
+	indexed := s.keys[npi]
+	for name, keys := range indexed {
+		entries := s.indexes[name]
+		for _, key := range keys {
+			delete(entries[key], npi)
+			if len(entries[key]) == 0 {
+				delete(entries, key)
+			}
+		}
+		delete(indexed, name)
+	}
+}
+
+// collect returns the providers for npis, ordered by NPI. s.mu must be held.
+func (s *MemoryStore) collect(npis map[string]struct{}) []Provider {
+	sorted := make([]string, 0, len(npis))
+	for npi := range npis {
+		sorted = append(sorted, npi)
+	}
+	sort.Strings(sorted)
+
+	providers := make([]Provider, len(sorted))
+	for i, npi := range sorted {
+		providers[i] = *s.providers[npi]
+	}
+	return providers
+}
+
+// uniqueKeys drops empty and duplicate keys, keeping order.
+func uniqueKeys(keys []string) []string {
+	var unique []string
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// NormalizePhone reduces a US telephone number to its 10 digits, dropping punctuation,
+// a leading country code "1" and any extension ("x123", "ext. 123"). It returns ""
+// if the result is not 10 digits.
+func NormalizePhone(s string) string {
+	lower := strings.ToLower(s)
+	if i := strings.IndexAny(lower, "xe#"); i >= 0 {
+		lower = lower[:i]
+	}
+
+	digits := make([]byte, 0, len(lower))
+	for i := 0; i < len(lower); i++ {
+		if lower[i] >= '0' && lower[i] <= '9' {
+			digits = append(digits, lower[i])
+		}
+	}
+	if len(digits) == 11 && digits[0] == '1' {
+		digits = digits[1:]
+	}
+	if len(digits) != 10 {
+		return ""
+	}
+	return string(digits)
+}
+
+// NormalizeEndpoint canonicalizes a Direct address or endpoint URL for matching:
+// surrounding whitespace, a "mailto:" prefix and a trailing "/" are removed, and the
+// result is lowercased.
+func NormalizeEndpoint(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "mailto:")
+	return strings.TrimSuffix(s, "/")
+}
+
+// FindByPhone returns the stored providers with a practice or mailing address (or
+// practice location) listing the given telephone number. The NPI Registry API cannot
+// search by phone, so this requires a store configured with WithStore; otherwise it
+// returns ErrNoStore. Fax numbers are not indexed.
+func (c *Client) FindByPhone(ctx context.Context, number string) ([]Provider, error) {
+	ctx, span := c.tracer.Start(ctx, "FindByPhone",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.String("phone", number),
+		)...),
+	)
+	defer span.End()
+
+	key := NormalizePhone(number)
+	if key == "" {
+		err := &ValidationError{Field: "phone", Message: "phone number must have 10 digits"}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return c.lookupStore(ctx, span, IndexPhone, key)
+}
+
+// lookupStore runs a secondary-index lookup against the configured store.
+func (c *Client) lookupStore(ctx context.Context, span trace.Span, index, key string) ([]Provider, error) {
+	if c.store == nil {
+		span.RecordError(ErrNoStore)
+		span.SetStatus(codes.Error, ErrNoStore.Error())
+		return nil, ErrNoStore
+	}
+
+	providers, err := c.store.Lookup(ctx, index, key)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "store lookup failed")
+		return nil, fmt.Errorf("store lookup by %s failed: %w", index, err)
+	}
+	recordRetrievals(ctx, RetrievalMeta{Source: SourceStore}, providers...)
+	span.SetAttributes(c.traceAttrs(attribute.Int("result_count", len(providers)))...)
+	return providers, nil
+}
+
+// FindByEndpoint returns the stored providers listing the given Direct address or
+// endpoint URL, e.g. to route an inbound Direct message back to an NPI. Like
+// FindByPhone, it requires a store configured with WithStore.
+func (c *Client) FindByEndpoint(ctx context.Context, endpoint string) ([]Provider, error) {
+	ctx, span := c.tracer.Start(ctx, "FindByEndpoint",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.String("endpoint", endpoint),
+		)...),
+	)
+	defer span.End()
+
+	key := NormalizeEndpoint(endpoint)
+	if key == "" {
+		err := &ValidationError{Field: "endpoint", Message: "endpoint cannot be empty"}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	return c.lookupStore(ctx, span, IndexEndpoint, key)
+}
@@ -0,0 +1,155 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestNormalizePhone tests phone number normalization.
+func TestNormalizePhone(t *testing.T) {
+	tests := map[string]string{
+		"617-555-0100":          "6175550100",
+		"(617) 555-0100":        "6175550100",
+		"+1 617.555.0100":       "6175550100",
+		"6175550100 x204":       "6175550100",
+		"617-555-0100 ext. 204": "6175550100",
+		"555-0100":              "",
+		"":                      "",
+	}
+	for input, want := range tests {
+		if got := NormalizePhone(input); got != want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// TestMemoryStore_PhoneIndex tests that the phone index follows puts and deletes.
+func TestMemoryStore_PhoneIndex(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	a := mockProvider()
+	a.Number = "1111111111"
+	a.Addresses = []Address{{AddressPurpose: "LOCATION", TelephoneNumber: "617-555-0100"}}
+	b := mockProvider()
+	b.Number = "2222222222"
+	b.Addresses = nil
+	b.PracticeLocations = []PracticeLocation{{TelephoneNumber: "(617) 555-0100"}}
+
+	if err := store.Put(ctx, a, b); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	found, err := store.Lookup(ctx, IndexPhone, "6175550100")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if len(found) != 2 || found[0].Number != "1111111111" || found[1].Number != "2222222222" {
+		t.Fatalf("unexpected lookup result: %+v", found)
+	}
+
+	// Replacing a record drops its stale index entries
+	a.Addresses[0].TelephoneNumber = "617-555-0199"
+	store.Put(ctx, a)
+	found, _ = store.Lookup(ctx, IndexPhone, "6175550100")
+	if len(found) != 1 || found[0].Number != "2222222222" {
+		t.Errorf("stale index entry after replace: %+v", found)
+	}
+
+	store.Delete(ctx, "2222222222")
+	found, _ = store.Lookup(ctx, IndexPhone, "6175550100")
+	if len(found) != 0 {
+		t.Errorf("expected no results after delete, got %+v", found)
+	}
+	if store.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", store.Len())
+	}
+
+	if _, err := store.Lookup(ctx, "nope", "x"); !IsValidation(err) {
+		t.Errorf("expected ValidationError for unknown index, got %v", err)
+	}
+	if err := store.Put(ctx, Provider{}); !IsValidation(err) {
+		t.Errorf("expected ValidationError for provider without NPI, got %v", err)
+	}
+}
+
+// TestClient_FindByPhone tests the client-level phone lookup.
+func TestClient_FindByPhone(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := NewClient().FindByPhone(ctx, "617-555-0100"); !errors.Is(err, ErrNoStore) {
+		t.Errorf("expected ErrNoStore, got %v", err)
+	}
+
+	store := NewMemoryStore()
+	provider := mockProvider()
+	provider.Addresses = []Address{{AddressPurpose: "MAILING", TelephoneNumber: "1-617-555-0100"}}
+	store.Put(ctx, provider)
+
+	client := NewClient(WithStore(store))
+	found, err := client.FindByPhone(ctx, "(617) 555-0100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0].Number != provider.Number {
+		t.Errorf("unexpected result: %+v", found)
+	}
+
+	if _, err := client.FindByPhone(ctx, "555"); !IsValidation(err) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+}
+
+// TestClient_FindByEndpoint tests lookup by Direct address and endpoint URL.
+func TestClient_FindByEndpoint(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	provider := mockProvider()
+	provider.Endpoints = []Endpoint{
+		{EndpointType: "DIRECT", Endpoint: "Someone@Direct.Example.org"},
+		{EndpointType: "FHIR", Endpoint: "https://fhir.example.org/r4/"},
+	}
+	store.Put(ctx, provider)
+	client := NewClient(WithStore(store))
+
+	for _, query := range []string{"someone@direct.example.org", " mailto:SOMEONE@direct.example.org", "https://fhir.example.org/r4"} {
+		found, err := client.FindByEndpoint(ctx, query)
+		if err != nil {
+			t.Fatalf("FindByEndpoint(%q): %v", query, err)
+		}
+		if len(found) != 1 || found[0].Number != provider.Number {
+			t.Errorf("FindByEndpoint(%q) = %+v", query, found)
+		}
+	}
+
+	if _, err := client.FindByEndpoint(ctx, "  "); !IsValidation(err) {
+		t.Errorf("expected ValidationError, got %v", err)
+	}
+}
+
+// TestMemoryStore_All tests listing every stored provider in NPI order.
+func TestMemoryStore_All(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	store.Put(ctx, Provider{Number: "1234567893"}, Provider{Number: "1234567891"}, Provider{Number: "1234567892"})
+
+	var npis []string
+	for provider, err := range store.All(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		npis = append(npis, provider.Number)
+	}
+	if strings.Join(npis, ",") != "1234567891,1234567892,1234567893" {
+		t.Errorf("All = %v", npis)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, err := range store.All(cancelled) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package gonpi
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// streetAbbreviations maps street suffixes, directionals and common words to their USPS
+// abbreviations, so spelled-out and abbreviated forms of an address compare equal.
+var streetAbbreviations = map[string]string{
+	"ALLEY": "ALY", "AVENUE": "AVE", "AV": "AVE", "BOULEVARD": "BLVD", "BUILDING": "BLDG",
+	"CENTER": "CTR", "CENTRE": "CTR", "CIRCLE": "CIR", "COURT": "CT", "DRIVE": "DR",
+	"EXPRESSWAY": "EXPY", "FREEWAY": "FWY", "HIGHWAY": "HWY", "LANE": "LN", "PARKWAY": "PKWY",
+	"PLACE": "PL", "PLAZA": "PLZ", "ROAD": "RD", "ROUTE": "RTE", "SQUARE": "SQ", "STREET": "ST",
+	"TERRACE": "TER", "TRAIL": "TRL", "TURNPIKE": "TPKE",
+	"NORTH": "N", "SOUTH": "S", "EAST": "E", "WEST": "W",
+	"NORTHEAST": "NE", "NORTHWEST": "NW", "SOUTHEAST": "SE", "SOUTHWEST": "SW",
+	"FIRST": "1ST", "SECOND": "2ND", "THIRD": "3RD", "FOURTH": "4TH", "FIFTH": "5TH",
+	"SIXTH": "6TH", "SEVENTH": "7TH", "EIGHTH": "8TH", "NINTH": "9TH", "TENTH": "10TH",
+	"FORT": "FT", "MOUNT": "MT", "SAINT": "ST",
+}
+
+// unitDesignators are the words introducing a suite, floor or room within a building.
+var unitDesignators = map[string]bool{
+	"#": true, "APARTMENT": true, "APT": true, "FL": true, "FLOOR": true, "RM": true,
+	"ROOM": true, "STE": true, "SUITE": true, "UNIT": true,
+}
+
+// streetTokens returns the uppercase words of s with punctuation removed, "#" kept as
+// its own word and "P O" and "POST OFFICE" joined into "PO".
+func streetTokens(s string) []string {
+	s = strings.ReplaceAll(strings.ToUpper(s), "#", " # ")
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r != '#' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for i := 0; i < len(words); i++ {
+		if i+1 < len(words) && (words[i] == "P" && words[i+1] == "O" || words[i] == "POST" && words[i+1] == "OFFICE") {
+			tokens = append(tokens, "PO")
+			i++
+			continue
+		}
+		tokens = append(tokens, words[i])
+	}
+	return tokens
+}
+
+// splitStreet returns the normalized building part of a street line and the words
+// of the unit following its first unit designator, if any.
+func splitStreet(s string) (street, unit []string) {
+	tokens := streetTokens(s)
+	for i, token := range tokens {
+		if i > 0 && unitDesignators[token] {
+			unit = tokens[i+1:]
+			tokens = tokens[:i]
+			break
+		}
+	}
+	for i, token := range tokens {
+		if abbr, ok := streetAbbreviations[token]; ok {
+			tokens[i] = abbr
+		}
+	}
+	return tokens, unit
+}
+
+// NormalizeStreet canonicalizes a street address line for matching: it is uppercased,
+// punctuation is removed, suffixes and directionals are abbreviated the way USPS does,
+// and any suite, floor or room is dropped, so "100 North Main Street, Suite 200" and
+// "100 N MAIN ST" compare equal.
+func NormalizeStreet(street string) string {
+	tokens, _ := splitStreet(street)
+	return strings.Join(tokens, " ")
+}
+
+// normalizeUnit returns the unit of an address in comparable form: the words after
+// the unit designator in address1, or else address2 without its leading designator,
+// so "STE 200", "SUITE 200" and "#200" all become "200".
+func normalizeUnit(address1, address2 string) string {
+	if _, unit := splitStreet(address1); len(unit) > 0 {
+		return strings.Join(unit, " ")
+	}
+	tokens := streetTokens(address2)
+	if len(tokens) > 1 && unitDesignators[tokens[0]] {
+		tokens = tokens[1:]
+	}
+	return strings.Join(tokens, " ")
+}
+
+// AddressKey returns the IndexAddress key for a street line in the given postal code
+// and country, or "" if the street or postal code is empty. The key combines the
+// country, the postal code (its first five digits in the US and its territories) and
+// NormalizeStreet(street), so it identifies a building rather than a suite.
+func AddressKey(street, postalCode string, country CountryCode) string {
+	street = NormalizeStreet(street)
+	var postal string
+	if country.USPostal() {
+		country = CountryUS
+		postal = zip5(strings.ReplaceAll(postalCode, "-", ""))
+	} else {
+		country = country.normalize()
+		postal = strings.NewReplacer(" ", "", "-", "").Replace(strings.ToUpper(strings.TrimSpace(postalCode)))
+	}
+	if street == "" || postal == "" {
+		return ""
+	}
+	return string(country) + ":" + postal + ":" + street
+}
+
+// AddressQuery is a street address to match with FindByAddress. Address1 and
+// PostalCode are required; the other fields narrow the match.
+type AddressQuery struct {
+	// Address1 is the street line, such as "100 N Main St". A suite in it, as in
+	// "100 N Main St Ste 200", restricts the match like Address2 does.
+	Address1 string
+
+	// Address2 optionally restricts matches to one suite, floor or room, such as
+	// "Suite 200". Leave it empty to match every NPI in the building.
+	Address2 string
+
+	// City and State, if set, must equal the matched address, ignoring case.
+	City  string
+	State string
+
+	// PostalCode is the ZIP or postal code. Only the first five digits of US codes
+	// are compared.
+	PostalCode string
+
+	// CountryCode is the address's country, which defaults to the US.
+	CountryCode CountryCode
+}
+
+// unit returns the query's unit in normalizeUnit form, or "" to match any unit.
+func (q AddressQuery) unit() string {
+	return normalizeUnit(q.Address1, q.Address2)
+}
+
+// matches reports whether addr is the queried address.
+func (q AddressQuery) matches(addr Address, key, unit string) bool {
+	switch {
+	case AddressKey(addr.Address1, addr.PostalCode, CountryCode(addr.CountryCode)) != key:
+		return false
+	case q.City != "" && !strings.EqualFold(strings.TrimSpace(q.City), strings.TrimSpace(addr.City)):
+		return false
+	case q.State != "" && !strings.EqualFold(strings.TrimSpace(q.State), strings.TrimSpace(addr.State)):
+		return false
+	}
+	return unit == "" || normalizeUnit(addr.Address1, addr.Address2) == unit
+}
+
+// FindByAddress returns the stored providers practicing at the given street address,
+// e.g. to find the NPIs co-located at a facility. The NPI Registry API cannot search
+// by street, so, like FindByPhone, this requires a store configured with WithStore.
+// Addresses are compared after NormalizeStreet, and without an Address2 every suite
+// in the building matches. Mailing addresses are not indexed.
+//
+// Example usage:
+//
+//	colocated, err := client.FindByAddress(ctx, gonpi.AddressQuery{
+//	    Address1:   "55 Fruit Street",
+//	    PostalCode: "02114",
+//	})
+func (c *Client) FindByAddress(ctx context.Context, query AddressQuery) ([]Provider, error) {
+	ctx, span := c.tracer.Start(ctx, "FindByAddress",
+		trace.WithAttributes(c.traceAttrs(
+			attribute.String("address_1", query.Address1),
+			attribute.String("postal_code", query.PostalCode),
+		)...),
+	)
+	defer span.End()
+
+	key := AddressKey(query.Address1, query.PostalCode, query.CountryCode)
+	if key == "" {
+		err := &ValidationError{Field: "address_1", Message: "street address and postal code cannot be empty"}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	providers, err := c.lookupStore(ctx, span, IndexAddress, key)
+	unit := query.unit()
+	if err != nil || query.City == "" && query.State == "" && unit == "" {
+		return providers, err
+	}
+
+	matched := providers[:0]
+	for _, provider := range providers {
+		for _, addr := range practiceAddresses(&provider) {
+			if query.matches(addr, key, unit) {
+				matched = append(matched, provider)
+				break
+			}
+		}
+	}
+	span.SetAttributes(c.traceAttrs(attribute.Int("result_count", len(matched)))...)
+	return matched, nil
+}
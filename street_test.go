@@ -0,0 +1,93 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestNormalizeStreet tests street normalization and address keys.
+func TestNormalizeStreet(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"100 North Main Street, Suite 200", "100 N MAIN ST"},
+		{"100 N. Main St #200", "100 N MAIN ST"},
+		{"55 Fruit St.", "55 FRUIT ST"},
+		{"1 Medical Center Drive Floor 3", "1 MEDICAL CTR DR"},
+		{"P.O. Box 12", "PO BOX 12"},
+		{"Post Office Box 12", "PO BOX 12"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeStreet(tt.in); got != tt.want {
+			t.Errorf("NormalizeStreet(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if got := AddressKey("100 North Main Street", "02139-1234", ""); got != "US:02139:100 N MAIN ST" {
+		t.Errorf("AddressKey() = %q", got)
+	}
+	if AddressKey("1 Calle del Parque", "00907", "PR") != AddressKey("1 CALLE DEL PARQUE", "00907", "US") {
+		t.Error("expected territory and US keys to match")
+	}
+	if got := AddressKey("200 Elizabeth St", "m5g 2c4", "ca"); got != "CA:M5G2C4:200 ELIZABETH ST" {
+		t.Errorf("AddressKey() = %q", got)
+	}
+	if AddressKey("100 Main St", "", "") != "" || AddressKey("", "02139", "") != "" {
+		t.Error("expected empty keys for incomplete addresses")
+	}
+}
+
+// TestFindByAddress tests finding the providers co-located at a street address.
+func TestFindByAddress(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	clinic := mockProvider()
+	clinic.Number = "1111111111"
+	clinic.Addresses = []Address{
+		{AddressPurpose: "LOCATION", Address1: "100 NORTH MAIN STREET", Address2: "SUITE 200", City: "CAMBRIDGE", State: "MA", PostalCode: "021391234"},
+	}
+	lab := mockProvider()
+	lab.Number = "2222222222"
+	lab.Addresses = []Address{{AddressPurpose: "MAILING", Address1: "PO BOX 1", PostalCode: "02139"}}
+	lab.PracticeLocations = []PracticeLocation{{Address1: "100 N Main St Ste 310", City: "Cambridge", State: "MA", PostalCode: "02139"}}
+	billing := mockProvider()
+	billing.Number = "3333333333"
+	billing.Addresses = []Address{
+		{AddressPurpose: "MAILING", Address1: "100 N MAIN ST", PostalCode: "02139"},
+		{AddressPurpose: "LOCATION", Address1: "9 ELM ST", PostalCode: "02139"},
+	}
+	store.Put(ctx, clinic, lab, billing)
+	client := NewClient(WithStore(store))
+	defer client.Close()
+
+	retrievals := NewRetrievals()
+	found, err := client.FindByAddress(ContextWithCallOptions(ctx, WithRetrievals(retrievals)), AddressQuery{Address1: "100 N. Main St.", PostalCode: "02139"})
+	if err != nil {
+		t.Fatalf("FindByAddress() error = %v", err)
+	}
+	if len(found) != 2 || retrievals.Meta(found[0].Number).Source != SourceStore {
+		t.Errorf("building lookup = %+v", found)
+	}
+
+	found, _ = client.FindByAddress(ctx, AddressQuery{Address1: "100 N Main St", Address2: "#310", PostalCode: "02139"})
+	if len(found) != 1 || found[0].Number != lab.Number {
+		t.Errorf("suite lookup = %+v", found)
+	}
+	found, _ = client.FindByAddress(ctx, AddressQuery{Address1: "100 N Main St Suite 200", PostalCode: "02139"})
+	if len(found) != 1 || found[0].Number != clinic.Number {
+		t.Errorf("suite in address_1 lookup = %+v", found)
+	}
+	found, _ = client.FindByAddress(ctx, AddressQuery{Address1: "100 N Main St", City: "Boston", PostalCode: "02139"})
+	if len(found) != 0 {
+		t.Errorf("city mismatch lookup = %+v", found)
+	}
+
+	var verr *ValidationError
+	if _, err := client.FindByAddress(ctx, AddressQuery{Address1: "100 N Main St"}); !errors.As(err, &verr) {
+		t.Errorf("expected a ValidationError, got %v", err)
+	}
+	if _, err := NewClient().FindByAddress(ctx, AddressQuery{Address1: "100 N Main St", PostalCode: "02139"}); !errors.Is(err, ErrNoStore) {
+		t.Errorf("expected ErrNoStore, got %v", err)
+	}
+}
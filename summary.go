@@ -0,0 +1,69 @@
+package gonpi
+
+// ProviderSummary is a compact, flattened view of a provider for embedding in other
+// systems' records. Its JSON tags are stable: fields may be added, but existing ones
+// will not be renamed or removed.
+type ProviderSummary struct {
+	// NPI is the provider's NPI.
+	NPI string `json:"npi"`
+
+	// EnumerationType is "NPI-1" for individuals and "NPI-2" for organizations.
+	EnumerationType string `json:"enumeration_type"`
+
+	// DisplayName is the organization name, or the individual's full name followed by
+	// their credential, such as "JANE Q DOE, MD".
+	DisplayName string `json:"display_name"`
+
+	// SpecialtyCode and SpecialtyDesc are the primary taxonomy, or the first listed.
+	SpecialtyCode string `json:"specialty_code,omitempty"`
+	SpecialtyDesc string `json:"specialty_desc,omitempty"`
+
+	// City, State, PostalCode and Phone are from the practice location address, or the
+	// first address listed.
+	City       string `json:"city,omitempty"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Phone      string `json:"phone,omitempty"`
+
+	// Status is "active", "deactivated", or the registry's code if it is neither.
+	Status string `json:"status"`
+}
+
+// Summarize returns the summary of provider.
+//
+// Example usage:
+//
+//	record.Provider = gonpi.Summarize(*provider)
+func Summarize(provider Provider) ProviderSummary {
+	summary := ProviderSummary{
+		NPI:             provider.Number,
+		EnumerationType: provider.EnumerationType,
+		DisplayName:     provider.DisplayName(),
+		Status:          provider.Basic.Status,
+	}
+	switch provider.Basic.Status {
+	case "A":
+		summary.Status = "active"
+	case "D":
+		summary.Status = "deactivated"
+	}
+
+	for i, taxonomy := range provider.Taxonomies {
+		if i == 0 || taxonomy.Primary {
+			summary.SpecialtyCode, summary.SpecialtyDesc = taxonomy.Code, taxonomy.Desc
+		}
+		if taxonomy.Primary {
+			break
+		}
+	}
+	for i, address := range provider.Addresses {
+		if i == 0 || address.AddressPurpose == "LOCATION" {
+			summary.City, summary.State = address.City, address.State
+			summary.PostalCode, summary.Phone = address.PostalCode, address.TelephoneNumber
+		}
+		if address.AddressPurpose == "LOCATION" {
+			break
+		}
+	}
+	return summary
+}
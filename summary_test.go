@@ -0,0 +1,54 @@
+package gonpi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestSummarize tests the fields picked for individuals and organizations.
+func TestSummarize(t *testing.T) {
+	p := mockProvider()
+	p.Addresses = append([]Address{{AddressPurpose: "MAILING", City: "BOX TOWN", State: "NV"}}, p.Addresses...)
+	p.Taxonomies = append([]Taxonomy{{Code: "207R00000X", Desc: "Internal Medicine"}}, p.Taxonomies...)
+
+	want := ProviderSummary{
+		NPI:             "1234567890",
+		EnumerationType: "NPI-1",
+		DisplayName:     "John Doe, MD",
+		SpecialtyCode:   "207Q00000X",
+		SpecialtyDesc:   "Family Medicine",
+		City:            "ANYTOWN",
+		State:           "CA",
+		PostalCode:      "12345",
+		Phone:           "555-1234",
+		Status:          "active",
+	}
+	if got := Summarize(p); got != want {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+
+	org := Provider{
+		Number:          "1245319599",
+		EnumerationType: "NPI-2",
+		Basic:           BasicInfo{OrganizationName: "GENERAL HOSPITAL", Credential: "MD", Status: "D"},
+		Addresses:       []Address{{AddressPurpose: "MAILING", City: "BOX TOWN"}},
+		Taxonomies:      []Taxonomy{{Code: "282N00000X", Desc: "General Acute Care Hospital"}},
+	}
+	got := Summarize(org)
+	if got.DisplayName != "GENERAL HOSPITAL" || got.Status != "deactivated" || got.City != "BOX TOWN" || got.SpecialtyCode != "282N00000X" {
+		t.Errorf("Summarize(org) = %+v", got)
+	}
+}
+
+// TestProviderSummary_JSON tests that the JSON tags stay stable.
+func TestProviderSummary_JSON(t *testing.T) {
+	data, err := json.Marshal(Summarize(mockProvider()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"npi":"1234567890","enumeration_type":"NPI-1","display_name":"John Doe, MD","specialty_code":"207Q00000X",` +
+		`"specialty_desc":"Family Medicine","city":"ANYTOWN","state":"CA","postal_code":"12345","phone":"555-1234","status":"active"}`
+	if string(data) != want {
+		t.Errorf("JSON = %s\nwant  %s", data, want)
+	}
+}
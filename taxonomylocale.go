@@ -0,0 +1,108 @@
+package gonpi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TaxonomyBundle translates taxonomy display strings into one language, for
+// member-facing directories that show specialties in Spanish or other languages.
+// Taxonomy descriptions are keyed by code, and service categories and age groups by
+// their values. Missing entries fall back to the registry's English description and
+// the English Label, so a partial bundle is safe to use. A nil *TaxonomyBundle
+// returns English throughout.
+//
+// Example bundle file, as read by LoadTaxonomyBundle:
+//
+//	{
+//	  "language": "es",
+//	  "descriptions": {"207Q00000X": "Medicina familiar", "208000000X": "Pediatría"},
+//	  "categories": {"primary_care": "Atención primaria"},
+//	  "age_groups": {"pediatric": "Pediátrico"}
+//	}
+type TaxonomyBundle struct {
+	// Language is the bundle's BCP 47 language tag, such as "es" or "zh-Hant".
+	Language string `json:"language"`
+
+	// Descriptions maps taxonomy codes to translated descriptions.
+	Descriptions map[string]string `json:"descriptions,omitempty"`
+
+	// Categories maps service categories to translated labels.
+	Categories map[ServiceCategory]string `json:"categories,omitempty"`
+
+	// AgeGroups maps age groups to translated labels.
+	AgeGroups map[AgeGroup]string `json:"age_groups,omitempty"`
+}
+
+// LoadTaxonomyBundle reads a TaxonomyBundle from JSON. Unknown fields are rejected,
+// taxonomy codes are uppercased, and the language is required.
+func LoadTaxonomyBundle(r io.Reader) (*TaxonomyBundle, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var bundle TaxonomyBundle
+	if err := decoder.Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode taxonomy bundle: %w", err)
+	}
+	if strings.TrimSpace(bundle.Language) == "" {
+		return nil, &ValidationError{Field: "language", Message: "taxonomy bundle has no language"}
+	}
+	descriptions := make(map[string]string, len(bundle.Descriptions))
+	for code, desc := range bundle.Descriptions {
+		descriptions[strings.ToUpper(strings.TrimSpace(code))] = desc
+	}
+	bundle.Descriptions = descriptions
+	return &bundle, nil
+}
+
+// Description returns the translated description of taxonomy, or its registry
+// description if the bundle has none.
+func (b *TaxonomyBundle) Description(taxonomy Taxonomy) string {
+	if b != nil {
+		if desc, ok := b.Descriptions[strings.ToUpper(strings.TrimSpace(taxonomy.Code))]; ok && desc != "" {
+			return desc
+		}
+	}
+	return taxonomy.Desc
+}
+
+// Category returns the translated label of category, or its English Label.
+func (b *TaxonomyBundle) Category(category ServiceCategory) string {
+	if b != nil {
+		if label, ok := b.Categories[category]; ok && label != "" {
+			return label
+		}
+	}
+	return category.Label()
+}
+
+// AgeGroup returns the translated label of group, or its English Label.
+func (b *TaxonomyBundle) AgeGroup(group AgeGroup) string {
+	if b != nil {
+		if label, ok := b.AgeGroups[group]; ok && label != "" {
+			return label
+		}
+	}
+	return group.Label()
+}
+
+// Localize returns a copy of provider with its taxonomy descriptions translated, so
+// code that displays Taxonomy.Desc, such as Summarize, shows the bundle's language.
+// The provider passed in is not modified.
+//
+// Example usage:
+//
+//	summary := gonpi.Summarize(spanish.Localize(*provider))
+func (b *TaxonomyBundle) Localize(provider Provider) Provider {
+	if b == nil || len(provider.Taxonomies) == 0 {
+		return provider
+	}
+	taxonomies := make([]Taxonomy, len(provider.Taxonomies))
+	for i, taxonomy := range provider.Taxonomies {
+		taxonomy.Desc = b.Description(taxonomy)
+		taxonomies[i] = taxonomy
+	}
+	provider.Taxonomies = taxonomies
+	return provider
+}
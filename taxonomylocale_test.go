@@ -0,0 +1,57 @@
+package gonpi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTaxonomyBundle tests translating descriptions and labels with English fallback.
+func TestTaxonomyBundle(t *testing.T) {
+	bundle, err := LoadTaxonomyBundle(strings.NewReader(`{
+	  "language": "es",
+	  "descriptions": {"207q00000x": "Medicina familiar"},
+	  "categories": {"primary_care": "Atención primaria"},
+	  "age_groups": {"pediatric": "Pediátrico"}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadTaxonomyBundle() error = %v", err)
+	}
+
+	provider := mockProvider()
+	provider.Taxonomies = []Taxonomy{
+		{Code: "207Q00000X", Desc: "Family Medicine", Primary: true},
+		{Code: "208000000X", Desc: "Pediatrics"},
+	}
+	localized := bundle.Localize(provider)
+	if got := Summarize(localized).SpecialtyDesc; got != "Medicina familiar" {
+		t.Errorf("localized specialty = %q", got)
+	}
+	if localized.Taxonomies[1].Desc != "Pediatrics" || provider.Taxonomies[0].Desc != "Family Medicine" {
+		t.Errorf("unexpected descriptions: %+v, original %+v", localized.Taxonomies, provider.Taxonomies)
+	}
+
+	class := ClassifyTaxonomy("208000000X")
+	if got := bundle.Category(class.Category); got != "Atención primaria" {
+		t.Errorf("Category() = %q", got)
+	}
+	if got := bundle.AgeGroup(class.AgeGroup); got != "Pediátrico" {
+		t.Errorf("AgeGroup() = %q", got)
+	}
+	if got := bundle.Category(CategoryDME); got != "Durable medical equipment" {
+		t.Errorf("fallback Category() = %q", got)
+	}
+
+	var english *TaxonomyBundle
+	if got := english.AgeGroup(AgeAll); got != "All ages" {
+		t.Errorf("nil bundle AgeGroup() = %q", got)
+	}
+
+	var verr *ValidationError
+	if _, err := LoadTaxonomyBundle(strings.NewReader(`{"descriptions": {}}`)); !errors.As(err, &verr) {
+		t.Errorf("expected a ValidationError for a missing language, got %v", err)
+	}
+	if _, err := LoadTaxonomyBundle(strings.NewReader(`{"language": "es", "labels": {}}`)); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,48 @@
+package gonpi
+
+import "time"
+
+// EnumerationTime returns when the NPI was enumerated, parsed from
+// Basic.EnumerationDate or, if absent, CreatedEpoch. It returns false if neither
+// can be parsed.
+func (p Provider) EnumerationTime() (time.Time, bool) {
+	return enumerationTime(p)
+}
+
+// LastUpdatedTime returns when the record was last updated, parsed from
+// Basic.LastUpdated, LastUpdated or LastUpdatedEpoch. It returns false if none
+// can be parsed.
+func (p Provider) LastUpdatedTime() (time.Time, bool) {
+	return lastUpdatedTime(p)
+}
+
+// LastUpdatedEpochTime returns LastUpdatedEpoch as a time, taking small values as
+// seconds rather than milliseconds. It returns false if the epoch is unset.
+func (p Provider) LastUpdatedEpochTime() (time.Time, bool) {
+	return epochTime(p.LastUpdatedEpoch)
+}
+
+// YearsSinceEnumeration returns the number of whole years between enumeration and now.
+// It returns false if the enumeration date is unknown.
+func (p Provider) YearsSinceEnumeration(now time.Time) (int, bool) {
+	enumerated, ok := p.EnumerationTime()
+	if !ok {
+		return 0, false
+	}
+	now = now.UTC()
+	years := now.Year() - enumerated.Year()
+	if now.Month() < enumerated.Month() || (now.Month() == enumerated.Month() && now.Day() < enumerated.Day()) {
+		years--
+	}
+	if years < 0 {
+		years = 0
+	}
+	return years, true
+}
+
+// IsRecentlyUpdated reports whether the record was updated within window of the
+// current time. Records without a parseable update date are not recent.
+func (p Provider) IsRecentlyUpdated(window time.Duration) bool {
+	updated, ok := p.LastUpdatedTime()
+	return ok && time.Since(updated) <= window
+}
@@ -0,0 +1,77 @@
+package gonpi
+
+import (
+	"testing"
+	"time"
+)
+
+// TestProvider_YearsSinceEnumeration tests whole-year computation.
+func TestProvider_YearsSinceEnumeration(t *testing.T) {
+	provider := mockProvider() // enumerated 2010-05-15
+
+	tests := []struct {
+		now  string
+		want int
+	}{
+		{"2010-05-15", 0},
+		{"2011-05-14", 0},
+		{"2011-05-15", 1},
+		{"2024-12-31", 14},
+	}
+	for _, tt := range tests {
+		got, ok := provider.YearsSinceEnumeration(date(tt.now))
+		if !ok || got != tt.want {
+			t.Errorf("YearsSinceEnumeration(%s) = %d, %v; want %d", tt.now, got, ok, tt.want)
+		}
+	}
+
+	provider.Basic.EnumerationDate = ""
+	provider.CreatedEpoch = 0
+	if _, ok := provider.YearsSinceEnumeration(time.Now()); ok {
+		t.Error("expected unknown enumeration date")
+	}
+}
+
+// TestProvider_LastUpdatedTime tests parsing the last-updated date.
+func TestProvider_LastUpdatedTime(t *testing.T) {
+	provider := mockProvider()
+
+	updated, ok := provider.LastUpdatedTime()
+	if !ok || !updated.Equal(date("2023-01-01")) {
+		t.Errorf("unexpected last updated time: %v, %v", updated, ok)
+	}
+
+	enumerated, ok := provider.EnumerationTime()
+	if !ok || !enumerated.Equal(date("2010-05-15")) {
+		t.Errorf("unexpected enumeration time: %v, %v", enumerated, ok)
+	}
+}
+
+// TestProvider_LastUpdatedEpochTime tests converting the epoch in milliseconds or seconds.
+func TestProvider_LastUpdatedEpochTime(t *testing.T) {
+	want := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider := mockProvider()
+	for _, epoch := range []FlexInt{FlexInt(want.UnixMilli()), FlexInt(want.Unix())} {
+		provider.LastUpdatedEpoch = epoch
+		if updated, ok := provider.LastUpdatedEpochTime(); !ok || !updated.Equal(want) {
+			t.Errorf("LastUpdatedEpochTime() with epoch %d = %v, %v", epoch, updated, ok)
+		}
+	}
+	provider.LastUpdatedEpoch = 0
+	if _, ok := provider.LastUpdatedEpochTime(); ok {
+		t.Error("expected no time without an epoch")
+	}
+}
+
+// TestProvider_IsRecentlyUpdated tests the recency window.
+func TestProvider_IsRecentlyUpdated(t *testing.T) {
+	provider := mockProvider()
+	provider.Basic.LastUpdated = time.Now().UTC().AddDate(0, 0, -3).Format(registryDateLayout)
+
+	if !provider.IsRecentlyUpdated(7 * 24 * time.Hour) {
+		t.Error("expected provider to be recently updated")
+	}
+	if provider.IsRecentlyUpdated(24 * time.Hour) {
+		t.Error("expected provider not to be updated within a day")
+	}
+}
@@ -0,0 +1,97 @@
+package gonpi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TraceAttributeFilter inspects an attribute before it is recorded on a span or span event.
+// It returns the attribute to record, which may be rewritten, and whether it should be kept.
+//
+// Example usage:
+//
+//	client := NewClient(
+//	    WithTraceAttributeFilter(HashIdentifyingAttributes),
+//	)
+type TraceAttributeFilter func(kv attribute.KeyValue) (attribute.KeyValue, bool)
+
+// identifyingAttributeKeys lists span attributes that can identify a provider or a
+// person being searched for. Request URLs are included because they carry the query.
+var identifyingAttributeKeys = map[attribute.Key]bool{
+	"npi":               true,
+	"first_name":        true,
+	"last_name":         true,
+	"organization_name": true,
+	"url.full":          true,
+	"phone":             true,
+	"endpoint":          true,
+	"license":           true,
+	"address_1":         true,
+	"postal_code":       true,
+	"gonpi.tenant":      true,
+}
+
+// IsIdentifyingAttribute reports whether key is one of the span attributes that may
+// carry personally identifying information (names, NPIs, request URLs).
+func IsIdentifyingAttribute(key attribute.Key) bool {
+	return identifyingAttributeKeys[key]
+}
+
+// DropIdentifyingAttributes is a TraceAttributeFilter that removes identifying
+// attributes while keeping operational ones such as limits, counts and status codes.
+func DropIdentifyingAttributes(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	return kv, !IsIdentifyingAttribute(kv.Key)
+}
+
+// HashIdentifyingAttributes is a TraceAttributeFilter that replaces the value of
+// identifying attributes with a hex-encoded SHA-256 digest. Hashed values still allow
+// correlating spans for the same query without exposing the query itself.
+// Empty values are kept as-is.
+func HashIdentifyingAttributes(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	if !IsIdentifyingAttribute(kv.Key) {
+		return kv, true
+	}
+	value := kv.Value.Emit()
+	if value == "" {
+		return kv, true
+	}
+	sum := sha256.Sum256([]byte(value))
+	return kv.Key.String(hex.EncodeToString(sum[:])), true
+}
+
+// errorType returns the error.type recorded on span events for err: the status code
+// of an *APIError, or else its RetryClass. Unlike the error message, it cannot carry
+// a request URL or the names and NPIs in its query.
+func errorType(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return strconv.Itoa(apiErr.StatusCode)
+	}
+	return string(classifyRetry(err))
+}
+
+// WithTraceAttributeFilter sets a filter applied to every attribute the client records
+// on its spans. Errors recorded on spans are not filtered.
+func WithTraceAttributeFilter(filter TraceAttributeFilter) ClientOption {
+	return func(c *Client) {
+		c.traceFilter = filter
+	}
+}
+
+// traceAttrs applies the configured TraceAttributeFilter to kvs.
+func (c *Client) traceAttrs(kvs ...attribute.KeyValue) []attribute.KeyValue {
+	if c.traceFilter == nil {
+		return kvs
+	}
+	filtered := kvs[:0]
+	for _, kv := range kvs {
+		if kv, keep := c.traceFilter(kv); keep {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,133 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TestTraceAttrs_NoFilter tests that attributes pass through unchanged by default.
+func TestTraceAttrs_NoFilter(t *testing.T) {
+	client := NewClient()
+
+	kvs := client.traceAttrs(attribute.String("npi", "1234567890"), attribute.Int("limit", 10))
+	if len(kvs) != 2 || kvs[0].Value.AsString() != "1234567890" {
+		t.Errorf("unexpected attributes: %v", kvs)
+	}
+}
+
+// TestTraceAttrs_Drop tests that identifying attributes are dropped.
+func TestTraceAttrs_Drop(t *testing.T) {
+	client := NewClient(WithTraceAttributeFilter(DropIdentifyingAttributes))
+
+	kvs := client.traceAttrs(
+		attribute.String("last_name", "Smith"),
+		attribute.String("first_name", "John"),
+		attribute.String("url.full", "https://example.com/?last_name=Smith"),
+		attribute.String("address_1", "55 Fruit St"),
+		attribute.String("postal_code", "02114"),
+		attribute.String("state", "CA"),
+		attribute.Int("limit", 10),
+	)
+
+	if len(kvs) != 2 {
+		t.Fatalf("expected 2 attributes, got %d: %v", len(kvs), kvs)
+	}
+	if kvs[0].Key != "state" || kvs[1].Key != "limit" {
+		t.Errorf("unexpected attributes kept: %v", kvs)
+	}
+}
+
+// TestTraceAttrs_Hash tests that identifying attributes are hashed deterministically.
+func TestTraceAttrs_Hash(t *testing.T) {
+	client := NewClient(WithTraceAttributeFilter(HashIdentifyingAttributes))
+
+	first := client.traceAttrs(attribute.String("npi", "1234567890"), attribute.String("last_name", ""))
+	second := client.traceAttrs(attribute.String("npi", "1234567890"))
+
+	if first[0].Value.AsString() == "1234567890" {
+		t.Error("expected NPI to be hashed")
+	}
+	if len(first[0].Value.AsString()) != 64 {
+		t.Errorf("expected hex SHA-256, got %q", first[0].Value.AsString())
+	}
+	if first[0].Value.AsString() != second[0].Value.AsString() {
+		t.Error("expected hashing to be deterministic")
+	}
+	if first[1].Value.AsString() != "" {
+		t.Error("expected empty value to be kept as-is")
+	}
+}
+
+// recordingTracerProvider records the instrumentation names it is asked for.
+type recordingTracerProvider struct {
+	noop.TracerProvider
+	names []string
+}
+
+func (p *recordingTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	p.names = append(p.names, name)
+	return p.TracerProvider.Tracer(name, opts...)
+}
+
+// TestWithTracerProvider tests that the tracer is resolved from the given provider.
+func TestWithTracerProvider(t *testing.T) {
+	provider := &recordingTracerProvider{}
+	NewClient(WithTracerProvider(provider))
+
+	if len(provider.names) != 1 || provider.names[0] != TracerName {
+		t.Errorf("expected tracer %q to be requested once, got %v", TracerName, provider.names)
+	}
+}
+
+// TestServerAttributes tests server.address and server.port inference.
+func TestServerAttributes(t *testing.T) {
+	tests := []struct {
+		rawURL   string
+		wantHost string
+		wantPort int64
+	}{
+		{"https://npiregistry.cms.hhs.gov/api/", "npiregistry.cms.hhs.gov", 443},
+		{"http://localhost/api", "localhost", 80},
+		{"http://127.0.0.1:8080/", "127.0.0.1", 8080},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			u, _ := url.Parse(tt.rawURL)
+			attrs := serverAttributes(u)
+			if len(attrs) != 2 {
+				t.Fatalf("expected 2 attributes, got %v", attrs)
+			}
+			if attrs[0].Key != "server.address" || attrs[0].Value.AsString() != tt.wantHost {
+				t.Errorf("unexpected address attribute: %v", attrs[0])
+			}
+			if attrs[1].Key != "server.port" || attrs[1].Value.AsInt64() != tt.wantPort {
+				t.Errorf("unexpected port attribute: %v", attrs[1])
+			}
+		})
+	}
+}
+
+// TestErrorType tests that span events describe errors without their messages.
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&APIError{StatusCode: 503, Message: "unavailable"}, "503"},
+		{fmt.Errorf("http request failed: %w", &url.Error{Op: "Get", URL: "https://example.com/?last_name=Doe", Err: context.DeadlineExceeded}), string(RetryTimeout)},
+		{&url.Error{Op: "Get", URL: "https://example.com/?last_name=Doe", Err: errors.New("connection refused")}, string(RetryNetwork)},
+	}
+	for _, tt := range tests {
+		if got := errorType(tt.err); got != tt.want {
+			t.Errorf("errorType(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
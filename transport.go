@@ -0,0 +1,193 @@
+package gonpi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport is an http.RoundTripper that applies a client's retries, rate limiting,
+// headers, call metadata, tracing and metrics to arbitrary HTTP requests, so that
+// other CMS endpoints, such as the NPPES downloadable files site, can be fetched as
+// resiliently as the registry API.
+//
+// Unlike the client, Transport does not interpret responses: error statuses are
+// retried like API errors (5xx, 429 and 408) and the final response is returned as-is.
+// Requests with a body are only retried if it can be rewound with Request.GetBody.
+type Transport struct {
+	client *Client
+	base   http.RoundTripper
+}
+
+// NewTransport returns a Transport configured with the same options as NewClient.
+// Only options affecting requests apply, such as WithRetry, WithRateLimit, WithHeader,
+// WithTracerProvider, WithStatsSink and WithHTTPClient, whose transport is used to send
+// requests (http.DefaultTransport if it has none).
+//
+// Example usage:
+//
+//	files := &http.Client{Transport: gonpi.NewTransport(gonpi.WithRateLimit(1, 1))}
+//	resp, err := files.Get("https://download.cms.gov/nppes/NPI_Files.html")
+func NewTransport(opts ...ClientOption) *Transport {
+	return NewClient(opts...).Transport()
+}
+
+// Transport returns a Transport sharing this client's settings, rate limiter and
+// telemetry, so that requests made through it count against the same rate limit as
+// API calls.
+func (c *Client) Transport() *Transport {
+	base := http.DefaultTransport
+	if c.httpClient != nil && c.httpClient.Transport != nil {
+		base = c.httpClient.Transport
+	}
+	return &Transport{client: c, base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := t.client
+	ctx, span := c.tracer.Start(req.Context(), "RoundTrip",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(c.traceAttrs(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.String()),
+		)...),
+	)
+	defer span.End()
+	span.SetAttributes(serverAttributes(req.URL)...)
+
+	ctx, done, err := c.background.enter(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "client closed")
+		return nil, err
+	}
+	resp, err := t.roundTrip(ctx, span, req)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	// The call stays in flight until the caller has read the body
+	resp.Body = &drainingBody{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+// roundTrip sends req with retries.
+func (t *Transport) roundTrip(ctx context.Context, span trace.Span, req *http.Request) (*http.Response, error) {
+	c := t.client
+
+	maxRetries := c.retry.MaxRetries
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			c.increment(MetricRetries)
+			select {
+			case <-ctx.Done():
+				span.RecordError(ctx.Err())
+				span.SetStatus(codes.Error, "request cancelled")
+				return nil, fmt.Errorf("request cancelled: %w", ctx.Err())
+			case <-time.After(c.retry.backoff(attempt)):
+			}
+		}
+
+		attemptReq, err := t.attempt(ctx, req, attempt)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "failed to rewind request body")
+			return nil, err
+		}
+		if err := c.prepare(ctx, attemptReq, span, false); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.send(attemptReq, t.base.RoundTrip)
+		if err != nil {
+			if attempt < maxRetries && isTransientError(err) {
+				t.retrying(ctx, span, req, attempt, err)
+				continue
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "http request failed")
+			return nil, err
+		}
+
+		span.SetAttributes(c.traceAttrs(semconv.HTTPResponseStatusCode(resp.StatusCode))...)
+		if resp.StatusCode < http.StatusBadRequest {
+			span.SetAttributes(c.traceAttrs(attribute.Int("attempts", attempt+1))...)
+			return resp, nil
+		}
+
+		c.increment(MetricRequestErrors)
+		statusErr := &APIError{StatusCode: resp.StatusCode, Message: resp.Status}
+		if attempt < maxRetries && isRetryable(statusErr) {
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, MaxResponseBodySize))
+			resp.Body.Close()
+			t.retrying(ctx, span, req, attempt, statusErr)
+			continue
+		}
+		span.SetAttributes(c.traceAttrs(semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))...)
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		return resp, nil
+	}
+}
+
+// drainingBody is a response body that ends its call's registration with the client
+// when closed, so that Client.Shutdown waits for callers to finish reading.
+type drainingBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *drainingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// attempt returns the request to send for the given attempt: a clone of req with
+// default and configured headers added where missing and, on retries, a fresh body.
+func (t *Transport) attempt(ctx context.Context, req *http.Request, attempt int) (*http.Request, error) {
+	out := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		out.Body = body
+	}
+
+	if out.Header.Get("User-Agent") == "" {
+		out.Header.Set("User-Agent", "gonpi/1.0")
+	}
+	for key, values := range t.client.headers {
+		if out.Header.Get(key) == "" {
+			out.Header[key] = values
+		}
+	}
+	return out, nil
+}
+
+// retrying records a failed attempt that will be retried.
+func (t *Transport) retrying(ctx context.Context, span trace.Span, req *http.Request, attempt int, err error) {
+	span.AddEvent("retry_attempt",
+		trace.WithAttributes(t.client.traceAttrs(
+			attribute.Int("attempt", attempt+1),
+			semconv.ErrorTypeKey.String(errorType(err)),
+		)...),
+	)
+	t.client.notifyRetry(ctx, req.URL.String(), attempt+1, err)
+}
@@ -0,0 +1,120 @@
+package gonpi
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry retries quickly for tests.
+var fastRetry = RetryConfig{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffMultiplier: 1}
+
+// TestTransport_Retries tests that error statuses are retried, bodies rewound and
+// headers applied.
+func TestTransport_Retries(t *testing.T) {
+	var attempts atomic.Int32
+	var bodies []string
+	var agent, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		agent, apiKey = r.Header.Get("User-Agent"), r.Header.Get("X-Api-Key")
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	sink := newRecordingSink()
+	httpClient := &http.Client{Transport: NewTransport(WithRetry(fastRetry), WithHeader("X-Api-Key", "secret"), WithStatsSink(sink))}
+
+	resp, err := httpClient.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("got %d %q", resp.StatusCode, body)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts.Load())
+	}
+	for i, b := range bodies {
+		if b != "payload" {
+			t.Errorf("attempt %d body = %q", i+1, b)
+		}
+	}
+	if agent != "gonpi/1.0" || apiKey != "secret" {
+		t.Errorf("User-Agent = %q, X-Api-Key = %q", agent, apiKey)
+	}
+	if sink.counters[MetricRequests] != 3 || sink.counters[MetricRetries] != 2 || sink.counters[MetricRequestErrors] != 2 {
+		t.Errorf("unexpected metrics %v", sink.counters)
+	}
+}
+
+// TestTransport_FinalResponse tests that exhausted retries return the last response
+// and that non-retryable statuses and unrewindable bodies are not retried.
+func TestTransport_FinalResponse(t *testing.T) {
+	var attempts atomic.Int32
+	status := http.StatusBadGateway
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: NewTransport(WithRetry(fastRetry))}
+
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || attempts.Load() != 4 {
+		t.Errorf("got %d after %d attempts, want 502 after 4", resp.StatusCode, attempts.Load())
+	}
+
+	attempts.Store(0)
+	status = http.StatusNotFound
+	resp, _ = httpClient.Get(server.URL)
+	resp.Body.Close()
+	if attempts.Load() != 1 {
+		t.Errorf("404 attempted %d times", attempts.Load())
+	}
+
+	attempts.Store(0)
+	status = http.StatusServiceUnavailable
+	req, _ := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(bytes.NewReader([]byte("once"))))
+	resp, _ = httpClient.Do(req)
+	resp.Body.Close()
+	if attempts.Load() != 1 {
+		t.Errorf("unrewindable body attempted %d times", attempts.Load())
+	}
+}
+
+// TestClientTransport tests that a client's Transport shares its rate limiter.
+func TestClientTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := NewClient(WithRateLimit(0.1, 1))
+	httpClient := &http.Client{Transport: client.Transport(), Timeout: 50 * time.Millisecond}
+
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := httpClient.Get(server.URL); err == nil {
+		t.Error("expected the shared rate limit to delay the second request past the timeout")
+	}
+}
@@ -108,11 +108,15 @@ type Provider struct {
 	CreatedEpoch      FlexInt            `json:"created_epoch"`
 	LastUpdated       string             `json:"last_updated"`
 	LastUpdatedEpoch  FlexInt            `json:"last_updated_epoch"`
+
+	// Extensions holds data added by enrichment steps such as EnrichGeo.
+	// It is nil for providers decoded from the API.
+	Extensions *Extensions `json:"gonpi_extensions,omitempty"`
 }
 
 func (p Provider) FullName() string {
 	if p.EnumerationType == "NPI-2" {
-		return p.Basic.OrganizationName
+		return p.organizationName()
 	}
 	fullName := p.Basic.FirstName
 	if p.Basic.MiddleName != "" {
@@ -122,6 +126,19 @@ func (p Provider) FullName() string {
 	return fullName
 }
 
+// Extensions holds data derived by gonpi that is not part of the NPI Registry response.
+// Enrichment steps such as EnrichGeo and EnrichCounty populate it on Provider.Extensions.
+type Extensions struct {
+	// Geo contains coordinates for the provider's practice locations.
+	Geo []GeoLocation `json:"geo,omitempty"`
+
+	// Counties contains county identifiers for the provider's practice locations.
+	Counties []CountyLocation `json:"counties,omitempty"`
+
+	// Affiliations contains the facilities the provider is affiliated with.
+	Affiliations []HospitalAffiliation `json:"affiliations,omitempty"`
+}
+
 // BasicInfo contains basic information about the provider.
 type BasicInfo struct {
 	FirstName                         string `json:"first_name"`
@@ -241,6 +258,18 @@ type OtherName struct {
 type APIResponse struct {
 	ResultCount int        `json:"result_count"`
 	Results     []Provider `json:"results"`
+
+	// Errors lists the problems the registry found with the query, such as an invalid
+	// state code. The registry reports them with a 200 status and no results;
+	// SearchProviders returns them as a ValidationError.
+	Errors []ResponseError `json:"Errors,omitempty"`
+}
+
+// ResponseError is one entry of APIResponse.Errors.
+type ResponseError struct {
+	Description string `json:"description"`
+	Field       string `json:"field"`
+	Number      string `json:"number"`
 }
 
 // SearchOptions defines all available filters for searching providers in the NPI Registry.
@@ -297,19 +326,23 @@ type SearchOptions struct {
 	City string
 
 	// State filters by two-letter state code (e.g., "CA", "NY", "TX").
-	// Must be uppercase.
+	// Must be uppercase. US territories and military codes (AA, AE, AP) are accepted;
+	// see States for the full list. Invalid codes return a ValidationError.
 	State string
 
 	// PostalCode filters by ZIP code.
 	// Supports 5-digit (e.g., "90210") or 9-digit (e.g., "90210-1234") formats.
 	PostalCode string
 
-	// CountryCode filters by two-letter country code (default: "US").
+	// CountryCode filters by two-letter country code (default: "US", or the
+	// client's WithDefaultCountryCode).
 	// Examples: "US", "CA", "MX"
+	// Must be an uppercase ISO 3166-1 alpha-2 code; invalid codes return a ValidationError.
 	CountryCode string
 
 	// Limit specifies the maximum number of results to return per request.
-	// Valid range: 1-200. Default: 10 if not specified or 0.
+	// Valid range: 1-200. Default: 10 if not specified or 0, unless the client
+	// was created with WithDefaultLimit.
 	// Values exceeding 200 are automatically capped at 200.
 	Limit int
 
@@ -320,6 +353,36 @@ type SearchOptions struct {
 	//   - Page 3: Skip=20, Limit=10
 	Skip int
 
+	// Cursor continues a SearchPage from the NextCursor of the previous page, replacing
+	// Skip and Limit. It is ignored by SearchProviders and SearchAll.
+	Cursor string
+
+	// MaxResults caps the total number of providers returned across pages by
+	// SearchAll. For SearchProviders it caps the single page, lowering Limit if needed.
+	// 0 means no cap.
+	MaxResults int
+
+	// PageConcurrency lets SearchAll fetch up to this many pages at once after the first
+	// page comes back full, yielding results in the same order as sequential paging.
+	// Requests still pass through the client's rate limit. Up to PageConcurrency-1
+	// requests past the last page may be wasted. 0 or 1 fetches pages one at a time.
+	PageConcurrency int
+
+	// Consistency selects how SearchAll handles results that change between pages.
+	// Checking makes consecutive pages overlap by one result. Default: PagesUnchecked.
+	Consistency PageConsistency
+
+	// Credential, such as "MD" or "NP", ranks providers holding it higher in
+	// ScoreProvider, compared like Provider.HasCredential. The registry cannot search
+	// by credential, so it is applied client-side and does not narrow the results;
+	// filter with HasAnyCredential for that.
+	Credential string
+
+	// SortByRelevance reorders SearchProviders results by ScoreProvider against these
+	// options. The API's own ordering carries no meaning. It is applied client-side and
+	// only within the returned page.
+	SortByRelevance bool
+
 	// Pretty formats the JSON response for human readability.
 	// Only affects the raw API response; has no effect on returned Go structs.
 	Pretty bool
@@ -348,8 +411,10 @@ type ClientOption func(*Client)
 //   - Retry #3: waits 400ms
 //   - Continues up to MaxRetries, capped at MaxDelay
 //
-// Only retries server errors (5xx) and rate limits (429).
-// Client errors (4xx) are not retried.
+// Only retries server errors (5xx), rate limits (429), request timeouts (408)
+// and transient network failures such as timeouts or reset connections.
+// Other client errors (4xx), unknown hosts, malformed URLs and TLS
+// certificate failures are not retried.
 type RetryConfig struct {
 	// MaxRetries is the maximum number of retry attempts.
 	// 0 means no retries. Default: 3.
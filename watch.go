@@ -0,0 +1,263 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Publisher delivers change events to a downstream system.
+// Implementations must be safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, event ChangeEvent) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(ctx context.Context, event ChangeEvent) error
+
+// Publish implements Publisher.
+func (f PublisherFunc) Publish(ctx context.Context, event ChangeEvent) error {
+	return f(ctx, event)
+}
+
+// Watcher polls a fixed set of NPIs and publishes a ChangeEvent whenever a provider
+// record appears, changes, is deactivated or is reactivated between polls.
+type Watcher struct {
+	client     *Client
+	npis       []string
+	publishers []Publisher
+
+	mu          sync.Mutex
+	snapshots   map[string]*Provider
+	deactivated map[string]bool
+	state       StateStore
+	stateKey    string
+}
+
+// watcherStateVersion is the version of the state saved by Watcher.Persist.
+const watcherStateVersion = 1
+
+// watcherState is the state of a Watcher saved in a StateStore.
+type watcherState struct {
+	Version     int                  `json:"version"`
+	Snapshots   map[string]*Provider `json:"snapshots"`
+	Deactivated []string             `json:"deactivated,omitempty"`
+}
+
+// NewWatcher creates a Watcher for npis that delivers events to publishers.
+// The first Poll records a baseline and emits EventProviderCreated for every provider found.
+func (c *Client) NewWatcher(npis []string, publishers ...Publisher) *Watcher {
+	return &Watcher{
+		client:      c,
+		npis:        npis,
+		publishers:  publishers,
+		snapshots:   make(map[string]*Provider),
+		deactivated: make(map[string]bool),
+	}
+}
+
+// Poll fetches every watched NPI once, publishes events for changes since the previous
+// poll and returns them. A previously seen NPI that is no longer found is reported as
+// EventProviderDeactivated. Other lookup failures leave the previous snapshot in place
+// and are returned joined with any publish errors; events are still returned for the
+// NPIs that were fetched.
+//
+// A change is recorded only once every publisher accepts its event. If any publisher
+// fails, the previous snapshot is kept and the change is reported again, to every
+// publisher, on the next Poll, so publishers should tolerate duplicate events.
+func (w *Watcher) Poll(ctx context.Context) ([]ChangeEvent, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	items, err := w.client.GetProvidersByNPIsOrdered(ctx, w.npis)
+	if len(items) == 0 {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var events []ChangeEvent
+	var errs []error
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		npi, normErr := NormalizeNPI(item.NPI)
+		if normErr != nil {
+			npi = item.NPI
+		}
+		if seen[npi] {
+			continue
+		}
+		seen[npi] = true
+
+		if item.Err != nil && !IsNotFound(item.Err) {
+			errs = append(errs, fmt.Errorf("NPI %s: %w", item.NPI, item.Err))
+			continue
+		}
+		if item.Provider == nil {
+			if previous := w.snapshots[npi]; previous != nil && !w.deactivated[npi] {
+				events = append(events, w.event(EventProviderDeactivated, npi, now, nil, previous))
+			}
+			continue
+		}
+
+		if event, ok := w.observe(npi, item.Provider, now); ok {
+			events = append(events, event)
+		} else {
+			w.commit(npi, item.Provider)
+		}
+	}
+
+	for _, event := range events {
+		published := true
+		for _, publisher := range w.publishers {
+			if err := publisher.Publish(ctx, event); err != nil {
+				errs = append(errs, fmt.Errorf("failed to publish %s for NPI %s: %w", event.Type, event.NPI, err))
+				published = false
+			}
+		}
+		if published {
+			w.commit(event.NPI, event.Provider)
+		}
+	}
+	if w.state != nil {
+		if err := w.save(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return events, errors.Join(errs...)
+}
+
+// Persist restores the watcher's snapshots from store under key, if any were saved,
+// and saves them there after every Poll, so a restarted watcher reports only the
+// changes since its last poll instead of a provider.created event for every NPI.
+// Changes whose events failed to publish are saved as they were before the change,
+// so a restarted watcher publishes them again. Save failures are returned by Poll.
+// Call Persist before the first Poll.
+//
+// Example usage:
+//
+//	state, err := gonpi.NewFileStateStore("/var/lib/gonpi")
+//	watcher := client.NewWatcher(npis, publisher)
+//	if err := watcher.Persist(ctx, state, "watch/roster"); err != nil {
+//	    return err
+//	}
+//	watcher.Run(ctx, 24*time.Hour, logError)
+func (w *Watcher) Persist(ctx context.Context, store StateStore, key string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, err := store.Get(ctx, key)
+	if err != nil && !errors.Is(err, ErrStateNotFound) {
+		return fmt.Errorf("failed to restore watcher state: %w", err)
+	}
+	if err == nil {
+		var state watcherState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("failed to restore watcher state: %w", err)
+		}
+		if state.Version > watcherStateVersion {
+			return fmt.Errorf("failed to restore watcher state: version %d is newer than supported version %d", state.Version, watcherStateVersion)
+		}
+		for npi, p := range state.Snapshots {
+			w.snapshots[npi] = p
+		}
+		for _, npi := range state.Deactivated {
+			w.deactivated[npi] = true
+		}
+	}
+	w.state, w.stateKey = store, key
+	return nil
+}
+
+// save writes the snapshots to the state store. The caller must hold w.mu.
+func (w *Watcher) save(ctx context.Context) error {
+	state := watcherState{Version: watcherStateVersion, Snapshots: w.snapshots}
+	for npi, deactivated := range w.deactivated {
+		if deactivated {
+			state.Deactivated = append(state.Deactivated, npi)
+		}
+	}
+	sort.Strings(state.Deactivated)
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to save watcher state: %w", err)
+	}
+	if err := w.state.Put(ctx, w.stateKey, data); err != nil {
+		return fmt.Errorf("failed to save watcher state: %w", err)
+	}
+	return nil
+}
+
+// observe returns the event implied by current, the latest record for npi, if any.
+// It does not record current; see commit.
+func (w *Watcher) observe(npi string, current *Provider, now time.Time) (ChangeEvent, bool) {
+	previous, known := w.snapshots[npi]
+	active := isActive(current)
+	wasDeactivated := w.deactivated[npi]
+
+	switch {
+	case !known:
+		return w.event(EventProviderCreated, npi, now, current, nil), true
+	case wasDeactivated && active:
+		return w.event(EventProviderReactivated, npi, now, current, previous), true
+	case !wasDeactivated && !active:
+		return w.event(EventProviderDeactivated, npi, now, current, previous), true
+	case !sameRecord(previous, current):
+		return w.event(EventProviderUpdated, npi, now, current, previous), true
+	}
+	return ChangeEvent{}, false
+}
+
+// commit records current as the latest snapshot for npi, or marks npi deactivated if
+// current is nil because it was no longer found.
+func (w *Watcher) commit(npi string, current *Provider) {
+	if current == nil {
+		w.deactivated[npi] = true
+		return
+	}
+	w.snapshots[npi] = current
+	w.deactivated[npi] = !isActive(current)
+}
+
+func (w *Watcher) event(typ EventType, npi string, now time.Time, current, previous *Provider) ChangeEvent {
+	event := ChangeEvent{
+		Version:  EventSchemaVersion,
+		Type:     typ,
+		NPI:      npi,
+		Time:     now,
+		Provider: current,
+		Previous: previous,
+	}
+	if current != nil && previous != nil {
+		event.Changes = DiffProviders(previous, current)
+	}
+	return event
+}
+
+// Run polls every interval until ctx is cancelled or the client is closed. Poll errors
+// are passed to onError if it is not nil; they do not stop the watcher. Run returns
+// ctx.Err(), or context.Canceled after Client.Close or once Client.Shutdown lets the
+// current poll finish.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ctx, done := w.client.background.track(ctx)
+	defer done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Poll(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.client.background.draining:
+			return context.Canceled
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package gonpi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWatcher_Poll tests that the watcher emits created and updated events between polls.
+func TestWatcher_Poll(t *testing.T) {
+	var mu sync.Mutex
+	provider := mockProvider()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{provider}))
+	}))
+	defer server.Close()
+
+	var published []ChangeEvent
+	sink := PublisherFunc(func(_ context.Context, event ChangeEvent) error {
+		published = append(published, event)
+		return nil
+	})
+
+	client := NewClient(WithBaseURL(server.URL))
+	watcher := client.NewWatcher([]string{provider.Number}, sink)
+
+	events, err := watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("first poll: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventProviderCreated {
+		t.Fatalf("first poll events = %+v, want one %s", events, EventProviderCreated)
+	}
+
+	events, err = watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("second poll: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("unchanged poll emitted %d events", len(events))
+	}
+
+	mu.Lock()
+	provider.Basic.LastUpdated = "2024-06-01"
+	mu.Unlock()
+
+	events, err = watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("third poll: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventProviderUpdated {
+		t.Fatalf("third poll events = %+v, want one %s", events, EventProviderUpdated)
+	}
+	if events[0].Previous == nil || events[0].Previous.Basic.LastUpdated == "2024-06-01" {
+		t.Error("updated event should carry the previous record")
+	}
+	if len(published) != 2 {
+		t.Errorf("published %d events, want 2", len(published))
+	}
+}
+
+// TestWatcher_PublishError tests that publish failures are reported without losing events.
+func TestWatcher_PublishError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mockAPIResponse([]Provider{mockProvider()}))
+	}))
+	defer server.Close()
+
+	boom := errors.New("boom")
+	client := NewClient(WithBaseURL(server.URL))
+	watcher := client.NewWatcher([]string{"1234567890"}, PublisherFunc(func(context.Context, ChangeEvent) error {
+		return boom
+	}))
+
+	events, err := watcher.Poll(context.Background())
+	if !errors.Is(err, boom) {
+		t.Errorf("expected publish error, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("expected 1 event, got %d", len(events))
+	}
+
+	events, err = watcher.Poll(context.Background())
+	if !errors.Is(err, boom) || len(events) != 1 || events[0].Type != EventProviderCreated {
+		t.Errorf("failed event should be retried on the next poll, got %+v, %v", events, err)
+	}
+}
+
+type fakeKafka struct {
+	topic      string
+	key, value []byte
+}
+
+func (f *fakeKafka) Produce(_ context.Context, topic string, key, value []byte) error {
+	f.topic, f.key, f.value = topic, key, value
+	return nil
+}
+
+type fakeNATS struct {
+	subject string
+	data    []byte
+}
+
+func (f *fakeNATS) Publish(subject string, data []byte) error {
+	f.subject, f.data = subject, data
+	return nil
+}
+
+// TestBrokerPublishers tests that the Kafka and NATS publishers encode events as JSON.
+func TestBrokerPublishers(t *testing.T) {
+	provider := mockProvider()
+	event := ChangeEvent{Type: EventProviderCreated, NPI: provider.Number, Time: time.Now(), Provider: &provider}
+
+	kafka := &fakeKafka{}
+	if err := (&KafkaPublisher{Producer: kafka, Topic: "npi-changes"}).Publish(context.Background(), event); err != nil {
+		t.Fatalf("kafka publish: %v", err)
+	}
+	if kafka.topic != "npi-changes" || string(kafka.key) != provider.Number {
+		t.Errorf("kafka topic/key = %q/%q", kafka.topic, kafka.key)
+	}
+
+	nats := &fakeNATS{}
+	if err := (&NATSPublisher{Conn: nats, Subject: "npi.changes"}).Publish(context.Background(), event); err != nil {
+		t.Fatalf("nats publish: %v", err)
+	}
+	var decoded ChangeEvent
+	if err := json.Unmarshal(nats.data, &decoded); err != nil {
+		t.Fatalf("nats payload: %v", err)
+	}
+	if nats.subject != "npi.changes" || decoded.NPI != provider.Number || decoded.Type != EventProviderCreated {
+		t.Errorf("unexpected nats message %q: %+v", nats.subject, decoded)
+	}
+}
+
+// TestWebhookPublisher tests that the webhook publisher POSTs JSON and surfaces non-2xx responses.
+func TestWebhookPublisher(t *testing.T) {
+	status := http.StatusNoContent
+	var gotAuth, gotType string
+	var gotEvent ChangeEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &gotEvent)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := &WebhookPublisher{URL: server.URL, Header: http.Header{"Authorization": {"Bearer token"}}}
+	event := ChangeEvent{Type: EventProviderUpdated, NPI: "1234567890"}
+
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if gotAuth != "Bearer token" || gotType != "application/json" || gotEvent.NPI != "1234567890" {
+		t.Errorf("unexpected request: auth=%q type=%q event=%+v", gotAuth, gotType, gotEvent)
+	}
+
+	status = http.StatusBadGateway
+	err := publisher.Publish(context.Background(), event)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected APIError with 502, got %v", err)
+	}
+}
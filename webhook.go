@@ -0,0 +1,65 @@
+package gonpi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on webhook deliveries signed by WebhookPublisher.
+const (
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// timestamp, a dot and the request body, keyed with the subscription secret.
+	WebhookSignatureHeader = "X-Gonpi-Signature"
+
+	// WebhookTimestampHeader carries the Unix time the delivery was signed at.
+	WebhookTimestampHeader = "X-Gonpi-Timestamp"
+)
+
+// ErrInvalidSignature indicates that a webhook delivery is unsigned, was signed with
+// a different secret, or was signed too long ago.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// SignWebhook returns the WebhookSignatureHeader value for body sent at timestamp.
+func SignWebhook(secret []byte, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature headers of a webhook delivery with body, for
+// receivers written in Go. Deliveries signed more than tolerance before or after now
+// are rejected, to limit replays; a tolerance of 0 disables the check. It returns an
+// error wrapping ErrInvalidSignature if the delivery does not verify.
+//
+// Example usage:
+//
+//	body, _ := io.ReadAll(r.Body)
+//	if err := gonpi.VerifyWebhook(secret, r.Header, body, 5*time.Minute); err != nil {
+//	    http.Error(w, err.Error(), http.StatusUnauthorized)
+//	    return
+//	}
+func VerifyWebhook(secret []byte, header http.Header, body []byte, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(header.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		return errors.Join(ErrInvalidSignature, errors.New("missing or malformed "+WebhookTimestampHeader))
+	}
+	timestamp := time.Unix(unix, 0)
+	if tolerance > 0 {
+		if age := time.Since(timestamp); age > tolerance || age < -tolerance {
+			return errors.Join(ErrInvalidSignature, errors.New("timestamp outside tolerance"))
+		}
+	}
+	signature := strings.TrimSpace(header.Get(WebhookSignatureHeader))
+	if !hmac.Equal([]byte(signature), []byte(SignWebhook(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package gonpi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestVerifyWebhook tests signing and verifying deliveries.
+func TestVerifyWebhook(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"type":"provider.updated"}`)
+	now := time.Now()
+	header := http.Header{}
+	header.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	header.Set(WebhookSignatureHeader, SignWebhook(secret, now, body))
+
+	if err := VerifyWebhook(secret, header, body, time.Minute); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+	if err := VerifyWebhook([]byte("other"), header, body, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for wrong secret, got %v", err)
+	}
+	if err := VerifyWebhook(secret, header, []byte(`{}`), time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for altered body, got %v", err)
+	}
+
+	old := now.Add(-time.Hour)
+	header.Set(WebhookTimestampHeader, strconv.FormatInt(old.Unix(), 10))
+	header.Set(WebhookSignatureHeader, SignWebhook(secret, old, body))
+	if err := VerifyWebhook(secret, header, body, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for stale delivery, got %v", err)
+	}
+	if err := VerifyWebhook(secret, header, body, 0); err != nil {
+		t.Errorf("expected stale delivery to verify without tolerance, got %v", err)
+	}
+	if err := VerifyWebhook(secret, http.Header{}, body, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for unsigned delivery, got %v", err)
+	}
+}
+
+// TestWebhookPublisher_Secret tests that deliveries are signed when a secret is set.
+func TestWebhookPublisher_Secret(t *testing.T) {
+	secret := []byte("s3cret")
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyErr = VerifyWebhook(secret, r.Header, body, time.Minute)
+	}))
+	defer server.Close()
+
+	publisher := &WebhookPublisher{URL: server.URL, Secret: secret}
+	if err := publisher.Publish(context.Background(), ChangeEvent{Type: EventProviderCreated, NPI: "1234567893"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verifyErr != nil {
+		t.Errorf("delivery did not verify: %v", verifyErr)
+	}
+}